@@ -0,0 +1,74 @@
+// Command auditverify walks the audit_log hash chain and checks it against
+// the chain head and every recorded or exported anchor. It prints the
+// verification as JSON and exits with status 2 when the chain is broken.
+//
+//	go run ./cmd/auditverify -anchor-dir /var/lib/payments/audit-anchors
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ayo6706/payment-multicurrency/internal/db"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	_ = godotenv.Load()
+
+	databaseURL := flag.String("database-url", envOr("DATABASE_URL", "PAYMENT_DATABASE_URL"), "Postgres connection string")
+	anchorDir := flag.String("anchor-dir", envOr("AUDIT_ANCHOR_DIR", "PAYMENT_AUDIT_ANCHOR_DIR"), "directory of exported anchor files; empty checks database anchors only")
+	flag.Parse()
+
+	if *databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL or -database-url is required")
+		return 1
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	pool, err := db.Connect(ctx, *databaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect database: %v\n", err)
+		return 1
+	}
+	defer pool.Close()
+
+	result, err := service.NewAuditService(repository.NewStore(pool)).WithAnchorDir(*anchorDir).VerifyChain(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify audit chain: %v\n", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		fmt.Fprintf(os.Stderr, "encode result: %v\n", err)
+		return 1
+	}
+	if !result.Valid {
+		return 2
+	}
+	return 0
+}
+
+func envOr(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
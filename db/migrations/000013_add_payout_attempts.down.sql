@@ -0,0 +1,2 @@
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_attempts_nonnegative_ck;
+ALTER TABLE payouts DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,11 @@
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'payouts_attempts_nonnegative_ck'
+  ) THEN
+    ALTER TABLE payouts
+      ADD CONSTRAINT payouts_attempts_nonnegative_ck CHECK (attempts >= 0);
+  END IF;
+END $$;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id UUID PRIMARY KEY,
+  aggregate_type TEXT NOT NULL,
+  aggregate_id UUID NOT NULL,
+  event_type TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  published_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due
+  ON outbox_events (next_attempt_at, created_at)
+  WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at
+  ON outbox_events (published_at)
+  WHERE published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate
+  ON outbox_events (aggregate_type, aggregate_id, created_at);
+
+-- One row per (event, sink) once that sink has accepted the event, so a retry
+-- after a partial failure only re-publishes to the sinks that missed it.
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+  event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+  sink TEXT NOT NULL,
+  delivered_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (event_id, sink)
+);
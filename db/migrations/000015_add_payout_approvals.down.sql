@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_payouts_awaiting_approval;
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_four_eyes_ck;
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('PENDING', 'PROCESSING', 'COMPLETED', 'FAILED', 'MANUAL_REVIEW'));
+
+ALTER TABLE payouts DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE payouts DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE payouts DROP COLUMN IF EXISTS requested_by;
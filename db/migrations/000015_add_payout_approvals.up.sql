@@ -0,0 +1,22 @@
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS requested_by UUID REFERENCES users(id);
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id);
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PROCESSING', 'COMPLETED', 'FAILED', 'REJECTED', 'MANUAL_REVIEW'));
+
+-- Four-eyes control: the reviewer of a payout can never be its requester.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'payouts_four_eyes_ck'
+  ) THEN
+    ALTER TABLE payouts
+      ADD CONSTRAINT payouts_four_eyes_ck CHECK (reviewed_by IS NULL OR requested_by IS NULL OR reviewed_by <> requested_by);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_payouts_awaiting_approval
+  ON payouts (created_at)
+  WHERE status = 'AWAITING_APPROVAL';
@@ -0,0 +1,2 @@
+ALTER TABLE payouts DROP COLUMN IF EXISTS beneficiary_id;
+DROP TABLE IF EXISTS beneficiaries;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS beneficiaries (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  name TEXT NOT NULL,
+  scheme TEXT NOT NULL,
+  iban TEXT,
+  bic TEXT,
+  routing_number TEXT,
+  account_number TEXT,
+  sort_code TEXT,
+  -- Payouts to this beneficiary are refused before available_at (fraud cool-down).
+  available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  deleted_at TIMESTAMPTZ,
+  CONSTRAINT beneficiaries_scheme_ck CHECK (scheme IN ('IBAN', 'US_ACH', 'UK_SORT_CODE')),
+  CONSTRAINT beneficiaries_details_ck CHECK (
+    (scheme = 'IBAN' AND iban IS NOT NULL)
+    OR (scheme = 'US_ACH' AND routing_number IS NOT NULL AND account_number IS NOT NULL)
+    OR (scheme = 'UK_SORT_CODE' AND sort_code IS NOT NULL AND account_number IS NOT NULL)
+  )
+);
+
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_user
+  ON beneficiaries (user_id, created_at)
+  WHERE deleted_at IS NULL;
+
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS beneficiary_id UUID REFERENCES beneficiaries(id);
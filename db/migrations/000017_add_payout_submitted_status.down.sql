@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_payouts_gateway_ref;
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PROCESSING', 'COMPLETED', 'FAILED', 'REJECTED', 'MANUAL_REVIEW'));
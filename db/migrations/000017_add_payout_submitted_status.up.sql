@@ -0,0 +1,8 @@
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PROCESSING', 'SUBMITTED', 'COMPLETED', 'FAILED', 'REJECTED', 'MANUAL_REVIEW'));
+
+-- Asynchronous gateways report outcomes against the reference they issued.
+CREATE INDEX IF NOT EXISTS idx_payouts_gateway_ref
+  ON payouts (gateway_ref)
+  WHERE gateway_ref IS NOT NULL;
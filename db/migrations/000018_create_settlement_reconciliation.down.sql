@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reconciliation_breaks;
+DROP TABLE IF EXISTS settlement_lines;
+DROP TABLE IF EXISTS settlement_files;
@@ -0,0 +1,69 @@
+CREATE TABLE IF NOT EXISTS settlement_files (
+  id UUID PRIMARY KEY,
+  file_name TEXT NOT NULL,
+  format TEXT NOT NULL,
+  -- The same file is never imported twice.
+  sha256 TEXT NOT NULL UNIQUE,
+  period_start TIMESTAMPTZ,
+  period_end TIMESTAMPTZ,
+  line_count INT NOT NULL DEFAULT 0,
+  matched_count INT NOT NULL DEFAULT 0,
+  break_count INT NOT NULL DEFAULT 0,
+  imported_by UUID REFERENCES users(id),
+  imported_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT settlement_files_format_ck CHECK (format IN ('CSV', 'CAMT053'))
+);
+
+CREATE TABLE IF NOT EXISTS settlement_lines (
+  id UUID PRIMARY KEY,
+  file_id UUID NOT NULL REFERENCES settlement_files(id),
+  line_no INT NOT NULL,
+  gateway_ref TEXT NOT NULL,
+  amount_micros BIGINT NOT NULL,
+  currency TEXT NOT NULL,
+  direction TEXT NOT NULL,
+  booked_at TIMESTAMPTZ,
+  description TEXT,
+  -- Set whenever gateway_ref resolves to a payout, even if the line is a break.
+  payout_id UUID REFERENCES payouts(id),
+  match_status TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT settlement_lines_file_line_uq UNIQUE (file_id, line_no),
+  CONSTRAINT settlement_lines_direction_ck CHECK (direction IN ('debit', 'credit')),
+  CONSTRAINT settlement_lines_match_status_ck CHECK (match_status IN ('MATCHED', 'BREAK', 'IGNORED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlement_lines_payout
+  ON settlement_lines (payout_id)
+  WHERE payout_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS reconciliation_breaks (
+  id UUID PRIMARY KEY,
+  break_type TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'OPEN',
+  payout_id UUID REFERENCES payouts(id),
+  line_id UUID REFERENCES settlement_lines(id),
+  file_id UUID REFERENCES settlement_files(id),
+  gateway_ref TEXT,
+  currency TEXT,
+  expected_micros BIGINT,
+  actual_micros BIGINT,
+  details TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  cleared_by UUID REFERENCES users(id),
+  cleared_at TIMESTAMPTZ,
+  resolution_note TEXT,
+  CONSTRAINT reconciliation_breaks_type_ck CHECK (break_type IN ('PAID_BUT_FAILED', 'COMPLETED_BUT_MISSING', 'AMOUNT_MISMATCH', 'UNKNOWN_DEBIT')),
+  CONSTRAINT reconciliation_breaks_status_ck CHECK (status IN ('OPEN', 'CLEARED'))
+);
+
+-- A line raises each kind of break once, and a payout is reported missing once;
+-- re-running the checks never duplicates a break, even after it is cleared.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_breaks_line
+  ON reconciliation_breaks (break_type, line_id)
+  WHERE line_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_breaks_missing
+  ON reconciliation_breaks (payout_id)
+  WHERE break_type = 'COMPLETED_BUT_MISSING';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_breaks_status
+  ON reconciliation_breaks (status, created_at);
@@ -0,0 +1,25 @@
+-- One row per ledger invariant violation. A finding stays OPEN while every
+-- reconciliation run keeps detecting it and is resolved by the first run that
+-- no longer does.
+CREATE TABLE IF NOT EXISTS reconciliation_findings (
+  id UUID PRIMARY KEY,
+  finding_type TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'OPEN',
+  -- The account for balance and locked-funds findings, the transaction for
+  -- unbalanced-entry findings.
+  subject_id UUID NOT NULL,
+  currency TEXT NOT NULL,
+  expected_micros BIGINT NOT NULL,
+  actual_micros BIGINT NOT NULL,
+  first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  resolved_at TIMESTAMPTZ,
+  CONSTRAINT reconciliation_findings_type_ck CHECK (finding_type IN ('ACCOUNT_BALANCE_DRIFT', 'LOCKED_FUNDS_DRIFT', 'TRANSACTION_UNBALANCED')),
+  CONSTRAINT reconciliation_findings_status_ck CHECK (status IN ('OPEN', 'RESOLVED'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_findings_open
+  ON reconciliation_findings (finding_type, subject_id, currency)
+  WHERE status = 'OPEN';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_findings_status
+  ON reconciliation_findings (status, first_seen_at);
@@ -0,0 +1,24 @@
+-- One row per reconciliation run, kept as evidence that the checks ran and
+-- what they found.
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+  id UUID PRIMARY KEY,
+  trigger TEXT NOT NULL,
+  scope TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'RUNNING',
+  triggered_by UUID REFERENCES users(id),
+  started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  finished_at TIMESTAMPTZ,
+  currency_totals JSONB NOT NULL DEFAULT '[]'::jsonb,
+  findings JSONB NOT NULL DEFAULT '[]'::jsonb,
+  finding_count INT NOT NULL DEFAULT 0,
+  error TEXT,
+  CONSTRAINT reconciliation_runs_trigger_ck CHECK (trigger IN ('SCHEDULED', 'MANUAL')),
+  CONSTRAINT reconciliation_runs_status_ck CHECK (status IN ('RUNNING', 'PASSED', 'FAILED', 'ERROR'))
+);
+
+-- At most one run at a time, across all instances.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_runs_running
+  ON reconciliation_runs (status)
+  WHERE status = 'RUNNING';
+CREATE INDEX IF NOT EXISTS idx_reconciliation_runs_started_at
+  ON reconciliation_runs (started_at DESC);
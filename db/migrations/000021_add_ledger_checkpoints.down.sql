@@ -0,0 +1,21 @@
+ALTER TABLE reconciliation_runs DROP CONSTRAINT IF EXISTS reconciliation_runs_scope_ck;
+
+DELETE FROM reconciliation_findings WHERE finding_type = 'SEALED_PERIOD_CHANGED';
+DROP INDEX IF EXISTS uq_reconciliation_findings_open;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_findings_open
+  ON reconciliation_findings (finding_type, subject_id, currency)
+  WHERE status = 'OPEN';
+ALTER TABLE reconciliation_findings DROP CONSTRAINT IF EXISTS reconciliation_findings_type_ck;
+ALTER TABLE reconciliation_findings
+  ADD CONSTRAINT reconciliation_findings_type_ck CHECK (finding_type IN ('ACCOUNT_BALANCE_DRIFT', 'LOCKED_FUNDS_DRIFT', 'TRANSACTION_UNBALANCED'));
+ALTER TABLE reconciliation_findings DROP COLUMN IF EXISTS period;
+
+DROP TRIGGER IF EXISTS trg_entries_mark_dirty_days ON entries;
+DROP FUNCTION IF EXISTS mark_ledger_dirty_days();
+DROP TABLE IF EXISTS ledger_month_seals;
+DROP TABLE IF EXISTS ledger_dirty_days;
+DROP TABLE IF EXISTS ledger_account_totals;
+DROP TABLE IF EXISTS ledger_day_totals;
+DROP TABLE IF EXISTS ledger_checkpoint;
+DROP INDEX IF EXISTS idx_entries_account_created;
+DROP INDEX IF EXISTS idx_entries_transaction_id;
@@ -0,0 +1,84 @@
+-- Lookups by transaction and by account no longer scan every partition.
+CREATE INDEX IF NOT EXISTS idx_entries_transaction_id ON entries (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_entries_account_created ON entries (account_id, created_at);
+
+-- Incremental reconciliation folds each closed UTC day of entries into the
+-- totals below once, then only scans entries created since.
+CREATE TABLE IF NOT EXISTS ledger_checkpoint (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  -- The first day folded in: the day of the earliest entry when the
+  -- checkpoint was created.
+  started_from DATE NOT NULL,
+  -- Every day before this date is included in the totals.
+  checkpointed_until DATE NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_day_totals (
+  day DATE NOT NULL,
+  currency TEXT NOT NULL,
+  entry_count BIGINT NOT NULL,
+  debit_micros BIGINT NOT NULL,
+  credit_micros BIGINT NOT NULL,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (day, currency)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_account_totals (
+  account_id UUID PRIMARY KEY REFERENCES accounts(id),
+  net_micros BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Days already checkpointed that received entries afterwards. The next run
+-- recomputes them.
+CREATE TABLE IF NOT EXISTS ledger_dirty_days (
+  day DATE PRIMARY KEY,
+  marked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Closed months, sealed with a checksum over their entries.
+CREATE TABLE IF NOT EXISTS ledger_month_seals (
+  month DATE PRIMARY KEY,
+  entry_count BIGINT NOT NULL,
+  checksum TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'SEALED',
+  sealed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  verified_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT ledger_month_seals_status_ck CHECK (status IN ('SEALED', 'BROKEN'))
+);
+
+CREATE OR REPLACE FUNCTION mark_ledger_dirty_days() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  INSERT INTO ledger_dirty_days (day)
+  SELECT DISTINCT (n.created_at AT TIME ZONE 'UTC')::date
+  FROM new_entries n
+  CROSS JOIN ledger_checkpoint c
+  WHERE (n.created_at AT TIME ZONE 'UTC')::date < c.checkpointed_until
+  ON CONFLICT (day) DO NOTHING;
+  RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_entries_mark_dirty_days ON entries;
+CREATE TRIGGER trg_entries_mark_dirty_days
+AFTER INSERT ON entries
+REFERENCING NEW TABLE AS new_entries
+FOR EACH STATEMENT
+EXECUTE FUNCTION mark_ledger_dirty_days();
+
+-- Findings about a sealed month carry the month in period.
+ALTER TABLE reconciliation_findings ADD COLUMN IF NOT EXISTS period TEXT NOT NULL DEFAULT '';
+ALTER TABLE reconciliation_findings DROP CONSTRAINT IF EXISTS reconciliation_findings_type_ck;
+ALTER TABLE reconciliation_findings
+  ADD CONSTRAINT reconciliation_findings_type_ck CHECK (finding_type IN ('ACCOUNT_BALANCE_DRIFT', 'LOCKED_FUNDS_DRIFT', 'TRANSACTION_UNBALANCED', 'SEALED_PERIOD_CHANGED'));
+DROP INDEX IF EXISTS uq_reconciliation_findings_open;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_reconciliation_findings_open
+  ON reconciliation_findings (finding_type, subject_id, currency, period)
+  WHERE status = 'OPEN';
+
+ALTER TABLE reconciliation_runs DROP CONSTRAINT IF EXISTS reconciliation_runs_scope_ck;
+ALTER TABLE reconciliation_runs
+  ADD CONSTRAINT reconciliation_runs_scope_ck CHECK (scope IN ('FULL', 'INCREMENTAL'));
@@ -0,0 +1,11 @@
+UPDATE ledger_month_seals SET status = 'SEALED' WHERE status = 'ARCHIVED';
+ALTER TABLE ledger_month_seals DROP CONSTRAINT IF EXISTS ledger_month_seals_status_ck;
+ALTER TABLE ledger_month_seals
+  ADD CONSTRAINT ledger_month_seals_status_ck CHECK (status IN ('SEALED', 'BROKEN'));
+
+DROP TABLE IF EXISTS entries_archived_account_totals;
+DROP TABLE IF EXISTS entries_archive_currency_totals;
+DROP TABLE IF EXISTS entries_archives;
+DROP FUNCTION IF EXISTS list_entries_default_months();
+DROP FUNCTION IF EXISTS drop_entries_partition(DATE, BIGINT);
+DROP FUNCTION IF EXISTS create_entries_partition(DATE);
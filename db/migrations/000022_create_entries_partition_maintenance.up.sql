@@ -0,0 +1,119 @@
+-- Monthly entries partitions are created, filled from entries_default and
+-- dropped by the partition maintenance worker through these functions. Each
+-- takes the same advisory lock so instances never race on the DDL.
+
+-- create_entries_partition creates the partition for the month containing
+-- p_month and moves the month's rows out of entries_default into it. It
+-- returns the number of rows moved; an existing partition is left as is.
+CREATE OR REPLACE FUNCTION create_entries_partition(p_month DATE) RETURNS BIGINT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  month_start DATE := date_trunc('month', p_month)::DATE;
+  from_ts TIMESTAMPTZ := month_start::TIMESTAMP AT TIME ZONE 'UTC';
+  to_ts TIMESTAMPTZ := (month_start + INTERVAL '1 month')::TIMESTAMP AT TIME ZONE 'UTC';
+  part_name TEXT := format('entries_y%sm%s', to_char(month_start, 'YYYY'), to_char(month_start, 'MM'));
+  moved BIGINT;
+BEGIN
+  PERFORM pg_advisory_xact_lock(hashtext('entries_partition_maintenance'));
+  IF to_regclass(part_name) IS NOT NULL THEN
+    RETURN 0;
+  END IF;
+
+  SELECT COUNT(*) INTO moved FROM entries_default WHERE created_at >= from_ts AND created_at < to_ts;
+  IF moved = 0 THEN
+    EXECUTE format('CREATE TABLE %I PARTITION OF entries FOR VALUES FROM (%L) TO (%L)', part_name, from_ts, to_ts);
+    RETURN 0;
+  END IF;
+
+  -- A partition cannot be created while the default partition holds rows
+  -- in its range. Detaching the default also drops its copy of
+  -- trg_entries_immutable, so the moved rows can be deleted from it.
+  ALTER TABLE entries DETACH PARTITION entries_default;
+  EXECUTE format('CREATE TABLE %I PARTITION OF entries FOR VALUES FROM (%L) TO (%L)', part_name, from_ts, to_ts);
+  EXECUTE format(
+    'INSERT INTO %I (id, transaction_id, account_id, amount, direction, created_at)
+     SELECT id, transaction_id, account_id, amount, direction, created_at
+     FROM entries_default WHERE created_at >= $1 AND created_at < $2',
+    part_name
+  ) USING from_ts, to_ts;
+  DELETE FROM entries_default WHERE created_at >= from_ts AND created_at < to_ts;
+  ALTER TABLE entries ATTACH PARTITION entries_default DEFAULT;
+  RETURN moved;
+END;
+$$;
+
+-- drop_entries_partition detaches and drops the partition for the month
+-- containing p_month once it has been archived. It fails, leaving the
+-- partition attached, unless the partition still holds exactly
+-- p_archived_rows rows once detached, so no row written after the export
+-- is lost.
+CREATE OR REPLACE FUNCTION drop_entries_partition(p_month DATE, p_archived_rows BIGINT) RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  month_start DATE := date_trunc('month', p_month)::DATE;
+  part_name TEXT := format('entries_y%sm%s', to_char(month_start, 'YYYY'), to_char(month_start, 'MM'));
+  row_count BIGINT;
+BEGIN
+  PERFORM pg_advisory_xact_lock(hashtext('entries_partition_maintenance'));
+  IF to_regclass(part_name) IS NULL THEN
+    RETURN;
+  END IF;
+  EXECUTE format('ALTER TABLE entries DETACH PARTITION %I', part_name);
+  EXECUTE format('SELECT COUNT(*) FROM %I', part_name) INTO row_count;
+  IF row_count <> p_archived_rows THEN
+    RAISE EXCEPTION 'partition % holds % rows but % were archived', part_name, row_count, p_archived_rows;
+  END IF;
+  EXECUTE format('DROP TABLE %I', part_name);
+END;
+$$;
+
+-- list_entries_default_months returns the months that have rows in
+-- entries_default.
+CREATE OR REPLACE FUNCTION list_entries_default_months() RETURNS SETOF DATE
+LANGUAGE sql STABLE
+AS $$
+  SELECT DISTINCT date_trunc('month', created_at AT TIME ZONE 'UTC')::DATE
+  FROM entries_default
+  ORDER BY 1;
+$$;
+
+-- One row per partition exported to cold storage. The row is claimed as
+-- EXPORTING before the file is written and becomes ARCHIVED in the same
+-- transaction that drops the partition.
+CREATE TABLE IF NOT EXISTS entries_archives (
+  id UUID PRIMARY KEY,
+  partition_name TEXT NOT NULL UNIQUE,
+  period_start TIMESTAMPTZ NOT NULL,
+  period_end TIMESTAMPTZ NOT NULL,
+  status TEXT NOT NULL DEFAULT 'EXPORTING',
+  row_count BIGINT NOT NULL DEFAULT 0,
+  file_path TEXT,
+  manifest_path TEXT,
+  sha256 TEXT,
+  claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  archived_at TIMESTAMPTZ,
+  CONSTRAINT entries_archives_status_ck CHECK (status IN ('EXPORTING', 'ARCHIVED'))
+);
+
+-- Totals of the archived entries, so full reconciliation still accounts for
+-- rows no longer in the entries table.
+CREATE TABLE IF NOT EXISTS entries_archive_currency_totals (
+  archive_id UUID NOT NULL REFERENCES entries_archives(id),
+  currency TEXT NOT NULL,
+  entry_count BIGINT NOT NULL,
+  debit_micros BIGINT NOT NULL,
+  credit_micros BIGINT NOT NULL,
+  PRIMARY KEY (archive_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS entries_archived_account_totals (
+  account_id UUID PRIMARY KEY REFERENCES accounts(id),
+  net_micros BIGINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE ledger_month_seals DROP CONSTRAINT IF EXISTS ledger_month_seals_status_ck;
+ALTER TABLE ledger_month_seals
+  ADD CONSTRAINT ledger_month_seals_status_ck CHECK (status IN ('SEALED', 'BROKEN', 'ARCHIVED'));
@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS trg_audit_anchors_immutable ON audit_anchors;
+DROP TRIGGER IF EXISTS trg_audit_log_chain ON audit_log;
+DROP INDEX IF EXISTS idx_audit_log_chain_seq;
+ALTER TABLE audit_log
+  DROP COLUMN IF EXISTS record_hash,
+  DROP COLUMN IF EXISTS prev_hash,
+  DROP COLUMN IF EXISTS chain_seq;
+
+DROP FUNCTION IF EXISTS audit_log_chain();
+DROP FUNCTION IF EXISTS audit_record_hash(BIGINT, BIGINT, TEXT, UUID, UUID, TEXT, TEXT, TEXT, JSONB, TIMESTAMPTZ, TEXT);
+DROP FUNCTION IF EXISTS audit_hash_field(TEXT);
+DROP TABLE IF EXISTS audit_anchors;
+DROP TABLE IF EXISTS audit_chain_head;
@@ -0,0 +1,133 @@
+-- Every audit record carries the SHA-256 of its content and of the record
+-- before it, so rewriting history breaks the chain. The canonical encoding
+-- below must stay in sync with auditRecordHash in internal/service.
+ALTER TABLE audit_log
+  ADD COLUMN IF NOT EXISTS chain_seq BIGINT,
+  ADD COLUMN IF NOT EXISTS prev_hash TEXT,
+  ADD COLUMN IF NOT EXISTS record_hash TEXT;
+
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+  id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+  last_seq BIGINT NOT NULL,
+  last_hash TEXT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS audit_anchors (
+  id BIGSERIAL PRIMARY KEY,
+  chain_seq BIGINT NOT NULL UNIQUE,
+  record_hash TEXT NOT NULL,
+  file_path TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE OR REPLACE FUNCTION audit_hash_field(v TEXT) RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE WHEN v IS NULL THEN '-' ELSE octet_length(v)::text || ':' || v END;
+$$;
+
+CREATE OR REPLACE FUNCTION audit_record_hash(
+  p_chain_seq BIGINT,
+  p_id BIGINT,
+  p_entity_type TEXT,
+  p_entity_id UUID,
+  p_actor_id UUID,
+  p_action TEXT,
+  p_prev_state TEXT,
+  p_next_state TEXT,
+  p_metadata JSONB,
+  p_created_at TIMESTAMPTZ,
+  p_prev_hash TEXT
+) RETURNS TEXT
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT encode(sha256(convert_to(
+    audit_hash_field(p_chain_seq::text) ||
+    audit_hash_field(p_id::text) ||
+    audit_hash_field(p_entity_type) ||
+    audit_hash_field(p_entity_id::text) ||
+    audit_hash_field(p_actor_id::text) ||
+    audit_hash_field(p_action) ||
+    audit_hash_field(p_prev_state) ||
+    audit_hash_field(p_next_state) ||
+    audit_hash_field(p_metadata::text) ||
+    audit_hash_field((extract(epoch FROM p_created_at) * 1000000)::bigint::text) ||
+    audit_hash_field(p_prev_hash),
+    'UTF8')), 'hex');
+$$;
+
+-- Appending locks the chain head until the writing transaction ends, so
+-- audit records are chained in commit order without gaps.
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_seq BIGINT;
+  v_prev TEXT;
+BEGIN
+  INSERT INTO audit_chain_head (id, last_seq, last_hash)
+  VALUES (TRUE, 0, repeat('0', 64))
+  ON CONFLICT (id) DO NOTHING;
+
+  SELECT last_seq, last_hash INTO v_seq, v_prev
+  FROM audit_chain_head
+  WHERE id
+  FOR UPDATE;
+
+  NEW.chain_seq := v_seq + 1;
+  NEW.prev_hash := v_prev;
+  NEW.record_hash := audit_record_hash(
+    NEW.chain_seq, NEW.id, NEW.entity_type, NEW.entity_id, NEW.actor_id, NEW.action,
+    NEW.prev_state, NEW.next_state, NEW.metadata, NEW.created_at, NEW.prev_hash);
+
+  UPDATE audit_chain_head
+  SET last_seq = NEW.chain_seq, last_hash = NEW.record_hash, updated_at = NOW()
+  WHERE id;
+  RETURN NEW;
+END;
+$$;
+
+-- Chain the existing history in id order.
+ALTER TABLE audit_log DISABLE TRIGGER trg_audit_log_immutable;
+DO $$
+DECLARE
+  r RECORD;
+  v_seq BIGINT := 0;
+  v_prev TEXT := repeat('0', 64);
+  v_hash TEXT;
+BEGIN
+  FOR r IN SELECT * FROM audit_log ORDER BY id LOOP
+    v_seq := v_seq + 1;
+    v_hash := audit_record_hash(
+      v_seq, r.id, r.entity_type, r.entity_id, r.actor_id, r.action,
+      r.prev_state, r.next_state, r.metadata, r.created_at, v_prev);
+    UPDATE audit_log SET chain_seq = v_seq, prev_hash = v_prev, record_hash = v_hash WHERE id = r.id;
+    v_prev := v_hash;
+  END LOOP;
+
+  INSERT INTO audit_chain_head (id, last_seq, last_hash)
+  VALUES (TRUE, v_seq, v_prev)
+  ON CONFLICT (id) DO UPDATE SET last_seq = EXCLUDED.last_seq, last_hash = EXCLUDED.last_hash, updated_at = NOW();
+END $$;
+ALTER TABLE audit_log ENABLE TRIGGER trg_audit_log_immutable;
+
+ALTER TABLE audit_log
+  ALTER COLUMN chain_seq SET NOT NULL,
+  ALTER COLUMN prev_hash SET NOT NULL,
+  ALTER COLUMN record_hash SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_chain_seq ON audit_log (chain_seq);
+
+DROP TRIGGER IF EXISTS trg_audit_log_chain ON audit_log;
+CREATE TRIGGER trg_audit_log_chain
+BEFORE INSERT ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_chain();
+
+DROP TRIGGER IF EXISTS trg_audit_anchors_immutable ON audit_anchors;
+CREATE TRIGGER trg_audit_anchors_immutable
+BEFORE UPDATE OR DELETE ON audit_anchors
+FOR EACH ROW
+EXECUTE FUNCTION immutable_record_guard();
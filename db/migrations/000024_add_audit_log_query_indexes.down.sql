@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_payouts_transaction_id;
+DROP INDEX IF EXISTS idx_audit_log_actor;
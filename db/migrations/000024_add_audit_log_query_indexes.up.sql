@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, created_at DESC) WHERE actor_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payouts_transaction_id ON payouts (transaction_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_accounts_status;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_closed_empty_ck;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_status_ck;
+ALTER TABLE accounts
+  DROP COLUMN IF EXISTS status_changed_at,
+  DROP COLUMN IF EXISTS status_changed_by,
+  DROP COLUMN IF EXISTS status_reason,
+  DROP COLUMN IF EXISTS status;
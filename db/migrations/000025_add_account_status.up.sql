@@ -0,0 +1,29 @@
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'ACTIVE',
+  ADD COLUMN IF NOT EXISTS status_reason TEXT,
+  ADD COLUMN IF NOT EXISTS status_changed_by UUID REFERENCES users(id),
+  ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'accounts_status_ck'
+  ) THEN
+    ALTER TABLE accounts
+      ADD CONSTRAINT accounts_status_ck CHECK (status IN ('ACTIVE', 'FROZEN_DEBITS', 'FROZEN_ALL', 'CLOSED'));
+  END IF;
+END $$;
+
+-- A closed account holds nothing: its balance was zero or swept, and no
+-- payout may still hold funds on it.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'accounts_closed_empty_ck'
+  ) THEN
+    ALTER TABLE accounts
+      ADD CONSTRAINT accounts_closed_empty_ck CHECK (status <> 'CLOSED' OR (balance = 0 AND locked_micros = 0));
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_status ON accounts (status) WHERE status <> 'ACTIVE';
@@ -0,0 +1,4 @@
+-- Backfilled opening balances are ledger records: entries and audit_log are
+-- append-only, so they stay, and so do the equity accounts that fund them
+-- and the transaction type they use.
+SELECT 1;
@@ -0,0 +1,64 @@
+-- Equity accounts fund opening balances, one per currency, owned by the
+-- system user like the liquidity accounts.
+INSERT INTO accounts (id, user_id, currency, balance, created_at)
+VALUES
+('55555555-5555-5555-5555-555555555555', '11111111-1111-1111-1111-111111111111', 'USD', 0, NOW()),
+('66666666-6666-6666-6666-666666666666', '11111111-1111-1111-1111-111111111111', 'EUR', 0, NOW()),
+('77777777-7777-7777-7777-777777777777', '11111111-1111-1111-1111-111111111111', 'GBP', 0, NOW())
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_ck;
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_type_ck CHECK (type IN ('transfer', 'exchange', 'payout', 'deposit', 'opening_balance'));
+
+-- Accounts used to be created with a client-supplied balance and no ledger
+-- entries. Post the part of each balance its entries do not explain as an
+-- opening balance against the equity account of its currency. Entries are
+-- dated now so sealed months are left untouched.
+DO $$
+DECLARE
+  r RECORD;
+  v_tx UUID;
+  v_equity UUID;
+  v_metadata JSONB;
+BEGIN
+  FOR r IN
+    SELECT a.id, a.currency, a.balance - COALESCE(e.net_amount, 0) - COALESCE(x.net_micros, 0) AS drift
+    FROM accounts a
+    LEFT JOIN (
+      SELECT account_id, SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END) AS net_amount
+      FROM entries
+      GROUP BY account_id
+    ) e ON e.account_id = a.id
+    LEFT JOIN entries_archived_account_totals x ON x.account_id = a.id
+    WHERE a.user_id <> '11111111-1111-1111-1111-111111111111'
+      AND a.balance <> COALESCE(e.net_amount, 0) + COALESCE(x.net_micros, 0)
+    ORDER BY a.id
+  LOOP
+    v_tx := gen_random_uuid();
+    v_equity := CASE r.currency
+      WHEN 'USD' THEN '55555555-5555-5555-5555-555555555555'::uuid
+      WHEN 'EUR' THEN '66666666-6666-6666-6666-666666666666'::uuid
+      WHEN 'GBP' THEN '77777777-7777-7777-7777-777777777777'::uuid
+    END;
+    v_metadata := jsonb_build_object(
+      'kind', 'opening_balance',
+      'account_id', r.id,
+      'equity_account_id', v_equity,
+      'backfill', true
+    );
+
+    INSERT INTO transactions (id, amount, currency, type, status, reference_id, metadata)
+    VALUES (v_tx, ABS(r.drift), r.currency, 'opening_balance', 'COMPLETED', 'opening-balance:' || r.id, v_metadata);
+
+    INSERT INTO entries (id, transaction_id, account_id, amount, direction)
+    VALUES
+      (gen_random_uuid(), v_tx, r.id, ABS(r.drift), CASE WHEN r.drift > 0 THEN 'credit' ELSE 'debit' END),
+      (gen_random_uuid(), v_tx, v_equity, ABS(r.drift), CASE WHEN r.drift > 0 THEN 'debit' ELSE 'credit' END);
+
+    UPDATE accounts SET balance = balance - r.drift WHERE id = v_equity;
+
+    INSERT INTO audit_log (entity_type, entity_id, action, prev_state, next_state, metadata)
+    VALUES ('transaction', v_tx, 'opening_balance_backfilled', NULL, 'COMPLETED', v_metadata);
+  END LOOP;
+END $$;
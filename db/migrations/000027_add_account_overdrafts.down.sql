@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_accounts_overdrawn;
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_within_overdraft_ck;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_overdraft_terms_ck;
+
+ALTER TABLE accounts
+  DROP COLUMN IF EXISTS overdraft_interest_bps,
+  DROP COLUMN IF EXISTS overdraft_limit_micros;
@@ -0,0 +1,41 @@
+ALTER TABLE accounts
+  ADD COLUMN IF NOT EXISTS overdraft_limit_micros BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS overdraft_interest_bps INTEGER NOT NULL DEFAULT 0;
+
+-- Customer accounts could never go negative before; any that did keep
+-- their current overdraft as an approved limit so the check below holds.
+UPDATE accounts
+SET overdraft_limit_micros = -balance
+WHERE balance < 0
+  AND user_id <> '11111111-1111-1111-1111-111111111111';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'accounts_overdraft_terms_ck'
+  ) THEN
+    ALTER TABLE accounts
+      ADD CONSTRAINT accounts_overdraft_terms_ck CHECK (overdraft_limit_micros >= 0 AND overdraft_interest_bps BETWEEN 0 AND 10000);
+  END IF;
+END $$;
+
+-- Customer balances may only go negative within an approved overdraft.
+-- System accounts carry the other side of deposits, payouts and opening
+-- balances and are unconstrained.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'accounts_balance_within_overdraft_ck'
+  ) THEN
+    ALTER TABLE accounts
+      ADD CONSTRAINT accounts_balance_within_overdraft_ck CHECK (
+        user_id = '11111111-1111-1111-1111-111111111111'::uuid OR balance >= -overdraft_limit_micros
+      );
+  END IF;
+END $$;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_ck;
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_type_ck CHECK (type IN ('transfer', 'exchange', 'payout', 'deposit', 'opening_balance', 'fee'));
+
+CREATE INDEX IF NOT EXISTS idx_accounts_overdrawn ON accounts (id) WHERE balance < 0 AND overdraft_interest_bps > 0;
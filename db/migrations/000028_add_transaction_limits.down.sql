@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS limit_usage;
+DROP TABLE IF EXISTS user_limit_overrides;
+DROP TABLE IF EXISTS transaction_limits;
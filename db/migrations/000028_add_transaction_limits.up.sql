@@ -0,0 +1,53 @@
+-- Default limits per transaction type and currency. A NULL column means no
+-- cap for that window; a type/currency pair without a row is unlimited.
+CREATE TABLE IF NOT EXISTS transaction_limits (
+  txn_type TEXT NOT NULL,
+  currency VARCHAR(3) NOT NULL,
+  per_transaction_max_micros BIGINT,
+  daily_max_micros BIGINT,
+  monthly_max_micros BIGINT,
+  updated_by UUID REFERENCES users(id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (txn_type, currency),
+  CONSTRAINT transaction_limits_type_ck CHECK (txn_type IN ('transfer', 'exchange', 'payout')),
+  CONSTRAINT transaction_limits_positive_ck CHECK (
+    (per_transaction_max_micros IS NULL OR per_transaction_max_micros > 0)
+    AND (daily_max_micros IS NULL OR daily_max_micros > 0)
+    AND (monthly_max_micros IS NULL OR monthly_max_micros > 0)
+  )
+);
+
+-- A user's override replaces the default for that type and currency as a
+-- whole, so an override can both raise and lift individual caps.
+CREATE TABLE IF NOT EXISTS user_limit_overrides (
+  user_id UUID NOT NULL REFERENCES users(id),
+  txn_type TEXT NOT NULL,
+  currency VARCHAR(3) NOT NULL,
+  per_transaction_max_micros BIGINT,
+  daily_max_micros BIGINT,
+  monthly_max_micros BIGINT,
+  note TEXT,
+  updated_by UUID REFERENCES users(id),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, txn_type, currency),
+  CONSTRAINT user_limit_overrides_type_ck CHECK (txn_type IN ('transfer', 'exchange', 'payout')),
+  CONSTRAINT user_limit_overrides_positive_ck CHECK (
+    (per_transaction_max_micros IS NULL OR per_transaction_max_micros > 0)
+    AND (daily_max_micros IS NULL OR daily_max_micros > 0)
+    AND (monthly_max_micros IS NULL OR monthly_max_micros > 0)
+  )
+);
+
+-- Usage counters used when Redis is unavailable. period_start is the UTC day
+-- or the first day of the UTC month.
+CREATE TABLE IF NOT EXISTS limit_usage (
+  user_id UUID NOT NULL REFERENCES users(id),
+  txn_type TEXT NOT NULL,
+  currency VARCHAR(3) NOT NULL,
+  period TEXT NOT NULL,
+  period_start DATE NOT NULL,
+  used_micros BIGINT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, txn_type, currency, period, period_start),
+  CONSTRAINT limit_usage_period_ck CHECK (period IN ('day', 'month'))
+);
@@ -0,0 +1,8 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_kyc_tier_verified_ck;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_kyc_status_ck;
+ALTER TABLE users
+  DROP COLUMN IF EXISTS kyc_updated_at,
+  DROP COLUMN IF EXISTS kyc_provider_ref,
+  DROP COLUMN IF EXISTS kyc_tier,
+  DROP COLUMN IF EXISTS kyc_status;
+DROP TABLE IF EXISTS kyc_tiers;
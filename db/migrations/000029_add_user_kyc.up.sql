@@ -0,0 +1,49 @@
+-- KYC tiers decide what a user may do. allowed_currencies are the currencies
+-- accounts may be opened in; max_balance_micros caps any one account's
+-- balance in its own currency (NULL = no cap).
+CREATE TABLE IF NOT EXISTS kyc_tiers (
+  tier SMALLINT PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  allowed_currencies TEXT[] NOT NULL,
+  payouts_allowed BOOLEAN NOT NULL DEFAULT FALSE,
+  max_balance_micros BIGINT,
+  CONSTRAINT kyc_tiers_tier_ck CHECK (tier >= 0),
+  CONSTRAINT kyc_tiers_max_balance_ck CHECK (max_balance_micros IS NULL OR max_balance_micros > 0)
+);
+
+INSERT INTO kyc_tiers (tier, name, allowed_currencies, payouts_allowed, max_balance_micros) VALUES
+  (0, 'UNVERIFIED', ARRAY['USD'], FALSE, 1000000000),
+  (1, 'BASIC', ARRAY['USD', 'EUR', 'GBP'], TRUE, 10000000000),
+  (2, 'FULL', ARRAY['USD', 'EUR', 'GBP'], TRUE, NULL)
+ON CONFLICT (tier) DO NOTHING;
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS kyc_status TEXT NOT NULL DEFAULT 'NONE',
+  ADD COLUMN IF NOT EXISTS kyc_tier SMALLINT NOT NULL DEFAULT 0 REFERENCES kyc_tiers(tier),
+  ADD COLUMN IF NOT EXISTS kyc_provider_ref TEXT,
+  ADD COLUMN IF NOT EXISTS kyc_updated_at TIMESTAMPTZ;
+
+-- Users who already existed keep what they could do before tiers existed.
+UPDATE users SET kyc_status = 'VERIFIED', kyc_tier = 2, kyc_updated_at = NOW()
+WHERE kyc_updated_at IS NULL;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'users_kyc_status_ck'
+  ) THEN
+    ALTER TABLE users
+      ADD CONSTRAINT users_kyc_status_ck CHECK (kyc_status IN ('NONE', 'PENDING', 'VERIFIED', 'REJECTED'));
+  END IF;
+END $$;
+
+-- Only a verified user can hold a tier above the default.
+DO $$
+BEGIN
+  IF NOT EXISTS (
+    SELECT 1 FROM pg_constraint WHERE conname = 'users_kyc_tier_verified_ck'
+  ) THEN
+    ALTER TABLE users
+      ADD CONSTRAINT users_kyc_tier_verified_ck CHECK (kyc_tier = 0 OR kyc_status = 'VERIFIED');
+  END IF;
+END $$;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS payout_screenings;
+DROP INDEX IF EXISTS idx_payouts_screening_hold;
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'PROCESSING', 'SUBMITTED', 'COMPLETED', 'FAILED', 'REJECTED', 'MANUAL_REVIEW'));
+
+DROP TABLE IF EXISTS sanctions_entries;
+DROP TABLE IF EXISTS sanctions_list_loads;
@@ -0,0 +1,50 @@
+-- Each load replaces the entries of one list source; the load row records
+-- what was loaded, by whom and a checksum of the file.
+CREATE TABLE IF NOT EXISTS sanctions_list_loads (
+  id BIGSERIAL PRIMARY KEY,
+  source TEXT NOT NULL,
+  entry_count INTEGER NOT NULL,
+  checksum TEXT NOT NULL,
+  loaded_by TEXT NOT NULL,
+  loaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT sanctions_list_loads_source_ck CHECK (source IN ('OFAC_SDN', 'EU_CONSOLIDATED'))
+);
+
+CREATE TABLE IF NOT EXISTS sanctions_entries (
+  id BIGSERIAL PRIMARY KEY,
+  load_id BIGINT NOT NULL REFERENCES sanctions_list_loads(id),
+  source TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  name TEXT NOT NULL,
+  entry_type TEXT NOT NULL DEFAULT '',
+  programs TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_sanctions_entries_source ON sanctions_entries (source);
+
+ALTER TABLE payouts DROP CONSTRAINT IF EXISTS payouts_status_ck;
+ALTER TABLE payouts
+  ADD CONSTRAINT payouts_status_ck CHECK (status IN ('AWAITING_APPROVAL', 'SCREENING_HOLD', 'PENDING', 'PROCESSING', 'SUBMITTED', 'COMPLETED', 'FAILED', 'REJECTED', 'MANUAL_REVIEW'));
+
+CREATE INDEX IF NOT EXISTS idx_payouts_screening_hold
+  ON payouts (created_at)
+  WHERE status = 'SCREENING_HOLD';
+
+-- The match that held a payout and how it was resolved. release_status is
+-- where a cleared payout goes next.
+CREATE TABLE IF NOT EXISTS payout_screenings (
+  payout_id UUID PRIMARY KEY REFERENCES payouts(id),
+  screened_name TEXT NOT NULL,
+  list_source TEXT NOT NULL,
+  list_entry_id TEXT NOT NULL,
+  matched_name TEXT NOT NULL,
+  score DOUBLE PRECISION NOT NULL,
+  release_status TEXT NOT NULL,
+  decision TEXT,
+  decided_by UUID REFERENCES users(id),
+  decided_at TIMESTAMPTZ,
+  reason TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT payout_screenings_release_status_ck CHECK (release_status IN ('PENDING', 'AWAITING_APPROVAL')),
+  CONSTRAINT payout_screenings_decision_ck CHECK (decision IS NULL OR decision IN ('CLEARED', 'CONFIRMED'))
+);
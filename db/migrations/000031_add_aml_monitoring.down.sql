@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS aml_alerts;
+DROP TABLE IF EXISTS aml_cases;
+DROP TABLE IF EXISTS aml_observations;
+DROP TABLE IF EXISTS aml_rules;
@@ -0,0 +1,90 @@
+-- Monitoring rules. The rule kinds are fixed in code; their parameters and
+-- whether they run are configured here. amount_micros is compared against
+-- movement amounts in the movement's own currency. updated_by carries no
+-- foreign key so the seeded rules survive user cleanup.
+CREATE TABLE IF NOT EXISTS aml_rules (
+  code TEXT PRIMARY KEY,
+  description TEXT NOT NULL,
+  enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  window_seconds INTEGER NOT NULL,
+  amount_micros BIGINT NOT NULL,
+  min_count INTEGER NOT NULL DEFAULT 1,
+  ratio_bps INTEGER NOT NULL DEFAULT 0,
+  severity TEXT NOT NULL DEFAULT 'MEDIUM',
+  updated_by UUID,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT aml_rules_code_ck CHECK (code IN ('STRUCTURING', 'RAPID_MOVEMENT', 'FX_ROUND_TRIP')),
+  CONSTRAINT aml_rules_window_ck CHECK (window_seconds > 0),
+  CONSTRAINT aml_rules_amount_ck CHECK (amount_micros > 0),
+  CONSTRAINT aml_rules_min_count_ck CHECK (min_count > 0),
+  CONSTRAINT aml_rules_ratio_ck CHECK (ratio_bps BETWEEN 0 AND 10000),
+  CONSTRAINT aml_rules_severity_ck CHECK (severity IN ('LOW', 'MEDIUM', 'HIGH'))
+);
+
+INSERT INTO aml_rules (code, description, window_seconds, amount_micros, min_count, ratio_bps, severity) VALUES
+  ('STRUCTURING', 'Repeated movements just below the reporting threshold', 86400, 10000000000, 3, 1000, 'MEDIUM'),
+  ('RAPID_MOVEMENT', 'Funds credited and moved out again within the window', 86400, 5000000000, 1, 9000, 'HIGH'),
+  ('FX_ROUND_TRIP', 'Exchange into a currency and back again within the window', 86400, 1000000000, 1, 0, 'MEDIUM')
+ON CONFLICT (code) DO NOTHING;
+
+-- One row per account leg of a completed transaction, taken from the outbox.
+-- counter_currency is the other side of an exchange.
+CREATE TABLE IF NOT EXISTS aml_observations (
+  id BIGSERIAL PRIMARY KEY,
+  event_id UUID NOT NULL,
+  event_type TEXT NOT NULL,
+  transaction_id UUID NOT NULL,
+  account_id UUID NOT NULL REFERENCES accounts(id),
+  user_id UUID NOT NULL REFERENCES users(id),
+  direction TEXT NOT NULL,
+  amount_micros BIGINT NOT NULL,
+  currency VARCHAR(3) NOT NULL,
+  counter_currency VARCHAR(3),
+  occurred_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT aml_observations_direction_ck CHECK (direction IN ('IN', 'OUT')),
+  CONSTRAINT aml_observations_event_leg_uniq UNIQUE (event_id, account_id, direction)
+);
+
+CREATE INDEX IF NOT EXISTS idx_aml_observations_account_time ON aml_observations (account_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_aml_observations_user_time ON aml_observations (user_id, occurred_at);
+
+-- Cases group alerts per user. A user has at most one case that is not
+-- closed; new alerts attach to it.
+CREATE TABLE IF NOT EXISTS aml_cases (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  status TEXT NOT NULL DEFAULT 'OPEN',
+  assigned_to UUID REFERENCES users(id),
+  resolution TEXT,
+  note TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  closed_at TIMESTAMPTZ,
+  CONSTRAINT aml_cases_status_ck CHECK (status IN ('OPEN', 'INVESTIGATING', 'ESCALATED', 'CLOSED')),
+  CONSTRAINT aml_cases_resolution_ck CHECK (
+    (status = 'CLOSED' AND resolution IN ('NO_ACTION', 'REPORTED'))
+    OR (status <> 'CLOSED' AND resolution IS NULL)
+  )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_aml_cases_user_active ON aml_cases (user_id) WHERE status <> 'CLOSED';
+CREATE INDEX IF NOT EXISTS idx_aml_cases_status ON aml_cases (status, created_at);
+
+CREATE TABLE IF NOT EXISTS aml_alerts (
+  id UUID PRIMARY KEY,
+  case_id UUID NOT NULL REFERENCES aml_cases(id),
+  rule_code TEXT NOT NULL REFERENCES aml_rules(code),
+  account_id UUID NOT NULL REFERENCES accounts(id),
+  user_id UUID NOT NULL REFERENCES users(id),
+  event_id UUID NOT NULL,
+  severity TEXT NOT NULL,
+  details JSONB NOT NULL DEFAULT '{}'::jsonb,
+  window_start TIMESTAMPTZ NOT NULL,
+  window_end TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_aml_alerts_rule_account ON aml_alerts (rule_code, account_id, window_end);
+CREATE INDEX IF NOT EXISTS idx_aml_alerts_case ON aml_alerts (case_id);
+CREATE INDEX IF NOT EXISTS idx_aml_alerts_created ON aml_alerts (created_at);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS last_login_at,
+  DROP COLUMN IF EXISTS locked_until,
+  DROP COLUMN IF EXISTS failed_login_attempts,
+  DROP COLUMN IF EXISTS password_changed_at,
+  DROP COLUMN IF EXISTS password_hash;
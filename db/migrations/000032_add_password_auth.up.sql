@@ -0,0 +1,24 @@
+-- Password credentials. password_hash is an argon2id PHC string; users
+-- created before passwords existed have none and must set one through a
+-- password reset. A user is refused until locked_until after
+-- failed_login_attempts reaches the configured maximum.
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS password_hash TEXT,
+  ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS failed_login_attempts INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+
+-- Only a SHA-256 of each reset token is stored; the token itself is sent to
+-- the user by email and is single use.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+  token_hash TEXT PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  expires_at TIMESTAMPTZ NOT NULL,
+  used_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens (user_id) WHERE used_at IS NULL;
@@ -0,0 +1,30 @@
+-- A session is one login. Its access tokens are short-lived JWTs carrying
+-- the session ID (sid) and access_jti is the only one currently valid; its
+-- refresh tokens rotate on every use and expire with the session.
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  access_jti UUID NOT NULL,
+  access_expires_at TIMESTAMPTZ NOT NULL,
+  user_agent TEXT NOT NULL DEFAULT '',
+  ip_address TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_refreshed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  revoked_reason TEXT,
+  CONSTRAINT user_sessions_revoked_reason_check CHECK ((revoked_at IS NULL) = (revoked_reason IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_active ON user_sessions (user_id, created_at DESC) WHERE revoked_at IS NULL;
+
+-- Only a SHA-256 of each refresh token is stored. A used token is kept so
+-- that presenting it again is recognised as reuse and ends the session.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+  token_hash TEXT PRIMARY KEY,
+  session_id UUID NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens (session_id);
@@ -0,0 +1,19 @@
+-- API keys let services call the API without the login flow. A key acts as
+-- its owner, narrowed to its scopes. Only a SHA-256 of each key is stored;
+-- prefix is its first characters, kept so owners can tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  name TEXT NOT NULL,
+  prefix TEXT NOT NULL,
+  key_hash TEXT NOT NULL UNIQUE,
+  scopes TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  expires_at TIMESTAMPTZ,
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  rotated_from UUID REFERENCES api_keys(id),
+  CONSTRAINT api_keys_scopes_check CHECK (cardinality(scopes) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_active ON api_keys (user_id, created_at DESC) WHERE revoked_at IS NULL;
@@ -0,0 +1,19 @@
+UPDATE api_keys k
+SET scopes = ARRAY(
+  SELECT DISTINCT CASE regexp_replace(s, ':any$', '')
+    WHEN 'accounts:create' THEN 'accounts:write'
+    WHEN 'transfers:create' THEN 'transfers:write'
+    WHEN 'payouts:create' THEN 'payouts:write'
+    WHEN 'accounts:read' THEN 'accounts:read'
+    WHEN 'beneficiaries:read' THEN 'beneficiaries:read'
+    WHEN 'beneficiaries:write' THEN 'beneficiaries:write'
+    WHEN 'payouts:read' THEN 'payouts:read'
+    ELSE 'admin'
+  END
+  FROM unnest(k.scopes) s
+);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
@@ -0,0 +1,149 @@
+-- Permissions are the names handlers check (see internal/permission); roles
+-- group them. Access tokens carry their role's permissions as scope, so a
+-- change here reaches existing tokens when they are refreshed.
+CREATE TABLE IF NOT EXISTS permissions (
+  name TEXT PRIMARY KEY,
+  description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS roles (
+  name TEXT PRIMARY KEY,
+  description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+  permission TEXT NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+  PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (name, description) VALUES
+  ('accounts:create', 'Open accounts for yourself'),
+  ('accounts:create:any', 'Open accounts for any user'),
+  ('accounts:read', 'Read balances, statements and transaction audit trails of your accounts'),
+  ('accounts:read:any', 'Read balances, statements and transaction audit trails of any account'),
+  ('transfers:create', 'Move money out of your accounts'),
+  ('transfers:create:any', 'Move money out of any account'),
+  ('beneficiaries:read', 'Read your beneficiaries'),
+  ('beneficiaries:read:any', 'Read any user''s beneficiaries'),
+  ('beneficiaries:write', 'Add, change and remove your beneficiaries'),
+  ('beneficiaries:write:any', 'Add, change and remove any user''s beneficiaries'),
+  ('payouts:read', 'Read payouts from your accounts'),
+  ('payouts:read:any', 'Read any payout and the payout review queues'),
+  ('api-keys:manage', 'Issue, rotate and revoke your API keys'),
+  ('accounts:lifecycle', 'Freeze, unfreeze and close accounts'),
+  ('accounts:overdraft', 'Set account overdraft limits'),
+  ('ledger:opening-balance', 'Post opening balances'),
+  ('payouts:create', 'Request payouts'),
+  ('payouts:resolve', 'Resolve payouts in manual review'),
+  ('payouts:approve', 'Approve and reject payouts awaiting approval'),
+  ('payouts:screening', 'Clear or reject payouts held by sanctions screening'),
+  ('reconciliation:read', 'Read settlement files, breaks and reconciliation runs'),
+  ('reconciliation:write', 'Import settlement files, clear breaks and start reconciliation runs'),
+  ('limits:read', 'Read transaction limits'),
+  ('limits:write', 'Change transaction limits'),
+  ('kyc:read', 'Read KYC tiers and user KYC status'),
+  ('kyc:write', 'Change user KYC status'),
+  ('aml:read', 'Read AML rules, alerts and cases'),
+  ('aml:write', 'Change AML rules and cases'),
+  ('audit:read', 'Search and verify the audit log'),
+  ('roles:manage', 'List roles and change users'' roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description) VALUES
+  ('user', 'Customer acting on their own accounts'),
+  ('admin', 'Every permission'),
+  ('ops', 'Payment operations: payout review, reconciliation and account lifecycle'),
+  ('support', 'Customer support: read-only customer data and KYC'),
+  ('finance', 'Finance: payout approval, ledger postings, overdrafts and limits'),
+  ('system', 'Internal liquidity users; cannot authenticate for anything')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+  ('user', 'accounts:create'),
+  ('user', 'accounts:read'),
+  ('user', 'transfers:create'),
+  ('user', 'beneficiaries:read'),
+  ('user', 'beneficiaries:write'),
+  ('user', 'payouts:read'),
+  ('user', 'api-keys:manage'),
+  ('ops', 'accounts:read'),
+  ('ops', 'accounts:read:any'),
+  ('ops', 'accounts:lifecycle'),
+  ('ops', 'payouts:read'),
+  ('ops', 'payouts:read:any'),
+  ('ops', 'payouts:resolve'),
+  ('ops', 'reconciliation:read'),
+  ('ops', 'reconciliation:write'),
+  ('support', 'accounts:read'),
+  ('support', 'accounts:read:any'),
+  ('support', 'beneficiaries:read'),
+  ('support', 'beneficiaries:read:any'),
+  ('support', 'payouts:read'),
+  ('support', 'payouts:read:any'),
+  ('support', 'kyc:read'),
+  ('support', 'kyc:write'),
+  ('support', 'limits:read'),
+  ('support', 'audit:read'),
+  ('finance', 'accounts:read'),
+  ('finance', 'accounts:read:any'),
+  ('finance', 'accounts:overdraft'),
+  ('finance', 'ledger:opening-balance'),
+  ('finance', 'payouts:read'),
+  ('finance', 'payouts:read:any'),
+  ('finance', 'payouts:approve'),
+  ('finance', 'reconciliation:read'),
+  ('finance', 'limits:read'),
+  ('finance', 'limits:write')
+ON CONFLICT DO NOTHING;
+
+-- Only user, admin and system could be assigned before; anything else was
+-- never honoured as a role and falls back to user.
+UPDATE users SET role = 'user' WHERE role NOT IN (SELECT name FROM roles);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_fkey;
+ALTER TABLE users ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
+
+-- API key scopes become permissions. Each old scope maps to everything it
+-- reached before, including what an admin owner could do through it; a key
+-- is still limited to its owner's role when used.
+WITH mapping(old, new) AS (VALUES
+  ('accounts:read', 'accounts:read'),
+  ('accounts:read', 'accounts:read:any'),
+  ('accounts:write', 'accounts:create'),
+  ('accounts:write', 'accounts:create:any'),
+  ('transfers:write', 'transfers:create'),
+  ('transfers:write', 'transfers:create:any'),
+  ('beneficiaries:read', 'beneficiaries:read'),
+  ('beneficiaries:read', 'beneficiaries:read:any'),
+  ('beneficiaries:write', 'beneficiaries:write'),
+  ('beneficiaries:write', 'beneficiaries:write:any'),
+  ('payouts:read', 'payouts:read'),
+  ('payouts:read', 'payouts:read:any'),
+  ('payouts:write', 'payouts:create'),
+  ('admin', 'payouts:read:any'),
+  ('admin', 'payouts:resolve'),
+  ('admin', 'payouts:approve'),
+  ('admin', 'payouts:screening'),
+  ('admin', 'accounts:lifecycle'),
+  ('admin', 'accounts:overdraft'),
+  ('admin', 'ledger:opening-balance'),
+  ('admin', 'reconciliation:read'),
+  ('admin', 'reconciliation:write'),
+  ('admin', 'limits:read'),
+  ('admin', 'limits:write'),
+  ('admin', 'kyc:read'),
+  ('admin', 'kyc:write'),
+  ('admin', 'aml:read'),
+  ('admin', 'aml:write'),
+  ('admin', 'audit:read'),
+  ('admin', 'roles:manage')
+)
+UPDATE api_keys k
+SET scopes = ARRAY(
+  SELECT DISTINCT m.new FROM mapping m WHERE m.old = ANY(k.scopes) ORDER BY m.new
+);
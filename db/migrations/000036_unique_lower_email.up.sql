@@ -0,0 +1,24 @@
+-- Logins match emails case-insensitively, so two users whose emails differ
+-- only in case would make a login ambiguous. Such users cannot be merged
+-- automatically: the migration stops and names them so they can be resolved
+-- by hand first (see the on-call runbook).
+DO $$
+DECLARE
+  duplicates TEXT;
+BEGIN
+  SELECT string_agg(lower_email, ', ' ORDER BY lower_email) INTO duplicates
+  FROM (
+    SELECT lower(email) AS lower_email
+    FROM users
+    GROUP BY lower(email)
+    HAVING count(*) > 1
+  ) d;
+  IF duplicates IS NOT NULL THEN
+    RAISE EXCEPTION 'users share an email up to case: %', duplicates;
+  END IF;
+END $$;
+
+UPDATE users SET email = lower(email) WHERE email <> lower(email);
+
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email));
@@ -0,0 +1,38 @@
+DROP TRIGGER IF EXISTS trg_audit_log_chain_at_commit ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_chain_at_commit();
+
+DROP TRIGGER IF EXISTS trg_audit_log_immutable ON audit_log;
+CREATE TRIGGER trg_audit_log_immutable
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION immutable_record_guard();
+DROP FUNCTION IF EXISTS audit_log_immutable_guard();
+
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_seq BIGINT;
+  v_prev TEXT;
+BEGIN
+  INSERT INTO audit_chain_head (id, last_seq, last_hash)
+  VALUES (TRUE, 0, repeat('0', 64))
+  ON CONFLICT (id) DO NOTHING;
+
+  SELECT last_seq, last_hash INTO v_seq, v_prev
+  FROM audit_chain_head
+  WHERE id
+  FOR UPDATE;
+
+  NEW.chain_seq := v_seq + 1;
+  NEW.prev_hash := v_prev;
+  NEW.record_hash := audit_record_hash(
+    NEW.chain_seq, NEW.id, NEW.entity_type, NEW.entity_id, NEW.actor_id, NEW.action,
+    NEW.prev_state, NEW.next_state, NEW.metadata, NEW.created_at, NEW.prev_hash);
+
+  UPDATE audit_chain_head
+  SET last_seq = NEW.chain_seq, last_hash = NEW.record_hash, updated_at = NOW()
+  WHERE id;
+  RETURN NEW;
+END;
+$$;
@@ -0,0 +1,88 @@
+-- Audit records are chained when their transaction commits rather than when
+-- they are inserted. The chain head is then locked only for the last moment
+-- of each writing transaction, after every account or payout lock it takes,
+-- so writers no longer queue behind each other for their whole transaction
+-- and the head can no longer take part in a lock-order deadlock. Chaining is
+-- still serial: each commit that wrote audit records holds the head while it
+-- hashes them.
+
+-- On insert a record gets placeholder chain values; chain_seq = -id keeps the
+-- unique index satisfied until the record is chained at commit.
+CREATE OR REPLACE FUNCTION audit_log_chain() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  NEW.chain_seq := -NEW.id;
+  NEW.prev_hash := '';
+  NEW.record_hash := '';
+  RETURN NEW;
+END;
+$$;
+
+-- Runs for each record as its transaction commits, in insert order.
+CREATE OR REPLACE FUNCTION audit_log_chain_at_commit() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_seq BIGINT;
+  v_prev TEXT;
+  v_hash TEXT;
+BEGIN
+  INSERT INTO audit_chain_head (id, last_seq, last_hash)
+  VALUES (TRUE, 0, repeat('0', 64))
+  ON CONFLICT (id) DO NOTHING;
+
+  SELECT last_seq, last_hash INTO v_seq, v_prev
+  FROM audit_chain_head
+  WHERE id
+  FOR UPDATE;
+
+  v_seq := v_seq + 1;
+  v_hash := audit_record_hash(
+    v_seq, NEW.id, NEW.entity_type, NEW.entity_id, NEW.actor_id, NEW.action,
+    NEW.prev_state, NEW.next_state, NEW.metadata, NEW.created_at, v_prev);
+
+  UPDATE audit_log
+  SET chain_seq = v_seq, prev_hash = v_prev, record_hash = v_hash
+  WHERE id = NEW.id;
+
+  UPDATE audit_chain_head
+  SET last_seq = v_seq, last_hash = v_hash, updated_at = NOW()
+  WHERE id;
+  RETURN NULL;
+END;
+$$;
+
+-- audit_log stays append-only; the one update allowed is chaining a record
+-- that still holds its placeholders, without touching its content.
+CREATE OR REPLACE FUNCTION audit_log_immutable_guard() RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF TG_OP = 'UPDATE'
+     AND OLD.chain_seq < 0
+     AND NEW.chain_seq > 0
+     AND (NEW.id, NEW.entity_type, NEW.entity_id, NEW.actor_id, NEW.action,
+          NEW.prev_state, NEW.next_state, NEW.metadata, NEW.created_at)
+         IS NOT DISTINCT FROM
+         (OLD.id, OLD.entity_type, OLD.entity_id, OLD.actor_id, OLD.action,
+          OLD.prev_state, OLD.next_state, OLD.metadata, OLD.created_at)
+  THEN
+    RETURN NEW;
+  END IF;
+  RAISE EXCEPTION '% is append-only and cannot be modified', TG_TABLE_NAME;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_audit_log_immutable ON audit_log;
+CREATE TRIGGER trg_audit_log_immutable
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_immutable_guard();
+
+DROP TRIGGER IF EXISTS trg_audit_log_chain_at_commit ON audit_log;
+CREATE CONSTRAINT TRIGGER trg_audit_log_chain_at_commit
+AFTER INSERT ON audit_log
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_chain_at_commit();
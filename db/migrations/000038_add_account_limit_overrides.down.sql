@@ -0,0 +1,9 @@
+DELETE FROM limit_usage WHERE account_id IS NOT NULL;
+ALTER TABLE limit_usage DROP CONSTRAINT IF EXISTS limit_usage_key;
+ALTER TABLE limit_usage DROP COLUMN IF EXISTS account_id;
+ALTER TABLE limit_usage ADD PRIMARY KEY (user_id, txn_type, currency, period, period_start);
+
+DELETE FROM user_limit_overrides WHERE account_id IS NOT NULL;
+ALTER TABLE user_limit_overrides DROP CONSTRAINT IF EXISTS user_limit_overrides_key;
+ALTER TABLE user_limit_overrides DROP COLUMN IF EXISTS account_id;
+ALTER TABLE user_limit_overrides ADD PRIMARY KEY (user_id, txn_type, currency);
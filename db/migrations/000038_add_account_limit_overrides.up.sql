@@ -0,0 +1,16 @@
+-- An override may target one of the user's accounts. A NULL account_id
+-- applies to every account in the currency; an account override wins over
+-- it for that account. NULLS NOT DISTINCT keeps one user-wide row per key.
+ALTER TABLE user_limit_overrides
+  ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id);
+ALTER TABLE user_limit_overrides DROP CONSTRAINT IF EXISTS user_limit_overrides_pkey;
+ALTER TABLE user_limit_overrides
+  ADD CONSTRAINT user_limit_overrides_key UNIQUE NULLS NOT DISTINCT (user_id, account_id, txn_type, currency);
+
+-- Usage under an account override is counted for that account alone; a
+-- NULL account_id is the user-wide counter.
+ALTER TABLE limit_usage
+  ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id);
+ALTER TABLE limit_usage DROP CONSTRAINT IF EXISTS limit_usage_pkey;
+ALTER TABLE limit_usage
+  ADD CONSTRAINT limit_usage_key UNIQUE NULLS NOT DISTINCT (user_id, account_id, txn_type, currency, period, period_start);
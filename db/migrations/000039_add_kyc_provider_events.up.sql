@@ -0,0 +1,8 @@
+-- Provider webhook events already handled, so a replayed event is a no-op
+-- whatever its occurred_at says.
+CREATE TABLE IF NOT EXISTS kyc_provider_events (
+  event_id TEXT PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES users(id),
+  occurred_at TIMESTAMPTZ NOT NULL,
+  received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
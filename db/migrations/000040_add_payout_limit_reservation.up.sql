@@ -0,0 +1,3 @@
+-- The limit usage a payout request counted, so a payout that is rejected or
+-- fails gives it back. Cleared once released; NULL when nothing was counted.
+ALTER TABLE payouts ADD COLUMN IF NOT EXISTS limit_reservation JSONB;
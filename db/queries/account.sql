@@ -1,15 +1,15 @@
 -- name: CreateUser :one
 INSERT INTO users (id, username, email, role, created_at) 
 VALUES ($1, $2, $3, $4, NOW()) 
-RETURNING created_at;
+RETURNING created_at, kyc_status, kyc_tier;
 
 -- name: CreateAccount :one
 INSERT INTO accounts (id, user_id, currency, balance, created_at) 
 VALUES ($1, $2, $3, $4, NOW()) 
-RETURNING created_at;
+RETURNING created_at, status;
 
 -- name: GetAccount :one
-SELECT id, user_id, currency, balance, created_at 
+SELECT id, user_id, currency, balance, status, status_reason, overdraft_limit_micros, overdraft_interest_bps, created_at
 FROM accounts 
 WHERE id = $1;
 
@@ -21,7 +21,7 @@ ORDER BY created_at DESC
 LIMIT $2 OFFSET $3;
 
 -- name: GetUser :one
-SELECT id, username, email, role, created_at 
+SELECT id, username, email, role, created_at, kyc_status, kyc_tier 
 FROM users 
 WHERE id = $1;
 
@@ -46,4 +46,54 @@ SET locked_micros = locked_micros - $1, balance = balance - $1
 WHERE id = $2;
 
 -- name: GetAccountBalanceAndLocked :one
-SELECT balance, locked_micros, currency FROM accounts WHERE id = $1 FOR UPDATE;
+SELECT balance, locked_micros, currency, status, overdraft_limit_micros, overdraft_interest_bps FROM accounts WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status),
+    status_reason = sqlc.arg(status_reason),
+    status_changed_by = sqlc.arg(status_changed_by),
+    status_changed_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING id, user_id, currency, balance, locked_micros, status, status_reason, status_changed_by, status_changed_at, created_at;
+
+-- name: UpdateAccountOverdraft :one
+UPDATE accounts
+SET overdraft_limit_micros = sqlc.arg(overdraft_limit_micros),
+    overdraft_interest_bps = sqlc.arg(overdraft_interest_bps)
+WHERE id = sqlc.arg(id)
+RETURNING id, user_id, currency, balance, status, status_reason, overdraft_limit_micros, overdraft_interest_bps, created_at;
+
+-- name: ListOverdrawnAccounts :many
+-- Accounts with an interest rate that are overdrawn now or have entries
+-- since since, so may have been overdrawn when an earlier day ended.
+SELECT a.id
+FROM accounts a
+WHERE a.overdraft_interest_bps > 0
+  AND (a.balance < 0 OR EXISTS (
+    SELECT 1 FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(since)
+  ))
+  AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetAccountNetEntriesSince :one
+-- Net of the account's entries created at or after since, credits positive.
+-- The balance at since is the current balance less this.
+SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)::bigint
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND created_at >= sqlc.arg(since);
+
+-- name: GetLastOverdraftInterestReference :one
+-- The reference of the account's latest overdraft interest charge posted
+-- since since. References end in the ISO date charged, so they sort by day.
+SELECT t.reference_id
+FROM entries e
+INNER JOIN transactions t ON t.id = e.transaction_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(since)
+  AND t.type = 'fee'
+  AND t.reference_id LIKE sqlc.arg(reference_prefix)::text || '%'
+ORDER BY t.reference_id DESC
+LIMIT 1;
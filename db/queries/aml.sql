@@ -0,0 +1,128 @@
+-- name: ListAMLRules :many
+SELECT code, description, enabled, window_seconds, amount_micros, min_count, ratio_bps, severity, updated_by, updated_at
+FROM aml_rules
+ORDER BY code;
+
+-- name: UpdateAMLRule :one
+UPDATE aml_rules
+SET enabled = $2,
+    window_seconds = $3,
+    amount_micros = $4,
+    min_count = $5,
+    ratio_bps = $6,
+    severity = $7,
+    updated_by = $8,
+    updated_at = NOW()
+WHERE code = $1
+RETURNING code, description, enabled, window_seconds, amount_micros, min_count, ratio_bps, severity, updated_by, updated_at;
+
+-- name: InsertAMLObservation :execrows
+INSERT INTO aml_observations (event_id, event_type, transaction_id, account_id, user_id, direction, amount_micros, currency, counter_currency, occurred_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (event_id, account_id, direction) DO NOTHING;
+
+-- name: CountAMLObservationsInBand :one
+SELECT COUNT(*)::bigint AS movements, COALESCE(SUM(amount_micros), 0)::bigint AS total_micros
+FROM aml_observations
+WHERE account_id = sqlc.arg(account_id)
+  AND direction = sqlc.arg(direction)
+  AND currency = sqlc.arg(currency)
+  AND occurred_at > sqlc.arg(window_start)
+  AND occurred_at <= sqlc.arg(window_end)
+  AND amount_micros >= sqlc.arg(min_amount_micros)
+  AND amount_micros < sqlc.arg(max_amount_micros);
+
+-- name: SumAMLAccountFlows :one
+SELECT
+  COALESCE(SUM(amount_micros) FILTER (WHERE direction = 'IN'), 0)::bigint AS in_micros,
+  COALESCE(SUM(amount_micros) FILTER (WHERE direction = 'OUT'), 0)::bigint AS out_micros
+FROM aml_observations
+WHERE account_id = sqlc.arg(account_id)
+  AND occurred_at > sqlc.arg(window_start)
+  AND occurred_at <= sqlc.arg(window_end);
+
+-- name: FindAMLReverseExchange :one
+SELECT transaction_id, amount_micros, occurred_at
+FROM aml_observations
+WHERE user_id = sqlc.arg(user_id)
+  AND event_type = 'exchange.completed'
+  AND direction = 'OUT'
+  AND currency = sqlc.arg(currency)
+  AND counter_currency = sqlc.arg(counter_currency)
+  AND amount_micros >= sqlc.arg(min_amount_micros)
+  AND occurred_at > sqlc.arg(window_start)
+  AND occurred_at <= sqlc.arg(window_end)
+ORDER BY occurred_at DESC
+LIMIT 1;
+
+-- name: HasRecentAMLAlert :one
+SELECT EXISTS (
+  SELECT 1 FROM aml_alerts
+  WHERE rule_code = $1 AND account_id = $2 AND window_end > $3
+);
+
+-- name: CreateAMLCase :one
+INSERT INTO aml_cases (id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) WHERE status <> 'CLOSED' DO NOTHING
+RETURNING id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at;
+
+-- name: GetActiveAMLCaseForUser :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE user_id = $1 AND status <> 'CLOSED';
+
+-- name: GetAMLCaseForUpdate :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetAMLCase :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE id = $1;
+
+-- name: ListAMLCases :many
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountAMLCases :one
+SELECT COUNT(*)::bigint
+FROM aml_cases
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text);
+
+-- name: UpdateAMLCase :one
+UPDATE aml_cases
+SET status = $2,
+    assigned_to = $3,
+    resolution = $4,
+    note = $5,
+    updated_at = NOW(),
+    closed_at = CASE WHEN $2 = 'CLOSED' THEN NOW() ELSE NULL END
+WHERE id = $1
+RETURNING id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at;
+
+-- name: CreateAMLAlert :one
+INSERT INTO aml_alerts (id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end, created_at;
+
+-- name: ListAMLAlerts :many
+SELECT id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end, created_at
+FROM aml_alerts
+WHERE (sqlc.narg(rule_code)::text IS NULL OR rule_code = sqlc.narg(rule_code)::text)
+  AND (sqlc.narg(case_id)::uuid IS NULL OR case_id = sqlc.narg(case_id)::uuid)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(limit_count) OFFSET sqlc.arg(offset_count);
+
+-- name: CountAMLAlerts :one
+SELECT COUNT(*)::bigint
+FROM aml_alerts
+WHERE (sqlc.narg(rule_code)::text IS NULL OR rule_code = sqlc.narg(rule_code)::text)
+  AND (sqlc.narg(case_id)::uuid IS NULL OR case_id = sqlc.narg(case_id)::uuid)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id)::uuid);
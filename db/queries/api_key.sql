@@ -0,0 +1,49 @@
+-- name: CreateAPIKey :exec
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, rotated_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetAPIKeyByHash :one
+SELECT k.id, k.user_id, k.name, k.prefix, k.scopes, k.created_at, k.expires_at, k.last_used_at, k.revoked_at, k.rotated_from, u.role,
+       ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role = u.role ORDER BY rp.permission)::text[] AS role_permissions
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+WHERE k.key_hash = $1;
+
+-- name: GetActiveAPIKeyForUpdate :one
+SELECT *
+FROM api_keys
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > sqlc.arg(now))
+FOR UPDATE;
+
+-- name: ListActiveAPIKeys :many
+SELECT *
+FROM api_keys
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > sqlc.arg(now))
+ORDER BY created_at DESC;
+
+-- name: TouchAPIKey :exec
+-- Only writes when the recorded use is older than stale_before, so a busy
+-- key does not update its row on every request.
+UPDATE api_keys
+SET last_used_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id)
+  AND (last_used_at IS NULL OR last_used_at < sqlc.arg(stale_before));
+
+-- name: ExpireAPIKey :exec
+-- Shortens a rotated key's life to the grace period, never lengthens it.
+UPDATE api_keys
+SET expires_at = LEAST(COALESCE(expires_at, sqlc.arg(expires_at)), sqlc.arg(expires_at))
+WHERE id = sqlc.arg(id);
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > sqlc.arg(now));
@@ -17,3 +17,66 @@ SELECT id, entity_type, entity_id, actor_id, action, prev_state, next_state, met
 FROM audit_log
 WHERE entity_type = $1 AND entity_id = $2
 ORDER BY id ASC;
+
+-- name: GetAuditChainHead :one
+SELECT last_seq, last_hash
+FROM audit_chain_head
+WHERE id;
+
+-- name: ListAuditChain :many
+SELECT chain_seq, id, entity_type, entity_id, actor_id, action, prev_state, next_state, metadata, created_at, prev_hash, record_hash
+FROM audit_log
+WHERE chain_seq > sqlc.arg(after_seq) AND chain_seq <= sqlc.arg(until_seq)
+ORDER BY chain_seq ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListAuditAnchors :many
+SELECT *
+FROM audit_anchors
+ORDER BY chain_seq ASC;
+
+-- name: GetLatestAuditAnchor :one
+SELECT *
+FROM audit_anchors
+ORDER BY chain_seq DESC
+LIMIT 1;
+
+-- name: CreateAuditAnchor :one
+INSERT INTO audit_anchors (chain_seq, record_hash, file_path)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT id, chain_seq, entity_type, entity_id, actor_id, action, prev_state, next_state, metadata, created_at, record_hash
+FROM audit_log
+WHERE (sqlc.narg(entity_type)::text IS NULL OR entity_type = sqlc.narg(entity_type)::text)
+  AND (sqlc.narg(entity_id)::uuid IS NULL OR entity_id = sqlc.narg(entity_id)::uuid)
+  AND (sqlc.narg(actor_id)::uuid IS NULL OR actor_id = sqlc.narg(actor_id)::uuid)
+  AND (sqlc.narg(action)::text IS NULL OR action = sqlc.narg(action)::text)
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from)::timestamptz)
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to)::timestamptz)
+  AND (sqlc.narg(cursor_at)::timestamptz IS NULL OR (created_at, id) < (sqlc.narg(cursor_at)::timestamptz, sqlc.narg(cursor_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: IsTransactionVisibleToUser :one
+SELECT EXISTS (
+  SELECT 1
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.transaction_id = sqlc.arg(transaction_id) AND a.user_id = sqlc.arg(user_id)
+) OR EXISTS (
+  SELECT 1
+  FROM payouts p
+  JOIN accounts a ON a.id = p.account_id
+  WHERE p.transaction_id = sqlc.arg(transaction_id) AND a.user_id = sqlc.arg(user_id)
+) AS visible;
+
+-- name: ListTransactionAuditTrail :many
+SELECT l.id, l.entity_type, l.entity_id, l.actor_id, l.action, l.prev_state, l.next_state, l.created_at
+FROM audit_log l
+WHERE (l.entity_type = 'transaction' AND l.entity_id = sqlc.arg(transaction_id))
+   OR (l.entity_type = 'payout' AND l.entity_id IN (
+     SELECT p.id FROM payouts p WHERE p.transaction_id = sqlc.arg(transaction_id)
+   ))
+ORDER BY l.created_at ASC, l.id ASC;
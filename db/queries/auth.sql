@@ -0,0 +1,45 @@
+-- name: GetUserCredentialsByEmail :one
+SELECT id, username, email, role, created_at, kyc_status, kyc_tier, password_hash, failed_login_attempts, locked_until
+FROM users
+WHERE lower(email) = lower(sqlc.arg(email));
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_changed_at = NOW(),
+    failed_login_attempts = 0,
+    locked_until = NULL
+WHERE id = $1;
+
+-- name: RecordFailedLogin :one
+-- Reaching max_attempts locks the user until locked_until and starts the
+-- count again.
+UPDATE users
+SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= sqlc.arg(max_attempts)::int THEN 0 ELSE failed_login_attempts + 1 END,
+    locked_until = CASE WHEN failed_login_attempts + 1 >= sqlc.arg(max_attempts)::int THEN sqlc.arg(locked_until)::timestamptz ELSE locked_until END
+WHERE id = sqlc.arg(id)
+RETURNING failed_login_attempts, locked_until;
+
+-- name: RecordSuccessfulLogin :exec
+UPDATE users
+SET failed_login_attempts = 0,
+    locked_until = NULL,
+    last_login_at = NOW()
+WHERE id = $1;
+
+-- name: RevokePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, user_id, expires_at)
+VALUES ($1, $2, $3);
+
+-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE token_hash = sqlc.arg(token_hash)
+  AND used_at IS NULL
+  AND expires_at > sqlc.arg(now)
+RETURNING user_id;
@@ -0,0 +1,33 @@
+-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetBeneficiary :one
+SELECT * FROM beneficiaries
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListBeneficiariesByUser :many
+SELECT * FROM beneficiaries
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateBeneficiary :one
+UPDATE beneficiaries
+SET name = $2,
+    scheme = $3,
+    iban = $4,
+    bic = $5,
+    routing_number = $6,
+    account_number = $7,
+    sort_code = $8,
+    available_at = $9,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteBeneficiary :execrows
+UPDATE beneficiaries
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
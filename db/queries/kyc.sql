@@ -0,0 +1,42 @@
+-- name: ListKYCTiers :many
+SELECT tier, name, allowed_currencies, payouts_allowed, max_balance_micros
+FROM kyc_tiers
+ORDER BY tier;
+
+-- name: GetKYCTier :one
+SELECT tier, name, allowed_currencies, payouts_allowed, max_balance_micros
+FROM kyc_tiers
+WHERE tier = $1;
+
+-- name: GetUserKYC :one
+SELECT u.id, u.kyc_status, u.kyc_tier, u.kyc_provider_ref, u.kyc_updated_at,
+       t.name AS tier_name, t.allowed_currencies, t.payouts_allowed, t.max_balance_micros
+FROM users u
+JOIN kyc_tiers t ON t.tier = u.kyc_tier
+WHERE u.id = $1;
+
+-- name: LockUserKYC :one
+SELECT kyc_status, kyc_tier, kyc_provider_ref, kyc_updated_at
+FROM users
+WHERE id = $1
+FOR UPDATE;
+
+-- name: UpdateUserKYC :exec
+UPDATE users
+SET kyc_status = sqlc.arg(kyc_status),
+    kyc_tier = sqlc.arg(kyc_tier),
+    kyc_provider_ref = sqlc.arg(kyc_provider_ref),
+    kyc_updated_at = sqlc.arg(kyc_updated_at)
+WHERE id = sqlc.arg(id);
+
+-- name: GetAccountKYCRules :one
+SELECT a.user_id, a.balance, u.kyc_tier, t.allowed_currencies, t.payouts_allowed, t.max_balance_micros
+FROM accounts a
+JOIN users u ON u.id = a.user_id
+JOIN kyc_tiers t ON t.tier = u.kyc_tier
+WHERE a.id = $1;
+
+-- name: RecordKYCProviderEvent :execrows
+INSERT INTO kyc_provider_events (event_id, user_id, occurred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id) DO NOTHING;
@@ -0,0 +1,67 @@
+-- name: GetTransactionLimit :one
+SELECT txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at
+FROM transaction_limits
+WHERE txn_type = $1 AND currency = $2;
+
+-- name: ListTransactionLimits :many
+SELECT txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at
+FROM transaction_limits
+ORDER BY txn_type, currency;
+
+-- name: UpsertTransactionLimit :one
+INSERT INTO transaction_limits (txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (txn_type, currency) DO UPDATE
+SET per_transaction_max_micros = EXCLUDED.per_transaction_max_micros,
+    daily_max_micros = EXCLUDED.daily_max_micros,
+    monthly_max_micros = EXCLUDED.monthly_max_micros,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at;
+
+-- name: DeleteTransactionLimit :execrows
+DELETE FROM transaction_limits WHERE txn_type = $1 AND currency = $2;
+
+-- name: GetApplicableLimitOverride :one
+-- The account's own override wins over the user-wide one.
+SELECT user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id
+FROM user_limit_overrides
+WHERE user_id = sqlc.arg(user_id) AND txn_type = sqlc.arg(txn_type) AND currency = sqlc.arg(currency)
+  AND (account_id IS NULL OR account_id = sqlc.narg(account_id))
+ORDER BY account_id NULLS LAST
+LIMIT 1;
+
+-- name: ListUserLimitOverrides :many
+SELECT user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id
+FROM user_limit_overrides
+WHERE user_id = $1
+ORDER BY txn_type, currency, account_id NULLS FIRST;
+
+-- name: UpsertUserLimitOverride :one
+INSERT INTO user_limit_overrides (user_id, account_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+ON CONFLICT (user_id, account_id, txn_type, currency) DO UPDATE
+SET per_transaction_max_micros = EXCLUDED.per_transaction_max_micros,
+    daily_max_micros = EXCLUDED.daily_max_micros,
+    monthly_max_micros = EXCLUDED.monthly_max_micros,
+    note = EXCLUDED.note,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id;
+
+-- name: DeleteUserLimitOverride :execrows
+DELETE FROM user_limit_overrides
+WHERE user_id = $1 AND account_id IS NOT DISTINCT FROM $2 AND txn_type = $3 AND currency = $4;
+
+-- name: AddLimitUsage :one
+INSERT INTO limit_usage (user_id, account_id, txn_type, currency, period, period_start, used_micros, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, sqlc.arg(delta), NOW())
+ON CONFLICT (user_id, account_id, txn_type, currency, period, period_start) DO UPDATE
+SET used_micros = limit_usage.used_micros + EXCLUDED.used_micros,
+    updated_at = NOW()
+RETURNING used_micros;
+
+-- name: GetLimitUsage :one
+SELECT used_micros
+FROM limit_usage
+WHERE user_id = $1 AND account_id IS NOT DISTINCT FROM $2 AND txn_type = $3 AND currency = $4 AND period = $5 AND period_start = $6;
@@ -0,0 +1,47 @@
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload, created_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW());
+
+-- name: ClaimOutboxEvents :many
+WITH due AS (
+  SELECT id FROM outbox_events
+  WHERE published_at IS NULL AND next_attempt_at <= NOW()
+  ORDER BY created_at ASC
+  FOR UPDATE SKIP LOCKED
+  LIMIT sqlc.arg(batch_size)
+)
+UPDATE outbox_events o
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM due
+WHERE o.id = due.id
+RETURNING o.*;
+
+-- name: GetOutboxDeliveredSinks :many
+SELECT sink FROM outbox_deliveries WHERE event_id = $1;
+
+-- name: InsertOutboxDelivery :exec
+INSERT INTO outbox_deliveries (event_id, sink, delivered_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (event_id, sink) DO NOTHING;
+
+-- name: MarkOutboxEventPublished :execrows
+UPDATE outbox_events
+SET published_at = NOW(), last_error = NULL
+WHERE id = $1 AND published_at IS NULL;
+
+-- name: MarkOutboxEventRetry :execrows
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1 AND published_at IS NULL;
+
+-- name: CountPendingOutboxEvents :one
+SELECT COUNT(*)::bigint FROM outbox_events WHERE published_at IS NULL;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE published_at IS NOT NULL AND published_at < $1;
+
+-- name: GetOutboxEventsByAggregate :many
+SELECT * FROM outbox_events
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY created_at ASC;
@@ -0,0 +1,67 @@
+-- name: ListEntriesPartitions :many
+SELECT c.relname::text AS partition_name
+FROM pg_catalog.pg_inherits i
+INNER JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'entries'::regclass
+ORDER BY c.relname;
+
+-- name: CreateEntriesPartition :one
+SELECT create_entries_partition(sqlc.arg(month)::date)::bigint AS moved;
+
+-- name: DropEntriesPartition :exec
+SELECT drop_entries_partition(sqlc.arg(month)::date, sqlc.arg(archived_rows)::bigint);
+
+-- name: ListEntriesDefaultMonths :many
+SELECT month::date AS month FROM list_entries_default_months() AS month;
+
+-- name: ClaimEntriesArchive :one
+-- Claims a partition for export. A claim left EXPORTING since before
+-- stale_before is taken over.
+INSERT INTO entries_archives (id, partition_name, period_start, period_end, claimed_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (partition_name) DO UPDATE SET claimed_at = NOW()
+WHERE entries_archives.status = 'EXPORTING' AND entries_archives.claimed_at < sqlc.arg(stale_before)
+RETURNING *;
+
+-- name: ListArchivedPartitionNames :many
+SELECT partition_name FROM entries_archives WHERE status = 'ARCHIVED' ORDER BY partition_name;
+
+-- name: ListEntriesForArchive :many
+-- One page of the entries created in [period_start, period_end), in
+-- (created_at, id) order after the given key.
+SELECT
+  e.id,
+  e.transaction_id,
+  e.account_id,
+  a.currency,
+  e.amount,
+  e.direction,
+  e.created_at
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= sqlc.arg(period_start) AND e.created_at < sqlc.arg(period_end)
+  AND (e.created_at, e.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::uuid)
+ORDER BY e.created_at, e.id
+LIMIT sqlc.arg(row_limit);
+
+-- name: CreateEntriesArchiveCurrencyTotal :exec
+INSERT INTO entries_archive_currency_totals (archive_id, currency, entry_count, debit_micros, credit_micros)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: AddEntriesArchivedAccountTotal :exec
+INSERT INTO entries_archived_account_totals (account_id, net_micros)
+VALUES ($1, $2)
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = entries_archived_account_totals.net_micros + EXCLUDED.net_micros,
+  updated_at = NOW();
+
+-- name: CompleteEntriesArchive :one
+UPDATE entries_archives
+SET status = 'ARCHIVED',
+    row_count = $2,
+    file_path = $3,
+    manifest_path = $4,
+    sha256 = $5,
+    archived_at = NOW()
+WHERE id = $1 AND status = 'EXPORTING'
+RETURNING *;
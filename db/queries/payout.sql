@@ -1,8 +1,17 @@
--- name: InsertPayout :one
-INSERT INTO payouts (id, transaction_id, account_id, amount_micros, currency, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING *;
-
+-- name: InsertPayout :one
+INSERT INTO payouts (id, transaction_id, account_id, amount_micros, currency, status, requested_by, beneficiary_id, limit_reservation, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+RETURNING *;
+
+-- name: TakePayoutLimitReservation :one
+-- Clears the recorded reservation and returns it, so each payout releases
+-- its limit usage at most once.
+UPDATE payouts p
+SET limit_reservation = NULL
+FROM (SELECT q.id, q.limit_reservation FROM payouts q WHERE q.id = $1 FOR UPDATE) old
+WHERE p.id = old.id AND old.limit_reservation IS NOT NULL
+RETURNING old.limit_reservation;
+
 -- name: GetPayout :one
 SELECT * FROM payouts WHERE id = $1;
 
@@ -16,18 +25,34 @@ ORDER BY created_at ASC
 FOR UPDATE SKIP LOCKED 
 LIMIT $1;
 
+-- name: GetPendingPayoutForUpdate :one
+SELECT * FROM payouts
+WHERE id = $1 AND status = 'PENDING'
+FOR UPDATE SKIP LOCKED;
+
 -- name: GetStaleProcessingPayouts :many
 SELECT * FROM payouts
 WHERE status = 'PROCESSING' AND updated_at < $1
 ORDER BY updated_at ASC
 FOR UPDATE SKIP LOCKED
 LIMIT $2;
-
+
+-- name: ClaimPayoutAttempt :one
+UPDATE payouts
+SET status = 'PROCESSING', gateway_ref = NULL, attempts = attempts + 1, updated_at = NOW()
+WHERE id = $1 AND status = 'PENDING'
+RETURNING attempts;
+
+-- name: TouchPayout :execrows
+UPDATE payouts
+SET updated_at = NOW()
+WHERE id = $1;
+
 -- name: UpdatePayoutStatus :execrows
 UPDATE payouts
 SET status = $1, gateway_ref = $2, updated_at = NOW()
 WHERE id = $3;
-
+
 -- name: GetPayoutByTransactionID :one
 SELECT * FROM payouts WHERE transaction_id = $1;
 
@@ -40,3 +65,37 @@ LIMIT $2 OFFSET $3;
 -- name: CountPayoutsByStatus :one
 SELECT COUNT(*)::bigint FROM payouts
 WHERE status = $1;
+
+-- name: NotifyChannel :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
+
+-- name: ReviewPayout :execrows
+UPDATE payouts
+SET status = $1, reviewed_by = $2, reviewed_at = NOW(), updated_at = NOW()
+WHERE id = $3 AND status = 'AWAITING_APPROVAL';
+
+-- name: GetPayoutsAwaitingApproval :many
+SELECT * FROM payouts
+WHERE status = 'AWAITING_APPROVAL'
+ORDER BY created_at ASC
+LIMIT $1 OFFSET $2;
+
+-- name: MarkPayoutSubmitted :execrows
+UPDATE payouts
+SET status = 'SUBMITTED', gateway_ref = $1, updated_at = NOW()
+WHERE id = $2 AND status = 'PROCESSING';
+
+-- name: ReopenSubmittedPayout :execrows
+UPDATE payouts
+SET status = 'PROCESSING', updated_at = NOW()
+WHERE id = $1 AND status = 'SUBMITTED';
+
+-- name: GetPayoutByGatewayRef :one
+SELECT * FROM payouts WHERE gateway_ref = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ReleaseScreenedPayout :execrows
+UPDATE payouts
+SET status = $1, updated_at = NOW()
+WHERE id = $2 AND status = 'SCREENING_HOLD';
@@ -28,3 +28,324 @@ HAVING COALESCE(SUM(
     ELSE 0
   END
 ), 0) <> 0;
+
+-- name: ListAccountBalanceDrifts :many
+-- Accounts whose stored balance differs from the net of their own entries,
+-- archived entries included.
+SELECT
+  a.id,
+  a.currency,
+  a.balance,
+  (COALESCE(e.net_amount, 0) + COALESCE(x.net_micros, 0))::bigint AS entries_net
+FROM accounts a
+LEFT JOIN (
+  SELECT
+    account_id,
+    SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END) AS net_amount
+  FROM entries
+  GROUP BY account_id
+) e ON e.account_id = a.id
+LEFT JOIN entries_archived_account_totals x ON x.account_id = a.id
+WHERE a.balance <> COALESCE(e.net_amount, 0) + COALESCE(x.net_micros, 0);
+
+-- name: ListLockedFundsDrifts :many
+-- Accounts whose locked_micros differs from the payouts still holding funds.
+SELECT
+  a.id,
+  a.currency,
+  a.locked_micros,
+  COALESCE(p.open_micros, 0)::bigint AS open_payout_micros
+FROM accounts a
+LEFT JOIN (
+  SELECT account_id, SUM(amount_micros) AS open_micros
+  FROM payouts
+  WHERE status IN ('AWAITING_APPROVAL', 'SCREENING_HOLD', 'PENDING', 'PROCESSING', 'SUBMITTED', 'MANUAL_REVIEW')
+  GROUP BY account_id
+) p ON p.account_id = a.id
+WHERE a.locked_micros <> COALESCE(p.open_micros, 0);
+
+-- name: ListUnbalancedTransactions :many
+-- Transactions whose entries do not net to zero within a currency.
+SELECT
+  e.transaction_id,
+  a.currency,
+  SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END)::bigint AS net_amount
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+GROUP BY e.transaction_id, a.currency
+HAVING SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) <> 0;
+
+-- name: UpsertReconciliationFinding :one
+INSERT INTO reconciliation_findings (id, finding_type, subject_id, currency, period, expected_micros, actual_micros, first_seen_at, last_seen_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, sqlc.arg(seen_at), sqlc.arg(seen_at))
+ON CONFLICT (finding_type, subject_id, currency, period) WHERE status = 'OPEN'
+DO UPDATE SET
+  expected_micros = EXCLUDED.expected_micros,
+  actual_micros = EXCLUDED.actual_micros,
+  last_seen_at = EXCLUDED.last_seen_at
+RETURNING *;
+
+-- name: ResolveReconciliationFindings :execrows
+-- Resolves open findings of the given types that the current run (started at
+-- seen_before) did not see.
+UPDATE reconciliation_findings
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE status = 'OPEN'
+  AND finding_type = ANY(sqlc.arg(finding_types)::text[])
+  AND last_seen_at < sqlc.arg(seen_before);
+
+-- name: ListReconciliationFindings :many
+SELECT * FROM reconciliation_findings
+WHERE status = $1
+ORDER BY first_seen_at ASC
+LIMIT $2 OFFSET $3;
+
+-- name: GetLedgerCurrencyTotals :many
+-- Archived partitions are counted from the totals kept when they were
+-- archived.
+SELECT
+  t.currency,
+  SUM(t.entry_count)::bigint AS entry_count,
+  SUM(t.debit_micros)::bigint AS debit_micros,
+  SUM(t.credit_micros)::bigint AS credit_micros
+FROM (
+  SELECT
+    a.currency,
+    COUNT(*) AS entry_count,
+    COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0) AS debit_micros,
+    COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0) AS credit_micros
+  FROM entries e
+  INNER JOIN accounts a ON a.id = e.account_id
+  GROUP BY a.currency
+  UNION ALL
+  SELECT currency, entry_count, debit_micros, credit_micros
+  FROM entries_archive_currency_totals
+) t
+GROUP BY t.currency
+ORDER BY t.currency;
+
+-- name: AbandonStaleReconciliationRuns :execrows
+UPDATE reconciliation_runs
+SET status = 'ERROR', finished_at = NOW(), error = 'run did not finish'
+WHERE status = 'RUNNING' AND started_at < $1;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (id, trigger, scope, triggered_by, started_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: FinishReconciliationRun :one
+UPDATE reconciliation_runs
+SET status = $2,
+    finished_at = NOW(),
+    currency_totals = $3,
+    findings = $4,
+    finding_count = $5,
+    error = $6
+WHERE id = $1 AND status = 'RUNNING'
+RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs WHERE id = $1;
+
+-- name: ListReconciliationRuns :many
+SELECT * FROM reconciliation_runs
+ORDER BY started_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetLedgerCheckpoint :one
+SELECT * FROM ledger_checkpoint WHERE id;
+
+-- name: GetEarliestEntryTime :one
+SELECT MIN(created_at)::timestamptz AS earliest FROM entries;
+
+-- name: InitLedgerCheckpoint :exec
+INSERT INTO ledger_checkpoint (started_from, checkpointed_until)
+VALUES (sqlc.arg(day), sqlc.arg(day))
+ON CONFLICT (id) DO NOTHING;
+
+-- name: AdvanceLedgerCheckpoint :execrows
+UPDATE ledger_checkpoint
+SET checkpointed_until = sqlc.arg(next_day), updated_at = NOW()
+WHERE id AND checkpointed_until = sqlc.arg(day);
+
+-- name: DeleteLedgerDayTotals :exec
+DELETE FROM ledger_day_totals WHERE day = $1;
+
+-- name: InsertLedgerDayTotals :exec
+-- Totals per currency for the entries created in [day_start, day_end).
+INSERT INTO ledger_day_totals (day, currency, entry_count, debit_micros, credit_micros)
+SELECT
+  sqlc.arg(day)::date,
+  a.currency,
+  COUNT(*)::bigint,
+  COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::bigint,
+  COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::bigint
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= sqlc.arg(day_start) AND e.created_at < sqlc.arg(day_end)
+GROUP BY a.currency;
+
+-- name: AddLedgerAccountTotals :exec
+-- Adds the net of the entries created in [day_start, day_end) to each
+-- account's checkpointed total.
+INSERT INTO ledger_account_totals (account_id, net_micros)
+SELECT
+  account_id,
+  SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END)::bigint
+FROM entries
+WHERE created_at >= sqlc.arg(day_start) AND created_at < sqlc.arg(day_end)
+GROUP BY account_id
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = ledger_account_totals.net_micros + EXCLUDED.net_micros,
+  updated_at = NOW();
+
+-- name: RebuildLedgerAccountTotals :exec
+-- Recomputes the checkpointed total of every account with entries in
+-- [day_start, day_end) from all of its entries before checkpoint_end and
+-- its archived entries.
+INSERT INTO ledger_account_totals (account_id, net_micros)
+SELECT
+  t.account_id,
+  (t.net_amount + COALESCE(x.net_micros, 0))::bigint
+FROM (
+  SELECT
+    e.account_id,
+    SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) AS net_amount
+  FROM entries e
+  WHERE e.created_at < sqlc.arg(checkpoint_end)
+    AND e.account_id IN (
+      SELECT DISTINCT d.account_id FROM entries d
+      WHERE d.created_at >= sqlc.arg(day_start) AND d.created_at < sqlc.arg(day_end)
+    )
+  GROUP BY e.account_id
+) t
+LEFT JOIN entries_archived_account_totals x ON x.account_id = t.account_id
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = EXCLUDED.net_micros,
+  updated_at = NOW();
+
+-- name: ListLedgerDirtyDays :many
+SELECT day FROM ledger_dirty_days ORDER BY day;
+
+-- name: DeleteLedgerDirtyDay :exec
+DELETE FROM ledger_dirty_days WHERE day = $1;
+
+-- name: GetCheckpointedCurrencyTotals :many
+SELECT
+  currency,
+  SUM(entry_count)::bigint AS entry_count,
+  SUM(debit_micros)::bigint AS debit_micros,
+  SUM(credit_micros)::bigint AS credit_micros
+FROM ledger_day_totals
+GROUP BY currency
+ORDER BY currency;
+
+-- name: GetLedgerCurrencyTotalsSince :many
+SELECT
+  a.currency,
+  COUNT(*)::bigint AS entry_count,
+  COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::bigint AS debit_micros,
+  COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::bigint AS credit_micros
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= $1
+GROUP BY a.currency
+ORDER BY a.currency;
+
+-- name: ListAccountBalanceDriftsSince :many
+-- Like ListAccountBalanceDrifts, but takes each account's net up to since
+-- from ledger_account_totals and only scans entries created after it.
+SELECT
+  a.id,
+  a.currency,
+  a.balance,
+  (COALESCE(t.net_micros, 0) + COALESCE(e.net_amount, 0))::bigint AS entries_net
+FROM accounts a
+LEFT JOIN ledger_account_totals t ON t.account_id = a.id
+LEFT JOIN (
+  SELECT
+    s.account_id,
+    SUM(CASE WHEN s.direction = 'credit' THEN s.amount ELSE -s.amount END) AS net_amount
+  FROM entries s
+  WHERE s.created_at >= sqlc.arg(since)
+  GROUP BY s.account_id
+) e ON e.account_id = a.id
+WHERE a.balance <> COALESCE(t.net_micros, 0) + COALESCE(e.net_amount, 0);
+
+-- name: ListUnbalancedTransactionsInRange :many
+-- Like ListUnbalancedTransactions, restricted to transactions with an entry
+-- created in [range_start, range_end).
+SELECT
+  e.transaction_id,
+  a.currency,
+  SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END)::bigint AS net_amount
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.transaction_id IN (
+  SELECT DISTINCT r.transaction_id FROM entries r
+  WHERE r.created_at >= sqlc.arg(range_start) AND r.created_at < sqlc.arg(range_end)
+)
+GROUP BY e.transaction_id, a.currency
+HAVING SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) <> 0;
+
+-- name: ResolveTransactionFindingsInRange :execrows
+-- Resolves open unbalanced-transaction findings the current run did not see,
+-- limited to transactions with an entry created in [range_start, range_end).
+UPDATE reconciliation_findings f
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE f.status = 'OPEN'
+  AND f.finding_type = 'TRANSACTION_UNBALANCED'
+  AND f.last_seen_at < sqlc.arg(seen_before)
+  AND EXISTS (
+    SELECT 1 FROM entries e
+    WHERE e.transaction_id = f.subject_id
+      AND e.created_at >= sqlc.arg(range_start) AND e.created_at < sqlc.arg(range_end)
+  );
+
+-- name: GetLedgerPeriodChecksum :one
+-- An order-independent checksum over the entries created in
+-- [period_start, period_end): the sum of a 60-bit hash of each entry.
+SELECT
+  COUNT(*)::bigint AS entry_count,
+  COALESCE(SUM(
+    ('x' || substr(md5(
+      e.id::text || '|' || e.transaction_id::text || '|' || e.account_id::text || '|' ||
+      e.amount::text || '|' || e.direction || '|' ||
+      (EXTRACT(EPOCH FROM e.created_at) * 1000000)::bigint::text
+    ), 1, 15))::bit(60)::bigint::numeric
+  ), 0)::text AS checksum
+FROM entries e
+WHERE e.created_at >= sqlc.arg(period_start) AND e.created_at < sqlc.arg(period_end);
+
+-- name: ListLedgerMonthSeals :many
+SELECT month FROM ledger_month_seals ORDER BY month;
+
+-- name: CreateLedgerMonthSeal :exec
+INSERT INTO ledger_month_seals (month, entry_count, checksum)
+VALUES ($1, $2, $3)
+ON CONFLICT (month) DO NOTHING;
+
+-- name: GetLedgerMonthSeal :one
+SELECT * FROM ledger_month_seals WHERE month = $1;
+
+-- name: GetLeastRecentlyVerifiedMonthSeal :one
+SELECT * FROM ledger_month_seals
+WHERE status <> 'ARCHIVED'
+ORDER BY verified_at ASC, month ASC
+LIMIT 1;
+
+-- name: MarkLedgerMonthSealVerified :exec
+UPDATE ledger_month_seals
+SET status = $2, verified_at = NOW()
+WHERE month = $1;
+
+-- name: ResolveLedgerPeriodFindings :execrows
+UPDATE reconciliation_findings
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE status = 'OPEN' AND finding_type = 'SEALED_PERIOD_CHANGED' AND period = $1;
+
+-- name: MarkLedgerMonthSealArchived :execrows
+UPDATE ledger_month_seals
+SET status = 'ARCHIVED'
+WHERE month = $1 AND status = 'SEALED';
@@ -0,0 +1,14 @@
+-- name: ListRolePermissions :many
+SELECT permission
+FROM role_permissions
+WHERE role = $1
+ORDER BY permission;
+
+-- name: ListRoles :many
+SELECT r.name, r.description,
+       ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role = r.name ORDER BY rp.permission)::text[] AS permissions
+FROM roles r
+ORDER BY r.name;
+
+-- name: RoleExists :one
+SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1);
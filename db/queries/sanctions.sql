@@ -0,0 +1,44 @@
+-- name: CreateSanctionsListLoad :one
+INSERT INTO sanctions_list_loads (source, entry_count, checksum, loaded_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteSanctionsEntriesBySource :execrows
+DELETE FROM sanctions_entries
+WHERE source = $1;
+
+-- name: InsertSanctionsEntries :execrows
+INSERT INTO sanctions_entries (load_id, source, external_id, name, entry_type, programs)
+SELECT sqlc.arg(load_id)::bigint, sqlc.arg(source)::text,
+       unnest(sqlc.arg(external_ids)::text[]),
+       unnest(sqlc.arg(names)::text[]),
+       unnest(sqlc.arg(entry_types)::text[]),
+       unnest(sqlc.arg(programs)::text[]);
+
+-- name: ListSanctionsEntries :many
+SELECT source, external_id, name, entry_type, programs
+FROM sanctions_entries
+ORDER BY id;
+
+-- name: GetSanctionsListVersion :one
+SELECT COALESCE(MAX(id), 0)::bigint FROM sanctions_list_loads;
+
+-- name: InsertPayoutScreening :exec
+INSERT INTO payout_screenings (payout_id, screened_name, list_source, list_entry_id, matched_name, score, release_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetPayoutScreening :one
+SELECT * FROM payout_screenings
+WHERE payout_id = $1;
+
+-- name: ListPayoutScreenings :many
+SELECT * FROM payout_screenings
+WHERE payout_id = ANY(sqlc.arg(payout_ids)::uuid[]);
+
+-- name: DecidePayoutScreening :execrows
+UPDATE payout_screenings
+SET decision = sqlc.arg(decision),
+    decided_by = sqlc.arg(decided_by),
+    decided_at = NOW(),
+    reason = sqlc.arg(reason)
+WHERE payout_id = sqlc.arg(payout_id) AND decision IS NULL;
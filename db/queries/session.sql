@@ -0,0 +1,65 @@
+-- name: CreateUserSession :exec
+INSERT INTO user_sessions (id, user_id, access_jti, access_expires_at, user_agent, ip_address, created_at, last_refreshed_at, expires_at)
+VALUES (sqlc.arg(id), sqlc.arg(user_id), sqlc.arg(access_jti), sqlc.arg(access_expires_at), sqlc.arg(user_agent), sqlc.arg(ip_address), sqlc.arg(now), sqlc.arg(now), sqlc.arg(expires_at));
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (token_hash, session_id, created_at)
+VALUES ($1, $2, $3);
+
+-- name: GetRefreshTokenForUpdate :one
+SELECT rt.used_at, s.id AS session_id, s.user_id, s.access_jti, s.access_expires_at, s.expires_at, s.revoked_at, u.role
+FROM refresh_tokens rt
+JOIN user_sessions s ON s.id = rt.session_id
+JOIN users u ON u.id = s.user_id
+WHERE rt.token_hash = $1
+FOR UPDATE OF rt, s;
+
+-- name: MarkRefreshTokenUsed :exec
+UPDATE refresh_tokens
+SET used_at = sqlc.arg(now)
+WHERE token_hash = sqlc.arg(token_hash);
+
+-- name: RotateSessionAccessToken :exec
+UPDATE user_sessions
+SET access_jti = sqlc.arg(access_jti),
+    access_expires_at = sqlc.arg(access_expires_at),
+    last_refreshed_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id);
+
+-- name: GetSessionState :one
+SELECT user_id, access_jti, access_expires_at, expires_at, revoked_at
+FROM user_sessions
+WHERE id = $1;
+
+-- name: ListActiveUserSessions :many
+SELECT id, user_id, user_agent, ip_address, created_at, last_refreshed_at, expires_at
+FROM user_sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND expires_at > sqlc.arg(now)
+ORDER BY created_at DESC;
+
+-- name: RevokeUserSession :one
+UPDATE user_sessions
+SET revoked_at = sqlc.arg(now),
+    revoked_reason = sqlc.arg(reason)::text
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+RETURNING access_jti, access_expires_at;
+
+-- name: RevokeAllUserSessions :many
+UPDATE user_sessions
+SET revoked_at = sqlc.arg(now),
+    revoked_reason = sqlc.arg(reason)::text
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+RETURNING id, access_jti, access_expires_at;
+
+-- name: UpdateUserRole :one
+-- Returns the role the user had before.
+UPDATE users u
+SET role = sqlc.arg(role)
+FROM (SELECT p.id, p.role FROM users p WHERE p.id = sqlc.arg(id) FOR UPDATE) prev
+WHERE u.id = prev.id
+RETURNING prev.role AS previous_role;
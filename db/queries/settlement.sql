@@ -0,0 +1,85 @@
+-- name: GetSettlementFileBySHA256 :one
+SELECT * FROM settlement_files WHERE sha256 = $1;
+
+-- name: CreateSettlementFile :one
+INSERT INTO settlement_files (id, file_name, format, sha256, period_start, period_end, imported_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: UpdateSettlementFileCounts :one
+UPDATE settlement_files
+SET line_count = $2, matched_count = $3, break_count = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: ListSettlementFiles :many
+SELECT * FROM settlement_files
+ORDER BY imported_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetSettlementCoverageStart :one
+SELECT MIN(period_start)::timestamptz AS coverage_start FROM settlement_files;
+
+-- name: CreateSettlementLine :one
+INSERT INTO settlement_lines (id, file_id, line_no, gateway_ref, amount_micros, currency, direction, booked_at, description, payout_id, match_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: CountSettlementDebitsForPayout :one
+SELECT COUNT(*)::bigint FROM settlement_lines
+WHERE payout_id = $1 AND direction = 'debit';
+
+-- name: CreateReconciliationBreak :execrows
+INSERT INTO reconciliation_breaks (id, break_type, payout_id, line_id, file_id, gateway_ref, currency, expected_micros, actual_micros, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT DO NOTHING;
+
+-- name: GetReconciliationBreak :one
+SELECT * FROM reconciliation_breaks WHERE id = $1;
+
+-- name: ListReconciliationBreaks :many
+SELECT * FROM reconciliation_breaks
+WHERE (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status)::text)
+  AND (sqlc.narg(break_type)::text IS NULL OR break_type = sqlc.narg(break_type)::text)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: ClearReconciliationBreak :one
+UPDATE reconciliation_breaks
+SET status = 'CLEARED', cleared_by = $2, cleared_at = NOW(), resolution_note = $3
+WHERE id = $1 AND status = 'OPEN'
+RETURNING *;
+
+-- name: ClearMissingSettlementBreak :execrows
+UPDATE reconciliation_breaks
+SET status = 'CLEARED', cleared_at = NOW(), resolution_note = $2
+WHERE payout_id = $1 AND break_type = 'COMPLETED_BUT_MISSING' AND status = 'OPEN';
+
+-- name: ListCompletedPayoutsMissingSettlement :many
+SELECT p.* FROM payouts p
+WHERE p.status = 'COMPLETED'
+  AND p.updated_at >= sqlc.arg(completed_after)
+  AND p.updated_at < sqlc.arg(completed_before)
+  AND NOT EXISTS (
+    SELECT 1 FROM settlement_lines l
+    WHERE l.payout_id = p.id AND l.direction = 'debit'
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM reconciliation_breaks b
+    WHERE b.payout_id = p.id AND b.break_type = 'COMPLETED_BUT_MISSING'
+  )
+ORDER BY p.updated_at ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ListSettledLinesForFailedPayouts :many
+SELECT l.*, p.status AS payout_status, p.amount_micros AS payout_amount_micros
+FROM settlement_lines l
+INNER JOIN payouts p ON p.id = l.payout_id
+WHERE l.direction = 'debit'
+  AND p.status IN ('FAILED', 'REJECTED')
+  AND NOT EXISTS (
+    SELECT 1 FROM reconciliation_breaks b
+    WHERE b.line_id = l.id AND b.break_type = 'PAID_BUT_FAILED'
+  )
+ORDER BY l.created_at ASC
+LIMIT $1;
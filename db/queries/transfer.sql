@@ -4,10 +4,10 @@ FROM transactions
 WHERE reference_id = $1;
 
 -- name: LockAccount :one
-SELECT id FROM accounts WHERE id = $1 FOR UPDATE;
+SELECT status FROM accounts WHERE id = $1 FOR UPDATE;
 
 -- name: GetAccountBalanceAndCurrency :one
-SELECT balance, currency FROM accounts WHERE id = $1;
+SELECT balance, locked_micros, overdraft_limit_micros, currency FROM accounts WHERE id = $1;
 
 -- name: GetAccountCurrency :one
 SELECT currency FROM accounts WHERE id = $1;
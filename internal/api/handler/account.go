@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -20,7 +21,7 @@ func NewAccountHandler(svc *service.AccountService) *AccountHandler {
 }
 
 func (h *AccountHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -39,7 +40,7 @@ func (h *AccountHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, r, http.StatusInternalServerError, "account/balance-read-failed", "Failed to get balance")
 		return
 	}
-	if !isAdmin && account.UserID != actorID {
+	if account.UserID != actorID && !can(r, permission.AccountsReadAny) {
 		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
 		return
 	}
@@ -49,7 +50,7 @@ func (h *AccountHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AccountHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -67,7 +68,7 @@ func (h *AccountHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, r, http.StatusInternalServerError, "account/authorization-failed", "Failed to authorize account access")
 		return
 	}
-	if !isAdmin && account.UserID != actorID {
+	if account.UserID != actorID && !can(r, permission.AccountsReadAny) {
 		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
 		return
 	}
@@ -90,7 +91,7 @@ func (h *AccountHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -106,18 +107,26 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Balance != 0 {
+		RespondError(w, r, http.StatusBadRequest, "account/opening-balance-not-allowed", "Accounts open with a zero balance; opening balances are posted by finance staff")
+		return
+	}
+
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user_id")
 		return
 	}
-	if !isAdmin && userID != actorID {
+	if userID != actorID && !can(r, permission.AccountsCreateAny) {
 		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
 		return
 	}
 
-	account, err := h.svc.CreateAccount(r.Context(), userID, req.Currency, req.Balance)
+	account, err := h.svc.CreateAccount(r.Context(), userID, req.Currency)
 	if err != nil {
+		if respondKYCError(w, r, err) {
+			return
+		}
 		if status, pType, msg, ok := mapDBError(err); ok {
 			RespondError(w, r, status, pType, msg)
 			return
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AccountLifecycleHandler handles admin freeze, unfreeze and close requests.
+type AccountLifecycleHandler struct {
+	svc *service.AccountLifecycleService
+}
+
+// NewAccountLifecycleHandler creates a new AccountLifecycleHandler instance.
+func NewAccountLifecycleHandler(svc *service.AccountLifecycleService) *AccountLifecycleHandler {
+	return &AccountLifecycleHandler{svc: svc}
+}
+
+type accountStatusRequest struct {
+	Scope            string `json:"scope"`
+	Reason           string `json:"reason"`
+	Note             string `json:"note"`
+	SweepToAccountID string `json:"sweep_to_account_id"`
+}
+
+// FreezeAccount handles POST /v1/admin/accounts/{id}/freeze.
+// Scope DEBITS blocks outgoing movements; ALL blocks credits as well.
+func (h *AccountLifecycleHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, change, req, ok := parseAccountStatusRequest(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.svc.Freeze(r.Context(), accountID, strings.ToUpper(strings.TrimSpace(req.Scope)), change)
+	if err != nil {
+		respondAccountLifecycleError(w, r, err, "freeze")
+		return
+	}
+	RespondJSON(w, http.StatusOK, account)
+}
+
+// UnfreezeAccount handles POST /v1/admin/accounts/{id}/unfreeze.
+func (h *AccountLifecycleHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, change, _, ok := parseAccountStatusRequest(w, r)
+	if !ok {
+		return
+	}
+	account, err := h.svc.Unfreeze(r.Context(), accountID, change)
+	if err != nil {
+		respondAccountLifecycleError(w, r, err, "unfreeze")
+		return
+	}
+	RespondJSON(w, http.StatusOK, account)
+}
+
+// CloseAccount handles POST /v1/admin/accounts/{id}/close.
+// A non-zero balance is swept to sweep_to_account_id before closing.
+func (h *AccountLifecycleHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, change, req, ok := parseAccountStatusRequest(w, r)
+	if !ok {
+		return
+	}
+	var sweepTo *uuid.UUID
+	if req.SweepToAccountID != "" {
+		id, err := uuid.Parse(req.SweepToAccountID)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-sweep-account-id", "Invalid sweep_to_account_id")
+			return
+		}
+		sweepTo = &id
+	}
+	result, err := h.svc.Close(r.Context(), accountID, change, sweepTo)
+	if err != nil {
+		respondAccountLifecycleError(w, r, err, "close")
+		return
+	}
+	RespondJSON(w, http.StatusOK, result)
+}
+
+func parseAccountStatusRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, service.StatusChange, accountStatusRequest, bool) {
+	var req accountStatusRequest
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return uuid.Nil, service.StatusChange{}, req, false
+	}
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-account-id", "Invalid account ID")
+		return uuid.Nil, service.StatusChange{}, req, false
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return uuid.Nil, service.StatusChange{}, req, false
+	}
+	change := service.StatusChange{
+		ActorID: actorID,
+		Reason:  strings.ToUpper(strings.TrimSpace(req.Reason)),
+		Note:    strings.TrimSpace(req.Note),
+	}
+	return accountID, change, req, true
+}
+
+func respondAccountLifecycleError(w http.ResponseWriter, r *http.Request, err error, op string) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		RespondError(w, r, http.StatusNotFound, "account/not-found", "Account not found")
+	case errors.Is(err, service.ErrInvalidStatusReason),
+		errors.Is(err, service.ErrInvalidFreezeScope),
+		errors.Is(err, service.ErrInvalidSweepAccount),
+		errors.Is(err, service.ErrCurrencyMismatch),
+		errors.Is(err, service.ErrSystemAccountStatus):
+		RespondError(w, r, http.StatusBadRequest, "account/invalid-status-change", err.Error())
+	case errors.Is(err, service.ErrAccountNotFrozen):
+		RespondError(w, r, http.StatusConflict, "account/not-frozen", err.Error())
+	case errors.Is(err, service.ErrAccountBalanceNotZero):
+		RespondError(w, r, http.StatusConflict, "account/balance-not-zero", err.Error())
+	case errors.Is(err, service.ErrAccountFundsLocked):
+		RespondError(w, r, http.StatusConflict, "account/funds-locked", err.Error())
+	default:
+		if respondAccountStatusError(w, r, err) {
+			return
+		}
+		zap.L().Error("account "+op+" failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "account/"+op+"-failed", "Failed to "+op+" account")
+	}
+}
+
+// respondAccountStatusError writes a 409 problem when err reports a frozen
+// or closed account and returns whether it did.
+func respondAccountStatusError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrAccountFrozen):
+		RespondError(w, r, http.StatusConflict, "account/frozen", err.Error())
+	case errors.Is(err, service.ErrAccountClosed):
+		RespondError(w, r, http.StatusConflict, "account/closed", err.Error())
+	default:
+		return false
+	}
+	return true
+}
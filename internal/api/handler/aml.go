@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// AMLHandler handles admin review of transaction monitoring rules, alerts
+// and cases.
+type AMLHandler struct {
+	svc *service.AMLService
+}
+
+// NewAMLHandler creates a new AMLHandler instance.
+func NewAMLHandler(svc *service.AMLService) *AMLHandler {
+	return &AMLHandler{svc: svc}
+}
+
+// ListRules handles GET /v1/admin/aml/rules.
+func (h *AMLHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.ListRules(r.Context())
+	if err != nil {
+		zap.L().Error("list aml rules failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "aml/list-failed", "Failed to list AML rules")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{"rules": rules})
+}
+
+// UpdateRule handles PUT /v1/admin/aml/rules/{code}. The body
+// replaces every parameter of the rule.
+func (h *AMLHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	var req service.AMLRuleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	rule, err := h.svc.UpdateRule(r.Context(), chi.URLParam(r, "code"), req, actorID)
+	if err != nil {
+		respondAMLError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, rule)
+}
+
+// ListAlerts handles GET /v1/admin/aml/alerts, optionally
+// filtered by rule, case_id and user_id.
+func (h *AMLHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := service.AMLAlertFilter{RuleCode: strings.ToUpper(strings.TrimSpace(query.Get("rule")))}
+	switch filter.RuleCode {
+	case "", domain.AMLRuleStructuring, domain.AMLRuleRapidMovement, domain.AMLRuleFXRoundTrip:
+	default:
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-rule", "rule must be STRUCTURING, RAPID_MOVEMENT or FX_ROUND_TRIP")
+		return
+	}
+	if v := strings.TrimSpace(query.Get("case_id")); v != "" {
+		caseID, err := uuid.Parse(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-case-id", "Invalid case ID")
+			return
+		}
+		filter.CaseID = &caseID
+	}
+	if v := strings.TrimSpace(query.Get("user_id")); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+			return
+		}
+		filter.UserID = &userID
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	alerts, total, err := h.svc.ListAlerts(r.Context(), filter, limit, offset)
+	if err != nil {
+		zap.L().Error("list aml alerts failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "aml/list-failed", "Failed to list AML alerts")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":       alerts,
+		"limit":       limit,
+		"offset":      offset,
+		"count":       len(alerts),
+		"total_count": total,
+	})
+}
+
+// ListCases handles GET /v1/admin/aml/cases, optionally
+// filtered by status.
+func (h *AMLHandler) ListCases(w http.ResponseWriter, r *http.Request) {
+	status := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status")))
+	switch status {
+	case "", domain.AMLCaseOpen, domain.AMLCaseInvestigating, domain.AMLCaseEscalated, domain.AMLCaseClosed:
+	default:
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-status", "status must be OPEN, INVESTIGATING, ESCALATED or CLOSED")
+		return
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	cases, total, err := h.svc.ListCases(r.Context(), status, limit, offset)
+	if err != nil {
+		zap.L().Error("list aml cases failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "aml/list-failed", "Failed to list AML cases")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":       cases,
+		"limit":       limit,
+		"offset":      offset,
+		"count":       len(cases),
+		"total_count": total,
+	})
+}
+
+// GetCase handles GET /v1/admin/aml/cases/{id}.
+func (h *AMLHandler) GetCase(w http.ResponseWriter, r *http.Request) {
+	caseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-case-id", "Invalid case ID")
+		return
+	}
+	amlCase, err := h.svc.GetCase(r.Context(), caseID)
+	if err != nil {
+		respondAMLError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, amlCase)
+}
+
+// UpdateCase handles POST /v1/admin/aml/cases/{id}/status. It
+// moves the case through OPEN, INVESTIGATING, ESCALATED and CLOSED and can
+// reassign it.
+func (h *AMLHandler) UpdateCase(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	caseID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-case-id", "Invalid case ID")
+		return
+	}
+	var req service.AMLCaseUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	amlCase, err := h.svc.UpdateCase(r.Context(), caseID, req, actorID)
+	if err != nil {
+		respondAMLError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, amlCase)
+}
+
+func respondAMLError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAMLRule):
+		RespondError(w, r, http.StatusBadRequest, "aml/invalid-rule", err.Error())
+	case errors.Is(err, service.ErrAMLRuleNotFound):
+		RespondError(w, r, http.StatusNotFound, "aml/rule-not-found", "AML rule not found")
+	case errors.Is(err, service.ErrAMLCaseNotFound):
+		RespondError(w, r, http.StatusNotFound, "aml/case-not-found", "AML case not found")
+	case errors.Is(err, service.ErrInvalidAMLCaseUpdate):
+		RespondError(w, r, http.StatusConflict, "aml/invalid-case-update", err.Error())
+	default:
+		if status, problemType, message, ok := mapDBError(err); ok {
+			RespondError(w, r, status, problemType, message)
+			return
+		}
+		zap.L().Error("aml request failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "aml/update-failed", "Failed to update AML records")
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/api/middleware"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// APIKeyHandler lets users issue and manage API keys for their services.
+// Keys are managed with a login token only, so a leaked key cannot mint
+// more keys.
+type APIKeyHandler struct {
+	svc *service.AuthService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler instance.
+func NewAPIKeyHandler(svc *service.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+// CreateAPIKey handles POST /v1/api-keys. The key is only ever returned
+// here.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestKeyOwner(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	issued, err := h.svc.CreateAPIKey(r.Context(), userID, service.APIKeySpec{Name: req.Name, Scopes: req.Scopes, ExpiresAt: req.ExpiresAt})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKey) {
+			RespondError(w, r, http.StatusBadRequest, "api-key/invalid", err.Error())
+			return
+		}
+		zap.L().Error("create api key failed", zap.Error(err), zap.String("user_id", userID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "api-key/create-failed", "Failed to create API key")
+		return
+	}
+	RespondJSON(w, http.StatusCreated, issued)
+}
+
+// ListAPIKeys handles GET /v1/api-keys.
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestKeyOwner(w, r)
+	if !ok {
+		return
+	}
+	keys, err := h.svc.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		zap.L().Error("list api keys failed", zap.Error(err), zap.String("user_id", userID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "api-key/list-failed", "Failed to list API keys")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items": keys,
+		"count": len(keys),
+	})
+}
+
+// RotateAPIKey handles POST /v1/api-keys/{id}/rotate. The old key keeps
+// working for the configured grace period.
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestKeyOwner(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-api-key-id", "Invalid API key ID")
+		return
+	}
+	issued, err := h.svc.RotateAPIKey(r.Context(), userID, keyID)
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			RespondError(w, r, http.StatusNotFound, "api-key/not-found", "API key not found")
+			return
+		}
+		zap.L().Error("rotate api key failed", zap.Error(err), zap.String("api_key_id", keyID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "api-key/rotate-failed", "Failed to rotate API key")
+		return
+	}
+	RespondJSON(w, http.StatusCreated, issued)
+}
+
+// RevokeAPIKey handles DELETE /v1/api-keys/{id}.
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requestKeyOwner(w, r)
+	if !ok {
+		return
+	}
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-api-key-id", "Invalid API key ID")
+		return
+	}
+	if err := h.svc.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			RespondError(w, r, http.StatusNotFound, "api-key/not-found", "API key not found")
+			return
+		}
+		zap.L().Error("revoke api key failed", zap.Error(err), zap.String("api_key_id", keyID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "api-key/revoke-failed", "Failed to revoke API key")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requestKeyOwner returns the caller, refusing requests made with an API key.
+func requestKeyOwner(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if middleware.APIKeyFromContext(r.Context()) != nil {
+		RespondError(w, r, http.StatusForbidden, "api-key/login-required", "API keys are managed with a login token")
+		return uuid.Nil, false
+	}
+	userID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
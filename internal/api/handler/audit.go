@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxAuditPageSize caps limit on GET /v1/admin/audit.
+const maxAuditPageSize = 500
+
+// AuditHandler handles admin requests for the audit trail.
+type AuditHandler struct {
+	svc *service.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler instance.
+func NewAuditHandler(svc *service.AuditService) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// VerifyChain handles GET /v1/admin/audit/verify.
+// It walks the whole hash chain; a broken chain is reported with
+// "valid": false and the breaks found, not as an error status.
+func (h *AuditHandler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.VerifyChain(r.Context())
+	if err != nil {
+		zap.L().Error("verify audit chain failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "audit/verify-failed", "Failed to verify audit chain")
+		return
+	}
+	RespondJSON(w, http.StatusOK, result)
+}
+
+// ListAuditLogs handles GET /v1/admin/audit.
+// Optional filters: entity_type, entity_id, actor_id, action, and an RFC 3339
+// from/to range (to is exclusive). Pages are newest first; pass next_cursor
+// back as ?cursor= for the next page. With ?format=csv every matching record
+// is streamed as CSV instead and limit and cursor are ignored.
+func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	filter, ok := parseAuditLogFilter(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	switch strings.ToLower(strings.TrimSpace(query.Get("format"))) {
+	case "":
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-log.csv"`)
+		w.WriteHeader(http.StatusOK)
+		if err := h.svc.ExportAuditLogs(r.Context(), filter, w); err != nil {
+			// The status line is already sent; the truncated file is all we can signal.
+			zap.L().Error("export audit logs failed", zap.Error(err), zap.String("actor_id", actorID.String()))
+			return
+		}
+		zap.L().Info("audit logs exported", zap.String("actor_id", actorID.String()), zap.String("query", r.URL.RawQuery))
+		return
+	default:
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-format", "format must be csv")
+		return
+	}
+
+	limit := int32(50)
+	if v := strings.TrimSpace(query.Get("limit")); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > maxAuditPageSize {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-limit", "limit must be an integer between 1 and 500")
+			return
+		}
+		limit = int32(parsed)
+	}
+	cursor := strings.TrimSpace(query.Get("cursor"))
+
+	entries, next, err := h.svc.ListAuditLogs(r.Context(), filter, cursor, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrAuditCursorInvalid) {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-cursor", "Invalid cursor")
+			return
+		}
+		zap.L().Error("list audit logs failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "audit/list-failed", "Failed to list audit logs")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":       entries,
+		"limit":       limit,
+		"count":       len(entries),
+		"next_cursor": next,
+	})
+}
+
+// GetTransactionAuditTrail handles GET /v1/transactions/{id}/audit.
+// Customers see the redacted history of transactions on their own accounts.
+func (h *AuditHandler) GetTransactionAuditTrail(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	transactionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-transaction-id", "Invalid transaction ID")
+		return
+	}
+
+	events, err := h.svc.TransactionAuditTrail(r.Context(), transactionID, actorID, can(r, permission.AccountsReadAny))
+	if err != nil {
+		if errors.Is(err, service.ErrTransactionNotFound) {
+			RespondError(w, r, http.StatusNotFound, "transaction/not-found", "Transaction not found")
+			return
+		}
+		zap.L().Error("get transaction audit trail failed", zap.Error(err), zap.String("transaction_id", transactionID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "audit/trail-failed", "Failed to load audit trail")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"transaction_id": transactionID,
+		"items":          events,
+	})
+}
+
+// parseAuditLogFilter reads the audit log filters, writing a problem response
+// and returning ok=false when one is malformed.
+func parseAuditLogFilter(w http.ResponseWriter, r *http.Request) (service.AuditLogFilter, bool) {
+	query := r.URL.Query()
+	filter := service.AuditLogFilter{
+		EntityType: strings.TrimSpace(query.Get("entity_type")),
+		Action:     strings.TrimSpace(query.Get("action")),
+	}
+	if v := strings.TrimSpace(query.Get("entity_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-entity-id", "entity_id must be a UUID")
+			return filter, false
+		}
+		filter.EntityID = &id
+	}
+	if v := strings.TrimSpace(query.Get("actor_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-actor-id", "actor_id must be a UUID")
+			return filter, false
+		}
+		filter.ActorID = &id
+	}
+	if v := strings.TrimSpace(query.Get("from")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-time-range", "from must be an RFC 3339 timestamp")
+			return filter, false
+		}
+		filter.From = &t
+	}
+	if v := strings.TrimSpace(query.Get("to")); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-time-range", "to must be an RFC 3339 timestamp")
+			return filter, false
+		}
+		filter.To = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-time-range", "from must be before to")
+		return filter, false
+	}
+	return filter, true
+}
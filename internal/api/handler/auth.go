@@ -2,54 +2,120 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ayo6706/payment-multicurrency/internal/api/middleware"
-	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type AuthHandler struct {
-	repo *repository.Repository
+	svc *service.AuthService
 }
 
-func NewAuthHandler(repo *repository.Repository) *AuthHandler {
-	return &AuthHandler{repo: repo}
+func NewAuthHandler(svc *service.AuthService) *AuthHandler {
+	return &AuthHandler{svc: svc}
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		UserID string `json:"user_id"` // Mock login by UserID
+		Email    string `json:"email"`
+		Password string `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
 		return
 	}
+	if strings.TrimSpace(req.Email) == "" || req.Password == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-credentials", "email and password are required")
+		return
+	}
 
-	uid, err := uuid.Parse(req.UserID)
+	user, err := h.svc.Authenticate(r.Context(), req.Email, req.Password)
 	if err != nil {
-		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user_id")
+		var lockedErr *service.AccountLockedError
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			RespondError(w, r, http.StatusUnauthorized, "auth/invalid-credentials", "Invalid email or password")
+		case errors.As(err, &lockedErr):
+			retryAfter := int(math.Ceil(time.Until(lockedErr.Until).Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			RespondError(w, r, http.StatusLocked, "auth/account-locked", "Too many failed logins; try again later or reset your password")
+		default:
+			zap.L().Error("login failed", zap.Error(err))
+			RespondError(w, r, http.StatusInternalServerError, "auth/login-failed", "Failed to log in")
+		}
 		return
 	}
 
-	user, err := h.repo.GetUser(r.Context(), uid)
+	tokens, err := h.svc.StartSession(r.Context(), user, service.SessionClient{UserAgent: r.UserAgent(), IPAddress: clientIP(r)})
 	if err != nil {
-		RespondError(w, r, http.StatusNotFound, "auth/user-not-found", "User not found")
+		zap.L().Error("start session failed", zap.Error(err), zap.String("user_id", user.ID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "auth/login-failed", "Failed to log in")
 		return
 	}
+	respondSessionTokens(w, r, tokens)
+}
 
+// Refresh handles POST /v1/auth/refresh. It rotates the refresh token: the
+// one presented stops working and a new one is returned with a new access
+// token.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	tokens, err := h.svc.RefreshSession(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			RespondError(w, r, http.StatusUnauthorized, "auth/invalid-refresh-token", "Refresh token is invalid or expired")
+			return
+		}
+		zap.L().Error("refresh session failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "auth/refresh-failed", "Failed to refresh session")
+		return
+	}
+	respondSessionTokens(w, r, tokens)
+}
+
+// Logout handles POST /v1/auth/logout. It ends the session of the access
+// token used to call it.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := requestSession(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Logout(r.Context(), userID, sessionID); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		zap.L().Error("logout failed", zap.Error(err), zap.String("session_id", sessionID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "auth/logout-failed", "Failed to log out")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func respondSessionTokens(w http.ResponseWriter, r *http.Request, tokens *service.SessionTokens) {
+	now := time.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": uid.String(),
-		"role":    user.Role,
+		"user_id": tokens.UserID.String(),
+		"role":    tokens.Role,
+		"scope":   strings.Join(tokens.Permissions, " "),
+		"sid":     tokens.SessionID.String(),
 		"iss":     middleware.JWTIssuer(),
 		"aud":     middleware.JWTAudience(),
-		"sub":     uid.String(),
-		"iat":     time.Now().Unix(),
-		"nbf":     time.Now().Add(-30 * time.Second).Unix(),
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"jti":     uuid.NewString(),
+		"sub":     tokens.UserID.String(),
+		"iat":     now.Unix(),
+		"nbf":     now.Add(-30 * time.Second).Unix(),
+		"exp":     tokens.AccessExpiresAt.Unix(),
+		"jti":     tokens.AccessJTI.String(),
 	})
 
 	tokenString, err := token.SignedString(middleware.JWTSecret())
@@ -58,8 +124,61 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"token": tokenString,
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"token":              tokenString,
+		"token_type":         "Bearer",
+		"expires_in":         int64(time.Until(tokens.AccessExpiresAt).Seconds()),
+		"refresh_token":      tokens.RefreshToken,
+		"refresh_expires_at": tokens.RefreshExpiresAt.UTC(),
+		"session_id":         tokens.SessionID,
+		"scope":              strings.Join(tokens.Permissions, " "),
+	})
+}
+
+// RequestPasswordReset handles POST /v1/auth/password-reset. It answers 202
+// whether or not the email is registered so it cannot be used to find
+// accounts.
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-email", "email is required")
+		return
+	}
+	if err := h.svc.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		zap.L().Error("password reset request failed", zap.Error(err))
+	}
+	RespondJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If the email is registered, a reset token has been sent to it",
 	})
 }
+
+// ConfirmPasswordReset handles POST /v1/auth/password-reset/confirm.
+func (h *AuthHandler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	if err := h.svc.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidResetToken):
+			RespondError(w, r, http.StatusBadRequest, "auth/invalid-reset-token", "Reset token is invalid or expired")
+		case errors.Is(err, service.ErrWeakPassword):
+			RespondError(w, r, http.StatusBadRequest, "auth/weak-password", err.Error())
+		default:
+			zap.L().Error("password reset failed", zap.Error(err))
+			RespondError(w, r, http.StatusInternalServerError, "auth/reset-failed", "Failed to reset password")
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
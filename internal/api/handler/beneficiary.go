@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BeneficiaryHandler handles HTTP requests for saved payout destinations.
+type BeneficiaryHandler struct {
+	svc *service.BeneficiaryService
+}
+
+// NewBeneficiaryHandler creates a new BeneficiaryHandler instance.
+func NewBeneficiaryHandler(svc *service.BeneficiaryService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{svc: svc}
+}
+
+// beneficiaryRequest is the body for creating or replacing a beneficiary.
+// UserID is only honoured for callers allowed to act for any user.
+type beneficiaryRequest struct {
+	UserID string `json:"user_id,omitempty"`
+	service.PayoutDestinationInput
+}
+
+// CreateBeneficiary handles POST /v1/beneficiaries
+func (h *BeneficiaryHandler) CreateBeneficiary(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+
+	var req beneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	ownerID, ok := beneficiaryOwner(w, r, req.UserID, actorID, permission.BeneficiariesWriteAny)
+	if !ok {
+		return
+	}
+	if err := req.PayoutDestinationInput.Validate(); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "beneficiary/invalid-details", strings.TrimPrefix(err.Error(), "destination."))
+		return
+	}
+
+	beneficiary, err := h.svc.CreateBeneficiary(r.Context(), ownerID, req.PayoutDestinationInput, &actorID)
+	if err != nil {
+		if status, problemType, msg, ok := mapDBError(err); ok {
+			RespondError(w, r, status, problemType, msg)
+			return
+		}
+		zap.L().Error("create beneficiary failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "beneficiary/create-failed", "Failed to create beneficiary")
+		return
+	}
+	RespondJSON(w, http.StatusCreated, beneficiary)
+}
+
+// ListBeneficiaries handles GET /v1/beneficiaries
+// Callers with beneficiaries:read:any may pass ?user_id= to list another
+// user's beneficiaries.
+func (h *BeneficiaryHandler) ListBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	ownerID, ok := beneficiaryOwner(w, r, r.URL.Query().Get("user_id"), actorID, permission.BeneficiariesReadAny)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListBeneficiaries(r.Context(), ownerID, limit, offset)
+	if err != nil {
+		zap.L().Error("list beneficiaries failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "beneficiary/list-failed", "Failed to list beneficiaries")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":  items,
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(items),
+	})
+}
+
+// GetBeneficiary handles GET /v1/beneficiaries/{id}
+func (h *BeneficiaryHandler) GetBeneficiary(w http.ResponseWriter, r *http.Request) {
+	beneficiary, _, ok := h.loadOwned(w, r, permission.BeneficiariesReadAny)
+	if !ok {
+		return
+	}
+	RespondJSON(w, http.StatusOK, beneficiary)
+}
+
+// UpdateBeneficiary handles PUT /v1/beneficiaries/{id}
+// Changing account details restarts the payout cool-down.
+func (h *BeneficiaryHandler) UpdateBeneficiary(w http.ResponseWriter, r *http.Request) {
+	beneficiary, actorID, ok := h.loadOwned(w, r, permission.BeneficiariesWriteAny)
+	if !ok {
+		return
+	}
+
+	var req beneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	if err := req.PayoutDestinationInput.Validate(); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "beneficiary/invalid-details", strings.TrimPrefix(err.Error(), "destination."))
+		return
+	}
+
+	updated, err := h.svc.UpdateBeneficiary(r.Context(), beneficiary.ID, req.PayoutDestinationInput, &actorID)
+	if err != nil {
+		if errors.Is(err, service.ErrBeneficiaryNotFound) {
+			RespondError(w, r, http.StatusNotFound, "beneficiary/not-found", "Beneficiary not found")
+			return
+		}
+		zap.L().Error("update beneficiary failed", zap.Error(err), zap.String("beneficiary_id", beneficiary.ID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "beneficiary/update-failed", "Failed to update beneficiary")
+		return
+	}
+	RespondJSON(w, http.StatusOK, updated)
+}
+
+// DeleteBeneficiary handles DELETE /v1/beneficiaries/{id}
+func (h *BeneficiaryHandler) DeleteBeneficiary(w http.ResponseWriter, r *http.Request) {
+	beneficiary, actorID, ok := h.loadOwned(w, r, permission.BeneficiariesWriteAny)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteBeneficiary(r.Context(), beneficiary.ID, &actorID); err != nil {
+		if errors.Is(err, service.ErrBeneficiaryNotFound) {
+			RespondError(w, r, http.StatusNotFound, "beneficiary/not-found", "Beneficiary not found")
+			return
+		}
+		zap.L().Error("delete beneficiary failed", zap.Error(err), zap.String("beneficiary_id", beneficiary.ID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "beneficiary/delete-failed", "Failed to delete beneficiary")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwned resolves the {id} beneficiary and checks the caller owns it or
+// holds anyPermission.
+func (h *BeneficiaryHandler) loadOwned(w http.ResponseWriter, r *http.Request, anyPermission string) (*models.Beneficiary, uuid.UUID, bool) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-beneficiary-id", "Invalid beneficiary ID")
+		return nil, uuid.Nil, false
+	}
+
+	beneficiary, err := h.svc.GetBeneficiary(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrBeneficiaryNotFound) {
+			RespondError(w, r, http.StatusNotFound, "beneficiary/not-found", "Beneficiary not found")
+			return nil, uuid.Nil, false
+		}
+		zap.L().Error("get beneficiary failed", zap.Error(err), zap.String("beneficiary_id", id.String()))
+		RespondError(w, r, http.StatusInternalServerError, "beneficiary/read-failed", "Failed to get beneficiary")
+		return nil, uuid.Nil, false
+	}
+	if beneficiary.UserID != actorID && !can(r, anyPermission) {
+		// Do not reveal that another user's beneficiary exists.
+		RespondError(w, r, http.StatusNotFound, "beneficiary/not-found", "Beneficiary not found")
+		return nil, uuid.Nil, false
+	}
+	return beneficiary, actorID, true
+}
+
+// beneficiaryOwner picks whose address book a request targets. Callers act
+// on their own unless they hold anyPermission, which lets them name any user.
+func beneficiaryOwner(w http.ResponseWriter, r *http.Request, requested string, actorID uuid.UUID, anyPermission string) (uuid.UUID, bool) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return actorID, true
+	}
+	ownerID, err := uuid.Parse(requested)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user_id")
+		return uuid.Nil, false
+	}
+	if ownerID != actorID && !can(r, anyPermission) {
+		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
+		return uuid.Nil, false
+	}
+	return ownerID, true
+}
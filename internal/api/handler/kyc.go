@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// KYCHandler handles KYC tiers, admin KYC decisions and the verification
+// provider's webhook.
+type KYCHandler struct {
+	svc *service.KYCService
+}
+
+// NewKYCHandler creates a new KYCHandler instance.
+func NewKYCHandler(svc *service.KYCService) *KYCHandler {
+	return &KYCHandler{svc: svc}
+}
+
+// ListTiers handles GET /v1/admin/kyc/tiers.
+func (h *KYCHandler) ListTiers(w http.ResponseWriter, r *http.Request) {
+	tiers, err := h.svc.ListTiers(r.Context())
+	if err != nil {
+		zap.L().Error("list kyc tiers failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "kyc/list-failed", "Failed to list KYC tiers")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{"tiers": tiers})
+}
+
+// GetUserKYC handles GET /v1/admin/users/{id}/kyc.
+func (h *KYCHandler) GetUserKYC(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	kyc, err := h.svc.GetUserKYC(r.Context(), userID)
+	if err != nil {
+		respondKYCAdminError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, kyc)
+}
+
+// UpdateUserKYC handles PUT /v1/admin/users/{id}/kyc.
+func (h *KYCHandler) UpdateUserKYC(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	var req service.KYCUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	kyc, err := h.svc.UpdateUserKYC(r.Context(), userID, req, actorID)
+	if err != nil {
+		respondKYCAdminError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, kyc)
+}
+
+// HandleProviderWebhook handles POST /v1/webhooks/kyc.
+// It verifies the HMAC signature and applies the provider's decision.
+func (h *KYCHandler) HandleProviderWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		zap.L().Error("read kyc webhook body failed", zap.Error(err))
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Failed to read request body")
+		return
+	}
+
+	kyc, applied, err := h.svc.HandleProviderEvent(r.Context(), body, r.Header.Get("X-Webhook-Signature"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSignature):
+			RespondError(w, r, http.StatusUnauthorized, "webhook/invalid-signature", "Invalid signature")
+		case errors.Is(err, service.ErrInvalidWebhookPayload), errors.Is(err, service.ErrInvalidKYC):
+			RespondError(w, r, http.StatusBadRequest, "webhook/invalid-request", err.Error())
+		case errors.Is(err, service.ErrUserNotFound):
+			RespondError(w, r, http.StatusNotFound, "user/not-found", "User not found")
+		default:
+			zap.L().Error("process kyc webhook failed", zap.Error(err))
+			RespondError(w, r, http.StatusInternalServerError, "webhook/internal-failure", "Failed to process webhook")
+		}
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{"applied": applied, "kyc": kyc})
+}
+
+func respondKYCAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidKYC):
+		RespondError(w, r, http.StatusBadRequest, "kyc/invalid", err.Error())
+	case errors.Is(err, service.ErrUserNotFound):
+		RespondError(w, r, http.StatusNotFound, "user/not-found", "User not found")
+	default:
+		zap.L().Error("kyc request failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "kyc/update-failed", "Failed to process KYC request")
+	}
+}
+
+// respondKYCError writes a 403 problem when err reports an operation the
+// user's KYC tier does not allow and returns whether it did.
+func respondKYCError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrKYCCurrencyNotAllowed):
+		RespondError(w, r, http.StatusForbidden, "kyc/currency-not-allowed", err.Error())
+	case errors.Is(err, service.ErrKYCPayoutsNotAllowed):
+		RespondError(w, r, http.StatusForbidden, "kyc/payouts-not-allowed", err.Error())
+	case errors.Is(err, service.ErrKYCBalanceLimit):
+		RespondError(w, r, http.StatusForbidden, "kyc/balance-limit", err.Error())
+	default:
+		return false
+	}
+	return true
+}
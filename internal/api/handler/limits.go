@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/api/problem"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LimitHandler handles admin management of transaction limits.
+type LimitHandler struct {
+	svc *service.LimitService
+}
+
+// NewLimitHandler creates a new LimitHandler instance.
+func NewLimitHandler(svc *service.LimitService) *LimitHandler {
+	return &LimitHandler{svc: svc}
+}
+
+type limitRequest struct {
+	service.Limits
+	Note string `json:"note"`
+}
+
+// ListDefaults handles GET /v1/admin/limits.
+func (h *LimitHandler) ListDefaults(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.svc.ListDefaults(r.Context())
+	if err != nil {
+		zap.L().Error("list transaction limits failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "limits/list-failed", "Failed to list limits")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{"limits": rules})
+}
+
+// SetDefault handles PUT /v1/admin/limits/{type}/{currency}.
+// Omitted or null caps are not enforced.
+func (h *LimitHandler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	var req limitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	rule, err := h.svc.SetDefault(r.Context(), chi.URLParam(r, "type"), chi.URLParam(r, "currency"), req.Limits, actorID)
+	if err != nil {
+		respondLimitAdminError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, rule)
+}
+
+// DeleteDefault handles DELETE /v1/admin/limits/{type}/{currency}.
+func (h *LimitHandler) DeleteDefault(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	if err := h.svc.DeleteDefault(r.Context(), chi.URLParam(r, "type"), chi.URLParam(r, "currency"), actorID); err != nil {
+		respondLimitAdminError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListUserOverrides handles GET /v1/admin/users/{id}/limits.
+func (h *LimitHandler) ListUserOverrides(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	rules, err := h.svc.ListUserOverrides(r.Context(), userID)
+	if err != nil {
+		zap.L().Error("list limit overrides failed", zap.Error(err), zap.String("user_id", userID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "limits/list-failed", "Failed to list limits")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{"limits": rules})
+}
+
+// SetUserOverride handles PUT /v1/admin/users/{id}/limits/{type}/{currency}.
+// The override replaces the default for the user as a whole, or with an
+// account_id query parameter for that account only.
+func (h *LimitHandler) SetUserOverride(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	accountID, ok := limitAccount(w, r)
+	if !ok {
+		return
+	}
+	var req limitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	rule, err := h.svc.SetUserOverride(r.Context(), userID, accountID, chi.URLParam(r, "type"), chi.URLParam(r, "currency"), req.Limits, actorID, req.Note)
+	if err != nil {
+		respondLimitAdminError(w, r, err)
+		return
+	}
+	RespondJSON(w, http.StatusOK, rule)
+}
+
+// DeleteUserOverride handles DELETE /v1/admin/users/{id}/limits/{type}/{currency},
+// returning the user to the default limits. With an account_id query
+// parameter it removes only that account's override.
+func (h *LimitHandler) DeleteUserOverride(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	accountID, ok := limitAccount(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteUserOverride(r.Context(), userID, accountID, chi.URLParam(r, "type"), chi.URLParam(r, "currency"), actorID); err != nil {
+		respondLimitAdminError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// limitAccount parses the optional account_id query parameter; uuid.Nil
+// means the override covers every account.
+func limitAccount(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	raw := r.URL.Query().Get("account_id")
+	if raw == "" {
+		return uuid.Nil, true
+	}
+	accountID, err := uuid.Parse(raw)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-account-id", "Invalid account ID")
+		return uuid.Nil, false
+	}
+	return accountID, true
+}
+
+func respondLimitAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLimit):
+		RespondError(w, r, http.StatusBadRequest, "limits/invalid", err.Error())
+	case errors.Is(err, service.ErrLimitNotFound):
+		RespondError(w, r, http.StatusNotFound, "limits/not-found", err.Error())
+	default:
+		if status, problemType, message, ok := mapDBError(err); ok {
+			RespondError(w, r, status, problemType, message)
+			return
+		}
+		zap.L().Error("update transaction limits failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "limits/update-failed", "Failed to update limits")
+	}
+}
+
+// respondLimitError writes a 422 limits/exceeded problem when err reports a
+// limit breach and returns whether it did. The problem carries the breached
+// window, the cap and what was already used so clients can explain it.
+func respondLimitError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var limitErr *service.LimitExceededError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	extensions := map[string]any{
+		"txn_type":      limitErr.TxnType,
+		"currency":      limitErr.Currency,
+		"limit_window":  limitErr.Window,
+		"limit_micros":  limitErr.LimitMicros,
+		"used_micros":   limitErr.UsedMicros,
+		"amount_micros": limitErr.AmountMicros,
+	}
+	if !limitErr.ResetsAt.IsZero() {
+		extensions["resets_at"] = limitErr.ResetsAt.UTC().Format(time.RFC3339)
+	}
+	problem.WriteExtended(w, r, http.StatusUnprocessableEntity, problem.Type("limits/exceeded"), "", limitErr.Error(), extensions)
+	return true
+}
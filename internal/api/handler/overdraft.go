@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// OverdraftHandler handles admin changes to account overdraft terms.
+type OverdraftHandler struct {
+	svc *service.OverdraftService
+}
+
+// NewOverdraftHandler creates a new OverdraftHandler instance.
+func NewOverdraftHandler(svc *service.OverdraftService) *OverdraftHandler {
+	return &OverdraftHandler{svc: svc}
+}
+
+// SetOverdraft handles PUT /v1/admin/accounts/{id}/overdraft.
+// A limit of zero removes the overdraft once the account is back above zero.
+func (h *OverdraftHandler) SetOverdraft(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-account-id", "Invalid account ID")
+		return
+	}
+
+	var req struct {
+		LimitMicros int64  `json:"limit_micros"`
+		InterestBps int32  `json:"interest_bps"`
+		Note        string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+
+	account, err := h.svc.SetOverdraft(r.Context(), accountID, service.OverdraftTerms{
+		LimitMicros: req.LimitMicros,
+		InterestBps: req.InterestBps,
+	}, actorID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidOverdraftTerms):
+			RespondError(w, r, http.StatusBadRequest, "account/invalid-overdraft", err.Error())
+		case errors.Is(err, service.ErrAccountNotFound):
+			RespondError(w, r, http.StatusNotFound, "account/not-found", "Account not found")
+		case errors.Is(err, service.ErrOverdraftInUse):
+			RespondError(w, r, http.StatusConflict, "account/overdraft-in-use", err.Error())
+		default:
+			if respondAccountStatusError(w, r, err) {
+				return
+			}
+			zap.L().Error("set overdraft failed", zap.Error(err), zap.String("account_id", accountID.String()))
+			RespondError(w, r, http.StatusInternalServerError, "account/overdraft-failed", "Failed to update overdraft")
+		}
+		return
+	}
+	RespondJSON(w, http.StatusOK, account)
+}
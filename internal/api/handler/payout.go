@@ -1,13 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -35,11 +36,19 @@ type CreatePayoutRequest struct {
 	AmountMicros int64                          `json:"amount_micros"`
 	Currency     string                         `json:"currency"`
 	Destination  service.PayoutDestinationInput `json:"destination"`
+	// BeneficiaryID pays a saved beneficiary instead of an inline destination.
+	BeneficiaryID string `json:"beneficiary_id,omitempty"`
 }
 
 // CreatePayout handles POST /v1/payouts
 // It creates a new payout request and returns 202 Accepted.
 func (h *PayoutHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+
 	idempotencyKey := r.Header.Get("Idempotency-Key")
 	if idempotencyKey == "" {
 		RespondError(w, r, http.StatusBadRequest, "idempotency/missing-key", "Idempotency-Key header is required")
@@ -66,7 +75,19 @@ func (h *PayoutHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, r, http.StatusBadRequest, "request/missing-currency", "currency is required")
 		return
 	}
-	if err := req.Destination.Validate(); err != nil {
+	var beneficiaryID *uuid.UUID
+	if req.BeneficiaryID != "" {
+		parsed, err := uuid.Parse(req.BeneficiaryID)
+		if err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-beneficiary-id", "Invalid beneficiary_id")
+			return
+		}
+		if req.Destination != (service.PayoutDestinationInput{}) {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-destination", "destination and beneficiary_id are mutually exclusive")
+			return
+		}
+		beneficiaryID = &parsed
+	} else if err := req.Destination.Validate(); err != nil {
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-destination", err.Error())
 		return
 	}
@@ -80,18 +101,32 @@ func (h *PayoutHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
 
 	// Call service
 	payoutReq := service.RequestPayoutRequest{
-		AccountID:    accountID,
-		AmountMicros: req.AmountMicros,
-		Currency:     req.Currency,
-		Destination:  req.Destination,
-		ReferenceID:  idempotencyKey,
+		AccountID:     accountID,
+		AmountMicros:  req.AmountMicros,
+		Currency:      req.Currency,
+		Destination:   req.Destination,
+		ReferenceID:   idempotencyKey,
+		BeneficiaryID: beneficiaryID,
+		RequestedBy:   &actorID,
 	}
 
 	resp, err := h.payoutSvc.RequestPayout(r.Context(), payoutReq)
 	if err != nil {
-		if errors.Is(err, models.ErrInsufficientFunds) {
+		switch {
+		case errors.Is(err, models.ErrInsufficientFunds):
 			RespondError(w, r, http.StatusBadRequest, "payout/insufficient-funds", err.Error())
 			return
+		case errors.Is(err, service.ErrBeneficiaryNotFound):
+			RespondError(w, r, http.StatusNotFound, "beneficiary/not-found", "Beneficiary not found")
+			return
+		case errors.Is(err, service.ErrBeneficiaryOwnerMismatch):
+			RespondError(w, r, http.StatusBadRequest, "payout/beneficiary-owner-mismatch", err.Error())
+			return
+		case errors.Is(err, service.ErrBeneficiaryCoolingDown):
+			RespondError(w, r, http.StatusConflict, "payout/beneficiary-cooling-down", err.Error())
+			return
+		case respondAccountStatusError(w, r, err), respondLimitError(w, r, err), respondKYCError(w, r, err):
+			return
 		}
 		zap.L().Error("create payout failed", zap.Error(err))
 		RespondError(w, r, http.StatusInternalServerError, "payout/create-failed", "Failed to create payout")
@@ -104,7 +139,7 @@ func (h *PayoutHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
 // GetPayout handles GET /v1/payouts/{id}
 // It returns the current status of a payout.
 func (h *PayoutHandler) GetPayout(w http.ResponseWriter, r *http.Request) {
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -129,7 +164,7 @@ func (h *PayoutHandler) GetPayout(w http.ResponseWriter, r *http.Request) {
 		RespondError(w, r, http.StatusInternalServerError, "payout/read-failed", "Failed to get payout")
 		return
 	}
-	if !isAdmin {
+	if !can(r, permission.PayoutsReadAny) {
 		account, accErr := h.repo.GetAccount(r.Context(), payout.AccountID)
 		if accErr != nil {
 			RespondError(w, r, http.StatusInternalServerError, "payout/account-read-failed", "Failed to verify payout ownership")
@@ -144,25 +179,11 @@ func (h *PayoutHandler) GetPayout(w http.ResponseWriter, r *http.Request) {
 	RespondJSON(w, http.StatusOK, payout)
 }
 
-// ListManualReviewPayouts handles GET /v1/payouts/manual-review (admin only).
+// ListManualReviewPayouts handles GET /v1/payouts/manual-review.
 func (h *PayoutHandler) ListManualReviewPayouts(w http.ResponseWriter, r *http.Request) {
-	limit := int32(50)
-	offset := int32(0)
-	if v := strings.TrimSpace(r.URL.Query().Get("limit")); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed <= 0 {
-			RespondError(w, r, http.StatusBadRequest, "request/invalid-limit", "limit must be a positive integer")
-			return
-		}
-		limit = int32(parsed)
-	}
-	if v := strings.TrimSpace(r.URL.Query().Get("offset")); v != "" {
-		parsed, err := strconv.Atoi(v)
-		if err != nil || parsed < 0 {
-			RespondError(w, r, http.StatusBadRequest, "request/invalid-offset", "offset must be a non-negative integer")
-			return
-		}
-		offset = int32(parsed)
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
 	}
 
 	payouts, err := h.payoutSvc.ListManualReviewPayouts(r.Context(), limit, offset)
@@ -191,9 +212,9 @@ type resolveManualReviewRequest struct {
 	GatewayRef *string `json:"gateway_ref,omitempty"`
 }
 
-// ResolveManualReviewPayout handles POST /v1/payouts/{id}/resolve (admin only).
+// ResolveManualReviewPayout handles POST /v1/payouts/{id}/resolve.
 func (h *PayoutHandler) ResolveManualReviewPayout(w http.ResponseWriter, r *http.Request) {
-	actorID, _, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -247,3 +268,187 @@ func (h *PayoutHandler) ResolveManualReviewPayout(w http.ResponseWriter, r *http
 
 	RespondJSON(w, http.StatusOK, result)
 }
+
+// ListPayoutsAwaitingApproval handles GET /v1/payouts/approvals.
+func (h *PayoutHandler) ListPayoutsAwaitingApproval(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	payouts, err := h.payoutSvc.ListPayoutsAwaitingApproval(r.Context(), limit, offset)
+	if err != nil {
+		zap.L().Error("list payouts awaiting approval failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "payout/approval-list-failed", "Failed to list payouts awaiting approval")
+		return
+	}
+	total, err := h.payoutSvc.ApprovalQueueSize(r.Context())
+	if err != nil {
+		zap.L().Warn("failed to compute approval queue size", zap.Error(err))
+		total = int64(len(payouts))
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":       payouts,
+		"limit":       limit,
+		"offset":      offset,
+		"count":       len(payouts),
+		"total_count": total,
+	})
+}
+
+type reviewPayoutRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ApprovePayout handles POST /v1/payouts/{id}/approve.
+func (h *PayoutHandler) ApprovePayout(w http.ResponseWriter, r *http.Request) {
+	h.reviewPayout(w, r, false, h.payoutSvc.ApprovePayout)
+}
+
+// RejectPayout handles POST /v1/payouts/{id}/reject.
+func (h *PayoutHandler) RejectPayout(w http.ResponseWriter, r *http.Request) {
+	h.reviewPayout(w, r, true, h.payoutSvc.RejectPayout)
+}
+
+func (h *PayoutHandler) reviewPayout(
+	w http.ResponseWriter,
+	r *http.Request,
+	reasonRequired bool,
+	decide func(context.Context, service.PayoutReviewRequest) (*models.Payout, error),
+) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	payoutID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-payout-id", "Invalid payout ID")
+		return
+	}
+
+	var req reviewPayoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+			return
+		}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if reasonRequired && req.Reason == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-reason", "reason is required")
+		return
+	}
+
+	result, err := decide(r.Context(), service.PayoutReviewRequest{
+		PayoutID: payoutID,
+		ActorID:  actorID,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPayoutNotFound):
+			RespondError(w, r, http.StatusNotFound, "payout/not-found", "Payout not found")
+			return
+		case errors.Is(err, service.ErrPayoutNotAwaitingApproval):
+			RespondError(w, r, http.StatusConflict, "payout/not-awaiting-approval", "Payout is not awaiting approval")
+			return
+		case errors.Is(err, service.ErrPayoutSelfApproval):
+			RespondError(w, r, http.StatusForbidden, "payout/self-approval", "Payout must be reviewed by someone other than its requester")
+			return
+		default:
+			zap.L().Error("review payout failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
+			RespondError(w, r, http.StatusInternalServerError, "payout/review-failed", "Failed to review payout")
+			return
+		}
+	}
+
+	RespondJSON(w, http.StatusOK, result)
+}
+
+// ListScreeningHoldPayouts handles GET /v1/payouts/screening-holds.
+func (h *PayoutHandler) ListScreeningHoldPayouts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	payouts, err := h.payoutSvc.ListScreeningHoldPayouts(r.Context(), limit, offset)
+	if err != nil {
+		zap.L().Error("list screening hold payouts failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "payout/screening-list-failed", "Failed to list payouts held by screening")
+		return
+	}
+	total, err := h.payoutSvc.ScreeningHoldQueueSize(r.Context())
+	if err != nil {
+		zap.L().Warn("failed to compute screening hold queue size", zap.Error(err))
+		total = int64(len(payouts))
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":       payouts,
+		"limit":       limit,
+		"offset":      offset,
+		"count":       len(payouts),
+		"total_count": total,
+	})
+}
+
+type resolveScreeningRequest struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+// ResolveScreeningHold handles POST /v1/payouts/{id}/screening/resolve.
+func (h *PayoutHandler) ResolveScreeningHold(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	payoutID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-payout-id", "Invalid payout ID")
+		return
+	}
+
+	var req resolveScreeningRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	req.Decision = strings.TrimSpace(strings.ToLower(req.Decision))
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Decision == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-decision", "decision is required")
+		return
+	}
+	if req.Reason == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-reason", "reason is required")
+		return
+	}
+
+	result, err := h.payoutSvc.ResolveScreeningHold(r.Context(), service.ResolveScreeningRequest{
+		PayoutID: payoutID,
+		Decision: service.ScreeningDecision(req.Decision),
+		Reason:   req.Reason,
+		ActorID:  actorID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPayoutNotFound):
+			RespondError(w, r, http.StatusNotFound, "payout/not-found", "Payout not found")
+		case errors.Is(err, service.ErrPayoutNotInScreeningHold):
+			RespondError(w, r, http.StatusConflict, "payout/not-in-screening-hold", "Payout is not held by screening")
+		case errors.Is(err, service.ErrInvalidScreeningDecision):
+			RespondError(w, r, http.StatusBadRequest, "payout/invalid-decision", "decision must be clear or confirm_match")
+		case errors.Is(err, service.ErrPayoutSelfApproval):
+			RespondError(w, r, http.StatusForbidden, "payout/self-approval", "Payout must be reviewed by someone other than its requester")
+		default:
+			zap.L().Error("resolve screening hold failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
+			RespondError(w, r, http.StatusInternalServerError, "payout/screening-resolve-failed", "Failed to resolve screening hold")
+		}
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// maxSettlementFileBytes caps an uploaded settlement file.
+const maxSettlementFileBytes = 32 << 20
+
+// ReconciliationHandler handles admin requests for reconciliation runs and
+// settlement files.
+type ReconciliationHandler struct {
+	svc *service.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new ReconciliationHandler instance.
+func NewReconciliationHandler(svc *service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{svc: svc}
+}
+
+// ImportSettlementFile handles POST /v1/admin/reconciliation/settlement-files.
+// The file is sent as multipart field "file"; an optional "format" field
+// (CSV or CAMT053) overrides detection from the content.
+func (h *ReconciliationHandler) ImportSettlementFile(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSettlementFileBytes)
+	if err := r.ParseMultipartForm(maxSettlementFileBytes); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Expected a multipart form with a file field")
+		return
+	}
+	part, header, err := r.FormFile("file")
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Expected a multipart form with a file field")
+		return
+	}
+	defer part.Close()
+	data, err := io.ReadAll(part)
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Failed to read settlement file")
+		return
+	}
+
+	file, err := h.svc.ImportSettlementFile(r.Context(), header.Filename, r.FormValue("format"), data, &actorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSettlementFileInvalid):
+			RespondError(w, r, http.StatusUnprocessableEntity, "reconciliation/invalid-settlement-file", err.Error())
+			return
+		case errors.Is(err, service.ErrSettlementFileDuplicate):
+			RespondError(w, r, http.StatusConflict, "reconciliation/duplicate-settlement-file", "Settlement file was already imported")
+			return
+		}
+		if status, problemType, msg, ok := mapDBError(err); ok {
+			RespondError(w, r, status, problemType, msg)
+			return
+		}
+		zap.L().Error("import settlement file failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/import-failed", "Failed to import settlement file")
+		return
+	}
+	RespondJSON(w, http.StatusCreated, file)
+}
+
+// ListSettlementFiles handles GET /v1/admin/reconciliation/settlement-files.
+func (h *ReconciliationHandler) ListSettlementFiles(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	files, err := h.svc.ListSettlementFiles(r.Context(), limit, offset)
+	if err != nil {
+		zap.L().Error("list settlement files failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/list-failed", "Failed to list settlement files")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":  files,
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(files),
+	})
+}
+
+// ListBreaks handles GET /v1/admin/reconciliation/breaks.
+// Optional ?status= and ?type= filter the result.
+func (h *ReconciliationHandler) ListBreaks(w http.ResponseWriter, r *http.Request) {
+	status := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("status")))
+	switch status {
+	case "", domain.BreakStatusOpen, domain.BreakStatusCleared:
+	default:
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-status", "status must be OPEN or CLEARED")
+		return
+	}
+	breakType := strings.ToUpper(strings.TrimSpace(r.URL.Query().Get("type")))
+	switch breakType {
+	case "", domain.BreakTypePaidButFailed, domain.BreakTypeCompletedButMissing, domain.BreakTypeAmountMismatch, domain.BreakTypeUnknownDebit:
+	default:
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-type", "type must be PAID_BUT_FAILED, COMPLETED_BUT_MISSING, AMOUNT_MISMATCH or UNKNOWN_DEBIT")
+		return
+	}
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	breaks, err := h.svc.ListReconciliationBreaks(r.Context(), status, breakType, limit, offset)
+	if err != nil {
+		zap.L().Error("list reconciliation breaks failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/list-failed", "Failed to list reconciliation breaks")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":  breaks,
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(breaks),
+	})
+}
+
+type clearBreakRequest struct {
+	Note string `json:"note"`
+}
+
+// ClearBreak handles POST /v1/admin/reconciliation/breaks/{id}/clear.
+func (h *ReconciliationHandler) ClearBreak(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	breakID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-break-id", "Invalid break ID")
+		return
+	}
+
+	var req clearBreakRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		RespondError(w, r, http.StatusBadRequest, "request/missing-note", "note is required")
+		return
+	}
+
+	cleared, err := h.svc.ClearReconciliationBreak(r.Context(), breakID, actorID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReconciliationBreakNotFound):
+			RespondError(w, r, http.StatusNotFound, "reconciliation/break-not-found", "Reconciliation break not found")
+			return
+		case errors.Is(err, service.ErrReconciliationBreakCleared):
+			RespondError(w, r, http.StatusConflict, "reconciliation/break-already-cleared", "Reconciliation break is already cleared")
+			return
+		}
+		zap.L().Error("clear reconciliation break failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/clear-failed", "Failed to clear reconciliation break")
+		return
+	}
+	RespondJSON(w, http.StatusOK, cleared)
+}
+
+type triggerRunRequest struct {
+	Scope string `json:"scope"`
+}
+
+// TriggerRun handles POST /v1/admin/reconciliation/runs.
+// The run executes in the background; poll GET .../runs/{id} for the outcome.
+// The optional body {"scope":"FULL"} rescans the whole ledger instead of
+// working from the checkpoint.
+func (h *ReconciliationHandler) TriggerRun(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+
+	var req triggerRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+
+	run, err := h.svc.TriggerRun(r.Context(), actorID, strings.ToUpper(strings.TrimSpace(req.Scope)))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrReconciliationScopeInvalid):
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-scope", "scope must be FULL or INCREMENTAL")
+			return
+		case errors.Is(err, service.ErrReconciliationRunInProgress):
+			RespondError(w, r, http.StatusConflict, "reconciliation/run-in-progress", "A reconciliation run is already in progress")
+			return
+		}
+		zap.L().Error("trigger reconciliation run failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/run-failed", "Failed to start reconciliation run")
+		return
+	}
+	RespondJSON(w, http.StatusAccepted, run)
+}
+
+// ListRuns handles GET /v1/admin/reconciliation/runs.
+func (h *ReconciliationHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.svc.ListRuns(r.Context(), limit, offset)
+	if err != nil {
+		zap.L().Error("list reconciliation runs failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/list-failed", "Failed to list reconciliation runs")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items":  runs,
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(runs),
+	})
+}
+
+// GetRun handles GET /v1/admin/reconciliation/runs/{id}.
+func (h *ReconciliationHandler) GetRun(w http.ResponseWriter, r *http.Request) {
+	runID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-run-id", "Invalid run ID")
+		return
+	}
+
+	run, err := h.svc.GetRun(r.Context(), runID)
+	if err != nil {
+		if errors.Is(err, service.ErrReconciliationRunNotFound) {
+			RespondError(w, r, http.StatusNotFound, "reconciliation/run-not-found", "Reconciliation run not found")
+			return
+		}
+		zap.L().Error("get reconciliation run failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "reconciliation/get-failed", "Failed to load reconciliation run")
+		return
+	}
+	RespondJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/ayo6706/payment-multicurrency/internal/api/middleware"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// SessionHandler lets users see and end their own sessions.
+type SessionHandler struct {
+	svc *service.AuthService
+}
+
+// NewSessionHandler creates a new SessionHandler instance.
+func NewSessionHandler(svc *service.AuthService) *SessionHandler {
+	return &SessionHandler{svc: svc}
+}
+
+// ListSessions handles GET /v1/sessions. The session of the calling token is
+// marked current.
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, sessionID, ok := requestSession(w, r)
+	if !ok {
+		return
+	}
+	sessions, err := h.svc.ListSessions(r.Context(), userID)
+	if err != nil {
+		zap.L().Error("list sessions failed", zap.Error(err), zap.String("user_id", userID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "session/list-failed", "Failed to list sessions")
+		return
+	}
+	type sessionResponse struct {
+		service.Session
+		Current bool `json:"current"`
+	}
+	items := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, sessionResponse{Session: session, Current: session.ID == sessionID})
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// RevokeSession handles DELETE /v1/sessions/{id}.
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := requestSession(w, r)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-session-id", "Invalid session ID")
+		return
+	}
+	if err := h.svc.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			RespondError(w, r, http.StatusNotFound, "session/not-found", "Session not found")
+			return
+		}
+		zap.L().Error("revoke session failed", zap.Error(err), zap.String("session_id", sessionID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "session/revoke-failed", "Failed to revoke session")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions handles DELETE /v1/sessions, signing the user out
+// everywhere, including the calling session.
+func (h *SessionHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := requestSession(w, r)
+	if !ok {
+		return
+	}
+	revoked, err := h.svc.RevokeAllSessions(r.Context(), userID)
+	if err != nil {
+		zap.L().Error("revoke sessions failed", zap.Error(err), zap.String("user_id", userID.String()))
+		RespondError(w, r, http.StatusInternalServerError, "session/revoke-failed", "Failed to revoke sessions")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]int{"revoked": revoked})
+}
+
+// requestSession returns the caller and the session of their token, writing
+// a problem response when either is missing.
+func requestSession(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+	sessionID, err := uuid.Parse(middleware.SessionIDFromContext(r.Context()))
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, sessionID, true
+}
+
+// clientIP returns the address the request came from, without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
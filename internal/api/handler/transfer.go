@@ -7,8 +7,10 @@ import (
 	"strings"
 
 	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
@@ -49,7 +51,7 @@ func (h *TransferHandler) MakeInternalTransfer(w http.ResponseWriter, r *http.Re
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-to-account-id", "Invalid to_account_id")
 		return
 	}
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -59,7 +61,7 @@ func (h *TransferHandler) MakeInternalTransfer(w http.ResponseWriter, r *http.Re
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-from-account-id", "Invalid from_account_id")
 		return
 	}
-	if !isAdmin && fromAcc.UserID != actorID {
+	if fromAcc.UserID != actorID && !can(r, permission.TransfersCreateAny) {
 		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
 		return
 	}
@@ -70,6 +72,9 @@ func (h *TransferHandler) MakeInternalTransfer(w http.ResponseWriter, r *http.Re
 			RespondError(w, r, http.StatusBadRequest, "transfer/insufficient-funds", err.Error())
 			return
 		}
+		if respondAccountStatusError(w, r, err) || respondLimitError(w, r, err) || respondKYCError(w, r, err) {
+			return
+		}
 		if errors.Is(err, service.ErrInvalidAmount) || errors.Is(err, service.ErrReferenceRequired) || errors.Is(err, service.ErrSameAccountTransfer) || errors.Is(err, service.ErrCurrencyMismatch) {
 			RespondError(w, r, http.StatusBadRequest, "transfer/invalid-request", err.Error())
 			return
@@ -126,7 +131,7 @@ func (h *TransferHandler) MakeExchangeTransfer(w http.ResponseWriter, r *http.Re
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-to-account-id", "Invalid to_account_id")
 		return
 	}
-	actorID, isAdmin, err := requestActor(r)
+	actorID, err := requestActor(r)
 	if err != nil {
 		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
 		return
@@ -136,7 +141,7 @@ func (h *TransferHandler) MakeExchangeTransfer(w http.ResponseWriter, r *http.Re
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-from-account-id", "Invalid from_account_id")
 		return
 	}
-	if !isAdmin && fromAcc.UserID != actorID {
+	if fromAcc.UserID != actorID && !can(r, permission.TransfersCreateAny) {
 		RespondError(w, r, http.StatusForbidden, "auth/insufficient-permissions", "insufficient permissions")
 		return
 	}
@@ -157,6 +162,9 @@ func (h *TransferHandler) MakeExchangeTransfer(w http.ResponseWriter, r *http.Re
 			RespondError(w, r, http.StatusBadRequest, "transfer/insufficient-funds", err.Error())
 			return
 		}
+		if respondAccountStatusError(w, r, err) || respondLimitError(w, r, err) || respondKYCError(w, r, err) {
+			return
+		}
 		if errors.Is(err, models.ErrUnsupportedCurrency) || errors.Is(err, models.ErrRateUnavailable) {
 			RespondError(w, r, http.StatusBadRequest, "transfer/exchange-invalid-request", err.Error())
 			return
@@ -176,3 +184,50 @@ func (h *TransferHandler) MakeExchangeTransfer(w http.ResponseWriter, r *http.Re
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(tx)
 }
+
+// PostOpeningBalance handles POST /v1/admin/accounts/{id}/opening-balance.
+// The account is credited from the equity account of its currency; an
+// account can be funded this way once.
+func (h *TransferHandler) PostOpeningBalance(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	accountID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-account-id", "Invalid account ID")
+		return
+	}
+
+	var req struct {
+		Amount int64  `json:"amount"`
+		Note   string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+
+	tx, err := h.svc.PostOpeningBalance(r.Context(), accountID, req.Amount, actorID, req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAmount):
+			RespondError(w, r, http.StatusBadRequest, "transfer/invalid-request", err.Error())
+		case errors.Is(err, service.ErrAccountNotFound):
+			RespondError(w, r, http.StatusNotFound, "account/not-found", "Account not found")
+		case errors.Is(err, service.ErrSystemAccountOpeningBalance):
+			RespondError(w, r, http.StatusBadRequest, "account/system-account", err.Error())
+		case errors.Is(err, service.ErrOpeningBalanceExists):
+			RespondError(w, r, http.StatusConflict, "account/opening-balance-exists", err.Error())
+		default:
+			if respondAccountStatusError(w, r, err) || respondKYCError(w, r, err) {
+				return
+			}
+			zap.L().Error("post opening balance failed", zap.Error(err), zap.String("account_id", accountID.String()))
+			RespondError(w, r, http.StatusInternalServerError, "account/opening-balance-failed", "Failed to post opening balance")
+		}
+		return
+	}
+	RespondJSON(w, http.StatusCreated, tx)
+}
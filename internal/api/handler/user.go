@@ -2,39 +2,48 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
-	"github.com/ayo6706/payment-multicurrency/internal/models"
-	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type UserHandler struct {
-	repo *repository.Repository
+	svc *service.AuthService
 }
 
-func NewUserHandler(repo *repository.Repository) *UserHandler {
-	return &UserHandler{repo: repo}
+func NewUserHandler(svc *service.AuthService) *UserHandler {
+	return &UserHandler{svc: svc}
 }
 
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Username string `json:"username"`
 		Email    string `json:"email"`
+		Password string `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
 		return
 	}
 
-	user := &models.User{
-		ID:       uuid.New(),
+	user, err := h.svc.Register(r.Context(), service.Registration{
 		Username: req.Username,
 		Email:    req.Email,
-		Role:     "user",
-	}
-	if err := h.repo.CreateUser(r.Context(), user); err != nil {
+		Password: req.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRegistration):
+			RespondError(w, r, http.StatusBadRequest, "user/invalid", err.Error())
+			return
+		case errors.Is(err, service.ErrWeakPassword):
+			RespondError(w, r, http.StatusBadRequest, "auth/weak-password", err.Error())
+			return
+		}
 		if status, pType, msg, ok := mapDBError(err); ok {
 			RespondError(w, r, status, pType, msg)
 			return
@@ -48,3 +57,54 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
 }
+
+// ListRoles handles GET /v1/admin/roles.
+func (h *UserHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.svc.ListRoles(r.Context())
+	if err != nil {
+		zap.L().Error("list roles failed", zap.Error(err))
+		RespondError(w, r, http.StatusInternalServerError, "user/list-roles-failed", "Failed to list roles")
+		return
+	}
+	RespondJSON(w, http.StatusOK, map[string]any{
+		"items": roles,
+		"count": len(roles),
+	})
+}
+
+// UpdateRole handles PUT /v1/admin/users/{id}/role. Every
+// session of the user ends, so a demotion takes effect immediately.
+func (h *UserHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	actorID, err := requestActor(r)
+	if err != nil {
+		RespondError(w, r, http.StatusUnauthorized, "auth/unauthorized", "Unauthorized")
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-user-id", "Invalid user ID")
+		return
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondError(w, r, http.StatusBadRequest, "request/invalid-body", "Invalid request body")
+		return
+	}
+
+	change, err := h.svc.ChangeRole(r.Context(), userID, req.Role, actorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRole):
+			RespondError(w, r, http.StatusBadRequest, "user/invalid-role", err.Error())
+		case errors.Is(err, service.ErrUserNotFound):
+			RespondError(w, r, http.StatusNotFound, "user/not-found", "User not found")
+		default:
+			zap.L().Error("change role failed", zap.Error(err), zap.String("user_id", userID.String()))
+			RespondError(w, r, http.StatusInternalServerError, "user/update-failed", "Failed to change role")
+		}
+		return
+	}
+	RespondJSON(w, http.StatusOK, change)
+}
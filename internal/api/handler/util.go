@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/ayo6706/payment-multicurrency/internal/api/middleware"
@@ -27,18 +28,48 @@ func RespondError(w http.ResponseWriter, r *http.Request, status int, problemTyp
 	problem.Write(w, r, status, problemType, http.StatusText(status), message)
 }
 
-func requestActor(r *http.Request) (uuid.UUID, bool, error) {
+// parsePagination reads limit and offset query parameters, writing a problem
+// response and returning ok=false when either is malformed.
+func parsePagination(w http.ResponseWriter, r *http.Request) (limit, offset int32, ok bool) {
+	limit = 50
+	if v := strings.TrimSpace(r.URL.Query().Get("limit")); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-limit", "limit must be a positive integer")
+			return 0, 0, false
+		}
+		limit = int32(parsed)
+	}
+	if v := strings.TrimSpace(r.URL.Query().Get("offset")); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			RespondError(w, r, http.StatusBadRequest, "request/invalid-offset", "offset must be a non-negative integer")
+			return 0, 0, false
+		}
+		offset = int32(parsed)
+	}
+	return limit, offset, true
+}
+
+func requestActor(r *http.Request) (uuid.UUID, error) {
 	userID := middleware.UserIDFromContext(r.Context())
 	if userID == "" {
-		return uuid.Nil, false, errors.New("missing user in auth context")
+		return uuid.Nil, errors.New("missing user in auth context")
 	}
 
 	actorID, err := uuid.Parse(userID)
 	if err != nil {
-		return uuid.Nil, false, errors.New("invalid user_id in auth context")
+		return uuid.Nil, errors.New("invalid user_id in auth context")
 	}
 
-	return actorID, middleware.UserRoleFromContext(r.Context()) == "admin", nil
+	return actorID, nil
+}
+
+// can reports whether the caller holds permission, for handlers whose
+// route permission covers the caller's own resources and that need an
+// ":any" permission to reach other users'.
+func can(r *http.Request, permission string) bool {
+	return middleware.HasPermission(r.Context(), permission)
 }
 
 func mapDBError(err error) (status int, problemType, message string, ok bool) {
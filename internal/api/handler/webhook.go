@@ -49,6 +49,9 @@ func (h *WebhookHandler) HandleDepositWebhook(w http.ResponseWriter, r *http.Req
 			RespondError(w, r, http.StatusConflict, "webhook/reference-mismatch", "Reference already used with different payload")
 			return
 		}
+		if respondAccountStatusError(w, r, err) || respondKYCError(w, r, err) {
+			return
+		}
 		if errors.Is(err, service.ErrInvalidWebhookPayload) {
 			RespondError(w, r, http.StatusBadRequest, "webhook/invalid-request", "Invalid webhook payload")
 			return
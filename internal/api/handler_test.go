@@ -5,12 +5,18 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/csv"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -20,8 +26,12 @@ import (
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
 	"github.com/ayo6706/payment-multicurrency/internal/gateway"
 	"github.com/ayo6706/payment-multicurrency/internal/idempotency"
+	"github.com/ayo6706/payment-multicurrency/internal/mail"
 	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/password"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/sanctions"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/ayo6706/payment-multicurrency/internal/testutil/dblock"
 	"github.com/golang-jwt/jwt/v5"
@@ -34,10 +44,37 @@ import (
 
 var testDB *pgxpool.Pool
 
+// testMailer captures the mail sent by the API under test.
+var testMailer = &recordingMailer{}
+
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []mail.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *recordingMailer) last() (mail.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		return mail.Message{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
+
 const (
 	testJWTSecret   = "test-secret-0123456789-test-secret"
 	testJWTIssuer   = "payment-multicurrency-test"
 	testJWTAudience = "payment-api-test"
+	testKYCSecret   = "kyc-test-secret"
+	// testPassword is set on users by loginAndGetToken.
+	testPassword = "Test-Password-0123"
 )
 
 func TestMain(m *testing.M) {
@@ -74,7 +111,7 @@ func TestMain(m *testing.M) {
 }
 
 func cleanupDB(t *testing.T) {
-	_, err := testDB.Exec(context.Background(), "TRUNCATE TABLE audit_log, payouts, users, accounts, transactions, entries, idempotency_keys CASCADE")
+	_, err := testDB.Exec(context.Background(), "TRUNCATE TABLE kyc_provider_events, api_keys, refresh_tokens, user_sessions, password_reset_tokens, aml_alerts, aml_cases, aml_observations, payout_screenings, sanctions_entries, sanctions_list_loads, limit_usage, user_limit_overrides, transaction_limits, audit_anchors, audit_chain_head, entries_archive_currency_totals, entries_archives, ledger_month_seals, ledger_dirty_days, ledger_day_totals, ledger_checkpoint, reconciliation_runs, reconciliation_findings, reconciliation_breaks, settlement_lines, settlement_files, outbox_events, audit_log, payouts, beneficiaries, users, accounts, transactions, entries, idempotency_keys CASCADE")
 	require.NoError(t, err)
 	seedSystemAccounts(t)
 }
@@ -82,10 +119,16 @@ func cleanupDB(t *testing.T) {
 func setupAPI() *api.Router {
 	repo := repository.NewRepository(testDB)
 	store := repository.NewStore(testDB)
-	accountSvc := service.NewAccountService(repo)
-	transferSvc := service.NewTransferService(store, service.NewMockExchangeRateService())
-	payoutSvc := service.NewPayoutService(store, gateway.NewMockGateway())
-	webhookSvc := service.NewWebhookService(store, "test", false)
+	kycSvc := service.NewKYCService(store).WithWebhookSecret(testKYCSecret)
+	accountSvc := service.NewAccountService(repo).WithKYC(kycSvc)
+	limitSvc := service.NewLimitService(store)
+	transferSvc := service.NewTransferService(store, service.NewMockExchangeRateService()).WithLimits(limitSvc).WithKYC(kycSvc)
+	screeningSvc := service.NewScreeningService(store)
+	payoutSvc := service.NewPayoutService(store, gateway.NewMockGateway()).WithLimits(limitSvc).WithKYC(kycSvc).WithScreening(screeningSvc)
+	webhookSvc := service.NewWebhookService(store, "test", false).WithKYC(kycSvc)
+	beneficiarySvc := service.NewBeneficiaryService(store).WithScreening(screeningSvc)
+	reconSvc := service.NewReconciliationService(store)
+	auditSvc := service.NewAuditService(store)
 	cfg := &config.Config{
 		HTTPPort:             "0",
 		JWTSecret:            testJWTSecret,
@@ -100,24 +143,41 @@ func setupAPI() *api.Router {
 		IdempotencyTTL:       time.Hour,
 	}
 	idemStore := idempotency.NewStore(nil, testDB, cfg.IdempotencyTTL)
-	return api.NewRouter(cfg, zap.NewNop(), testDB, repo, idemStore, nil, accountSvc, transferSvc, payoutSvc, webhookSvc)
+	return api.NewRouter(cfg, zap.NewNop(), testDB, repo, idemStore, nil, accountSvc, transferSvc, payoutSvc, webhookSvc, beneficiarySvc, reconSvc, auditSvc, service.NewAccountLifecycleService(store), service.NewOverdraftService(store), limitSvc, kycSvc, service.NewAMLService(store), service.NewAuthService(store).WithMailer(testMailer))
 }
 
 func generateTestToken(userID string) string {
 	return generateTokenWithRole(userID, "user")
 }
 
+// generateTokenWithRole signs a token for a session it opens directly in the
+// database, so userID must be an existing user.
 func generateTokenWithRole(userID, role string) string {
 	now := time.Now()
+	sessionID, jti := uuid.New(), uuid.New()
+	_, err := testDB.Exec(context.Background(), `
+		INSERT INTO user_sessions (id, user_id, access_jti, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $4)`, sessionID, userID, jti, now.Add(time.Hour))
+	if err != nil {
+		panic(fmt.Sprintf("open test session: %v", err))
+	}
+	var permissions []string
+	if err := testDB.QueryRow(context.Background(), `
+		SELECT COALESCE(array_agg(permission ORDER BY permission), '{}') FROM role_permissions WHERE role = $1`, role).Scan(&permissions); err != nil {
+		panic(fmt.Sprintf("load role permissions: %v", err))
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": userID,
 		"role":    role,
+		"scope":   strings.Join(permissions, " "),
+		"sid":     sessionID.String(),
 		"iss":     testJWTIssuer,
 		"aud":     testJWTAudience,
 		"sub":     userID,
 		"iat":     now.Unix(),
 		"nbf":     now.Add(-30 * time.Second).Unix(),
 		"exp":     now.Add(time.Hour).Unix(),
+		"jti":     jti.String(),
 	})
 	tokenString, _ := token.SignedString(middleware.JWTSecret())
 	return tokenString
@@ -207,6 +267,7 @@ func TestCreateUser(t *testing.T) {
 	payload := map[string]string{
 		"username": "ayo",
 		"email":    "ayo@example.com",
+		"password": "Correct-Horse-42",
 	}
 	body, _ := json.Marshal(payload)
 
@@ -235,6 +296,7 @@ func TestCreateUserIgnoresRequestedRole(t *testing.T) {
 	payload := map[string]string{
 		"username": "eve",
 		"email":    "eve@example.com",
+		"password": "Correct-Horse-42",
 		"role":     "admin",
 	}
 	body, _ := json.Marshal(payload)
@@ -250,7 +312,7 @@ func TestCreateUserIgnoresRequestedRole(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
 	assert.Equal(t, "user", response.Role)
 
-	loginBody, _ := json.Marshal(map[string]string{"user_id": response.ID.String()})
+	loginBody, _ := json.Marshal(map[string]string{"email": "eve@example.com", "password": "Correct-Horse-42"})
 	loginReq := httptest.NewRequest("POST", "/v1/auth/login", bytes.NewBuffer(loginBody))
 	loginReq.Header.Set("Content-Type", "application/json")
 	loginW := httptest.NewRecorder()
@@ -270,18 +332,51 @@ func TestCreateUserIgnoresRequestedRole(t *testing.T) {
 	assert.Equal(t, "user", claims["role"])
 }
 
-func TestAuthLoginInvalidUser(t *testing.T) {
+func TestCreateUserRejectsWeakPassword(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	router := a.Routes()
+
+	cases := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{name: "missing", password: "", want: "auth/weak-password"},
+		{name: "too_short", password: "Sh0rt-pass", want: "auth/weak-password"},
+		{name: "contains_username", password: "Mallory-Rules-42", want: "auth/weak-password"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"username": "mallory", "email": "mallory@example.com", "password": tc.password})
+			req := httptest.NewRequest("POST", "/v1/users", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestAuthLoginInvalidCredentials(t *testing.T) {
 	cleanupDB(t)
 	a := setupAPI()
 	client := a.Routes()
 
+	user := &models.User{ID: uuid.New(), Username: "known", Email: "known@example.com", Role: "user"}
+	require.NoError(t, repository.NewRepository(testDB).CreateUser(context.Background(), user))
+	setTestPassword(t, user.ID)
+
 	cases := []struct {
 		name string
 		body map[string]string
 		want int
 	}{
-		{name: "unknown_user", body: map[string]string{"user_id": uuid.New().String()}, want: http.StatusNotFound},
-		{name: "invalid_user_id_format", body: map[string]string{"user_id": "not-a-uuid"}, want: http.StatusBadRequest},
+		{name: "unknown_email", body: map[string]string{"email": "nobody@example.com", "password": testPassword}, want: http.StatusUnauthorized},
+		{name: "wrong_password", body: map[string]string{"email": "known@example.com", "password": "Wrong-Password-99"}, want: http.StatusUnauthorized},
+		{name: "missing_password", body: map[string]string{"email": "known@example.com"}, want: http.StatusBadRequest},
+		{name: "legacy_user_id", body: map[string]string{"user_id": user.ID.String()}, want: http.StatusBadRequest},
 	}
 
 	for _, tc := range cases {
@@ -298,6 +393,60 @@ func TestAuthLoginInvalidUser(t *testing.T) {
 	}
 }
 
+func TestAuthLockoutAndPasswordReset(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+
+	post := func(path string, payload map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/v1/users", map[string]string{"username": "sam", "email": "Sam@Example.com", "password": "Correct-Horse-42"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Equal(t, http.StatusOK, post("/v1/auth/login", map[string]string{"email": "sam@example.com", "password": "Correct-Horse-42"}).Code)
+
+	// Five wrong passwords lock the account; even the right one is refused.
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusUnauthorized, post("/v1/auth/login", map[string]string{"email": "sam@example.com", "password": "Wrong-Horse-42"}).Code)
+	}
+	w = post("/v1/auth/login", map[string]string{"email": "sam@example.com", "password": "Correct-Horse-42"})
+	require.Equal(t, http.StatusLocked, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/account-locked")
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Greater(t, retryAfter, 0)
+
+	// Unknown and known emails get the same answer.
+	require.Equal(t, http.StatusAccepted, post("/v1/auth/password-reset", map[string]string{"email": "ghost@example.com"}).Code)
+	if msg, sent := testMailer.last(); sent {
+		require.NotEqual(t, "ghost@example.com", msg.To)
+	}
+	require.Equal(t, http.StatusAccepted, post("/v1/auth/password-reset", map[string]string{"email": "sam@example.com"}).Code)
+	msg, sent := testMailer.last()
+	require.True(t, sent)
+	require.Equal(t, "sam@example.com", msg.To)
+	token := regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`).FindString(msg.Body)
+	require.NotEmpty(t, token)
+
+	w = post("/v1/auth/password-reset/confirm", map[string]string{"token": token, "password": "short"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/weak-password")
+	require.Equal(t, http.StatusNoContent, post("/v1/auth/password-reset/confirm", map[string]string{"token": token, "password": "Brand-New-Secret-7"}).Code)
+	w = post("/v1/auth/password-reset/confirm", map[string]string{"token": token, "password": "Another-Secret-8"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/invalid-reset-token")
+
+	// The reset lifts the lockout and replaces the old password.
+	require.Equal(t, http.StatusUnauthorized, post("/v1/auth/login", map[string]string{"email": "sam@example.com", "password": "Correct-Horse-42"}).Code)
+	require.Equal(t, http.StatusOK, post("/v1/auth/login", map[string]string{"email": "sam@example.com", "password": "Brand-New-Secret-7"}).Code)
+}
+
 func TestCreateAccount(t *testing.T) {
 	cleanupDB(t)
 	a := setupAPI()
@@ -317,7 +466,6 @@ func TestCreateAccount(t *testing.T) {
 	accPayload := map[string]interface{}{
 		"user_id":  u.ID,
 		"currency": "USD",
-		"balance":  1000,
 	}
 	accBody, _ := json.Marshal(accPayload)
 
@@ -335,8 +483,18 @@ func TestCreateAccount(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &accResp)
 		require.NoError(t, err)
 		assert.Equal(t, u.ID, accResp.UserID)
-		assert.Equal(t, int64(1000), accResp.Balance)
+		assert.Equal(t, int64(0), accResp.Balance)
 	}
+
+	// 3. A client-supplied opening balance is rejected
+	accPayload["balance"] = 1000
+	accBody, _ = json.Marshal(accPayload)
+	req = httptest.NewRequest("POST", "/v1/accounts", bytes.NewBuffer(accBody))
+	req.Header.Set("Authorization", "Bearer "+generateTestToken(u.ID.String()))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
 func TestCreateAccountInvalidCurrency(t *testing.T) {
@@ -616,7 +774,7 @@ func TestPayoutCreationAuthorizationAndSuccess(t *testing.T) {
 			u := &models.User{ID: uuid.New(), Username: tc.username, Email: tc.email}
 			require.NoError(t, repo.CreateUser(context.Background(), u))
 			if tc.makeAdmin {
-				_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(u.ID))
+				_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin', kyc_status='VERIFIED', kyc_tier=1 WHERE id=$1", repository.ToPgUUID(u.ID))
 				require.NoError(t, err)
 			}
 
@@ -650,13 +808,16 @@ func TestGetPayoutUnauthorizedAndNotFound(t *testing.T) {
 	a := setupAPI()
 	client := a.Routes()
 
+	user := &models.User{ID: uuid.New(), Username: "payout_reader", Email: "payout_reader@example.com", Role: "user"}
+	require.NoError(t, repository.NewRepository(testDB).CreateUser(context.Background(), user))
+
 	cases := []struct {
 		name   string
 		token  string
 		status int
 	}{
 		{name: "unauthorized", token: "", status: http.StatusUnauthorized},
-		{name: "authorized_not_found", token: generateTestToken(uuid.New().String()), status: http.StatusNotFound},
+		{name: "authorized_not_found", token: generateTestToken(user.ID.String()), status: http.StatusNotFound},
 	}
 
 	for _, tc := range cases {
@@ -947,8 +1108,30 @@ func computeHMAC(payload []byte, key string) string {
 	return "sha256=" + hex.EncodeToString(h.Sum(nil))
 }
 
+var (
+	testPasswordHashOnce sync.Once
+	testPasswordHash     string
+)
+
+// setTestPassword sets testPassword on the user and returns their email.
+func setTestPassword(t *testing.T, userID uuid.UUID) string {
+	t.Helper()
+	testPasswordHashOnce.Do(func() {
+		var err error
+		testPasswordHash, err = password.Hash(testPassword)
+		if err != nil {
+			panic(err)
+		}
+	})
+	var email string
+	err := testDB.QueryRow(context.Background(), "UPDATE users SET password_hash = $1 WHERE id = $2 RETURNING email", testPasswordHash, userID).Scan(&email)
+	require.NoError(t, err)
+	return email
+}
+
 func loginAndGetToken(t *testing.T, handler http.Handler, userID uuid.UUID) string {
-	payload := map[string]string{"user_id": userID.String()}
+	email := setTestPassword(t, userID)
+	payload := map[string]string{"email": email, "password": testPassword}
 	body, _ := json.Marshal(payload)
 	req := httptest.NewRequest("POST", "/v1/auth/login", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -977,7 +1160,10 @@ func seedSystemAccounts(t *testing.T) {
 		VALUES
 		('22222222-2222-2222-2222-222222222222','11111111-1111-1111-1111-111111111111','USD',0,0),
 		('33333333-3333-3333-3333-333333333333','11111111-1111-1111-1111-111111111111','EUR',0,0),
-		('44444444-4444-4444-4444-444444444444','11111111-1111-1111-1111-111111111111','GBP',0,0)
+		('44444444-4444-4444-4444-444444444444','11111111-1111-1111-1111-111111111111','GBP',0,0),
+		('55555555-5555-5555-5555-555555555555','11111111-1111-1111-1111-111111111111','USD',0,0),
+		('66666666-6666-6666-6666-666666666666','11111111-1111-1111-1111-111111111111','EUR',0,0),
+		('77777777-7777-7777-7777-777777777777','11111111-1111-1111-1111-111111111111','GBP',0,0)
 		ON CONFLICT (id) DO NOTHING;
 	`)
 	require.NoError(t, err)
@@ -1041,3 +1227,1216 @@ func TestTransferIdempotency(t *testing.T) {
 	assert.Equal(t, int64(50), updatedAcc1.Balance)
 	assert.Equal(t, int64(50), updatedAcc2.Balance)
 }
+
+func TestPayoutApprovalEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	queries := repository.New(testDB)
+
+	maker := &models.User{ID: uuid.New(), Username: "approval-maker", Email: "approval-maker@example.com"}
+	checker := &models.User{ID: uuid.New(), Username: "approval-checker", Email: "approval-checker@example.com"}
+	for _, u := range []*models.User{maker, checker} {
+		require.NoError(t, repo.CreateUser(context.Background(), u))
+		_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(u.ID))
+		require.NoError(t, err)
+	}
+
+	account := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repo.CreateAccount(context.Background(), account))
+
+	txID := uuid.New()
+	_, err := queries.CreateTransaction(context.Background(), repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(txID),
+		Amount:      5_000,
+		Currency:    "USD",
+		Type:        domain.TxTypePayout,
+		Status:      domain.TxStatusPending,
+		ReferenceID: "approval-" + uuid.NewString(),
+	})
+	require.NoError(t, err)
+
+	payoutID := uuid.New()
+	_, err = queries.InsertPayout(context.Background(), repository.InsertPayoutParams{
+		ID:            repository.ToPgUUID(payoutID),
+		TransactionID: repository.ToPgUUID(txID),
+		AccountID:     repository.ToPgUUID(account.ID),
+		AmountMicros:  5_000,
+		Currency:      "USD",
+		Status:        domain.PayoutStatusAwaitingApproval,
+		RequestedBy:   repository.ToPgUUID(maker.ID),
+	})
+	require.NoError(t, err)
+	_, err = testDB.Exec(context.Background(), "UPDATE accounts SET locked_micros=$1 WHERE id=$2", 5_000, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+
+	makerToken := loginAndGetToken(t, client, maker.ID)
+	checkerToken := loginAndGetToken(t, client, checker.ID)
+
+	listReq := httptest.NewRequest("GET", "/v1/payouts/approvals", nil)
+	listReq.Header.Set("Authorization", "Bearer "+checkerToken)
+	listW := httptest.NewRecorder()
+	client.ServeHTTP(listW, listReq)
+	require.Equal(t, http.StatusOK, listW.Code)
+	var listResp struct {
+		Items []models.Payout `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(listW.Body.Bytes(), &listResp))
+	require.Len(t, listResp.Items, 1)
+	require.Equal(t, payoutID, listResp.Items[0].ID)
+
+	selfReq := httptest.NewRequest("POST", "/v1/payouts/"+payoutID.String()+"/approve", nil)
+	selfReq.Header.Set("Authorization", "Bearer "+makerToken)
+	selfW := httptest.NewRecorder()
+	client.ServeHTTP(selfW, selfReq)
+	require.Equal(t, http.StatusForbidden, selfW.Code)
+
+	rejectReq := httptest.NewRequest("POST", "/v1/payouts/"+payoutID.String()+"/reject", bytes.NewReader([]byte(`{}`)))
+	rejectReq.Header.Set("Authorization", "Bearer "+checkerToken)
+	rejectReq.Header.Set("Content-Type", "application/json")
+	rejectW := httptest.NewRecorder()
+	client.ServeHTTP(rejectW, rejectReq)
+	require.Equal(t, http.StatusBadRequest, rejectW.Code)
+
+	approveReq := httptest.NewRequest("POST", "/v1/payouts/"+payoutID.String()+"/approve", bytes.NewReader([]byte(`{"reason":"callback verified"}`)))
+	approveReq.Header.Set("Authorization", "Bearer "+checkerToken)
+	approveReq.Header.Set("Content-Type", "application/json")
+	approveW := httptest.NewRecorder()
+	client.ServeHTTP(approveW, approveReq)
+	require.Equal(t, http.StatusOK, approveW.Code)
+
+	var approved models.Payout
+	require.NoError(t, json.Unmarshal(approveW.Body.Bytes(), &approved))
+	require.Equal(t, domain.PayoutStatusPending, approved.Status)
+	require.NotNil(t, approved.ReviewedBy)
+	require.Equal(t, checker.ID, *approved.ReviewedBy)
+}
+
+func TestBeneficiaryEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+
+	owner := &models.User{ID: uuid.New(), Username: "benef-owner", Email: "benef-owner@example.com"}
+	other := &models.User{ID: uuid.New(), Username: "benef-other", Email: "benef-other@example.com"}
+	require.NoError(t, repo.CreateUser(context.Background(), owner))
+	require.NoError(t, repo.CreateUser(context.Background(), other))
+	ownerToken := loginAndGetToken(t, client, owner.ID)
+	otherToken := loginAndGetToken(t, client, other.ID)
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	bad := send("POST", "/v1/beneficiaries", ownerToken, `{"name":"Bad","iban":"GB28NWBK60161331926819"}`)
+	require.Equal(t, http.StatusBadRequest, bad.Code)
+	assert.Contains(t, bad.Body.String(), "beneficiary/invalid-details")
+
+	created := send("POST", "/v1/beneficiaries", ownerToken, `{"name":"Landlord","iban":"GB29 NWBK 6016 1331 9268 19","bic":"NWBKGB2L"}`)
+	require.Equal(t, http.StatusCreated, created.Code)
+	var beneficiary models.Beneficiary
+	require.NoError(t, json.Unmarshal(created.Body.Bytes(), &beneficiary))
+	require.Equal(t, "GB29NWBK60161331926819", beneficiary.IBAN)
+	require.Equal(t, "IBAN", beneficiary.Scheme)
+
+	path := "/v1/beneficiaries/" + beneficiary.ID.String()
+	require.Equal(t, http.StatusNotFound, send("GET", path, otherToken, "").Code)
+	require.Equal(t, http.StatusForbidden, send("GET", "/v1/beneficiaries?user_id="+owner.ID.String(), otherToken, "").Code)
+
+	list := send("GET", "/v1/beneficiaries", ownerToken, "")
+	require.Equal(t, http.StatusOK, list.Code)
+	var listResp struct {
+		Items []models.Beneficiary `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(list.Body.Bytes(), &listResp))
+	require.Len(t, listResp.Items, 1)
+
+	require.Equal(t, http.StatusNoContent, send("DELETE", path, ownerToken, "").Code)
+	require.Equal(t, http.StatusNotFound, send("GET", path, ownerToken, "").Code)
+}
+
+func TestSettlementReconciliationEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+
+	admin := &models.User{ID: uuid.New(), Username: "recon-admin", Email: "recon-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "recon-user", Email: "recon-user@example.com"}
+	require.NoError(t, repo.CreateUser(context.Background(), admin))
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	upload := func(token, name, content string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+		req := httptest.NewRequest("POST", "/v1/admin/reconciliation/settlement-files", &body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	statement := "gateway_ref,amount,currency,booked_at\nunknown-ref,12.50,USD,2026-03-02\n"
+	require.Equal(t, http.StatusForbidden, upload(userToken, "bank.csv", statement).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, upload(adminToken, "bank.csv", "gateway_ref,amount\nx,1\n").Code)
+
+	imported := upload(adminToken, "bank.csv", statement)
+	require.Equal(t, http.StatusCreated, imported.Code, imported.Body.String())
+	var file models.SettlementFile
+	require.NoError(t, json.Unmarshal(imported.Body.Bytes(), &file))
+	require.Equal(t, int32(1), file.BreakCount)
+	require.Equal(t, http.StatusConflict, upload(adminToken, "bank-again.csv", statement).Code)
+
+	require.Equal(t, http.StatusBadRequest, send("GET", "/v1/admin/reconciliation/breaks?type=BOGUS", adminToken, "").Code)
+	list := send("GET", "/v1/admin/reconciliation/breaks?status=open&type=UNKNOWN_DEBIT", adminToken, "")
+	require.Equal(t, http.StatusOK, list.Code)
+	var listResp struct {
+		Items []models.ReconciliationBreak `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(list.Body.Bytes(), &listResp))
+	require.Len(t, listResp.Items, 1)
+
+	clearPath := "/v1/admin/reconciliation/breaks/" + listResp.Items[0].ID.String() + "/clear"
+	require.Equal(t, http.StatusBadRequest, send("POST", clearPath, adminToken, `{"note":" "}`).Code)
+	cleared := send("POST", clearPath, adminToken, `{"note":"bank fee, booked manually"}`)
+	require.Equal(t, http.StatusOK, cleared.Code)
+	var brk models.ReconciliationBreak
+	require.NoError(t, json.Unmarshal(cleared.Body.Bytes(), &brk))
+	require.Equal(t, domain.BreakStatusCleared, brk.Status)
+	require.Equal(t, http.StatusConflict, send("POST", clearPath, adminToken, `{"note":"again"}`).Code)
+}
+
+func TestReconciliationRunEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+
+	admin := &models.User{ID: uuid.New(), Username: "runs-admin", Email: "runs-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "runs-user", Email: "runs-user@example.com"}
+	require.NoError(t, repo.CreateUser(context.Background(), admin))
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	send := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, send("POST", "/v1/admin/reconciliation/runs", userToken).Code)
+	badScope := httptest.NewRequest("POST", "/v1/admin/reconciliation/runs", bytes.NewBufferString(`{"scope":"PARTIAL"}`))
+	badScope.Header.Set("Authorization", "Bearer "+adminToken)
+	badScopeResp := httptest.NewRecorder()
+	client.ServeHTTP(badScopeResp, badScope)
+	require.Equal(t, http.StatusBadRequest, badScopeResp.Code)
+	triggered := send("POST", "/v1/admin/reconciliation/runs", adminToken)
+	require.Equal(t, http.StatusAccepted, triggered.Code, triggered.Body.String())
+	var run models.ReconciliationRun
+	require.NoError(t, json.Unmarshal(triggered.Body.Bytes(), &run))
+	require.Equal(t, domain.RunTriggerManual, run.Trigger)
+	require.Equal(t, domain.RunScopeIncremental, run.Scope)
+
+	require.Eventually(t, func() bool {
+		w := send("GET", "/v1/admin/reconciliation/runs/"+run.ID.String(), adminToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+		return run.Status != domain.RunStatusRunning
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Equal(t, domain.RunStatusPassed, run.Status)
+
+	list := send("GET", "/v1/admin/reconciliation/runs", adminToken)
+	require.Equal(t, http.StatusOK, list.Code)
+	var listResp struct {
+		Items []models.ReconciliationRun `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(list.Body.Bytes(), &listResp))
+	require.Len(t, listResp.Items, 1)
+	require.Equal(t, http.StatusNotFound, send("GET", "/v1/admin/reconciliation/runs/"+uuid.New().String(), adminToken).Code)
+}
+
+func TestAuditChainVerifyEndpoint(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+
+	admin := &models.User{ID: uuid.New(), Username: "audit-admin", Email: "audit-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "audit-user", Email: "audit-user@example.com"}
+	require.NoError(t, repo.CreateUser(context.Background(), admin))
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	for i := 0; i < 3; i++ {
+		_, err := testDB.Exec(context.Background(), "INSERT INTO audit_log (entity_type, entity_id, action, next_state, metadata) VALUES ('transaction', $1, 'created', 'PENDING', '{\"n\": 1}')", repository.ToPgUUID(uuid.New()))
+		require.NoError(t, err)
+	}
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/admin/audit/verify", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, send(userToken).Code)
+	w := send(adminToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result models.AuditChainVerification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.True(t, result.Valid, w.Body.String())
+	require.Equal(t, int64(3), result.RecordsChecked)
+	require.Equal(t, int64(3), result.LastSeq)
+}
+
+func TestAuditLogQueryEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	queries := repository.New(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "auditq-admin", Email: "auditq-admin@example.com"}
+	owner := &models.User{ID: uuid.New(), Username: "auditq-owner", Email: "auditq-owner@example.com"}
+	other := &models.User{ID: uuid.New(), Username: "auditq-other", Email: "auditq-other@example.com"}
+	for _, u := range []*models.User{admin, owner, other} {
+		require.NoError(t, repo.CreateUser(ctx, u))
+	}
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	ownerToken := loginAndGetToken(t, client, owner.ID)
+	otherToken := loginAndGetToken(t, client, other.ID)
+
+	account := &models.Account{ID: uuid.New(), UserID: owner.ID, Currency: "USD", Balance: 500}
+	require.NoError(t, repo.CreateAccount(ctx, account))
+	txID := uuid.New()
+	_, err = queries.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(txID),
+		Amount:      500,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "audit-query",
+	})
+	require.NoError(t, err)
+	_, err = testDB.Exec(ctx, "INSERT INTO entries (id, transaction_id, account_id, amount, direction) VALUES ($1, $2, $3, 500, 'credit'), ($4, $2, '22222222-2222-2222-2222-222222222222', 500, 'debit')",
+		repository.ToPgUUID(uuid.New()), repository.ToPgUUID(txID), repository.ToPgUUID(account.ID), repository.ToPgUUID(uuid.New()))
+	require.NoError(t, err)
+	for _, actor := range []*uuid.UUID{&owner.ID, nil, &admin.ID} {
+		var actorParam any
+		if actor != nil {
+			actorParam = repository.ToPgUUID(*actor)
+		}
+		_, err := testDB.Exec(ctx, `INSERT INTO audit_log (entity_type, entity_id, actor_id, action, prev_state, next_state, metadata)
+			VALUES ('transaction', $1, $2, 'transition', 'PENDING', 'COMPLETED', '{"note": "internal note, do not share"}')`, repository.ToPgUUID(txID), actorParam)
+		require.NoError(t, err)
+	}
+
+	send := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	type page struct {
+		Items      []models.AuditLogEntry `json:"items"`
+		NextCursor string                 `json:"next_cursor"`
+	}
+
+	require.Equal(t, http.StatusForbidden, send("/v1/admin/audit", ownerToken).Code)
+	require.Equal(t, http.StatusBadRequest, send("/v1/admin/audit?limit=501", adminToken).Code)
+	require.Equal(t, http.StatusBadRequest, send("/v1/admin/audit?cursor=not-a-cursor", adminToken).Code)
+	require.Equal(t, http.StatusBadRequest, send("/v1/admin/audit?from=2030-01-02T00:00:00Z&to=2030-01-01T00:00:00Z", adminToken).Code)
+
+	w := send("/v1/admin/audit?entity_id="+txID.String()+"&limit=2", adminToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var first page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+	require.Len(t, first.Items, 2)
+	require.NotEmpty(t, first.NextCursor)
+	w = send("/v1/admin/audit?entity_id="+txID.String()+"&limit=2&cursor="+first.NextCursor, adminToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var second page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	require.Len(t, second.Items, 1)
+	require.Empty(t, second.NextCursor)
+	require.Greater(t, first.Items[1].ID, second.Items[0].ID)
+
+	w = send("/v1/admin/audit?actor_id="+owner.ID.String(), adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var byActor page
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &byActor))
+	require.Len(t, byActor.Items, 1)
+
+	w = send("/v1/admin/audit?entity_type=transaction&format=csv", adminToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, "id", records[0][0])
+
+	require.Equal(t, http.StatusNotFound, send("/v1/transactions/"+txID.String()+"/audit", otherToken).Code)
+	w = send("/v1/transactions/"+txID.String()+"/audit", ownerToken)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NotContains(t, w.Body.String(), "internal note")
+	require.NotContains(t, w.Body.String(), admin.ID.String())
+	var trail struct {
+		Items []models.AuditTrailEvent `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trail))
+	require.Len(t, trail.Items, 3)
+	require.Equal(t, domain.AuditActorCustomer, trail.Items[0].ActorType)
+	require.Equal(t, domain.AuditActorSystem, trail.Items[1].ActorType)
+	require.Equal(t, domain.AuditActorStaff, trail.Items[2].ActorType)
+}
+
+func TestAccountLifecycleEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "lifecycle-admin", Email: "lifecycle-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "lifecycle-user", Email: "lifecycle-user@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	require.NoError(t, repo.CreateUser(ctx, user))
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	acc := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 50}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+	dest := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, dest))
+
+	send := func(method, path, token string, payload any, idemKey string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if idemKey != "" {
+			req.Header.Set("Idempotency-Key", idemKey)
+		}
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	freezePath := "/v1/admin/accounts/" + acc.ID.String() + "/freeze"
+
+	w := send("POST", freezePath, userToken, map[string]any{"scope": "DEBITS", "reason": "FRAUD_SUSPECTED"}, "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = send("POST", freezePath, adminToken, map[string]any{"scope": "DEBITS", "reason": "OTHER"}, "")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = send("POST", freezePath, adminToken, map[string]any{"scope": "DEBITS", "reason": "FRAUD_SUSPECTED"}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var account models.Account
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &account))
+	require.Equal(t, "FROZEN_DEBITS", account.Status)
+	require.Equal(t, "FRAUD_SUSPECTED", account.StatusReason)
+
+	transfer := map[string]any{"from_account_id": acc.ID, "to_account_id": dest.ID, "amount": 10}
+	w = send("POST", "/v1/transfers/internal", userToken, transfer, uuid.New().String())
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "account/frozen")
+
+	closePath := "/v1/admin/accounts/" + acc.ID.String() + "/close"
+	w = send("POST", closePath, adminToken, map[string]any{"reason": "CUSTOMER_REQUEST"}, "")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), "account/balance-not-zero")
+
+	w = send("POST", "/v1/admin/accounts/"+acc.ID.String()+"/unfreeze", adminToken, map[string]any{"reason": "REVIEW_CLEARED"}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", closePath, adminToken, map[string]any{"reason": "CUSTOMER_REQUEST", "sweep_to_account_id": dest.ID}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var closed service.CloseAccountResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &closed))
+	require.Equal(t, "CLOSED", closed.Account.Status)
+	require.NotNil(t, closed.Sweep)
+	require.Equal(t, int64(50), closed.Sweep.Amount)
+
+	w = send("POST", "/v1/admin/accounts/"+uuid.New().String()+"/unfreeze", adminToken, map[string]any{"reason": "REVIEW_CLEARED"}, "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOpeningBalanceEndpoint(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "opening-admin", Email: "opening-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "opening-user", Email: "opening-user@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	require.NoError(t, repo.CreateUser(ctx, user))
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	acc := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "GBP"}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+
+	send := func(token string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{"amount": 700, "note": "branch migration"})
+		req := httptest.NewRequest("POST", "/v1/admin/accounts/"+acc.ID.String()+"/opening-balance", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, send(userToken).Code)
+	w := send(adminToken)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var tx models.Transaction
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tx))
+	require.Equal(t, "opening_balance", tx.Type)
+	require.Equal(t, int64(700), tx.Amount)
+
+	w = send(adminToken)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	funded, err := repo.GetAccount(ctx, acc.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(700), funded.Balance)
+
+	acc.ID = uuid.MustParse(domain.SystemEquityAccountGBP)
+	w = send(adminToken)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "account/system-account")
+}
+
+func TestOverdraftEndpoint(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "overdraft-admin", Email: "overdraft-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "overdraft-user", Email: "overdraft-user@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	require.NoError(t, repo.CreateUser(ctx, user))
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	acc := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD"}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+
+	send := func(token string, payload map[string]any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest("PUT", "/v1/admin/accounts/"+acc.ID.String()+"/overdraft", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusForbidden, send(userToken, map[string]any{"limit_micros": 500}).Code)
+	require.Equal(t, http.StatusBadRequest, send(adminToken, map[string]any{"limit_micros": -1}).Code)
+
+	w := send(adminToken, map[string]any{"limit_micros": 500, "interest_bps": 1500, "note": "approved"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var account models.Account
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &account))
+	require.Equal(t, int64(500), account.OverdraftLimit)
+	require.Equal(t, int32(1500), account.OverdraftInterestBps)
+
+	_, err = testDB.Exec(ctx, "UPDATE accounts SET balance=-300 WHERE id=$1", repository.ToPgUUID(acc.ID))
+	require.NoError(t, err)
+	w = send(adminToken, map[string]any{"limit_micros": 100})
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestTransactionLimitEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "limits-admin", Email: "limits-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "limits-user", Email: "limits-user@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	require.NoError(t, repo.CreateUser(ctx, user))
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	acc := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1000}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+	dest := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, dest))
+
+	send := func(method, path, token string, payload any, idemKey string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if idemKey != "" {
+			req.Header.Set("Idempotency-Key", idemKey)
+		}
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("PUT", "/v1/admin/limits/transfer/USD", userToken, map[string]any{"daily_max_micros": 100}, "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = send("PUT", "/v1/admin/limits/deposit/USD", adminToken, map[string]any{"daily_max_micros": 100}, "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("PUT", "/v1/admin/limits/transfer/USD", adminToken, map[string]any{"daily_max_micros": 100}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	transfer := map[string]any{"from_account_id": acc.ID, "to_account_id": dest.ID, "amount": 150}
+	w = send("POST", "/v1/transfers/internal", userToken, transfer, uuid.New().String())
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	var problemBody map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problemBody))
+	require.Equal(t, "https://errors.paymentapp.com/limits/exceeded", problemBody["type"])
+	require.Equal(t, "day", problemBody["limit_window"])
+	require.EqualValues(t, 100, problemBody["limit_micros"])
+	require.NotEmpty(t, problemBody["resets_at"])
+
+	overridePath := "/v1/admin/users/" + user.ID.String() + "/limits/transfer/USD"
+	w = send("PUT", overridePath, adminToken, map[string]any{"daily_max_micros": 500, "note": "verified business"}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", "/v1/transfers/internal", userToken, transfer, uuid.New().String())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = send("GET", "/v1/admin/users/"+user.ID.String()+"/limits", adminToken, nil, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "verified business")
+
+	w = send("PUT", overridePath+"?account_id=not-a-uuid", adminToken, map[string]any{"daily_max_micros": 100}, "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("PUT", overridePath+"?account_id="+acc.ID.String(), adminToken, map[string]any{"daily_max_micros": 100}, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), acc.ID.String())
+	w = send("POST", "/v1/transfers/internal", userToken, transfer, uuid.New().String())
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	w = send("DELETE", overridePath+"?account_id="+acc.ID.String(), adminToken, nil, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = send("DELETE", overridePath, adminToken, nil, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = send("DELETE", overridePath, adminToken, nil, "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestKYCEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "kyc-admin", Email: "kyc-admin@example.com"}
+	user := &models.User{ID: uuid.New(), Username: "kyc-user", Email: "kyc-user@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	require.NoError(t, repo.CreateUser(ctx, user))
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(admin.ID))
+	require.NoError(t, err)
+	adminToken := loginAndGetToken(t, client, admin.ID)
+	userToken := loginAndGetToken(t, client, user.ID)
+
+	send := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	webhook := func(event map[string]any, secret string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(event)
+		req := httptest.NewRequest("POST", "/v1/webhooks/kyc", bytes.NewReader(body))
+		req.Header.Set("X-Webhook-Signature", computeHMAC(body, secret))
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "/v1/admin/kyc/tiers", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "UNVERIFIED")
+
+	// A new user starts unverified and may only hold USD.
+	w = send("POST", "/v1/accounts", userToken, map[string]any{"user_id": user.ID, "currency": "EUR"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var problemBody map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problemBody))
+	require.Equal(t, "https://errors.paymentapp.com/kyc/currency-not-allowed", problemBody["type"])
+
+	kycPath := "/v1/admin/users/" + user.ID.String() + "/kyc"
+	w = send("PUT", kycPath, userToken, map[string]any{"status": "VERIFIED", "tier": 1})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = send("PUT", kycPath, adminToken, map[string]any{"status": "PENDING", "tier": 1})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("PUT", kycPath, adminToken, map[string]any{"status": "VERIFIED", "tier": 1, "note": "documents checked"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = send("POST", "/v1/accounts", userToken, map[string]any{"user_id": user.ID, "currency": "EUR"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var audits int
+	require.NoError(t, testDB.QueryRow(ctx, "SELECT COUNT(*) FROM audit_log WHERE entity_type='user' AND entity_id=$1 AND action='kyc_updated'", repository.ToPgUUID(user.ID)).Scan(&audits))
+	require.Equal(t, 1, audits)
+
+	event := map[string]any{"event_id": "evt-1", "user_id": user.ID, "status": "REJECTED", "tier": 0, "occurred_at": time.Now().Add(-time.Hour).UTC()}
+	w = webhook(event, "wrong-secret")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// An event older than the admin's decision does not undo it.
+	w = webhook(event, testKYCSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"applied":false`)
+
+	event["event_id"] = "evt-2"
+	event["occurred_at"] = time.Now().Add(time.Minute).UTC()
+	w = webhook(event, testKYCSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"applied":true`)
+
+	// A replayed event is acknowledged without being applied again.
+	event["status"] = "VERIFIED"
+	event["tier"] = 1
+	event["occurred_at"] = time.Now().Add(2 * time.Minute).UTC()
+	w = webhook(event, testKYCSecret)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"applied":false`)
+
+	w = send("GET", kycPath, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"status":"REJECTED"`)
+}
+
+func TestSanctionsScreeningEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	ctx := context.Background()
+
+	maker := &models.User{ID: uuid.New(), Username: "screen-maker", Email: "screen-maker@example.com"}
+	checker := &models.User{ID: uuid.New(), Username: "screen-checker", Email: "screen-checker@example.com"}
+	for _, u := range []*models.User{maker, checker} {
+		require.NoError(t, repo.CreateUser(ctx, u))
+		_, err := testDB.Exec(ctx, "UPDATE users SET role='admin', kyc_status='VERIFIED', kyc_tier=1 WHERE id=$1", repository.ToPgUUID(u.ID))
+		require.NoError(t, err)
+	}
+	acc := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+	makerToken := loginAndGetToken(t, client, maker.ID)
+	checkerToken := loginAndGetToken(t, client, checker.ID)
+
+	_, err := service.NewScreeningService(repository.NewStore(testDB)).LoadList(ctx, sanctions.SourceEUConsolidated, []sanctions.Entry{
+		{Source: sanctions.SourceEUConsolidated, ExternalID: "13", Name: "Saddam Hussein Al-Tikriti", EntryType: "person"},
+	}, "checksum", "ops")
+	require.NoError(t, err)
+
+	send := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", uuid.New().String())
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("POST", "/v1/payouts", makerToken, map[string]any{
+		"account_id":    acc.ID,
+		"amount_micros": 1000,
+		"currency":      "USD",
+		"destination":   map[string]string{"iban": "GB29NWBK60161331926819", "name": "Saddam Hussein al-Takriti"},
+	})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var payout service.PayoutResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &payout))
+	require.Equal(t, domain.PayoutStatusScreeningHold, payout.Status)
+
+	w = send("GET", "/v1/payouts/screening-holds", checkerToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var queue struct {
+		Items      []service.ScreenedPayout `json:"items"`
+		TotalCount int64                    `json:"total_count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queue))
+	require.Len(t, queue.Items, 1)
+	require.Equal(t, int64(1), queue.TotalCount)
+	require.Equal(t, "Saddam Hussein Al-Tikriti", queue.Items[0].Screening.MatchedName)
+
+	resolvePath := "/v1/payouts/" + payout.PayoutID.String() + "/screening/resolve"
+	w = send("POST", resolvePath, checkerToken, map[string]string{"decision": "confirm_match"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("POST", resolvePath, makerToken, map[string]string{"decision": "confirm_match", "reason": "listed party"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	w = send("POST", resolvePath, checkerToken, map[string]string{"decision": "confirm_match", "reason": "listed party"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"status":"REJECTED"`)
+	w = send("POST", resolvePath, checkerToken, map[string]string{"decision": "clear", "reason": "again"})
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAMLEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+	repo := repository.NewRepository(testDB)
+	aml := service.NewAMLService(repository.NewStore(testDB))
+	ctx := context.Background()
+
+	analyst := &models.User{ID: uuid.New(), Username: "aml-analyst", Email: "aml-analyst@example.com", Role: "admin"}
+	customer := &models.User{ID: uuid.New(), Username: "aml-customer", Email: "aml-customer@example.com"}
+	for _, u := range []*models.User{analyst, customer} {
+		require.NoError(t, repo.CreateUser(ctx, u))
+	}
+	_, err := testDB.Exec(ctx, "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(analyst.ID))
+	require.NoError(t, err)
+	acc := &models.Account{ID: uuid.New(), UserID: customer.ID, Currency: "USD"}
+	require.NoError(t, repo.CreateAccount(ctx, acc))
+	adminToken := loginAndGetToken(t, client, analyst.ID)
+	userToken := loginAndGetToken(t, client, customer.ID)
+
+	for i, amount := range []int64{9_400_000_000, 9_600_000_000, 9_900_000_000} {
+		body, _ := json.Marshal(map[string]any{"transaction_id": uuid.New(), "account_id": acc.ID, "amount_micros": amount, "currency": "USD"})
+		require.NoError(t, aml.Publish(ctx, outbox.Event{ID: uuid.New(), Type: outbox.EventDepositCompleted, Payload: body, CreatedAt: time.Now().Add(time.Duration(i) * time.Minute)}))
+	}
+
+	send := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "/v1/admin/aml/alerts", userToken, nil)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = send("GET", "/v1/admin/aml/rules", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var rules struct {
+		Rules []service.AMLRule `json:"rules"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rules))
+	require.Len(t, rules.Rules, 3)
+	var roundTrip service.AMLRule
+	for _, rule := range rules.Rules {
+		if rule.Code == domain.AMLRuleFXRoundTrip {
+			roundTrip = rule
+		}
+	}
+	t.Cleanup(func() {
+		_, err := aml.UpdateRule(ctx, roundTrip.Code, service.AMLRuleUpdate{
+			Enabled: roundTrip.Enabled, WindowSeconds: roundTrip.WindowSeconds, AmountMicros: roundTrip.AmountMicros,
+			MinCount: roundTrip.MinCount, RatioBps: roundTrip.RatioBps, Severity: roundTrip.Severity,
+		}, analyst.ID)
+		require.NoError(t, err)
+	})
+
+	update := map[string]any{"enabled": false, "window_seconds": 7200, "amount_micros": 500_000_000, "min_count": 1, "ratio_bps": 0, "severity": "LOW"}
+	w = send("PUT", "/v1/admin/aml/rules/FX_ROUND_TRIP", adminToken, update)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"enabled":false`)
+	update["severity"] = "URGENT"
+	w = send("PUT", "/v1/admin/aml/rules/FX_ROUND_TRIP", adminToken, update)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	w = send("PUT", "/v1/admin/aml/rules/VELOCITY", adminToken, update)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("GET", "/v1/admin/aml/alerts?rule=structuring&user_id="+customer.ID.String(), adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var alerts struct {
+		Items      []service.AMLAlert `json:"items"`
+		TotalCount int64              `json:"total_count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alerts))
+	require.Len(t, alerts.Items, 1)
+	require.Equal(t, int64(1), alerts.TotalCount)
+	w = send("GET", "/v1/admin/aml/alerts?rule=VELOCITY", adminToken, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = send("GET", "/v1/admin/aml/cases?status=OPEN", adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"total_count":1`)
+
+	casePath := "/v1/admin/aml/cases/" + alerts.Items[0].CaseID.String()
+	w = send("GET", casePath, adminToken, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"rule_code":"STRUCTURING"`)
+	w = send("GET", "/v1/admin/aml/cases/"+uuid.New().String(), adminToken, nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	w = send("POST", casePath+"/status", adminToken, map[string]any{"status": "ESCALATED"})
+	require.Equal(t, http.StatusConflict, w.Code)
+	w = send("POST", casePath+"/status", adminToken, map[string]any{"status": "INVESTIGATING", "assigned_to": analyst.ID})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("POST", casePath+"/status", adminToken, map[string]any{"status": "CLOSED", "resolution": "NO_ACTION", "note": "payroll deposits"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, w.Body.String(), `"status":"CLOSED"`)
+}
+
+func TestSessionEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+
+	type tokenResponse struct {
+		Token        string    `json:"token"`
+		RefreshToken string    `json:"refresh_token"`
+		SessionID    uuid.UUID `json:"session_id"`
+		ExpiresIn    int64     `json:"expires_in"`
+	}
+	call := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	login := func(email string) tokenResponse {
+		w := call("POST", "/v1/auth/login", "", map[string]string{"email": email, "password": testPassword})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp tokenResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NotEmpty(t, resp.RefreshToken)
+		return resp
+	}
+
+	repo := repository.NewRepository(testDB)
+	user := &models.User{ID: uuid.New(), Username: "roamer", Email: "roamer@example.com", Role: "user"}
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	setTestPassword(t, user.ID)
+
+	laptop := login(user.Email)
+	assert.LessOrEqual(t, laptop.ExpiresIn, int64(15*60))
+	phone := login(user.Email)
+
+	w := call("GET", "/v1/sessions", laptop.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Items []struct {
+			ID      uuid.UUID `json:"id"`
+			Current bool      `json:"current"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Items, 2)
+	for _, item := range listed.Items {
+		assert.Equal(t, item.ID == laptop.SessionID, item.Current)
+	}
+
+	// Refreshing rotates both tokens; the old ones stop working.
+	w = call("POST", "/v1/auth/refresh", "", map[string]string{"refresh_token": laptop.RefreshToken})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var refreshed tokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+	assert.Equal(t, laptop.SessionID, refreshed.SessionID)
+	assert.NotEqual(t, laptop.RefreshToken, refreshed.RefreshToken)
+	w = call("GET", "/v1/sessions", laptop.Token, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/token-revoked")
+	require.Equal(t, http.StatusOK, call("GET", "/v1/sessions", refreshed.Token, nil).Code)
+
+	// Replaying a used refresh token ends the session.
+	w = call("POST", "/v1/auth/refresh", "", map[string]string{"refresh_token": laptop.RefreshToken})
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/invalid-refresh-token")
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/sessions", refreshed.Token, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, call("POST", "/v1/auth/refresh", "", map[string]string{"refresh_token": refreshed.RefreshToken}).Code)
+
+	// One session can end another, and logout ends the caller's own.
+	tablet := login(user.Email)
+	require.Equal(t, http.StatusNotFound, call("DELETE", "/v1/sessions/"+uuid.New().String(), phone.Token, nil).Code)
+	require.Equal(t, http.StatusNoContent, call("DELETE", "/v1/sessions/"+tablet.SessionID.String(), phone.Token, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/sessions", tablet.Token, nil).Code)
+	require.Equal(t, http.StatusNoContent, call("POST", "/v1/auth/logout", phone.Token, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/sessions", phone.Token, nil).Code)
+	require.Equal(t, http.StatusUnauthorized, call("POST", "/v1/auth/refresh", "", map[string]string{"refresh_token": phone.RefreshToken}).Code)
+
+	// Signing out everywhere.
+	login(user.Email)
+	desk := login(user.Email)
+	w = call("DELETE", "/v1/sessions", desk.Token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked":2}`, w.Body.String())
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/sessions", desk.Token, nil).Code)
+
+	// Tokens from before sessions, without sid and jti, are refused.
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID.String(), "role": "user", "iss": testJWTIssuer, "aud": testJWTAudience,
+		"sub": user.ID.String(), "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	legacyToken, err := legacy.SignedString(middleware.JWTSecret())
+	require.NoError(t, err)
+	w = call("GET", "/v1/sessions", legacyToken, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/invalid-token-claims")
+}
+
+func TestRoleChangeRevokesSessions(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+
+	repo := repository.NewRepository(testDB)
+	owner := &models.User{ID: uuid.New(), Username: "owner", Email: "owner@example.com", Role: "user"}
+	deputy := &models.User{ID: uuid.New(), Username: "deputy", Email: "deputy@example.com", Role: "user"}
+	for _, u := range []*models.User{owner, deputy} {
+		require.NoError(t, repo.CreateUser(context.Background(), u))
+		_, err := testDB.Exec(context.Background(), "UPDATE users SET role='admin' WHERE id=$1", repository.ToPgUUID(u.ID))
+		require.NoError(t, err)
+	}
+	ownerToken := loginAndGetToken(t, client, owner.ID)
+	deputyToken := loginAndGetToken(t, client, deputy.ID)
+
+	call := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/kyc/tiers", deputyToken, nil).Code)
+	require.Equal(t, http.StatusBadRequest, call("PUT", "/v1/admin/users/"+deputy.ID.String()+"/role", ownerToken, map[string]string{"role": "system"}).Code)
+	require.Equal(t, http.StatusBadRequest, call("PUT", "/v1/admin/users/11111111-1111-1111-1111-111111111111/role", ownerToken, map[string]string{"role": "user"}).Code)
+	require.Equal(t, http.StatusNotFound, call("PUT", "/v1/admin/users/"+uuid.New().String()+"/role", ownerToken, map[string]string{"role": "user"}).Code)
+
+	w := call("PUT", "/v1/admin/users/"+deputy.ID.String()+"/role", ownerToken, map[string]string{"role": "user"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var change service.RoleChange
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &change))
+	assert.Equal(t, "admin", change.PreviousRole)
+	assert.Equal(t, "user", change.Role)
+	assert.Equal(t, 1, change.SessionsRevoked)
+
+	// The demotion applies to the token already issued, not just new logins.
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/admin/kyc/tiers", deputyToken, nil).Code)
+	deputyToken = loginAndGetToken(t, client, deputy.ID)
+	require.Equal(t, http.StatusForbidden, call("GET", "/v1/admin/kyc/tiers", deputyToken, nil).Code)
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/kyc/tiers", ownerToken, nil).Code)
+}
+
+func TestStaffRolePermissions(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+
+	repo := repository.NewRepository(testDB)
+	customer := &models.User{ID: uuid.New(), Username: "customer", Email: "customer@example.com", Role: "user"}
+	require.NoError(t, repo.CreateUser(context.Background(), customer))
+	acct := &models.Account{ID: uuid.New(), UserID: customer.ID, Currency: "USD", Balance: 42}
+	require.NoError(t, repo.CreateAccount(context.Background(), acct))
+	staff := map[string]string{}
+	for _, role := range []string{"admin", "support", "ops", "finance"} {
+		u := &models.User{ID: uuid.New(), Username: role + "-staff", Email: role + "@example.com", Role: "user"}
+		require.NoError(t, repo.CreateUser(context.Background(), u))
+		_, err := testDB.Exec(context.Background(), "UPDATE users SET role=$1 WHERE id=$2", role, repository.ToPgUUID(u.ID))
+		require.NoError(t, err)
+		staff[role] = loginAndGetToken(t, client, u.ID)
+	}
+	customerToken := loginAndGetToken(t, client, customer.ID)
+
+	call := func(method, path, token string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	balance := "/v1/accounts/" + acct.ID.String() + "/balance"
+	freeze := "/v1/admin/accounts/" + acct.ID.String() + "/freeze"
+
+	// Each staff role reaches its own slice of the back office.
+	require.Equal(t, http.StatusOK, call("GET", balance, staff["support"], nil).Code)
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/audit", staff["support"], nil).Code)
+	w := call("POST", freeze, staff["support"], map[string]string{"scope": "ALL", "reason": "FRAUD_SUSPECTED"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/insufficient-permissions")
+	require.Equal(t, http.StatusForbidden, call("GET", "/v1/admin/limits", staff["ops"], nil).Code)
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/limits", staff["finance"], nil).Code)
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/reconciliation/runs", staff["ops"], nil).Code)
+	require.Equal(t, http.StatusForbidden, call("GET", "/v1/admin/reconciliation/runs", staff["support"], nil).Code)
+	require.Equal(t, http.StatusForbidden, call("GET", "/v1/admin/roles", staff["finance"], nil).Code)
+	require.Equal(t, http.StatusForbidden, call("GET", "/v1/admin/kyc/tiers", customerToken, nil).Code)
+
+	w = call("GET", "/v1/admin/roles", staff["admin"], nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var roles struct {
+		Items []models.Role `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &roles))
+	granted := map[string][]string{}
+	for _, role := range roles.Items {
+		granted[role.Name] = role.Permissions
+	}
+	assert.Contains(t, granted["support"], "audit:read")
+	assert.NotContains(t, granted["support"], "accounts:lifecycle")
+	assert.NotContains(t, granted["user"], "accounts:read:any")
+
+	w = call("PUT", "/v1/admin/users/"+customer.ID.String()+"/role", staff["admin"], map[string]string{"role": "auditor"})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "user/invalid-role")
+	w = call("PUT", "/v1/admin/users/"+customer.ID.String()+"/role", staff["admin"], map[string]string{"role": "support"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	customerToken = loginAndGetToken(t, client, customer.ID)
+	require.Equal(t, http.StatusOK, call("GET", "/v1/admin/kyc/tiers", customerToken, nil).Code)
+}
+
+func TestAPIKeyEndpoints(t *testing.T) {
+	cleanupDB(t)
+	a := setupAPI()
+	client := a.Routes()
+
+	repo := repository.NewRepository(testDB)
+	user := &models.User{ID: uuid.New(), Username: "service", Email: "service@example.com", Role: "user"}
+	require.NoError(t, repo.CreateUser(context.Background(), user))
+	acct := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 42}
+	require.NoError(t, repo.CreateAccount(context.Background(), acct))
+	token := generateTestToken(user.ID.String())
+
+	call := func(method, path string, headers map[string]string, payload any) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			body, _ = json.Marshal(payload)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		client.ServeHTTP(w, req)
+		return w
+	}
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+
+	w := call("POST", "/v1/api-keys", bearer, map[string]any{"name": "ops", "scopes": []string{"payouts:approve"}})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "api-key/invalid")
+
+	w = call("POST", "/v1/api-keys", bearer, map[string]any{"name": "reporting", "scopes": []string{"accounts:read"}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var issued struct {
+		ID     uuid.UUID `json:"id"`
+		Key    string    `json:"key"`
+		Prefix string    `json:"prefix"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	require.True(t, strings.HasPrefix(issued.Key, issued.Prefix))
+	withKey := map[string]string{"X-API-Key": issued.Key}
+
+	// The key acts as its owner, within its permissions.
+	require.Equal(t, http.StatusOK, call("GET", "/v1/accounts/"+acct.ID.String()+"/balance", withKey, nil).Code)
+	w = call("POST", "/v1/accounts", withKey, map[string]string{"currency": "EUR"})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/insufficient-permissions")
+	w = call("POST", "/v1/api-keys", withKey, map[string]any{"name": "copy", "scopes": []string{"accounts:read"}})
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "api-key/login-required")
+	require.Equal(t, http.StatusUnauthorized, call("GET", "/v1/sessions", withKey, nil).Code)
+	w = call("GET", "/v1/accounts/"+acct.ID.String()+"/balance", map[string]string{"X-API-Key": issued.Key, "Authorization": "Bearer " + token}, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/ambiguous-credentials")
+
+	w = call("GET", "/v1/api-keys", bearer, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), issued.Key)
+	var listed struct {
+		Items []struct {
+			ID         uuid.UUID  `json:"id"`
+			LastUsedAt *time.Time `json:"last_used_at"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Items, 1)
+	assert.NotNil(t, listed.Items[0].LastUsedAt)
+
+	w = call("POST", "/v1/api-keys/"+issued.ID.String()+"/rotate", bearer, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var rotated struct {
+		ID  uuid.UUID `json:"id"`
+		Key string    `json:"key"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	require.Equal(t, http.StatusOK, call("GET", "/v1/accounts/"+acct.ID.String()+"/balance", map[string]string{"X-API-Key": rotated.Key}, nil).Code)
+
+	require.Equal(t, http.StatusNotFound, call("DELETE", "/v1/api-keys/"+uuid.New().String(), bearer, nil).Code)
+	require.Equal(t, http.StatusNoContent, call("DELETE", "/v1/api-keys/"+rotated.ID.String(), bearer, nil).Code)
+	w = call("GET", "/v1/accounts/"+acct.ID.String()+"/balance", map[string]string{"X-API-Key": rotated.Key}, nil)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "auth/invalid-api-key")
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/ayo6706/payment-multicurrency/internal/api/problem"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type contextKey string
@@ -15,6 +18,9 @@ type contextKey string
 const (
 	userContextKey  contextKey = "user_id"
 	roleContextKey  contextKey = "user_role"
+	sessionCtxKey   contextKey = "session_id"
+	apiKeyCtxKey    contextKey = "api_key"
+	permsContextKey contextKey = "permissions"
 	traceContextKey contextKey = "trace_id"
 )
 
@@ -23,11 +29,34 @@ var jwtIssuer string
 var jwtAudience string
 
 type authClaims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	// Scope lists the permissions of the user's role, space separated.
+	Scope *string `json:"scope"`
 	jwt.RegisteredClaims
 }
 
+// SessionChecker reports whether an access token, identified by its session
+// (sid) and jti claims, has been revoked.
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID, jti uuid.UUID) (bool, error)
+}
+
+// APIKeyAuthenticator resolves an X-API-Key header to the key's owner and
+// the permissions the key may use. An unknown, revoked or expired key
+// returns nil.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error)
+}
+
+// Authenticator checks what AuthMiddleware cannot check from the request
+// alone.
+type Authenticator interface {
+	SessionChecker
+	APIKeyAuthenticator
+}
+
 func SetJWTSecret(secret string) {
 	if secret == "" {
 		return
@@ -54,10 +83,28 @@ func JWTAudience() string {
 	return jwtAudience
 }
 
-// AuthMiddleware validates the JWT token and injects user metadata into the context.
-func AuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware accepts either a JWT in the Authorization header or an API
+// key in X-API-Key, rejects tokens whose session has ended and keys that no
+// longer work, and injects user metadata into the context. Both carry the
+// same identity: a user, a role and the permissions RequirePermission checks.
+func AuthMiddleware(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return authenticate(auth, next)
+	}
+}
+
+func authenticate(auth Authenticator, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
+		apiKey := r.Header.Get("X-API-Key")
+		if authHeader != "" && apiKey != "" {
+			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/ambiguous-credentials"), http.StatusText(http.StatusUnauthorized), "Send either an Authorization header or X-API-Key, not both")
+			return
+		}
+		if apiKey != "" {
+			authenticateAPIKey(auth, apiKey, next, w, r)
+			return
+		}
 		if authHeader == "" {
 			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/authorization-header-required"), http.StatusText(http.StatusUnauthorized), "Authorization header required")
 			return
@@ -91,7 +138,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-token"), http.StatusText(http.StatusUnauthorized), "Invalid token")
 			return
 		}
-		if claims.UserID == "" {
+		if claims.UserID == "" || claims.Scope == nil {
 			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-token-claims"), http.StatusText(http.StatusUnauthorized), "Invalid token claims")
 			return
 		}
@@ -99,19 +146,59 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-token-claims"), http.StatusText(http.StatusUnauthorized), "Invalid token claims")
 			return
 		}
+		sessionID, sidErr := uuid.Parse(claims.SessionID)
+		jti, jtiErr := uuid.Parse(claims.ID)
+		if sidErr != nil || jtiErr != nil {
+			problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-token-claims"), http.StatusText(http.StatusUnauthorized), "Invalid token claims")
+			return
+		}
+		if auth != nil {
+			active, err := auth.SessionActive(r.Context(), sessionID, jti)
+			if err != nil {
+				problem.Write(w, r, http.StatusServiceUnavailable, problem.Type("auth/unavailable"), http.StatusText(http.StatusServiceUnavailable), "Unable to verify token")
+				return
+			}
+			if !active {
+				problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/token-revoked"), http.StatusText(http.StatusUnauthorized), "Token has been revoked")
+				return
+			}
+		}
 		ctx := context.WithValue(r.Context(), userContextKey, claims.UserID)
 		ctx = context.WithValue(ctx, roleContextKey, claims.Role)
+		ctx = context.WithValue(ctx, sessionCtxKey, sessionID.String())
+		ctx = context.WithValue(ctx, permsContextKey, strings.Fields(*claims.Scope))
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
-// RequireRole ensures the authenticated user has the required role.
-func RequireRole(requiredRole string) func(http.Handler) http.Handler {
+func authenticateAPIKey(auth Authenticator, apiKey string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if auth == nil {
+		problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-api-key"), http.StatusText(http.StatusUnauthorized), "Invalid API key")
+		return
+	}
+	principal, err := auth.AuthenticateAPIKey(r.Context(), apiKey)
+	if err != nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, problem.Type("auth/unavailable"), http.StatusText(http.StatusServiceUnavailable), "Unable to verify API key")
+		return
+	}
+	if principal == nil {
+		problem.Write(w, r, http.StatusUnauthorized, problem.Type("auth/invalid-api-key"), http.StatusText(http.StatusUnauthorized), "Invalid API key")
+		return
+	}
+	ctx := context.WithValue(r.Context(), userContextKey, principal.UserID.String())
+	ctx = context.WithValue(ctx, roleContextKey, principal.Role)
+	ctx = context.WithValue(ctx, permsContextKey, principal.Permissions)
+	ctx = context.WithValue(ctx, apiKeyCtxKey, principal.APIKey)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequirePermission ensures the caller holds permission, from their role's
+// token scope or, for an API key, from both the owner's role and the key.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			role := UserRoleFromContext(r.Context())
-			if role != requiredRole {
-				problem.Write(w, r, http.StatusForbidden, problem.Type("auth/insufficient-permissions"), http.StatusText(http.StatusForbidden), "insufficient permissions")
+			if !HasPermission(r.Context(), permission) {
+				problem.Write(w, r, http.StatusForbidden, problem.Type("auth/insufficient-permissions"), http.StatusText(http.StatusForbidden), "missing permission "+permission)
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -119,6 +206,15 @@ func RequireRole(requiredRole string) func(http.Handler) http.Handler {
 	}
 }
 
+// HasPermission reports whether the authenticated caller holds permission.
+func HasPermission(ctx context.Context, permission string) bool {
+	if ctx == nil {
+		return false
+	}
+	permissions, _ := ctx.Value(permsContextKey).([]string)
+	return slices.Contains(permissions, permission)
+}
+
 // UserIDFromContext returns the authenticated user ID.
 func UserIDFromContext(ctx context.Context) string {
 	if ctx == nil {
@@ -141,6 +237,29 @@ func UserRoleFromContext(ctx context.Context) string {
 	return ""
 }
 
+// SessionIDFromContext returns the session of the authenticated user's token.
+func SessionIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(sessionCtxKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// APIKeyFromContext returns the API key a request was made with, or nil for
+// requests made with a login token.
+func APIKeyFromContext(ctx context.Context) *models.APIKey {
+	if ctx == nil {
+		return nil
+	}
+	if v, ok := ctx.Value(apiKeyCtxKey).(*models.APIKey); ok {
+		return v
+	}
+	return nil
+}
+
 // TraceIDFromContext returns the trace id for the request.
 func TraceIDFromContext(ctx context.Context) string {
 	if ctx == nil {
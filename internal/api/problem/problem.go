@@ -24,6 +24,12 @@ func Type(slug string) string {
 
 // Write sends RFC 7807-compliant errors.
 func Write(w http.ResponseWriter, r *http.Request, status int, problemType, title, detail string) {
+	WriteExtended(w, r, status, problemType, title, detail, nil)
+}
+
+// WriteExtended sends an RFC 7807 error with extension members alongside
+// the standard ones. Extensions never replace a standard member.
+func WriteExtended(w http.ResponseWriter, r *http.Request, status int, problemType, title, detail string, extensions map[string]any) {
 	if title == "" {
 		title = http.StatusText(status)
 	}
@@ -40,14 +46,30 @@ func Write(w http.ResponseWriter, r *http.Request, status int, problemType, titl
 		requestID = w.Header().Get("X-Trace-ID")
 	}
 
-	w.Header().Set("Content-Type", contentType)
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(Details{
+	details := Details{
 		Type:      problemType,
 		Title:     title,
 		Status:    status,
 		Detail:    detail,
 		Instance:  instance,
 		RequestID: requestID,
-	})
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if len(extensions) == 0 {
+		_ = json.NewEncoder(w).Encode(details)
+		return
+	}
+	body := make(map[string]any, len(extensions)+6)
+	for k, v := range extensions {
+		body[k] = v
+	}
+	body["type"] = details.Type
+	body["title"] = details.Title
+	body["status"] = details.Status
+	body["detail"] = details.Detail
+	body["instance"] = details.Instance
+	body["request_id"] = details.RequestID
+	_ = json.NewEncoder(w).Encode(body)
 }
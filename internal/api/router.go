@@ -6,6 +6,7 @@ import (
 	"github.com/ayo6706/payment-multicurrency/internal/api/spec"
 	"github.com/ayo6706/payment-multicurrency/internal/config"
 	"github.com/ayo6706/payment-multicurrency/internal/idempotency"
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/go-chi/chi/v5"
@@ -17,16 +18,25 @@ import (
 )
 
 type Router struct {
-	cfg         *config.Config
-	logger      *zap.Logger
-	db          *pgxpool.Pool
-	repo        *repository.Repository
-	idemStore   *idempotency.Store
-	redis       redis.Cmdable
-	accountSvc  *service.AccountService
-	transferSvc *service.TransferService
-	payoutSvc   *service.PayoutService
-	webhookSvc  *service.WebhookService
+	cfg          *config.Config
+	logger       *zap.Logger
+	db           *pgxpool.Pool
+	repo         *repository.Repository
+	idemStore    *idempotency.Store
+	redis        redis.Cmdable
+	accountSvc   *service.AccountService
+	transferSvc  *service.TransferService
+	payoutSvc    *service.PayoutService
+	webhookSvc   *service.WebhookService
+	benefSvc     *service.BeneficiaryService
+	reconSvc     *service.ReconciliationService
+	auditSvc     *service.AuditService
+	lifecycleSvc *service.AccountLifecycleService
+	overdraftSvc *service.OverdraftService
+	limitSvc     *service.LimitService
+	kycSvc       *service.KYCService
+	amlSvc       *service.AMLService
+	authSvc      *service.AuthService
 }
 
 func NewRouter(
@@ -40,18 +50,36 @@ func NewRouter(
 	transferSvc *service.TransferService,
 	payoutSvc *service.PayoutService,
 	webhookSvc *service.WebhookService,
+	benefSvc *service.BeneficiaryService,
+	reconSvc *service.ReconciliationService,
+	auditSvc *service.AuditService,
+	lifecycleSvc *service.AccountLifecycleService,
+	overdraftSvc *service.OverdraftService,
+	limitSvc *service.LimitService,
+	kycSvc *service.KYCService,
+	amlSvc *service.AMLService,
+	authSvc *service.AuthService,
 ) *Router {
 	return &Router{
-		cfg:         cfg,
-		logger:      logger,
-		db:          db,
-		repo:        repo,
-		idemStore:   idemStore,
-		redis:       redis,
-		accountSvc:  accountSvc,
-		transferSvc: transferSvc,
-		payoutSvc:   payoutSvc,
-		webhookSvc:  webhookSvc,
+		cfg:          cfg,
+		logger:       logger,
+		db:           db,
+		repo:         repo,
+		idemStore:    idemStore,
+		redis:        redis,
+		accountSvc:   accountSvc,
+		transferSvc:  transferSvc,
+		payoutSvc:    payoutSvc,
+		webhookSvc:   webhookSvc,
+		benefSvc:     benefSvc,
+		reconSvc:     reconSvc,
+		auditSvc:     auditSvc,
+		lifecycleSvc: lifecycleSvc,
+		overdraftSvc: overdraftSvc,
+		limitSvc:     limitSvc,
+		kycSvc:       kycSvc,
+		amlSvc:       amlSvc,
+		authSvc:      authSvc,
 	}
 }
 
@@ -66,24 +94,47 @@ func (api *Router) Routes() chi.Router {
 	transferSvc := api.transferSvc
 	payoutSvc := api.payoutSvc
 	webhookSvc := api.webhookSvc
-	if accountSvc == nil || transferSvc == nil || payoutSvc == nil || webhookSvc == nil {
+	benefSvc := api.benefSvc
+	reconSvc := api.reconSvc
+	auditSvc := api.auditSvc
+	lifecycleSvc := api.lifecycleSvc
+	overdraftSvc := api.overdraftSvc
+	limitSvc := api.limitSvc
+	kycSvc := api.kycSvc
+	amlSvc := api.amlSvc
+	authSvc := api.authSvc
+	if accountSvc == nil || transferSvc == nil || payoutSvc == nil || webhookSvc == nil || benefSvc == nil || reconSvc == nil || auditSvc == nil || lifecycleSvc == nil || overdraftSvc == nil || limitSvc == nil || kycSvc == nil || amlSvc == nil || authSvc == nil {
 		panic("router dependencies are not configured")
 	}
 
-	authHandler := handler.NewAuthHandler(api.repo)
-	userHandler := handler.NewUserHandler(api.repo)
+	authHandler := handler.NewAuthHandler(authSvc)
+	userHandler := handler.NewUserHandler(authSvc)
+	sessionHandler := handler.NewSessionHandler(authSvc)
+	apiKeyHandler := handler.NewAPIKeyHandler(authSvc)
 	accountHandler := handler.NewAccountHandler(accountSvc)
 	transferHandler := handler.NewTransferHandler(transferSvc, api.repo)
 	payoutHandler := handler.NewPayoutHandler(payoutSvc, api.repo)
 	webhookHandler := handler.NewWebhookHandler(webhookSvc)
+	beneficiaryHandler := handler.NewBeneficiaryHandler(benefSvc)
+	reconciliationHandler := handler.NewReconciliationHandler(reconSvc)
+	auditHandler := handler.NewAuditHandler(auditSvc)
+	lifecycleHandler := handler.NewAccountLifecycleHandler(lifecycleSvc)
+	overdraftHandler := handler.NewOverdraftHandler(overdraftSvc)
+	limitHandler := handler.NewLimitHandler(limitSvc)
+	kycHandler := handler.NewKYCHandler(kycSvc)
+	amlHandler := handler.NewAMLHandler(amlSvc)
 	healthHandler := handler.NewHealthHandler(api.db, api.redis)
 
 	r.Group(func(public chi.Router) {
 		public.Use(middleware.PublicRateLimiter(api.cfg.PublicRateLimitRPS))
 
 		public.Post("/v1/auth/login", authHandler.Login)
+		public.Post("/v1/auth/refresh", authHandler.Refresh)
+		public.Post("/v1/auth/password-reset", authHandler.RequestPasswordReset)
+		public.Post("/v1/auth/password-reset/confirm", authHandler.ConfirmPasswordReset)
 		public.Post("/v1/users", userHandler.CreateUser)
 		public.Post("/v1/webhooks/deposit", webhookHandler.HandleDepositWebhook)
+		public.Post("/v1/webhooks/kyc", kycHandler.HandleProviderWebhook)
 		public.Get("/health/live", healthHandler.Live)
 		public.Get("/health/ready", healthHandler.Ready)
 		public.Handle("/metrics", promhttp.Handler())
@@ -92,20 +143,82 @@ func (api *Router) Routes() chi.Router {
 	})
 
 	r.Group(func(auth chi.Router) {
-		auth.Use(middleware.AuthMiddleware)
+		auth.Use(middleware.AuthMiddleware(authSvc))
 		auth.Use(middleware.AuthRateLimiter(api.cfg.AuthRateLimitRPS))
 
-		auth.Post("/v1/accounts", accountHandler.CreateAccount)
-		auth.Get("/v1/accounts/{id}/balance", accountHandler.GetBalance)
-		auth.Get("/v1/accounts/{id}/statement", accountHandler.GetStatement)
+		// Session routes only need a valid login; every other route names the
+		// permission it needs, which the caller's role or API key must grant.
+		auth.Post("/v1/auth/logout", authHandler.Logout)
+		auth.Get("/v1/sessions", sessionHandler.ListSessions)
+		auth.Delete("/v1/sessions", sessionHandler.RevokeAllSessions)
+		auth.Delete("/v1/sessions/{id}", sessionHandler.RevokeSession)
 
-		auth.With(middleware.IdempotencyMiddleware(api.idemStore, api.logger)).Post("/v1/transfers/internal", transferHandler.MakeInternalTransfer)
-		auth.With(middleware.IdempotencyMiddleware(api.idemStore, api.logger)).Post("/v1/transfers/exchange", transferHandler.MakeExchangeTransfer)
+		auth.With(middleware.RequirePermission(permission.APIKeysManage)).Post("/v1/api-keys", apiKeyHandler.CreateAPIKey)
+		auth.With(middleware.RequirePermission(permission.APIKeysManage)).Get("/v1/api-keys", apiKeyHandler.ListAPIKeys)
+		auth.With(middleware.RequirePermission(permission.APIKeysManage)).Post("/v1/api-keys/{id}/rotate", apiKeyHandler.RotateAPIKey)
+		auth.With(middleware.RequirePermission(permission.APIKeysManage)).Delete("/v1/api-keys/{id}", apiKeyHandler.RevokeAPIKey)
 
-		auth.With(middleware.IdempotencyMiddleware(api.idemStore, api.logger), middleware.RequireRole("admin")).Post("/v1/payouts", payoutHandler.CreatePayout)
-		auth.With(middleware.RequireRole("admin")).Get("/v1/payouts/manual-review", payoutHandler.ListManualReviewPayouts)
-		auth.With(middleware.RequireRole("admin")).Post("/v1/payouts/{id}/resolve", payoutHandler.ResolveManualReviewPayout)
-		auth.Get("/v1/payouts/{id}", payoutHandler.GetPayout)
+		auth.With(middleware.RequirePermission(permission.AccountsCreate)).Post("/v1/accounts", accountHandler.CreateAccount)
+		auth.With(middleware.RequirePermission(permission.AccountsRead)).Get("/v1/accounts/{id}/balance", accountHandler.GetBalance)
+		auth.With(middleware.RequirePermission(permission.AccountsRead)).Get("/v1/accounts/{id}/statement", accountHandler.GetStatement)
+		auth.With(middleware.RequirePermission(permission.AccountsRead)).Get("/v1/transactions/{id}/audit", auditHandler.GetTransactionAuditTrail)
+
+		auth.With(middleware.RequirePermission(permission.TransfersCreate), middleware.IdempotencyMiddleware(api.idemStore, api.logger)).Post("/v1/transfers/internal", transferHandler.MakeInternalTransfer)
+		auth.With(middleware.RequirePermission(permission.TransfersCreate), middleware.IdempotencyMiddleware(api.idemStore, api.logger)).Post("/v1/transfers/exchange", transferHandler.MakeExchangeTransfer)
+
+		auth.With(middleware.RequirePermission(permission.BeneficiariesWrite)).Post("/v1/beneficiaries", beneficiaryHandler.CreateBeneficiary)
+		auth.With(middleware.RequirePermission(permission.BeneficiariesRead)).Get("/v1/beneficiaries", beneficiaryHandler.ListBeneficiaries)
+		auth.With(middleware.RequirePermission(permission.BeneficiariesRead)).Get("/v1/beneficiaries/{id}", beneficiaryHandler.GetBeneficiary)
+		auth.With(middleware.RequirePermission(permission.BeneficiariesWrite)).Put("/v1/beneficiaries/{id}", beneficiaryHandler.UpdateBeneficiary)
+		auth.With(middleware.RequirePermission(permission.BeneficiariesWrite)).Delete("/v1/beneficiaries/{id}", beneficiaryHandler.DeleteBeneficiary)
+
+		auth.With(middleware.RequirePermission(permission.PayoutsCreate), middleware.IdempotencyMiddleware(api.idemStore, api.logger)).Post("/v1/payouts", payoutHandler.CreatePayout)
+		auth.With(middleware.RequirePermission(permission.PayoutsRead)).Get("/v1/payouts/{id}", payoutHandler.GetPayout)
+		auth.With(middleware.RequirePermission(permission.PayoutsReadAny)).Get("/v1/payouts/manual-review", payoutHandler.ListManualReviewPayouts)
+		auth.With(middleware.RequirePermission(permission.PayoutsResolve)).Post("/v1/payouts/{id}/resolve", payoutHandler.ResolveManualReviewPayout)
+		auth.With(middleware.RequirePermission(permission.PayoutsReadAny)).Get("/v1/payouts/approvals", payoutHandler.ListPayoutsAwaitingApproval)
+		auth.With(middleware.RequirePermission(permission.PayoutsApprove)).Post("/v1/payouts/{id}/approve", payoutHandler.ApprovePayout)
+		auth.With(middleware.RequirePermission(permission.PayoutsApprove)).Post("/v1/payouts/{id}/reject", payoutHandler.RejectPayout)
+		auth.With(middleware.RequirePermission(permission.PayoutsReadAny)).Get("/v1/payouts/screening-holds", payoutHandler.ListScreeningHoldPayouts)
+		auth.With(middleware.RequirePermission(permission.PayoutsScreening)).Post("/v1/payouts/{id}/screening/resolve", payoutHandler.ResolveScreeningHold)
+
+		auth.With(middleware.RequirePermission(permission.ReconciliationWrite)).Post("/v1/admin/reconciliation/settlement-files", reconciliationHandler.ImportSettlementFile)
+		auth.With(middleware.RequirePermission(permission.ReconciliationRead)).Get("/v1/admin/reconciliation/settlement-files", reconciliationHandler.ListSettlementFiles)
+		auth.With(middleware.RequirePermission(permission.ReconciliationRead)).Get("/v1/admin/reconciliation/breaks", reconciliationHandler.ListBreaks)
+		auth.With(middleware.RequirePermission(permission.ReconciliationWrite)).Post("/v1/admin/reconciliation/breaks/{id}/clear", reconciliationHandler.ClearBreak)
+		auth.With(middleware.RequirePermission(permission.ReconciliationWrite)).Post("/v1/admin/reconciliation/runs", reconciliationHandler.TriggerRun)
+		auth.With(middleware.RequirePermission(permission.ReconciliationRead)).Get("/v1/admin/reconciliation/runs", reconciliationHandler.ListRuns)
+		auth.With(middleware.RequirePermission(permission.ReconciliationRead)).Get("/v1/admin/reconciliation/runs/{id}", reconciliationHandler.GetRun)
+
+		auth.With(middleware.RequirePermission(permission.AccountsLifecycle)).Post("/v1/admin/accounts/{id}/freeze", lifecycleHandler.FreezeAccount)
+		auth.With(middleware.RequirePermission(permission.AccountsLifecycle)).Post("/v1/admin/accounts/{id}/unfreeze", lifecycleHandler.UnfreezeAccount)
+		auth.With(middleware.RequirePermission(permission.AccountsLifecycle)).Post("/v1/admin/accounts/{id}/close", lifecycleHandler.CloseAccount)
+		auth.With(middleware.RequirePermission(permission.LedgerOpeningBalance)).Post("/v1/admin/accounts/{id}/opening-balance", transferHandler.PostOpeningBalance)
+		auth.With(middleware.RequirePermission(permission.AccountsOverdraft)).Put("/v1/admin/accounts/{id}/overdraft", overdraftHandler.SetOverdraft)
+
+		auth.With(middleware.RequirePermission(permission.LimitsRead)).Get("/v1/admin/limits", limitHandler.ListDefaults)
+		auth.With(middleware.RequirePermission(permission.LimitsWrite)).Put("/v1/admin/limits/{type}/{currency}", limitHandler.SetDefault)
+		auth.With(middleware.RequirePermission(permission.LimitsWrite)).Delete("/v1/admin/limits/{type}/{currency}", limitHandler.DeleteDefault)
+		auth.With(middleware.RequirePermission(permission.LimitsRead)).Get("/v1/admin/users/{id}/limits", limitHandler.ListUserOverrides)
+		auth.With(middleware.RequirePermission(permission.LimitsWrite)).Put("/v1/admin/users/{id}/limits/{type}/{currency}", limitHandler.SetUserOverride)
+		auth.With(middleware.RequirePermission(permission.LimitsWrite)).Delete("/v1/admin/users/{id}/limits/{type}/{currency}", limitHandler.DeleteUserOverride)
+
+		auth.With(middleware.RequirePermission(permission.RolesManage)).Get("/v1/admin/roles", userHandler.ListRoles)
+		auth.With(middleware.RequirePermission(permission.RolesManage)).Put("/v1/admin/users/{id}/role", userHandler.UpdateRole)
+
+		auth.With(middleware.RequirePermission(permission.KYCRead)).Get("/v1/admin/kyc/tiers", kycHandler.ListTiers)
+		auth.With(middleware.RequirePermission(permission.KYCRead)).Get("/v1/admin/users/{id}/kyc", kycHandler.GetUserKYC)
+		auth.With(middleware.RequirePermission(permission.KYCWrite)).Put("/v1/admin/users/{id}/kyc", kycHandler.UpdateUserKYC)
+
+		auth.With(middleware.RequirePermission(permission.AMLRead)).Get("/v1/admin/aml/rules", amlHandler.ListRules)
+		auth.With(middleware.RequirePermission(permission.AMLWrite)).Put("/v1/admin/aml/rules/{code}", amlHandler.UpdateRule)
+		auth.With(middleware.RequirePermission(permission.AMLRead)).Get("/v1/admin/aml/alerts", amlHandler.ListAlerts)
+		auth.With(middleware.RequirePermission(permission.AMLRead)).Get("/v1/admin/aml/cases", amlHandler.ListCases)
+		auth.With(middleware.RequirePermission(permission.AMLRead)).Get("/v1/admin/aml/cases/{id}", amlHandler.GetCase)
+		auth.With(middleware.RequirePermission(permission.AMLWrite)).Post("/v1/admin/aml/cases/{id}/status", amlHandler.UpdateCase)
+
+		auth.With(middleware.RequirePermission(permission.AuditRead)).Get("/v1/admin/audit", auditHandler.ListAuditLogs)
+		auth.With(middleware.RequirePermission(permission.AuditRead)).Get("/v1/admin/audit/verify", auditHandler.VerifyChain)
 	})
 
 	return r
@@ -14,16 +14,27 @@ import (
 	"github.com/ayo6706/payment-multicurrency/internal/api/middleware"
 	"github.com/ayo6706/payment-multicurrency/internal/config"
 	"github.com/ayo6706/payment-multicurrency/internal/db"
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
 	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/ayo6706/payment-multicurrency/internal/gateway/sepa"
 	"github.com/ayo6706/payment-multicurrency/internal/idempotency"
+	"github.com/ayo6706/payment-multicurrency/internal/mail"
 	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/sanctions"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
 	"github.com/ayo6706/payment-multicurrency/internal/worker"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
+const (
+	// payoutDispatchBuffer bounds queued dispatch events; overflow falls back to the poll sweep.
+	payoutDispatchBuffer = 256
+	outboxStreamMaxLen   = 100_000
+)
+
 func Run() error {
 	cfg, err := config.Load()
 	if err != nil {
@@ -64,23 +75,104 @@ func Run() error {
 	store := repository.NewStore(pool)
 
 	mockFX := service.NewMockExchangeRateService()
-	transferSvc := service.NewTransferService(store, mockFX)
-	accountSvc := service.NewAccountService(repo)
-	mockGateway := gateway.NewMockGateway()
-	payoutSvc := service.NewPayoutService(store, mockGateway)
+	limitSvc := service.NewLimitService(store).WithRedis(redisClient)
+	kycSvc := service.NewKYCService(store).WithWebhookSecret(cfg.KYCWebhookSecret)
+	screeningSvc := service.NewScreeningService(store).WithThresholds(sanctions.Thresholds{
+		Default:  cfg.SanctionsMatchThreshold,
+		BySource: cfg.SanctionsSourceThresholds,
+	})
+	transferSvc := service.NewTransferService(store, mockFX).WithLimits(limitSvc).WithKYC(kycSvc)
+	accountSvc := service.NewAccountService(repo).WithKYC(kycSvc)
+	gateways, err := newPayoutGateways(cfg)
+	if err != nil {
+		return err
+	}
+	payoutSvc := service.NewPayoutService(store, gateways.fallback).
+		WithConcurrency(cfg.PayoutConcurrency).
+		WithSendTimeout(cfg.PayoutTimeout).
+		WithApprovalThresholds(cfg.PayoutApprovalThresholds).
+		WithLimits(limitSvc).
+		WithKYC(kycSvc).
+		WithScreening(screeningSvc)
+	for currency, gw := range gateways.byCurrency {
+		payoutSvc.WithCurrencyGateway(currency, gw)
+	}
+	sepaGateway := gateways.sepa
+	beneficiarySvc := service.NewBeneficiaryService(store).
+		WithCooldown(cfg.BeneficiaryCooldown).
+		WithScreening(screeningSvc)
+
+	bus := outbox.NewBus()
+	amlSvc := service.NewAMLService(store)
+	var mailer mail.Sender = mail.LogSender{}
+	if cfg.MailSinkDir != "" {
+		mailer, err = mail.NewFileSender(cfg.MailSinkDir, cfg.MailFrom)
+		if err != nil {
+			return fmt.Errorf("configure mail sink: %w", err)
+		}
+	}
+	authSvc := service.NewAuthService(store).
+		WithLockout(cfg.LoginMaxFailedAttempts, cfg.LoginLockoutDuration).
+		WithResetTTL(cfg.PasswordResetTTL).
+		WithSessionTTLs(cfg.AccessTokenTTL, cfg.RefreshTokenTTL).
+		WithAPIKeyRotationGrace(cfg.APIKeyRotationGrace).
+		WithMailer(mailer).
+		WithRedis(redisClient)
+	sinks := []outbox.Sink{bus, amlSvc}
+	if cfg.OutboxRedisStream != "" {
+		sinks = append(sinks, outbox.NewRedisStreamSink(redisClient, cfg.OutboxRedisStream, outboxStreamMaxLen))
+	}
+	for _, url := range cfg.OutboxWebhookURLs {
+		sinks = append(sinks, outbox.NewWebhookSink(url, cfg.OutboxWebhookSecret, 10*time.Second))
+	}
+	outboxSvc := service.NewOutboxRelayService(store, sinks...)
+	outboxWorker := worker.NewOutboxWorker(outboxSvc).
+		WithPollInterval(cfg.OutboxPollInterval).
+		WithBatchSize(cfg.OutboxBatchSize).
+		WithRetention(cfg.OutboxRetention)
+
 	payoutWorker := worker.NewPayoutWorker(payoutSvc)
 	payoutWorker.WithPollInterval(cfg.PayoutPollInterval)
 	payoutWorker.WithBatchSize(cfg.PayoutBatchSize)
-	webhookSvc := service.NewWebhookService(store, cfg.WebhookHMACKey, cfg.WebhookSkipSignature)
-	reconciliationSvc := service.NewReconciliationService(store)
+	payoutWorker.WithDispatchEvents(bus.Subscribe(payoutDispatchBuffer, outbox.EventPayoutRequested, outbox.EventPayoutRequeued))
+	payoutListener := db.NewListener(cfg.DatabaseURL, domain.PayoutNotifyChannel, payoutDispatchBuffer)
+	payoutWorker.WithWakeups(payoutListener.Notifications())
+	webhookSvc := service.NewWebhookService(store, cfg.WebhookHMACKey, cfg.WebhookSkipSignature).WithKYC(kycSvc)
+	reconciliationSvc := service.NewReconciliationService(store).
+		WithMissingAfter(cfg.SettlementMissingAfter).
+		WithCheckpointLag(cfg.ReconciliationCheckpointLag)
 	reconciliationWorker := worker.NewReconciliationWorker(reconciliationSvc).WithInterval(cfg.ReconciliationInterval)
+	partitionSvc := service.NewPartitionService(store).
+		WithHorizon(cfg.PartitionHorizonMonths).
+		WithArchive(cfg.PartitionArchiveDir, cfg.PartitionRetentionMonths)
+	partitionWorker := worker.NewPartitionWorker(partitionSvc).WithInterval(cfg.PartitionMaintenanceInterval)
+	auditSvc := service.NewAuditService(store).WithAnchorDir(cfg.AuditAnchorDir)
+	lifecycleSvc := service.NewAccountLifecycleService(store)
+	overdraftSvc := service.NewOverdraftService(store)
+	overdraftWorker := worker.NewOverdraftInterestWorker(overdraftSvc).WithInterval(cfg.OverdraftInterestInterval)
+	auditAnchorWorker := worker.NewAuditAnchorWorker(auditSvc).WithInterval(cfg.AuditAnchorInterval)
 
+	stopPayoutListener := payoutListener.Run(ctx)
 	stopWorker := payoutWorker.Run(ctx)
-	logger.Info("payout worker started", zap.Duration("interval", cfg.PayoutPollInterval), zap.Int32("batch", cfg.PayoutBatchSize))
+	logger.Info("payout worker started", zap.Duration("interval", cfg.PayoutPollInterval), zap.Int32("batch", cfg.PayoutBatchSize), zap.Int("concurrency", cfg.PayoutConcurrency))
 	stopReconciliationWorker := reconciliationWorker.Run(ctx)
 	logger.Info("reconciliation worker started", zap.Duration("interval", cfg.ReconciliationInterval))
+	stopPartitionWorker := partitionWorker.Run(ctx)
+	logger.Info("partition worker started", zap.Duration("interval", cfg.PartitionMaintenanceInterval), zap.Int("horizon_months", cfg.PartitionHorizonMonths), zap.Int("retention_months", cfg.PartitionRetentionMonths))
+	stopAuditAnchorWorker := auditAnchorWorker.Run(ctx)
+	stopOverdraftWorker := overdraftWorker.Run(ctx)
+	logger.Info("audit anchor worker started", zap.Duration("interval", cfg.AuditAnchorInterval), zap.String("anchor_dir", cfg.AuditAnchorDir))
+	stopOutboxWorker := outboxWorker.Run(ctx)
+	logger.Info("outbox worker started", zap.Duration("interval", cfg.OutboxPollInterval), zap.Int("sinks", len(sinks)))
+	stopReportWorker := func() {}
+	if sepaGateway != nil {
+		stopReportWorker = worker.NewGatewayReportWorker(sepaGateway, payoutSvc).
+			WithPollInterval(cfg.SEPAReportPollInterval).
+			Run(ctx)
+		logger.Info("sepa file gateway enabled", zap.String("outbox_dir", cfg.SEPAOutboxDir), zap.Duration("batch_window", cfg.SEPABatchWindow), zap.String("reports_dir", cfg.SEPAReportsDir))
+	}
 
-	router := api.NewRouter(cfg, logger, pool, repo, idemStore, redisClient, accountSvc, transferSvc, payoutSvc, webhookSvc)
+	router := api.NewRouter(cfg, logger, pool, repo, idemStore, redisClient, accountSvc, transferSvc, payoutSvc, webhookSvc, beneficiarySvc, reconciliationSvc, auditSvc, lifecycleSvc, overdraftSvc, limitSvc, kycSvc, amlSvc, authSvc)
 
 	server := &http.Server{
 		Addr:         ":" + cfg.HTTPPort,
@@ -108,10 +200,19 @@ func Run() error {
 		}
 	}
 
+	logger.Info("stopping outbox worker")
+	stopOutboxWorker()
 	logger.Info("stopping payout worker")
+	stopPayoutListener()
 	stopWorker()
 	logger.Info("stopping reconciliation worker")
 	stopReconciliationWorker()
+	logger.Info("stopping partition worker")
+	stopPartitionWorker()
+	logger.Info("stopping audit anchor worker")
+	stopAuditAnchorWorker()
+	stopOverdraftWorker()
+	stopReportWorker()
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
@@ -123,6 +224,58 @@ func Run() error {
 	return nil
 }
 
+// payoutGateways are the gateways payouts are sent through, each behind its
+// own rate limiter.
+type payoutGateways struct {
+	fallback   gateway.Gateway
+	byCurrency map[string]gateway.Gateway
+	// sepa is the SEPA gateway itself, for the report worker, which only
+	// consumes status reports; nil when disabled.
+	sepa *sepa.Gateway
+}
+
+// newPayoutGateways wraps the mock gateway and, when SEPA_OUTBOX_DIR is
+// set, the EUR SEPA gateway in rate limiters configured per gateway.
+func newPayoutGateways(cfg *config.Config) (payoutGateways, error) {
+	gateways := payoutGateways{
+		fallback:   gateway.NewRateLimited(gateway.NewMockGateway(), cfg.GatewayRateLimitRPS, cfg.GatewayRateLimitBurst),
+		byCurrency: map[string]gateway.Gateway{},
+	}
+	if cfg.SEPAOutboxDir != "" {
+		sepaGateway, err := newSEPAGateway(cfg)
+		if err != nil {
+			return payoutGateways{}, err
+		}
+		gateways.sepa = sepaGateway
+		gateways.byCurrency["EUR"] = gateway.NewRateLimited(sepaGateway, cfg.SEPARateLimitRPS, cfg.SEPARateLimitBurst)
+	}
+	return gateways, nil
+}
+
+// newSEPAGateway builds the pain.001 file gateway. Status reports are only
+// consumed when SEPA_REPORTS_DIR is set.
+func newSEPAGateway(cfg *config.Config) (*sepa.Gateway, error) {
+	sepaCfg := sepa.Config{
+		Debtor: sepa.Debtor{
+			Name: cfg.SEPADebtorName,
+			IBAN: cfg.SEPADebtorIBAN,
+			BIC:  cfg.SEPADebtorBIC,
+		},
+		ArchiveDir:   cfg.SEPAArchiveDir,
+		Sink:         sepa.DirSink{Dir: cfg.SEPAOutboxDir},
+		BatchWindow:  cfg.SEPABatchWindow,
+		MaxBatchSize: cfg.SEPAMaxBatchSize,
+	}
+	if cfg.SEPAReportsDir != "" {
+		sepaCfg.Reports = sepa.DirSource{Dir: cfg.SEPAReportsDir}
+	}
+	gw, err := sepa.New(sepaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("configure sepa gateway: %w", err)
+	}
+	return gw, nil
+}
+
 func newLogger(level string) (*zap.Logger, error) {
 	cfg := zap.NewProductionConfig()
 	switch strings.ToLower(level) {
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/config"
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutGatewaysThrottleEURRoute(t *testing.T) {
+	cfg := &config.Config{
+		GatewayRateLimitRPS:   0,
+		GatewayRateLimitBurst: 1,
+		SEPAOutboxDir:         t.TempDir(),
+		SEPAArchiveDir:        t.TempDir(),
+		SEPADebtorName:        "Payment Multicurrency Ltd",
+		SEPADebtorIBAN:        "DE89370400440532013000",
+		SEPADebtorBIC:         "COBADEFFXXX",
+		SEPABatchWindow:       time.Second,
+		SEPAMaxBatchSize:      10,
+		SEPARateLimitRPS:      20, // one token every 50ms
+		SEPARateLimitBurst:    1,
+	}
+	gateways, err := newPayoutGateways(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, gateways.sepa)
+
+	// The default gateway has its own limiter, disabled here.
+	_, ok := gateways.fallback.(*gateway.RateLimited)
+	require.False(t, ok)
+
+	eur, ok := gateways.byCurrency["EUR"].(*gateway.RateLimited)
+	require.True(t, ok, "EUR payouts must go through a rate limiter")
+	require.True(t, gateway.SettlesAsynchronously(eur))
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		status, err := eur.GetPayoutStatus(ctx, "payout-unknown-1")
+		require.NoError(t, err)
+		require.Equal(t, gateway.PayoutStateNotFound, status.State)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+}
+
+func TestPayoutGatewaysWithoutSEPA(t *testing.T) {
+	gateways, err := newPayoutGateways(&config.Config{GatewayRateLimitRPS: 10, GatewayRateLimitBurst: 5})
+	require.NoError(t, err)
+	require.Nil(t, gateways.sepa)
+	require.Empty(t, gateways.byCurrency)
+	_, ok := gateways.fallback.(*gateway.RateLimited)
+	require.True(t, ok)
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	listenerMinBackoff = 500 * time.Millisecond
+	listenerMaxBackoff = 30 * time.Second
+)
+
+// Listener holds a dedicated connection LISTENing on one channel and forwards
+// notification payloads. Pooled connections are unsuitable because LISTEN
+// state is lost whenever the pool recycles the connection.
+//
+// Notifications sent while the connection is down are lost, so after every
+// (re)connect the listener emits an empty payload asking consumers to sweep.
+// The same marker replaces the first payload after any dropped ones.
+type Listener struct {
+	dbURL    string
+	channel  string
+	out      chan string
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	// dropped is set once a payload is dropped on a full buffer; only the
+	// Start goroutine touches it.
+	dropped bool
+}
+
+// NewListener creates a listener for channel; buffer bounds queued payloads.
+func NewListener(dbURL, channel string, buffer int) *Listener {
+	if buffer < 1 {
+		buffer = 1
+	}
+	return &Listener{
+		dbURL:   dbURL,
+		channel: channel,
+		out:     make(chan string, buffer),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Notifications returns the stream of notification payloads.
+func (l *Listener) Notifications() <-chan string {
+	return l.out
+}
+
+// Start blocks, reconnecting with exponential backoff until stopped.
+func (l *Listener) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	backoff := listenerMinBackoff
+	for {
+		err := l.listen(ctx, func() { backoff = listenerMinBackoff })
+		if ctx.Err() != nil {
+			zap.L().Info("db listener stopped", zap.String("channel", l.channel))
+			return
+		}
+		observability.IncrementDBListenerReconnect(l.channel)
+		zap.L().Warn("db listener disconnected; reconnecting", zap.Error(err), zap.String("channel", l.channel), zap.Duration("backoff", backoff))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > listenerMaxBackoff {
+			backoff = listenerMaxBackoff
+		}
+	}
+}
+
+// Stop signals the listener to close its connection and return.
+func (l *Listener) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopCh)
+	})
+}
+
+// Run starts the listener in a goroutine and returns a stop function.
+func (l *Listener) Run(ctx context.Context) func() {
+	go l.Start(ctx)
+	return l.Stop
+}
+
+func (l *Listener) listen(ctx context.Context, onConnected func()) error {
+	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	conn, err := pgx.Connect(connectCtx, l.dbURL)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen %s: %w", l.channel, err)
+	}
+	onConnected()
+	zap.L().Info("db listener connected", zap.String("channel", l.channel))
+	l.emit("")
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+		l.emit(n.Payload)
+	}
+}
+
+// emit forwards a payload without blocking. A dropped payload would wait for
+// the consumer's next poll, so after a drop the next payload that fits is
+// sent as an empty one, asking for a sweep that covers everything dropped.
+func (l *Listener) emit(payload string) {
+	if l.dropped {
+		payload = ""
+	}
+	select {
+	case l.out <- payload:
+		l.dropped = false
+	default:
+		l.dropped = true
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Payment schemes a payout destination can be routed over.
+const (
+	SchemeIBAN       = "IBAN"
+	SchemeUSACH      = "US_ACH"
+	SchemeUKSortCode = "UK_SORT_CODE"
+)
+
+// ibanLengths is the registered IBAN length per ISO 3166 country code.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22,
+	"BH": 22, "BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22,
+	"DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18, "FO": 18, "FR": 27,
+	"GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28,
+	"IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24,
+	"ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24,
+	"PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "SA": 24, "SC": 31,
+	"SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24,
+	"TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+var (
+	ibanPattern = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`)
+	bicPattern  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	digits      = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// NormalizeAccountIdentifier strips the spaces and dashes people paste into
+// IBANs, BICs and sort codes, and upper-cases the result.
+func NormalizeAccountIdentifier(v string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(v)))
+}
+
+// ValidateIBAN checks an IBAN's structure, country-specific length and
+// ISO 7064 mod-97 check digits. The input must already be normalized.
+func ValidateIBAN(iban string) error {
+	if !ibanPattern.MatchString(iban) {
+		return errors.New("iban must be a country code, two check digits and an alphanumeric account number")
+	}
+	want, ok := ibanLengths[iban[:2]]
+	if !ok {
+		return fmt.Errorf("iban country %s is not supported", iban[:2])
+	}
+	if len(iban) != want {
+		return fmt.Errorf("iban for %s must be %d characters, got %d", iban[:2], want, len(iban))
+	}
+	if ibanMod97(iban[4:]+iban[:4]) != 1 {
+		return errors.New("iban check digits are invalid")
+	}
+	return nil
+}
+
+// ibanMod97 computes the remainder of the IBAN's numeric form, with letters
+// expanded to 10..35, without materializing the full number.
+func ibanMod97(rearranged string) int {
+	remainder := 0
+	for _, c := range rearranged {
+		if c >= 'A' && c <= 'Z' {
+			v := int(c-'A') + 10
+			remainder = (remainder*100 + v) % 97
+			continue
+		}
+		remainder = (remainder*10 + int(c-'0')) % 97
+	}
+	return remainder
+}
+
+// ValidateBIC checks a SWIFT BIC: bank code, country, location and an
+// optional branch code. The input must already be normalized.
+func ValidateBIC(bic string) error {
+	if !bicPattern.MatchString(bic) {
+		return errors.New("bic must be 8 or 11 characters: bank, country, location and optional branch code")
+	}
+	return nil
+}
+
+// ValidateABARoutingNumber checks a 9-digit US ABA routing number and its
+// 3-7-1 weighted checksum.
+func ValidateABARoutingNumber(routing string) error {
+	if len(routing) != 9 || !digits.MatchString(routing) {
+		return errors.New("routing_number must be 9 digits")
+	}
+	weights := [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i, c := range routing {
+		sum += int(c-'0') * weights[i]
+	}
+	if sum%10 != 0 {
+		return errors.New("routing_number checksum is invalid")
+	}
+	return nil
+}
+
+// ValidateUSAccountNumber checks a US bank account number (4 to 17 digits).
+func ValidateUSAccountNumber(account string) error {
+	if len(account) < 4 || len(account) > 17 || !digits.MatchString(account) {
+		return errors.New("account_number must be 4 to 17 digits")
+	}
+	return nil
+}
+
+// ValidateSortCode checks a 6-digit UK sort code. The input must already be
+// normalized, so "12-34-56" arrives as "123456".
+func ValidateSortCode(sortCode string) error {
+	if len(sortCode) != 6 || !digits.MatchString(sortCode) {
+		return errors.New("sort_code must be 6 digits")
+	}
+	return nil
+}
+
+// ValidateUKAccountNumber checks an 8-digit UK bank account number.
+func ValidateUKAccountNumber(account string) error {
+	if len(account) != 8 || !digits.MatchString(account) {
+		return errors.New("account_number must be 8 digits for UK sort code payments")
+	}
+	return nil
+}
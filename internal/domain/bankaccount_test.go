@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIBAN(t *testing.T) {
+	cases := []struct {
+		name string
+		iban string
+		ok   bool
+	}{
+		{name: "gb", iban: "GB29NWBK60161331926819", ok: true},
+		{name: "de", iban: "DE89370400440532013000", ok: true},
+		{name: "fr_with_letters", iban: "FR1420041010050500013M02606", ok: true},
+		{name: "nl", iban: "NL91ABNA0417164300", ok: true},
+		{name: "normalized_input", iban: NormalizeAccountIdentifier("be68 5390 0754 7034"), ok: true},
+		{name: "bad_checksum", iban: "GB28NWBK60161331926819", ok: false},
+		{name: "wrong_length_for_country", iban: "DE8937040044053201300", ok: false},
+		{name: "unknown_country", iban: "ZZ29NWBK60161331926819", ok: false},
+		{name: "not_alphanumeric", iban: "GB29NWBK6016133192681!", ok: false},
+		{name: "empty", iban: "", ok: false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateIBAN(tc.iban)
+			if tc.ok {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	assert.NoError(t, ValidateBIC("NWBKGB2L"))
+	assert.NoError(t, ValidateBIC("DEUTDEFF500"))
+	assert.Error(t, ValidateBIC("NWBKGB2"))
+	assert.Error(t, ValidateBIC("NWBK1B2L"))
+	assert.Error(t, ValidateBIC("DEUTDEFF50"))
+}
+
+func TestValidateUSBankDetails(t *testing.T) {
+	assert.NoError(t, ValidateABARoutingNumber("021000021"))
+	assert.NoError(t, ValidateABARoutingNumber("011000015"))
+	assert.Error(t, ValidateABARoutingNumber("021000022"), "checksum")
+	assert.Error(t, ValidateABARoutingNumber("02100002"), "length")
+	assert.Error(t, ValidateABARoutingNumber("02100002A"), "digits")
+
+	assert.NoError(t, ValidateUSAccountNumber("1234"))
+	assert.NoError(t, ValidateUSAccountNumber("12345678901234567"))
+	assert.Error(t, ValidateUSAccountNumber("123"))
+	assert.Error(t, ValidateUSAccountNumber("123456789012345678"))
+}
+
+func TestValidateUKBankDetails(t *testing.T) {
+	assert.NoError(t, ValidateSortCode(NormalizeAccountIdentifier("60-16-13")))
+	assert.Error(t, ValidateSortCode("60161"))
+	assert.NoError(t, ValidateUKAccountNumber("31926819"))
+	assert.Error(t, ValidateUKAccountNumber("3192681"))
+}
@@ -8,6 +8,11 @@ const (
 	SystemAccountEUR = "33333333-3333-3333-3333-333333333333"
 	SystemAccountGBP = "44444444-4444-4444-4444-444444444444"
 
+	// Equity accounts fund opening balances (migration 000026)
+	SystemEquityAccountUSD = "55555555-5555-5555-5555-555555555555"
+	SystemEquityAccountEUR = "66666666-6666-6666-6666-666666666666"
+	SystemEquityAccountGBP = "77777777-7777-7777-7777-777777777777"
+
 	DirectionDebit  = "debit"
 	DirectionCredit = "credit"
 
@@ -16,16 +21,104 @@ const (
 	TxTypePayout   = "payout"
 	TxTypeDeposit  = "deposit"
 
+	TxTypeOpeningBalance = "opening_balance"
+	TxTypeFee            = "fee"
+
 	TxStatusCompleted  = "COMPLETED"
 	TxStatusFailed     = "FAILED"
 	TxStatusPending    = "PENDING"
 	TxStatusProcessing = "PROCESSING"
 	TxStatusReversed   = "REVERSED"
 
+	// Account statuses. FROZEN_DEBITS still accepts credits.
+	AccountStatusActive       = "ACTIVE"
+	AccountStatusFrozenDebits = "FROZEN_DEBITS"
+	AccountStatusFrozenAll    = "FROZEN_ALL"
+	AccountStatusClosed       = "CLOSED"
+
+	// Reason codes for account status changes
+	AccountReasonFraudSuspected     = "FRAUD_SUSPECTED"
+	AccountReasonAccountCompromised = "ACCOUNT_COMPROMISED"
+	AccountReasonLegalOrder         = "LEGAL_ORDER"
+	AccountReasonComplianceReview   = "COMPLIANCE_REVIEW"
+	AccountReasonCustomerRequest    = "CUSTOMER_REQUEST"
+	AccountReasonReviewCleared      = "REVIEW_CLEARED"
+	AccountReasonOther              = "OTHER"
+
+	// KYC statuses. Only a VERIFIED user can hold a tier above KYCTierUnverified.
+	KYCStatusNone     = "NONE"
+	KYCStatusPending  = "PENDING"
+	KYCStatusVerified = "VERIFIED"
+	KYCStatusRejected = "REJECTED"
+	KYCTierUnverified = 0
+
 	// Payout statuses
-	PayoutStatusPending      = "PENDING"
-	PayoutStatusProcessing   = "PROCESSING"
-	PayoutStatusCompleted    = "COMPLETED"
-	PayoutStatusFailed       = "FAILED"
-	PayoutStatusManualReview = "MANUAL_REVIEW"
+	PayoutStatusAwaitingApproval = "AWAITING_APPROVAL"
+	PayoutStatusPending          = "PENDING"
+	PayoutStatusProcessing       = "PROCESSING"
+	PayoutStatusSubmitted        = "SUBMITTED"
+	PayoutStatusCompleted        = "COMPLETED"
+	PayoutStatusFailed           = "FAILED"
+	PayoutStatusRejected         = "REJECTED"
+	PayoutStatusManualReview     = "MANUAL_REVIEW"
+	PayoutStatusScreeningHold    = "SCREENING_HOLD"
+
+	// Settlement reconciliation break types and statuses
+	BreakTypePaidButFailed       = "PAID_BUT_FAILED"
+	BreakTypeCompletedButMissing = "COMPLETED_BUT_MISSING"
+	BreakTypeAmountMismatch      = "AMOUNT_MISMATCH"
+	BreakTypeUnknownDebit        = "UNKNOWN_DEBIT"
+	BreakStatusOpen              = "OPEN"
+	BreakStatusCleared           = "CLEARED"
+
+	// Ledger invariant findings raised by reconciliation
+	FindingTypeAccountBalanceDrift = "ACCOUNT_BALANCE_DRIFT"
+	FindingTypeLockedFundsDrift    = "LOCKED_FUNDS_DRIFT"
+	FindingTypeUnbalancedTx        = "TRANSACTION_UNBALANCED"
+	FindingTypeSealedPeriodChanged = "SEALED_PERIOD_CHANGED"
+
+	// Reconciliation run triggers, scopes and statuses
+	RunTriggerScheduled = "SCHEDULED"
+	RunTriggerManual    = "MANUAL"
+	RunScopeFull        = "FULL"
+	RunScopeIncremental = "INCREMENTAL"
+	RunStatusRunning    = "RUNNING"
+	RunStatusPassed     = "PASSED"
+	RunStatusFailed     = "FAILED"
+	RunStatusError      = "ERROR"
+
+	// AML monitoring rules, case statuses and closing resolutions
+	AMLRuleStructuring    = "STRUCTURING"
+	AMLRuleRapidMovement  = "RAPID_MOVEMENT"
+	AMLRuleFXRoundTrip    = "FX_ROUND_TRIP"
+	AMLCaseOpen           = "OPEN"
+	AMLCaseInvestigating  = "INVESTIGATING"
+	AMLCaseEscalated      = "ESCALATED"
+	AMLCaseClosed         = "CLOSED"
+	AMLResolutionNoAction = "NO_ACTION"
+	AMLResolutionReported = "REPORTED"
+	AMLSeverityLow        = "LOW"
+	AMLSeverityMedium     = "MEDIUM"
+	AMLSeverityHigh       = "HIGH"
+
+	// Ledger month seal statuses
+	LedgerSealStatusSealed   = "SEALED"
+	LedgerSealStatusBroken   = "BROKEN"
+	LedgerSealStatusArchived = "ARCHIVED"
+
+	// Audit hash chain break reasons
+	AuditBreakHashMismatch   = "HASH_MISMATCH"
+	AuditBreakLinkMismatch   = "PREV_HASH_MISMATCH"
+	AuditBreakSequenceGap    = "SEQUENCE_GAP"
+	AuditBreakHeadMismatch   = "HEAD_MISMATCH"
+	AuditBreakAnchorMismatch = "ANCHOR_MISMATCH"
+	AuditBreakAnchorMissing  = "ANCHOR_MISSING"
+
+	// Actor types in the customer audit trail
+	AuditActorCustomer = "customer"
+	AuditActorStaff    = "staff"
+	AuditActorSystem   = "system"
+
+	// PayoutNotifyChannel is the Postgres NOTIFY channel carrying payout IDs ready for dispatch.
+	PayoutNotifyChannel = "payout_requested"
 )
@@ -0,0 +1,38 @@
+package gateway
+
+import "context"
+
+// AsyncGateway is implemented by gateways whose SendPayout only submits a
+// payout, such as bank file channels. The returned reference identifies the
+// payout in later status reports, and the final outcome arrives as a Settlement.
+type AsyncGateway interface {
+	Gateway
+	SettlesAsynchronously() bool
+}
+
+// SettlesAsynchronously reports whether payouts accepted by g still await a
+// status report before they are final.
+func SettlesAsynchronously(g Gateway) bool {
+	async, ok := g.(AsyncGateway)
+	return ok && async.SettlesAsynchronously()
+}
+
+// Settlement is a gateway's final (or interim) word on a submitted payout.
+type Settlement struct {
+	// Ref is the reference SendPayout returned for the payout.
+	Ref    string
+	State  PayoutState
+	Reason string
+}
+
+// SettlementHandler applies one settlement. Returning an error leaves the
+// report it came from unacknowledged so it is retried.
+type SettlementHandler func(ctx context.Context, settlement Settlement) error
+
+// ReportConsumer is implemented by asynchronous gateways that receive status
+// reports out of band.
+type ReportConsumer interface {
+	// ConsumeReports applies every pending status report and returns the
+	// number of settlements handled.
+	ConsumeReports(ctx context.Context, handle SettlementHandler) (int, error)
+}
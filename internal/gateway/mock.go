@@ -2,8 +2,10 @@ package gateway
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -11,33 +13,92 @@ import (
 type Gateway interface {
 	// SendPayout sends a payout to an external destination.
 	// Returns a gateway reference ID and an error if the payout failed.
-	SendPayout(ctx context.Context, destination string, amount int64, currency string) (string, error)
+	// Gateways must treat req.IdempotencyKey as a dedupe key: resending the
+	// same key must not move money twice.
+	SendPayout(ctx context.Context, req PayoutRequest) (string, error)
+
+	// GetPayoutStatus looks up a previously submitted payout by idempotency key.
+	GetPayoutStatus(ctx context.Context, idempotencyKey string) (PayoutStatus, error)
+}
+
+// PayoutRequest describes a single payout submission.
+// Destination is a display form of the payee; the Creditor fields carry the
+// structured details that file-based schemes need.
+type PayoutRequest struct {
+	PayoutID       string
+	IdempotencyKey string
+	Destination    string
+	AmountMicros   int64
+	Currency       string
+	CreditorName   string
+	CreditorIBAN   string
+	CreditorBIC    string
 }
 
+// PayoutState is the gateway-side view of a submitted payout.
+type PayoutState string
+
+const (
+	// PayoutStateUnknown means the gateway could not say what happened.
+	PayoutStateUnknown PayoutState = "UNKNOWN"
+	// PayoutStateNotFound means the gateway never received the key.
+	PayoutStateNotFound PayoutState = "NOT_FOUND"
+	// PayoutStateSubmitted means the gateway holds the payout but has not yet
+	// reported whether it settled. Only asynchronous gateways return it.
+	PayoutStateSubmitted PayoutState = "SUBMITTED"
+	// PayoutStateSent means the gateway accepted and sent the payout.
+	PayoutStateSent PayoutState = "SENT"
+	// PayoutStateFailed means the gateway received the payout and rejected it.
+	PayoutStateFailed PayoutState = "FAILED"
+)
+
+// PayoutStatus is the result of a status lookup.
+type PayoutStatus struct {
+	State PayoutState
+	Ref   string
+}
+
+// errMockRejected is the mock's failure, returned again for a key it failed.
+var errMockRejected = errors.New("gateway rejected payout")
+
 // MockGateway simulates an external payment gateway for testing.
 // It introduces a random delay (2-5 seconds) and fails ~10% of the time.
 type MockGateway struct {
 	// FailureRate is the probability of failure (0.0 to 1.0). Default: 0.1 (10%)
 	FailureRate float64
+	// Delay returns the simulated latency of a call. Nil means 2-5 seconds.
+	Delay func() time.Duration
+
+	mu   sync.Mutex
+	sent map[string]PayoutStatus
 }
 
 // NewMockGateway creates a new MockGateway with default settings.
 func NewMockGateway() *MockGateway {
 	return &MockGateway{
 		FailureRate: 0.1, // 10% failure rate
+		sent:        make(map[string]PayoutStatus),
 	}
 }
 
 // SendPayout simulates sending a payout to an external gateway.
 // It sleeps for 2-5 seconds to simulate network latency, then randomly
 // fails based on the FailureRate. Returns a fake reference ID on success.
-func (g *MockGateway) SendPayout(ctx context.Context, destination string, amount int64, currency string) (string, error) {
-	// Simulate network delay: 2-5 seconds
-	delay := 2 + rand.Intn(3) // 2, 3, or 4 seconds, plus random ms
-	delayMs := time.Duration(delay*1000+rand.Intn(1000)) * time.Millisecond
+// Both outcomes are recorded, so repeated calls and status lookups with the
+// same idempotency key return the original one.
+func (g *MockGateway) SendPayout(ctx context.Context, req PayoutRequest) (string, error) {
+	if req.IdempotencyKey == "" {
+		return "", fmt.Errorf("idempotency key is required")
+	}
+	if status, ok := g.lookup(req.IdempotencyKey); ok {
+		if status.State == PayoutStateFailed {
+			return "", errMockRejected
+		}
+		return status.Ref, nil
+	}
 
 	select {
-	case <-time.After(delayMs):
+	case <-time.After(g.delay()):
 		// Continue after delay
 	case <-ctx.Done():
 		return "", fmt.Errorf("gateway call canceled: %w", ctx.Err())
@@ -45,11 +106,49 @@ func (g *MockGateway) SendPayout(ctx context.Context, destination string, amount
 
 	// Randomly fail based on FailureRate
 	if rand.Float64() < g.FailureRate {
-		return "", fmt.Errorf("gateway temporarily unavailable")
+		g.record(req.IdempotencyKey, PayoutStatus{State: PayoutStateFailed})
+		return "", errMockRejected
 	}
 
 	// Generate fake reference ID
 	// Format: MOCK-YYYYMMDD-HHMMSS-XXXXX
 	ref := fmt.Sprintf("MOCK-%s-%05d", time.Now().Format("20060102-150405"), rand.Intn(100000))
+	g.record(req.IdempotencyKey, PayoutStatus{State: PayoutStateSent, Ref: ref})
 	return ref, nil
 }
+
+// GetPayoutStatus reports what the mock gateway recorded for an idempotency key.
+func (g *MockGateway) GetPayoutStatus(ctx context.Context, idempotencyKey string) (PayoutStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return PayoutStatus{State: PayoutStateUnknown}, err
+	}
+	if status, ok := g.lookup(idempotencyKey); ok {
+		return status, nil
+	}
+	return PayoutStatus{State: PayoutStateNotFound}, nil
+}
+
+// delay simulates network latency: 2-5 seconds unless Delay is set.
+func (g *MockGateway) delay() time.Duration {
+	if g.Delay != nil {
+		return g.Delay()
+	}
+	delay := 2 + rand.Intn(3) // 2, 3, or 4 seconds, plus random ms
+	return time.Duration(delay*1000+rand.Intn(1000)) * time.Millisecond
+}
+
+func (g *MockGateway) lookup(key string) (PayoutStatus, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	status, ok := g.sent[key]
+	return status, ok
+}
+
+func (g *MockGateway) record(key string, status PayoutStatus) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.sent == nil {
+		g.sent = make(map[string]PayoutStatus)
+	}
+	g.sent[key] = status
+}
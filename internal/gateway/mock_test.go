@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noDelay() time.Duration { return 0 }
+
+func TestMockGatewayRecordsOutcomes(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &MockGateway{FailureRate: 1, Delay: noDelay}
+	_, err := failing.SendPayout(ctx, PayoutRequest{IdempotencyKey: "payout-a-1"})
+	require.ErrorIs(t, err, errMockRejected)
+	status, err := failing.GetPayoutStatus(ctx, "payout-a-1")
+	require.NoError(t, err)
+	require.Equal(t, PayoutStateFailed, status.State)
+
+	// A resend with the failed key fails again even once the gateway recovers.
+	failing.FailureRate = 0
+	_, err = failing.SendPayout(ctx, PayoutRequest{IdempotencyKey: "payout-a-1"})
+	require.ErrorIs(t, err, errMockRejected)
+
+	sending := &MockGateway{Delay: noDelay}
+	ref, err := sending.SendPayout(ctx, PayoutRequest{IdempotencyKey: "payout-b-1"})
+	require.NoError(t, err)
+	again, err := sending.SendPayout(ctx, PayoutRequest{IdempotencyKey: "payout-b-1"})
+	require.NoError(t, err)
+	require.Equal(t, ref, again)
+	status, err = sending.GetPayoutStatus(ctx, "payout-b-1")
+	require.NoError(t, err)
+	require.Equal(t, PayoutStatus{State: PayoutStateSent, Ref: ref}, status)
+
+	status, err = sending.GetPayoutStatus(ctx, "payout-c-1")
+	require.NoError(t, err)
+	require.Equal(t, PayoutStateNotFound, status.State)
+}
@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimited wraps a Gateway with a token bucket shared by every call to it,
+// so a worker pool cannot exceed the provider's request budget.
+type RateLimited struct {
+	next Gateway
+
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+}
+
+// NewRateLimited limits next to rps calls per second with the given burst.
+// A non-positive rps disables limiting and returns next unchanged.
+func NewRateLimited(next Gateway, rps float64, burst int) Gateway {
+	if rps <= 0 {
+		return next
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimited{
+		next:     next,
+		interval: time.Duration(float64(time.Second) / rps),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// SendPayout waits for a token, then delegates.
+func (g *RateLimited) SendPayout(ctx context.Context, req PayoutRequest) (string, error) {
+	if err := g.wait(ctx); err != nil {
+		return "", err
+	}
+	return g.next.SendPayout(ctx, req)
+}
+
+// GetPayoutStatus waits for a token, then delegates.
+func (g *RateLimited) GetPayoutStatus(ctx context.Context, idempotencyKey string) (PayoutStatus, error) {
+	if err := g.wait(ctx); err != nil {
+		return PayoutStatus{State: PayoutStateUnknown}, err
+	}
+	return g.next.GetPayoutStatus(ctx, idempotencyKey)
+}
+
+// SettlesAsynchronously reports whether the wrapped gateway does.
+func (g *RateLimited) SettlesAsynchronously() bool {
+	return SettlesAsynchronously(g.next)
+}
+
+// wait blocks until a token is available or ctx is done.
+func (g *RateLimited) wait(ctx context.Context) error {
+	for {
+		delay := g.reserve()
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise reports how long
+// until the next one is.
+func (g *RateLimited) reserve() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.tokens += float64(now.Sub(g.last)) / float64(g.interval)
+	if g.tokens > float64(g.burst) {
+		g.tokens = float64(g.burst)
+	}
+	g.last = now
+
+	if g.tokens >= 1 {
+		g.tokens--
+		return 0
+	}
+	return time.Duration((1 - g.tokens) * float64(g.interval))
+}
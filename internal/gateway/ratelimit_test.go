@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type countingGateway struct {
+	calls int
+}
+
+func (g *countingGateway) SendPayout(ctx context.Context, req PayoutRequest) (string, error) {
+	g.calls++
+	return "REF", nil
+}
+
+func (g *countingGateway) GetPayoutStatus(ctx context.Context, idempotencyKey string) (PayoutStatus, error) {
+	g.calls++
+	return PayoutStatus{State: PayoutStateNotFound}, nil
+}
+
+func TestRateLimitedDisabledReturnsInner(t *testing.T) {
+	inner := &countingGateway{}
+	require.Same(t, inner, NewRateLimited(inner, 0, 1))
+}
+
+func TestRateLimitedAllowsBurstThenWaits(t *testing.T) {
+	inner := &countingGateway{}
+	gw := NewRateLimited(inner, 20, 2) // one token every 50ms
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := gw.SendPayout(ctx, PayoutRequest{IdempotencyKey: "k"})
+		require.NoError(t, err)
+	}
+	require.Less(t, time.Since(start), 25*time.Millisecond, "burst should not wait")
+
+	_, err := gw.GetPayoutStatus(ctx, "k")
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	require.Equal(t, 3, inner.calls)
+}
+
+func TestRateLimitedHonorsContext(t *testing.T) {
+	inner := &countingGateway{}
+	gw := NewRateLimited(inner, 1, 1)
+
+	_, err := gw.SendPayout(context.Background(), PayoutRequest{IdempotencyKey: "k"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = gw.SendPayout(ctx, PayoutRequest{IdempotencyKey: "k2"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 1, inner.calls)
+}
@@ -0,0 +1,125 @@
+package sepa
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileSink delivers credit transfer files to the bank. Put must be all or
+// nothing: a failed Put must not leave a file the bank could pick up, and
+// repeating a Put with the same name and content must be harmless.
+type FileSink interface {
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// ReportSource supplies status reports from the bank.
+type ReportSource interface {
+	// List returns the names of unprocessed reports, oldest first.
+	List(ctx context.Context) ([]string, error)
+	Read(ctx context.Context, name string) ([]byte, error)
+	// Ack marks a report as fully applied.
+	Ack(ctx context.Context, name string) error
+	// Reject sets aside a report that cannot be applied.
+	Reject(ctx context.Context, name string, reason error) error
+}
+
+// DirSink writes files into a directory, such as an SFTP drop folder mounted
+// locally. Files are written under a dot-prefixed temporary name and renamed
+// into place, so a poller never sees a partial file.
+type DirSink struct {
+	Dir string
+}
+
+// Put writes data to Dir/name atomically.
+func (s DirSink) Put(_ context.Context, name string, data []byte) error {
+	return writeFileAtomic(filepath.Join(s.Dir, name), data)
+}
+
+// DirSource reads status reports from a directory. Applied reports move to
+// processed/ and unusable ones to rejected/, next to a .error file explaining why.
+type DirSource struct {
+	Dir string
+}
+
+// List returns the XML files waiting in Dir, sorted by name.
+func (s DirSource) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("list reports: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && !strings.HasPrefix(name, ".") && strings.EqualFold(filepath.Ext(name), ".xml") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Read returns a report's content.
+func (s DirSource) Read(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("read report %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Ack moves a report into processed/.
+func (s DirSource) Ack(_ context.Context, name string) error {
+	return s.move(name, "processed")
+}
+
+// Reject moves a report into rejected/ and records the reason beside it.
+func (s DirSource) Reject(_ context.Context, name string, reason error) error {
+	if err := s.move(name, "rejected"); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(s.Dir, "rejected", name+".error"), []byte(reason.Error()+"\n"))
+}
+
+func (s DirSource) move(name, subdir string) error {
+	dir := filepath.Join(s.Dir, subdir)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create %s dir: %w", subdir, err)
+	}
+	if err := os.Rename(filepath.Join(s.Dir, name), filepath.Join(dir, name)); err != nil {
+		return fmt.Errorf("move report %s to %s: %w", name, subdir, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary sibling, syncs it and renames it
+// over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("sync %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+// Package sepa implements a file-based payout gateway that batches EUR
+// payouts into ISO 20022 pain.001.001.03 credit transfer files and settles
+// them from pain.002 status reports.
+package sepa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchWindow  = 5 * time.Second
+	defaultMaxBatchSize = 500
+	putTimeout          = 30 * time.Second
+
+	archiveSuffix = ".xml"
+	pendingSuffix = ".xml.pending"
+)
+
+// Config configures a Gateway.
+type Config struct {
+	Debtor Debtor
+	// ArchiveDir keeps a copy of every file sent. It is the gateway's record
+	// of which end-to-end IDs were submitted, so it must survive restarts and
+	// be shared by every instance.
+	ArchiveDir string
+	Sink       FileSink
+	// Reports is optional; without it ConsumeReports does nothing.
+	Reports ReportSource
+	// BatchWindow is how long the first payout of a file waits for others.
+	BatchWindow  time.Duration
+	MaxBatchSize int
+}
+
+// Gateway collects payouts into pain.001 files. SendPayout returns once the
+// payout's file has been delivered, with the transfer's end-to-end ID as the
+// reference; the outcome arrives later through ConsumeReports.
+type Gateway struct {
+	debtor     Debtor
+	archiveDir string
+	sink       FileSink
+	reports    ReportSource
+	window     time.Duration
+	maxBatch   int
+	now        func() time.Time
+
+	mu       sync.Mutex
+	current  *batch
+	inflight map[string]*batch
+	// states and files index the archive: end-to-end ID to last known
+	// state, and MsgId to the end-to-end IDs the file carried.
+	states map[string]gateway.PayoutState
+	files  map[string][]string
+}
+
+type batch struct {
+	transfers []Transfer
+	done      chan struct{}
+	err       error
+}
+
+var (
+	_ gateway.AsyncGateway   = (*Gateway)(nil)
+	_ gateway.ReportConsumer = (*Gateway)(nil)
+)
+
+// New validates cfg, finishes delivering any file a previous run left
+// pending, and indexes the archive.
+func New(cfg Config) (*Gateway, error) {
+	debtor := Debtor{
+		Name: strings.TrimSpace(cfg.Debtor.Name),
+		IBAN: domain.NormalizeAccountIdentifier(cfg.Debtor.IBAN),
+		BIC:  domain.NormalizeAccountIdentifier(cfg.Debtor.BIC),
+	}
+	if debtor.Name == "" {
+		return nil, errors.New("sepa: debtor name is required")
+	}
+	if err := domain.ValidateIBAN(debtor.IBAN); err != nil {
+		return nil, fmt.Errorf("sepa: debtor %w", err)
+	}
+	if err := domain.ValidateBIC(debtor.BIC); err != nil {
+		return nil, fmt.Errorf("sepa: debtor %w", err)
+	}
+	if cfg.Sink == nil {
+		return nil, errors.New("sepa: file sink is required")
+	}
+	if cfg.ArchiveDir == "" {
+		return nil, errors.New("sepa: archive dir is required")
+	}
+	if err := os.MkdirAll(cfg.ArchiveDir, 0o750); err != nil {
+		return nil, fmt.Errorf("sepa: create archive dir: %w", err)
+	}
+
+	g := &Gateway{
+		debtor:     debtor,
+		archiveDir: cfg.ArchiveDir,
+		sink:       cfg.Sink,
+		reports:    cfg.Reports,
+		window:     defaultBatchWindow,
+		maxBatch:   defaultMaxBatchSize,
+		now:        time.Now,
+		inflight:   make(map[string]*batch),
+		states:     make(map[string]gateway.PayoutState),
+		files:      make(map[string][]string),
+	}
+	if cfg.BatchWindow > 0 {
+		g.window = cfg.BatchWindow
+	}
+	if cfg.MaxBatchSize > 0 {
+		g.maxBatch = cfg.MaxBatchSize
+	}
+	if err := g.reindex(context.Background()); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SettlesAsynchronously reports that payouts settle from status reports.
+func (g *Gateway) SettlesAsynchronously() bool {
+	return true
+}
+
+// EndToEndID derives the pain.001 end-to-end ID for an idempotency key, so a
+// retried attempt maps onto the transfer it already created.
+func EndToEndID(idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(idempotencyKey))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SendPayout queues the payout for the current file and waits for the file to
+// be delivered. A key that was already delivered returns its reference again.
+func (g *Gateway) SendPayout(ctx context.Context, req gateway.PayoutRequest) (string, error) {
+	transfer, err := g.transferFor(req)
+	if err != nil {
+		return "", err
+	}
+	ref := transfer.EndToEndID
+
+	g.mu.Lock()
+	if _, ok := g.states[ref]; ok {
+		g.mu.Unlock()
+		return ref, nil
+	}
+	b, ok := g.inflight[ref]
+	if !ok {
+		b = g.current
+		if b == nil {
+			b = &batch{done: make(chan struct{})}
+			g.current = b
+			time.AfterFunc(g.window, func() { g.flush(b) })
+		}
+		b.transfers = append(b.transfers, transfer)
+		g.inflight[ref] = b
+		if len(b.transfers) >= g.maxBatch {
+			g.current = nil
+			go g.write(b)
+		}
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-b.done:
+		if b.err != nil {
+			return "", b.err
+		}
+		return ref, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// GetPayoutStatus reports SUBMITTED, or the settled state, for a delivered
+// key. A key still waiting for its file is UNKNOWN: the file may yet be sent.
+func (g *Gateway) GetPayoutStatus(ctx context.Context, idempotencyKey string) (gateway.PayoutStatus, error) {
+	ref := EndToEndID(idempotencyKey)
+	if status, ok := g.lookupStatus(ref); ok {
+		return status, nil
+	}
+	// Another instance may have delivered it since the archive was indexed.
+	if err := g.reindex(ctx); err != nil {
+		return gateway.PayoutStatus{}, err
+	}
+	if status, ok := g.lookupStatus(ref); ok {
+		return status, nil
+	}
+	return gateway.PayoutStatus{State: gateway.PayoutStateNotFound}, nil
+}
+
+func (g *Gateway) lookupStatus(ref string) (gateway.PayoutStatus, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if state, ok := g.states[ref]; ok {
+		return gateway.PayoutStatus{State: state, Ref: ref}, true
+	}
+	if _, ok := g.inflight[ref]; ok {
+		return gateway.PayoutStatus{State: gateway.PayoutStateUnknown, Ref: ref}, true
+	}
+	return gateway.PayoutStatus{}, false
+}
+
+// ConsumeReports applies every waiting pain.002 report through handle and
+// acknowledges it. A report that cannot be parsed, or that refers to a file
+// this gateway never sent, is rejected; a handler error stops the pass and
+// leaves the report to be retried.
+func (g *Gateway) ConsumeReports(ctx context.Context, handle gateway.SettlementHandler) (int, error) {
+	if g.reports == nil {
+		return 0, nil
+	}
+	names, err := g.reports.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+		data, err := g.reports.Read(ctx, name)
+		if err != nil {
+			return handled, err
+		}
+		reportID, settlements, err := parseStatusReport(data, g.originalIDs)
+		if err != nil {
+			zap.L().Warn("rejecting pain.002 status report", zap.Error(err), zap.String("report", name))
+			if rejectErr := g.reports.Reject(ctx, name, err); rejectErr != nil {
+				return handled, rejectErr
+			}
+			continue
+		}
+		for _, settlement := range settlements {
+			if err := handle(ctx, settlement); err != nil {
+				return handled, fmt.Errorf("apply %s from report %s: %w", settlement.Ref, name, err)
+			}
+			g.mu.Lock()
+			g.states[settlement.Ref] = settlement.State
+			g.mu.Unlock()
+			handled++
+		}
+		if err := g.reports.Ack(ctx, name); err != nil {
+			return handled, err
+		}
+		zap.L().Info("applied pain.002 status report",
+			zap.String("report", name),
+			zap.String("msg_id", reportID),
+			zap.Int("settlements", len(settlements)),
+		)
+	}
+	return handled, nil
+}
+
+// originalIDs returns the end-to-end IDs of a sent file by MsgId (which is
+// also its PmtInfId), re-reading the archive for files other instances sent.
+func (g *Gateway) originalIDs(msgID string) ([]string, bool) {
+	g.mu.Lock()
+	ids, ok := g.files[msgID]
+	g.mu.Unlock()
+	if ok {
+		return ids, true
+	}
+	if err := g.reindex(context.Background()); err != nil {
+		zap.L().Warn("sepa archive reindex failed", zap.Error(err))
+		return nil, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ids, ok = g.files[msgID]
+	return ids, ok
+}
+
+// transferFor validates a payout request as a single-transfer file, so one
+// bad payout fails on its own instead of spoiling the batch it would join.
+func (g *Gateway) transferFor(req gateway.PayoutRequest) (Transfer, error) {
+	if req.Currency != currencyEUR {
+		return Transfer{}, fmt.Errorf("sepa: currency %s is not supported, only EUR", req.Currency)
+	}
+	if req.AmountMicros%microsPerCent != 0 {
+		return Transfer{}, fmt.Errorf("sepa: amount %d micros is not a whole number of cents", req.AmountMicros)
+	}
+	if req.IdempotencyKey == "" {
+		return Transfer{}, errors.New("sepa: idempotency key is required")
+	}
+	iban := domain.NormalizeAccountIdentifier(req.CreditorIBAN)
+	if err := domain.ValidateIBAN(iban); err != nil {
+		return Transfer{}, fmt.Errorf("sepa: creditor %w", err)
+	}
+
+	transfer := Transfer{
+		EndToEndID:     EndToEndID(req.IdempotencyKey),
+		InstructionID:  strings.ReplaceAll(req.PayoutID, "-", ""),
+		AmountMicros:   req.AmountMicros,
+		CreditorName:   req.CreditorName,
+		CreditorIBAN:   iban,
+		CreditorBIC:    domain.NormalizeAccountIdentifier(req.CreditorBIC),
+		RemittanceInfo: "Payout " + req.PayoutID,
+	}
+	if err := BuildDocument("CHECK", g.now(), g.debtor, []Transfer{transfer}).Validate(); err != nil {
+		return Transfer{}, fmt.Errorf("sepa: payout %s fails pain.001 validation: %w", req.PayoutID, err)
+	}
+	return transfer, nil
+}
+
+// flush writes b when its batch window closes, unless it already filled up.
+func (g *Gateway) flush(b *batch) {
+	g.mu.Lock()
+	sealed := g.current == b
+	if sealed {
+		g.current = nil
+	}
+	g.mu.Unlock()
+	if sealed {
+		g.write(b)
+	}
+}
+
+// write delivers a sealed batch. The file is archived as pending before it
+// is handed to the sink, and marked sent after, so a crash in between is
+// finished by the next reindex rather than forgotten.
+func (g *Gateway) write(b *batch) {
+	msgID, err := newMessageID(g.now())
+	if err == nil {
+		err = g.deliver(msgID, b.transfers)
+	}
+
+	g.mu.Lock()
+	ids := make([]string, 0, len(b.transfers))
+	for _, t := range b.transfers {
+		delete(g.inflight, t.EndToEndID)
+		ids = append(ids, t.EndToEndID)
+		if err == nil {
+			g.states[t.EndToEndID] = gateway.PayoutStateSubmitted
+		}
+	}
+	if err == nil {
+		g.files[msgID] = ids
+	}
+	g.mu.Unlock()
+
+	if err != nil {
+		zap.L().Error("sepa credit transfer file not delivered", zap.Error(err), zap.Int("transfers", len(ids)))
+	} else {
+		zap.L().Info("sepa credit transfer file delivered", zap.String("msg_id", msgID), zap.Int("transfers", len(ids)))
+	}
+	b.err = err
+	close(b.done)
+}
+
+func (g *Gateway) deliver(msgID string, transfers []Transfer) error {
+	doc := BuildDocument(msgID, g.now(), g.debtor, transfers)
+	if err := doc.Validate(); err != nil {
+		return fmt.Errorf("sepa: file %s fails pain.001 validation: %w", msgID, err)
+	}
+	data, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+	pending := filepath.Join(g.archiveDir, msgID+pendingSuffix)
+	if err := writeFileAtomic(pending, data); err != nil {
+		return fmt.Errorf("sepa: archive file %s: %w", msgID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), putTimeout)
+	defer cancel()
+	if err := g.sink.Put(ctx, msgID+archiveSuffix, data); err != nil {
+		os.Remove(pending)
+		return fmt.Errorf("sepa: deliver file %s: %w", msgID, err)
+	}
+	if err := os.Rename(pending, filepath.Join(g.archiveDir, msgID+archiveSuffix)); err != nil {
+		// Delivered regardless; the next reindex re-puts and renames it.
+		zap.L().Warn("sepa archive rename failed", zap.Error(err), zap.String("msg_id", msgID))
+	}
+	return nil
+}
+
+// reindex loads archived files not yet indexed. Pending files left by a crash
+// that are not this instance's own in-flight writes are delivered again, which
+// the FileSink contract makes harmless if the first delivery got through.
+func (g *Gateway) reindex(ctx context.Context) error {
+	entries, err := os.ReadDir(g.archiveDir)
+	if err != nil {
+		return fmt.Errorf("sepa: read archive: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		var msgID string
+		switch {
+		case strings.HasSuffix(name, pendingSuffix):
+			msgID = strings.TrimSuffix(name, pendingSuffix)
+		case strings.HasSuffix(name, archiveSuffix) && !strings.HasPrefix(name, "."):
+			msgID = strings.TrimSuffix(name, archiveSuffix)
+		default:
+			continue
+		}
+
+		g.mu.Lock()
+		_, indexed := g.files[msgID]
+		g.mu.Unlock()
+		if indexed {
+			continue
+		}
+
+		path := filepath.Join(g.archiveDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // renamed by a concurrent writer; picked up next time
+			}
+			return fmt.Errorf("sepa: read archived file %s: %w", name, err)
+		}
+		var doc Document
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("sepa: parse archived file %s: %w", name, err)
+		}
+		ids := doc.EndToEndIDs()
+
+		if strings.HasSuffix(name, pendingSuffix) {
+			if g.ownsInflight(ids) {
+				continue
+			}
+			putCtx, cancel := context.WithTimeout(ctx, putTimeout)
+			err := g.sink.Put(putCtx, msgID+archiveSuffix, data)
+			cancel()
+			if err != nil {
+				return fmt.Errorf("sepa: redeliver pending file %s: %w", msgID, err)
+			}
+			if err := os.Rename(path, filepath.Join(g.archiveDir, msgID+archiveSuffix)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("sepa: archive redelivered file %s: %w", msgID, err)
+			}
+			zap.L().Warn("redelivered pending sepa file", zap.String("msg_id", msgID), zap.Int("transfers", len(ids)))
+		}
+
+		g.mu.Lock()
+		g.files[msgID] = ids
+		for _, id := range ids {
+			if _, known := g.states[id]; !known {
+				g.states[id] = gateway.PayoutStateSubmitted
+			}
+		}
+		g.mu.Unlock()
+	}
+	return nil
+}
+
+func (g *Gateway) ownsInflight(ids []string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := g.inflight[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newMessageID returns a MsgId that is unique across instances and restarts
+// and fits Max35Text, e.g. "PMC-20260102150405-1a2b3c4d".
+func newMessageID(now time.Time) (string, error) {
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", fmt.Errorf("sepa: generate message id: %w", err)
+	}
+	return "PMC-" + now.UTC().Format("20060102150405") + "-" + hex.EncodeToString(suffix[:]), nil
+}
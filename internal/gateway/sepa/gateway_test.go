@@ -0,0 +1,261 @@
+package sepa
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSink struct{}
+
+func (failingSink) Put(context.Context, string, []byte) error {
+	return errors.New("sftp unavailable")
+}
+
+func newTestGateway(t *testing.T, window time.Duration, maxBatch int) (*Gateway, string, string, string) {
+	t.Helper()
+	root := t.TempDir()
+	outbox := filepath.Join(root, "outbox")
+	archive := filepath.Join(root, "archive")
+	reports := filepath.Join(root, "reports")
+	for _, dir := range []string{outbox, reports} {
+		require.NoError(t, os.MkdirAll(dir, 0o750))
+	}
+	gw, err := New(Config{
+		Debtor:       testDebtor,
+		ArchiveDir:   archive,
+		Sink:         DirSink{Dir: outbox},
+		Reports:      DirSource{Dir: reports},
+		BatchWindow:  window,
+		MaxBatchSize: maxBatch,
+	})
+	require.NoError(t, err)
+	return gw, outbox, archive, reports
+}
+
+func eurPayout(n int) gateway.PayoutRequest {
+	return gateway.PayoutRequest{
+		PayoutID:       fmt.Sprintf("00000000-0000-0000-0000-%012d", n),
+		IdempotencyKey: fmt.Sprintf("payout-%d:1", n),
+		AmountMicros:   int64(n) * 1_000_000,
+		Currency:       "EUR",
+		CreditorName:   fmt.Sprintf("Creditor %d", n),
+		CreditorIBAN:   "NL91 ABNA 0417 1643 00",
+	}
+}
+
+func readOnlyFile(t *testing.T, dir string) Document {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	var doc Document
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.NoError(t, doc.Validate())
+	return doc
+}
+
+func TestSendPayoutBatchesIntoOneFile(t *testing.T) {
+	gw, outbox, _, _ := newTestGateway(t, time.Hour, 3)
+	ctx := context.Background()
+
+	refs := make([]string, 3)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ref, err := gw.SendPayout(ctx, eurPayout(i+1))
+			require.NoError(t, err)
+			refs[i] = ref
+		}(i)
+	}
+	wg.Wait()
+
+	doc := readOnlyFile(t, outbox)
+	require.Equal(t, "3", doc.CstmrCdt.GrpHdr.NbOfTxs)
+	require.Equal(t, "6.00", doc.CstmrCdt.GrpHdr.CtrlSum)
+	require.ElementsMatch(t, refs, doc.EndToEndIDs())
+	require.Equal(t, EndToEndID("payout-1:1"), refs[0])
+
+	// A resend of a delivered key must not produce another transfer.
+	ref, err := gw.SendPayout(ctx, eurPayout(1))
+	require.NoError(t, err)
+	require.Equal(t, refs[0], ref)
+	readOnlyFile(t, outbox)
+
+	status, err := gw.GetPayoutStatus(ctx, "payout-2:1")
+	require.NoError(t, err)
+	require.Equal(t, gateway.PayoutStateSubmitted, status.State)
+	status, err = gw.GetPayoutStatus(ctx, "payout-9:1")
+	require.NoError(t, err)
+	require.Equal(t, gateway.PayoutStateNotFound, status.State)
+}
+
+func TestSendPayoutFlushesAfterWindow(t *testing.T) {
+	gw, outbox, _, _ := newTestGateway(t, 20*time.Millisecond, 100)
+	ref, err := gw.SendPayout(context.Background(), eurPayout(7))
+	require.NoError(t, err)
+	doc := readOnlyFile(t, outbox)
+	require.Equal(t, []string{ref}, doc.EndToEndIDs())
+}
+
+func TestSendPayoutRejectsInvalidPayouts(t *testing.T) {
+	gw, _, _, _ := newTestGateway(t, time.Millisecond, 10)
+	ctx := context.Background()
+
+	usd := eurPayout(1)
+	usd.Currency = "USD"
+	_, err := gw.SendPayout(ctx, usd)
+	require.ErrorContains(t, err, "only EUR")
+
+	subCent := eurPayout(1)
+	subCent.AmountMicros = 1_005_000
+	_, err = gw.SendPayout(ctx, subCent)
+	require.ErrorContains(t, err, "whole number of cents")
+
+	badIBAN := eurPayout(1)
+	badIBAN.CreditorIBAN = "NL92ABNA0417164300"
+	_, err = gw.SendPayout(ctx, badIBAN)
+	require.ErrorContains(t, err, "check digits")
+
+	noName := eurPayout(1)
+	noName.CreditorName = "###"
+	_, err = gw.SendPayout(ctx, noName)
+	require.ErrorContains(t, err, "Cdtr/Nm")
+}
+
+func TestSendPayoutSinkFailureFailsBatch(t *testing.T) {
+	archive := t.TempDir()
+	gw, err := New(Config{Debtor: testDebtor, ArchiveDir: archive, Sink: failingSink{}, BatchWindow: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = gw.SendPayout(context.Background(), eurPayout(1))
+	require.ErrorContains(t, err, "sftp unavailable")
+	entries, err := os.ReadDir(archive)
+	require.NoError(t, err)
+	require.Empty(t, entries, "an undelivered file must not stay in the archive")
+
+	status, err := gw.GetPayoutStatus(context.Background(), "payout-1:1")
+	require.NoError(t, err)
+	require.Equal(t, gateway.PayoutStateNotFound, status.State)
+}
+
+func TestNewRedeliversPendingFilesAndIndexesArchive(t *testing.T) {
+	gw, outbox, archive, _ := newTestGateway(t, time.Millisecond, 10)
+	_, err := gw.SendPayout(context.Background(), eurPayout(1))
+	require.NoError(t, err)
+
+	// Simulate a crash between archiving a file and delivering it.
+	doc := BuildDocument("PMC-20260102150405-deadbeef", time.Now(), testDebtor, []Transfer{{
+		EndToEndID: EndToEndID("payout-2:1"), AmountMicros: 2_000_000, CreditorName: "Creditor 2", CreditorIBAN: "NL91ABNA0417164300",
+	}})
+	data, err := doc.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(archive, "PMC-20260102150405-deadbeef.xml.pending"), data, 0o640))
+
+	restarted, err := New(Config{Debtor: testDebtor, ArchiveDir: archive, Sink: DirSink{Dir: outbox}})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(outbox, "PMC-20260102150405-deadbeef.xml"))
+	require.FileExists(t, filepath.Join(archive, "PMC-20260102150405-deadbeef.xml"))
+
+	for _, key := range []string{"payout-1:1", "payout-2:1"} {
+		status, err := restarted.GetPayoutStatus(context.Background(), key)
+		require.NoError(t, err)
+		require.Equal(t, gateway.PayoutStateSubmitted, status.State, key)
+	}
+}
+
+func TestConsumeReportsSettlesByEndToEndID(t *testing.T) {
+	gw, outbox, _, reports := newTestGateway(t, time.Hour, 3)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := gw.SendPayout(ctx, eurPayout(i))
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	msgID := readOnlyFile(t, outbox).CstmrCdt.GrpHdr.MsgId
+
+	// Payment-level acceptance with one transaction rejected.
+	report := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03">
+  <CstmrPmtStsRpt>
+    <GrpHdr><MsgId>BANK-1</MsgId><CreDtTm>2026-01-02T16:00:00</CreDtTm></GrpHdr>
+    <OrgnlGrpInfAndSts><OrgnlMsgId>%[1]s</OrgnlMsgId><OrgnlMsgNmId>pain.001.001.03</OrgnlMsgNmId><GrpSts>PART</GrpSts></OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>%[1]s</OrgnlPmtInfId>
+      <PmtInfSts>ACSP</PmtInfSts>
+      <TxInfAndSts>
+        <OrgnlEndToEndId>%[2]s</OrgnlEndToEndId>
+        <TxSts>RJCT</TxSts>
+        <StsRsnInf><Rsn><Cd>AC04</Cd></Rsn><AddtlInf>Account closed</AddtlInf></StsRsnInf>
+      </TxInfAndSts>
+    </OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>`, msgID, EndToEndID("payout-2:1"))
+	require.NoError(t, os.WriteFile(filepath.Join(reports, "report-1.xml"), []byte(report), 0o640))
+	require.NoError(t, os.WriteFile(filepath.Join(reports, "report-2.xml"), []byte("<Document/>"), 0o640))
+
+	got := map[string]gateway.Settlement{}
+	handled, err := gw.ConsumeReports(ctx, func(_ context.Context, s gateway.Settlement) error {
+		got[s.Ref] = s
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, handled)
+	require.Equal(t, gateway.PayoutStateSent, got[EndToEndID("payout-1:1")].State)
+	require.Equal(t, gateway.PayoutStateSent, got[EndToEndID("payout-3:1")].State)
+	rejected := got[EndToEndID("payout-2:1")]
+	require.Equal(t, gateway.PayoutStateFailed, rejected.State)
+	require.Equal(t, "pain.002 RJCT AC04: Account closed", rejected.Reason)
+
+	require.FileExists(t, filepath.Join(reports, "processed", "report-1.xml"))
+	require.FileExists(t, filepath.Join(reports, "rejected", "report-2.xml"))
+	require.FileExists(t, filepath.Join(reports, "rejected", "report-2.xml.error"))
+
+	status, err := gw.GetPayoutStatus(ctx, "payout-2:1")
+	require.NoError(t, err)
+	require.Equal(t, gateway.PayoutStateFailed, status.State)
+}
+
+func TestConsumeReportsLeavesReportOnHandlerError(t *testing.T) {
+	gw, outbox, _, reports := newTestGateway(t, time.Millisecond, 10)
+	ctx := context.Background()
+	_, err := gw.SendPayout(ctx, eurPayout(1))
+	require.NoError(t, err)
+	msgID := readOnlyFile(t, outbox).CstmrCdt.GrpHdr.MsgId
+
+	report := fmt.Sprintf(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"><CstmrPmtStsRpt>
+<GrpHdr><MsgId>BANK-2</MsgId></GrpHdr>
+<OrgnlGrpInfAndSts><OrgnlMsgId>%s</OrgnlMsgId><GrpSts>ACSC</GrpSts></OrgnlGrpInfAndSts>
+</CstmrPmtStsRpt></Document>`, msgID)
+	require.NoError(t, os.WriteFile(filepath.Join(reports, "report.xml"), []byte(report), 0o640))
+
+	_, err = gw.ConsumeReports(ctx, func(context.Context, gateway.Settlement) error {
+		return errors.New("database down")
+	})
+	require.ErrorContains(t, err, "database down")
+	require.FileExists(t, filepath.Join(reports, "report.xml"))
+
+	handled, err := gw.ConsumeReports(ctx, func(context.Context, gateway.Settlement) error { return nil })
+	require.NoError(t, err)
+	require.Equal(t, 1, handled)
+	require.NoFileExists(t, filepath.Join(reports, "report.xml"))
+}
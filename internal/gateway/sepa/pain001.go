@@ -0,0 +1,374 @@
+package sepa
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pain001Namespace is the XML namespace of pain.001.001.03 credit transfer initiations.
+const Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Document is a pain.001.001.03 customer credit transfer initiation. Only the
+// elements this gateway emits are modelled.
+type Document struct {
+	XMLName  xml.Name                   `xml:"Document"`
+	Xmlns    string                     `xml:"xmlns,attr"`
+	CstmrCdt customerCreditTransferInit `xml:"CstmrCdtTrfInitn"`
+}
+
+type customerCreditTransferInit struct {
+	GrpHdr groupHeader          `xml:"GrpHdr"`
+	PmtInf []paymentInstruction `xml:"PmtInf"`
+}
+
+type groupHeader struct {
+	MsgId    string `xml:"MsgId"`
+	CreDtTm  string `xml:"CreDtTm"`
+	NbOfTxs  string `xml:"NbOfTxs"`
+	CtrlSum  string `xml:"CtrlSum"`
+	InitgPty party  `xml:"InitgPty"`
+}
+
+type paymentInstruction struct {
+	PmtInfId    string                `xml:"PmtInfId"`
+	PmtMtd      string                `xml:"PmtMtd"`
+	NbOfTxs     string                `xml:"NbOfTxs"`
+	CtrlSum     string                `xml:"CtrlSum"`
+	PmtTpInf    paymentTypeInfo       `xml:"PmtTpInf"`
+	ReqdExctnDt string                `xml:"ReqdExctnDt"`
+	Dbtr        party                 `xml:"Dbtr"`
+	DbtrAcct    cashAccount           `xml:"DbtrAcct"`
+	DbtrAgt     agent                 `xml:"DbtrAgt"`
+	ChrgBr      string                `xml:"ChrgBr"`
+	CdtTrfTxInf []creditTransferTxInf `xml:"CdtTrfTxInf"`
+}
+
+type paymentTypeInfo struct {
+	SvcLvl serviceLevel `xml:"SvcLvl"`
+}
+
+type serviceLevel struct {
+	Cd string `xml:"Cd"`
+}
+
+type party struct {
+	Nm string `xml:"Nm"`
+}
+
+type cashAccount struct {
+	Id accountId `xml:"Id"`
+}
+
+type accountId struct {
+	IBAN string `xml:"IBAN"`
+}
+
+type agent struct {
+	FinInstnId financialInstitution `xml:"FinInstnId"`
+}
+
+type financialInstitution struct {
+	BIC string `xml:"BIC"`
+}
+
+type creditTransferTxInf struct {
+	PmtId    paymentId   `xml:"PmtId"`
+	Amt      amount      `xml:"Amt"`
+	CdtrAgt  *agent      `xml:"CdtrAgt,omitempty"`
+	Cdtr     party       `xml:"Cdtr"`
+	CdtrAcct cashAccount `xml:"CdtrAcct"`
+	RmtInf   *remittance `xml:"RmtInf,omitempty"`
+}
+
+type paymentId struct {
+	InstrId    string `xml:"InstrId,omitempty"`
+	EndToEndId string `xml:"EndToEndId"`
+}
+
+type amount struct {
+	InstdAmt instructedAmount `xml:"InstdAmt"`
+}
+
+type instructedAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type remittance struct {
+	Ustrd string `xml:"Ustrd"`
+}
+
+// Transfer is one credit transfer to be placed in a file.
+type Transfer struct {
+	EndToEndID     string
+	InstructionID  string
+	AmountMicros   int64
+	CreditorName   string
+	CreditorIBAN   string
+	CreditorBIC    string
+	RemittanceInfo string
+}
+
+// Debtor is the account the file debits.
+type Debtor struct {
+	Name string
+	IBAN string
+	BIC  string
+}
+
+const (
+	currencyEUR = "EUR"
+	// microsPerCent converts ledger micros to euro cents; SEPA amounts carry two decimals.
+	microsPerCent = 10_000
+	// maxSEPAAmountCents is the rulebook's 999,999,999.99 ceiling per transfer.
+	maxSEPAAmountCents = 99_999_999_999
+)
+
+// XSD facets from pain.001.001.03 plus the SEPA rulebook's narrower limits.
+var (
+	ibanXSD    = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[a-zA-Z0-9]{1,30}$`)
+	bicXSD     = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+	nbOfTxsXSD = regexp.MustCompile(`^[0-9]{1,15}$`)
+	// sepaIdentifier is the restricted character set for SEPA references,
+	// which must also not start or end with a slash or contain "//".
+	sepaIdentifier = regexp.MustCompile(`^[A-Za-z0-9/\-?:().,'+ ]+$`)
+	isoDate        = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+)
+
+// BuildDocument assembles a single-instruction credit transfer file.
+// MsgId doubles as the PmtInfId so a status report can be traced either way.
+func BuildDocument(msgID string, created time.Time, debtor Debtor, transfers []Transfer) *Document {
+	var total int64
+	txs := make([]creditTransferTxInf, 0, len(transfers))
+	for _, t := range transfers {
+		cents := t.AmountMicros / microsPerCent
+		total += cents
+		tx := creditTransferTxInf{
+			PmtId:    paymentId{InstrId: t.InstructionID, EndToEndId: t.EndToEndID},
+			Amt:      amount{InstdAmt: instructedAmount{Ccy: currencyEUR, Value: formatCents(cents)}},
+			Cdtr:     party{Nm: sanitizeText(t.CreditorName, 70)},
+			CdtrAcct: cashAccount{Id: accountId{IBAN: t.CreditorIBAN}},
+		}
+		if t.CreditorBIC != "" {
+			tx.CdtrAgt = &agent{FinInstnId: financialInstitution{BIC: t.CreditorBIC}}
+		}
+		if info := sanitizeText(t.RemittanceInfo, 140); info != "" {
+			tx.RmtInf = &remittance{Ustrd: info}
+		}
+		txs = append(txs, tx)
+	}
+
+	count := strconv.Itoa(len(txs))
+	sum := formatCents(total)
+	name := sanitizeText(debtor.Name, 70)
+	return &Document{
+		Xmlns: Pain001Namespace,
+		CstmrCdt: customerCreditTransferInit{
+			GrpHdr: groupHeader{
+				MsgId:    msgID,
+				CreDtTm:  created.UTC().Format("2006-01-02T15:04:05"),
+				NbOfTxs:  count,
+				CtrlSum:  sum,
+				InitgPty: party{Nm: name},
+			},
+			PmtInf: []paymentInstruction{{
+				PmtInfId:    msgID,
+				PmtMtd:      "TRF",
+				NbOfTxs:     count,
+				CtrlSum:     sum,
+				PmtTpInf:    paymentTypeInfo{SvcLvl: serviceLevel{Cd: "SEPA"}},
+				ReqdExctnDt: created.UTC().Format("2006-01-02"),
+				Dbtr:        party{Nm: name},
+				DbtrAcct:    cashAccount{Id: accountId{IBAN: debtor.IBAN}},
+				DbtrAgt:     agent{FinInstnId: financialInstitution{BIC: debtor.BIC}},
+				ChrgBr:      "SLEV",
+				CdtTrfTxInf: txs,
+			}},
+		},
+	}
+}
+
+// Validate enforces the pain.001.001.03 schema facets for the elements this
+// gateway emits, plus the SEPA rulebook restrictions. Every violation is
+// reported, not just the first.
+func (d *Document) Validate() error {
+	var errs []error
+	check := func(cond bool, format string, args ...any) {
+		if !cond {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(d.Xmlns == Pain001Namespace, "Document: namespace must be %s", Pain001Namespace)
+	hdr := d.CstmrCdt.GrpHdr
+	checkSEPAIdentifier(check, "GrpHdr/MsgId", hdr.MsgId)
+	_, err := time.Parse("2006-01-02T15:04:05", hdr.CreDtTm)
+	check(err == nil, "GrpHdr/CreDtTm: %q is not an ISODateTime", hdr.CreDtTm)
+	check(nbOfTxsXSD.MatchString(hdr.NbOfTxs), "GrpHdr/NbOfTxs: %q must be 1 to 15 digits", hdr.NbOfTxs)
+	checkText(check, "GrpHdr/InitgPty/Nm", hdr.InitgPty.Nm, 70)
+	check(len(d.CstmrCdt.PmtInf) > 0, "PmtInf: at least one payment instruction is required")
+
+	var groupTxs int
+	var groupCents int64
+	for i, pmt := range d.CstmrCdt.PmtInf {
+		path := fmt.Sprintf("PmtInf[%d]", i)
+		checkSEPAIdentifier(check, path+"/PmtInfId", pmt.PmtInfId)
+		check(pmt.PmtMtd == "TRF", "%s/PmtMtd: must be TRF", path)
+		check(pmt.PmtTpInf.SvcLvl.Cd == "SEPA", "%s/PmtTpInf/SvcLvl/Cd: must be SEPA", path)
+		check(isoDate.MatchString(pmt.ReqdExctnDt), "%s/ReqdExctnDt: %q is not an ISODate", path, pmt.ReqdExctnDt)
+		checkText(check, path+"/Dbtr/Nm", pmt.Dbtr.Nm, 70)
+		check(ibanXSD.MatchString(pmt.DbtrAcct.Id.IBAN), "%s/DbtrAcct/Id/IBAN: %q does not match the IBAN2007Identifier pattern", path, pmt.DbtrAcct.Id.IBAN)
+		check(bicXSD.MatchString(pmt.DbtrAgt.FinInstnId.BIC), "%s/DbtrAgt/FinInstnId/BIC: %q does not match the BICIdentifier pattern", path, pmt.DbtrAgt.FinInstnId.BIC)
+		check(pmt.ChrgBr == "SLEV", "%s/ChrgBr: must be SLEV", path)
+		check(len(pmt.CdtTrfTxInf) > 0, "%s/CdtTrfTxInf: at least one transaction is required", path)
+
+		var cents int64
+		seen := make(map[string]struct{}, len(pmt.CdtTrfTxInf))
+		for j, tx := range pmt.CdtTrfTxInf {
+			txPath := fmt.Sprintf("%s/CdtTrfTxInf[%d]", path, j)
+			if tx.PmtId.InstrId != "" {
+				checkSEPAIdentifier(check, txPath+"/PmtId/InstrId", tx.PmtId.InstrId)
+			}
+			checkSEPAIdentifier(check, txPath+"/PmtId/EndToEndId", tx.PmtId.EndToEndId)
+			_, dup := seen[tx.PmtId.EndToEndId]
+			check(!dup, "%s/PmtId/EndToEndId: %q is repeated in the file", txPath, tx.PmtId.EndToEndId)
+			seen[tx.PmtId.EndToEndId] = struct{}{}
+
+			check(tx.Amt.InstdAmt.Ccy == currencyEUR, "%s/Amt/InstdAmt/@Ccy: SEPA transfers must be in EUR", txPath)
+			txCents, err := parseCents(tx.Amt.InstdAmt.Value)
+			if err != nil {
+				check(false, "%s/Amt/InstdAmt: %v", txPath, err)
+			} else {
+				check(txCents >= 1 && txCents <= maxSEPAAmountCents, "%s/Amt/InstdAmt: %s is outside 0.01..999999999.99", txPath, tx.Amt.InstdAmt.Value)
+				cents += txCents
+			}
+			if tx.CdtrAgt != nil {
+				check(bicXSD.MatchString(tx.CdtrAgt.FinInstnId.BIC), "%s/CdtrAgt/FinInstnId/BIC: %q does not match the BICIdentifier pattern", txPath, tx.CdtrAgt.FinInstnId.BIC)
+			}
+			checkText(check, txPath+"/Cdtr/Nm", tx.Cdtr.Nm, 70)
+			check(ibanXSD.MatchString(tx.CdtrAcct.Id.IBAN), "%s/CdtrAcct/Id/IBAN: %q does not match the IBAN2007Identifier pattern", txPath, tx.CdtrAcct.Id.IBAN)
+			if tx.RmtInf != nil {
+				checkText(check, txPath+"/RmtInf/Ustrd", tx.RmtInf.Ustrd, 140)
+			}
+		}
+
+		check(pmt.NbOfTxs == strconv.Itoa(len(pmt.CdtTrfTxInf)), "%s/NbOfTxs: %q does not match %d transactions", path, pmt.NbOfTxs, len(pmt.CdtTrfTxInf))
+		checkControlSum(check, path+"/CtrlSum", pmt.CtrlSum, cents)
+		groupTxs += len(pmt.CdtTrfTxInf)
+		groupCents += cents
+	}
+
+	check(hdr.NbOfTxs == strconv.Itoa(groupTxs), "GrpHdr/NbOfTxs: %q does not match %d transactions", hdr.NbOfTxs, groupTxs)
+	checkControlSum(check, "GrpHdr/CtrlSum", hdr.CtrlSum, groupCents)
+	return errors.Join(errs...)
+}
+
+// Marshal renders the document with an XML declaration.
+func (d *Document) Marshal() ([]byte, error) {
+	body, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal pain.001: %w", err)
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// EndToEndIDs lists every transaction's end-to-end ID in file order.
+func (d *Document) EndToEndIDs() []string {
+	var ids []string
+	for _, pmt := range d.CstmrCdt.PmtInf {
+		for _, tx := range pmt.CdtTrfTxInf {
+			ids = append(ids, tx.PmtId.EndToEndId)
+		}
+	}
+	return ids
+}
+
+func checkSEPAIdentifier(check func(bool, string, ...any), path, v string) {
+	check(v != "" && len(v) <= 35, "%s: must be 1 to 35 characters (Max35Text)", path)
+	check(sepaIdentifier.MatchString(v) && !strings.HasPrefix(v, "/") && !strings.HasSuffix(v, "/") && !strings.Contains(v, "//"),
+		"%s: %q contains characters outside the SEPA character set", path, v)
+}
+
+func checkText(check func(bool, string, ...any), path, v string, max int) {
+	check(v != "" && len(v) <= max, "%s: must be 1 to %d characters", path, max)
+	check(v == sanitizeText(v, max), "%s: %q contains characters outside the SEPA character set", path, v)
+}
+
+// checkControlSum checks a control sum is a SEPA amount (two decimals, at
+// most 18 digits) equal to the transactions it covers.
+func checkControlSum(check func(bool, string, ...any), path, v string, wantCents int64) {
+	got, err := parseCents(v)
+	if err != nil {
+		check(false, "%s: %v", path, err)
+		return
+	}
+	check(got == wantCents, "%s: %s does not match the transaction total %s", path, v, formatCents(wantCents))
+}
+
+// formatCents renders cents as a two-decimal amount.
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// parseCents parses a non-negative amount with at most two decimals, the
+// SEPA restriction of the schema's five-fraction-digit amount type.
+func parseCents(v string) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(v, ".")
+	if whole == "" || (hasFrac && (frac == "" || len(frac) > 2)) {
+		return 0, fmt.Errorf("%q must have at most two decimals", v)
+	}
+	if len(whole)+len(frac) > 18 {
+		return 0, fmt.Errorf("%q exceeds 18 total digits", v)
+	}
+	for _, c := range whole + frac {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%q is not a decimal amount", v)
+		}
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	cents, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is out of range", v)
+	}
+	return cents, nil
+}
+
+// sanitizeText maps free text onto the SEPA Latin character set: common
+// accented letters lose their accent, anything else becomes a space, and the
+// result is trimmed to max characters.
+func sanitizeText(v string, max int) string {
+	var b strings.Builder
+	for _, r := range v {
+		if plain, ok := accentFolds[r]; ok {
+			r = plain
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("/-?:().,'+ ", r):
+		default:
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+	out := strings.Join(strings.Fields(b.String()), " ")
+	if len(out) > max {
+		out = strings.TrimSpace(out[:max])
+	}
+	return out
+}
+
+var accentFolds = map[rune]rune{
+	'À': 'A', 'Á': 'A', 'Â': 'A', 'Ã': 'A', 'Ä': 'A', 'Å': 'A', 'Ç': 'C', 'È': 'E', 'É': 'E', 'Ê': 'E', 'Ë': 'E',
+	'Ì': 'I', 'Í': 'I', 'Î': 'I', 'Ï': 'I', 'Ñ': 'N', 'Ò': 'O', 'Ó': 'O', 'Ô': 'O', 'Õ': 'O', 'Ö': 'O', 'Ø': 'O',
+	'Ù': 'U', 'Ú': 'U', 'Û': 'U', 'Ü': 'U', 'Ý': 'Y', 'ß': 's',
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ç': 'c', 'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ñ': 'n', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ý': 'y', 'ÿ': 'y',
+}
@@ -0,0 +1,71 @@
+package sepa
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testDebtor = Debtor{Name: "Payment Multicurrency Ltd", IBAN: "DE89370400440532013000", BIC: "COBADEFFXXX"}
+
+func TestBuildDocumentValidatesAndRoundTrips(t *testing.T) {
+	created := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	doc := BuildDocument("PMC-20260102150405-1a2b3c4d", created, testDebtor, []Transfer{
+		{EndToEndID: "e2e-one", AmountMicros: 12_340_000, CreditorName: "Zoë Müller", CreditorIBAN: "FR1420041010050500013M02606", CreditorBIC: "PSSTFRPPXXX", RemittanceInfo: "Payout 1"},
+		{EndToEndID: "e2e-two", AmountMicros: 50_000, CreditorName: "Acme & Co", CreditorIBAN: "NL91ABNA0417164300"},
+	})
+	require.NoError(t, doc.Validate())
+
+	hdr := doc.CstmrCdt.GrpHdr
+	require.Equal(t, "2", hdr.NbOfTxs)
+	require.Equal(t, "12.39", hdr.CtrlSum)
+	require.Equal(t, "2026-01-02T15:04:05", hdr.CreDtTm)
+	txs := doc.CstmrCdt.PmtInf[0].CdtTrfTxInf
+	require.Equal(t, "Zoe Muller", txs[0].Cdtr.Nm)
+	require.Equal(t, "Acme Co", txs[1].Cdtr.Nm)
+	require.Equal(t, "0.05", txs[1].Amt.InstdAmt.Value)
+	require.Nil(t, txs[1].CdtrAgt)
+
+	data, err := doc.Marshal()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "<?xml"))
+	require.Contains(t, string(data), `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">`)
+	require.Contains(t, string(data), `<InstdAmt Ccy="EUR">12.34</InstdAmt>`)
+
+	var parsed Document
+	require.NoError(t, xml.Unmarshal(data, &parsed))
+	require.NoError(t, parsed.Validate())
+	require.Equal(t, []string{"e2e-one", "e2e-two"}, parsed.EndToEndIDs())
+}
+
+func TestDocumentValidateReportsSchemaViolations(t *testing.T) {
+	created := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	doc := BuildDocument(strings.Repeat("M", 36), created, testDebtor, []Transfer{
+		{EndToEndID: "dup", AmountMicros: 1_000_000, CreditorName: "A", CreditorIBAN: "not-an-iban"},
+		{EndToEndID: "dup", AmountMicros: 1_000_000, CreditorName: "B", CreditorIBAN: "NL91ABNA0417164300", CreditorBIC: "BAD"},
+	})
+	doc.CstmrCdt.GrpHdr.CtrlSum = "3.00"
+	doc.CstmrCdt.PmtInf[0].CdtTrfTxInf[1].Amt.InstdAmt.Value = "1.001"
+
+	err := doc.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"GrpHdr/MsgId: must be 1 to 35 characters",
+		"CdtTrfTxInf[0]/CdtrAcct/Id/IBAN",
+		"CdtTrfTxInf[1]/PmtId/EndToEndId: \"dup\" is repeated",
+		"CdtTrfTxInf[1]/CdtrAgt/FinInstnId/BIC",
+		"CdtTrfTxInf[1]/Amt/InstdAmt: \"1.001\" must have at most two decimals",
+		"GrpHdr/CtrlSum: 3.00 does not match the transaction total 1.00",
+	} {
+		require.Contains(t, err.Error(), want)
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	require.Equal(t, "Francois O'Brien", sanitizeText("  François\tO'Brien ", 70))
+	require.Equal(t, "Payout 42", sanitizeText("Payout #42", 140))
+	require.Equal(t, "abc", sanitizeText("abcdef", 3))
+}
@@ -0,0 +1,154 @@
+package sepa
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+)
+
+// statusReport is the subset of a pain.002.001.03 customer payment status
+// report needed to settle transfers. Tags carry no namespace so reports from
+// banks that declare a different pain.002 version still parse.
+type statusReport struct {
+	XMLName xml.Name `xml:"Document"`
+	Report  struct {
+		GrpHdr struct {
+			MsgId string `xml:"MsgId"`
+		} `xml:"GrpHdr"`
+		OrgnlGrpInfAndSts struct {
+			OrgnlMsgId string         `xml:"OrgnlMsgId"`
+			GrpSts     string         `xml:"GrpSts"`
+			StsRsnInf  []statusReason `xml:"StsRsnInf"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		OrgnlPmtInfAndSts []struct {
+			OrgnlPmtInfId string         `xml:"OrgnlPmtInfId"`
+			PmtInfSts     string         `xml:"PmtInfSts"`
+			StsRsnInf     []statusReason `xml:"StsRsnInf"`
+			TxInfAndSts   []struct {
+				OrgnlEndToEndId string         `xml:"OrgnlEndToEndId"`
+				TxSts           string         `xml:"TxSts"`
+				StsRsnInf       []statusReason `xml:"StsRsnInf"`
+			} `xml:"TxInfAndSts"`
+		} `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type statusReason struct {
+	Rsn struct {
+		Cd    string `xml:"Cd"`
+		Prtry string `xml:"Prtry"`
+	} `xml:"Rsn"`
+	AddtlInf []string `xml:"AddtlInf"`
+}
+
+// reportStatusState maps ISO 20022 transaction status codes onto gateway
+// states. Acceptance for settlement (ACSP) is treated as final, as most banks
+// never follow it with ACSC. Technical acceptance and pending codes return
+// false: they do not settle anything.
+func reportStatusState(code string) (gateway.PayoutState, bool) {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "ACSC", "ACSP", "ACWC", "ACCC":
+		return gateway.PayoutStateSent, true
+	case "RJCT":
+		return gateway.PayoutStateFailed, true
+	default:
+		return "", false
+	}
+}
+
+// parseStatusReport resolves a pain.002 report into one settlement per
+// affected end-to-end ID. Group and payment-information statuses apply to
+// every transfer of the original file unless a transaction-level status
+// overrides them. originalIDs returns the end-to-end IDs a MsgId or
+// PmtInfId covered, and false if the file is unknown.
+func parseStatusReport(data []byte, originalIDs func(id string) ([]string, bool)) (string, []gateway.Settlement, error) {
+	var report statusReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return "", nil, fmt.Errorf("parse pain.002: %w", err)
+	}
+	if report.XMLName.Local != "Document" || !strings.HasPrefix(report.XMLName.Space, "urn:iso:std:iso:20022:tech:xsd:pain.002") {
+		return "", nil, errors.New("parse pain.002: document is not a pain.002 status report")
+	}
+	grp := report.Report.OrgnlGrpInfAndSts
+	if grp.OrgnlMsgId == "" {
+		return "", nil, errors.New("parse pain.002: OrgnlGrpInfAndSts/OrgnlMsgId is required")
+	}
+
+	outcomes := make(map[string]gateway.Settlement)
+	var order []string
+	set := func(e2e, code string, reasons []statusReason) {
+		state, final := reportStatusState(code)
+		if !final {
+			return
+		}
+		if _, seen := outcomes[e2e]; !seen {
+			order = append(order, e2e)
+		}
+		outcomes[e2e] = gateway.Settlement{Ref: e2e, State: state, Reason: formatStatusReason(code, reasons)}
+	}
+	applyToAll := func(id, code string, reasons []statusReason) error {
+		if _, final := reportStatusState(code); !final {
+			return nil
+		}
+		ids, ok := originalIDs(id)
+		if !ok {
+			return fmt.Errorf("parse pain.002: report covers unknown file %q", id)
+		}
+		for _, e2e := range ids {
+			set(e2e, code, reasons)
+		}
+		return nil
+	}
+
+	if err := applyToAll(grp.OrgnlMsgId, grp.GrpSts, grp.StsRsnInf); err != nil {
+		return "", nil, err
+	}
+	for _, pmt := range report.Report.OrgnlPmtInfAndSts {
+		if pmt.OrgnlPmtInfId != "" {
+			if err := applyToAll(pmt.OrgnlPmtInfId, pmt.PmtInfSts, pmt.StsRsnInf); err != nil {
+				return "", nil, err
+			}
+		}
+		for _, tx := range pmt.TxInfAndSts {
+			if tx.OrgnlEndToEndId == "" {
+				return "", nil, errors.New("parse pain.002: TxInfAndSts/OrgnlEndToEndId is required")
+			}
+			set(tx.OrgnlEndToEndId, tx.TxSts, tx.StsRsnInf)
+		}
+	}
+
+	settlements := make([]gateway.Settlement, 0, len(order))
+	for _, e2e := range order {
+		settlements = append(settlements, outcomes[e2e])
+	}
+	return report.Report.GrpHdr.MsgId, settlements, nil
+}
+
+// formatStatusReason renders rejection reasons such as
+// "pain.002 RJCT AC04: account closed". Accepted transfers carry no reason.
+func formatStatusReason(code string, reasons []statusReason) string {
+	if strings.ToUpper(strings.TrimSpace(code)) != "RJCT" {
+		return ""
+	}
+	parts := []string{"RJCT"}
+	for _, r := range reasons {
+		reason := r.Rsn.Cd
+		if reason == "" {
+			reason = r.Rsn.Prtry
+		}
+		if info := strings.TrimSpace(strings.Join(r.AddtlInf, " ")); info != "" {
+			if reason == "" {
+				reason = info
+			} else {
+				reason += ": " + info
+			}
+		}
+		if reason != "" {
+			parts = append(parts, reason)
+		}
+	}
+	return "pain.002 " + strings.Join(parts, " ")
+}
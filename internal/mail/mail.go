@@ -0,0 +1,70 @@
+// Package mail sends transactional email. Until a provider is integrated,
+// messages go to a local sink: files in a directory, or the log.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Message is one plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FileSender writes each message as an .eml file in a directory, where a
+// developer or a test harness can read it.
+type FileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender creates the directory if needed and returns a FileSender
+// writing messages from from into it.
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create mail sink directory: %w", err)
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+// Send implements Sender.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), uuid.NewString())
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+	return nil
+}
+
+// LogSender logs that a message was sent without its body, which may carry
+// secrets such as reset tokens. It is the default when no sink is configured.
+type LogSender struct{}
+
+// Send implements Sender.
+func (LogSender) Send(_ context.Context, msg Message) error {
+	zap.L().Info("mail sent to log sink", zap.String("to", msg.To), zap.String("subject", msg.Subject))
+	return nil
+}
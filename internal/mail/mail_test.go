@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSenderWritesMessage(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewFileSender(dir, "no-reply@payments.local")
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(context.Background(), Message{To: "ayo@example.com", Subject: "Reset your password", Body: "token: abc"}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "To: ayo@example.com\r\n")
+	require.Contains(t, string(raw), "Subject: Reset your password\r\n")
+	require.Contains(t, string(raw), "\r\n\r\ntoken: abc")
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -19,15 +20,55 @@ type User struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Role      string    `json:"role"`
+	KYCStatus string    `json:"kyc_status"`
+	KYCTier   int16     `json:"kyc_tier"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// APIKey is a credential a service uses in place of its owner's login. The
+// secret itself is shown once, when the key is issued.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RotatedFrom *uuid.UUID `json:"rotated_from,omitempty"`
+}
+
+// Principal is who an authenticated request acts as: a user with their
+// role's permissions, narrowed to a key's scopes when an API key was used.
+type Principal struct {
+	UserID      uuid.UUID
+	Role        string
+	Permissions []string
+	APIKey      *APIKey
+}
+
+// Role is a named group of permissions.
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
 type Account struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Currency  string    `json:"currency"`
-	Balance   int64     `json:"balance"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Currency     string    `json:"currency"`
+	Balance      int64     `json:"balance"`
+	Status       string    `json:"status"`
+	StatusReason string    `json:"status_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// OverdraftLimit is how far below zero the balance may go.
+	OverdraftLimit int64 `json:"overdraft_limit_micros"`
+	// OverdraftInterestBps is the annual interest rate, in basis points,
+	// charged daily on a negative balance.
+	OverdraftInterestBps int32 `json:"overdraft_interest_bps"`
 }
 
 type Transaction struct {
@@ -52,13 +93,164 @@ type Entry struct {
 }
 
 type Payout struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	AccountID     uuid.UUID  `json:"account_id"`
+	AmountMicros  int64      `json:"amount_micros"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"`
+	GatewayRef    *string    `json:"gateway_ref,omitempty"`
+	Attempts      int32      `json:"attempts"`
+	BeneficiaryID *uuid.UUID `json:"beneficiary_id,omitempty"`
+	RequestedBy   *uuid.UUID `json:"requested_by,omitempty"`
+	ReviewedBy    *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Beneficiary is a saved payout destination owned by a user.
+type Beneficiary struct {
 	ID            uuid.UUID `json:"id"`
-	TransactionID uuid.UUID `json:"transaction_id"`
-	AccountID     uuid.UUID `json:"account_id"`
-	AmountMicros  int64     `json:"amount_micros"`
-	Currency      string    `json:"currency"`
-	Status        string    `json:"status"`
-	GatewayRef    *string   `json:"gateway_ref,omitempty"`
+	UserID        uuid.UUID `json:"user_id"`
+	Name          string    `json:"name"`
+	Scheme        string    `json:"scheme"`
+	IBAN          string    `json:"iban,omitempty"`
+	BIC           string    `json:"bic,omitempty"`
+	RoutingNumber string    `json:"routing_number,omitempty"`
+	AccountNumber string    `json:"account_number,omitempty"`
+	SortCode      string    `json:"sort_code,omitempty"`
+	AvailableAt   time.Time `json:"available_at"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
+
+// SettlementFile is an imported bank or gateway settlement file.
+type SettlementFile struct {
+	ID           uuid.UUID  `json:"id"`
+	FileName     string     `json:"file_name"`
+	Format       string     `json:"format"`
+	SHA256       string     `json:"sha256"`
+	PeriodStart  *time.Time `json:"period_start,omitempty"`
+	PeriodEnd    *time.Time `json:"period_end,omitempty"`
+	LineCount    int32      `json:"line_count"`
+	MatchedCount int32      `json:"matched_count"`
+	BreakCount   int32      `json:"break_count"`
+	ImportedBy   *uuid.UUID `json:"imported_by,omitempty"`
+	ImportedAt   time.Time  `json:"imported_at"`
+}
+
+// ReconciliationBreak is a discrepancy between the ledger and a settlement
+// file that an operator has to review.
+type ReconciliationBreak struct {
+	ID             uuid.UUID  `json:"id"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	PayoutID       *uuid.UUID `json:"payout_id,omitempty"`
+	LineID         *uuid.UUID `json:"line_id,omitempty"`
+	FileID         *uuid.UUID `json:"file_id,omitempty"`
+	GatewayRef     string     `json:"gateway_ref,omitempty"`
+	Currency       string     `json:"currency,omitempty"`
+	ExpectedMicros *int64     `json:"expected_micros,omitempty"`
+	ActualMicros   *int64     `json:"actual_micros,omitempty"`
+	Details        string     `json:"details"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ClearedBy      *uuid.UUID `json:"cleared_by,omitempty"`
+	ClearedAt      *time.Time `json:"cleared_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+}
+
+// ReconciliationRun records one execution of the reconciliation checks.
+type ReconciliationRun struct {
+	ID             uuid.UUID       `json:"id"`
+	Trigger        string          `json:"trigger"`
+	Scope          string          `json:"scope"`
+	Status         string          `json:"status"`
+	TriggeredBy    *uuid.UUID      `json:"triggered_by,omitempty"`
+	StartedAt      time.Time       `json:"started_at"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+	CurrencyTotals []CurrencyTotal `json:"currency_totals"`
+	Findings       []LedgerFinding `json:"findings"`
+	FindingCount   int32           `json:"finding_count"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// CurrencyTotal sums the ledger entries booked in one currency.
+type CurrencyTotal struct {
+	Currency     string `json:"currency"`
+	EntryCount   int64  `json:"entry_count"`
+	DebitMicros  int64  `json:"debit_micros"`
+	CreditMicros int64  `json:"credit_micros"`
+	NetMicros    int64  `json:"net_micros"`
+}
+
+// LedgerFinding is a ledger invariant violation. SubjectID is the account or
+// transaction the check failed for; Period is the month (YYYY-MM) of a
+// changed sealed period.
+type LedgerFinding struct {
+	Type           string    `json:"type"`
+	SubjectID      uuid.UUID `json:"subject_id"`
+	Currency       string    `json:"currency"`
+	Period         string    `json:"period,omitempty"`
+	ExpectedMicros int64     `json:"expected_micros"`
+	ActualMicros   int64     `json:"actual_micros"`
+}
+
+// AuditChainVerification is the outcome of walking the audit_log hash chain.
+type AuditChainVerification struct {
+	Valid          bool              `json:"valid"`
+	FromSeq        int64             `json:"from_seq"`
+	LastSeq        int64             `json:"last_seq"`
+	LastHash       string            `json:"last_hash,omitempty"`
+	RecordsChecked int64             `json:"records_checked"`
+	AnchorsChecked int               `json:"anchors_checked"`
+	BreakCount     int               `json:"break_count"`
+	Breaks         []AuditChainBreak `json:"breaks"`
+	VerifiedAt     time.Time         `json:"verified_at"`
+}
+
+// AuditChainBreak is one point where the audit chain does not verify.
+// Anchor breaks name the anchor source: the database or an exported file.
+type AuditChainBreak struct {
+	ChainSeq int64  `json:"chain_seq"`
+	AuditID  int64  `json:"audit_id,omitempty"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// AuditAnchor records the chain head at a point in time. Anchors are
+// exported outside the database so a rewritten chain can be detected.
+type AuditAnchor struct {
+	ChainSeq   int64     `json:"chain_seq"`
+	RecordHash string    `json:"record_hash"`
+	AnchoredAt time.Time `json:"anchored_at"`
+}
+
+// AuditLogEntry is one audit_log record as shown to admins.
+type AuditLogEntry struct {
+	ID         int64           `json:"id"`
+	ChainSeq   int64           `json:"chain_seq"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	Action     string          `json:"action"`
+	PrevState  string          `json:"prev_state,omitempty"`
+	NextState  string          `json:"next_state,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	RecordHash string          `json:"record_hash"`
+}
+
+// AuditTrailEvent is an audit record as shown to the customer it concerns.
+// Actor identities and internal metadata are left out; ActorType is
+// "customer", "staff" or "system".
+type AuditTrailEvent struct {
+	EntityType string    `json:"entity_type"`
+	Action     string    `json:"action"`
+	PrevState  string    `json:"prev_state,omitempty"`
+	NextState  string    `json:"next_state,omitempty"`
+	ActorType  string    `json:"actor_type"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -16,6 +16,25 @@ var (
 	manualReviewQueueGauge prometheus.Gauge
 	manualReviewCounter    *prometheus.CounterVec
 	workerRunCounter       *prometheus.CounterVec
+	staleRecoveryCounter   *prometheus.CounterVec
+	outboxPublishCounter   *prometheus.CounterVec
+	outboxPendingGauge     prometheus.Gauge
+	dbListenerReconnects   *prometheus.CounterVec
+	payoutApprovalCounter  *prometheus.CounterVec
+	settlementBreakCounter *prometheus.CounterVec
+	ledgerBreakCounter     *prometheus.CounterVec
+	partitionOpCounter     *prometheus.CounterVec
+	defaultRowsCounter     prometheus.Counter
+	auditChainBreakCounter *prometheus.CounterVec
+	auditAnchorCounter     prometheus.Counter
+	overdraftInterest      *prometheus.CounterVec
+	limitBreachCounter     *prometheus.CounterVec
+	limitFallbackCounter   prometheus.Counter
+	screeningHitCounter    *prometheus.CounterVec
+	screeningDecisions     *prometheus.CounterVec
+	amlAlertCounter        *prometheus.CounterVec
+	loginAttemptCounter    *prometheus.CounterVec
+	sessionRevokedCounter  *prometheus.CounterVec
 )
 
 // Init registers all Prometheus collectors.
@@ -52,6 +71,101 @@ func Init() {
 			Help: "Background worker run outcomes",
 		}, []string{"worker", "result"})
 
+		staleRecoveryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payout_stale_recoveries_total",
+			Help: "Stale PROCESSING payouts resolved after a gateway status lookup",
+		}, []string{"outcome"})
+
+		outboxPublishCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbox_publish_total",
+			Help: "Outbox relay publish attempts per sink",
+		}, []string{"sink", "result"})
+
+		outboxPendingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_pending_events",
+			Help: "Outbox events not yet published to every sink",
+		})
+
+		dbListenerReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_listener_reconnects_total",
+			Help: "LISTEN connection drops followed by a reconnect attempt",
+		}, []string{"channel"})
+
+		payoutApprovalCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "payout_approval_decisions_total",
+			Help: "Maker-checker decisions on payouts awaiting approval",
+		}, []string{"decision"})
+
+		settlementBreakCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "settlement_breaks_total",
+			Help: "Settlement reconciliation breaks raised, by break type",
+		}, []string{"type"})
+
+		ledgerBreakCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ledger_reconciliation_breaks_total",
+			Help: "Ledger invariant violations found by reconciliation runs, by check",
+		}, []string{"type"})
+
+		partitionOpCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "entries_partition_operations_total",
+			Help: "Entries partitions created or archived by partition maintenance",
+		}, []string{"operation"})
+
+		defaultRowsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "entries_default_rows_moved_total",
+			Help: "Rows moved out of entries_default into their month's partition",
+		})
+
+		auditChainBreakCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "audit_chain_breaks_total",
+			Help: "Audit hash chain breaks found by verification",
+		}, []string{"reason"})
+
+		auditAnchorCounter = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "audit_chain_anchors_total",
+			Help: "Audit chain anchors recorded",
+		})
+
+		overdraftInterest = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "overdraft_interest_charged_micros_total",
+			Help: "Overdraft interest charged to accounts, in micros",
+		}, []string{"currency"})
+
+		limitBreachCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "transaction_limit_breaches_total",
+			Help: "Movements refused by transaction limits",
+		}, []string{"type", "window"})
+
+		limitFallbackCounter = prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "transaction_limit_cache_fallbacks_total",
+			Help: "Limit lookups served by Postgres because the Redis cache was unavailable",
+		})
+
+		screeningHitCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sanctions_screening_hits_total",
+			Help: "Names matched against a sanctions list, by what was screened and list source",
+		}, []string{"subject", "source"})
+
+		screeningDecisions = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sanctions_screening_decisions_total",
+			Help: "Review decisions on payouts held by sanctions screening",
+		}, []string{"decision"})
+
+		amlAlertCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "aml_alerts_total",
+			Help: "Alerts raised by transaction monitoring, by rule and severity",
+		}, []string{"rule", "severity"})
+
+		loginAttemptCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_login_attempts_total",
+			Help: "Password logins by result: success, invalid or locked",
+		}, []string{"result"})
+
+		sessionRevokedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_sessions_revoked_total",
+			Help: "Sessions ended before expiry by reason",
+		}, []string{"reason"})
+
 		prometheus.MustRegister(
 			httpDurationHistogram,
 			ledgerImbalanceCounter,
@@ -59,6 +173,25 @@ func Init() {
 			manualReviewQueueGauge,
 			manualReviewCounter,
 			workerRunCounter,
+			staleRecoveryCounter,
+			outboxPublishCounter,
+			outboxPendingGauge,
+			dbListenerReconnects,
+			payoutApprovalCounter,
+			settlementBreakCounter,
+			ledgerBreakCounter,
+			partitionOpCounter,
+			defaultRowsCounter,
+			auditChainBreakCounter,
+			auditAnchorCounter,
+			overdraftInterest,
+			limitBreachCounter,
+			limitFallbackCounter,
+			screeningHitCounter,
+			screeningDecisions,
+			amlAlertCounter,
+			loginAttemptCounter,
+			sessionRevokedCounter,
 		)
 	})
 }
@@ -104,3 +237,136 @@ func IncrementWorkerRun(worker, result string) {
 	}
 	workerRunCounter.WithLabelValues(worker, result).Inc()
 }
+
+func IncrementPayoutStaleRecovery(outcome string) {
+	if staleRecoveryCounter == nil {
+		return
+	}
+	staleRecoveryCounter.WithLabelValues(outcome).Inc()
+}
+
+func IncrementOutboxPublish(sink, result string) {
+	if outboxPublishCounter == nil {
+		return
+	}
+	outboxPublishCounter.WithLabelValues(sink, result).Inc()
+}
+
+func SetOutboxPending(count int64) {
+	if outboxPendingGauge == nil {
+		return
+	}
+	outboxPendingGauge.Set(float64(count))
+}
+
+func IncrementDBListenerReconnect(channel string) {
+	if dbListenerReconnects == nil {
+		return
+	}
+	dbListenerReconnects.WithLabelValues(channel).Inc()
+}
+
+func IncrementPayoutApprovalDecision(decision string) {
+	if payoutApprovalCounter == nil {
+		return
+	}
+	payoutApprovalCounter.WithLabelValues(decision).Inc()
+}
+
+func IncrementSettlementBreak(breakType string) {
+	if settlementBreakCounter == nil {
+		return
+	}
+	settlementBreakCounter.WithLabelValues(breakType).Inc()
+}
+
+func IncrementLedgerBreak(findingType string) {
+	if ledgerBreakCounter == nil {
+		return
+	}
+	ledgerBreakCounter.WithLabelValues(findingType).Inc()
+}
+
+func IncrementPartitionOperation(operation string) {
+	if partitionOpCounter == nil {
+		return
+	}
+	partitionOpCounter.WithLabelValues(operation).Inc()
+}
+
+func AddDefaultPartitionRowsMoved(rows int64) {
+	if defaultRowsCounter == nil {
+		return
+	}
+	defaultRowsCounter.Add(float64(rows))
+}
+
+func IncrementAuditChainBreak(reason string) {
+	if auditChainBreakCounter == nil {
+		return
+	}
+	auditChainBreakCounter.WithLabelValues(reason).Inc()
+}
+
+func IncrementAuditAnchor() {
+	if auditAnchorCounter == nil {
+		return
+	}
+	auditAnchorCounter.Inc()
+}
+
+func AddOverdraftInterest(currency string, micros int64) {
+	if overdraftInterest == nil {
+		return
+	}
+	overdraftInterest.WithLabelValues(currency).Add(float64(micros))
+}
+
+func IncrementLimitBreach(txnType, window string) {
+	if limitBreachCounter == nil {
+		return
+	}
+	limitBreachCounter.WithLabelValues(txnType, window).Inc()
+}
+
+func IncrementLimitCacheFallback() {
+	if limitFallbackCounter == nil {
+		return
+	}
+	limitFallbackCounter.Inc()
+}
+
+func IncrementScreeningHit(subject, source string) {
+	if screeningHitCounter == nil {
+		return
+	}
+	screeningHitCounter.WithLabelValues(subject, source).Inc()
+}
+
+func IncrementScreeningDecision(decision string) {
+	if screeningDecisions == nil {
+		return
+	}
+	screeningDecisions.WithLabelValues(decision).Inc()
+}
+
+func IncrementAMLAlert(rule, severity string) {
+	if amlAlertCounter == nil {
+		return
+	}
+	amlAlertCounter.WithLabelValues(rule, severity).Inc()
+}
+
+func IncrementLoginAttempt(result string) {
+	if loginAttemptCounter == nil {
+		return
+	}
+	loginAttemptCounter.WithLabelValues(result).Inc()
+}
+
+func IncrementSessionsRevoked(reason string, n int) {
+	if sessionRevokedCounter == nil {
+		return
+	}
+	sessionRevokedCounter.WithLabelValues(reason).Add(float64(n))
+}
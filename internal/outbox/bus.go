@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Bus is an in-process sink that fans events out to local subscribers.
+// Delivery is best-effort: a full subscriber buffer drops the event, so
+// consumers must keep a fallback sweep for anything they miss.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]chan Event
+}
+
+// NewBus creates an empty in-process bus.
+func NewBus() *Bus {
+	return &Bus{subs: make(map[string][]chan Event)}
+}
+
+// Name implements Sink.
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Subscribe returns a buffered channel receiving events of the given types.
+func (b *Bus) Subscribe(buffer int, eventTypes ...string) <-chan Event {
+	if buffer < 1 {
+		buffer = 1
+	}
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, eventType := range eventTypes {
+		b.subs[eventType] = append(b.subs[eventType], ch)
+	}
+	return ch
+}
+
+// Publish implements Sink. It never blocks on slow subscribers.
+func (b *Bus) Publish(ctx context.Context, evt Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs[evt.Type] {
+		select {
+		case ch <- evt:
+		default:
+			zap.L().Warn("outbox bus subscriber full; event dropped", zap.String("event_id", evt.ID.String()), zap.String("event_type", evt.Type))
+		}
+	}
+	return nil
+}
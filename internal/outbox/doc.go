@@ -0,0 +1,5 @@
+// Package outbox defines domain events and the sinks the outbox relay publishes them to.
+//
+// The sinks are an in-process bus, a Redis stream and outbound webhooks. The
+// Redis stream is the only broker sink; there is no NATS sink.
+package outbox
@@ -0,0 +1,57 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types written to the outbox.
+const (
+	EventPayoutAwaitingApproval = "payout.awaiting_approval"
+	EventPayoutRequested        = "payout.requested"
+	EventPayoutRejected         = "payout.rejected"
+	EventPayoutScreeningHold    = "payout.screening_hold"
+	EventPayoutRequeued         = "payout.requeued"
+	EventPayoutSubmitted        = "payout.submitted"
+	EventPayoutCompleted        = "payout.completed"
+	EventPayoutFailed           = "payout.failed"
+	EventTransferCompleted      = "transfer.completed"
+	EventExchangeCompleted      = "exchange.completed"
+	EventDepositCompleted       = "deposit.completed"
+	EventAccountStatusChanged   = "account.status_changed"
+	EventOpeningBalancePosted   = "opening_balance.posted"
+	EventOverdraftUpdated       = "account.overdraft_updated"
+	EventOverdraftInterest      = "overdraft.interest_charged"
+	EventUserKYCUpdated         = "user.kyc_updated"
+	EventAMLAlertRaised         = "aml.alert_raised"
+	EventAMLCaseUpdated         = "aml.case_updated"
+)
+
+// Aggregate types events are keyed by.
+const (
+	AggregateAccount     = "account"
+	AggregatePayout      = "payout"
+	AggregateTransaction = "transaction"
+	AggregateUser        = "user"
+)
+
+// Event is a committed domain event as delivered to sinks.
+// ID is stable across redeliveries and is the consumer-side dedupe key.
+type Event struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// Sink receives events from the relay.
+// Name must be stable across restarts: it keys the per-sink delivery ledger.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, evt Event) error
+}
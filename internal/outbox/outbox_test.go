@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(eventType string) Event {
+	return Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: AggregatePayout,
+		AggregateID:   uuid.New(),
+		Payload:       json.RawMessage(`{"amount_micros":100}`),
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestBusDeliversOnlySubscribedTypes(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe(4, EventPayoutRequested)
+
+	requested := testEvent(EventPayoutRequested)
+	require.NoError(t, bus.Publish(context.Background(), requested))
+	require.NoError(t, bus.Publish(context.Background(), testEvent(EventTransferCompleted)))
+
+	select {
+	case got := <-ch:
+		require.Equal(t, requested.ID, got.ID)
+	default:
+		t.Fatal("expected subscribed event")
+	}
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected event %s", got.Type)
+	default:
+	}
+}
+
+func TestBusDropsWhenSubscriberFull(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe(1, EventPayoutRequested)
+
+	require.NoError(t, bus.Publish(context.Background(), testEvent(EventPayoutRequested)))
+	require.NoError(t, bus.Publish(context.Background(), testEvent(EventPayoutRequested)))
+	require.Len(t, ch, 1)
+}
+
+func TestWebhookSinkSignsBody(t *testing.T) {
+	secret := "outbox-secret"
+	evt := testEvent(EventPayoutCompleted)
+
+	var gotSig, gotID string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Webhook-Signature")
+		gotID = r.Header.Get("X-Event-ID")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, secret, time.Second)
+	require.NoError(t, sink.Publish(context.Background(), evt))
+	require.Equal(t, evt.ID.String(), gotID)
+	require.Equal(t, Sign([]byte(secret), gotBody), gotSig)
+
+	var decoded Event
+	require.NoError(t, json.Unmarshal(gotBody, &decoded))
+	require.Equal(t, evt.ID, decoded.ID)
+	require.Equal(t, evt.Type, decoded.Type)
+}
+
+func TestWebhookSinkRejectsNon2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, "secret", time.Second)
+	require.Error(t, sink.Publish(context.Background(), testEvent(EventPayoutFailed)))
+}
@@ -0,0 +1,49 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamSink appends events to a Redis stream with XADD.
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink creates a sink writing to stream, trimmed to roughly maxLen entries.
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+// Name implements Sink.
+func (s *RedisStreamSink) Name() string {
+	return "redis_stream:" + s.stream
+}
+
+// Publish implements Sink.
+func (s *RedisStreamSink) Publish(ctx context.Context, evt Event) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{
+			"event_id":       evt.ID.String(),
+			"event_type":     evt.Type,
+			"aggregate_type": evt.AggregateType,
+			"aggregate_id":   evt.AggregateID.String(),
+			"payload":        string(evt.Payload),
+			"created_at":     evt.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("xadd %s: %w", s.stream, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package password hashes user passwords with argon2id and checks them
+// against the password policy.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id parameters for new hashes (the OWASP minimum of 19 MiB, two
+// passes, one lane). Verify reads the parameters from each stored hash, so
+// raising them only affects passwords set afterwards.
+const (
+	argonMemoryKiB = 19 * 1024
+	argonTime      = 2
+	argonThreads   = 1
+	argonKeyLen    = 32
+	argonSaltLen   = 16
+)
+
+// Policy bounds.
+const (
+	MinLength = 12
+	MaxLength = 128
+	// minClasses is how many of lower case, upper case, digits and symbols
+	// a password must mix.
+	minClasses = 3
+)
+
+var (
+	// ErrWeak indicates a password that does not meet the policy.
+	ErrWeak = errors.New("password does not meet policy")
+	// ErrMalformedHash indicates a stored hash that is not an argon2id PHC string.
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// Hash returns the argon2id hash of plain in PHC string format.
+func Hash(plain string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(plain), salt, argonTime, argonMemoryKiB, argonThreads, argonKeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemoryKiB, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether plain matches encoded, a hash returned by Hash.
+func Verify(plain, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return false, ErrMalformedHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrMalformedHash
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil || memory == 0 || iterations == 0 || threads == 0 {
+		return false, ErrMalformedHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false, ErrMalformedHash
+	}
+	got := argon2.IDKey([]byte(plain), salt, iterations, memory, threads, uint32(len(want)))
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// Validate checks plain against the policy: MinLength to MaxLength
+// characters, at least three kinds of character, and not containing any of
+// identifiers (such as the username or the local part of the email).
+func Validate(plain string, identifiers ...string) error {
+	length := utf8.RuneCountInString(plain)
+	if length < MinLength || length > MaxLength {
+		return fmt.Errorf("%w: must be %d to %d characters", ErrWeak, MinLength, MaxLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < minClasses {
+		return fmt.Errorf("%w: must mix at least %d of lower case, upper case, digits and symbols", ErrWeak, minClasses)
+	}
+
+	folded := strings.ToLower(plain)
+	for _, id := range identifiers {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if len(id) >= 3 && strings.Contains(folded, id) {
+			return fmt.Errorf("%w: must not contain your username or email", ErrWeak)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := Hash("Correct-Horse-42")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	other, err := Hash("Correct-Horse-42")
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other, "each hash has its own salt")
+
+	ok, err := Verify("Correct-Horse-42", hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = Verify("correct-horse-42", hash)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	for _, malformed := range []string{"", "plaintext", "$argon2i$v=19$m=19456,t=2,p=1$c2FsdA$a2V5", "$argon2id$v=16$m=19456,t=2,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=0,t=2,p=1$c2FsdA$a2V5"} {
+		_, err := Verify("Correct-Horse-42", malformed)
+		require.ErrorIs(t, err, ErrMalformedHash, malformed)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate("Correct-Horse-42", "ayo", "ayo@example.com"))
+	require.NoError(t, Validate("correct horse battery 42!"))
+
+	cases := map[string]string{
+		"too short":         "Sh0rt-pass",
+		"too long":          strings.Repeat("Aa1-", 33),
+		"two classes":       "alllowercase42",
+		"contains username": "Ayo-Is-Great-42",
+	}
+	for name, plain := range cases {
+		require.ErrorIs(t, Validate(plain, "ayo"), ErrWeak, name)
+	}
+}
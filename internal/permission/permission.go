@@ -0,0 +1,81 @@
+// Package permission names what an authenticated caller may do. Roles in
+// the role_permissions table grant these; access tokens carry them as their
+// scope and API keys are limited to a subset of them.
+package permission
+
+// Permissions for acting on the caller's own resources. The ":any" forms
+// extend the same action to every user's resources.
+const (
+	AccountsCreate        = "accounts:create"
+	AccountsCreateAny     = "accounts:create:any"
+	AccountsRead          = "accounts:read"
+	AccountsReadAny       = "accounts:read:any"
+	TransfersCreate       = "transfers:create"
+	TransfersCreateAny    = "transfers:create:any"
+	BeneficiariesRead     = "beneficiaries:read"
+	BeneficiariesReadAny  = "beneficiaries:read:any"
+	BeneficiariesWrite    = "beneficiaries:write"
+	BeneficiariesWriteAny = "beneficiaries:write:any"
+	PayoutsRead           = "payouts:read"
+	PayoutsReadAny        = "payouts:read:any"
+	APIKeysManage         = "api-keys:manage"
+)
+
+// Staff permissions.
+const (
+	AccountsLifecycle    = "accounts:lifecycle"
+	AccountsOverdraft    = "accounts:overdraft"
+	LedgerOpeningBalance = "ledger:opening-balance"
+	PayoutsCreate        = "payouts:create"
+	PayoutsResolve       = "payouts:resolve"
+	PayoutsApprove       = "payouts:approve"
+	PayoutsScreening     = "payouts:screening"
+	ReconciliationRead   = "reconciliation:read"
+	ReconciliationWrite  = "reconciliation:write"
+	LimitsRead           = "limits:read"
+	LimitsWrite          = "limits:write"
+	KYCRead              = "kyc:read"
+	KYCWrite             = "kyc:write"
+	AMLRead              = "aml:read"
+	AMLWrite             = "aml:write"
+	AuditRead            = "audit:read"
+	RolesManage          = "roles:manage"
+)
+
+var known = map[string]bool{
+	AccountsCreate:        true,
+	AccountsCreateAny:     true,
+	AccountsRead:          true,
+	AccountsReadAny:       true,
+	TransfersCreate:       true,
+	TransfersCreateAny:    true,
+	BeneficiariesRead:     true,
+	BeneficiariesReadAny:  true,
+	BeneficiariesWrite:    true,
+	BeneficiariesWriteAny: true,
+	PayoutsRead:           true,
+	PayoutsReadAny:        true,
+	APIKeysManage:         true,
+	AccountsLifecycle:     true,
+	AccountsOverdraft:     true,
+	LedgerOpeningBalance:  true,
+	PayoutsCreate:         true,
+	PayoutsResolve:        true,
+	PayoutsApprove:        true,
+	PayoutsScreening:      true,
+	ReconciliationRead:    true,
+	ReconciliationWrite:   true,
+	LimitsRead:            true,
+	LimitsWrite:           true,
+	KYCRead:               true,
+	KYCWrite:              true,
+	AMLRead:               true,
+	AMLWrite:              true,
+	AuditRead:             true,
+	RolesManage:           true,
+}
+
+// Known reports whether name is a permission the API checks.
+func Known(name string) bool {
+	return known[name]
+}
@@ -0,0 +1,25 @@
+package permission
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The permissions table must list exactly the permissions the API checks,
+// or a role could be granted a name no route looks for.
+func TestKnownPermissionsAreSeeded(t *testing.T) {
+	migration, err := os.ReadFile("../../db/migrations/000035_add_roles_and_permissions.up.sql")
+	require.NoError(t, err)
+
+	insert := regexp.MustCompile(`(?s)INSERT INTO permissions \(name, description\) VALUES(.*?)ON CONFLICT`).FindSubmatch(migration)
+	require.NotNil(t, insert)
+	seeded := map[string]bool{}
+	for _, m := range regexp.MustCompile(`(?m)^\s*\('([a-z:-]+)', '`).FindAllSubmatch(insert[1], -1) {
+		seeded[string(m[1])] = true
+	}
+	require.Equal(t, known, seeded)
+	require.False(t, Known("admin"))
+}
@@ -14,7 +14,7 @@ import (
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (id, user_id, currency, balance, created_at) 
 VALUES ($1, $2, $3, $4, NOW()) 
-RETURNING created_at
+RETURNING created_at, status
 `
 
 type CreateAccountParams struct {
@@ -24,22 +24,27 @@ type CreateAccountParams struct {
 	Balance  int64       `db:"balance" json:"balance"`
 }
 
-func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (pgtype.Timestamptz, error) {
+type CreateAccountRow struct {
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Status    string             `db:"status" json:"status"`
+}
+
+func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (CreateAccountRow, error) {
 	row := q.db.QueryRow(ctx, createAccount,
 		arg.ID,
 		arg.UserID,
 		arg.Currency,
 		arg.Balance,
 	)
-	var created_at pgtype.Timestamptz
-	err := row.Scan(&created_at)
-	return created_at, err
+	var i CreateAccountRow
+	err := row.Scan(&i.CreatedAt, &i.Status)
+	return i, err
 }
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (id, username, email, role, created_at) 
 VALUES ($1, $2, $3, $4, NOW()) 
-RETURNING created_at
+RETURNING created_at, kyc_status, kyc_tier
 `
 
 type CreateUserParams struct {
@@ -49,16 +54,22 @@ type CreateUserParams struct {
 	Role     string      `db:"role" json:"role"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (pgtype.Timestamptz, error) {
+type CreateUserRow struct {
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	KycStatus string             `db:"kyc_status" json:"kyc_status"`
+	KycTier   int16              `db:"kyc_tier" json:"kyc_tier"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
 	row := q.db.QueryRow(ctx, createUser,
 		arg.ID,
 		arg.Username,
 		arg.Email,
 		arg.Role,
 	)
-	var created_at pgtype.Timestamptz
-	err := row.Scan(&created_at)
-	return created_at, err
+	var i CreateUserRow
+	err := row.Scan(&i.CreatedAt, &i.KycStatus, &i.KycTier)
+	return i, err
 }
 
 const deductLockedFunds = `-- name: DeductLockedFunds :execrows
@@ -81,17 +92,21 @@ func (q *Queries) DeductLockedFunds(ctx context.Context, arg DeductLockedFundsPa
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, user_id, currency, balance, created_at 
+SELECT id, user_id, currency, balance, status, status_reason, overdraft_limit_micros, overdraft_interest_bps, created_at
 FROM accounts 
 WHERE id = $1
 `
 
 type GetAccountRow struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
-	Currency  string             `db:"currency" json:"currency"`
-	Balance   int64              `db:"balance" json:"balance"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID                   pgtype.UUID        `db:"id" json:"id"`
+	UserID               pgtype.UUID        `db:"user_id" json:"user_id"`
+	Currency             string             `db:"currency" json:"currency"`
+	Balance              int64              `db:"balance" json:"balance"`
+	Status               string             `db:"status" json:"status"`
+	StatusReason         *string            `db:"status_reason" json:"status_reason"`
+	OverdraftLimitMicros int64              `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	OverdraftInterestBps int32              `db:"overdraft_interest_bps" json:"overdraft_interest_bps"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
 func (q *Queries) GetAccount(ctx context.Context, id pgtype.UUID) (GetAccountRow, error) {
@@ -102,28 +117,62 @@ func (q *Queries) GetAccount(ctx context.Context, id pgtype.UUID) (GetAccountRow
 		&i.UserID,
 		&i.Currency,
 		&i.Balance,
+		&i.Status,
+		&i.StatusReason,
+		&i.OverdraftLimitMicros,
+		&i.OverdraftInterestBps,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
 const getAccountBalanceAndLocked = `-- name: GetAccountBalanceAndLocked :one
-SELECT balance, locked_micros, currency FROM accounts WHERE id = $1 FOR UPDATE
+SELECT balance, locked_micros, currency, status, overdraft_limit_micros, overdraft_interest_bps FROM accounts WHERE id = $1 FOR UPDATE
 `
 
 type GetAccountBalanceAndLockedRow struct {
-	Balance      int64  `db:"balance" json:"balance"`
-	LockedMicros int64  `db:"locked_micros" json:"locked_micros"`
-	Currency     string `db:"currency" json:"currency"`
+	Balance              int64  `db:"balance" json:"balance"`
+	LockedMicros         int64  `db:"locked_micros" json:"locked_micros"`
+	Currency             string `db:"currency" json:"currency"`
+	Status               string `db:"status" json:"status"`
+	OverdraftLimitMicros int64  `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	OverdraftInterestBps int32  `db:"overdraft_interest_bps" json:"overdraft_interest_bps"`
 }
 
 func (q *Queries) GetAccountBalanceAndLocked(ctx context.Context, id pgtype.UUID) (GetAccountBalanceAndLockedRow, error) {
 	row := q.db.QueryRow(ctx, getAccountBalanceAndLocked, id)
 	var i GetAccountBalanceAndLockedRow
-	err := row.Scan(&i.Balance, &i.LockedMicros, &i.Currency)
+	err := row.Scan(
+		&i.Balance,
+		&i.LockedMicros,
+		&i.Currency,
+		&i.Status,
+		&i.OverdraftLimitMicros,
+		&i.OverdraftInterestBps,
+	)
 	return i, err
 }
 
+const getAccountNetEntriesSince = `-- name: GetAccountNetEntriesSince :one
+SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)::bigint
+FROM entries
+WHERE account_id = $1 AND created_at >= $2
+`
+
+type GetAccountNetEntriesSinceParams struct {
+	AccountID pgtype.UUID        `db:"account_id" json:"account_id"`
+	Since     pgtype.Timestamptz `db:"since" json:"since"`
+}
+
+// Net of the account's entries created at or after since, credits positive.
+// The balance at since is the current balance less this.
+func (q *Queries) GetAccountNetEntriesSince(ctx context.Context, arg GetAccountNetEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getAccountNetEntriesSince, arg.AccountID, arg.Since)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const getEntries = `-- name: GetEntries :many
 SELECT id, transaction_id, account_id, amount, direction, created_at
 FROM entries
@@ -165,25 +214,105 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 	return items, nil
 }
 
+const getLastOverdraftInterestReference = `-- name: GetLastOverdraftInterestReference :one
+SELECT t.reference_id
+FROM entries e
+INNER JOIN transactions t ON t.id = e.transaction_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND t.type = 'fee'
+  AND t.reference_id LIKE $3::text || '%'
+ORDER BY t.reference_id DESC
+LIMIT 1
+`
+
+type GetLastOverdraftInterestReferenceParams struct {
+	AccountID       pgtype.UUID        `db:"account_id" json:"account_id"`
+	Since           pgtype.Timestamptz `db:"since" json:"since"`
+	ReferencePrefix string             `db:"reference_prefix" json:"reference_prefix"`
+}
+
+// The reference of the account's latest overdraft interest charge posted
+// since since. References end in the ISO date charged, so they sort by day.
+func (q *Queries) GetLastOverdraftInterestReference(ctx context.Context, arg GetLastOverdraftInterestReferenceParams) (string, error) {
+	row := q.db.QueryRow(ctx, getLastOverdraftInterestReference, arg.AccountID, arg.Since, arg.ReferencePrefix)
+	var reference_id string
+	err := row.Scan(&reference_id)
+	return reference_id, err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, username, email, role, created_at 
+SELECT id, username, email, role, created_at, kyc_status, kyc_tier 
 FROM users 
 WHERE id = $1
 `
 
-func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
+type GetUserRow struct {
+	ID        pgtype.UUID        `db:"id" json:"id"`
+	Username  string             `db:"username" json:"username"`
+	Email     string             `db:"email" json:"email"`
+	Role      string             `db:"role" json:"role"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	KycStatus string             `db:"kyc_status" json:"kyc_status"`
+	KycTier   int16              `db:"kyc_tier" json:"kyc_tier"`
+}
+
+func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (GetUserRow, error) {
 	row := q.db.QueryRow(ctx, getUser, id)
-	var i User
+	var i GetUserRow
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
 		&i.Email,
 		&i.Role,
 		&i.CreatedAt,
+		&i.KycStatus,
+		&i.KycTier,
 	)
 	return i, err
 }
 
+const listOverdrawnAccounts = `-- name: ListOverdrawnAccounts :many
+SELECT a.id
+FROM accounts a
+WHERE a.overdraft_interest_bps > 0
+  AND (a.balance < 0 OR EXISTS (
+    SELECT 1 FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= $1
+  ))
+  AND a.id > $2
+ORDER BY a.id
+LIMIT $3
+`
+
+type ListOverdrawnAccountsParams struct {
+	Since    pgtype.Timestamptz `db:"since" json:"since"`
+	AfterID  pgtype.UUID        `db:"after_id" json:"after_id"`
+	RowLimit int32              `db:"row_limit" json:"row_limit"`
+}
+
+// Accounts with an interest rate that are overdrawn now or have entries
+// since since, so may have been overdrawn when an earlier day ended.
+func (q *Queries) ListOverdrawnAccounts(ctx context.Context, arg ListOverdrawnAccountsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listOverdrawnAccounts, arg.Since, arg.AfterID, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const lockAccountFunds = `-- name: LockAccountFunds :execrows
 UPDATE accounts
 SET locked_micros = locked_micros + $1
@@ -240,3 +369,99 @@ func (q *Queries) ReleaseAccountFundsSafe(ctx context.Context, arg ReleaseAccoun
 	}
 	return result.RowsAffected(), nil
 }
+
+const updateAccountOverdraft = `-- name: UpdateAccountOverdraft :one
+UPDATE accounts
+SET overdraft_limit_micros = $1,
+    overdraft_interest_bps = $2
+WHERE id = $3
+RETURNING id, user_id, currency, balance, status, status_reason, overdraft_limit_micros, overdraft_interest_bps, created_at
+`
+
+type UpdateAccountOverdraftParams struct {
+	OverdraftLimitMicros int64       `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	OverdraftInterestBps int32       `db:"overdraft_interest_bps" json:"overdraft_interest_bps"`
+	ID                   pgtype.UUID `db:"id" json:"id"`
+}
+
+type UpdateAccountOverdraftRow struct {
+	ID                   pgtype.UUID        `db:"id" json:"id"`
+	UserID               pgtype.UUID        `db:"user_id" json:"user_id"`
+	Currency             string             `db:"currency" json:"currency"`
+	Balance              int64              `db:"balance" json:"balance"`
+	Status               string             `db:"status" json:"status"`
+	StatusReason         *string            `db:"status_reason" json:"status_reason"`
+	OverdraftLimitMicros int64              `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	OverdraftInterestBps int32              `db:"overdraft_interest_bps" json:"overdraft_interest_bps"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (UpdateAccountOverdraftRow, error) {
+	row := q.db.QueryRow(ctx, updateAccountOverdraft, arg.OverdraftLimitMicros, arg.OverdraftInterestBps, arg.ID)
+	var i UpdateAccountOverdraftRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Currency,
+		&i.Balance,
+		&i.Status,
+		&i.StatusReason,
+		&i.OverdraftLimitMicros,
+		&i.OverdraftInterestBps,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1,
+    status_reason = $2,
+    status_changed_by = $3,
+    status_changed_at = NOW()
+WHERE id = $4
+RETURNING id, user_id, currency, balance, locked_micros, status, status_reason, status_changed_by, status_changed_at, created_at
+`
+
+type UpdateAccountStatusParams struct {
+	Status          string      `db:"status" json:"status"`
+	StatusReason    *string     `db:"status_reason" json:"status_reason"`
+	StatusChangedBy pgtype.UUID `db:"status_changed_by" json:"status_changed_by"`
+	ID              pgtype.UUID `db:"id" json:"id"`
+}
+
+type UpdateAccountStatusRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Currency        string             `db:"currency" json:"currency"`
+	Balance         int64              `db:"balance" json:"balance"`
+	LockedMicros    int64              `db:"locked_micros" json:"locked_micros"`
+	Status          string             `db:"status" json:"status"`
+	StatusReason    *string            `db:"status_reason" json:"status_reason"`
+	StatusChangedBy pgtype.UUID        `db:"status_changed_by" json:"status_changed_by"`
+	StatusChangedAt pgtype.Timestamptz `db:"status_changed_at" json:"status_changed_at"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (UpdateAccountStatusRow, error) {
+	row := q.db.QueryRow(ctx, updateAccountStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.StatusChangedBy,
+		arg.ID,
+	)
+	var i UpdateAccountStatusRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Currency,
+		&i.Balance,
+		&i.LockedMicros,
+		&i.Status,
+		&i.StatusReason,
+		&i.StatusChangedBy,
+		&i.StatusChangedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
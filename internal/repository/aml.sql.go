@@ -0,0 +1,598 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: aml.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const countAMLAlerts = `-- name: CountAMLAlerts :one
+SELECT COUNT(*)::bigint
+FROM aml_alerts
+WHERE ($1::text IS NULL OR rule_code = $1::text)
+  AND ($2::uuid IS NULL OR case_id = $2::uuid)
+  AND ($3::uuid IS NULL OR user_id = $3::uuid)
+`
+
+type CountAMLAlertsParams struct {
+	RuleCode *string     `db:"rule_code" json:"rule_code"`
+	CaseID   pgtype.UUID `db:"case_id" json:"case_id"`
+	UserID   pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) CountAMLAlerts(ctx context.Context, arg CountAMLAlertsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countAMLAlerts, arg.RuleCode, arg.CaseID, arg.UserID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countAMLCases = `-- name: CountAMLCases :one
+SELECT COUNT(*)::bigint
+FROM aml_cases
+WHERE ($1::text IS NULL OR status = $1::text)
+`
+
+func (q *Queries) CountAMLCases(ctx context.Context, status *string) (int64, error) {
+	row := q.db.QueryRow(ctx, countAMLCases, status)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const countAMLObservationsInBand = `-- name: CountAMLObservationsInBand :one
+SELECT COUNT(*)::bigint AS movements, COALESCE(SUM(amount_micros), 0)::bigint AS total_micros
+FROM aml_observations
+WHERE account_id = $1
+  AND direction = $2
+  AND currency = $3
+  AND occurred_at > $4
+  AND occurred_at <= $5
+  AND amount_micros >= $6
+  AND amount_micros < $7
+`
+
+type CountAMLObservationsInBandParams struct {
+	AccountID       pgtype.UUID        `db:"account_id" json:"account_id"`
+	Direction       string             `db:"direction" json:"direction"`
+	Currency        string             `db:"currency" json:"currency"`
+	WindowStart     pgtype.Timestamptz `db:"window_start" json:"window_start"`
+	WindowEnd       pgtype.Timestamptz `db:"window_end" json:"window_end"`
+	MinAmountMicros int64              `db:"min_amount_micros" json:"min_amount_micros"`
+	MaxAmountMicros int64              `db:"max_amount_micros" json:"max_amount_micros"`
+}
+
+type CountAMLObservationsInBandRow struct {
+	Movements   int64 `db:"movements" json:"movements"`
+	TotalMicros int64 `db:"total_micros" json:"total_micros"`
+}
+
+func (q *Queries) CountAMLObservationsInBand(ctx context.Context, arg CountAMLObservationsInBandParams) (CountAMLObservationsInBandRow, error) {
+	row := q.db.QueryRow(ctx, countAMLObservationsInBand,
+		arg.AccountID,
+		arg.Direction,
+		arg.Currency,
+		arg.WindowStart,
+		arg.WindowEnd,
+		arg.MinAmountMicros,
+		arg.MaxAmountMicros,
+	)
+	var i CountAMLObservationsInBandRow
+	err := row.Scan(&i.Movements, &i.TotalMicros)
+	return i, err
+}
+
+const createAMLAlert = `-- name: CreateAMLAlert :one
+INSERT INTO aml_alerts (id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end, created_at
+`
+
+type CreateAMLAlertParams struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	CaseID      pgtype.UUID        `db:"case_id" json:"case_id"`
+	RuleCode    string             `db:"rule_code" json:"rule_code"`
+	AccountID   pgtype.UUID        `db:"account_id" json:"account_id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	EventID     pgtype.UUID        `db:"event_id" json:"event_id"`
+	Severity    string             `db:"severity" json:"severity"`
+	Details     []byte             `db:"details" json:"details"`
+	WindowStart pgtype.Timestamptz `db:"window_start" json:"window_start"`
+	WindowEnd   pgtype.Timestamptz `db:"window_end" json:"window_end"`
+}
+
+func (q *Queries) CreateAMLAlert(ctx context.Context, arg CreateAMLAlertParams) (AmlAlert, error) {
+	row := q.db.QueryRow(ctx, createAMLAlert,
+		arg.ID,
+		arg.CaseID,
+		arg.RuleCode,
+		arg.AccountID,
+		arg.UserID,
+		arg.EventID,
+		arg.Severity,
+		arg.Details,
+		arg.WindowStart,
+		arg.WindowEnd,
+	)
+	var i AmlAlert
+	err := row.Scan(
+		&i.ID,
+		&i.CaseID,
+		&i.RuleCode,
+		&i.AccountID,
+		&i.UserID,
+		&i.EventID,
+		&i.Severity,
+		&i.Details,
+		&i.WindowStart,
+		&i.WindowEnd,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAMLCase = `-- name: CreateAMLCase :one
+INSERT INTO aml_cases (id, user_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) WHERE status <> 'CLOSED' DO NOTHING
+RETURNING id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+`
+
+type CreateAMLCaseParams struct {
+	ID     pgtype.UUID `db:"id" json:"id"`
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) CreateAMLCase(ctx context.Context, arg CreateAMLCaseParams) (AmlCase, error) {
+	row := q.db.QueryRow(ctx, createAMLCase, arg.ID, arg.UserID)
+	var i AmlCase
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.AssignedTo,
+		&i.Resolution,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const findAMLReverseExchange = `-- name: FindAMLReverseExchange :one
+SELECT transaction_id, amount_micros, occurred_at
+FROM aml_observations
+WHERE user_id = $1
+  AND event_type = 'exchange.completed'
+  AND direction = 'OUT'
+  AND currency = $2
+  AND counter_currency = $3
+  AND amount_micros >= $4
+  AND occurred_at > $5
+  AND occurred_at <= $6
+ORDER BY occurred_at DESC
+LIMIT 1
+`
+
+type FindAMLReverseExchangeParams struct {
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Currency        string             `db:"currency" json:"currency"`
+	CounterCurrency *string            `db:"counter_currency" json:"counter_currency"`
+	MinAmountMicros int64              `db:"min_amount_micros" json:"min_amount_micros"`
+	WindowStart     pgtype.Timestamptz `db:"window_start" json:"window_start"`
+	WindowEnd       pgtype.Timestamptz `db:"window_end" json:"window_end"`
+}
+
+type FindAMLReverseExchangeRow struct {
+	TransactionID pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
+	AmountMicros  int64              `db:"amount_micros" json:"amount_micros"`
+	OccurredAt    pgtype.Timestamptz `db:"occurred_at" json:"occurred_at"`
+}
+
+func (q *Queries) FindAMLReverseExchange(ctx context.Context, arg FindAMLReverseExchangeParams) (FindAMLReverseExchangeRow, error) {
+	row := q.db.QueryRow(ctx, findAMLReverseExchange,
+		arg.UserID,
+		arg.Currency,
+		arg.CounterCurrency,
+		arg.MinAmountMicros,
+		arg.WindowStart,
+		arg.WindowEnd,
+	)
+	var i FindAMLReverseExchangeRow
+	err := row.Scan(&i.TransactionID, &i.AmountMicros, &i.OccurredAt)
+	return i, err
+}
+
+const getAMLCase = `-- name: GetAMLCase :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE id = $1
+`
+
+func (q *Queries) GetAMLCase(ctx context.Context, id pgtype.UUID) (AmlCase, error) {
+	row := q.db.QueryRow(ctx, getAMLCase, id)
+	var i AmlCase
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.AssignedTo,
+		&i.Resolution,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getAMLCaseForUpdate = `-- name: GetAMLCaseForUpdate :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetAMLCaseForUpdate(ctx context.Context, id pgtype.UUID) (AmlCase, error) {
+	row := q.db.QueryRow(ctx, getAMLCaseForUpdate, id)
+	var i AmlCase
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.AssignedTo,
+		&i.Resolution,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getActiveAMLCaseForUser = `-- name: GetActiveAMLCaseForUser :one
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE user_id = $1 AND status <> 'CLOSED'
+`
+
+func (q *Queries) GetActiveAMLCaseForUser(ctx context.Context, userID pgtype.UUID) (AmlCase, error) {
+	row := q.db.QueryRow(ctx, getActiveAMLCaseForUser, userID)
+	var i AmlCase
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.AssignedTo,
+		&i.Resolution,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const hasRecentAMLAlert = `-- name: HasRecentAMLAlert :one
+SELECT EXISTS (
+  SELECT 1 FROM aml_alerts
+  WHERE rule_code = $1 AND account_id = $2 AND window_end > $3
+)
+`
+
+type HasRecentAMLAlertParams struct {
+	RuleCode  string             `db:"rule_code" json:"rule_code"`
+	AccountID pgtype.UUID        `db:"account_id" json:"account_id"`
+	WindowEnd pgtype.Timestamptz `db:"window_end" json:"window_end"`
+}
+
+func (q *Queries) HasRecentAMLAlert(ctx context.Context, arg HasRecentAMLAlertParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasRecentAMLAlert, arg.RuleCode, arg.AccountID, arg.WindowEnd)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const insertAMLObservation = `-- name: InsertAMLObservation :execrows
+INSERT INTO aml_observations (event_id, event_type, transaction_id, account_id, user_id, direction, amount_micros, currency, counter_currency, occurred_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (event_id, account_id, direction) DO NOTHING
+`
+
+type InsertAMLObservationParams struct {
+	EventID         pgtype.UUID        `db:"event_id" json:"event_id"`
+	EventType       string             `db:"event_type" json:"event_type"`
+	TransactionID   pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
+	AccountID       pgtype.UUID        `db:"account_id" json:"account_id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Direction       string             `db:"direction" json:"direction"`
+	AmountMicros    int64              `db:"amount_micros" json:"amount_micros"`
+	Currency        string             `db:"currency" json:"currency"`
+	CounterCurrency *string            `db:"counter_currency" json:"counter_currency"`
+	OccurredAt      pgtype.Timestamptz `db:"occurred_at" json:"occurred_at"`
+}
+
+func (q *Queries) InsertAMLObservation(ctx context.Context, arg InsertAMLObservationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertAMLObservation,
+		arg.EventID,
+		arg.EventType,
+		arg.TransactionID,
+		arg.AccountID,
+		arg.UserID,
+		arg.Direction,
+		arg.AmountMicros,
+		arg.Currency,
+		arg.CounterCurrency,
+		arg.OccurredAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listAMLAlerts = `-- name: ListAMLAlerts :many
+SELECT id, case_id, rule_code, account_id, user_id, event_id, severity, details, window_start, window_end, created_at
+FROM aml_alerts
+WHERE ($1::text IS NULL OR rule_code = $1::text)
+  AND ($2::uuid IS NULL OR case_id = $2::uuid)
+  AND ($3::uuid IS NULL OR user_id = $3::uuid)
+ORDER BY created_at DESC
+LIMIT $5 OFFSET $4
+`
+
+type ListAMLAlertsParams struct {
+	RuleCode    *string     `db:"rule_code" json:"rule_code"`
+	CaseID      pgtype.UUID `db:"case_id" json:"case_id"`
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	OffsetCount int32       `db:"offset_count" json:"offset_count"`
+	LimitCount  int32       `db:"limit_count" json:"limit_count"`
+}
+
+func (q *Queries) ListAMLAlerts(ctx context.Context, arg ListAMLAlertsParams) ([]AmlAlert, error) {
+	rows, err := q.db.Query(ctx, listAMLAlerts,
+		arg.RuleCode,
+		arg.CaseID,
+		arg.UserID,
+		arg.OffsetCount,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AmlAlert
+	for rows.Next() {
+		var i AmlAlert
+		if err := rows.Scan(
+			&i.ID,
+			&i.CaseID,
+			&i.RuleCode,
+			&i.AccountID,
+			&i.UserID,
+			&i.EventID,
+			&i.Severity,
+			&i.Details,
+			&i.WindowStart,
+			&i.WindowEnd,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAMLCases = `-- name: ListAMLCases :many
+SELECT id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+FROM aml_cases
+WHERE ($1::text IS NULL OR status = $1::text)
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $2
+`
+
+type ListAMLCasesParams struct {
+	Status      *string `db:"status" json:"status"`
+	OffsetCount int32   `db:"offset_count" json:"offset_count"`
+	LimitCount  int32   `db:"limit_count" json:"limit_count"`
+}
+
+func (q *Queries) ListAMLCases(ctx context.Context, arg ListAMLCasesParams) ([]AmlCase, error) {
+	rows, err := q.db.Query(ctx, listAMLCases, arg.Status, arg.OffsetCount, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AmlCase
+	for rows.Next() {
+		var i AmlCase
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Status,
+			&i.AssignedTo,
+			&i.Resolution,
+			&i.Note,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAMLRules = `-- name: ListAMLRules :many
+SELECT code, description, enabled, window_seconds, amount_micros, min_count, ratio_bps, severity, updated_by, updated_at
+FROM aml_rules
+ORDER BY code
+`
+
+func (q *Queries) ListAMLRules(ctx context.Context) ([]AmlRule, error) {
+	rows, err := q.db.Query(ctx, listAMLRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AmlRule
+	for rows.Next() {
+		var i AmlRule
+		if err := rows.Scan(
+			&i.Code,
+			&i.Description,
+			&i.Enabled,
+			&i.WindowSeconds,
+			&i.AmountMicros,
+			&i.MinCount,
+			&i.RatioBps,
+			&i.Severity,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumAMLAccountFlows = `-- name: SumAMLAccountFlows :one
+SELECT
+  COALESCE(SUM(amount_micros) FILTER (WHERE direction = 'IN'), 0)::bigint AS in_micros,
+  COALESCE(SUM(amount_micros) FILTER (WHERE direction = 'OUT'), 0)::bigint AS out_micros
+FROM aml_observations
+WHERE account_id = $1
+  AND occurred_at > $2
+  AND occurred_at <= $3
+`
+
+type SumAMLAccountFlowsParams struct {
+	AccountID   pgtype.UUID        `db:"account_id" json:"account_id"`
+	WindowStart pgtype.Timestamptz `db:"window_start" json:"window_start"`
+	WindowEnd   pgtype.Timestamptz `db:"window_end" json:"window_end"`
+}
+
+type SumAMLAccountFlowsRow struct {
+	InMicros  int64 `db:"in_micros" json:"in_micros"`
+	OutMicros int64 `db:"out_micros" json:"out_micros"`
+}
+
+func (q *Queries) SumAMLAccountFlows(ctx context.Context, arg SumAMLAccountFlowsParams) (SumAMLAccountFlowsRow, error) {
+	row := q.db.QueryRow(ctx, sumAMLAccountFlows, arg.AccountID, arg.WindowStart, arg.WindowEnd)
+	var i SumAMLAccountFlowsRow
+	err := row.Scan(&i.InMicros, &i.OutMicros)
+	return i, err
+}
+
+const updateAMLCase = `-- name: UpdateAMLCase :one
+UPDATE aml_cases
+SET status = $2,
+    assigned_to = $3,
+    resolution = $4,
+    note = $5,
+    updated_at = NOW(),
+    closed_at = CASE WHEN $2 = 'CLOSED' THEN NOW() ELSE NULL END
+WHERE id = $1
+RETURNING id, user_id, status, assigned_to, resolution, note, created_at, updated_at, closed_at
+`
+
+type UpdateAMLCaseParams struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	Status     string      `db:"status" json:"status"`
+	AssignedTo pgtype.UUID `db:"assigned_to" json:"assigned_to"`
+	Resolution *string     `db:"resolution" json:"resolution"`
+	Note       *string     `db:"note" json:"note"`
+}
+
+func (q *Queries) UpdateAMLCase(ctx context.Context, arg UpdateAMLCaseParams) (AmlCase, error) {
+	row := q.db.QueryRow(ctx, updateAMLCase,
+		arg.ID,
+		arg.Status,
+		arg.AssignedTo,
+		arg.Resolution,
+		arg.Note,
+	)
+	var i AmlCase
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Status,
+		&i.AssignedTo,
+		&i.Resolution,
+		&i.Note,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const updateAMLRule = `-- name: UpdateAMLRule :one
+UPDATE aml_rules
+SET enabled = $2,
+    window_seconds = $3,
+    amount_micros = $4,
+    min_count = $5,
+    ratio_bps = $6,
+    severity = $7,
+    updated_by = $8,
+    updated_at = NOW()
+WHERE code = $1
+RETURNING code, description, enabled, window_seconds, amount_micros, min_count, ratio_bps, severity, updated_by, updated_at
+`
+
+type UpdateAMLRuleParams struct {
+	Code          string      `db:"code" json:"code"`
+	Enabled       bool        `db:"enabled" json:"enabled"`
+	WindowSeconds int32       `db:"window_seconds" json:"window_seconds"`
+	AmountMicros  int64       `db:"amount_micros" json:"amount_micros"`
+	MinCount      int32       `db:"min_count" json:"min_count"`
+	RatioBps      int32       `db:"ratio_bps" json:"ratio_bps"`
+	Severity      string      `db:"severity" json:"severity"`
+	UpdatedBy     pgtype.UUID `db:"updated_by" json:"updated_by"`
+}
+
+func (q *Queries) UpdateAMLRule(ctx context.Context, arg UpdateAMLRuleParams) (AmlRule, error) {
+	row := q.db.QueryRow(ctx, updateAMLRule,
+		arg.Code,
+		arg.Enabled,
+		arg.WindowSeconds,
+		arg.AmountMicros,
+		arg.MinCount,
+		arg.RatioBps,
+		arg.Severity,
+		arg.UpdatedBy,
+	)
+	var i AmlRule
+	err := row.Scan(
+		&i.Code,
+		&i.Description,
+		&i.Enabled,
+		&i.WindowSeconds,
+		&i.AmountMicros,
+		&i.MinCount,
+		&i.RatioBps,
+		&i.Severity,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,228 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: api_key.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :exec
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, rotated_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type CreateAPIKeyParams struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name        string             `db:"name" json:"name"`
+	Prefix      string             `db:"prefix" json:"prefix"`
+	KeyHash     string             `db:"key_hash" json:"key_hash"`
+	Scopes      []string           `db:"scopes" json:"scopes"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ExpiresAt   pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	RotatedFrom pgtype.UUID        `db:"rotated_from" json:"rotated_from"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) error {
+	_, err := q.db.Exec(ctx, createAPIKey,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scopes,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.RotatedFrom,
+	)
+	return err
+}
+
+const expireAPIKey = `-- name: ExpireAPIKey :exec
+UPDATE api_keys
+SET expires_at = LEAST(COALESCE(expires_at, $1), $1)
+WHERE id = $2
+`
+
+type ExpireAPIKeyParams struct {
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	ID        pgtype.UUID        `db:"id" json:"id"`
+}
+
+// Shortens a rotated key's life to the grace period, never lengthens it.
+func (q *Queries) ExpireAPIKey(ctx context.Context, arg ExpireAPIKeyParams) error {
+	_, err := q.db.Exec(ctx, expireAPIKey, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT k.id, k.user_id, k.name, k.prefix, k.scopes, k.created_at, k.expires_at, k.last_used_at, k.revoked_at, k.rotated_from, u.role,
+       ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role = u.role ORDER BY rp.permission)::text[] AS role_permissions
+FROM api_keys k
+JOIN users u ON u.id = k.user_id
+WHERE k.key_hash = $1
+`
+
+type GetAPIKeyByHashRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name            string             `db:"name" json:"name"`
+	Prefix          string             `db:"prefix" json:"prefix"`
+	Scopes          []string           `db:"scopes" json:"scopes"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	LastUsedAt      pgtype.Timestamptz `db:"last_used_at" json:"last_used_at"`
+	RevokedAt       pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	RotatedFrom     pgtype.UUID        `db:"rotated_from" json:"rotated_from"`
+	Role            string             `db:"role" json:"role"`
+	RolePermissions []string           `db:"role_permissions" json:"role_permissions"`
+}
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (GetAPIKeyByHashRow, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByHash, keyHash)
+	var i GetAPIKeyByHashRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Prefix,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+		&i.Role,
+		&i.RolePermissions,
+	)
+	return i, err
+}
+
+const getActiveAPIKeyForUpdate = `-- name: GetActiveAPIKeyForUpdate :one
+SELECT id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at, rotated_from
+FROM api_keys
+WHERE id = $1
+  AND user_id = $2
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > $3)
+FOR UPDATE
+`
+
+type GetActiveAPIKeyForUpdateParams struct {
+	ID     pgtype.UUID        `db:"id" json:"id"`
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+func (q *Queries) GetActiveAPIKeyForUpdate(ctx context.Context, arg GetActiveAPIKeyForUpdateParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getActiveAPIKeyForUpdate, arg.ID, arg.UserID, arg.Now)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RevokedAt,
+		&i.RotatedFrom,
+	)
+	return i, err
+}
+
+const listActiveAPIKeys = `-- name: ListActiveAPIKeys :many
+SELECT id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at, rotated_from
+FROM api_keys
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > $2)
+ORDER BY created_at DESC
+`
+
+type ListActiveAPIKeysParams struct {
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+func (q *Queries) ListActiveAPIKeys(ctx context.Context, arg ListActiveAPIKeysParams) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, listActiveAPIKeys, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.RotatedFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys
+SET revoked_at = $1
+WHERE id = $2
+  AND user_id = $3
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > $1)
+`
+
+type RevokeAPIKeyParams struct {
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+	ID     pgtype.UUID        `db:"id" json:"id"`
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAPIKey, arg.Now, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = $1
+WHERE id = $2
+  AND (last_used_at IS NULL OR last_used_at < $3)
+`
+
+type TouchAPIKeyParams struct {
+	Now         pgtype.Timestamptz `db:"now" json:"now"`
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	StaleBefore pgtype.Timestamptz `db:"stale_before" json:"stale_before"`
+}
+
+// Only writes when the recorded use is older than stale_before, so a busy
+// key does not update its row on every request.
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.Exec(ctx, touchAPIKey, arg.Now, arg.ID, arg.StaleBefore)
+	return err
+}
@@ -11,6 +11,49 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createAuditAnchor = `-- name: CreateAuditAnchor :one
+INSERT INTO audit_anchors (chain_seq, record_hash, file_path)
+VALUES ($1, $2, $3)
+RETURNING id, chain_seq, record_hash, file_path, created_at
+`
+
+type CreateAuditAnchorParams struct {
+	ChainSeq   int64   `db:"chain_seq" json:"chain_seq"`
+	RecordHash string  `db:"record_hash" json:"record_hash"`
+	FilePath   *string `db:"file_path" json:"file_path"`
+}
+
+func (q *Queries) CreateAuditAnchor(ctx context.Context, arg CreateAuditAnchorParams) (AuditAnchor, error) {
+	row := q.db.QueryRow(ctx, createAuditAnchor, arg.ChainSeq, arg.RecordHash, arg.FilePath)
+	var i AuditAnchor
+	err := row.Scan(
+		&i.ID,
+		&i.ChainSeq,
+		&i.RecordHash,
+		&i.FilePath,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAuditChainHead = `-- name: GetAuditChainHead :one
+SELECT last_seq, last_hash
+FROM audit_chain_head
+WHERE id
+`
+
+type GetAuditChainHeadRow struct {
+	LastSeq  int64  `db:"last_seq" json:"last_seq"`
+	LastHash string `db:"last_hash" json:"last_hash"`
+}
+
+func (q *Queries) GetAuditChainHead(ctx context.Context) (GetAuditChainHeadRow, error) {
+	row := q.db.QueryRow(ctx, getAuditChainHead)
+	var i GetAuditChainHeadRow
+	err := row.Scan(&i.LastSeq, &i.LastHash)
+	return i, err
+}
+
 const getAuditLogsByEntity = `-- name: GetAuditLogsByEntity :many
 SELECT id, entity_type, entity_id, actor_id, action, prev_state, next_state, metadata, created_at
 FROM audit_log
@@ -23,15 +66,27 @@ type GetAuditLogsByEntityParams struct {
 	EntityID   pgtype.UUID `db:"entity_id" json:"entity_id"`
 }
 
-func (q *Queries) GetAuditLogsByEntity(ctx context.Context, arg GetAuditLogsByEntityParams) ([]AuditLog, error) {
+type GetAuditLogsByEntityRow struct {
+	ID         int64              `db:"id" json:"id"`
+	EntityType string             `db:"entity_type" json:"entity_type"`
+	EntityID   pgtype.UUID        `db:"entity_id" json:"entity_id"`
+	ActorID    pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Action     string             `db:"action" json:"action"`
+	PrevState  *string            `db:"prev_state" json:"prev_state"`
+	NextState  *string            `db:"next_state" json:"next_state"`
+	Metadata   []byte             `db:"metadata" json:"metadata"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) GetAuditLogsByEntity(ctx context.Context, arg GetAuditLogsByEntityParams) ([]GetAuditLogsByEntityRow, error) {
 	rows, err := q.db.Query(ctx, getAuditLogsByEntity, arg.EntityType, arg.EntityID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []AuditLog
+	var items []GetAuditLogsByEntityRow
 	for rows.Next() {
-		var i AuditLog
+		var i GetAuditLogsByEntityRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.EntityType,
@@ -53,6 +108,26 @@ func (q *Queries) GetAuditLogsByEntity(ctx context.Context, arg GetAuditLogsByEn
 	return items, nil
 }
 
+const getLatestAuditAnchor = `-- name: GetLatestAuditAnchor :one
+SELECT id, chain_seq, record_hash, file_path, created_at
+FROM audit_anchors
+ORDER BY chain_seq DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestAuditAnchor(ctx context.Context) (AuditAnchor, error) {
+	row := q.db.QueryRow(ctx, getLatestAuditAnchor)
+	var i AuditAnchor
+	err := row.Scan(
+		&i.ID,
+		&i.ChainSeq,
+		&i.RecordHash,
+		&i.FilePath,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const insertAuditLog = `-- name: InsertAuditLog :one
 INSERT INTO audit_log (
   entity_type,
@@ -92,3 +167,255 @@ func (q *Queries) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams)
 	err := row.Scan(&id)
 	return id, err
 }
+
+const isTransactionVisibleToUser = `-- name: IsTransactionVisibleToUser :one
+SELECT EXISTS (
+  SELECT 1
+  FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+  WHERE e.transaction_id = $1 AND a.user_id = $2
+) OR EXISTS (
+  SELECT 1
+  FROM payouts p
+  JOIN accounts a ON a.id = p.account_id
+  WHERE p.transaction_id = $1 AND a.user_id = $2
+) AS visible
+`
+
+type IsTransactionVisibleToUserParams struct {
+	TransactionID pgtype.UUID `db:"transaction_id" json:"transaction_id"`
+	UserID        pgtype.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *Queries) IsTransactionVisibleToUser(ctx context.Context, arg IsTransactionVisibleToUserParams) (*bool, error) {
+	row := q.db.QueryRow(ctx, isTransactionVisibleToUser, arg.TransactionID, arg.UserID)
+	var visible *bool
+	err := row.Scan(&visible)
+	return visible, err
+}
+
+const listAuditAnchors = `-- name: ListAuditAnchors :many
+SELECT id, chain_seq, record_hash, file_path, created_at
+FROM audit_anchors
+ORDER BY chain_seq ASC
+`
+
+func (q *Queries) ListAuditAnchors(ctx context.Context) ([]AuditAnchor, error) {
+	rows, err := q.db.Query(ctx, listAuditAnchors)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditAnchor
+	for rows.Next() {
+		var i AuditAnchor
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChainSeq,
+			&i.RecordHash,
+			&i.FilePath,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditChain = `-- name: ListAuditChain :many
+SELECT chain_seq, id, entity_type, entity_id, actor_id, action, prev_state, next_state, metadata, created_at, prev_hash, record_hash
+FROM audit_log
+WHERE chain_seq > $1 AND chain_seq <= $2
+ORDER BY chain_seq ASC
+LIMIT $3
+`
+
+type ListAuditChainParams struct {
+	AfterSeq int64 `db:"after_seq" json:"after_seq"`
+	UntilSeq int64 `db:"until_seq" json:"until_seq"`
+	RowLimit int32 `db:"row_limit" json:"row_limit"`
+}
+
+type ListAuditChainRow struct {
+	ChainSeq   int64              `db:"chain_seq" json:"chain_seq"`
+	ID         int64              `db:"id" json:"id"`
+	EntityType string             `db:"entity_type" json:"entity_type"`
+	EntityID   pgtype.UUID        `db:"entity_id" json:"entity_id"`
+	ActorID    pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Action     string             `db:"action" json:"action"`
+	PrevState  *string            `db:"prev_state" json:"prev_state"`
+	NextState  *string            `db:"next_state" json:"next_state"`
+	Metadata   []byte             `db:"metadata" json:"metadata"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	PrevHash   string             `db:"prev_hash" json:"prev_hash"`
+	RecordHash string             `db:"record_hash" json:"record_hash"`
+}
+
+func (q *Queries) ListAuditChain(ctx context.Context, arg ListAuditChainParams) ([]ListAuditChainRow, error) {
+	rows, err := q.db.Query(ctx, listAuditChain, arg.AfterSeq, arg.UntilSeq, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditChainRow
+	for rows.Next() {
+		var i ListAuditChainRow
+		if err := rows.Scan(
+			&i.ChainSeq,
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.ActorID,
+			&i.Action,
+			&i.PrevState,
+			&i.NextState,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.PrevHash,
+			&i.RecordHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, chain_seq, entity_type, entity_id, actor_id, action, prev_state, next_state, metadata, created_at, record_hash
+FROM audit_log
+WHERE ($1::text IS NULL OR entity_type = $1::text)
+  AND ($2::uuid IS NULL OR entity_id = $2::uuid)
+  AND ($3::uuid IS NULL OR actor_id = $3::uuid)
+  AND ($4::text IS NULL OR action = $4::text)
+  AND ($5::timestamptz IS NULL OR created_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR created_at < $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR (created_at, id) < ($7::timestamptz, $8::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT $9
+`
+
+type ListAuditLogsParams struct {
+	EntityType  *string            `db:"entity_type" json:"entity_type"`
+	EntityID    pgtype.UUID        `db:"entity_id" json:"entity_id"`
+	ActorID     pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Action      *string            `db:"action" json:"action"`
+	CreatedFrom pgtype.Timestamptz `db:"created_from" json:"created_from"`
+	CreatedTo   pgtype.Timestamptz `db:"created_to" json:"created_to"`
+	CursorAt    pgtype.Timestamptz `db:"cursor_at" json:"cursor_at"`
+	CursorID    *int64             `db:"cursor_id" json:"cursor_id"`
+	RowLimit    int32              `db:"row_limit" json:"row_limit"`
+}
+
+type ListAuditLogsRow struct {
+	ID         int64              `db:"id" json:"id"`
+	ChainSeq   int64              `db:"chain_seq" json:"chain_seq"`
+	EntityType string             `db:"entity_type" json:"entity_type"`
+	EntityID   pgtype.UUID        `db:"entity_id" json:"entity_id"`
+	ActorID    pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Action     string             `db:"action" json:"action"`
+	PrevState  *string            `db:"prev_state" json:"prev_state"`
+	NextState  *string            `db:"next_state" json:"next_state"`
+	Metadata   []byte             `db:"metadata" json:"metadata"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	RecordHash string             `db:"record_hash" json:"record_hash"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]ListAuditLogsRow, error) {
+	rows, err := q.db.Query(ctx, listAuditLogs,
+		arg.EntityType,
+		arg.EntityID,
+		arg.ActorID,
+		arg.Action,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CursorAt,
+		arg.CursorID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAuditLogsRow
+	for rows.Next() {
+		var i ListAuditLogsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChainSeq,
+			&i.EntityType,
+			&i.EntityID,
+			&i.ActorID,
+			&i.Action,
+			&i.PrevState,
+			&i.NextState,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.RecordHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionAuditTrail = `-- name: ListTransactionAuditTrail :many
+SELECT l.id, l.entity_type, l.entity_id, l.actor_id, l.action, l.prev_state, l.next_state, l.created_at
+FROM audit_log l
+WHERE (l.entity_type = 'transaction' AND l.entity_id = $1)
+   OR (l.entity_type = 'payout' AND l.entity_id IN (
+     SELECT p.id FROM payouts p WHERE p.transaction_id = $1
+   ))
+ORDER BY l.created_at ASC, l.id ASC
+`
+
+type ListTransactionAuditTrailRow struct {
+	ID         int64              `db:"id" json:"id"`
+	EntityType string             `db:"entity_type" json:"entity_type"`
+	EntityID   pgtype.UUID        `db:"entity_id" json:"entity_id"`
+	ActorID    pgtype.UUID        `db:"actor_id" json:"actor_id"`
+	Action     string             `db:"action" json:"action"`
+	PrevState  *string            `db:"prev_state" json:"prev_state"`
+	NextState  *string            `db:"next_state" json:"next_state"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) ListTransactionAuditTrail(ctx context.Context, transactionID pgtype.UUID) ([]ListTransactionAuditTrailRow, error) {
+	rows, err := q.db.Query(ctx, listTransactionAuditTrail, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTransactionAuditTrailRow
+	for rows.Next() {
+		var i ListTransactionAuditTrailRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.ActorID,
+			&i.Action,
+			&i.PrevState,
+			&i.NextState,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,157 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: auth.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const consumePasswordResetToken = `-- name: ConsumePasswordResetToken :one
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > $2
+RETURNING user_id
+`
+
+type ConsumePasswordResetTokenParams struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	Now       pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+func (q *Queries) ConsumePasswordResetToken(ctx context.Context, arg ConsumePasswordResetTokenParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, consumePasswordResetToken, arg.TokenHash, arg.Now)
+	var user_id pgtype.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const createPasswordResetToken = `-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, user_id, expires_at)
+VALUES ($1, $2, $3)
+`
+
+type CreatePasswordResetTokenParams struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) error {
+	_, err := q.db.Exec(ctx, createPasswordResetToken, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const getUserCredentialsByEmail = `-- name: GetUserCredentialsByEmail :one
+SELECT id, username, email, role, created_at, kyc_status, kyc_tier, password_hash, failed_login_attempts, locked_until
+FROM users
+WHERE lower(email) = lower($1)
+`
+
+type GetUserCredentialsByEmailRow struct {
+	ID                  pgtype.UUID        `db:"id" json:"id"`
+	Username            string             `db:"username" json:"username"`
+	Email               string             `db:"email" json:"email"`
+	Role                string             `db:"role" json:"role"`
+	CreatedAt           pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	KycStatus           string             `db:"kyc_status" json:"kyc_status"`
+	KycTier             int16              `db:"kyc_tier" json:"kyc_tier"`
+	PasswordHash        *string            `db:"password_hash" json:"password_hash"`
+	FailedLoginAttempts int32              `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamptz `db:"locked_until" json:"locked_until"`
+}
+
+func (q *Queries) GetUserCredentialsByEmail(ctx context.Context, email string) (GetUserCredentialsByEmailRow, error) {
+	row := q.db.QueryRow(ctx, getUserCredentialsByEmail, email)
+	var i GetUserCredentialsByEmailRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Role,
+		&i.CreatedAt,
+		&i.KycStatus,
+		&i.KycTier,
+		&i.PasswordHash,
+		&i.FailedLoginAttempts,
+		&i.LockedUntil,
+	)
+	return i, err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_login_attempts = CASE WHEN failed_login_attempts + 1 >= $1::int THEN 0 ELSE failed_login_attempts + 1 END,
+    locked_until = CASE WHEN failed_login_attempts + 1 >= $1::int THEN $2::timestamptz ELSE locked_until END
+WHERE id = $3
+RETURNING failed_login_attempts, locked_until
+`
+
+type RecordFailedLoginParams struct {
+	MaxAttempts int32              `db:"max_attempts" json:"max_attempts"`
+	LockedUntil pgtype.Timestamptz `db:"locked_until" json:"locked_until"`
+	ID          pgtype.UUID        `db:"id" json:"id"`
+}
+
+type RecordFailedLoginRow struct {
+	FailedLoginAttempts int32              `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamptz `db:"locked_until" json:"locked_until"`
+}
+
+// Reaching max_attempts locks the user until locked_until and starts the
+// count again.
+func (q *Queries) RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (RecordFailedLoginRow, error) {
+	row := q.db.QueryRow(ctx, recordFailedLogin, arg.MaxAttempts, arg.LockedUntil, arg.ID)
+	var i RecordFailedLoginRow
+	err := row.Scan(&i.FailedLoginAttempts, &i.LockedUntil)
+	return i, err
+}
+
+const recordSuccessfulLogin = `-- name: RecordSuccessfulLogin :exec
+UPDATE users
+SET failed_login_attempts = 0,
+    locked_until = NULL,
+    last_login_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RecordSuccessfulLogin(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, recordSuccessfulLogin, id)
+	return err
+}
+
+const revokePasswordResetTokens = `-- name: RevokePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) RevokePasswordResetTokens(ctx context.Context, userID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, revokePasswordResetTokens, userID)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_changed_at = NOW(),
+    failed_login_attempts = 0,
+    locked_until = NULL
+WHERE id = $1
+`
+
+type SetUserPasswordParams struct {
+	ID           pgtype.UUID `db:"id" json:"id"`
+	PasswordHash *string     `db:"password_hash" json:"password_hash"`
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, setUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
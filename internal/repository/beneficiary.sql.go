@@ -0,0 +1,208 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: beneficiary.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createBeneficiary = `-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at, created_at, updated_at, deleted_at
+`
+
+type CreateBeneficiaryParams struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	UserID        pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name          string             `db:"name" json:"name"`
+	Scheme        string             `db:"scheme" json:"scheme"`
+	Iban          *string            `db:"iban" json:"iban"`
+	Bic           *string            `db:"bic" json:"bic"`
+	RoutingNumber *string            `db:"routing_number" json:"routing_number"`
+	AccountNumber *string            `db:"account_number" json:"account_number"`
+	SortCode      *string            `db:"sort_code" json:"sort_code"`
+	AvailableAt   pgtype.Timestamptz `db:"available_at" json:"available_at"`
+}
+
+func (q *Queries) CreateBeneficiary(ctx context.Context, arg CreateBeneficiaryParams) (Beneficiary, error) {
+	row := q.db.QueryRow(ctx, createBeneficiary,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Scheme,
+		arg.Iban,
+		arg.Bic,
+		arg.RoutingNumber,
+		arg.AccountNumber,
+		arg.SortCode,
+		arg.AvailableAt,
+	)
+	var i Beneficiary
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Scheme,
+		&i.Iban,
+		&i.Bic,
+		&i.RoutingNumber,
+		&i.AccountNumber,
+		&i.SortCode,
+		&i.AvailableAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const deleteBeneficiary = `-- name: DeleteBeneficiary :execrows
+UPDATE beneficiaries
+SET deleted_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteBeneficiary(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBeneficiary, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getBeneficiary = `-- name: GetBeneficiary :one
+SELECT id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at, created_at, updated_at, deleted_at FROM beneficiaries
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetBeneficiary(ctx context.Context, id pgtype.UUID) (Beneficiary, error) {
+	row := q.db.QueryRow(ctx, getBeneficiary, id)
+	var i Beneficiary
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Scheme,
+		&i.Iban,
+		&i.Bic,
+		&i.RoutingNumber,
+		&i.AccountNumber,
+		&i.SortCode,
+		&i.AvailableAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const listBeneficiariesByUser = `-- name: ListBeneficiariesByUser :many
+SELECT id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at, created_at, updated_at, deleted_at FROM beneficiaries
+WHERE user_id = $1 AND deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListBeneficiariesByUserParams struct {
+	UserID pgtype.UUID `db:"user_id" json:"user_id"`
+	Limit  int32       `db:"limit" json:"limit"`
+	Offset int32       `db:"offset" json:"offset"`
+}
+
+func (q *Queries) ListBeneficiariesByUser(ctx context.Context, arg ListBeneficiariesByUserParams) ([]Beneficiary, error) {
+	rows, err := q.db.Query(ctx, listBeneficiariesByUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Beneficiary
+	for rows.Next() {
+		var i Beneficiary
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Scheme,
+			&i.Iban,
+			&i.Bic,
+			&i.RoutingNumber,
+			&i.AccountNumber,
+			&i.SortCode,
+			&i.AvailableAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBeneficiary = `-- name: UpdateBeneficiary :one
+UPDATE beneficiaries
+SET name = $2,
+    scheme = $3,
+    iban = $4,
+    bic = $5,
+    routing_number = $6,
+    account_number = $7,
+    sort_code = $8,
+    available_at = $9,
+    updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, user_id, name, scheme, iban, bic, routing_number, account_number, sort_code, available_at, created_at, updated_at, deleted_at
+`
+
+type UpdateBeneficiaryParams struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	Name          string             `db:"name" json:"name"`
+	Scheme        string             `db:"scheme" json:"scheme"`
+	Iban          *string            `db:"iban" json:"iban"`
+	Bic           *string            `db:"bic" json:"bic"`
+	RoutingNumber *string            `db:"routing_number" json:"routing_number"`
+	AccountNumber *string            `db:"account_number" json:"account_number"`
+	SortCode      *string            `db:"sort_code" json:"sort_code"`
+	AvailableAt   pgtype.Timestamptz `db:"available_at" json:"available_at"`
+}
+
+func (q *Queries) UpdateBeneficiary(ctx context.Context, arg UpdateBeneficiaryParams) (Beneficiary, error) {
+	row := q.db.QueryRow(ctx, updateBeneficiary,
+		arg.ID,
+		arg.Name,
+		arg.Scheme,
+		arg.Iban,
+		arg.Bic,
+		arg.RoutingNumber,
+		arg.AccountNumber,
+		arg.SortCode,
+		arg.AvailableAt,
+	)
+	var i Beneficiary
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Scheme,
+		&i.Iban,
+		&i.Bic,
+		&i.RoutingNumber,
+		&i.AccountNumber,
+		&i.SortCode,
+		&i.AvailableAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,205 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: kyc.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const getAccountKYCRules = `-- name: GetAccountKYCRules :one
+SELECT a.user_id, a.balance, u.kyc_tier, t.allowed_currencies, t.payouts_allowed, t.max_balance_micros
+FROM accounts a
+JOIN users u ON u.id = a.user_id
+JOIN kyc_tiers t ON t.tier = u.kyc_tier
+WHERE a.id = $1
+`
+
+type GetAccountKYCRulesRow struct {
+	UserID            pgtype.UUID `db:"user_id" json:"user_id"`
+	Balance           int64       `db:"balance" json:"balance"`
+	KycTier           int16       `db:"kyc_tier" json:"kyc_tier"`
+	AllowedCurrencies []string    `db:"allowed_currencies" json:"allowed_currencies"`
+	PayoutsAllowed    bool        `db:"payouts_allowed" json:"payouts_allowed"`
+	MaxBalanceMicros  *int64      `db:"max_balance_micros" json:"max_balance_micros"`
+}
+
+func (q *Queries) GetAccountKYCRules(ctx context.Context, id pgtype.UUID) (GetAccountKYCRulesRow, error) {
+	row := q.db.QueryRow(ctx, getAccountKYCRules, id)
+	var i GetAccountKYCRulesRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Balance,
+		&i.KycTier,
+		&i.AllowedCurrencies,
+		&i.PayoutsAllowed,
+		&i.MaxBalanceMicros,
+	)
+	return i, err
+}
+
+const getKYCTier = `-- name: GetKYCTier :one
+SELECT tier, name, allowed_currencies, payouts_allowed, max_balance_micros
+FROM kyc_tiers
+WHERE tier = $1
+`
+
+func (q *Queries) GetKYCTier(ctx context.Context, tier int16) (KycTier, error) {
+	row := q.db.QueryRow(ctx, getKYCTier, tier)
+	var i KycTier
+	err := row.Scan(
+		&i.Tier,
+		&i.Name,
+		&i.AllowedCurrencies,
+		&i.PayoutsAllowed,
+		&i.MaxBalanceMicros,
+	)
+	return i, err
+}
+
+const getUserKYC = `-- name: GetUserKYC :one
+SELECT u.id, u.kyc_status, u.kyc_tier, u.kyc_provider_ref, u.kyc_updated_at,
+       t.name AS tier_name, t.allowed_currencies, t.payouts_allowed, t.max_balance_micros
+FROM users u
+JOIN kyc_tiers t ON t.tier = u.kyc_tier
+WHERE u.id = $1
+`
+
+type GetUserKYCRow struct {
+	ID                pgtype.UUID        `db:"id" json:"id"`
+	KycStatus         string             `db:"kyc_status" json:"kyc_status"`
+	KycTier           int16              `db:"kyc_tier" json:"kyc_tier"`
+	KycProviderRef    *string            `db:"kyc_provider_ref" json:"kyc_provider_ref"`
+	KycUpdatedAt      pgtype.Timestamptz `db:"kyc_updated_at" json:"kyc_updated_at"`
+	TierName          string             `db:"tier_name" json:"tier_name"`
+	AllowedCurrencies []string           `db:"allowed_currencies" json:"allowed_currencies"`
+	PayoutsAllowed    bool               `db:"payouts_allowed" json:"payouts_allowed"`
+	MaxBalanceMicros  *int64             `db:"max_balance_micros" json:"max_balance_micros"`
+}
+
+func (q *Queries) GetUserKYC(ctx context.Context, id pgtype.UUID) (GetUserKYCRow, error) {
+	row := q.db.QueryRow(ctx, getUserKYC, id)
+	var i GetUserKYCRow
+	err := row.Scan(
+		&i.ID,
+		&i.KycStatus,
+		&i.KycTier,
+		&i.KycProviderRef,
+		&i.KycUpdatedAt,
+		&i.TierName,
+		&i.AllowedCurrencies,
+		&i.PayoutsAllowed,
+		&i.MaxBalanceMicros,
+	)
+	return i, err
+}
+
+const listKYCTiers = `-- name: ListKYCTiers :many
+SELECT tier, name, allowed_currencies, payouts_allowed, max_balance_micros
+FROM kyc_tiers
+ORDER BY tier
+`
+
+func (q *Queries) ListKYCTiers(ctx context.Context) ([]KycTier, error) {
+	rows, err := q.db.Query(ctx, listKYCTiers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KycTier
+	for rows.Next() {
+		var i KycTier
+		if err := rows.Scan(
+			&i.Tier,
+			&i.Name,
+			&i.AllowedCurrencies,
+			&i.PayoutsAllowed,
+			&i.MaxBalanceMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUserKYC = `-- name: LockUserKYC :one
+SELECT kyc_status, kyc_tier, kyc_provider_ref, kyc_updated_at
+FROM users
+WHERE id = $1
+FOR UPDATE
+`
+
+type LockUserKYCRow struct {
+	KycStatus      string             `db:"kyc_status" json:"kyc_status"`
+	KycTier        int16              `db:"kyc_tier" json:"kyc_tier"`
+	KycProviderRef *string            `db:"kyc_provider_ref" json:"kyc_provider_ref"`
+	KycUpdatedAt   pgtype.Timestamptz `db:"kyc_updated_at" json:"kyc_updated_at"`
+}
+
+func (q *Queries) LockUserKYC(ctx context.Context, id pgtype.UUID) (LockUserKYCRow, error) {
+	row := q.db.QueryRow(ctx, lockUserKYC, id)
+	var i LockUserKYCRow
+	err := row.Scan(
+		&i.KycStatus,
+		&i.KycTier,
+		&i.KycProviderRef,
+		&i.KycUpdatedAt,
+	)
+	return i, err
+}
+
+const recordKYCProviderEvent = `-- name: RecordKYCProviderEvent :execrows
+INSERT INTO kyc_provider_events (event_id, user_id, occurred_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id) DO NOTHING
+`
+
+type RecordKYCProviderEventParams struct {
+	EventID    string             `db:"event_id" json:"event_id"`
+	UserID     pgtype.UUID        `db:"user_id" json:"user_id"`
+	OccurredAt pgtype.Timestamptz `db:"occurred_at" json:"occurred_at"`
+}
+
+func (q *Queries) RecordKYCProviderEvent(ctx context.Context, arg RecordKYCProviderEventParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordKYCProviderEvent, arg.EventID, arg.UserID, arg.OccurredAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserKYC = `-- name: UpdateUserKYC :exec
+UPDATE users
+SET kyc_status = $1,
+    kyc_tier = $2,
+    kyc_provider_ref = $3,
+    kyc_updated_at = $4
+WHERE id = $5
+`
+
+type UpdateUserKYCParams struct {
+	KycStatus      string             `db:"kyc_status" json:"kyc_status"`
+	KycTier        int16              `db:"kyc_tier" json:"kyc_tier"`
+	KycProviderRef *string            `db:"kyc_provider_ref" json:"kyc_provider_ref"`
+	KycUpdatedAt   pgtype.Timestamptz `db:"kyc_updated_at" json:"kyc_updated_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+}
+
+func (q *Queries) UpdateUserKYC(ctx context.Context, arg UpdateUserKYCParams) error {
+	_, err := q.db.Exec(ctx, updateUserKYC,
+		arg.KycStatus,
+		arg.KycTier,
+		arg.KycProviderRef,
+		arg.KycUpdatedAt,
+		arg.ID,
+	)
+	return err
+}
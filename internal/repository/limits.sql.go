@@ -0,0 +1,351 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: limits.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addLimitUsage = `-- name: AddLimitUsage :one
+INSERT INTO limit_usage (user_id, account_id, txn_type, currency, period, period_start, used_micros, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+ON CONFLICT (user_id, account_id, txn_type, currency, period, period_start) DO UPDATE
+SET used_micros = limit_usage.used_micros + EXCLUDED.used_micros,
+    updated_at = NOW()
+RETURNING used_micros
+`
+
+type AddLimitUsageParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	AccountID   pgtype.UUID `db:"account_id" json:"account_id"`
+	TxnType     string      `db:"txn_type" json:"txn_type"`
+	Currency    string      `db:"currency" json:"currency"`
+	Period      string      `db:"period" json:"period"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+	Delta       int64       `db:"delta" json:"delta"`
+}
+
+func (q *Queries) AddLimitUsage(ctx context.Context, arg AddLimitUsageParams) (int64, error) {
+	row := q.db.QueryRow(ctx, addLimitUsage,
+		arg.UserID,
+		arg.AccountID,
+		arg.TxnType,
+		arg.Currency,
+		arg.Period,
+		arg.PeriodStart,
+		arg.Delta,
+	)
+	var used_micros int64
+	err := row.Scan(&used_micros)
+	return used_micros, err
+}
+
+const deleteTransactionLimit = `-- name: DeleteTransactionLimit :execrows
+DELETE FROM transaction_limits WHERE txn_type = $1 AND currency = $2
+`
+
+type DeleteTransactionLimitParams struct {
+	TxnType  string `db:"txn_type" json:"txn_type"`
+	Currency string `db:"currency" json:"currency"`
+}
+
+func (q *Queries) DeleteTransactionLimit(ctx context.Context, arg DeleteTransactionLimitParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTransactionLimit, arg.TxnType, arg.Currency)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserLimitOverride = `-- name: DeleteUserLimitOverride :execrows
+DELETE FROM user_limit_overrides
+WHERE user_id = $1 AND account_id IS NOT DISTINCT FROM $2 AND txn_type = $3 AND currency = $4
+`
+
+type DeleteUserLimitOverrideParams struct {
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+	AccountID pgtype.UUID `db:"account_id" json:"account_id"`
+	TxnType   string      `db:"txn_type" json:"txn_type"`
+	Currency  string      `db:"currency" json:"currency"`
+}
+
+func (q *Queries) DeleteUserLimitOverride(ctx context.Context, arg DeleteUserLimitOverrideParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserLimitOverride,
+		arg.UserID,
+		arg.AccountID,
+		arg.TxnType,
+		arg.Currency,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getApplicableLimitOverride = `-- name: GetApplicableLimitOverride :one
+SELECT user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id
+FROM user_limit_overrides
+WHERE user_id = $1 AND txn_type = $2 AND currency = $3
+  AND (account_id IS NULL OR account_id = $4)
+ORDER BY account_id NULLS LAST
+LIMIT 1
+`
+
+type GetApplicableLimitOverrideParams struct {
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
+	TxnType   string      `db:"txn_type" json:"txn_type"`
+	Currency  string      `db:"currency" json:"currency"`
+	AccountID pgtype.UUID `db:"account_id" json:"account_id"`
+}
+
+// The account's own override wins over the user-wide one.
+func (q *Queries) GetApplicableLimitOverride(ctx context.Context, arg GetApplicableLimitOverrideParams) (UserLimitOverride, error) {
+	row := q.db.QueryRow(ctx, getApplicableLimitOverride,
+		arg.UserID,
+		arg.TxnType,
+		arg.Currency,
+		arg.AccountID,
+	)
+	var i UserLimitOverride
+	err := row.Scan(
+		&i.UserID,
+		&i.TxnType,
+		&i.Currency,
+		&i.PerTransactionMaxMicros,
+		&i.DailyMaxMicros,
+		&i.MonthlyMaxMicros,
+		&i.Note,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.AccountID,
+	)
+	return i, err
+}
+
+const getLimitUsage = `-- name: GetLimitUsage :one
+SELECT used_micros
+FROM limit_usage
+WHERE user_id = $1 AND account_id IS NOT DISTINCT FROM $2 AND txn_type = $3 AND currency = $4 AND period = $5 AND period_start = $6
+`
+
+type GetLimitUsageParams struct {
+	UserID      pgtype.UUID `db:"user_id" json:"user_id"`
+	AccountID   pgtype.UUID `db:"account_id" json:"account_id"`
+	TxnType     string      `db:"txn_type" json:"txn_type"`
+	Currency    string      `db:"currency" json:"currency"`
+	Period      string      `db:"period" json:"period"`
+	PeriodStart pgtype.Date `db:"period_start" json:"period_start"`
+}
+
+func (q *Queries) GetLimitUsage(ctx context.Context, arg GetLimitUsageParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getLimitUsage,
+		arg.UserID,
+		arg.AccountID,
+		arg.TxnType,
+		arg.Currency,
+		arg.Period,
+		arg.PeriodStart,
+	)
+	var used_micros int64
+	err := row.Scan(&used_micros)
+	return used_micros, err
+}
+
+const getTransactionLimit = `-- name: GetTransactionLimit :one
+SELECT txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at
+FROM transaction_limits
+WHERE txn_type = $1 AND currency = $2
+`
+
+type GetTransactionLimitParams struct {
+	TxnType  string `db:"txn_type" json:"txn_type"`
+	Currency string `db:"currency" json:"currency"`
+}
+
+func (q *Queries) GetTransactionLimit(ctx context.Context, arg GetTransactionLimitParams) (TransactionLimit, error) {
+	row := q.db.QueryRow(ctx, getTransactionLimit, arg.TxnType, arg.Currency)
+	var i TransactionLimit
+	err := row.Scan(
+		&i.TxnType,
+		&i.Currency,
+		&i.PerTransactionMaxMicros,
+		&i.DailyMaxMicros,
+		&i.MonthlyMaxMicros,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listTransactionLimits = `-- name: ListTransactionLimits :many
+SELECT txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at
+FROM transaction_limits
+ORDER BY txn_type, currency
+`
+
+func (q *Queries) ListTransactionLimits(ctx context.Context) ([]TransactionLimit, error) {
+	rows, err := q.db.Query(ctx, listTransactionLimits)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TransactionLimit
+	for rows.Next() {
+		var i TransactionLimit
+		if err := rows.Scan(
+			&i.TxnType,
+			&i.Currency,
+			&i.PerTransactionMaxMicros,
+			&i.DailyMaxMicros,
+			&i.MonthlyMaxMicros,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserLimitOverrides = `-- name: ListUserLimitOverrides :many
+SELECT user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id
+FROM user_limit_overrides
+WHERE user_id = $1
+ORDER BY txn_type, currency, account_id NULLS FIRST
+`
+
+func (q *Queries) ListUserLimitOverrides(ctx context.Context, userID pgtype.UUID) ([]UserLimitOverride, error) {
+	rows, err := q.db.Query(ctx, listUserLimitOverrides, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserLimitOverride
+	for rows.Next() {
+		var i UserLimitOverride
+		if err := rows.Scan(
+			&i.UserID,
+			&i.TxnType,
+			&i.Currency,
+			&i.PerTransactionMaxMicros,
+			&i.DailyMaxMicros,
+			&i.MonthlyMaxMicros,
+			&i.Note,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+			&i.AccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTransactionLimit = `-- name: UpsertTransactionLimit :one
+INSERT INTO transaction_limits (txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (txn_type, currency) DO UPDATE
+SET per_transaction_max_micros = EXCLUDED.per_transaction_max_micros,
+    daily_max_micros = EXCLUDED.daily_max_micros,
+    monthly_max_micros = EXCLUDED.monthly_max_micros,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, updated_by, updated_at
+`
+
+type UpsertTransactionLimitParams struct {
+	TxnType                 string      `db:"txn_type" json:"txn_type"`
+	Currency                string      `db:"currency" json:"currency"`
+	PerTransactionMaxMicros *int64      `db:"per_transaction_max_micros" json:"per_transaction_max_micros"`
+	DailyMaxMicros          *int64      `db:"daily_max_micros" json:"daily_max_micros"`
+	MonthlyMaxMicros        *int64      `db:"monthly_max_micros" json:"monthly_max_micros"`
+	UpdatedBy               pgtype.UUID `db:"updated_by" json:"updated_by"`
+}
+
+func (q *Queries) UpsertTransactionLimit(ctx context.Context, arg UpsertTransactionLimitParams) (TransactionLimit, error) {
+	row := q.db.QueryRow(ctx, upsertTransactionLimit,
+		arg.TxnType,
+		arg.Currency,
+		arg.PerTransactionMaxMicros,
+		arg.DailyMaxMicros,
+		arg.MonthlyMaxMicros,
+		arg.UpdatedBy,
+	)
+	var i TransactionLimit
+	err := row.Scan(
+		&i.TxnType,
+		&i.Currency,
+		&i.PerTransactionMaxMicros,
+		&i.DailyMaxMicros,
+		&i.MonthlyMaxMicros,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertUserLimitOverride = `-- name: UpsertUserLimitOverride :one
+INSERT INTO user_limit_overrides (user_id, account_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+ON CONFLICT (user_id, account_id, txn_type, currency) DO UPDATE
+SET per_transaction_max_micros = EXCLUDED.per_transaction_max_micros,
+    daily_max_micros = EXCLUDED.daily_max_micros,
+    monthly_max_micros = EXCLUDED.monthly_max_micros,
+    note = EXCLUDED.note,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = NOW()
+RETURNING user_id, txn_type, currency, per_transaction_max_micros, daily_max_micros, monthly_max_micros, note, updated_by, updated_at, account_id
+`
+
+type UpsertUserLimitOverrideParams struct {
+	UserID                  pgtype.UUID `db:"user_id" json:"user_id"`
+	AccountID               pgtype.UUID `db:"account_id" json:"account_id"`
+	TxnType                 string      `db:"txn_type" json:"txn_type"`
+	Currency                string      `db:"currency" json:"currency"`
+	PerTransactionMaxMicros *int64      `db:"per_transaction_max_micros" json:"per_transaction_max_micros"`
+	DailyMaxMicros          *int64      `db:"daily_max_micros" json:"daily_max_micros"`
+	MonthlyMaxMicros        *int64      `db:"monthly_max_micros" json:"monthly_max_micros"`
+	Note                    *string     `db:"note" json:"note"`
+	UpdatedBy               pgtype.UUID `db:"updated_by" json:"updated_by"`
+}
+
+func (q *Queries) UpsertUserLimitOverride(ctx context.Context, arg UpsertUserLimitOverrideParams) (UserLimitOverride, error) {
+	row := q.db.QueryRow(ctx, upsertUserLimitOverride,
+		arg.UserID,
+		arg.AccountID,
+		arg.TxnType,
+		arg.Currency,
+		arg.PerTransactionMaxMicros,
+		arg.DailyMaxMicros,
+		arg.MonthlyMaxMicros,
+		arg.Note,
+		arg.UpdatedBy,
+	)
+	var i UserLimitOverride
+	err := row.Scan(
+		&i.UserID,
+		&i.TxnType,
+		&i.Currency,
+		&i.PerTransactionMaxMicros,
+		&i.DailyMaxMicros,
+		&i.MonthlyMaxMicros,
+		&i.Note,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+		&i.AccountID,
+	)
+	return i, err
+}
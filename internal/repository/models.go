@@ -9,12 +9,101 @@ import (
 )
 
 type Account struct {
-	ID           pgtype.UUID        `db:"id" json:"id"`
-	UserID       pgtype.UUID        `db:"user_id" json:"user_id"`
-	Currency     string             `db:"currency" json:"currency"`
-	Balance      int64              `db:"balance" json:"balance"`
-	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	LockedMicros int64              `db:"locked_micros" json:"locked_micros"`
+	ID                   pgtype.UUID        `db:"id" json:"id"`
+	UserID               pgtype.UUID        `db:"user_id" json:"user_id"`
+	Currency             string             `db:"currency" json:"currency"`
+	Balance              int64              `db:"balance" json:"balance"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	LockedMicros         int64              `db:"locked_micros" json:"locked_micros"`
+	Status               string             `db:"status" json:"status"`
+	StatusReason         *string            `db:"status_reason" json:"status_reason"`
+	StatusChangedBy      pgtype.UUID        `db:"status_changed_by" json:"status_changed_by"`
+	StatusChangedAt      pgtype.Timestamptz `db:"status_changed_at" json:"status_changed_at"`
+	OverdraftLimitMicros int64              `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	OverdraftInterestBps int32              `db:"overdraft_interest_bps" json:"overdraft_interest_bps"`
+}
+
+type AmlAlert struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	CaseID      pgtype.UUID        `db:"case_id" json:"case_id"`
+	RuleCode    string             `db:"rule_code" json:"rule_code"`
+	AccountID   pgtype.UUID        `db:"account_id" json:"account_id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	EventID     pgtype.UUID        `db:"event_id" json:"event_id"`
+	Severity    string             `db:"severity" json:"severity"`
+	Details     []byte             `db:"details" json:"details"`
+	WindowStart pgtype.Timestamptz `db:"window_start" json:"window_start"`
+	WindowEnd   pgtype.Timestamptz `db:"window_end" json:"window_end"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type AmlCase struct {
+	ID         pgtype.UUID        `db:"id" json:"id"`
+	UserID     pgtype.UUID        `db:"user_id" json:"user_id"`
+	Status     string             `db:"status" json:"status"`
+	AssignedTo pgtype.UUID        `db:"assigned_to" json:"assigned_to"`
+	Resolution *string            `db:"resolution" json:"resolution"`
+	Note       *string            `db:"note" json:"note"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	ClosedAt   pgtype.Timestamptz `db:"closed_at" json:"closed_at"`
+}
+
+type AmlObservation struct {
+	ID              int64              `db:"id" json:"id"`
+	EventID         pgtype.UUID        `db:"event_id" json:"event_id"`
+	EventType       string             `db:"event_type" json:"event_type"`
+	TransactionID   pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
+	AccountID       pgtype.UUID        `db:"account_id" json:"account_id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	Direction       string             `db:"direction" json:"direction"`
+	AmountMicros    int64              `db:"amount_micros" json:"amount_micros"`
+	Currency        string             `db:"currency" json:"currency"`
+	CounterCurrency *string            `db:"counter_currency" json:"counter_currency"`
+	OccurredAt      pgtype.Timestamptz `db:"occurred_at" json:"occurred_at"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type AmlRule struct {
+	Code          string             `db:"code" json:"code"`
+	Description   string             `db:"description" json:"description"`
+	Enabled       bool               `db:"enabled" json:"enabled"`
+	WindowSeconds int32              `db:"window_seconds" json:"window_seconds"`
+	AmountMicros  int64              `db:"amount_micros" json:"amount_micros"`
+	MinCount      int32              `db:"min_count" json:"min_count"`
+	RatioBps      int32              `db:"ratio_bps" json:"ratio_bps"`
+	Severity      string             `db:"severity" json:"severity"`
+	UpdatedBy     pgtype.UUID        `db:"updated_by" json:"updated_by"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type ApiKey struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name        string             `db:"name" json:"name"`
+	Prefix      string             `db:"prefix" json:"prefix"`
+	KeyHash     string             `db:"key_hash" json:"key_hash"`
+	Scopes      []string           `db:"scopes" json:"scopes"`
+	CreatedAt   pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ExpiresAt   pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `db:"last_used_at" json:"last_used_at"`
+	RevokedAt   pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	RotatedFrom pgtype.UUID        `db:"rotated_from" json:"rotated_from"`
+}
+
+type AuditAnchor struct {
+	ID         int64              `db:"id" json:"id"`
+	ChainSeq   int64              `db:"chain_seq" json:"chain_seq"`
+	RecordHash string             `db:"record_hash" json:"record_hash"`
+	FilePath   *string            `db:"file_path" json:"file_path"`
+	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type AuditChainHead struct {
+	ID        bool               `db:"id" json:"id"`
+	LastSeq   int64              `db:"last_seq" json:"last_seq"`
+	LastHash  string             `db:"last_hash" json:"last_hash"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 type AuditLog struct {
@@ -27,6 +116,53 @@ type AuditLog struct {
 	NextState  *string            `db:"next_state" json:"next_state"`
 	Metadata   []byte             `db:"metadata" json:"metadata"`
 	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ChainSeq   int64              `db:"chain_seq" json:"chain_seq"`
+	PrevHash   string             `db:"prev_hash" json:"prev_hash"`
+	RecordHash string             `db:"record_hash" json:"record_hash"`
+}
+
+type Beneficiary struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	UserID        pgtype.UUID        `db:"user_id" json:"user_id"`
+	Name          string             `db:"name" json:"name"`
+	Scheme        string             `db:"scheme" json:"scheme"`
+	Iban          *string            `db:"iban" json:"iban"`
+	Bic           *string            `db:"bic" json:"bic"`
+	RoutingNumber *string            `db:"routing_number" json:"routing_number"`
+	AccountNumber *string            `db:"account_number" json:"account_number"`
+	SortCode      *string            `db:"sort_code" json:"sort_code"`
+	AvailableAt   pgtype.Timestamptz `db:"available_at" json:"available_at"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	DeletedAt     pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+}
+
+type EntriesArchive struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	PartitionName string             `db:"partition_name" json:"partition_name"`
+	PeriodStart   pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd     pgtype.Timestamptz `db:"period_end" json:"period_end"`
+	Status        string             `db:"status" json:"status"`
+	RowCount      int64              `db:"row_count" json:"row_count"`
+	FilePath      *string            `db:"file_path" json:"file_path"`
+	ManifestPath  *string            `db:"manifest_path" json:"manifest_path"`
+	Sha256        *string            `db:"sha256" json:"sha256"`
+	ClaimedAt     pgtype.Timestamptz `db:"claimed_at" json:"claimed_at"`
+	ArchivedAt    pgtype.Timestamptz `db:"archived_at" json:"archived_at"`
+}
+
+type EntriesArchiveCurrencyTotal struct {
+	ArchiveID    pgtype.UUID `db:"archive_id" json:"archive_id"`
+	Currency     string      `db:"currency" json:"currency"`
+	EntryCount   int64       `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64       `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64       `db:"credit_micros" json:"credit_micros"`
+}
+
+type EntriesArchivedAccountTotal struct {
+	AccountID pgtype.UUID        `db:"account_id" json:"account_id"`
+	NetMicros int64              `db:"net_micros" json:"net_micros"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 type EntriesDefault struct {
@@ -87,16 +223,242 @@ type IdempotencyKey struct {
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
-type Payout struct {
+type KycProviderEvent struct {
+	EventID    string             `db:"event_id" json:"event_id"`
+	UserID     pgtype.UUID        `db:"user_id" json:"user_id"`
+	OccurredAt pgtype.Timestamptz `db:"occurred_at" json:"occurred_at"`
+	ReceivedAt pgtype.Timestamptz `db:"received_at" json:"received_at"`
+}
+
+type KycTier struct {
+	Tier              int16    `db:"tier" json:"tier"`
+	Name              string   `db:"name" json:"name"`
+	AllowedCurrencies []string `db:"allowed_currencies" json:"allowed_currencies"`
+	PayoutsAllowed    bool     `db:"payouts_allowed" json:"payouts_allowed"`
+	MaxBalanceMicros  *int64   `db:"max_balance_micros" json:"max_balance_micros"`
+}
+
+type LedgerAccountTotal struct {
+	AccountID pgtype.UUID        `db:"account_id" json:"account_id"`
+	NetMicros int64              `db:"net_micros" json:"net_micros"`
+	UpdatedAt pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type LedgerCheckpoint struct {
+	ID                bool               `db:"id" json:"id"`
+	StartedFrom       pgtype.Date        `db:"started_from" json:"started_from"`
+	CheckpointedUntil pgtype.Date        `db:"checkpointed_until" json:"checkpointed_until"`
+	UpdatedAt         pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type LedgerDayTotal struct {
+	Day          pgtype.Date        `db:"day" json:"day"`
+	Currency     string             `db:"currency" json:"currency"`
+	EntryCount   int64              `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64              `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64              `db:"credit_micros" json:"credit_micros"`
+	ComputedAt   pgtype.Timestamptz `db:"computed_at" json:"computed_at"`
+}
+
+type LedgerDirtyDay struct {
+	Day      pgtype.Date        `db:"day" json:"day"`
+	MarkedAt pgtype.Timestamptz `db:"marked_at" json:"marked_at"`
+}
+
+type LedgerMonthSeal struct {
+	Month      pgtype.Date        `db:"month" json:"month"`
+	EntryCount int64              `db:"entry_count" json:"entry_count"`
+	Checksum   string             `db:"checksum" json:"checksum"`
+	Status     string             `db:"status" json:"status"`
+	SealedAt   pgtype.Timestamptz `db:"sealed_at" json:"sealed_at"`
+	VerifiedAt pgtype.Timestamptz `db:"verified_at" json:"verified_at"`
+}
+
+type LimitUsage struct {
+	UserID      pgtype.UUID        `db:"user_id" json:"user_id"`
+	TxnType     string             `db:"txn_type" json:"txn_type"`
+	Currency    string             `db:"currency" json:"currency"`
+	Period      string             `db:"period" json:"period"`
+	PeriodStart pgtype.Date        `db:"period_start" json:"period_start"`
+	UsedMicros  int64              `db:"used_micros" json:"used_micros"`
+	UpdatedAt   pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	AccountID   pgtype.UUID        `db:"account_id" json:"account_id"`
+}
+
+type OutboxDelivery struct {
+	EventID     pgtype.UUID        `db:"event_id" json:"event_id"`
+	Sink        string             `db:"sink" json:"sink"`
+	DeliveredAt pgtype.Timestamptz `db:"delivered_at" json:"delivered_at"`
+}
+
+type OutboxEvent struct {
 	ID            pgtype.UUID        `db:"id" json:"id"`
-	TransactionID pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
-	AccountID     pgtype.UUID        `db:"account_id" json:"account_id"`
-	AmountMicros  int64              `db:"amount_micros" json:"amount_micros"`
-	Currency      string             `db:"currency" json:"currency"`
-	Status        string             `db:"status" json:"status"`
-	GatewayRef    *string            `db:"gateway_ref" json:"gateway_ref"`
+	AggregateType string             `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   pgtype.UUID        `db:"aggregate_id" json:"aggregate_id"`
+	EventType     string             `db:"event_type" json:"event_type"`
+	Payload       []byte             `db:"payload" json:"payload"`
+	Attempts      int32              `db:"attempts" json:"attempts"`
+	LastError     *string            `db:"last_error" json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+	PublishedAt   pgtype.Timestamptz `db:"published_at" json:"published_at"`
 	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type PasswordResetToken struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
+	ExpiresAt pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `db:"used_at" json:"used_at"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Payout struct {
+	ID               pgtype.UUID        `db:"id" json:"id"`
+	TransactionID    pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
+	AccountID        pgtype.UUID        `db:"account_id" json:"account_id"`
+	AmountMicros     int64              `db:"amount_micros" json:"amount_micros"`
+	Currency         string             `db:"currency" json:"currency"`
+	Status           string             `db:"status" json:"status"`
+	GatewayRef       *string            `db:"gateway_ref" json:"gateway_ref"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	Attempts         int32              `db:"attempts" json:"attempts"`
+	RequestedBy      pgtype.UUID        `db:"requested_by" json:"requested_by"`
+	ReviewedBy       pgtype.UUID        `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt       pgtype.Timestamptz `db:"reviewed_at" json:"reviewed_at"`
+	BeneficiaryID    pgtype.UUID        `db:"beneficiary_id" json:"beneficiary_id"`
+	LimitReservation []byte             `db:"limit_reservation" json:"limit_reservation"`
+}
+
+type PayoutScreening struct {
+	PayoutID      pgtype.UUID        `db:"payout_id" json:"payout_id"`
+	ScreenedName  string             `db:"screened_name" json:"screened_name"`
+	ListSource    string             `db:"list_source" json:"list_source"`
+	ListEntryID   string             `db:"list_entry_id" json:"list_entry_id"`
+	MatchedName   string             `db:"matched_name" json:"matched_name"`
+	Score         float64            `db:"score" json:"score"`
+	ReleaseStatus string             `db:"release_status" json:"release_status"`
+	Decision      *string            `db:"decision" json:"decision"`
+	DecidedBy     pgtype.UUID        `db:"decided_by" json:"decided_by"`
+	DecidedAt     pgtype.Timestamptz `db:"decided_at" json:"decided_at"`
+	Reason        *string            `db:"reason" json:"reason"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+}
+
+type ReconciliationBreak struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	BreakType      string             `db:"break_type" json:"break_type"`
+	Status         string             `db:"status" json:"status"`
+	PayoutID       pgtype.UUID        `db:"payout_id" json:"payout_id"`
+	LineID         pgtype.UUID        `db:"line_id" json:"line_id"`
+	FileID         pgtype.UUID        `db:"file_id" json:"file_id"`
+	GatewayRef     *string            `db:"gateway_ref" json:"gateway_ref"`
+	Currency       *string            `db:"currency" json:"currency"`
+	ExpectedMicros *int64             `db:"expected_micros" json:"expected_micros"`
+	ActualMicros   *int64             `db:"actual_micros" json:"actual_micros"`
+	Details        string             `db:"details" json:"details"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ClearedBy      pgtype.UUID        `db:"cleared_by" json:"cleared_by"`
+	ClearedAt      pgtype.Timestamptz `db:"cleared_at" json:"cleared_at"`
+	ResolutionNote *string            `db:"resolution_note" json:"resolution_note"`
+}
+
+type ReconciliationFinding struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	FindingType    string             `db:"finding_type" json:"finding_type"`
+	Status         string             `db:"status" json:"status"`
+	SubjectID      pgtype.UUID        `db:"subject_id" json:"subject_id"`
+	Currency       string             `db:"currency" json:"currency"`
+	ExpectedMicros int64              `db:"expected_micros" json:"expected_micros"`
+	ActualMicros   int64              `db:"actual_micros" json:"actual_micros"`
+	FirstSeenAt    pgtype.Timestamptz `db:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt     pgtype.Timestamptz `db:"last_seen_at" json:"last_seen_at"`
+	ResolvedAt     pgtype.Timestamptz `db:"resolved_at" json:"resolved_at"`
+	Period         string             `db:"period" json:"period"`
+}
+
+type ReconciliationRun struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Trigger        string             `db:"trigger" json:"trigger"`
+	Scope          string             `db:"scope" json:"scope"`
+	Status         string             `db:"status" json:"status"`
+	TriggeredBy    pgtype.UUID        `db:"triggered_by" json:"triggered_by"`
+	StartedAt      pgtype.Timestamptz `db:"started_at" json:"started_at"`
+	FinishedAt     pgtype.Timestamptz `db:"finished_at" json:"finished_at"`
+	CurrencyTotals []byte             `db:"currency_totals" json:"currency_totals"`
+	Findings       []byte             `db:"findings" json:"findings"`
+	FindingCount   int32              `db:"finding_count" json:"finding_count"`
+	Error          *string            `db:"error" json:"error"`
+}
+
+type RefreshToken struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	SessionID pgtype.UUID        `db:"session_id" json:"session_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UsedAt    pgtype.Timestamptz `db:"used_at" json:"used_at"`
+}
+
+type Role struct {
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+}
+
+type RolePermission struct {
+	Role       string `db:"role" json:"role"`
+	Permission string `db:"permission" json:"permission"`
+}
+
+type SanctionsEntry struct {
+	ID         int64  `db:"id" json:"id"`
+	LoadID     int64  `db:"load_id" json:"load_id"`
+	Source     string `db:"source" json:"source"`
+	ExternalID string `db:"external_id" json:"external_id"`
+	Name       string `db:"name" json:"name"`
+	EntryType  string `db:"entry_type" json:"entry_type"`
+	Programs   string `db:"programs" json:"programs"`
+}
+
+type SanctionsListLoad struct {
+	ID         int64              `db:"id" json:"id"`
+	Source     string             `db:"source" json:"source"`
+	EntryCount int32              `db:"entry_count" json:"entry_count"`
+	Checksum   string             `db:"checksum" json:"checksum"`
+	LoadedBy   string             `db:"loaded_by" json:"loaded_by"`
+	LoadedAt   pgtype.Timestamptz `db:"loaded_at" json:"loaded_at"`
+}
+
+type SettlementFile struct {
+	ID           pgtype.UUID        `db:"id" json:"id"`
+	FileName     string             `db:"file_name" json:"file_name"`
+	Format       string             `db:"format" json:"format"`
+	Sha256       string             `db:"sha256" json:"sha256"`
+	PeriodStart  pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd    pgtype.Timestamptz `db:"period_end" json:"period_end"`
+	LineCount    int32              `db:"line_count" json:"line_count"`
+	MatchedCount int32              `db:"matched_count" json:"matched_count"`
+	BreakCount   int32              `db:"break_count" json:"break_count"`
+	ImportedBy   pgtype.UUID        `db:"imported_by" json:"imported_by"`
+	ImportedAt   pgtype.Timestamptz `db:"imported_at" json:"imported_at"`
+}
+
+type SettlementLine struct {
+	ID           pgtype.UUID        `db:"id" json:"id"`
+	FileID       pgtype.UUID        `db:"file_id" json:"file_id"`
+	LineNo       int32              `db:"line_no" json:"line_no"`
+	GatewayRef   string             `db:"gateway_ref" json:"gateway_ref"`
+	AmountMicros int64              `db:"amount_micros" json:"amount_micros"`
+	Currency     string             `db:"currency" json:"currency"`
+	Direction    string             `db:"direction" json:"direction"`
+	BookedAt     pgtype.Timestamptz `db:"booked_at" json:"booked_at"`
+	Description  *string            `db:"description" json:"description"`
+	PayoutID     pgtype.UUID        `db:"payout_id" json:"payout_id"`
+	MatchStatus  string             `db:"match_status" json:"match_status"`
+	CreatedAt    pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
 type Transaction struct {
@@ -111,10 +473,56 @@ type Transaction struct {
 	Metadata    []byte             `db:"metadata" json:"metadata"`
 }
 
+type TransactionLimit struct {
+	TxnType                 string             `db:"txn_type" json:"txn_type"`
+	Currency                string             `db:"currency" json:"currency"`
+	PerTransactionMaxMicros *int64             `db:"per_transaction_max_micros" json:"per_transaction_max_micros"`
+	DailyMaxMicros          *int64             `db:"daily_max_micros" json:"daily_max_micros"`
+	MonthlyMaxMicros        *int64             `db:"monthly_max_micros" json:"monthly_max_micros"`
+	UpdatedBy               pgtype.UUID        `db:"updated_by" json:"updated_by"`
+	UpdatedAt               pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type User struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Username  string             `db:"username" json:"username"`
-	Email     string             `db:"email" json:"email"`
-	Role      string             `db:"role" json:"role"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID                  pgtype.UUID        `db:"id" json:"id"`
+	Username            string             `db:"username" json:"username"`
+	Email               string             `db:"email" json:"email"`
+	Role                string             `db:"role" json:"role"`
+	CreatedAt           pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	KycStatus           string             `db:"kyc_status" json:"kyc_status"`
+	KycTier             int16              `db:"kyc_tier" json:"kyc_tier"`
+	KycProviderRef      *string            `db:"kyc_provider_ref" json:"kyc_provider_ref"`
+	KycUpdatedAt        pgtype.Timestamptz `db:"kyc_updated_at" json:"kyc_updated_at"`
+	PasswordHash        *string            `db:"password_hash" json:"password_hash"`
+	PasswordChangedAt   pgtype.Timestamptz `db:"password_changed_at" json:"password_changed_at"`
+	FailedLoginAttempts int32              `db:"failed_login_attempts" json:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamptz `db:"locked_until" json:"locked_until"`
+	LastLoginAt         pgtype.Timestamptz `db:"last_login_at" json:"last_login_at"`
+}
+
+type UserLimitOverride struct {
+	UserID                  pgtype.UUID        `db:"user_id" json:"user_id"`
+	TxnType                 string             `db:"txn_type" json:"txn_type"`
+	Currency                string             `db:"currency" json:"currency"`
+	PerTransactionMaxMicros *int64             `db:"per_transaction_max_micros" json:"per_transaction_max_micros"`
+	DailyMaxMicros          *int64             `db:"daily_max_micros" json:"daily_max_micros"`
+	MonthlyMaxMicros        *int64             `db:"monthly_max_micros" json:"monthly_max_micros"`
+	Note                    *string            `db:"note" json:"note"`
+	UpdatedBy               pgtype.UUID        `db:"updated_by" json:"updated_by"`
+	UpdatedAt               pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	AccountID               pgtype.UUID        `db:"account_id" json:"account_id"`
+}
+
+type UserSession struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+	UserAgent       string             `db:"user_agent" json:"user_agent"`
+	IpAddress       string             `db:"ip_address" json:"ip_address"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	LastRefreshedAt pgtype.Timestamptz `db:"last_refreshed_at" json:"last_refreshed_at"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	RevokedAt       pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	RevokedReason   *string            `db:"revoked_reason" json:"revoked_reason"`
 }
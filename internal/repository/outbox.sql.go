@@ -0,0 +1,227 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: outbox.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+WITH due AS (
+  SELECT id FROM outbox_events
+  WHERE published_at IS NULL AND next_attempt_at <= NOW()
+  ORDER BY created_at ASC
+  FOR UPDATE SKIP LOCKED
+  LIMIT $2
+)
+UPDATE outbox_events o
+SET next_attempt_at = $1
+FROM due
+WHERE o.id = due.id
+RETURNING o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.attempts, o.last_error, o.next_attempt_at, o.published_at, o.created_at
+`
+
+type ClaimOutboxEventsParams struct {
+	LeaseUntil pgtype.Timestamptz `db:"lease_until" json:"lease_until"`
+	BatchSize  int32              `db:"batch_size" json:"batch_size"`
+}
+
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]OutboxEvent, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.LeaseUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.PublishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countPendingOutboxEvents = `-- name: CountPendingOutboxEvents :one
+SELECT COUNT(*)::bigint FROM outbox_events WHERE published_at IS NULL
+`
+
+func (q *Queries) CountPendingOutboxEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countPendingOutboxEvents)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const deletePublishedOutboxEvents = `-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE published_at IS NOT NULL AND published_at < $1
+`
+
+func (q *Queries) DeletePublishedOutboxEvents(ctx context.Context, publishedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePublishedOutboxEvents, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getOutboxDeliveredSinks = `-- name: GetOutboxDeliveredSinks :many
+SELECT sink FROM outbox_deliveries WHERE event_id = $1
+`
+
+func (q *Queries) GetOutboxDeliveredSinks(ctx context.Context, eventID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getOutboxDeliveredSinks, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var sink string
+		if err := rows.Scan(&sink); err != nil {
+			return nil, err
+		}
+		items = append(items, sink)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOutboxEventsByAggregate = `-- name: GetOutboxEventsByAggregate :many
+SELECT id, aggregate_type, aggregate_id, event_type, payload, attempts, last_error, next_attempt_at, published_at, created_at FROM outbox_events
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY created_at ASC
+`
+
+type GetOutboxEventsByAggregateParams struct {
+	AggregateType string      `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   pgtype.UUID `db:"aggregate_id" json:"aggregate_id"`
+}
+
+func (q *Queries) GetOutboxEventsByAggregate(ctx context.Context, arg GetOutboxEventsByAggregateParams) ([]OutboxEvent, error) {
+	rows, err := q.db.Query(ctx, getOutboxEventsByAggregate, arg.AggregateType, arg.AggregateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.AggregateType,
+			&i.AggregateID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.PublishedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertOutboxDelivery = `-- name: InsertOutboxDelivery :exec
+INSERT INTO outbox_deliveries (event_id, sink, delivered_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (event_id, sink) DO NOTHING
+`
+
+type InsertOutboxDeliveryParams struct {
+	EventID pgtype.UUID `db:"event_id" json:"event_id"`
+	Sink    string      `db:"sink" json:"sink"`
+}
+
+func (q *Queries) InsertOutboxDelivery(ctx context.Context, arg InsertOutboxDeliveryParams) error {
+	_, err := q.db.Exec(ctx, insertOutboxDelivery, arg.EventID, arg.Sink)
+	return err
+}
+
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events (id, aggregate_type, aggregate_id, event_type, payload, created_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+`
+
+type InsertOutboxEventParams struct {
+	ID            pgtype.UUID `db:"id" json:"id"`
+	AggregateType string      `db:"aggregate_type" json:"aggregate_type"`
+	AggregateID   pgtype.UUID `db:"aggregate_id" json:"aggregate_id"`
+	EventType     string      `db:"event_type" json:"event_type"`
+	Payload       []byte      `db:"payload" json:"payload"`
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, insertOutboxEvent,
+		arg.ID,
+		arg.AggregateType,
+		arg.AggregateID,
+		arg.EventType,
+		arg.Payload,
+	)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :execrows
+UPDATE outbox_events
+SET published_at = NOW(), last_error = NULL
+WHERE id = $1 AND published_at IS NULL
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, markOutboxEventPublished, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markOutboxEventRetry = `-- name: MarkOutboxEventRetry :execrows
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+WHERE id = $1 AND published_at IS NULL
+`
+
+type MarkOutboxEventRetryParams struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	LastError     *string            `db:"last_error" json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `db:"next_attempt_at" json:"next_attempt_at"`
+}
+
+func (q *Queries) MarkOutboxEventRetry(ctx context.Context, arg MarkOutboxEventRetryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markOutboxEventRetry, arg.ID, arg.LastError, arg.NextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
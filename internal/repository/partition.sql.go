@@ -0,0 +1,314 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: partition.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addEntriesArchivedAccountTotal = `-- name: AddEntriesArchivedAccountTotal :exec
+INSERT INTO entries_archived_account_totals (account_id, net_micros)
+VALUES ($1, $2)
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = entries_archived_account_totals.net_micros + EXCLUDED.net_micros,
+  updated_at = NOW()
+`
+
+type AddEntriesArchivedAccountTotalParams struct {
+	AccountID pgtype.UUID `db:"account_id" json:"account_id"`
+	NetMicros int64       `db:"net_micros" json:"net_micros"`
+}
+
+func (q *Queries) AddEntriesArchivedAccountTotal(ctx context.Context, arg AddEntriesArchivedAccountTotalParams) error {
+	_, err := q.db.Exec(ctx, addEntriesArchivedAccountTotal, arg.AccountID, arg.NetMicros)
+	return err
+}
+
+const claimEntriesArchive = `-- name: ClaimEntriesArchive :one
+INSERT INTO entries_archives (id, partition_name, period_start, period_end, claimed_at)
+VALUES ($1, $2, $3, $4, NOW())
+ON CONFLICT (partition_name) DO UPDATE SET claimed_at = NOW()
+WHERE entries_archives.status = 'EXPORTING' AND entries_archives.claimed_at < $5
+RETURNING id, partition_name, period_start, period_end, status, row_count, file_path, manifest_path, sha256, claimed_at, archived_at
+`
+
+type ClaimEntriesArchiveParams struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	PartitionName string             `db:"partition_name" json:"partition_name"`
+	PeriodStart   pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd     pgtype.Timestamptz `db:"period_end" json:"period_end"`
+	StaleBefore   pgtype.Timestamptz `db:"stale_before" json:"stale_before"`
+}
+
+// Claims a partition for export. A claim left EXPORTING since before
+// stale_before is taken over.
+func (q *Queries) ClaimEntriesArchive(ctx context.Context, arg ClaimEntriesArchiveParams) (EntriesArchive, error) {
+	row := q.db.QueryRow(ctx, claimEntriesArchive,
+		arg.ID,
+		arg.PartitionName,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.StaleBefore,
+	)
+	var i EntriesArchive
+	err := row.Scan(
+		&i.ID,
+		&i.PartitionName,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.Status,
+		&i.RowCount,
+		&i.FilePath,
+		&i.ManifestPath,
+		&i.Sha256,
+		&i.ClaimedAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const completeEntriesArchive = `-- name: CompleteEntriesArchive :one
+UPDATE entries_archives
+SET status = 'ARCHIVED',
+    row_count = $2,
+    file_path = $3,
+    manifest_path = $4,
+    sha256 = $5,
+    archived_at = NOW()
+WHERE id = $1 AND status = 'EXPORTING'
+RETURNING id, partition_name, period_start, period_end, status, row_count, file_path, manifest_path, sha256, claimed_at, archived_at
+`
+
+type CompleteEntriesArchiveParams struct {
+	ID           pgtype.UUID `db:"id" json:"id"`
+	RowCount     int64       `db:"row_count" json:"row_count"`
+	FilePath     *string     `db:"file_path" json:"file_path"`
+	ManifestPath *string     `db:"manifest_path" json:"manifest_path"`
+	Sha256       *string     `db:"sha256" json:"sha256"`
+}
+
+func (q *Queries) CompleteEntriesArchive(ctx context.Context, arg CompleteEntriesArchiveParams) (EntriesArchive, error) {
+	row := q.db.QueryRow(ctx, completeEntriesArchive,
+		arg.ID,
+		arg.RowCount,
+		arg.FilePath,
+		arg.ManifestPath,
+		arg.Sha256,
+	)
+	var i EntriesArchive
+	err := row.Scan(
+		&i.ID,
+		&i.PartitionName,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.Status,
+		&i.RowCount,
+		&i.FilePath,
+		&i.ManifestPath,
+		&i.Sha256,
+		&i.ClaimedAt,
+		&i.ArchivedAt,
+	)
+	return i, err
+}
+
+const createEntriesArchiveCurrencyTotal = `-- name: CreateEntriesArchiveCurrencyTotal :exec
+INSERT INTO entries_archive_currency_totals (archive_id, currency, entry_count, debit_micros, credit_micros)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type CreateEntriesArchiveCurrencyTotalParams struct {
+	ArchiveID    pgtype.UUID `db:"archive_id" json:"archive_id"`
+	Currency     string      `db:"currency" json:"currency"`
+	EntryCount   int64       `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64       `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64       `db:"credit_micros" json:"credit_micros"`
+}
+
+func (q *Queries) CreateEntriesArchiveCurrencyTotal(ctx context.Context, arg CreateEntriesArchiveCurrencyTotalParams) error {
+	_, err := q.db.Exec(ctx, createEntriesArchiveCurrencyTotal,
+		arg.ArchiveID,
+		arg.Currency,
+		arg.EntryCount,
+		arg.DebitMicros,
+		arg.CreditMicros,
+	)
+	return err
+}
+
+const createEntriesPartition = `-- name: CreateEntriesPartition :one
+SELECT create_entries_partition($1::date)::bigint AS moved
+`
+
+func (q *Queries) CreateEntriesPartition(ctx context.Context, month pgtype.Date) (int64, error) {
+	row := q.db.QueryRow(ctx, createEntriesPartition, month)
+	var moved int64
+	err := row.Scan(&moved)
+	return moved, err
+}
+
+const dropEntriesPartition = `-- name: DropEntriesPartition :exec
+SELECT drop_entries_partition($1::date, $2::bigint)
+`
+
+type DropEntriesPartitionParams struct {
+	Month        pgtype.Date `db:"month" json:"month"`
+	ArchivedRows int64       `db:"archived_rows" json:"archived_rows"`
+}
+
+func (q *Queries) DropEntriesPartition(ctx context.Context, arg DropEntriesPartitionParams) error {
+	_, err := q.db.Exec(ctx, dropEntriesPartition, arg.Month, arg.ArchivedRows)
+	return err
+}
+
+const listArchivedPartitionNames = `-- name: ListArchivedPartitionNames :many
+SELECT partition_name FROM entries_archives WHERE status = 'ARCHIVED' ORDER BY partition_name
+`
+
+func (q *Queries) ListArchivedPartitionNames(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listArchivedPartitionNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var partition_name string
+		if err := rows.Scan(&partition_name); err != nil {
+			return nil, err
+		}
+		items = append(items, partition_name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesDefaultMonths = `-- name: ListEntriesDefaultMonths :many
+SELECT month::date AS month FROM list_entries_default_months() AS month
+`
+
+func (q *Queries) ListEntriesDefaultMonths(ctx context.Context) ([]pgtype.Date, error) {
+	rows, err := q.db.Query(ctx, listEntriesDefaultMonths)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Date
+	for rows.Next() {
+		var month pgtype.Date
+		if err := rows.Scan(&month); err != nil {
+			return nil, err
+		}
+		items = append(items, month)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesForArchive = `-- name: ListEntriesForArchive :many
+SELECT
+  e.id,
+  e.transaction_id,
+  e.account_id,
+  a.currency,
+  e.amount,
+  e.direction,
+  e.created_at
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= $1 AND e.created_at < $2
+  AND (e.created_at, e.id) > ($3::timestamptz, $4::uuid)
+ORDER BY e.created_at, e.id
+LIMIT $5
+`
+
+type ListEntriesForArchiveParams struct {
+	PeriodStart    pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd      pgtype.Timestamptz `db:"period_end" json:"period_end"`
+	AfterCreatedAt pgtype.Timestamptz `db:"after_created_at" json:"after_created_at"`
+	AfterID        pgtype.UUID        `db:"after_id" json:"after_id"`
+	RowLimit       int32              `db:"row_limit" json:"row_limit"`
+}
+
+type ListEntriesForArchiveRow struct {
+	ID            pgtype.UUID        `db:"id" json:"id"`
+	TransactionID pgtype.UUID        `db:"transaction_id" json:"transaction_id"`
+	AccountID     pgtype.UUID        `db:"account_id" json:"account_id"`
+	Currency      string             `db:"currency" json:"currency"`
+	Amount        int64              `db:"amount" json:"amount"`
+	Direction     string             `db:"direction" json:"direction"`
+	CreatedAt     pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+// One page of the entries created in [period_start, period_end), in
+// (created_at, id) order after the given key.
+func (q *Queries) ListEntriesForArchive(ctx context.Context, arg ListEntriesForArchiveParams) ([]ListEntriesForArchiveRow, error) {
+	rows, err := q.db.Query(ctx, listEntriesForArchive,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntriesForArchiveRow
+	for rows.Next() {
+		var i ListEntriesForArchiveRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.AccountID,
+			&i.Currency,
+			&i.Amount,
+			&i.Direction,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesPartitions = `-- name: ListEntriesPartitions :many
+SELECT c.relname::text AS partition_name
+FROM pg_catalog.pg_inherits i
+INNER JOIN pg_catalog.pg_class c ON c.oid = i.inhrelid
+WHERE i.inhparent = 'entries'::regclass
+ORDER BY c.relname
+`
+
+func (q *Queries) ListEntriesPartitions(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listEntriesPartitions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var partition_name string
+		if err := rows.Scan(&partition_name); err != nil {
+			return nil, err
+		}
+		items = append(items, partition_name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
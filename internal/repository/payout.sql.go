@@ -11,6 +11,20 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimPayoutAttempt = `-- name: ClaimPayoutAttempt :one
+UPDATE payouts
+SET status = 'PROCESSING', gateway_ref = NULL, attempts = attempts + 1, updated_at = NOW()
+WHERE id = $1 AND status = 'PENDING'
+RETURNING attempts
+`
+
+func (q *Queries) ClaimPayoutAttempt(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, claimPayoutAttempt, id)
+	var attempts int32
+	err := row.Scan(&attempts)
+	return attempts, err
+}
+
 const countPayoutsByStatus = `-- name: CountPayoutsByStatus :one
 SELECT COUNT(*)::bigint FROM payouts
 WHERE status = $1
@@ -24,7 +38,7 @@ func (q *Queries) CountPayoutsByStatus(ctx context.Context, status string) (int6
 }
 
 const getPayout = `-- name: GetPayout :one
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts WHERE id = $1
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts WHERE id = $1
 `
 
 func (q *Queries) GetPayout(ctx context.Context, id pgtype.UUID) (Payout, error) {
@@ -40,12 +54,47 @@ func (q *Queries) GetPayout(ctx context.Context, id pgtype.UUID) (Payout, error)
 		&i.GatewayRef,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
+	)
+	return i, err
+}
+
+const getPayoutByGatewayRef = `-- name: GetPayoutByGatewayRef :one
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts WHERE gateway_ref = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetPayoutByGatewayRef(ctx context.Context, gatewayRef *string) (Payout, error) {
+	row := q.db.QueryRow(ctx, getPayoutByGatewayRef, gatewayRef)
+	var i Payout
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.AccountID,
+		&i.AmountMicros,
+		&i.Currency,
+		&i.Status,
+		&i.GatewayRef,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
 	)
 	return i, err
 }
 
 const getPayoutByTransactionID = `-- name: GetPayoutByTransactionID :one
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts WHERE transaction_id = $1
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts WHERE transaction_id = $1
 `
 
 func (q *Queries) GetPayoutByTransactionID(ctx context.Context, transactionID pgtype.UUID) (Payout, error) {
@@ -61,12 +110,18 @@ func (q *Queries) GetPayoutByTransactionID(ctx context.Context, transactionID pg
 		&i.GatewayRef,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
 	)
 	return i, err
 }
 
 const getPayoutForUpdate = `-- name: GetPayoutForUpdate :one
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts WHERE id = $1 FOR UPDATE
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts WHERE id = $1 FOR UPDATE
 `
 
 func (q *Queries) GetPayoutForUpdate(ctx context.Context, id pgtype.UUID) (Payout, error) {
@@ -82,12 +137,66 @@ func (q *Queries) GetPayoutForUpdate(ctx context.Context, id pgtype.UUID) (Payou
 		&i.GatewayRef,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
 	)
 	return i, err
 }
 
+const getPayoutsAwaitingApproval = `-- name: GetPayoutsAwaitingApproval :many
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts
+WHERE status = 'AWAITING_APPROVAL'
+ORDER BY created_at ASC
+LIMIT $1 OFFSET $2
+`
+
+type GetPayoutsAwaitingApprovalParams struct {
+	Limit  int32 `db:"limit" json:"limit"`
+	Offset int32 `db:"offset" json:"offset"`
+}
+
+func (q *Queries) GetPayoutsAwaitingApproval(ctx context.Context, arg GetPayoutsAwaitingApprovalParams) ([]Payout, error) {
+	rows, err := q.db.Query(ctx, getPayoutsAwaitingApproval, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payout
+	for rows.Next() {
+		var i Payout
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.AccountID,
+			&i.AmountMicros,
+			&i.Currency,
+			&i.Status,
+			&i.GatewayRef,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.BeneficiaryID,
+			&i.LimitReservation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPayoutsByStatus = `-- name: GetPayoutsByStatus :many
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts
 WHERE status = $1
 ORDER BY updated_at DESC, created_at DESC
 LIMIT $2 OFFSET $3
@@ -118,6 +227,12 @@ func (q *Queries) GetPayoutsByStatus(ctx context.Context, arg GetPayoutsByStatus
 			&i.GatewayRef,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Attempts,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.BeneficiaryID,
+			&i.LimitReservation,
 		); err != nil {
 			return nil, err
 		}
@@ -129,8 +244,37 @@ func (q *Queries) GetPayoutsByStatus(ctx context.Context, arg GetPayoutsByStatus
 	return items, nil
 }
 
+const getPendingPayoutForUpdate = `-- name: GetPendingPayoutForUpdate :one
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts
+WHERE id = $1 AND status = 'PENDING'
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetPendingPayoutForUpdate(ctx context.Context, id pgtype.UUID) (Payout, error) {
+	row := q.db.QueryRow(ctx, getPendingPayoutForUpdate, id)
+	var i Payout
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.AccountID,
+		&i.AmountMicros,
+		&i.Currency,
+		&i.Status,
+		&i.GatewayRef,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
+	)
+	return i, err
+}
+
 const getPendingPayouts = `-- name: GetPendingPayouts :many
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts 
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts 
 WHERE status = 'PENDING' 
 ORDER BY created_at ASC
 FOR UPDATE SKIP LOCKED 
@@ -156,6 +300,12 @@ func (q *Queries) GetPendingPayouts(ctx context.Context, limit int32) ([]Payout,
 			&i.GatewayRef,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Attempts,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.BeneficiaryID,
+			&i.LimitReservation,
 		); err != nil {
 			return nil, err
 		}
@@ -168,7 +318,7 @@ func (q *Queries) GetPendingPayouts(ctx context.Context, limit int32) ([]Payout,
 }
 
 const getStaleProcessingPayouts = `-- name: GetStaleProcessingPayouts :many
-SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at FROM payouts
+SELECT id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation FROM payouts
 WHERE status = 'PROCESSING' AND updated_at < $1
 ORDER BY updated_at ASC
 FOR UPDATE SKIP LOCKED
@@ -199,6 +349,12 @@ func (q *Queries) GetStaleProcessingPayouts(ctx context.Context, arg GetStalePro
 			&i.GatewayRef,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Attempts,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.BeneficiaryID,
+			&i.LimitReservation,
 		); err != nil {
 			return nil, err
 		}
@@ -211,18 +367,21 @@ func (q *Queries) GetStaleProcessingPayouts(ctx context.Context, arg GetStalePro
 }
 
 const insertPayout = `-- name: InsertPayout :one
-INSERT INTO payouts (id, transaction_id, account_id, amount_micros, currency, status, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
-RETURNING id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at
+INSERT INTO payouts (id, transaction_id, account_id, amount_micros, currency, status, requested_by, beneficiary_id, limit_reservation, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+RETURNING id, transaction_id, account_id, amount_micros, currency, status, gateway_ref, created_at, updated_at, attempts, requested_by, reviewed_by, reviewed_at, beneficiary_id, limit_reservation
 `
 
 type InsertPayoutParams struct {
-	ID            pgtype.UUID `db:"id" json:"id"`
-	TransactionID pgtype.UUID `db:"transaction_id" json:"transaction_id"`
-	AccountID     pgtype.UUID `db:"account_id" json:"account_id"`
-	AmountMicros  int64       `db:"amount_micros" json:"amount_micros"`
-	Currency      string      `db:"currency" json:"currency"`
-	Status        string      `db:"status" json:"status"`
+	ID               pgtype.UUID `db:"id" json:"id"`
+	TransactionID    pgtype.UUID `db:"transaction_id" json:"transaction_id"`
+	AccountID        pgtype.UUID `db:"account_id" json:"account_id"`
+	AmountMicros     int64       `db:"amount_micros" json:"amount_micros"`
+	Currency         string      `db:"currency" json:"currency"`
+	Status           string      `db:"status" json:"status"`
+	RequestedBy      pgtype.UUID `db:"requested_by" json:"requested_by"`
+	BeneficiaryID    pgtype.UUID `db:"beneficiary_id" json:"beneficiary_id"`
+	LimitReservation []byte      `db:"limit_reservation" json:"limit_reservation"`
 }
 
 func (q *Queries) InsertPayout(ctx context.Context, arg InsertPayoutParams) (Payout, error) {
@@ -233,6 +392,9 @@ func (q *Queries) InsertPayout(ctx context.Context, arg InsertPayoutParams) (Pay
 		arg.AmountMicros,
 		arg.Currency,
 		arg.Status,
+		arg.RequestedBy,
+		arg.BeneficiaryID,
+		arg.LimitReservation,
 	)
 	var i Payout
 	err := row.Scan(
@@ -245,10 +407,133 @@ func (q *Queries) InsertPayout(ctx context.Context, arg InsertPayoutParams) (Pay
 		&i.GatewayRef,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Attempts,
+		&i.RequestedBy,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.BeneficiaryID,
+		&i.LimitReservation,
 	)
 	return i, err
 }
 
+const markPayoutSubmitted = `-- name: MarkPayoutSubmitted :execrows
+UPDATE payouts
+SET status = 'SUBMITTED', gateway_ref = $1, updated_at = NOW()
+WHERE id = $2 AND status = 'PROCESSING'
+`
+
+type MarkPayoutSubmittedParams struct {
+	GatewayRef *string     `db:"gateway_ref" json:"gateway_ref"`
+	ID         pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) MarkPayoutSubmitted(ctx context.Context, arg MarkPayoutSubmittedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markPayoutSubmitted, arg.GatewayRef, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const notifyChannel = `-- name: NotifyChannel :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyChannelParams struct {
+	Channel string `db:"channel" json:"channel"`
+	Payload string `db:"payload" json:"payload"`
+}
+
+func (q *Queries) NotifyChannel(ctx context.Context, arg NotifyChannelParams) error {
+	_, err := q.db.Exec(ctx, notifyChannel, arg.Channel, arg.Payload)
+	return err
+}
+
+const releaseScreenedPayout = `-- name: ReleaseScreenedPayout :execrows
+UPDATE payouts
+SET status = $1, updated_at = NOW()
+WHERE id = $2 AND status = 'SCREENING_HOLD'
+`
+
+type ReleaseScreenedPayoutParams struct {
+	Status string      `db:"status" json:"status"`
+	ID     pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) ReleaseScreenedPayout(ctx context.Context, arg ReleaseScreenedPayoutParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseScreenedPayout, arg.Status, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reopenSubmittedPayout = `-- name: ReopenSubmittedPayout :execrows
+UPDATE payouts
+SET status = 'PROCESSING', updated_at = NOW()
+WHERE id = $1 AND status = 'SUBMITTED'
+`
+
+func (q *Queries) ReopenSubmittedPayout(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, reopenSubmittedPayout, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reviewPayout = `-- name: ReviewPayout :execrows
+UPDATE payouts
+SET status = $1, reviewed_by = $2, reviewed_at = NOW(), updated_at = NOW()
+WHERE id = $3 AND status = 'AWAITING_APPROVAL'
+`
+
+type ReviewPayoutParams struct {
+	Status     string      `db:"status" json:"status"`
+	ReviewedBy pgtype.UUID `db:"reviewed_by" json:"reviewed_by"`
+	ID         pgtype.UUID `db:"id" json:"id"`
+}
+
+func (q *Queries) ReviewPayout(ctx context.Context, arg ReviewPayoutParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reviewPayout, arg.Status, arg.ReviewedBy, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const takePayoutLimitReservation = `-- name: TakePayoutLimitReservation :one
+UPDATE payouts p
+SET limit_reservation = NULL
+FROM (SELECT q.id, q.limit_reservation FROM payouts q WHERE q.id = $1 FOR UPDATE) old
+WHERE p.id = old.id AND old.limit_reservation IS NOT NULL
+RETURNING old.limit_reservation
+`
+
+// Clears the recorded reservation and returns it, so each payout releases
+// its limit usage at most once.
+func (q *Queries) TakePayoutLimitReservation(ctx context.Context, id pgtype.UUID) ([]byte, error) {
+	row := q.db.QueryRow(ctx, takePayoutLimitReservation, id)
+	var limit_reservation []byte
+	err := row.Scan(&limit_reservation)
+	return limit_reservation, err
+}
+
+const touchPayout = `-- name: TouchPayout :execrows
+UPDATE payouts
+SET updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TouchPayout(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, touchPayout, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updatePayoutStatus = `-- name: UpdatePayoutStatus :execrows
 UPDATE payouts
 SET status = $1, gateway_ref = $2, updated_at = NOW()
@@ -7,8 +7,280 @@ package repository
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const abandonStaleReconciliationRuns = `-- name: AbandonStaleReconciliationRuns :execrows
+UPDATE reconciliation_runs
+SET status = 'ERROR', finished_at = NOW(), error = 'run did not finish'
+WHERE status = 'RUNNING' AND started_at < $1
+`
+
+func (q *Queries) AbandonStaleReconciliationRuns(ctx context.Context, startedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, abandonStaleReconciliationRuns, startedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addLedgerAccountTotals = `-- name: AddLedgerAccountTotals :exec
+INSERT INTO ledger_account_totals (account_id, net_micros)
+SELECT
+  account_id,
+  SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END)::bigint
+FROM entries
+WHERE created_at >= $1 AND created_at < $2
+GROUP BY account_id
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = ledger_account_totals.net_micros + EXCLUDED.net_micros,
+  updated_at = NOW()
+`
+
+type AddLedgerAccountTotalsParams struct {
+	DayStart pgtype.Timestamptz `db:"day_start" json:"day_start"`
+	DayEnd   pgtype.Timestamptz `db:"day_end" json:"day_end"`
+}
+
+// Adds the net of the entries created in [day_start, day_end) to each
+// account's checkpointed total.
+func (q *Queries) AddLedgerAccountTotals(ctx context.Context, arg AddLedgerAccountTotalsParams) error {
+	_, err := q.db.Exec(ctx, addLedgerAccountTotals, arg.DayStart, arg.DayEnd)
+	return err
+}
+
+const advanceLedgerCheckpoint = `-- name: AdvanceLedgerCheckpoint :execrows
+UPDATE ledger_checkpoint
+SET checkpointed_until = $1, updated_at = NOW()
+WHERE id AND checkpointed_until = $2
+`
+
+type AdvanceLedgerCheckpointParams struct {
+	NextDay pgtype.Date `db:"next_day" json:"next_day"`
+	Day     pgtype.Date `db:"day" json:"day"`
+}
+
+func (q *Queries) AdvanceLedgerCheckpoint(ctx context.Context, arg AdvanceLedgerCheckpointParams) (int64, error) {
+	result, err := q.db.Exec(ctx, advanceLedgerCheckpoint, arg.NextDay, arg.Day)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createLedgerMonthSeal = `-- name: CreateLedgerMonthSeal :exec
+INSERT INTO ledger_month_seals (month, entry_count, checksum)
+VALUES ($1, $2, $3)
+ON CONFLICT (month) DO NOTHING
+`
+
+type CreateLedgerMonthSealParams struct {
+	Month      pgtype.Date `db:"month" json:"month"`
+	EntryCount int64       `db:"entry_count" json:"entry_count"`
+	Checksum   string      `db:"checksum" json:"checksum"`
+}
+
+func (q *Queries) CreateLedgerMonthSeal(ctx context.Context, arg CreateLedgerMonthSealParams) error {
+	_, err := q.db.Exec(ctx, createLedgerMonthSeal, arg.Month, arg.EntryCount, arg.Checksum)
+	return err
+}
+
+const createReconciliationRun = `-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (id, trigger, scope, triggered_by, started_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, trigger, scope, status, triggered_by, started_at, finished_at, currency_totals, findings, finding_count, error
+`
+
+type CreateReconciliationRunParams struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	Trigger     string             `db:"trigger" json:"trigger"`
+	Scope       string             `db:"scope" json:"scope"`
+	TriggeredBy pgtype.UUID        `db:"triggered_by" json:"triggered_by"`
+	StartedAt   pgtype.Timestamptz `db:"started_at" json:"started_at"`
+}
+
+func (q *Queries) CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, createReconciliationRun,
+		arg.ID,
+		arg.Trigger,
+		arg.Scope,
+		arg.TriggeredBy,
+		arg.StartedAt,
+	)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Trigger,
+		&i.Scope,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CurrencyTotals,
+		&i.Findings,
+		&i.FindingCount,
+		&i.Error,
+	)
+	return i, err
+}
+
+const deleteLedgerDayTotals = `-- name: DeleteLedgerDayTotals :exec
+DELETE FROM ledger_day_totals WHERE day = $1
+`
+
+func (q *Queries) DeleteLedgerDayTotals(ctx context.Context, day pgtype.Date) error {
+	_, err := q.db.Exec(ctx, deleteLedgerDayTotals, day)
+	return err
+}
+
+const deleteLedgerDirtyDay = `-- name: DeleteLedgerDirtyDay :exec
+DELETE FROM ledger_dirty_days WHERE day = $1
+`
+
+func (q *Queries) DeleteLedgerDirtyDay(ctx context.Context, day pgtype.Date) error {
+	_, err := q.db.Exec(ctx, deleteLedgerDirtyDay, day)
+	return err
+}
+
+const finishReconciliationRun = `-- name: FinishReconciliationRun :one
+UPDATE reconciliation_runs
+SET status = $2,
+    finished_at = NOW(),
+    currency_totals = $3,
+    findings = $4,
+    finding_count = $5,
+    error = $6
+WHERE id = $1 AND status = 'RUNNING'
+RETURNING id, trigger, scope, status, triggered_by, started_at, finished_at, currency_totals, findings, finding_count, error
+`
+
+type FinishReconciliationRunParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	Status         string      `db:"status" json:"status"`
+	CurrencyTotals []byte      `db:"currency_totals" json:"currency_totals"`
+	Findings       []byte      `db:"findings" json:"findings"`
+	FindingCount   int32       `db:"finding_count" json:"finding_count"`
+	Error          *string     `db:"error" json:"error"`
+}
+
+func (q *Queries) FinishReconciliationRun(ctx context.Context, arg FinishReconciliationRunParams) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, finishReconciliationRun,
+		arg.ID,
+		arg.Status,
+		arg.CurrencyTotals,
+		arg.Findings,
+		arg.FindingCount,
+		arg.Error,
+	)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Trigger,
+		&i.Scope,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CurrencyTotals,
+		&i.Findings,
+		&i.FindingCount,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getCheckpointedCurrencyTotals = `-- name: GetCheckpointedCurrencyTotals :many
+SELECT
+  currency,
+  SUM(entry_count)::bigint AS entry_count,
+  SUM(debit_micros)::bigint AS debit_micros,
+  SUM(credit_micros)::bigint AS credit_micros
+FROM ledger_day_totals
+GROUP BY currency
+ORDER BY currency
+`
+
+type GetCheckpointedCurrencyTotalsRow struct {
+	Currency     string `db:"currency" json:"currency"`
+	EntryCount   int64  `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64  `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64  `db:"credit_micros" json:"credit_micros"`
+}
+
+func (q *Queries) GetCheckpointedCurrencyTotals(ctx context.Context) ([]GetCheckpointedCurrencyTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getCheckpointedCurrencyTotals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCheckpointedCurrencyTotalsRow
+	for rows.Next() {
+		var i GetCheckpointedCurrencyTotalsRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.EntryCount,
+			&i.DebitMicros,
+			&i.CreditMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEarliestEntryTime = `-- name: GetEarliestEntryTime :one
+SELECT MIN(created_at)::timestamptz AS earliest FROM entries
+`
+
+func (q *Queries) GetEarliestEntryTime(ctx context.Context) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getEarliestEntryTime)
+	var earliest pgtype.Timestamptz
+	err := row.Scan(&earliest)
+	return earliest, err
+}
+
+const getLeastRecentlyVerifiedMonthSeal = `-- name: GetLeastRecentlyVerifiedMonthSeal :one
+SELECT month, entry_count, checksum, status, sealed_at, verified_at FROM ledger_month_seals
+WHERE status <> 'ARCHIVED'
+ORDER BY verified_at ASC, month ASC
+LIMIT 1
+`
+
+func (q *Queries) GetLeastRecentlyVerifiedMonthSeal(ctx context.Context) (LedgerMonthSeal, error) {
+	row := q.db.QueryRow(ctx, getLeastRecentlyVerifiedMonthSeal)
+	var i LedgerMonthSeal
+	err := row.Scan(
+		&i.Month,
+		&i.EntryCount,
+		&i.Checksum,
+		&i.Status,
+		&i.SealedAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getLedgerCheckpoint = `-- name: GetLedgerCheckpoint :one
+SELECT id, started_from, checkpointed_until, updated_at FROM ledger_checkpoint WHERE id
+`
+
+func (q *Queries) GetLedgerCheckpoint(ctx context.Context) (LedgerCheckpoint, error) {
+	row := q.db.QueryRow(ctx, getLedgerCheckpoint)
+	var i LedgerCheckpoint
+	err := row.Scan(
+		&i.ID,
+		&i.StartedFrom,
+		&i.CheckpointedUntil,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getLedgerCurrencyImbalances = `-- name: GetLedgerCurrencyImbalances :many
 SELECT
   a.currency,
@@ -56,6 +328,126 @@ func (q *Queries) GetLedgerCurrencyImbalances(ctx context.Context) ([]GetLedgerC
 	return items, nil
 }
 
+const getLedgerCurrencyTotals = `-- name: GetLedgerCurrencyTotals :many
+SELECT
+  t.currency,
+  SUM(t.entry_count)::bigint AS entry_count,
+  SUM(t.debit_micros)::bigint AS debit_micros,
+  SUM(t.credit_micros)::bigint AS credit_micros
+FROM (
+  SELECT
+    a.currency,
+    COUNT(*) AS entry_count,
+    COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0) AS debit_micros,
+    COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0) AS credit_micros
+  FROM entries e
+  INNER JOIN accounts a ON a.id = e.account_id
+  GROUP BY a.currency
+  UNION ALL
+  SELECT currency, entry_count, debit_micros, credit_micros
+  FROM entries_archive_currency_totals
+) t
+GROUP BY t.currency
+ORDER BY t.currency
+`
+
+type GetLedgerCurrencyTotalsRow struct {
+	Currency     string `db:"currency" json:"currency"`
+	EntryCount   int64  `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64  `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64  `db:"credit_micros" json:"credit_micros"`
+}
+
+// Archived partitions are counted from the totals kept when they were
+// archived.
+func (q *Queries) GetLedgerCurrencyTotals(ctx context.Context) ([]GetLedgerCurrencyTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getLedgerCurrencyTotals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLedgerCurrencyTotalsRow
+	for rows.Next() {
+		var i GetLedgerCurrencyTotalsRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.EntryCount,
+			&i.DebitMicros,
+			&i.CreditMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLedgerCurrencyTotalsSince = `-- name: GetLedgerCurrencyTotalsSince :many
+SELECT
+  a.currency,
+  COUNT(*)::bigint AS entry_count,
+  COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::bigint AS debit_micros,
+  COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::bigint AS credit_micros
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= $1
+GROUP BY a.currency
+ORDER BY a.currency
+`
+
+type GetLedgerCurrencyTotalsSinceRow struct {
+	Currency     string `db:"currency" json:"currency"`
+	EntryCount   int64  `db:"entry_count" json:"entry_count"`
+	DebitMicros  int64  `db:"debit_micros" json:"debit_micros"`
+	CreditMicros int64  `db:"credit_micros" json:"credit_micros"`
+}
+
+func (q *Queries) GetLedgerCurrencyTotalsSince(ctx context.Context, createdAt pgtype.Timestamptz) ([]GetLedgerCurrencyTotalsSinceRow, error) {
+	rows, err := q.db.Query(ctx, getLedgerCurrencyTotalsSince, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLedgerCurrencyTotalsSinceRow
+	for rows.Next() {
+		var i GetLedgerCurrencyTotalsSinceRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.EntryCount,
+			&i.DebitMicros,
+			&i.CreditMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLedgerMonthSeal = `-- name: GetLedgerMonthSeal :one
+SELECT month, entry_count, checksum, status, sealed_at, verified_at FROM ledger_month_seals WHERE month = $1
+`
+
+func (q *Queries) GetLedgerMonthSeal(ctx context.Context, month pgtype.Date) (LedgerMonthSeal, error) {
+	row := q.db.QueryRow(ctx, getLedgerMonthSeal, month)
+	var i LedgerMonthSeal
+	err := row.Scan(
+		&i.Month,
+		&i.EntryCount,
+		&i.Checksum,
+		&i.Status,
+		&i.SealedAt,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
 const getLedgerNet = `-- name: GetLedgerNet :one
 SELECT COALESCE(SUM(
   CASE
@@ -73,3 +465,655 @@ func (q *Queries) GetLedgerNet(ctx context.Context) (int64, error) {
 	err := row.Scan(&net_amount)
 	return net_amount, err
 }
+
+const getLedgerPeriodChecksum = `-- name: GetLedgerPeriodChecksum :one
+SELECT
+  COUNT(*)::bigint AS entry_count,
+  COALESCE(SUM(
+    ('x' || substr(md5(
+      e.id::text || '|' || e.transaction_id::text || '|' || e.account_id::text || '|' ||
+      e.amount::text || '|' || e.direction || '|' ||
+      (EXTRACT(EPOCH FROM e.created_at) * 1000000)::bigint::text
+    ), 1, 15))::bit(60)::bigint::numeric
+  ), 0)::text AS checksum
+FROM entries e
+WHERE e.created_at >= $1 AND e.created_at < $2
+`
+
+type GetLedgerPeriodChecksumParams struct {
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd   pgtype.Timestamptz `db:"period_end" json:"period_end"`
+}
+
+type GetLedgerPeriodChecksumRow struct {
+	EntryCount int64  `db:"entry_count" json:"entry_count"`
+	Checksum   string `db:"checksum" json:"checksum"`
+}
+
+// An order-independent checksum over the entries created in
+// [period_start, period_end): the sum of a 60-bit hash of each entry.
+func (q *Queries) GetLedgerPeriodChecksum(ctx context.Context, arg GetLedgerPeriodChecksumParams) (GetLedgerPeriodChecksumRow, error) {
+	row := q.db.QueryRow(ctx, getLedgerPeriodChecksum, arg.PeriodStart, arg.PeriodEnd)
+	var i GetLedgerPeriodChecksumRow
+	err := row.Scan(&i.EntryCount, &i.Checksum)
+	return i, err
+}
+
+const getReconciliationRun = `-- name: GetReconciliationRun :one
+SELECT id, trigger, scope, status, triggered_by, started_at, finished_at, currency_totals, findings, finding_count, error FROM reconciliation_runs WHERE id = $1
+`
+
+func (q *Queries) GetReconciliationRun(ctx context.Context, id pgtype.UUID) (ReconciliationRun, error) {
+	row := q.db.QueryRow(ctx, getReconciliationRun, id)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Trigger,
+		&i.Scope,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.CurrencyTotals,
+		&i.Findings,
+		&i.FindingCount,
+		&i.Error,
+	)
+	return i, err
+}
+
+const initLedgerCheckpoint = `-- name: InitLedgerCheckpoint :exec
+INSERT INTO ledger_checkpoint (started_from, checkpointed_until)
+VALUES ($1, $1)
+ON CONFLICT (id) DO NOTHING
+`
+
+func (q *Queries) InitLedgerCheckpoint(ctx context.Context, day pgtype.Date) error {
+	_, err := q.db.Exec(ctx, initLedgerCheckpoint, day)
+	return err
+}
+
+const insertLedgerDayTotals = `-- name: InsertLedgerDayTotals :exec
+INSERT INTO ledger_day_totals (day, currency, entry_count, debit_micros, credit_micros)
+SELECT
+  $1::date,
+  a.currency,
+  COUNT(*)::bigint,
+  COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::bigint,
+  COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::bigint
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.created_at >= $2 AND e.created_at < $3
+GROUP BY a.currency
+`
+
+type InsertLedgerDayTotalsParams struct {
+	Day      pgtype.Date        `db:"day" json:"day"`
+	DayStart pgtype.Timestamptz `db:"day_start" json:"day_start"`
+	DayEnd   pgtype.Timestamptz `db:"day_end" json:"day_end"`
+}
+
+// Totals per currency for the entries created in [day_start, day_end).
+func (q *Queries) InsertLedgerDayTotals(ctx context.Context, arg InsertLedgerDayTotalsParams) error {
+	_, err := q.db.Exec(ctx, insertLedgerDayTotals, arg.Day, arg.DayStart, arg.DayEnd)
+	return err
+}
+
+const listAccountBalanceDrifts = `-- name: ListAccountBalanceDrifts :many
+SELECT
+  a.id,
+  a.currency,
+  a.balance,
+  (COALESCE(e.net_amount, 0) + COALESCE(x.net_micros, 0))::bigint AS entries_net
+FROM accounts a
+LEFT JOIN (
+  SELECT
+    account_id,
+    SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END) AS net_amount
+  FROM entries
+  GROUP BY account_id
+) e ON e.account_id = a.id
+LEFT JOIN entries_archived_account_totals x ON x.account_id = a.id
+WHERE a.balance <> COALESCE(e.net_amount, 0) + COALESCE(x.net_micros, 0)
+`
+
+type ListAccountBalanceDriftsRow struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	Currency   string      `db:"currency" json:"currency"`
+	Balance    int64       `db:"balance" json:"balance"`
+	EntriesNet int64       `db:"entries_net" json:"entries_net"`
+}
+
+// Accounts whose stored balance differs from the net of their own entries,
+// archived entries included.
+func (q *Queries) ListAccountBalanceDrifts(ctx context.Context) ([]ListAccountBalanceDriftsRow, error) {
+	rows, err := q.db.Query(ctx, listAccountBalanceDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountBalanceDriftsRow
+	for rows.Next() {
+		var i ListAccountBalanceDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesNet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountBalanceDriftsSince = `-- name: ListAccountBalanceDriftsSince :many
+SELECT
+  a.id,
+  a.currency,
+  a.balance,
+  (COALESCE(t.net_micros, 0) + COALESCE(e.net_amount, 0))::bigint AS entries_net
+FROM accounts a
+LEFT JOIN ledger_account_totals t ON t.account_id = a.id
+LEFT JOIN (
+  SELECT
+    s.account_id,
+    SUM(CASE WHEN s.direction = 'credit' THEN s.amount ELSE -s.amount END) AS net_amount
+  FROM entries s
+  WHERE s.created_at >= $1
+  GROUP BY s.account_id
+) e ON e.account_id = a.id
+WHERE a.balance <> COALESCE(t.net_micros, 0) + COALESCE(e.net_amount, 0)
+`
+
+type ListAccountBalanceDriftsSinceRow struct {
+	ID         pgtype.UUID `db:"id" json:"id"`
+	Currency   string      `db:"currency" json:"currency"`
+	Balance    int64       `db:"balance" json:"balance"`
+	EntriesNet int64       `db:"entries_net" json:"entries_net"`
+}
+
+// Like ListAccountBalanceDrifts, but takes each account's net up to since
+// from ledger_account_totals and only scans entries created after it.
+func (q *Queries) ListAccountBalanceDriftsSince(ctx context.Context, since pgtype.Timestamptz) ([]ListAccountBalanceDriftsSinceRow, error) {
+	rows, err := q.db.Query(ctx, listAccountBalanceDriftsSince, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountBalanceDriftsSinceRow
+	for rows.Next() {
+		var i ListAccountBalanceDriftsSinceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesNet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerDirtyDays = `-- name: ListLedgerDirtyDays :many
+SELECT day FROM ledger_dirty_days ORDER BY day
+`
+
+func (q *Queries) ListLedgerDirtyDays(ctx context.Context) ([]pgtype.Date, error) {
+	rows, err := q.db.Query(ctx, listLedgerDirtyDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Date
+	for rows.Next() {
+		var day pgtype.Date
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		items = append(items, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerMonthSeals = `-- name: ListLedgerMonthSeals :many
+SELECT month FROM ledger_month_seals ORDER BY month
+`
+
+func (q *Queries) ListLedgerMonthSeals(ctx context.Context) ([]pgtype.Date, error) {
+	rows, err := q.db.Query(ctx, listLedgerMonthSeals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Date
+	for rows.Next() {
+		var month pgtype.Date
+		if err := rows.Scan(&month); err != nil {
+			return nil, err
+		}
+		items = append(items, month)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLockedFundsDrifts = `-- name: ListLockedFundsDrifts :many
+SELECT
+  a.id,
+  a.currency,
+  a.locked_micros,
+  COALESCE(p.open_micros, 0)::bigint AS open_payout_micros
+FROM accounts a
+LEFT JOIN (
+  SELECT account_id, SUM(amount_micros) AS open_micros
+  FROM payouts
+  WHERE status IN ('AWAITING_APPROVAL', 'SCREENING_HOLD', 'PENDING', 'PROCESSING', 'SUBMITTED', 'MANUAL_REVIEW')
+  GROUP BY account_id
+) p ON p.account_id = a.id
+WHERE a.locked_micros <> COALESCE(p.open_micros, 0)
+`
+
+type ListLockedFundsDriftsRow struct {
+	ID               pgtype.UUID `db:"id" json:"id"`
+	Currency         string      `db:"currency" json:"currency"`
+	LockedMicros     int64       `db:"locked_micros" json:"locked_micros"`
+	OpenPayoutMicros int64       `db:"open_payout_micros" json:"open_payout_micros"`
+}
+
+// Accounts whose locked_micros differs from the payouts still holding funds.
+func (q *Queries) ListLockedFundsDrifts(ctx context.Context) ([]ListLockedFundsDriftsRow, error) {
+	rows, err := q.db.Query(ctx, listLockedFundsDrifts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLockedFundsDriftsRow
+	for rows.Next() {
+		var i ListLockedFundsDriftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.LockedMicros,
+			&i.OpenPayoutMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReconciliationFindings = `-- name: ListReconciliationFindings :many
+SELECT id, finding_type, status, subject_id, currency, expected_micros, actual_micros, first_seen_at, last_seen_at, resolved_at, period FROM reconciliation_findings
+WHERE status = $1
+ORDER BY first_seen_at ASC
+LIMIT $2 OFFSET $3
+`
+
+type ListReconciliationFindingsParams struct {
+	Status string `db:"status" json:"status"`
+	Limit  int32  `db:"limit" json:"limit"`
+	Offset int32  `db:"offset" json:"offset"`
+}
+
+func (q *Queries) ListReconciliationFindings(ctx context.Context, arg ListReconciliationFindingsParams) ([]ReconciliationFinding, error) {
+	rows, err := q.db.Query(ctx, listReconciliationFindings, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReconciliationFinding
+	for rows.Next() {
+		var i ReconciliationFinding
+		if err := rows.Scan(
+			&i.ID,
+			&i.FindingType,
+			&i.Status,
+			&i.SubjectID,
+			&i.Currency,
+			&i.ExpectedMicros,
+			&i.ActualMicros,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+			&i.ResolvedAt,
+			&i.Period,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReconciliationRuns = `-- name: ListReconciliationRuns :many
+SELECT id, trigger, scope, status, triggered_by, started_at, finished_at, currency_totals, findings, finding_count, error FROM reconciliation_runs
+ORDER BY started_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListReconciliationRunsParams struct {
+	Limit  int32 `db:"limit" json:"limit"`
+	Offset int32 `db:"offset" json:"offset"`
+}
+
+func (q *Queries) ListReconciliationRuns(ctx context.Context, arg ListReconciliationRunsParams) ([]ReconciliationRun, error) {
+	rows, err := q.db.Query(ctx, listReconciliationRuns, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReconciliationRun
+	for rows.Next() {
+		var i ReconciliationRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.Trigger,
+			&i.Scope,
+			&i.Status,
+			&i.TriggeredBy,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.CurrencyTotals,
+			&i.Findings,
+			&i.FindingCount,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedTransactions = `-- name: ListUnbalancedTransactions :many
+SELECT
+  e.transaction_id,
+  a.currency,
+  SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END)::bigint AS net_amount
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+GROUP BY e.transaction_id, a.currency
+HAVING SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) <> 0
+`
+
+type ListUnbalancedTransactionsRow struct {
+	TransactionID pgtype.UUID `db:"transaction_id" json:"transaction_id"`
+	Currency      string      `db:"currency" json:"currency"`
+	NetAmount     int64       `db:"net_amount" json:"net_amount"`
+}
+
+// Transactions whose entries do not net to zero within a currency.
+func (q *Queries) ListUnbalancedTransactions(ctx context.Context) ([]ListUnbalancedTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, listUnbalancedTransactions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnbalancedTransactionsRow
+	for rows.Next() {
+		var i ListUnbalancedTransactionsRow
+		if err := rows.Scan(&i.TransactionID, &i.Currency, &i.NetAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnbalancedTransactionsInRange = `-- name: ListUnbalancedTransactionsInRange :many
+SELECT
+  e.transaction_id,
+  a.currency,
+  SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END)::bigint AS net_amount
+FROM entries e
+INNER JOIN accounts a ON a.id = e.account_id
+WHERE e.transaction_id IN (
+  SELECT DISTINCT r.transaction_id FROM entries r
+  WHERE r.created_at >= $1 AND r.created_at < $2
+)
+GROUP BY e.transaction_id, a.currency
+HAVING SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) <> 0
+`
+
+type ListUnbalancedTransactionsInRangeParams struct {
+	RangeStart pgtype.Timestamptz `db:"range_start" json:"range_start"`
+	RangeEnd   pgtype.Timestamptz `db:"range_end" json:"range_end"`
+}
+
+type ListUnbalancedTransactionsInRangeRow struct {
+	TransactionID pgtype.UUID `db:"transaction_id" json:"transaction_id"`
+	Currency      string      `db:"currency" json:"currency"`
+	NetAmount     int64       `db:"net_amount" json:"net_amount"`
+}
+
+// Like ListUnbalancedTransactions, restricted to transactions with an entry
+// created in [range_start, range_end).
+func (q *Queries) ListUnbalancedTransactionsInRange(ctx context.Context, arg ListUnbalancedTransactionsInRangeParams) ([]ListUnbalancedTransactionsInRangeRow, error) {
+	rows, err := q.db.Query(ctx, listUnbalancedTransactionsInRange, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnbalancedTransactionsInRangeRow
+	for rows.Next() {
+		var i ListUnbalancedTransactionsInRangeRow
+		if err := rows.Scan(&i.TransactionID, &i.Currency, &i.NetAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLedgerMonthSealArchived = `-- name: MarkLedgerMonthSealArchived :execrows
+UPDATE ledger_month_seals
+SET status = 'ARCHIVED'
+WHERE month = $1 AND status = 'SEALED'
+`
+
+func (q *Queries) MarkLedgerMonthSealArchived(ctx context.Context, month pgtype.Date) (int64, error) {
+	result, err := q.db.Exec(ctx, markLedgerMonthSealArchived, month)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markLedgerMonthSealVerified = `-- name: MarkLedgerMonthSealVerified :exec
+UPDATE ledger_month_seals
+SET status = $2, verified_at = NOW()
+WHERE month = $1
+`
+
+type MarkLedgerMonthSealVerifiedParams struct {
+	Month  pgtype.Date `db:"month" json:"month"`
+	Status string      `db:"status" json:"status"`
+}
+
+func (q *Queries) MarkLedgerMonthSealVerified(ctx context.Context, arg MarkLedgerMonthSealVerifiedParams) error {
+	_, err := q.db.Exec(ctx, markLedgerMonthSealVerified, arg.Month, arg.Status)
+	return err
+}
+
+const rebuildLedgerAccountTotals = `-- name: RebuildLedgerAccountTotals :exec
+INSERT INTO ledger_account_totals (account_id, net_micros)
+SELECT
+  t.account_id,
+  (t.net_amount + COALESCE(x.net_micros, 0))::bigint
+FROM (
+  SELECT
+    e.account_id,
+    SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) AS net_amount
+  FROM entries e
+  WHERE e.created_at < $1
+    AND e.account_id IN (
+      SELECT DISTINCT d.account_id FROM entries d
+      WHERE d.created_at >= $2 AND d.created_at < $3
+    )
+  GROUP BY e.account_id
+) t
+LEFT JOIN entries_archived_account_totals x ON x.account_id = t.account_id
+ON CONFLICT (account_id) DO UPDATE SET
+  net_micros = EXCLUDED.net_micros,
+  updated_at = NOW()
+`
+
+type RebuildLedgerAccountTotalsParams struct {
+	CheckpointEnd pgtype.Timestamptz `db:"checkpoint_end" json:"checkpoint_end"`
+	DayStart      pgtype.Timestamptz `db:"day_start" json:"day_start"`
+	DayEnd        pgtype.Timestamptz `db:"day_end" json:"day_end"`
+}
+
+// Recomputes the checkpointed total of every account with entries in
+// [day_start, day_end) from all of its entries before checkpoint_end and
+// its archived entries.
+func (q *Queries) RebuildLedgerAccountTotals(ctx context.Context, arg RebuildLedgerAccountTotalsParams) error {
+	_, err := q.db.Exec(ctx, rebuildLedgerAccountTotals, arg.CheckpointEnd, arg.DayStart, arg.DayEnd)
+	return err
+}
+
+const resolveLedgerPeriodFindings = `-- name: ResolveLedgerPeriodFindings :execrows
+UPDATE reconciliation_findings
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE status = 'OPEN' AND finding_type = 'SEALED_PERIOD_CHANGED' AND period = $1
+`
+
+func (q *Queries) ResolveLedgerPeriodFindings(ctx context.Context, period string) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveLedgerPeriodFindings, period)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveReconciliationFindings = `-- name: ResolveReconciliationFindings :execrows
+UPDATE reconciliation_findings
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE status = 'OPEN'
+  AND finding_type = ANY($1::text[])
+  AND last_seen_at < $2
+`
+
+type ResolveReconciliationFindingsParams struct {
+	FindingTypes []string           `db:"finding_types" json:"finding_types"`
+	SeenBefore   pgtype.Timestamptz `db:"seen_before" json:"seen_before"`
+}
+
+// Resolves open findings of the given types that the current run (started at
+// seen_before) did not see.
+func (q *Queries) ResolveReconciliationFindings(ctx context.Context, arg ResolveReconciliationFindingsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveReconciliationFindings, arg.FindingTypes, arg.SeenBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resolveTransactionFindingsInRange = `-- name: ResolveTransactionFindingsInRange :execrows
+UPDATE reconciliation_findings f
+SET status = 'RESOLVED', resolved_at = NOW()
+WHERE f.status = 'OPEN'
+  AND f.finding_type = 'TRANSACTION_UNBALANCED'
+  AND f.last_seen_at < $1
+  AND EXISTS (
+    SELECT 1 FROM entries e
+    WHERE e.transaction_id = f.subject_id
+      AND e.created_at >= $2 AND e.created_at < $3
+  )
+`
+
+type ResolveTransactionFindingsInRangeParams struct {
+	SeenBefore pgtype.Timestamptz `db:"seen_before" json:"seen_before"`
+	RangeStart pgtype.Timestamptz `db:"range_start" json:"range_start"`
+	RangeEnd   pgtype.Timestamptz `db:"range_end" json:"range_end"`
+}
+
+// Resolves open unbalanced-transaction findings the current run did not see,
+// limited to transactions with an entry created in [range_start, range_end).
+func (q *Queries) ResolveTransactionFindingsInRange(ctx context.Context, arg ResolveTransactionFindingsInRangeParams) (int64, error) {
+	result, err := q.db.Exec(ctx, resolveTransactionFindingsInRange, arg.SeenBefore, arg.RangeStart, arg.RangeEnd)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const upsertReconciliationFinding = `-- name: UpsertReconciliationFinding :one
+INSERT INTO reconciliation_findings (id, finding_type, subject_id, currency, period, expected_micros, actual_micros, first_seen_at, last_seen_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+ON CONFLICT (finding_type, subject_id, currency, period) WHERE status = 'OPEN'
+DO UPDATE SET
+  expected_micros = EXCLUDED.expected_micros,
+  actual_micros = EXCLUDED.actual_micros,
+  last_seen_at = EXCLUDED.last_seen_at
+RETURNING id, finding_type, status, subject_id, currency, expected_micros, actual_micros, first_seen_at, last_seen_at, resolved_at, period
+`
+
+type UpsertReconciliationFindingParams struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	FindingType    string             `db:"finding_type" json:"finding_type"`
+	SubjectID      pgtype.UUID        `db:"subject_id" json:"subject_id"`
+	Currency       string             `db:"currency" json:"currency"`
+	Period         string             `db:"period" json:"period"`
+	ExpectedMicros int64              `db:"expected_micros" json:"expected_micros"`
+	ActualMicros   int64              `db:"actual_micros" json:"actual_micros"`
+	SeenAt         pgtype.Timestamptz `db:"seen_at" json:"seen_at"`
+}
+
+func (q *Queries) UpsertReconciliationFinding(ctx context.Context, arg UpsertReconciliationFindingParams) (ReconciliationFinding, error) {
+	row := q.db.QueryRow(ctx, upsertReconciliationFinding,
+		arg.ID,
+		arg.FindingType,
+		arg.SubjectID,
+		arg.Currency,
+		arg.Period,
+		arg.ExpectedMicros,
+		arg.ActualMicros,
+		arg.SeenAt,
+	)
+	var i ReconciliationFinding
+	err := row.Scan(
+		&i.ID,
+		&i.FindingType,
+		&i.Status,
+		&i.SubjectID,
+		&i.Currency,
+		&i.ExpectedMicros,
+		&i.ActualMicros,
+		&i.FirstSeenAt,
+		&i.LastSeenAt,
+		&i.ResolvedAt,
+		&i.Period,
+	)
+	return i, err
+}
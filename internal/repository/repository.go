@@ -34,7 +34,7 @@ func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
 	if user.Role == "" {
 		user.Role = "user"
 	}
-	createdAt, err := r.queries.CreateUser(ctx, CreateUserParams{
+	created, err := r.queries.CreateUser(ctx, CreateUserParams{
 		ID:       ToPgUUID(user.ID),
 		Username: user.Username,
 		Email:    user.Email,
@@ -43,12 +43,14 @@ func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
-	user.CreatedAt = createdAt.Time
+	user.KYCStatus = created.KycStatus
+	user.KYCTier = created.KycTier
+	user.CreatedAt = created.CreatedAt.Time
 	return nil
 }
 
 func (r *Repository) CreateAccount(ctx context.Context, account *models.Account) error {
-	createdAt, err := r.queries.CreateAccount(ctx, CreateAccountParams{
+	created, err := r.queries.CreateAccount(ctx, CreateAccountParams{
 		ID:       ToPgUUID(account.ID),
 		UserID:   ToPgUUID(account.UserID),
 		Currency: account.Currency,
@@ -57,7 +59,8 @@ func (r *Repository) CreateAccount(ctx context.Context, account *models.Account)
 	if err != nil {
 		return fmt.Errorf("failed to create account: %w", err)
 	}
-	account.CreatedAt = createdAt.Time
+	account.Status = created.Status
+	account.CreatedAt = created.CreatedAt.Time
 	return nil
 }
 
@@ -72,6 +75,8 @@ func (r *Repository) GetUser(ctx context.Context, id uuid.UUID) (*models.User, e
 		Username:  row.Username,
 		Email:     row.Email,
 		Role:      row.Role,
+		KYCStatus: row.KycStatus,
+		KYCTier:   row.KycTier,
 		CreatedAt: row.CreatedAt.Time,
 	}, nil
 }
@@ -82,13 +87,21 @@ func (r *Repository) GetAccount(ctx context.Context, id uuid.UUID) (*models.Acco
 		return nil, fmt.Errorf("failed to get account: %w", err)
 	}
 
-	return &models.Account{
+	account := &models.Account{
 		ID:        FromPgUUID(row.ID),
 		UserID:    FromPgUUID(row.UserID),
 		Currency:  row.Currency,
 		Balance:   row.Balance,
+		Status:    row.Status,
 		CreatedAt: row.CreatedAt.Time,
-	}, nil
+
+		OverdraftLimit:       row.OverdraftLimitMicros,
+		OverdraftInterestBps: row.OverdraftInterestBps,
+	}
+	if row.StatusReason != nil {
+		account.StatusReason = *row.StatusReason
+	}
+	return account, nil
 }
 
 func (r *Repository) GetEntries(ctx context.Context, accountID uuid.UUID, limit, offset int) ([]models.Entry, error) {
@@ -0,0 +1,81 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: role.sql
+
+package repository
+
+import (
+	"context"
+)
+
+const listRolePermissions = `-- name: ListRolePermissions :many
+SELECT permission
+FROM role_permissions
+WHERE role = $1
+ORDER BY permission
+`
+
+func (q *Queries) ListRolePermissions(ctx context.Context, role string) ([]string, error) {
+	rows, err := q.db.Query(ctx, listRolePermissions, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		items = append(items, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoles = `-- name: ListRoles :many
+SELECT r.name, r.description,
+       ARRAY(SELECT rp.permission FROM role_permissions rp WHERE rp.role = r.name ORDER BY rp.permission)::text[] AS permissions
+FROM roles r
+ORDER BY r.name
+`
+
+type ListRolesRow struct {
+	Name        string   `db:"name" json:"name"`
+	Description string   `db:"description" json:"description"`
+	Permissions []string `db:"permissions" json:"permissions"`
+}
+
+func (q *Queries) ListRoles(ctx context.Context) ([]ListRolesRow, error) {
+	rows, err := q.db.Query(ctx, listRoles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRolesRow
+	for rows.Next() {
+		var i ListRolesRow
+		if err := rows.Scan(&i.Name, &i.Description, &i.Permissions); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const roleExists = `-- name: RoleExists :one
+SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)
+`
+
+func (q *Queries) RoleExists(ctx context.Context, name string) (bool, error) {
+	row := q.db.QueryRow(ctx, roleExists, name)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,261 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: sanctions.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createSanctionsListLoad = `-- name: CreateSanctionsListLoad :one
+INSERT INTO sanctions_list_loads (source, entry_count, checksum, loaded_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, source, entry_count, checksum, loaded_by, loaded_at
+`
+
+type CreateSanctionsListLoadParams struct {
+	Source     string `db:"source" json:"source"`
+	EntryCount int32  `db:"entry_count" json:"entry_count"`
+	Checksum   string `db:"checksum" json:"checksum"`
+	LoadedBy   string `db:"loaded_by" json:"loaded_by"`
+}
+
+func (q *Queries) CreateSanctionsListLoad(ctx context.Context, arg CreateSanctionsListLoadParams) (SanctionsListLoad, error) {
+	row := q.db.QueryRow(ctx, createSanctionsListLoad,
+		arg.Source,
+		arg.EntryCount,
+		arg.Checksum,
+		arg.LoadedBy,
+	)
+	var i SanctionsListLoad
+	err := row.Scan(
+		&i.ID,
+		&i.Source,
+		&i.EntryCount,
+		&i.Checksum,
+		&i.LoadedBy,
+		&i.LoadedAt,
+	)
+	return i, err
+}
+
+const decidePayoutScreening = `-- name: DecidePayoutScreening :execrows
+UPDATE payout_screenings
+SET decision = $1,
+    decided_by = $2,
+    decided_at = NOW(),
+    reason = $3
+WHERE payout_id = $4 AND decision IS NULL
+`
+
+type DecidePayoutScreeningParams struct {
+	Decision  *string     `db:"decision" json:"decision"`
+	DecidedBy pgtype.UUID `db:"decided_by" json:"decided_by"`
+	Reason    *string     `db:"reason" json:"reason"`
+	PayoutID  pgtype.UUID `db:"payout_id" json:"payout_id"`
+}
+
+func (q *Queries) DecidePayoutScreening(ctx context.Context, arg DecidePayoutScreeningParams) (int64, error) {
+	result, err := q.db.Exec(ctx, decidePayoutScreening,
+		arg.Decision,
+		arg.DecidedBy,
+		arg.Reason,
+		arg.PayoutID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteSanctionsEntriesBySource = `-- name: DeleteSanctionsEntriesBySource :execrows
+DELETE FROM sanctions_entries
+WHERE source = $1
+`
+
+func (q *Queries) DeleteSanctionsEntriesBySource(ctx context.Context, source string) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteSanctionsEntriesBySource, source)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getPayoutScreening = `-- name: GetPayoutScreening :one
+SELECT payout_id, screened_name, list_source, list_entry_id, matched_name, score, release_status, decision, decided_by, decided_at, reason, created_at FROM payout_screenings
+WHERE payout_id = $1
+`
+
+func (q *Queries) GetPayoutScreening(ctx context.Context, payoutID pgtype.UUID) (PayoutScreening, error) {
+	row := q.db.QueryRow(ctx, getPayoutScreening, payoutID)
+	var i PayoutScreening
+	err := row.Scan(
+		&i.PayoutID,
+		&i.ScreenedName,
+		&i.ListSource,
+		&i.ListEntryID,
+		&i.MatchedName,
+		&i.Score,
+		&i.ReleaseStatus,
+		&i.Decision,
+		&i.DecidedBy,
+		&i.DecidedAt,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSanctionsListVersion = `-- name: GetSanctionsListVersion :one
+SELECT COALESCE(MAX(id), 0)::bigint FROM sanctions_list_loads
+`
+
+func (q *Queries) GetSanctionsListVersion(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getSanctionsListVersion)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const insertPayoutScreening = `-- name: InsertPayoutScreening :exec
+INSERT INTO payout_screenings (payout_id, screened_name, list_source, list_entry_id, matched_name, score, release_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertPayoutScreeningParams struct {
+	PayoutID      pgtype.UUID `db:"payout_id" json:"payout_id"`
+	ScreenedName  string      `db:"screened_name" json:"screened_name"`
+	ListSource    string      `db:"list_source" json:"list_source"`
+	ListEntryID   string      `db:"list_entry_id" json:"list_entry_id"`
+	MatchedName   string      `db:"matched_name" json:"matched_name"`
+	Score         float64     `db:"score" json:"score"`
+	ReleaseStatus string      `db:"release_status" json:"release_status"`
+}
+
+func (q *Queries) InsertPayoutScreening(ctx context.Context, arg InsertPayoutScreeningParams) error {
+	_, err := q.db.Exec(ctx, insertPayoutScreening,
+		arg.PayoutID,
+		arg.ScreenedName,
+		arg.ListSource,
+		arg.ListEntryID,
+		arg.MatchedName,
+		arg.Score,
+		arg.ReleaseStatus,
+	)
+	return err
+}
+
+const insertSanctionsEntries = `-- name: InsertSanctionsEntries :execrows
+INSERT INTO sanctions_entries (load_id, source, external_id, name, entry_type, programs)
+SELECT $1::bigint, $2::text,
+       unnest($3::text[]),
+       unnest($4::text[]),
+       unnest($5::text[]),
+       unnest($6::text[])
+`
+
+type InsertSanctionsEntriesParams struct {
+	LoadID      int64    `db:"load_id" json:"load_id"`
+	Source      string   `db:"source" json:"source"`
+	ExternalIds []string `db:"external_ids" json:"external_ids"`
+	Names       []string `db:"names" json:"names"`
+	EntryTypes  []string `db:"entry_types" json:"entry_types"`
+	Programs    []string `db:"programs" json:"programs"`
+}
+
+func (q *Queries) InsertSanctionsEntries(ctx context.Context, arg InsertSanctionsEntriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, insertSanctionsEntries,
+		arg.LoadID,
+		arg.Source,
+		arg.ExternalIds,
+		arg.Names,
+		arg.EntryTypes,
+		arg.Programs,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const listPayoutScreenings = `-- name: ListPayoutScreenings :many
+SELECT payout_id, screened_name, list_source, list_entry_id, matched_name, score, release_status, decision, decided_by, decided_at, reason, created_at FROM payout_screenings
+WHERE payout_id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListPayoutScreenings(ctx context.Context, payoutIds []pgtype.UUID) ([]PayoutScreening, error) {
+	rows, err := q.db.Query(ctx, listPayoutScreenings, payoutIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PayoutScreening
+	for rows.Next() {
+		var i PayoutScreening
+		if err := rows.Scan(
+			&i.PayoutID,
+			&i.ScreenedName,
+			&i.ListSource,
+			&i.ListEntryID,
+			&i.MatchedName,
+			&i.Score,
+			&i.ReleaseStatus,
+			&i.Decision,
+			&i.DecidedBy,
+			&i.DecidedAt,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSanctionsEntries = `-- name: ListSanctionsEntries :many
+SELECT source, external_id, name, entry_type, programs
+FROM sanctions_entries
+ORDER BY id
+`
+
+type ListSanctionsEntriesRow struct {
+	Source     string `db:"source" json:"source"`
+	ExternalID string `db:"external_id" json:"external_id"`
+	Name       string `db:"name" json:"name"`
+	EntryType  string `db:"entry_type" json:"entry_type"`
+	Programs   string `db:"programs" json:"programs"`
+}
+
+func (q *Queries) ListSanctionsEntries(ctx context.Context) ([]ListSanctionsEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listSanctionsEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSanctionsEntriesRow
+	for rows.Next() {
+		var i ListSanctionsEntriesRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.ExternalID,
+			&i.Name,
+			&i.EntryType,
+			&i.Programs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,310 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: session.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createRefreshToken = `-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (token_hash, session_id, created_at)
+VALUES ($1, $2, $3)
+`
+
+type CreateRefreshTokenParams struct {
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+	SessionID pgtype.UUID        `db:"session_id" json:"session_id"`
+	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error {
+	_, err := q.db.Exec(ctx, createRefreshToken, arg.TokenHash, arg.SessionID, arg.CreatedAt)
+	return err
+}
+
+const createUserSession = `-- name: CreateUserSession :exec
+INSERT INTO user_sessions (id, user_id, access_jti, access_expires_at, user_agent, ip_address, created_at, last_refreshed_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $8)
+`
+
+type CreateUserSessionParams struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+	UserAgent       string             `db:"user_agent" json:"user_agent"`
+	IpAddress       string             `db:"ip_address" json:"ip_address"`
+	Now             pgtype.Timestamptz `db:"now" json:"now"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) CreateUserSession(ctx context.Context, arg CreateUserSessionParams) error {
+	_, err := q.db.Exec(ctx, createUserSession,
+		arg.ID,
+		arg.UserID,
+		arg.AccessJti,
+		arg.AccessExpiresAt,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.Now,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const getRefreshTokenForUpdate = `-- name: GetRefreshTokenForUpdate :one
+SELECT rt.used_at, s.id AS session_id, s.user_id, s.access_jti, s.access_expires_at, s.expires_at, s.revoked_at, u.role
+FROM refresh_tokens rt
+JOIN user_sessions s ON s.id = rt.session_id
+JOIN users u ON u.id = s.user_id
+WHERE rt.token_hash = $1
+FOR UPDATE OF rt, s
+`
+
+type GetRefreshTokenForUpdateRow struct {
+	UsedAt          pgtype.Timestamptz `db:"used_at" json:"used_at"`
+	SessionID       pgtype.UUID        `db:"session_id" json:"session_id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	RevokedAt       pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+	Role            string             `db:"role" json:"role"`
+}
+
+func (q *Queries) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (GetRefreshTokenForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getRefreshTokenForUpdate, tokenHash)
+	var i GetRefreshTokenForUpdateRow
+	err := row.Scan(
+		&i.UsedAt,
+		&i.SessionID,
+		&i.UserID,
+		&i.AccessJti,
+		&i.AccessExpiresAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getSessionState = `-- name: GetSessionState :one
+SELECT user_id, access_jti, access_expires_at, expires_at, revoked_at
+FROM user_sessions
+WHERE id = $1
+`
+
+type GetSessionStateRow struct {
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+	RevokedAt       pgtype.Timestamptz `db:"revoked_at" json:"revoked_at"`
+}
+
+func (q *Queries) GetSessionState(ctx context.Context, id pgtype.UUID) (GetSessionStateRow, error) {
+	row := q.db.QueryRow(ctx, getSessionState, id)
+	var i GetSessionStateRow
+	err := row.Scan(
+		&i.UserID,
+		&i.AccessJti,
+		&i.AccessExpiresAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const listActiveUserSessions = `-- name: ListActiveUserSessions :many
+SELECT id, user_id, user_agent, ip_address, created_at, last_refreshed_at, expires_at
+FROM user_sessions
+WHERE user_id = $1
+  AND revoked_at IS NULL
+  AND expires_at > $2
+ORDER BY created_at DESC
+`
+
+type ListActiveUserSessionsParams struct {
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+}
+
+type ListActiveUserSessionsRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	UserID          pgtype.UUID        `db:"user_id" json:"user_id"`
+	UserAgent       string             `db:"user_agent" json:"user_agent"`
+	IpAddress       string             `db:"ip_address" json:"ip_address"`
+	CreatedAt       pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	LastRefreshedAt pgtype.Timestamptz `db:"last_refreshed_at" json:"last_refreshed_at"`
+	ExpiresAt       pgtype.Timestamptz `db:"expires_at" json:"expires_at"`
+}
+
+func (q *Queries) ListActiveUserSessions(ctx context.Context, arg ListActiveUserSessionsParams) ([]ListActiveUserSessionsRow, error) {
+	rows, err := q.db.Query(ctx, listActiveUserSessions, arg.UserID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActiveUserSessionsRow
+	for rows.Next() {
+		var i ListActiveUserSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.CreatedAt,
+			&i.LastRefreshedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markRefreshTokenUsed = `-- name: MarkRefreshTokenUsed :exec
+UPDATE refresh_tokens
+SET used_at = $1
+WHERE token_hash = $2
+`
+
+type MarkRefreshTokenUsedParams struct {
+	Now       pgtype.Timestamptz `db:"now" json:"now"`
+	TokenHash string             `db:"token_hash" json:"token_hash"`
+}
+
+func (q *Queries) MarkRefreshTokenUsed(ctx context.Context, arg MarkRefreshTokenUsedParams) error {
+	_, err := q.db.Exec(ctx, markRefreshTokenUsed, arg.Now, arg.TokenHash)
+	return err
+}
+
+const revokeAllUserSessions = `-- name: RevokeAllUserSessions :many
+UPDATE user_sessions
+SET revoked_at = $1,
+    revoked_reason = $2::text
+WHERE user_id = $3
+  AND revoked_at IS NULL
+RETURNING id, access_jti, access_expires_at
+`
+
+type RevokeAllUserSessionsParams struct {
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+	Reason string             `db:"reason" json:"reason"`
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+}
+
+type RevokeAllUserSessionsRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+}
+
+func (q *Queries) RevokeAllUserSessions(ctx context.Context, arg RevokeAllUserSessionsParams) ([]RevokeAllUserSessionsRow, error) {
+	rows, err := q.db.Query(ctx, revokeAllUserSessions, arg.Now, arg.Reason, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RevokeAllUserSessionsRow
+	for rows.Next() {
+		var i RevokeAllUserSessionsRow
+		if err := rows.Scan(&i.ID, &i.AccessJti, &i.AccessExpiresAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeUserSession = `-- name: RevokeUserSession :one
+UPDATE user_sessions
+SET revoked_at = $1,
+    revoked_reason = $2::text
+WHERE id = $3
+  AND user_id = $4
+  AND revoked_at IS NULL
+RETURNING access_jti, access_expires_at
+`
+
+type RevokeUserSessionParams struct {
+	Now    pgtype.Timestamptz `db:"now" json:"now"`
+	Reason string             `db:"reason" json:"reason"`
+	ID     pgtype.UUID        `db:"id" json:"id"`
+	UserID pgtype.UUID        `db:"user_id" json:"user_id"`
+}
+
+type RevokeUserSessionRow struct {
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+}
+
+func (q *Queries) RevokeUserSession(ctx context.Context, arg RevokeUserSessionParams) (RevokeUserSessionRow, error) {
+	row := q.db.QueryRow(ctx, revokeUserSession,
+		arg.Now,
+		arg.Reason,
+		arg.ID,
+		arg.UserID,
+	)
+	var i RevokeUserSessionRow
+	err := row.Scan(&i.AccessJti, &i.AccessExpiresAt)
+	return i, err
+}
+
+const rotateSessionAccessToken = `-- name: RotateSessionAccessToken :exec
+UPDATE user_sessions
+SET access_jti = $1,
+    access_expires_at = $2,
+    last_refreshed_at = $3
+WHERE id = $4
+`
+
+type RotateSessionAccessTokenParams struct {
+	AccessJti       pgtype.UUID        `db:"access_jti" json:"access_jti"`
+	AccessExpiresAt pgtype.Timestamptz `db:"access_expires_at" json:"access_expires_at"`
+	Now             pgtype.Timestamptz `db:"now" json:"now"`
+	ID              pgtype.UUID        `db:"id" json:"id"`
+}
+
+func (q *Queries) RotateSessionAccessToken(ctx context.Context, arg RotateSessionAccessTokenParams) error {
+	_, err := q.db.Exec(ctx, rotateSessionAccessToken,
+		arg.AccessJti,
+		arg.AccessExpiresAt,
+		arg.Now,
+		arg.ID,
+	)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users u
+SET role = $1
+FROM (SELECT p.id, p.role FROM users p WHERE p.id = $2 FOR UPDATE) prev
+WHERE u.id = prev.id
+RETURNING prev.role AS previous_role
+`
+
+type UpdateUserRoleParams struct {
+	Role string      `db:"role" json:"role"`
+	ID   pgtype.UUID `db:"id" json:"id"`
+}
+
+// Returns the role the user had before.
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.Role, arg.ID)
+	var previous_role string
+	err := row.Scan(&previous_role)
+	return previous_role, err
+}
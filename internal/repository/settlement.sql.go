@@ -0,0 +1,535 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: settlement.sql
+
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const clearMissingSettlementBreak = `-- name: ClearMissingSettlementBreak :execrows
+UPDATE reconciliation_breaks
+SET status = 'CLEARED', cleared_at = NOW(), resolution_note = $2
+WHERE payout_id = $1 AND break_type = 'COMPLETED_BUT_MISSING' AND status = 'OPEN'
+`
+
+type ClearMissingSettlementBreakParams struct {
+	PayoutID       pgtype.UUID `db:"payout_id" json:"payout_id"`
+	ResolutionNote *string     `db:"resolution_note" json:"resolution_note"`
+}
+
+func (q *Queries) ClearMissingSettlementBreak(ctx context.Context, arg ClearMissingSettlementBreakParams) (int64, error) {
+	result, err := q.db.Exec(ctx, clearMissingSettlementBreak, arg.PayoutID, arg.ResolutionNote)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearReconciliationBreak = `-- name: ClearReconciliationBreak :one
+UPDATE reconciliation_breaks
+SET status = 'CLEARED', cleared_by = $2, cleared_at = NOW(), resolution_note = $3
+WHERE id = $1 AND status = 'OPEN'
+RETURNING id, break_type, status, payout_id, line_id, file_id, gateway_ref, currency, expected_micros, actual_micros, details, created_at, cleared_by, cleared_at, resolution_note
+`
+
+type ClearReconciliationBreakParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	ClearedBy      pgtype.UUID `db:"cleared_by" json:"cleared_by"`
+	ResolutionNote *string     `db:"resolution_note" json:"resolution_note"`
+}
+
+func (q *Queries) ClearReconciliationBreak(ctx context.Context, arg ClearReconciliationBreakParams) (ReconciliationBreak, error) {
+	row := q.db.QueryRow(ctx, clearReconciliationBreak, arg.ID, arg.ClearedBy, arg.ResolutionNote)
+	var i ReconciliationBreak
+	err := row.Scan(
+		&i.ID,
+		&i.BreakType,
+		&i.Status,
+		&i.PayoutID,
+		&i.LineID,
+		&i.FileID,
+		&i.GatewayRef,
+		&i.Currency,
+		&i.ExpectedMicros,
+		&i.ActualMicros,
+		&i.Details,
+		&i.CreatedAt,
+		&i.ClearedBy,
+		&i.ClearedAt,
+		&i.ResolutionNote,
+	)
+	return i, err
+}
+
+const countSettlementDebitsForPayout = `-- name: CountSettlementDebitsForPayout :one
+SELECT COUNT(*)::bigint FROM settlement_lines
+WHERE payout_id = $1 AND direction = 'debit'
+`
+
+func (q *Queries) CountSettlementDebitsForPayout(ctx context.Context, payoutID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countSettlementDebitsForPayout, payoutID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const createReconciliationBreak = `-- name: CreateReconciliationBreak :execrows
+INSERT INTO reconciliation_breaks (id, break_type, payout_id, line_id, file_id, gateway_ref, currency, expected_micros, actual_micros, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT DO NOTHING
+`
+
+type CreateReconciliationBreakParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	BreakType      string      `db:"break_type" json:"break_type"`
+	PayoutID       pgtype.UUID `db:"payout_id" json:"payout_id"`
+	LineID         pgtype.UUID `db:"line_id" json:"line_id"`
+	FileID         pgtype.UUID `db:"file_id" json:"file_id"`
+	GatewayRef     *string     `db:"gateway_ref" json:"gateway_ref"`
+	Currency       *string     `db:"currency" json:"currency"`
+	ExpectedMicros *int64      `db:"expected_micros" json:"expected_micros"`
+	ActualMicros   *int64      `db:"actual_micros" json:"actual_micros"`
+	Details        string      `db:"details" json:"details"`
+}
+
+func (q *Queries) CreateReconciliationBreak(ctx context.Context, arg CreateReconciliationBreakParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createReconciliationBreak,
+		arg.ID,
+		arg.BreakType,
+		arg.PayoutID,
+		arg.LineID,
+		arg.FileID,
+		arg.GatewayRef,
+		arg.Currency,
+		arg.ExpectedMicros,
+		arg.ActualMicros,
+		arg.Details,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createSettlementFile = `-- name: CreateSettlementFile :one
+INSERT INTO settlement_files (id, file_name, format, sha256, period_start, period_end, imported_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, file_name, format, sha256, period_start, period_end, line_count, matched_count, break_count, imported_by, imported_at
+`
+
+type CreateSettlementFileParams struct {
+	ID          pgtype.UUID        `db:"id" json:"id"`
+	FileName    string             `db:"file_name" json:"file_name"`
+	Format      string             `db:"format" json:"format"`
+	Sha256      string             `db:"sha256" json:"sha256"`
+	PeriodStart pgtype.Timestamptz `db:"period_start" json:"period_start"`
+	PeriodEnd   pgtype.Timestamptz `db:"period_end" json:"period_end"`
+	ImportedBy  pgtype.UUID        `db:"imported_by" json:"imported_by"`
+}
+
+func (q *Queries) CreateSettlementFile(ctx context.Context, arg CreateSettlementFileParams) (SettlementFile, error) {
+	row := q.db.QueryRow(ctx, createSettlementFile,
+		arg.ID,
+		arg.FileName,
+		arg.Format,
+		arg.Sha256,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.ImportedBy,
+	)
+	var i SettlementFile
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Format,
+		&i.Sha256,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.LineCount,
+		&i.MatchedCount,
+		&i.BreakCount,
+		&i.ImportedBy,
+		&i.ImportedAt,
+	)
+	return i, err
+}
+
+const createSettlementLine = `-- name: CreateSettlementLine :one
+INSERT INTO settlement_lines (id, file_id, line_no, gateway_ref, amount_micros, currency, direction, booked_at, description, payout_id, match_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, file_id, line_no, gateway_ref, amount_micros, currency, direction, booked_at, description, payout_id, match_status, created_at
+`
+
+type CreateSettlementLineParams struct {
+	ID           pgtype.UUID        `db:"id" json:"id"`
+	FileID       pgtype.UUID        `db:"file_id" json:"file_id"`
+	LineNo       int32              `db:"line_no" json:"line_no"`
+	GatewayRef   string             `db:"gateway_ref" json:"gateway_ref"`
+	AmountMicros int64              `db:"amount_micros" json:"amount_micros"`
+	Currency     string             `db:"currency" json:"currency"`
+	Direction    string             `db:"direction" json:"direction"`
+	BookedAt     pgtype.Timestamptz `db:"booked_at" json:"booked_at"`
+	Description  *string            `db:"description" json:"description"`
+	PayoutID     pgtype.UUID        `db:"payout_id" json:"payout_id"`
+	MatchStatus  string             `db:"match_status" json:"match_status"`
+}
+
+func (q *Queries) CreateSettlementLine(ctx context.Context, arg CreateSettlementLineParams) (SettlementLine, error) {
+	row := q.db.QueryRow(ctx, createSettlementLine,
+		arg.ID,
+		arg.FileID,
+		arg.LineNo,
+		arg.GatewayRef,
+		arg.AmountMicros,
+		arg.Currency,
+		arg.Direction,
+		arg.BookedAt,
+		arg.Description,
+		arg.PayoutID,
+		arg.MatchStatus,
+	)
+	var i SettlementLine
+	err := row.Scan(
+		&i.ID,
+		&i.FileID,
+		&i.LineNo,
+		&i.GatewayRef,
+		&i.AmountMicros,
+		&i.Currency,
+		&i.Direction,
+		&i.BookedAt,
+		&i.Description,
+		&i.PayoutID,
+		&i.MatchStatus,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReconciliationBreak = `-- name: GetReconciliationBreak :one
+SELECT id, break_type, status, payout_id, line_id, file_id, gateway_ref, currency, expected_micros, actual_micros, details, created_at, cleared_by, cleared_at, resolution_note FROM reconciliation_breaks WHERE id = $1
+`
+
+func (q *Queries) GetReconciliationBreak(ctx context.Context, id pgtype.UUID) (ReconciliationBreak, error) {
+	row := q.db.QueryRow(ctx, getReconciliationBreak, id)
+	var i ReconciliationBreak
+	err := row.Scan(
+		&i.ID,
+		&i.BreakType,
+		&i.Status,
+		&i.PayoutID,
+		&i.LineID,
+		&i.FileID,
+		&i.GatewayRef,
+		&i.Currency,
+		&i.ExpectedMicros,
+		&i.ActualMicros,
+		&i.Details,
+		&i.CreatedAt,
+		&i.ClearedBy,
+		&i.ClearedAt,
+		&i.ResolutionNote,
+	)
+	return i, err
+}
+
+const getSettlementCoverageStart = `-- name: GetSettlementCoverageStart :one
+SELECT MIN(period_start)::timestamptz AS coverage_start FROM settlement_files
+`
+
+func (q *Queries) GetSettlementCoverageStart(ctx context.Context) (pgtype.Timestamptz, error) {
+	row := q.db.QueryRow(ctx, getSettlementCoverageStart)
+	var coverage_start pgtype.Timestamptz
+	err := row.Scan(&coverage_start)
+	return coverage_start, err
+}
+
+const getSettlementFileBySHA256 = `-- name: GetSettlementFileBySHA256 :one
+SELECT id, file_name, format, sha256, period_start, period_end, line_count, matched_count, break_count, imported_by, imported_at FROM settlement_files WHERE sha256 = $1
+`
+
+func (q *Queries) GetSettlementFileBySHA256(ctx context.Context, sha256 string) (SettlementFile, error) {
+	row := q.db.QueryRow(ctx, getSettlementFileBySHA256, sha256)
+	var i SettlementFile
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Format,
+		&i.Sha256,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.LineCount,
+		&i.MatchedCount,
+		&i.BreakCount,
+		&i.ImportedBy,
+		&i.ImportedAt,
+	)
+	return i, err
+}
+
+const listCompletedPayoutsMissingSettlement = `-- name: ListCompletedPayoutsMissingSettlement :many
+SELECT p.id, p.transaction_id, p.account_id, p.amount_micros, p.currency, p.status, p.gateway_ref, p.created_at, p.updated_at, p.attempts, p.requested_by, p.reviewed_by, p.reviewed_at, p.beneficiary_id, p.limit_reservation FROM payouts p
+WHERE p.status = 'COMPLETED'
+  AND p.updated_at >= $1
+  AND p.updated_at < $2
+  AND NOT EXISTS (
+    SELECT 1 FROM settlement_lines l
+    WHERE l.payout_id = p.id AND l.direction = 'debit'
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM reconciliation_breaks b
+    WHERE b.payout_id = p.id AND b.break_type = 'COMPLETED_BUT_MISSING'
+  )
+ORDER BY p.updated_at ASC
+LIMIT $3
+`
+
+type ListCompletedPayoutsMissingSettlementParams struct {
+	CompletedAfter  pgtype.Timestamptz `db:"completed_after" json:"completed_after"`
+	CompletedBefore pgtype.Timestamptz `db:"completed_before" json:"completed_before"`
+	RowLimit        int32              `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListCompletedPayoutsMissingSettlement(ctx context.Context, arg ListCompletedPayoutsMissingSettlementParams) ([]Payout, error) {
+	rows, err := q.db.Query(ctx, listCompletedPayoutsMissingSettlement, arg.CompletedAfter, arg.CompletedBefore, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Payout
+	for rows.Next() {
+		var i Payout
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.AccountID,
+			&i.AmountMicros,
+			&i.Currency,
+			&i.Status,
+			&i.GatewayRef,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Attempts,
+			&i.RequestedBy,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.BeneficiaryID,
+			&i.LimitReservation,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReconciliationBreaks = `-- name: ListReconciliationBreaks :many
+SELECT id, break_type, status, payout_id, line_id, file_id, gateway_ref, currency, expected_micros, actual_micros, details, created_at, cleared_by, cleared_at, resolution_note FROM reconciliation_breaks
+WHERE ($1::text IS NULL OR status = $1::text)
+  AND ($2::text IS NULL OR break_type = $2::text)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $3
+`
+
+type ListReconciliationBreaksParams struct {
+	Status    *string `db:"status" json:"status"`
+	BreakType *string `db:"break_type" json:"break_type"`
+	RowOffset int32   `db:"row_offset" json:"row_offset"`
+	RowLimit  int32   `db:"row_limit" json:"row_limit"`
+}
+
+func (q *Queries) ListReconciliationBreaks(ctx context.Context, arg ListReconciliationBreaksParams) ([]ReconciliationBreak, error) {
+	rows, err := q.db.Query(ctx, listReconciliationBreaks,
+		arg.Status,
+		arg.BreakType,
+		arg.RowOffset,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReconciliationBreak
+	for rows.Next() {
+		var i ReconciliationBreak
+		if err := rows.Scan(
+			&i.ID,
+			&i.BreakType,
+			&i.Status,
+			&i.PayoutID,
+			&i.LineID,
+			&i.FileID,
+			&i.GatewayRef,
+			&i.Currency,
+			&i.ExpectedMicros,
+			&i.ActualMicros,
+			&i.Details,
+			&i.CreatedAt,
+			&i.ClearedBy,
+			&i.ClearedAt,
+			&i.ResolutionNote,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSettledLinesForFailedPayouts = `-- name: ListSettledLinesForFailedPayouts :many
+SELECT l.id, l.file_id, l.line_no, l.gateway_ref, l.amount_micros, l.currency, l.direction, l.booked_at, l.description, l.payout_id, l.match_status, l.created_at, p.status AS payout_status, p.amount_micros AS payout_amount_micros
+FROM settlement_lines l
+INNER JOIN payouts p ON p.id = l.payout_id
+WHERE l.direction = 'debit'
+  AND p.status IN ('FAILED', 'REJECTED')
+  AND NOT EXISTS (
+    SELECT 1 FROM reconciliation_breaks b
+    WHERE b.line_id = l.id AND b.break_type = 'PAID_BUT_FAILED'
+  )
+ORDER BY l.created_at ASC
+LIMIT $1
+`
+
+type ListSettledLinesForFailedPayoutsRow struct {
+	ID                 pgtype.UUID        `db:"id" json:"id"`
+	FileID             pgtype.UUID        `db:"file_id" json:"file_id"`
+	LineNo             int32              `db:"line_no" json:"line_no"`
+	GatewayRef         string             `db:"gateway_ref" json:"gateway_ref"`
+	AmountMicros       int64              `db:"amount_micros" json:"amount_micros"`
+	Currency           string             `db:"currency" json:"currency"`
+	Direction          string             `db:"direction" json:"direction"`
+	BookedAt           pgtype.Timestamptz `db:"booked_at" json:"booked_at"`
+	Description        *string            `db:"description" json:"description"`
+	PayoutID           pgtype.UUID        `db:"payout_id" json:"payout_id"`
+	MatchStatus        string             `db:"match_status" json:"match_status"`
+	CreatedAt          pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	PayoutStatus       string             `db:"payout_status" json:"payout_status"`
+	PayoutAmountMicros int64              `db:"payout_amount_micros" json:"payout_amount_micros"`
+}
+
+func (q *Queries) ListSettledLinesForFailedPayouts(ctx context.Context, limit int32) ([]ListSettledLinesForFailedPayoutsRow, error) {
+	rows, err := q.db.Query(ctx, listSettledLinesForFailedPayouts, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSettledLinesForFailedPayoutsRow
+	for rows.Next() {
+		var i ListSettledLinesForFailedPayoutsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.LineNo,
+			&i.GatewayRef,
+			&i.AmountMicros,
+			&i.Currency,
+			&i.Direction,
+			&i.BookedAt,
+			&i.Description,
+			&i.PayoutID,
+			&i.MatchStatus,
+			&i.CreatedAt,
+			&i.PayoutStatus,
+			&i.PayoutAmountMicros,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSettlementFiles = `-- name: ListSettlementFiles :many
+SELECT id, file_name, format, sha256, period_start, period_end, line_count, matched_count, break_count, imported_by, imported_at FROM settlement_files
+ORDER BY imported_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type ListSettlementFilesParams struct {
+	Limit  int32 `db:"limit" json:"limit"`
+	Offset int32 `db:"offset" json:"offset"`
+}
+
+func (q *Queries) ListSettlementFiles(ctx context.Context, arg ListSettlementFilesParams) ([]SettlementFile, error) {
+	rows, err := q.db.Query(ctx, listSettlementFiles, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SettlementFile
+	for rows.Next() {
+		var i SettlementFile
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileName,
+			&i.Format,
+			&i.Sha256,
+			&i.PeriodStart,
+			&i.PeriodEnd,
+			&i.LineCount,
+			&i.MatchedCount,
+			&i.BreakCount,
+			&i.ImportedBy,
+			&i.ImportedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateSettlementFileCounts = `-- name: UpdateSettlementFileCounts :one
+UPDATE settlement_files
+SET line_count = $2, matched_count = $3, break_count = $4
+WHERE id = $1
+RETURNING id, file_name, format, sha256, period_start, period_end, line_count, matched_count, break_count, imported_by, imported_at
+`
+
+type UpdateSettlementFileCountsParams struct {
+	ID           pgtype.UUID `db:"id" json:"id"`
+	LineCount    int32       `db:"line_count" json:"line_count"`
+	MatchedCount int32       `db:"matched_count" json:"matched_count"`
+	BreakCount   int32       `db:"break_count" json:"break_count"`
+}
+
+func (q *Queries) UpdateSettlementFileCounts(ctx context.Context, arg UpdateSettlementFileCountsParams) (SettlementFile, error) {
+	row := q.db.QueryRow(ctx, updateSettlementFileCounts,
+		arg.ID,
+		arg.LineCount,
+		arg.MatchedCount,
+		arg.BreakCount,
+	)
+	var i SettlementFile
+	err := row.Scan(
+		&i.ID,
+		&i.FileName,
+		&i.Format,
+		&i.Sha256,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.LineCount,
+		&i.MatchedCount,
+		&i.BreakCount,
+		&i.ImportedBy,
+		&i.ImportedAt,
+	)
+	return i, err
+}
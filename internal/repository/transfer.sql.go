@@ -105,18 +105,25 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 }
 
 const getAccountBalanceAndCurrency = `-- name: GetAccountBalanceAndCurrency :one
-SELECT balance, currency FROM accounts WHERE id = $1
+SELECT balance, locked_micros, overdraft_limit_micros, currency FROM accounts WHERE id = $1
 `
 
 type GetAccountBalanceAndCurrencyRow struct {
-	Balance  int64  `db:"balance" json:"balance"`
-	Currency string `db:"currency" json:"currency"`
+	Balance              int64  `db:"balance" json:"balance"`
+	LockedMicros         int64  `db:"locked_micros" json:"locked_micros"`
+	OverdraftLimitMicros int64  `db:"overdraft_limit_micros" json:"overdraft_limit_micros"`
+	Currency             string `db:"currency" json:"currency"`
 }
 
 func (q *Queries) GetAccountBalanceAndCurrency(ctx context.Context, id pgtype.UUID) (GetAccountBalanceAndCurrencyRow, error) {
 	row := q.db.QueryRow(ctx, getAccountBalanceAndCurrency, id)
 	var i GetAccountBalanceAndCurrencyRow
-	err := row.Scan(&i.Balance, &i.Currency)
+	err := row.Scan(
+		&i.Balance,
+		&i.LockedMicros,
+		&i.OverdraftLimitMicros,
+		&i.Currency,
+	)
 	return i, err
 }
 
@@ -181,13 +188,14 @@ func (q *Queries) GetTransactionStatusForUpdate(ctx context.Context, id pgtype.U
 }
 
 const lockAccount = `-- name: LockAccount :one
-SELECT id FROM accounts WHERE id = $1 FOR UPDATE
+SELECT status FROM accounts WHERE id = $1 FOR UPDATE
 `
 
-func (q *Queries) LockAccount(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+func (q *Queries) LockAccount(ctx context.Context, id pgtype.UUID) (string, error) {
 	row := q.db.QueryRow(ctx, lockAccount, id)
-	err := row.Scan(&id)
-	return id, err
+	var status string
+	err := row.Scan(&status)
+	return status, err
 }
 
 const updateAccountBalance = `-- name: UpdateAccountBalance :execrows
@@ -0,0 +1,75 @@
+package sanctions
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// euEntity is the subset of a sanctionEntity in the EU consolidated list
+// (FSF XML) needed for screening. Tags carry no namespace so every schema
+// version parses.
+type euEntity struct {
+	LogicalID   string `xml:"logicalId,attr"`
+	SubjectType struct {
+		Code string `xml:"code,attr"`
+	} `xml:"subjectType"`
+	Regulations []struct {
+		Programme string `xml:"programme,attr"`
+	} `xml:"regulation"`
+	NameAliases []struct {
+		WholeName string `xml:"wholeName,attr"`
+	} `xml:"nameAlias"`
+}
+
+// parseEUConsolidated streams sanctionEntity elements so the full list,
+// tens of megabytes, is never held as one document.
+func parseEUConsolidated(data []byte) ([]Entry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var entries []Entry
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read eu consolidated xml: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "sanctionEntity" {
+			continue
+		}
+		var entity euEntity
+		if err := decoder.DecodeElement(&entity, &start); err != nil {
+			return nil, fmt.Errorf("decode sanctionEntity: %w", err)
+		}
+		if entity.LogicalID == "" {
+			return nil, errors.New("sanctionEntity without logicalId")
+		}
+
+		programmes := make([]string, 0, len(entity.Regulations))
+		for _, regulation := range entity.Regulations {
+			if p := strings.TrimSpace(regulation.Programme); p != "" {
+				programmes = append(programmes, p)
+			}
+		}
+		seen := make(map[string]bool, len(entity.NameAliases))
+		for _, alias := range entity.NameAliases {
+			name := strings.TrimSpace(alias.WholeName)
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			entries = append(entries, Entry{
+				Source:     SourceEUConsolidated,
+				ExternalID: entity.LogicalID,
+				Name:       name,
+				EntryType:  strings.ToLower(entity.SubjectType.Code),
+				Programs:   strings.Join(programmes, "; "),
+			})
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Package sanctions parses sanctions lists into named entries and fuzzy
+// matches payee names against them.
+package sanctions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Supported list sources.
+const (
+	// SourceOFACSDN is the US Treasury OFAC Specially Designated Nationals
+	// list in its CSV distribution (SDN.CSV, optionally with ALT.CSV).
+	SourceOFACSDN = "OFAC_SDN"
+	// SourceEUConsolidated is the EU consolidated financial sanctions list
+	// in its XML distribution.
+	SourceEUConsolidated = "EU_CONSOLIDATED"
+)
+
+// Entry is one name a listed party is known by. A party with aliases has
+// one entry per name sharing the same ExternalID.
+type Entry struct {
+	Source     string
+	ExternalID string
+	Name       string
+	EntryType  string
+	Programs   string
+}
+
+// Parse reads a list in the given source's format.
+func Parse(source string, data []byte) ([]Entry, error) {
+	source = strings.ToUpper(strings.TrimSpace(source))
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var (
+		entries []Entry
+		err     error
+	)
+	switch source {
+	case SourceOFACSDN:
+		entries, err = parseOFACSDN(data)
+	case SourceEUConsolidated:
+		entries, err = parseEUConsolidated(data)
+	default:
+		return nil, fmt.Errorf("unsupported sanctions list source %q", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("sanctions list has no entries")
+	}
+	return entries, nil
+}
+
+// ValidSource reports whether source is a supported list.
+func ValidSource(source string) bool {
+	return source == SourceOFACSDN || source == SourceEUConsolidated
+}
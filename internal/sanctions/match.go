@@ -0,0 +1,202 @@
+package sanctions
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// DefaultThreshold is the score at or above which a name is a match when
+// no threshold is configured.
+const DefaultThreshold = 0.90
+
+// Thresholds are the minimum match scores, from 0 to 1, per list source.
+type Thresholds struct {
+	Default  float64
+	BySource map[string]float64
+}
+
+// For returns the threshold that applies to source.
+func (t Thresholds) For(source string) float64 {
+	if v, ok := t.BySource[source]; ok {
+		return v
+	}
+	if t.Default > 0 {
+		return t.Default
+	}
+	return DefaultThreshold
+}
+
+// Match is a listed name a screened name resembles.
+type Match struct {
+	Entry Entry
+	Score float64
+}
+
+type indexedEntry struct {
+	entry      Entry
+	normalized string
+	sorted     string
+	tokens     []string
+}
+
+// Matcher scores names against a fixed set of entries. It is safe for
+// concurrent use.
+type Matcher struct {
+	entries []indexedEntry
+}
+
+// NewMatcher indexes entries for matching. Entries whose name normalizes to
+// nothing are dropped.
+func NewMatcher(entries []Entry) *Matcher {
+	m := &Matcher{entries: make([]indexedEntry, 0, len(entries))}
+	for _, entry := range entries {
+		normalized := Normalize(entry.Name)
+		if normalized == "" {
+			continue
+		}
+		tokens := strings.Fields(normalized)
+		m.entries = append(m.entries, indexedEntry{
+			entry:      entry,
+			normalized: normalized,
+			sorted:     sortedTokens(tokens),
+			tokens:     tokens,
+		})
+	}
+	return m
+}
+
+// Len returns the number of indexed names.
+func (m *Matcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.entries)
+}
+
+// Best returns the highest-scoring entry that reaches its source's
+// threshold, if any.
+func (m *Matcher) Best(name string, thresholds Thresholds) (Match, bool) {
+	normalized := Normalize(name)
+	if m == nil || normalized == "" {
+		return Match{}, false
+	}
+	tokens := strings.Fields(normalized)
+	sorted := sortedTokens(tokens)
+
+	var best Match
+	found := false
+	for _, candidate := range m.entries {
+		score := nameScore(normalized, sorted, tokens, candidate)
+		if score < thresholds.For(candidate.entry.Source) {
+			continue
+		}
+		if !found || score > best.Score {
+			best = Match{Entry: candidate.entry, Score: score}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// Normalize upper-cases name, strips diacritics and reduces everything
+// other than letters and digits to single spaces.
+func Normalize(name string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+			space = false
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// nameScore is the best of three comparisons: the names as written, with
+// words reordered (lists write "SURNAME, Given"), and how well each word
+// of a multi-word listed name is found in the screened name, which
+// tolerates titles and extra middle names.
+func nameScore(normalized, sorted string, tokens []string, candidate indexedEntry) float64 {
+	score := max(jaroWinkler(normalized, candidate.normalized), jaroWinkler(sorted, candidate.sorted))
+	if len(candidate.tokens) < 2 || len(tokens) < len(candidate.tokens) {
+		return score
+	}
+	total := 0.0
+	for _, listed := range candidate.tokens {
+		bestToken := 0.0
+		for _, token := range tokens {
+			bestToken = max(bestToken, jaroWinkler(token, listed))
+		}
+		total += bestToken
+	}
+	return max(score, total/float64(len(candidate.tokens)))
+}
+
+func sortedTokens(tokens []string) string {
+	sorted := slices.Clone(tokens)
+	slices.Sort(sorted)
+	return strings.Join(sorted, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b, from 0 (no
+// similarity) to 1 (identical).
+func jaroWinkler(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	window := max(len(ra), len(rb))/2 - 1
+	window = max(window, 0)
+
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if matchedB[j] || ra[i] != rb[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
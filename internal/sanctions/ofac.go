@@ -0,0 +1,95 @@
+package sanctions
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ofacNull is how the OFAC CSV files spell an empty field.
+const ofacNull = "-0-"
+
+// SDN.CSV has no header. The columns used are ent_num, SDN_Name, SDN_Type
+// and Program; ALT.CSV rows are ent_num, alt_num, alt_type, alt_name.
+func parseOFACSDN(data []byte) ([]Entry, error) {
+	rows, err := readOFACRows(data, 4)
+	if err != nil {
+		return nil, fmt.Errorf("read sdn csv: %w", err)
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		name := ofacField(row[1])
+		if name == "" {
+			continue
+		}
+		entries = append(entries, Entry{
+			Source:     SourceOFACSDN,
+			ExternalID: ofacField(row[0]),
+			Name:       name,
+			EntryType:  strings.ToLower(ofacField(row[2])),
+			Programs:   ofacField(row[3]),
+		})
+	}
+	return entries, nil
+}
+
+// ParseOFACAliases reads ALT.CSV and returns one entry per alias of a party
+// in primary, copying its type and programs. Aliases of parties not in
+// primary are skipped.
+func ParseOFACAliases(data []byte, primary []Entry) ([]Entry, error) {
+	rows, err := readOFACRows(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), 4)
+	if err != nil {
+		return nil, fmt.Errorf("read alt csv: %w", err)
+	}
+	parties := make(map[string]Entry, len(primary))
+	for _, entry := range primary {
+		parties[entry.ExternalID] = entry
+	}
+	var aliases []Entry
+	for _, row := range rows {
+		party, ok := parties[ofacField(row[0])]
+		name := ofacField(row[3])
+		if !ok || name == "" {
+			continue
+		}
+		party.Name = name
+		aliases = append(aliases, party)
+	}
+	return aliases, nil
+}
+
+func readOFACRows(data []byte, minColumns int) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The files end with a SUB (0x1A) control line.
+		if len(row) == 1 && strings.Trim(row[0], "\x1a \t") == "" {
+			continue
+		}
+		if len(row) < minColumns {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d has %d columns, want at least %d", line, len(row), minColumns)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func ofacField(value string) string {
+	value = strings.TrimSpace(value)
+	if value == ofacNull {
+		return ""
+	}
+	return value
+}
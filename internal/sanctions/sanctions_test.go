@@ -0,0 +1,113 @@
+package sanctions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOFACSDN(t *testing.T) {
+	sdn := []byte("\xef\xbb\xbf" +
+		`36,"AEROCARIBBEAN AIRLINES",-0- ,"CUBA",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ` + "\n" +
+		`2674,"ABU NIDAL ORGANIZATION",-0- ,"SDGT] [NS-PLC",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"Also known as ANO."` + "\n" +
+		`6365,"AL-ZAWAHIRI, Ayman","individual","SDGT",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ` + "\n" +
+		"\x1a\n")
+
+	entries, err := Parse(SourceOFACSDN, sdn)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, Entry{Source: SourceOFACSDN, ExternalID: "6365", Name: "AL-ZAWAHIRI, Ayman", EntryType: "individual", Programs: "SDGT"}, entries[2])
+	require.Empty(t, entries[0].EntryType)
+
+	alt := []byte(`2674,1,"aka","ANO",-0- ` + "\n" + `2674,2,"aka","FATAH REVOLUTIONARY COUNCIL",-0- ` + "\n" + `9999,3,"aka","UNKNOWN PARTY",-0- ` + "\n")
+	aliases, err := ParseOFACAliases(alt, entries)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+	require.Equal(t, "2674", aliases[1].ExternalID)
+	require.Equal(t, "FATAH REVOLUTIONARY COUNCIL", aliases[1].Name)
+	require.Equal(t, "SDGT] [NS-PLC", aliases[1].Programs)
+
+	_, err = Parse(SourceOFACSDN, []byte("1,ONLY TWO\n"))
+	require.Error(t, err)
+	_, err = Parse("UN", sdn)
+	require.Error(t, err)
+}
+
+func TestParseEUConsolidated(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<export xmlns="http://eu.europa.ec/fpi/fsd/export" generationDate="2026-01-05T10:00:00">
+  <sanctionEntity logicalId="13" designationDetails="" unitedNationId="">
+    <regulation programme="TAQA" />
+    <subjectType code="person" classificationCode="P" />
+    <nameAlias firstName="Saddam" lastName="Hussein Al-Tikriti" wholeName="Saddam Hussein Al-Tikriti" />
+    <nameAlias wholeName="Abu Ali" />
+    <nameAlias wholeName="Abu Ali" />
+  </sanctionEntity>
+  <sanctionEntity logicalId="20">
+    <regulation programme="IRQ" />
+    <subjectType code="enterprise" classificationCode="E" />
+    <nameAlias wholeName="" />
+    <nameAlias wholeName="Baghdad Trading Co" />
+  </sanctionEntity>
+</export>`)
+
+	entries, err := Parse(SourceEUConsolidated, data)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, Entry{Source: SourceEUConsolidated, ExternalID: "13", Name: "Saddam Hussein Al-Tikriti", EntryType: "person", Programs: "TAQA"}, entries[0])
+	require.Equal(t, "Abu Ali", entries[1].Name)
+	require.Equal(t, "enterprise", entries[2].EntryType)
+
+	_, err = Parse(SourceEUConsolidated, []byte(`<export></export>`))
+	require.Error(t, err)
+}
+
+func TestNormalize(t *testing.T) {
+	require.Equal(t, "AL ZAWAHIRI AYMAN", Normalize("AL-ZAWAHIRI, Ayman"))
+	require.Equal(t, "JOSE MULLER", Normalize("  José   Müller. "))
+	require.Empty(t, Normalize("-- ,"))
+}
+
+func TestMatcherBest(t *testing.T) {
+	matcher := NewMatcher([]Entry{
+		{Source: SourceOFACSDN, ExternalID: "6365", Name: "AL-ZAWAHIRI, Ayman"},
+		{Source: SourceEUConsolidated, ExternalID: "20", Name: "Baghdad Trading Co"},
+		{Source: SourceOFACSDN, ExternalID: "1", Name: "-"},
+	})
+	require.Equal(t, 2, matcher.Len())
+	thresholds := Thresholds{Default: 0.9}
+
+	cases := map[string]string{
+		"reordered":      "Ayman al Zawahiri",
+		"misspelt":       "Aiman Al-Zawahri",
+		"extra words":    "Dr Ayman Mohammed Al Zawahiri",
+		"company suffix": "BAGHDAD TRADING COMPANY",
+	}
+	for name, payee := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, ok := matcher.Best(payee, thresholds)
+			require.True(t, ok, payee)
+		})
+	}
+
+	for _, payee := range []string{"Jane Smith", "Acme Widgets Ltd", "Ayman", ""} {
+		match, ok := matcher.Best(payee, thresholds)
+		require.False(t, ok, "%s matched %s at %.3f", payee, match.Entry.Name, match.Score)
+	}
+
+	match, ok := matcher.Best("Ayman al Zawahiri", thresholds)
+	require.True(t, ok)
+	require.Equal(t, "6365", match.Entry.ExternalID)
+	require.InDelta(t, 1.0, match.Score, 0.0001)
+
+	strict := Thresholds{Default: 0.9, BySource: map[string]float64{SourceOFACSDN: 1}}
+	_, ok = matcher.Best("Aiman Al-Zawahri", strict)
+	require.False(t, ok)
+}
+
+func TestJaroWinkler(t *testing.T) {
+	require.InDelta(t, 0.961, jaroWinkler("MARTHA", "MARHTA"), 0.001)
+	require.InDelta(t, 0.840, jaroWinkler("DWAYNE", "DUANE"), 0.001)
+	require.Equal(t, 0.0, jaroWinkler("ABC", ""))
+	require.Equal(t, 1.0, jaroWinkler("ABC", "ABC"))
+}
@@ -10,6 +10,7 @@ import (
 
 type AccountService struct {
 	repo *repository.Repository
+	kyc  *KYCService
 }
 
 func NewAccountService(repo *repository.Repository) *AccountService {
@@ -33,12 +34,22 @@ func (s *AccountService) GetStatement(ctx context.Context, accountID uuid.UUID,
 	return s.repo.GetEntries(ctx, accountID, pageSize, offset)
 }
 
-func (s *AccountService) CreateAccount(ctx context.Context, userID uuid.UUID, currency string, balance int64) (*models.Account, error) {
+// WithKYC only opens accounts in currencies the user's KYC tier allows.
+func (s *AccountService) WithKYC(kyc *KYCService) *AccountService {
+	s.kyc = kyc
+	return s
+}
+
+// CreateAccount opens an empty account. Funds only arrive through the
+// ledger, e.g. TransferService.PostOpeningBalance.
+func (s *AccountService) CreateAccount(ctx context.Context, userID uuid.UUID, currency string) (*models.Account, error) {
+	if err := s.kyc.CheckCurrency(ctx, userID, currency); err != nil {
+		return nil, err
+	}
 	account := &models.Account{
 		ID:       uuid.New(),
 		UserID:   userID,
 		Currency: currency,
-		Balance:  balance,
 	}
 	if err := s.repo.CreateAccount(ctx, account); err != nil {
 		return nil, err
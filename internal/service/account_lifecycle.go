@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Freeze scopes accepted by AccountLifecycleService.Freeze.
+const (
+	FreezeScopeDebits = "DEBITS"
+	FreezeScopeAll    = "ALL"
+)
+
+var (
+	// ErrAccountFrozen indicates the account's status does not allow the movement.
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrAccountClosed indicates the account has been closed.
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrAccountNotFound indicates the account does not exist.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountNotFrozen indicates an unfreeze of an account that is not frozen.
+	ErrAccountNotFrozen = errors.New("account is not frozen")
+	// ErrAccountBalanceNotZero indicates a close without a sweep target on a funded account.
+	ErrAccountBalanceNotZero = errors.New("account balance is not zero")
+	// ErrAccountFundsLocked indicates funds are held by payouts still in flight.
+	ErrAccountFundsLocked = errors.New("account has funds locked by open payouts")
+	// ErrSystemAccountStatus indicates a status change on a system account.
+	ErrSystemAccountStatus = errors.New("system account status cannot be changed")
+	// ErrInvalidStatusReason indicates an unknown reason code or a missing note.
+	ErrInvalidStatusReason = errors.New("invalid status reason")
+	// ErrInvalidFreezeScope indicates a freeze scope other than DEBITS or ALL.
+	ErrInvalidFreezeScope = errors.New("freeze scope must be DEBITS or ALL")
+	// ErrInvalidSweepAccount indicates an unusable sweep target.
+	ErrInvalidSweepAccount = errors.New("invalid sweep account")
+)
+
+var accountStatusReasons = map[string]struct{}{
+	domain.AccountReasonFraudSuspected:     {},
+	domain.AccountReasonAccountCompromised: {},
+	domain.AccountReasonLegalOrder:         {},
+	domain.AccountReasonComplianceReview:   {},
+	domain.AccountReasonCustomerRequest:    {},
+	domain.AccountReasonReviewCleared:      {},
+	domain.AccountReasonOther:              {},
+}
+
+// checkAccountDebit reports whether an account in status may be debited.
+// Callers must hold the account row lock.
+func checkAccountDebit(accountID uuid.UUID, status string) error {
+	switch status {
+	case domain.AccountStatusActive:
+		return nil
+	case domain.AccountStatusClosed:
+		return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+	default:
+		return fmt.Errorf("%w: account %s does not accept debits", ErrAccountFrozen, accountID)
+	}
+}
+
+// checkAccountCredit reports whether an account in status may be credited.
+// Callers must hold the account row lock.
+func checkAccountCredit(accountID uuid.UUID, status string) error {
+	switch status {
+	case domain.AccountStatusActive, domain.AccountStatusFrozenDebits:
+		return nil
+	case domain.AccountStatusClosed:
+		return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+	default:
+		return fmt.Errorf("%w: account %s does not accept credits", ErrAccountFrozen, accountID)
+	}
+}
+
+// AccountLifecycleService freezes, unfreezes and closes accounts. Every
+// change is audited with its reason code and published to the outbox.
+type AccountLifecycleService struct {
+	store QueryStore
+	audit *AuditService
+}
+
+// NewAccountLifecycleService creates a new AccountLifecycleService instance.
+func NewAccountLifecycleService(store QueryStore) *AccountLifecycleService {
+	return &AccountLifecycleService{
+		store: store,
+		audit: NewAuditService(store),
+	}
+}
+
+// StatusChange describes who changes an account's status and why.
+type StatusChange struct {
+	ActorID uuid.UUID
+	Reason  string
+	Note    string
+}
+
+func (c StatusChange) validate() error {
+	if _, ok := accountStatusReasons[c.Reason]; !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidStatusReason, c.Reason)
+	}
+	if c.Reason == domain.AccountReasonOther && strings.TrimSpace(c.Note) == "" {
+		return fmt.Errorf("%w: note is required for reason OTHER", ErrInvalidStatusReason)
+	}
+	return nil
+}
+
+// CloseAccountResult is the closed account and, when a balance was swept,
+// the sweep transaction.
+type CloseAccountResult struct {
+	Account *models.Account     `json:"account"`
+	Sweep   *models.Transaction `json:"sweep_transaction,omitempty"`
+}
+
+// Freeze blocks debits (scope DEBITS) or all movements (scope ALL) on an
+// account. Freezing a frozen account changes its scope and reason.
+func (s *AccountLifecycleService) Freeze(ctx context.Context, accountID uuid.UUID, scope string, change StatusChange) (*models.Account, error) {
+	var status string
+	switch scope {
+	case FreezeScopeDebits:
+		status = domain.AccountStatusFrozenDebits
+	case FreezeScopeAll:
+		status = domain.AccountStatusFrozenAll
+	default:
+		return nil, ErrInvalidFreezeScope
+	}
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	var account *models.Account
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		prev, err := s.lockForStatusChange(ctx, qtx, accountID)
+		if err != nil {
+			return err
+		}
+		if prev == domain.AccountStatusClosed {
+			return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+		}
+		account, err = s.setStatus(ctx, qtx, accountID, prev, status, "frozen", change, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Unfreeze returns a frozen account to ACTIVE.
+func (s *AccountLifecycleService) Unfreeze(ctx context.Context, accountID uuid.UUID, change StatusChange) (*models.Account, error) {
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	var account *models.Account
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		prev, err := s.lockForStatusChange(ctx, qtx, accountID)
+		if err != nil {
+			return err
+		}
+		switch prev {
+		case domain.AccountStatusFrozenDebits, domain.AccountStatusFrozenAll:
+		case domain.AccountStatusClosed:
+			return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+		default:
+			return ErrAccountNotFrozen
+		}
+		account, err = s.setStatus(ctx, qtx, accountID, prev, domain.AccountStatusActive, "unfrozen", change, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Close closes an account. The account must have no funds locked by open
+// payouts, and its balance must be zero unless sweepTo names another
+// account in the same currency that accepts credits, in which case the
+// remainder is transferred there first. Sweeping is a debit, so a frozen
+// account has to be unfrozen before it can be closed with a balance.
+func (s *AccountLifecycleService) Close(ctx context.Context, accountID uuid.UUID, change StatusChange, sweepTo *uuid.UUID) (*CloseAccountResult, error) {
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+	if sweepTo != nil && (*sweepTo == accountID || isSystemAccount(*sweepTo)) {
+		return nil, ErrInvalidSweepAccount
+	}
+
+	result := &CloseAccountResult{}
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		// Lock the account and the sweep target in the same stable order
+		// Transfer uses so concurrent movements cannot deadlock.
+		ids := []uuid.UUID{accountID}
+		if sweepTo != nil {
+			ids = append(ids, *sweepTo)
+			sortUUIDs(ids)
+		}
+		statuses := make(map[uuid.UUID]string, len(ids))
+		for _, id := range ids {
+			status, err := s.lockForStatusChange(ctx, qtx, id)
+			if err != nil {
+				if id != accountID && errors.Is(err, ErrAccountNotFound) {
+					return fmt.Errorf("%w: account %s not found", ErrInvalidSweepAccount, id)
+				}
+				return err
+			}
+			statuses[id] = status
+		}
+		prev := statuses[accountID]
+		if prev == domain.AccountStatusClosed {
+			return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+		}
+
+		row, err := qtx.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(accountID))
+		if err != nil {
+			return fmt.Errorf("failed to fetch account: %w", err)
+		}
+		if row.LockedMicros > 0 {
+			return fmt.Errorf("%w: %d micros", ErrAccountFundsLocked, row.LockedMicros)
+		}
+		if row.Balance < 0 || (row.Balance > 0 && sweepTo == nil) {
+			return fmt.Errorf("%w: balance is %d micros", ErrAccountBalanceNotZero, row.Balance)
+		}
+
+		metadata := map[string]any{}
+		if row.Balance > 0 {
+			if err := checkAccountDebit(accountID, prev); err != nil {
+				return err
+			}
+			if err := checkAccountCredit(*sweepTo, statuses[*sweepTo]); err != nil {
+				return err
+			}
+			sweep, err := s.sweep(ctx, qtx, accountID, *sweepTo, row.Balance, row.Currency, change.ActorID)
+			if err != nil {
+				return err
+			}
+			result.Sweep = sweep
+			metadata["sweep_transaction_id"] = sweep.ID
+			metadata["sweep_to_account_id"] = *sweepTo
+			metadata["swept_micros"] = sweep.Amount
+		}
+
+		result.Account, err = s.setStatus(ctx, qtx, accountID, prev, domain.AccountStatusClosed, "closed", change, metadata)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockForStatusChange locks the account row and returns its current status.
+func (s *AccountLifecycleService) lockForStatusChange(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID) (string, error) {
+	if isSystemAccount(accountID) {
+		return "", ErrSystemAccountStatus
+	}
+	status, err := qtx.LockAccount(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrAccountNotFound
+		}
+		return "", fmt.Errorf("failed to lock account %s: %w", accountID, err)
+	}
+	return status, nil
+}
+
+// sweep moves the whole balance of a closing account to another account as
+// a completed transfer.
+func (s *AccountLifecycleService) sweep(ctx context.Context, qtx *repository.Queries, fromID, toID uuid.UUID, amount int64, currency string, actorID uuid.UUID) (*models.Transaction, error) {
+	toCurrency, err := qtx.GetAccountCurrency(ctx, repository.ToPgUUID(toID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sweep account: %w", err)
+	}
+	if toCurrency != currency {
+		return nil, fmt.Errorf("%w: account is %s, sweep account is %s", ErrCurrencyMismatch, currency, toCurrency)
+	}
+
+	transactionID := uuid.New()
+	referenceID := "account-close:" + fromID.String()
+	details := map[string]any{
+		"kind":            "account_close_sweep",
+		"from_account_id": fromID,
+		"to_account_id":   toID,
+	}
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if _, err := qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(transactionID),
+		Amount:      amount,
+		Currency:    currency,
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusPending,
+		ReferenceID: referenceID,
+		Metadata:    metadata,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create sweep transaction: %w", err)
+	}
+	if err := s.audit.Write(ctx, qtx, "transaction", transactionID, &actorID, "created", "", domain.TxStatusPending, metadata); err != nil {
+		return nil, err
+	}
+	if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusProcessing, &actorID, "processing_started", nil); err != nil {
+		return nil, fmt.Errorf("failed to transition sweep to processing: %w", err)
+	}
+
+	if err := postEntries(ctx, qtx, transactionID, fromID, toID, amount); err != nil {
+		return nil, err
+	}
+
+	if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, &actorID, "completed", nil); err != nil {
+		return nil, fmt.Errorf("failed to complete sweep: %w", err)
+	}
+	if err := writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventTransferCompleted, map[string]any{
+		"transaction_id":  transactionID,
+		"reference_id":    referenceID,
+		"from_account_id": fromID,
+		"to_account_id":   toID,
+		"amount_micros":   amount,
+		"currency":        currency,
+		"kind":            "account_close_sweep",
+	}); err != nil {
+		return nil, err
+	}
+	return &models.Transaction{
+		ID:          transactionID,
+		Amount:      amount,
+		Currency:    currency,
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: referenceID,
+		Metadata:    details,
+	}, nil
+}
+
+// setStatus writes the new status with its audit record and outbox event.
+func (s *AccountLifecycleService) setStatus(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID, prev, next, action string, change StatusChange, extra map[string]any) (*models.Account, error) {
+	reason := change.Reason
+	row, err := qtx.UpdateAccountStatus(ctx, repository.UpdateAccountStatusParams{
+		Status:          next,
+		StatusReason:    &reason,
+		StatusChangedBy: repository.ToPgUUID(change.ActorID),
+		ID:              repository.ToPgUUID(accountID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	details := map[string]any{"reason": change.Reason}
+	if change.Note != "" {
+		details["note"] = change.Note
+	}
+	for k, v := range extra {
+		details[k] = v
+	}
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := s.audit.Write(ctx, qtx, "account", accountID, &change.ActorID, action, prev, next, metadata); err != nil {
+		return nil, err
+	}
+
+	payload := map[string]any{
+		"account_id":  accountID,
+		"prev_status": prev,
+		"status":      next,
+	}
+	for k, v := range details {
+		payload[k] = v
+	}
+	if err := writeOutboxEvent(ctx, qtx, outbox.AggregateAccount, accountID, outbox.EventAccountStatusChanged, payload); err != nil {
+		return nil, err
+	}
+
+	return &models.Account{
+		ID:           repository.FromPgUUID(row.ID),
+		UserID:       repository.FromPgUUID(row.UserID),
+		Currency:     row.Currency,
+		Balance:      row.Balance,
+		Status:       row.Status,
+		StatusReason: reason,
+		CreatedAt:    row.CreatedAt.Time,
+	}, nil
+}
+
+func isSystemAccount(id uuid.UUID) bool {
+	switch id.String() {
+	case domain.SystemAccountUSD, domain.SystemAccountEUR, domain.SystemAccountGBP,
+		domain.SystemEquityAccountUSD, domain.SystemEquityAccountEUR, domain.SystemEquityAccountGBP:
+		return true
+	}
+	return false
+}
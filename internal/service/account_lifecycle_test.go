@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusChangeValidation(t *testing.T) {
+	svc := NewAccountLifecycleService(panicStore{})
+	ctx := context.Background()
+	id := uuid.New()
+
+	_, err := svc.Freeze(ctx, id, "SOME", StatusChange{Reason: domain.AccountReasonLegalOrder})
+	require.ErrorIs(t, err, ErrInvalidFreezeScope)
+	_, err = svc.Freeze(ctx, id, FreezeScopeAll, StatusChange{Reason: "BORED"})
+	require.ErrorIs(t, err, ErrInvalidStatusReason)
+	_, err = svc.Unfreeze(ctx, id, StatusChange{Reason: domain.AccountReasonOther})
+	require.ErrorIs(t, err, ErrInvalidStatusReason)
+	_, err = svc.Close(ctx, id, StatusChange{Reason: domain.AccountReasonCustomerRequest}, &id)
+	require.ErrorIs(t, err, ErrInvalidSweepAccount)
+}
+
+func TestAccountFreezeBlocksMovements(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	lifecycle := NewAccountLifecycleService(store)
+	transfers := NewTransferService(store, NewMockExchangeRateService())
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	frozen := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 100}
+	require.NoError(t, repo.CreateAccount(ctx, frozen))
+	other := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 100}
+	require.NoError(t, repo.CreateAccount(ctx, other))
+
+	account, err := lifecycle.Freeze(ctx, frozen.ID, FreezeScopeDebits, StatusChange{ActorID: admin.ID, Reason: domain.AccountReasonFraudSuspected, Note: "card testing"})
+	require.NoError(t, err)
+	require.Equal(t, domain.AccountStatusFrozenDebits, account.Status)
+
+	_, err = transfers.Transfer(ctx, frozen.ID, other.ID, 10, "freeze-debit")
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = transfers.Transfer(ctx, other.ID, frozen.ID, 10, "freeze-credit")
+	require.NoError(t, err)
+
+	_, err = lifecycle.Freeze(ctx, frozen.ID, FreezeScopeAll, StatusChange{ActorID: admin.ID, Reason: domain.AccountReasonLegalOrder})
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, other.ID, frozen.ID, 10, "freeze-all-credit")
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	account, err = lifecycle.Unfreeze(ctx, frozen.ID, StatusChange{ActorID: admin.ID, Reason: domain.AccountReasonReviewCleared})
+	require.NoError(t, err)
+	require.Equal(t, domain.AccountStatusActive, account.Status)
+	_, err = lifecycle.Unfreeze(ctx, frozen.ID, StatusChange{ActorID: admin.ID, Reason: domain.AccountReasonReviewCleared})
+	require.ErrorIs(t, err, ErrAccountNotFrozen)
+	_, err = transfers.Transfer(ctx, frozen.ID, other.ID, 10, "unfrozen-debit")
+	require.NoError(t, err)
+
+	auditRows, err := repository.New(db).GetAuditLogsByEntity(ctx, repository.GetAuditLogsByEntityParams{
+		EntityType: "account",
+		EntityID:   repository.ToPgUUID(frozen.ID),
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRows, 3)
+	require.Equal(t, "frozen", auditRows[0].Action)
+	require.Equal(t, "unfrozen", auditRows[2].Action)
+	require.Contains(t, string(auditRows[0].Metadata), domain.AccountReasonFraudSuspected)
+}
+
+func TestAccountCloseSweepsBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	lifecycle := NewAccountLifecycleService(store)
+	transfers := NewTransferService(store, NewMockExchangeRateService())
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	closing := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 75}
+	require.NoError(t, repo.CreateAccount(ctx, closing))
+	target := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, target))
+	euro := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "EUR", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, euro))
+
+	change := StatusChange{ActorID: admin.ID, Reason: domain.AccountReasonCustomerRequest}
+	_, err := lifecycle.Close(ctx, closing.ID, change, nil)
+	require.ErrorIs(t, err, ErrAccountBalanceNotZero)
+	_, err = lifecycle.Close(ctx, closing.ID, change, &euro.ID)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	result, err := lifecycle.Close(ctx, closing.ID, change, &target.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(75), result.Sweep.Amount)
+
+	targetDB, err := repo.GetAccount(ctx, target.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(75), targetDB.Balance)
+
+	_, err = transfers.Transfer(ctx, target.ID, closing.ID, 10, "credit-closed")
+	require.ErrorIs(t, err, ErrAccountClosed)
+	_, err = lifecycle.Freeze(ctx, closing.ID, FreezeScopeAll, change)
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
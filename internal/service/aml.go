@@ -0,0 +1,796 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// amlSinkName keys the monitoring engine in the outbox delivery ledger.
+const amlSinkName = "aml"
+
+// amlMaxWindow bounds how far back a rule may look.
+const amlMaxWindow = 30 * 24 * time.Hour
+
+// Directions of an observed movement relative to its account.
+const (
+	amlDirectionIn  = "IN"
+	amlDirectionOut = "OUT"
+)
+
+var (
+	// ErrAMLRuleNotFound indicates an unknown monitoring rule code.
+	ErrAMLRuleNotFound = errors.New("aml rule not found")
+	// ErrInvalidAMLRule indicates rule parameters outside their allowed range.
+	ErrInvalidAMLRule = errors.New("invalid aml rule")
+	// ErrAMLCaseNotFound indicates the case does not exist.
+	ErrAMLCaseNotFound = errors.New("aml case not found")
+	// ErrInvalidAMLCaseUpdate indicates a status the case cannot move to or a
+	// close without a resolution and note.
+	ErrInvalidAMLCaseUpdate = errors.New("invalid aml case update")
+)
+
+// amlCaseTransitions lists the statuses each case status may move to. Keeping
+// the same status is allowed so a case can be reassigned; CLOSED is final.
+var amlCaseTransitions = map[string][]string{
+	domain.AMLCaseOpen:          {domain.AMLCaseOpen, domain.AMLCaseInvestigating, domain.AMLCaseClosed},
+	domain.AMLCaseInvestigating: {domain.AMLCaseInvestigating, domain.AMLCaseEscalated, domain.AMLCaseClosed},
+	domain.AMLCaseEscalated:     {domain.AMLCaseEscalated, domain.AMLCaseClosed},
+}
+
+// AMLRule is a monitoring rule and its parameters. How AmountMicros,
+// MinCount and RatioBps are read depends on the rule.
+type AMLRule struct {
+	Code          string     `json:"code"`
+	Description   string     `json:"description"`
+	Enabled       bool       `json:"enabled"`
+	WindowSeconds int32      `json:"window_seconds"`
+	AmountMicros  int64      `json:"amount_micros"`
+	MinCount      int32      `json:"min_count"`
+	RatioBps      int32      `json:"ratio_bps"`
+	Severity      string     `json:"severity"`
+	UpdatedBy     *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// AMLRuleUpdate replaces a rule's parameters.
+type AMLRuleUpdate struct {
+	Enabled       bool   `json:"enabled"`
+	WindowSeconds int32  `json:"window_seconds"`
+	AmountMicros  int64  `json:"amount_micros"`
+	MinCount      int32  `json:"min_count"`
+	RatioBps      int32  `json:"ratio_bps"`
+	Severity      string `json:"severity"`
+}
+
+// AMLAlert is one firing of a rule on an account.
+type AMLAlert struct {
+	ID          uuid.UUID       `json:"id"`
+	CaseID      uuid.UUID       `json:"case_id"`
+	RuleCode    string          `json:"rule_code"`
+	AccountID   uuid.UUID       `json:"account_id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	EventID     uuid.UUID       `json:"event_id"`
+	Severity    string          `json:"severity"`
+	Details     json.RawMessage `json:"details"`
+	WindowStart time.Time       `json:"window_start"`
+	WindowEnd   time.Time       `json:"window_end"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// AMLAlertFilter narrows ListAlerts; zero fields match everything.
+type AMLAlertFilter struct {
+	RuleCode string
+	CaseID   *uuid.UUID
+	UserID   *uuid.UUID
+}
+
+// AMLCase groups the alerts raised on one user for review.
+type AMLCase struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Status     string     `json:"status"`
+	AssignedTo *uuid.UUID `json:"assigned_to,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+	Note       string     `json:"note,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	Alerts     []AMLAlert `json:"alerts,omitempty"`
+}
+
+// AMLCaseUpdate moves a case through its workflow. A nil AssignedTo keeps
+// the current assignee; Resolution is required only when closing.
+type AMLCaseUpdate struct {
+	Status     string     `json:"status"`
+	AssignedTo *uuid.UUID `json:"assigned_to"`
+	Resolution string     `json:"resolution"`
+	Note       string     `json:"note"`
+}
+
+// amlMovement is one account leg of a completed transaction.
+type amlMovement struct {
+	eventID         uuid.UUID
+	eventType       string
+	transactionID   uuid.UUID
+	accountID       uuid.UUID
+	userID          uuid.UUID
+	direction       string
+	amountMicros    int64
+	currency        string
+	counterCurrency string
+	occurredAt      time.Time
+}
+
+// amlHit is a rule that fired on a movement.
+type amlHit struct {
+	windowStart time.Time
+	details     map[string]any
+}
+
+// AMLService is the transaction monitoring engine. It is an outbox sink, so
+// it sees completed transactions after they commit and off the request
+// path; the relay's delivery ledger retries it until an event is recorded.
+// Each movement is stored once and evaluated against the enabled rules over
+// the window ending at the movement. Fired rules become alerts on the
+// user's open case.
+type AMLService struct {
+	store QueryStore
+	audit *AuditService
+}
+
+// NewAMLService creates a new AMLService instance.
+func NewAMLService(store QueryStore) *AMLService {
+	return &AMLService{store: store, audit: NewAuditService(store)}
+}
+
+// Name implements outbox.Sink.
+func (s *AMLService) Name() string { return amlSinkName }
+
+// Publish implements outbox.Sink. Events other than completed transactions
+// are ignored. A redelivered event finds its movements already stored and
+// is not evaluated twice.
+func (s *AMLService) Publish(ctx context.Context, evt outbox.Event) error {
+	movements, err := amlMovementsFromEvent(evt)
+	if err != nil {
+		zap.L().Error("aml event not monitored", zap.String("event_id", evt.ID.String()), zap.String("event_type", evt.Type), zap.Error(err))
+		return nil
+	}
+	if len(movements) == 0 {
+		return nil
+	}
+
+	return s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		recorded := make([]amlMovement, 0, len(movements))
+		for _, m := range movements {
+			if isSystemAccount(m.accountID) {
+				continue
+			}
+			account, err := qtx.GetAccount(ctx, repository.ToPgUUID(m.accountID))
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					zap.L().Warn("aml movement on unknown account", zap.String("event_id", evt.ID.String()), zap.String("account_id", m.accountID.String()))
+					continue
+				}
+				return fmt.Errorf("get account: %w", err)
+			}
+			m.userID = repository.FromPgUUID(account.UserID)
+			var counter *string
+			if m.counterCurrency != "" {
+				counter = &m.counterCurrency
+			}
+			rows, err := qtx.InsertAMLObservation(ctx, repository.InsertAMLObservationParams{
+				EventID:         repository.ToPgUUID(m.eventID),
+				EventType:       m.eventType,
+				TransactionID:   repository.ToPgUUID(m.transactionID),
+				AccountID:       repository.ToPgUUID(m.accountID),
+				UserID:          account.UserID,
+				Direction:       m.direction,
+				AmountMicros:    m.amountMicros,
+				Currency:        m.currency,
+				CounterCurrency: counter,
+				OccurredAt:      pgtype.Timestamptz{Time: m.occurredAt, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("insert aml observation: %w", err)
+			}
+			if rows == 1 {
+				recorded = append(recorded, m)
+			}
+		}
+		if len(recorded) == 0 {
+			return nil
+		}
+
+		rules, err := qtx.ListAMLRules(ctx)
+		if err != nil {
+			return fmt.Errorf("list aml rules: %w", err)
+		}
+		for _, m := range recorded {
+			for _, rule := range rules {
+				if !rule.Enabled {
+					continue
+				}
+				if err := s.evaluate(ctx, qtx, rule, m); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// evaluate runs rule over the window ending at m and raises an alert when it
+// fires, unless the rule already alerted on the account within the window.
+func (s *AMLService) evaluate(ctx context.Context, qtx *repository.Queries, rule repository.AmlRule, m amlMovement) error {
+	var (
+		hit *amlHit
+		err error
+	)
+	windowStart := m.occurredAt.Add(-time.Duration(rule.WindowSeconds) * time.Second)
+	switch rule.Code {
+	case domain.AMLRuleStructuring:
+		hit, err = evaluateStructuring(ctx, qtx, rule, m, windowStart)
+	case domain.AMLRuleRapidMovement:
+		hit, err = evaluateRapidMovement(ctx, qtx, rule, m, windowStart)
+	case domain.AMLRuleFXRoundTrip:
+		hit, err = evaluateFXRoundTrip(ctx, qtx, rule, m, windowStart)
+	}
+	if err != nil || hit == nil {
+		return err
+	}
+
+	recent, err := qtx.HasRecentAMLAlert(ctx, repository.HasRecentAMLAlertParams{
+		RuleCode:  rule.Code,
+		AccountID: repository.ToPgUUID(m.accountID),
+		WindowEnd: pgtype.Timestamptz{Time: windowStart, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("check recent aml alert: %w", err)
+	}
+	if recent {
+		return nil
+	}
+	return s.raiseAlert(ctx, qtx, rule, m, hit)
+}
+
+// evaluateStructuring fires when the account has made min_count movements in
+// the same direction and currency, each within ratio_bps below amount_micros.
+func evaluateStructuring(ctx context.Context, qtx *repository.Queries, rule repository.AmlRule, m amlMovement, windowStart time.Time) (*amlHit, error) {
+	floor := rule.AmountMicros - rule.AmountMicros/10_000*int64(rule.RatioBps)
+	if m.amountMicros < floor || m.amountMicros >= rule.AmountMicros {
+		return nil, nil
+	}
+	band, err := qtx.CountAMLObservationsInBand(ctx, repository.CountAMLObservationsInBandParams{
+		AccountID:       repository.ToPgUUID(m.accountID),
+		Direction:       m.direction,
+		Currency:        m.currency,
+		WindowStart:     pgtype.Timestamptz{Time: windowStart, Valid: true},
+		WindowEnd:       pgtype.Timestamptz{Time: m.occurredAt, Valid: true},
+		MinAmountMicros: floor,
+		MaxAmountMicros: rule.AmountMicros,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("count aml observations: %w", err)
+	}
+	if band.Movements < int64(rule.MinCount) {
+		return nil, nil
+	}
+	return &amlHit{windowStart: windowStart, details: map[string]any{
+		"direction":        m.direction,
+		"currency":         m.currency,
+		"movements":        band.Movements,
+		"total_micros":     band.TotalMicros,
+		"threshold_micros": rule.AmountMicros,
+	}}, nil
+}
+
+// evaluateRapidMovement fires on a debit when the account received at least
+// amount_micros in the window and has sent out at least ratio_bps of it.
+func evaluateRapidMovement(ctx context.Context, qtx *repository.Queries, rule repository.AmlRule, m amlMovement, windowStart time.Time) (*amlHit, error) {
+	if m.direction != amlDirectionOut {
+		return nil, nil
+	}
+	flows, err := qtx.SumAMLAccountFlows(ctx, repository.SumAMLAccountFlowsParams{
+		AccountID:   repository.ToPgUUID(m.accountID),
+		WindowStart: pgtype.Timestamptz{Time: windowStart, Valid: true},
+		WindowEnd:   pgtype.Timestamptz{Time: m.occurredAt, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sum aml account flows: %w", err)
+	}
+	if flows.InMicros < rule.AmountMicros || flows.OutMicros < flows.InMicros/10_000*int64(rule.RatioBps) {
+		return nil, nil
+	}
+	return &amlHit{windowStart: windowStart, details: map[string]any{
+		"currency":   m.currency,
+		"in_micros":  flows.InMicros,
+		"out_micros": flows.OutMicros,
+	}}, nil
+}
+
+// evaluateFXRoundTrip fires on an exchange when the user exchanged the
+// target currency back into the source currency earlier in the window, both
+// legs being at least amount_micros.
+func evaluateFXRoundTrip(ctx context.Context, qtx *repository.Queries, rule repository.AmlRule, m amlMovement, windowStart time.Time) (*amlHit, error) {
+	if m.eventType != outbox.EventExchangeCompleted || m.direction != amlDirectionOut || m.amountMicros < rule.AmountMicros {
+		return nil, nil
+	}
+	counter := m.counterCurrency
+	reverse, err := qtx.FindAMLReverseExchange(ctx, repository.FindAMLReverseExchangeParams{
+		UserID:          repository.ToPgUUID(m.userID),
+		Currency:        counter,
+		CounterCurrency: &m.currency,
+		MinAmountMicros: rule.AmountMicros,
+		WindowStart:     pgtype.Timestamptz{Time: windowStart, Valid: true},
+		WindowEnd:       pgtype.Timestamptz{Time: m.occurredAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find aml reverse exchange: %w", err)
+	}
+	return &amlHit{windowStart: windowStart, details: map[string]any{
+		"first_transaction_id":  repository.FromPgUUID(reverse.TransactionID),
+		"first_amount_micros":   reverse.AmountMicros,
+		"first_currency":        counter,
+		"second_transaction_id": m.transactionID,
+		"second_amount_micros":  m.amountMicros,
+		"second_currency":       m.currency,
+	}}, nil
+}
+
+// raiseAlert records hit as an alert on the user's open case, opening one
+// when the user has none.
+func (s *AMLService) raiseAlert(ctx context.Context, qtx *repository.Queries, rule repository.AmlRule, m amlMovement, hit *amlHit) error {
+	caseRow, err := qtx.CreateAMLCase(ctx, repository.CreateAMLCaseParams{
+		ID:     repository.ToPgUUID(uuid.New()),
+		UserID: repository.ToPgUUID(m.userID),
+	})
+	opened := err == nil
+	if errors.Is(err, pgx.ErrNoRows) {
+		caseRow, err = qtx.GetActiveAMLCaseForUser(ctx, repository.ToPgUUID(m.userID))
+	}
+	if err != nil {
+		return fmt.Errorf("get aml case: %w", err)
+	}
+	caseID := repository.FromPgUUID(caseRow.ID)
+
+	hit.details["transaction_id"] = m.transactionID
+	details, err := json.Marshal(hit.details)
+	if err != nil {
+		return fmt.Errorf("encode aml alert details: %w", err)
+	}
+	alert, err := qtx.CreateAMLAlert(ctx, repository.CreateAMLAlertParams{
+		ID:          repository.ToPgUUID(uuid.New()),
+		CaseID:      caseRow.ID,
+		RuleCode:    rule.Code,
+		AccountID:   repository.ToPgUUID(m.accountID),
+		UserID:      repository.ToPgUUID(m.userID),
+		EventID:     repository.ToPgUUID(m.eventID),
+		Severity:    rule.Severity,
+		Details:     details,
+		WindowStart: pgtype.Timestamptz{Time: hit.windowStart, Valid: true},
+		WindowEnd:   pgtype.Timestamptz{Time: m.occurredAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("create aml alert: %w", err)
+	}
+	alertID := repository.FromPgUUID(alert.ID)
+
+	payload := map[string]any{
+		"alert_id":       alertID,
+		"case_id":        caseID,
+		"rule_code":      rule.Code,
+		"severity":       rule.Severity,
+		"user_id":        m.userID,
+		"account_id":     m.accountID,
+		"transaction_id": m.transactionID,
+	}
+	metadata, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if opened {
+		if err := s.audit.Write(ctx, qtx, "aml_case", caseID, nil, "opened", "", domain.AMLCaseOpen, metadata); err != nil {
+			return err
+		}
+	}
+	if err := s.audit.Write(ctx, qtx, "aml_case", caseID, nil, "alert_raised", "", rule.Code, metadata); err != nil {
+		return err
+	}
+	if err := writeOutboxEvent(ctx, qtx, outbox.AggregateUser, m.userID, outbox.EventAMLAlertRaised, payload); err != nil {
+		return err
+	}
+
+	zap.L().Warn("aml alert raised",
+		zap.String("rule", rule.Code),
+		zap.String("alert_id", alertID.String()),
+		zap.String("case_id", caseID.String()),
+		zap.String("account_id", m.accountID.String()),
+	)
+	observability.IncrementAMLAlert(rule.Code, rule.Severity)
+	return nil
+}
+
+// ListRules returns every monitoring rule.
+func (s *AMLService) ListRules(ctx context.Context) ([]AMLRule, error) {
+	rows, err := s.store.Queries().ListAMLRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list aml rules: %w", err)
+	}
+	rules := make([]AMLRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, amlRuleFromRow(row))
+	}
+	return rules, nil
+}
+
+// UpdateRule replaces the parameters of the rule code. Changes apply to
+// movements evaluated from then on; earlier alerts are kept.
+func (s *AMLService) UpdateRule(ctx context.Context, code string, update AMLRuleUpdate, actorID uuid.UUID) (*AMLRule, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	update.Severity = strings.ToUpper(strings.TrimSpace(update.Severity))
+	switch code {
+	case domain.AMLRuleStructuring, domain.AMLRuleRapidMovement, domain.AMLRuleFXRoundTrip:
+	default:
+		return nil, ErrAMLRuleNotFound
+	}
+	if err := update.validate(code); err != nil {
+		return nil, err
+	}
+
+	var rule AMLRule
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.UpdateAMLRule(ctx, repository.UpdateAMLRuleParams{
+			Code:          code,
+			Enabled:       update.Enabled,
+			WindowSeconds: update.WindowSeconds,
+			AmountMicros:  update.AmountMicros,
+			MinCount:      update.MinCount,
+			RatioBps:      update.RatioBps,
+			Severity:      update.Severity,
+			UpdatedBy:     repository.ToPgUUID(actorID),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAMLRuleNotFound
+			}
+			return fmt.Errorf("update aml rule: %w", err)
+		}
+		rule = amlRuleFromRow(row)
+		metadata, err := json.Marshal(rule)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "aml_rule", uuid.Nil, &actorID, "rule_updated", "", code, metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (u AMLRuleUpdate) validate(code string) error {
+	switch {
+	case u.WindowSeconds <= 0 || time.Duration(u.WindowSeconds)*time.Second > amlMaxWindow:
+		return fmt.Errorf("%w: window_seconds must be between 1 and %d", ErrInvalidAMLRule, int(amlMaxWindow.Seconds()))
+	case u.AmountMicros <= 0:
+		return fmt.Errorf("%w: amount_micros must be positive", ErrInvalidAMLRule)
+	case u.MinCount <= 0:
+		return fmt.Errorf("%w: min_count must be positive", ErrInvalidAMLRule)
+	case u.RatioBps < 0 || u.RatioBps > 10_000:
+		return fmt.Errorf("%w: ratio_bps must be between 0 and 10000", ErrInvalidAMLRule)
+	case code != domain.AMLRuleFXRoundTrip && u.RatioBps == 0:
+		return fmt.Errorf("%w: ratio_bps is required for %s", ErrInvalidAMLRule, code)
+	}
+	switch u.Severity {
+	case domain.AMLSeverityLow, domain.AMLSeverityMedium, domain.AMLSeverityHigh:
+	default:
+		return fmt.Errorf("%w: severity must be LOW, MEDIUM or HIGH", ErrInvalidAMLRule)
+	}
+	return nil
+}
+
+// ListAlerts returns alerts matching filter, newest first, and how many
+// match in total.
+func (s *AMLService) ListAlerts(ctx context.Context, filter AMLAlertFilter, limit, offset int32) ([]AMLAlert, int64, error) {
+	limit, offset = clampAMLPage(limit, offset)
+	var ruleCode *string
+	if code := strings.ToUpper(strings.TrimSpace(filter.RuleCode)); code != "" {
+		ruleCode = &code
+	}
+	var caseID, userID pgtype.UUID
+	if filter.CaseID != nil {
+		caseID = repository.ToPgUUID(*filter.CaseID)
+	}
+	if filter.UserID != nil {
+		userID = repository.ToPgUUID(*filter.UserID)
+	}
+
+	queries := s.store.Queries()
+	rows, err := queries.ListAMLAlerts(ctx, repository.ListAMLAlertsParams{
+		RuleCode:    ruleCode,
+		CaseID:      caseID,
+		UserID:      userID,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("list aml alerts: %w", err)
+	}
+	total, err := queries.CountAMLAlerts(ctx, repository.CountAMLAlertsParams{
+		RuleCode: ruleCode,
+		CaseID:   caseID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("count aml alerts: %w", err)
+	}
+	alerts := make([]AMLAlert, 0, len(rows))
+	for _, row := range rows {
+		alerts = append(alerts, amlAlertFromRow(row))
+	}
+	return alerts, total, nil
+}
+
+// ListCases returns cases in status, or all cases when status is empty,
+// newest first, and how many match in total.
+func (s *AMLService) ListCases(ctx context.Context, status string, limit, offset int32) ([]AMLCase, int64, error) {
+	limit, offset = clampAMLPage(limit, offset)
+	var statusFilter *string
+	if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+		statusFilter = &status
+	}
+
+	queries := s.store.Queries()
+	rows, err := queries.ListAMLCases(ctx, repository.ListAMLCasesParams{
+		Status:      statusFilter,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("list aml cases: %w", err)
+	}
+	total, err := queries.CountAMLCases(ctx, statusFilter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count aml cases: %w", err)
+	}
+	cases := make([]AMLCase, 0, len(rows))
+	for _, row := range rows {
+		cases = append(cases, amlCaseFromRow(row))
+	}
+	return cases, total, nil
+}
+
+// GetCase returns a case with all of its alerts.
+func (s *AMLService) GetCase(ctx context.Context, caseID uuid.UUID) (*AMLCase, error) {
+	row, err := s.store.Queries().GetAMLCase(ctx, repository.ToPgUUID(caseID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAMLCaseNotFound
+		}
+		return nil, fmt.Errorf("get aml case: %w", err)
+	}
+	out := amlCaseFromRow(row)
+	alerts, _, err := s.ListAlerts(ctx, AMLAlertFilter{CaseID: &caseID}, 500, 0)
+	if err != nil {
+		return nil, err
+	}
+	out.Alerts = alerts
+	return &out, nil
+}
+
+// UpdateCase moves a case to update.Status and records who did it. Closing
+// requires a resolution and a note.
+func (s *AMLService) UpdateCase(ctx context.Context, caseID uuid.UUID, update AMLCaseUpdate, actorID uuid.UUID) (*AMLCase, error) {
+	update.Status = strings.ToUpper(strings.TrimSpace(update.Status))
+	update.Resolution = strings.ToUpper(strings.TrimSpace(update.Resolution))
+	update.Note = strings.TrimSpace(update.Note)
+	if update.Status == domain.AMLCaseClosed {
+		if update.Resolution != domain.AMLResolutionNoAction && update.Resolution != domain.AMLResolutionReported {
+			return nil, fmt.Errorf("%w: resolution must be NO_ACTION or REPORTED", ErrInvalidAMLCaseUpdate)
+		}
+		if update.Note == "" {
+			return nil, fmt.Errorf("%w: note is required to close a case", ErrInvalidAMLCaseUpdate)
+		}
+	} else if update.Resolution != "" {
+		return nil, fmt.Errorf("%w: resolution is only set when closing", ErrInvalidAMLCaseUpdate)
+	}
+
+	var out AMLCase
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		current, err := qtx.GetAMLCaseForUpdate(ctx, repository.ToPgUUID(caseID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAMLCaseNotFound
+			}
+			return fmt.Errorf("lock aml case: %w", err)
+		}
+		if !slices.Contains(amlCaseTransitions[current.Status], update.Status) {
+			return fmt.Errorf("%w: cannot move from %s to %s", ErrInvalidAMLCaseUpdate, current.Status, update.Status)
+		}
+
+		assignedTo := current.AssignedTo
+		if update.AssignedTo != nil {
+			assignedTo = repository.ToPgUUID(*update.AssignedTo)
+		}
+		note := current.Note
+		if update.Note != "" {
+			note = &update.Note
+		}
+		var resolution *string
+		if update.Resolution != "" {
+			resolution = &update.Resolution
+		}
+		row, err := qtx.UpdateAMLCase(ctx, repository.UpdateAMLCaseParams{
+			ID:         current.ID,
+			Status:     update.Status,
+			AssignedTo: assignedTo,
+			Resolution: resolution,
+			Note:       note,
+		})
+		if err != nil {
+			return fmt.Errorf("update aml case: %w", err)
+		}
+		out = amlCaseFromRow(row)
+
+		action := "status_changed"
+		if current.Status == update.Status {
+			action = "updated"
+		}
+		payload := map[string]any{
+			"case_id":     caseID,
+			"user_id":     out.UserID,
+			"status":      out.Status,
+			"prev_status": current.Status,
+			"assigned_to": out.AssignedTo,
+			"resolution":  out.Resolution,
+		}
+		metadata, err := json.Marshal(map[string]any{"assigned_to": out.AssignedTo, "resolution": out.Resolution, "note": update.Note})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		if err := s.audit.Write(ctx, qtx, "aml_case", caseID, &actorID, action, current.Status, update.Status, metadata); err != nil {
+			return err
+		}
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateUser, out.UserID, outbox.EventAMLCaseUpdated, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// amlMovementsFromEvent returns the account legs of a completed transaction
+// event, or nil for any other event type.
+func amlMovementsFromEvent(evt outbox.Event) ([]amlMovement, error) {
+	switch evt.Type {
+	case outbox.EventDepositCompleted, outbox.EventTransferCompleted, outbox.EventExchangeCompleted, outbox.EventPayoutCompleted:
+	default:
+		return nil, nil
+	}
+	var payload struct {
+		TransactionID      uuid.UUID `json:"transaction_id"`
+		AccountID          uuid.UUID `json:"account_id"`
+		FromAccountID      uuid.UUID `json:"from_account_id"`
+		ToAccountID        uuid.UUID `json:"to_account_id"`
+		AmountMicros       int64     `json:"amount_micros"`
+		Currency           string    `json:"currency"`
+		TargetAmountMicros int64     `json:"target_amount_micros"`
+		TargetCurrency     string    `json:"target_currency"`
+	}
+	if err := json.Unmarshal(evt.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode %s payload: %w", evt.Type, err)
+	}
+	if payload.TransactionID == uuid.Nil || payload.AmountMicros <= 0 {
+		return nil, fmt.Errorf("%s payload is missing transaction_id or amount_micros", evt.Type)
+	}
+
+	base := amlMovement{
+		eventID:       evt.ID,
+		eventType:     evt.Type,
+		transactionID: payload.TransactionID,
+		amountMicros:  payload.AmountMicros,
+		currency:      payload.Currency,
+		occurredAt:    evt.CreatedAt.UTC(),
+	}
+	leg := func(accountID uuid.UUID, direction string) amlMovement {
+		m := base
+		m.accountID = accountID
+		m.direction = direction
+		return m
+	}
+	switch evt.Type {
+	case outbox.EventDepositCompleted:
+		return []amlMovement{leg(payload.AccountID, amlDirectionIn)}, nil
+	case outbox.EventPayoutCompleted:
+		return []amlMovement{leg(payload.AccountID, amlDirectionOut)}, nil
+	case outbox.EventTransferCompleted:
+		return []amlMovement{leg(payload.FromAccountID, amlDirectionOut), leg(payload.ToAccountID, amlDirectionIn)}, nil
+	default:
+		out := leg(payload.FromAccountID, amlDirectionOut)
+		out.counterCurrency = payload.TargetCurrency
+		in := leg(payload.ToAccountID, amlDirectionIn)
+		in.amountMicros = payload.TargetAmountMicros
+		in.currency = payload.TargetCurrency
+		in.counterCurrency = payload.Currency
+		return []amlMovement{out, in}, nil
+	}
+}
+
+func clampAMLPage(limit, offset int32) (int32, int32) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func amlRuleFromRow(row repository.AmlRule) AMLRule {
+	return AMLRule{
+		Code:          row.Code,
+		Description:   row.Description,
+		Enabled:       row.Enabled,
+		WindowSeconds: row.WindowSeconds,
+		AmountMicros:  row.AmountMicros,
+		MinCount:      row.MinCount,
+		RatioBps:      row.RatioBps,
+		Severity:      row.Severity,
+		UpdatedBy:     optionalUUID(row.UpdatedBy),
+		UpdatedAt:     row.UpdatedAt.Time,
+	}
+}
+
+func amlAlertFromRow(row repository.AmlAlert) AMLAlert {
+	return AMLAlert{
+		ID:          repository.FromPgUUID(row.ID),
+		CaseID:      repository.FromPgUUID(row.CaseID),
+		RuleCode:    row.RuleCode,
+		AccountID:   repository.FromPgUUID(row.AccountID),
+		UserID:      repository.FromPgUUID(row.UserID),
+		EventID:     repository.FromPgUUID(row.EventID),
+		Severity:    row.Severity,
+		Details:     json.RawMessage(row.Details),
+		WindowStart: row.WindowStart.Time,
+		WindowEnd:   row.WindowEnd.Time,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
+
+func amlCaseFromRow(row repository.AmlCase) AMLCase {
+	out := AMLCase{
+		ID:         repository.FromPgUUID(row.ID),
+		UserID:     repository.FromPgUUID(row.UserID),
+		Status:     row.Status,
+		AssignedTo: optionalUUID(row.AssignedTo),
+		Resolution: derefString(row.Resolution),
+		Note:       derefString(row.Note),
+		CreatedAt:  row.CreatedAt.Time,
+		UpdatedAt:  row.UpdatedAt.Time,
+	}
+	if row.ClosedAt.Valid {
+		closedAt := row.ClosedAt.Time
+		out.ClosedAt = &closedAt
+	}
+	return out
+}
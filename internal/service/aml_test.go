@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMLValidation(t *testing.T) {
+	svc := NewAMLService(panicStore{})
+	ctx := context.Background()
+	valid := AMLRuleUpdate{Enabled: true, WindowSeconds: 3600, AmountMicros: 1_000_000, MinCount: 2, RatioBps: 500, Severity: "high"}
+
+	_, err := svc.UpdateRule(ctx, "VELOCITY", valid, uuid.New())
+	require.ErrorIs(t, err, ErrAMLRuleNotFound)
+	tooLong := valid
+	tooLong.WindowSeconds = 31 * 24 * 3600
+	_, err = svc.UpdateRule(ctx, domain.AMLRuleStructuring, tooLong, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidAMLRule)
+	noBand := valid
+	noBand.RatioBps = 0
+	_, err = svc.UpdateRule(ctx, domain.AMLRuleStructuring, noBand, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidAMLRule)
+
+	_, err = svc.UpdateCase(ctx, uuid.New(), AMLCaseUpdate{Status: domain.AMLCaseClosed, Note: "nothing found"}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidAMLCaseUpdate)
+	_, err = svc.UpdateCase(ctx, uuid.New(), AMLCaseUpdate{Status: domain.AMLCaseClosed, Resolution: domain.AMLResolutionNoAction}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidAMLCaseUpdate)
+	_, err = svc.UpdateCase(ctx, uuid.New(), AMLCaseUpdate{Status: domain.AMLCaseInvestigating, Resolution: domain.AMLResolutionReported}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidAMLCaseUpdate)
+
+	require.NoError(t, svc.Publish(ctx, outbox.Event{ID: uuid.New(), Type: outbox.EventPayoutRequested, Payload: json.RawMessage(`{}`)}))
+}
+
+func TestAMLMonitoring(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	svc := NewAMLService(repository.NewStore(db))
+	ctx := context.Background()
+
+	newAccount := func(user *models.User, currency string) uuid.UUID {
+		account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: currency}
+		require.NoError(t, repoSvc.CreateAccount(ctx, account))
+		return account.ID
+	}
+	analyst := &models.User{ID: uuid.New(), Username: "analyst", Email: "analyst@example.com", Role: "admin"}
+	smurf := &models.User{ID: uuid.New(), Username: "smurf", Email: "smurf@example.com", Role: "user"}
+	mule := &models.User{ID: uuid.New(), Username: "mule", Email: "mule@example.com", Role: "user"}
+	trader := &models.User{ID: uuid.New(), Username: "trader", Email: "trader@example.com", Role: "user"}
+	for _, u := range []*models.User{analyst, smurf, mule, trader} {
+		require.NoError(t, repoSvc.CreateUser(ctx, u))
+	}
+	smurfUSD := newAccount(smurf, "USD")
+	muleUSD := newAccount(mule, "USD")
+	traderUSD := newAccount(trader, "USD")
+	traderEUR := newAccount(trader, "EUR")
+
+	start := time.Now().UTC().Add(-time.Hour)
+	publish := func(eventType string, at time.Duration, payload map[string]any) outbox.Event {
+		payload["transaction_id"] = uuid.New()
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		evt := outbox.Event{ID: uuid.New(), Type: eventType, Payload: body, CreatedAt: start.Add(at)}
+		require.NoError(t, svc.Publish(ctx, evt))
+		return evt
+	}
+	deposit := func(account uuid.UUID, amount int64, at time.Duration) outbox.Event {
+		return publish(outbox.EventDepositCompleted, at, map[string]any{"account_id": account, "amount_micros": amount, "currency": "USD"})
+	}
+	alertsFor := func(rule string, user uuid.UUID) []AMLAlert {
+		alerts, _, err := svc.ListAlerts(ctx, AMLAlertFilter{RuleCode: rule, UserID: &user}, 50, 0)
+		require.NoError(t, err)
+		return alerts
+	}
+
+	// Three deposits just under 10,000 fire structuring once; the fourth
+	// falls in the same window and is not alerted again.
+	deposit(smurfUSD, 9_500_000_000, 0)
+	deposit(smurfUSD, 9_800_000_000, time.Minute)
+	require.Empty(t, alertsFor(domain.AMLRuleStructuring, smurf.ID))
+	third := deposit(smurfUSD, 9_900_000_000, 2*time.Minute)
+	deposit(smurfUSD, 9_700_000_000, 3*time.Minute)
+	structuring := alertsFor(domain.AMLRuleStructuring, smurf.ID)
+	require.Len(t, structuring, 1)
+	require.Equal(t, third.ID, structuring[0].EventID)
+	require.Equal(t, domain.AMLSeverityMedium, structuring[0].Severity)
+
+	// A redelivered event is not recorded or evaluated twice.
+	require.NoError(t, svc.Publish(ctx, third))
+	require.Len(t, alertsFor(domain.AMLRuleStructuring, smurf.ID), 1)
+
+	// Funds passed straight through an account.
+	deposit(muleUSD, 6_000_000_000, 0)
+	publish(outbox.EventPayoutCompleted, 10*time.Minute, map[string]any{"account_id": muleUSD, "amount_micros": 3_000_000_000, "currency": "USD"})
+	require.Empty(t, alertsFor(domain.AMLRuleRapidMovement, mule.ID))
+	publish(outbox.EventTransferCompleted, 20*time.Minute, map[string]any{"from_account_id": muleUSD, "to_account_id": smurfUSD, "amount_micros": 2_500_000_000, "currency": "USD"})
+	require.Len(t, alertsFor(domain.AMLRuleRapidMovement, mule.ID), 1)
+
+	// USD to EUR and back again.
+	exchange := func(from, to uuid.UUID, amount int64, currency, target string, at time.Duration) {
+		publish(outbox.EventExchangeCompleted, at, map[string]any{
+			"from_account_id": from, "to_account_id": to,
+			"amount_micros": amount, "currency": currency,
+			"target_amount_micros": amount * 9 / 10, "target_currency": target,
+		})
+	}
+	exchange(traderUSD, traderEUR, 2_000_000_000, "USD", "EUR", 0)
+	require.Empty(t, alertsFor(domain.AMLRuleFXRoundTrip, trader.ID))
+	exchange(traderEUR, traderUSD, 1_800_000_000, "EUR", "USD", 30*time.Minute)
+	roundTrips := alertsFor(domain.AMLRuleFXRoundTrip, trader.ID)
+	require.Len(t, roundTrips, 1)
+	require.Equal(t, traderEUR, roundTrips[0].AccountID)
+
+	// Alerts on one user share their open case, which moves through the
+	// workflow and, once closed, is final.
+	cases, total, err := svc.ListCases(ctx, domain.AMLCaseOpen, 50, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Len(t, cases, 3)
+
+	caseID := structuring[0].CaseID
+	_, err = svc.UpdateCase(ctx, caseID, AMLCaseUpdate{Status: domain.AMLCaseEscalated}, analyst.ID)
+	require.ErrorIs(t, err, ErrInvalidAMLCaseUpdate)
+	investigating, err := svc.UpdateCase(ctx, caseID, AMLCaseUpdate{Status: domain.AMLCaseInvestigating, AssignedTo: &analyst.ID}, analyst.ID)
+	require.NoError(t, err)
+	require.Equal(t, analyst.ID, *investigating.AssignedTo)
+	closed, err := svc.UpdateCase(ctx, caseID, AMLCaseUpdate{Status: domain.AMLCaseClosed, Resolution: domain.AMLResolutionReported, Note: "SAR filed"}, analyst.ID)
+	require.NoError(t, err)
+	require.NotNil(t, closed.ClosedAt)
+	require.Equal(t, analyst.ID, *closed.AssignedTo)
+	_, err = svc.UpdateCase(ctx, caseID, AMLCaseUpdate{Status: domain.AMLCaseInvestigating}, analyst.ID)
+	require.ErrorIs(t, err, ErrInvalidAMLCaseUpdate)
+
+	detail, err := svc.GetCase(ctx, caseID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AMLResolutionReported, detail.Resolution)
+	require.Len(t, detail.Alerts, 1)
+
+	// A new alert after closing opens a fresh case.
+	deposit(smurfUSD, 9_100_000_000, 2*24*time.Hour)
+	deposit(smurfUSD, 9_200_000_000, 2*24*time.Hour+time.Minute)
+	deposit(smurfUSD, 9_300_000_000, 2*24*time.Hour+2*time.Minute)
+	structuring = alertsFor(domain.AMLRuleStructuring, smurf.ID)
+	require.Len(t, structuring, 2)
+	require.NotEqual(t, caseID, structuring[0].CaseID)
+
+	_, err = svc.GetCase(ctx, uuid.New())
+	require.ErrorIs(t, err, ErrAMLCaseNotFound)
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefix starts every key so leaked keys are easy to spot in logs
+	// and by secret scanners.
+	apiKeyPrefix = "pmk_"
+	// apiKeyDisplayLength is how much of a key is kept as its prefix.
+	apiKeyDisplayLength    = len(apiKeyPrefix) + 8
+	apiKeyMaxNameLength    = 100
+	apiKeyLastUsedInterval = time.Minute
+
+	defaultAPIKeyRotationGrace = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidAPIKey indicates a missing name, a scope that is not one of
+	// the owner's permissions, or an expiry in the past.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrAPIKeyNotFound indicates a key that does not exist, belongs to
+	// another user, or has been revoked or has expired.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// APIKeySpec describes a key to issue. Scopes are permission names. A nil
+// ExpiresAt never expires.
+type APIKeySpec struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// IssuedAPIKey is a new key together with its secret, which is not stored.
+type IssuedAPIKey struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// WithAPIKeyRotationGrace sets how long a rotated key keeps working so its
+// clients can switch to the new one. Zero ends it at once.
+func (s *AuthService) WithAPIKeyRotationGrace(grace time.Duration) *AuthService {
+	s.apiKeyGrace = grace
+	return s
+}
+
+// CreateAPIKey issues a key that acts as userID, limited to spec.Scopes,
+// each of which the user's role must grant.
+func (s *AuthService) CreateAPIKey(ctx context.Context, userID uuid.UUID, spec APIKeySpec) (*IssuedAPIKey, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if spec.Name == "" || len(spec.Name) > apiKeyMaxNameLength {
+		return nil, fmt.Errorf("%w: name is required and at most %d characters", ErrInvalidAPIKey, apiKeyMaxNameLength)
+	}
+	scopes, err := normalizeScopes(spec.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if spec.ExpiresAt != nil && !spec.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKey)
+	}
+
+	var issued *IssuedAPIKey
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		user, err := qtx.GetUser(ctx, repository.ToPgUUID(userID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+		granted, err := qtx.ListRolePermissions(ctx, user.Role)
+		if err != nil {
+			return fmt.Errorf("failed to list role permissions: %w", err)
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				return fmt.Errorf("%w: the %s role does not grant %q", ErrInvalidAPIKey, user.Role, scope)
+			}
+		}
+		issued, err = s.issueAPIKey(ctx, qtx, userID, spec.Name, scopes, spec.ExpiresAt, nil)
+		if err != nil {
+			return err
+		}
+		return s.auditAPIKey(ctx, qtx, issued.ID, userID, "api_key_created", &issued.APIKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// RotateAPIKey replaces a key with a new one carrying the same name, scopes
+// and lifetime. The old key keeps working for the rotation grace period.
+func (s *AuthService) RotateAPIKey(ctx context.Context, userID, keyID uuid.UUID) (*IssuedAPIKey, error) {
+	now := s.now()
+	var issued *IssuedAPIKey
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		old, err := qtx.GetActiveAPIKeyForUpdate(ctx, repository.GetActiveAPIKeyForUpdateParams{
+			ID:     repository.ToPgUUID(keyID),
+			UserID: repository.ToPgUUID(userID),
+			Now:    pgtype.Timestamptz{Time: now, Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAPIKeyNotFound
+			}
+			return fmt.Errorf("failed to fetch api key: %w", err)
+		}
+		var expiresAt *time.Time
+		if old.ExpiresAt.Valid {
+			next := now.Add(old.ExpiresAt.Time.Sub(old.CreatedAt.Time))
+			expiresAt = &next
+		}
+		issued, err = s.issueAPIKey(ctx, qtx, userID, old.Name, old.Scopes, expiresAt, &keyID)
+		if err != nil {
+			return err
+		}
+		if err := qtx.ExpireAPIKey(ctx, repository.ExpireAPIKeyParams{
+			ID:        old.ID,
+			ExpiresAt: pgtype.Timestamptz{Time: now.Add(s.apiKeyGrace), Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to expire rotated api key: %w", err)
+		}
+		return s.auditAPIKey(ctx, qtx, issued.ID, userID, "api_key_rotated", &issued.APIKey)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// ListAPIKeys returns the user's keys that still work, newest first.
+func (s *AuthService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := s.store.Queries().ListActiveAPIKeys(ctx, repository.ListActiveAPIKeysParams{
+		UserID: repository.ToPgUUID(userID),
+		Now:    pgtype.Timestamptz{Time: s.now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	keys := make([]models.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, toAPIKeyModel(row))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops one of the user's keys working at once.
+func (s *AuthService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	return s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		rows, err := qtx.RevokeAPIKey(ctx, repository.RevokeAPIKeyParams{
+			ID:     repository.ToPgUUID(keyID),
+			UserID: repository.ToPgUUID(userID),
+			Now:    pgtype.Timestamptz{Time: s.now(), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to revoke api key: %w", err)
+		}
+		if rows == 0 {
+			return ErrAPIKeyNotFound
+		}
+		return s.auditAPIKey(ctx, qtx, keyID, userID, "api_key_revoked", nil)
+	})
+}
+
+// AuthenticateAPIKey resolves a key presented by a client to its owner.
+// The key may use the permissions its owner's current role grants that are
+// also among its scopes. An unknown, revoked or expired key returns nil and
+// no error.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (*models.Principal, error) {
+	key = strings.TrimSpace(key)
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil
+	}
+	row, err := s.store.Queries().GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch api key: %w", err)
+	}
+	now := s.now()
+	if row.RevokedAt.Valid || (row.ExpiresAt.Valid && !row.ExpiresAt.Time.After(now)) {
+		return nil, nil
+	}
+
+	if !row.LastUsedAt.Valid || row.LastUsedAt.Time.Before(now.Add(-apiKeyLastUsedInterval)) {
+		if err := s.store.Queries().TouchAPIKey(ctx, repository.TouchAPIKeyParams{
+			ID:          row.ID,
+			Now:         pgtype.Timestamptz{Time: now, Valid: true},
+			StaleBefore: pgtype.Timestamptz{Time: now.Add(-apiKeyLastUsedInterval), Valid: true},
+		}); err != nil {
+			zap.L().Warn("failed to record api key use", zap.String("api_key_id", repository.FromPgUUID(row.ID).String()), zap.Error(err))
+		}
+	}
+	permissions := make([]string, 0, len(row.Scopes))
+	for _, scope := range row.Scopes {
+		if slices.Contains(row.RolePermissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+	return &models.Principal{
+		UserID:      repository.FromPgUUID(row.UserID),
+		Role:        row.Role,
+		Permissions: permissions,
+		APIKey: &models.APIKey{
+			ID:          repository.FromPgUUID(row.ID),
+			UserID:      repository.FromPgUUID(row.UserID),
+			Name:        row.Name,
+			Prefix:      row.Prefix,
+			Scopes:      row.Scopes,
+			CreatedAt:   row.CreatedAt.Time,
+			ExpiresAt:   optionalTime(row.ExpiresAt),
+			LastUsedAt:  optionalTime(row.LastUsedAt),
+			RotatedFrom: optionalUUID(row.RotatedFrom),
+		},
+	}, nil
+}
+
+func (s *AuthService) issueAPIKey(ctx context.Context, qtx *repository.Queries, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time, rotatedFrom *uuid.UUID) (*IssuedAPIKey, error) {
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate api key: %w", err)
+	}
+	key := apiKeyPrefix + secret
+	issued := &IssuedAPIKey{
+		APIKey: models.APIKey{
+			ID:          uuid.New(),
+			UserID:      userID,
+			Name:        name,
+			Prefix:      key[:apiKeyDisplayLength],
+			Scopes:      scopes,
+			CreatedAt:   s.now(),
+			ExpiresAt:   expiresAt,
+			RotatedFrom: rotatedFrom,
+		},
+		Key: key,
+	}
+	rotated := pgtype.UUID{}
+	if rotatedFrom != nil {
+		rotated = repository.ToPgUUID(*rotatedFrom)
+	}
+	if err := qtx.CreateAPIKey(ctx, repository.CreateAPIKeyParams{
+		ID:          repository.ToPgUUID(issued.ID),
+		UserID:      repository.ToPgUUID(userID),
+		Name:        name,
+		Prefix:      issued.Prefix,
+		KeyHash:     hashToken(key),
+		Scopes:      scopes,
+		CreatedAt:   pgtype.Timestamptz{Time: issued.CreatedAt, Valid: true},
+		ExpiresAt:   timestampParam(expiresAt),
+		RotatedFrom: rotated,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	return issued, nil
+}
+
+func (s *AuthService) auditAPIKey(ctx context.Context, qtx *repository.Queries, keyID, actorID uuid.UUID, action string, key *models.APIKey) error {
+	var metadata []byte
+	if key != nil {
+		var err error
+		metadata, err = json.Marshal(map[string]any{"name": key.Name, "prefix": key.Prefix, "scopes": key.Scopes, "rotated_from": key.RotatedFrom})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+	}
+	return s.audit.Write(ctx, qtx, "api_key", keyID, &actorID, action, "", "", metadata)
+}
+
+// normalizeScopes checks scopes are permission names and returns them
+// sorted without duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool, len(scopes))
+	out := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !permission.Known(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKey, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			out = append(out, scope)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKey)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+func toAPIKeyModel(row repository.ApiKey) models.APIKey {
+	return models.APIKey{
+		ID:          repository.FromPgUUID(row.ID),
+		UserID:      repository.FromPgUUID(row.UserID),
+		Name:        row.Name,
+		Prefix:      row.Prefix,
+		Scopes:      row.Scopes,
+		CreatedAt:   row.CreatedAt.Time,
+		ExpiresAt:   optionalTime(row.ExpiresAt),
+		LastUsedAt:  optionalTime(row.LastUsedAt),
+		RotatedFrom: optionalUUID(row.RotatedFrom),
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyValidation(t *testing.T) {
+	svc := NewAuthService(panicStore{})
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+
+	_, err := svc.CreateAPIKey(ctx, uuid.New(), APIKeySpec{Name: " ", Scopes: []string{permission.AccountsRead}})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	_, err = svc.CreateAPIKey(ctx, uuid.New(), APIKeySpec{Name: "billing"})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	_, err = svc.CreateAPIKey(ctx, uuid.New(), APIKeySpec{Name: "billing", Scopes: []string{"accounts:delete"}})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+	_, err = svc.CreateAPIKey(ctx, uuid.New(), APIKeySpec{Name: "billing", Scopes: []string{permission.AccountsRead}, ExpiresAt: &past})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	principal, err := svc.AuthenticateAPIKey(ctx, "not-a-key")
+	require.NoError(t, err)
+	require.Nil(t, principal)
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	svc := NewAuthService(repository.NewStore(db)).WithAPIKeyRotationGrace(time.Hour)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, Registration{Username: "batch", Email: "batch@example.com", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	// A key cannot carry permissions its owner's role lacks.
+	_, err = svc.CreateAPIKey(ctx, user.ID, APIKeySpec{Name: "ops", Scopes: []string{permission.PayoutsApprove}})
+	require.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	expiry := now.Add(30 * 24 * time.Hour)
+	issued, err := svc.CreateAPIKey(ctx, user.ID, APIKeySpec{
+		Name:      " billing ",
+		Scopes:    []string{permission.PayoutsRead, permission.AccountsRead, permission.BeneficiariesRead, permission.AccountsRead},
+		ExpiresAt: &expiry,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "billing", issued.Name)
+	require.Equal(t, []string{permission.AccountsRead, permission.BeneficiariesRead, permission.PayoutsRead}, issued.Scopes)
+	require.True(t, strings.HasPrefix(issued.Key, issued.Prefix))
+
+	principal, err := svc.AuthenticateAPIKey(ctx, issued.Key)
+	require.NoError(t, err)
+	require.NotNil(t, principal)
+	require.Equal(t, user.ID, principal.UserID)
+	require.Equal(t, issued.ID, principal.APIKey.ID)
+	require.Equal(t, "user", principal.Role)
+	require.Equal(t, issued.Scopes, principal.Permissions)
+	principal, err = svc.AuthenticateAPIKey(ctx, issued.Key+"x")
+	require.NoError(t, err)
+	require.Nil(t, principal)
+
+	keys, err := svc.ListAPIKeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].LastUsedAt)
+
+	// Rotation keeps the lifetime and lets the old key work for the grace
+	// period only.
+	now = now.Add(24 * time.Hour)
+	rotated, err := svc.RotateAPIKey(ctx, user.ID, issued.ID)
+	require.NoError(t, err)
+	require.Equal(t, issued.ID, *rotated.RotatedFrom)
+	require.WithinDuration(t, now.Add(30*24*time.Hour), *rotated.ExpiresAt, time.Millisecond)
+	principal, err = svc.AuthenticateAPIKey(ctx, issued.Key)
+	require.NoError(t, err)
+	require.NotNil(t, principal)
+	now = now.Add(2 * time.Hour)
+	principal, err = svc.AuthenticateAPIKey(ctx, issued.Key)
+	require.NoError(t, err)
+	require.Nil(t, principal)
+	_, err = svc.RotateAPIKey(ctx, user.ID, issued.ID)
+	require.ErrorIs(t, err, ErrAPIKeyNotFound)
+
+	// A key only keeps the permissions its owner's current role grants.
+	_, err = svc.ChangeRole(ctx, user.ID, "ops", user.ID)
+	require.NoError(t, err)
+	principal, err = svc.AuthenticateAPIKey(ctx, rotated.Key)
+	require.NoError(t, err)
+	require.Equal(t, []string{permission.AccountsRead, permission.PayoutsRead}, principal.Permissions)
+
+	require.ErrorIs(t, svc.RevokeAPIKey(ctx, uuid.New(), rotated.ID), ErrAPIKeyNotFound)
+	require.NoError(t, svc.RevokeAPIKey(ctx, user.ID, rotated.ID))
+	require.ErrorIs(t, svc.RevokeAPIKey(ctx, user.ID, rotated.ID), ErrAPIKeyNotFound)
+	principal, err = svc.AuthenticateAPIKey(ctx, rotated.Key)
+	require.NoError(t, err)
+	require.Nil(t, principal)
+	keys, err = svc.ListAPIKeys(ctx, user.ID)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
@@ -9,9 +9,12 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// AuditService writes immutable audit trail entries.
+// AuditService writes immutable audit trail entries. The database chains
+// every entry to the previous one by hash; AuditService verifies and
+// anchors that chain.
 type AuditService struct {
-	store QueryStore
+	store     QueryStore
+	anchorDir string
 }
 
 func NewAuditService(store QueryStore) *AuditService {
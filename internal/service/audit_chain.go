@@ -0,0 +1,366 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// auditChainPageSize is the number of audit records read per page
+	// while walking the chain.
+	auditChainPageSize = 1000
+	// maxAuditChainBreaks caps the breaks listed in one verification;
+	// BreakCount still counts all of them.
+	maxAuditChainBreaks = 100
+	// auditAnchorSourceDB marks anchors read from the audit_anchors table.
+	auditAnchorSourceDB = "database"
+)
+
+// auditGenesisHash is the prev_hash of the first audit record.
+var auditGenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+var auditAnchorFilePattern = regexp.MustCompile(`^audit-anchor-\d{20}\.json$`)
+
+// ErrAuditChainBroken is returned when an anchor cannot be taken because
+// the records since the previous anchor do not verify.
+var ErrAuditChainBroken = errors.New("audit chain broken")
+
+// WithAnchorDir sets the directory anchors are exported to. Verification
+// also checks the chain against every anchor file found there.
+func (s *AuditService) WithAnchorDir(dir string) *AuditService {
+	s.anchorDir = dir
+	return s
+}
+
+// VerifyChain walks the whole audit chain, recomputing every record hash,
+// and checks it against the chain head and against every anchor stored in
+// the database or exported to the anchor directory.
+func (s *AuditService) VerifyChain(ctx context.Context) (*models.AuditChainVerification, error) {
+	q := s.store.Queries()
+
+	head, hasHead, err := auditChainHead(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	untilSeq := int64(math.MaxInt64)
+	if hasHead {
+		untilSeq = head.LastSeq
+	}
+	anchors, err := s.loadAnchors(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	v := newAuditChainVerifier(0, anchors)
+	if err := v.walk(ctx, q, 0, auditGenesisHash, untilSeq); err != nil {
+		return nil, err
+	}
+	switch {
+	case !hasHead && v.result.LastSeq > 0:
+		v.addBreak(models.AuditChainBreak{ChainSeq: v.result.LastSeq, Reason: domain.AuditBreakHeadMismatch, Actual: v.result.LastHash})
+	case hasHead && v.result.LastSeq != head.LastSeq:
+		v.addBreak(models.AuditChainBreak{
+			ChainSeq: head.LastSeq,
+			Reason:   domain.AuditBreakHeadMismatch,
+			Expected: strconv.FormatInt(head.LastSeq, 10),
+			Actual:   strconv.FormatInt(v.result.LastSeq, 10),
+		})
+	case hasHead && head.LastSeq > 0 && v.result.LastHash != head.LastHash:
+		v.addBreak(models.AuditChainBreak{ChainSeq: head.LastSeq, Reason: domain.AuditBreakHeadMismatch, Expected: head.LastHash, Actual: v.result.LastHash})
+	}
+	v.checkUnmatchedAnchors()
+
+	result := v.finish()
+	if !result.Valid {
+		zap.L().Error("audit chain verification failed",
+			zap.Int("breaks", result.BreakCount),
+			zap.Int64("last_seq", result.LastSeq))
+	}
+	return result, nil
+}
+
+// Anchor verifies the records appended since the latest anchor and records
+// the current chain head as a new anchor, exporting it to the anchor
+// directory when one is set. It returns nil when nothing was appended.
+func (s *AuditService) Anchor(ctx context.Context) (*models.AuditAnchor, error) {
+	q := s.store.Queries()
+
+	head, hasHead, err := auditChainHead(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if !hasHead || head.LastSeq == 0 {
+		return nil, nil
+	}
+
+	fromSeq, prevHash := int64(0), auditGenesisHash
+	latest, err := q.GetLatestAuditAnchor(ctx)
+	switch {
+	case err == nil:
+		fromSeq, prevHash = latest.ChainSeq, latest.RecordHash
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("get latest audit anchor: %w", err)
+	}
+	if head.LastSeq <= fromSeq {
+		return nil, nil
+	}
+
+	v := newAuditChainVerifier(fromSeq, nil)
+	if err := v.walk(ctx, q, fromSeq, prevHash, head.LastSeq); err != nil {
+		return nil, err
+	}
+	if v.result.LastSeq != head.LastSeq || v.result.LastHash != head.LastHash {
+		v.addBreak(models.AuditChainBreak{ChainSeq: head.LastSeq, Reason: domain.AuditBreakHeadMismatch, Expected: head.LastHash, Actual: v.result.LastHash})
+	}
+	if result := v.finish(); !result.Valid {
+		return nil, fmt.Errorf("%w: %d breaks after chain_seq %d", ErrAuditChainBroken, result.BreakCount, fromSeq)
+	}
+
+	anchor := &models.AuditAnchor{
+		ChainSeq:   head.LastSeq,
+		RecordHash: head.LastHash,
+		AnchoredAt: time.Now().UTC(),
+	}
+	var filePath *string
+	if s.anchorDir != "" {
+		path := filepath.Join(s.anchorDir, auditAnchorFileName(anchor.ChainSeq))
+		if err := writeFileAtomic(path, func(w io.Writer) error {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(anchor)
+		}); err != nil {
+			return nil, fmt.Errorf("export audit anchor: %w", err)
+		}
+		filePath = &path
+	}
+	if _, err := q.CreateAuditAnchor(ctx, repository.CreateAuditAnchorParams{
+		ChainSeq:   anchor.ChainSeq,
+		RecordHash: anchor.RecordHash,
+		FilePath:   filePath,
+	}); err != nil {
+		if isUniqueViolation(err) {
+			// Another instance anchored the same head.
+			return nil, nil
+		}
+		return nil, fmt.Errorf("create audit anchor: %w", err)
+	}
+
+	observability.IncrementAuditAnchor()
+	zap.L().Info("audit chain anchored",
+		zap.Int64("chain_seq", anchor.ChainSeq),
+		zap.String("record_hash", anchor.RecordHash))
+	return anchor, nil
+}
+
+// auditChainHead reads the chain head; ok is false before the first record.
+func auditChainHead(ctx context.Context, q *repository.Queries) (repository.GetAuditChainHeadRow, bool, error) {
+	head, err := q.GetAuditChainHead(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return head, false, nil
+		}
+		return head, false, fmt.Errorf("get audit chain head: %w", err)
+	}
+	return head, true, nil
+}
+
+type auditAnchorRef struct {
+	hash   string
+	source string
+}
+
+// loadAnchors collects anchors from the database and the anchor directory,
+// keyed by chain_seq.
+func (s *AuditService) loadAnchors(ctx context.Context, q *repository.Queries) (map[int64][]auditAnchorRef, error) {
+	anchors := make(map[int64][]auditAnchorRef)
+	rows, err := q.ListAuditAnchors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list audit anchors: %w", err)
+	}
+	for _, row := range rows {
+		anchors[row.ChainSeq] = append(anchors[row.ChainSeq], auditAnchorRef{hash: row.RecordHash, source: auditAnchorSourceDB})
+	}
+
+	if s.anchorDir == "" {
+		return anchors, nil
+	}
+	files, err := os.ReadDir(s.anchorDir)
+	if err != nil {
+		return nil, fmt.Errorf("read audit anchor dir: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !auditAnchorFilePattern.MatchString(file.Name()) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.anchorDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read audit anchor %s: %w", file.Name(), err)
+		}
+		var anchor models.AuditAnchor
+		if err := json.Unmarshal(data, &anchor); err != nil {
+			return nil, fmt.Errorf("decode audit anchor %s: %w", file.Name(), err)
+		}
+		anchors[anchor.ChainSeq] = append(anchors[anchor.ChainSeq], auditAnchorRef{hash: anchor.RecordHash, source: file.Name()})
+	}
+	return anchors, nil
+}
+
+// auditChainVerifier accumulates the result of walking part of the chain.
+type auditChainVerifier struct {
+	result  *models.AuditChainVerification
+	anchors map[int64][]auditAnchorRef
+}
+
+func newAuditChainVerifier(fromSeq int64, anchors map[int64][]auditAnchorRef) *auditChainVerifier {
+	return &auditChainVerifier{
+		result: &models.AuditChainVerification{
+			FromSeq: fromSeq,
+			LastSeq: fromSeq,
+			Breaks:  []models.AuditChainBreak{},
+		},
+		anchors: anchors,
+	}
+}
+
+// walk verifies the records after afterSeq up to untilSeq. Each record must
+// follow its predecessor without a gap, link to its hash, and hash to its
+// stored record_hash. After a break the walk continues from the stored
+// hash so one altered record is reported once.
+func (v *auditChainVerifier) walk(ctx context.Context, q *repository.Queries, afterSeq int64, prevHash string, untilSeq int64) error {
+	expectedSeq := afterSeq + 1
+	for {
+		rows, err := q.ListAuditChain(ctx, repository.ListAuditChainParams{
+			AfterSeq: afterSeq,
+			UntilSeq: untilSeq,
+			RowLimit: auditChainPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("list audit chain: %w", err)
+		}
+		for _, row := range rows {
+			if row.ChainSeq != expectedSeq {
+				v.addBreak(models.AuditChainBreak{
+					ChainSeq: expectedSeq,
+					AuditID:  row.ID,
+					Reason:   domain.AuditBreakSequenceGap,
+					Expected: strconv.FormatInt(expectedSeq, 10),
+					Actual:   strconv.FormatInt(row.ChainSeq, 10),
+				})
+			}
+			if row.PrevHash != prevHash {
+				v.addBreak(models.AuditChainBreak{ChainSeq: row.ChainSeq, AuditID: row.ID, Reason: domain.AuditBreakLinkMismatch, Expected: prevHash, Actual: row.PrevHash})
+			}
+			if recomputed := auditRecordHash(row); recomputed != row.RecordHash {
+				v.addBreak(models.AuditChainBreak{ChainSeq: row.ChainSeq, AuditID: row.ID, Reason: domain.AuditBreakHashMismatch, Expected: recomputed, Actual: row.RecordHash})
+			}
+			v.checkAnchors(row.ChainSeq, row.ID, row.RecordHash)
+
+			prevHash = row.RecordHash
+			afterSeq = row.ChainSeq
+			expectedSeq = row.ChainSeq + 1
+			v.result.RecordsChecked++
+			v.result.LastSeq = row.ChainSeq
+			v.result.LastHash = row.RecordHash
+		}
+		if len(rows) < auditChainPageSize {
+			return nil
+		}
+	}
+}
+
+func (v *auditChainVerifier) checkAnchors(seq, auditID int64, recordHash string) {
+	refs, ok := v.anchors[seq]
+	if !ok {
+		return
+	}
+	for _, ref := range refs {
+		v.result.AnchorsChecked++
+		if ref.hash != recordHash {
+			v.addBreak(models.AuditChainBreak{ChainSeq: seq, AuditID: auditID, Reason: domain.AuditBreakAnchorMismatch, Expected: ref.hash, Actual: recordHash, Source: ref.source})
+		}
+	}
+	delete(v.anchors, seq)
+}
+
+// checkUnmatchedAnchors reports anchors whose record was never reached,
+// which means the chain was truncated or renumbered.
+func (v *auditChainVerifier) checkUnmatchedAnchors() {
+	seqs := make([]int64, 0, len(v.anchors))
+	for seq := range v.anchors {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		for _, ref := range v.anchors[seq] {
+			v.result.AnchorsChecked++
+			v.addBreak(models.AuditChainBreak{ChainSeq: seq, Reason: domain.AuditBreakAnchorMissing, Expected: ref.hash, Source: ref.source})
+		}
+	}
+	v.anchors = nil
+}
+
+func (v *auditChainVerifier) addBreak(b models.AuditChainBreak) {
+	v.result.BreakCount++
+	if len(v.result.Breaks) < maxAuditChainBreaks {
+		v.result.Breaks = append(v.result.Breaks, b)
+	}
+	observability.IncrementAuditChainBreak(b.Reason)
+}
+
+func (v *auditChainVerifier) finish() *models.AuditChainVerification {
+	v.result.Valid = v.result.BreakCount == 0
+	v.result.VerifiedAt = time.Now().UTC()
+	return v.result
+}
+
+// auditRecordHash mirrors audit_record_hash() in the database: every field
+// is written as "<byte length>:<value>", or "-" when NULL, and the
+// concatenation is hashed with SHA-256.
+func auditRecordHash(row repository.ListAuditChainRow) string {
+	h := sha256.New()
+	writeAuditHashField(h, strconv.FormatInt(row.ChainSeq, 10), true)
+	writeAuditHashField(h, strconv.FormatInt(row.ID, 10), true)
+	writeAuditHashField(h, row.EntityType, true)
+	writeAuditHashField(h, repository.FromPgUUID(row.EntityID).String(), true)
+	writeAuditHashField(h, repository.FromPgUUID(row.ActorID).String(), row.ActorID.Valid)
+	writeAuditHashField(h, row.Action, true)
+	writeAuditHashField(h, derefString(row.PrevState), row.PrevState != nil)
+	writeAuditHashField(h, derefString(row.NextState), row.NextState != nil)
+	writeAuditHashField(h, string(row.Metadata), row.Metadata != nil)
+	writeAuditHashField(h, strconv.FormatInt(row.CreatedAt.Time.UnixMicro(), 10), true)
+	writeAuditHashField(h, row.PrevHash, true)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeAuditHashField(h hash.Hash, value string, valid bool) {
+	if !valid {
+		_, _ = io.WriteString(h, "-")
+		return
+	}
+	_, _ = io.WriteString(h, strconv.Itoa(len(value))+":"+value)
+}
+
+// auditAnchorFileName is zero padded so anchor files sort by chain_seq.
+func auditAnchorFileName(seq int64) string {
+	return fmt.Sprintf("audit-anchor-%020d.json", seq)
+}
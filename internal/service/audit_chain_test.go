@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditChainVerifiesAndAnchors(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	store := repository.NewStore(db)
+	dir := t.TempDir()
+	auditSvc := NewAuditService(store).WithAnchorDir(dir)
+
+	actor := uuid.New()
+	writeRecords := func(n int) {
+		require.NoError(t, store.RunInTx(ctx, func(qtx *repository.Queries) error {
+			for i := 0; i < n; i++ {
+				if err := auditSvc.Write(ctx, qtx, "payout", uuid.New(), &actor, "approved", domain.PayoutStatusAwaitingApproval, domain.PayoutStatusPending, []byte(`{"note": "ok", "amount": 5}`)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	writeRecords(3)
+
+	result, err := auditSvc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.True(t, result.Valid, result.Breaks)
+	require.Equal(t, int64(3), result.RecordsChecked)
+
+	anchor, err := auditSvc.Anchor(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, anchor)
+	require.Equal(t, int64(3), anchor.ChainSeq)
+	_, err = os.Stat(filepath.Join(dir, auditAnchorFileName(3)))
+	require.NoError(t, err)
+	again, err := auditSvc.Anchor(ctx)
+	require.NoError(t, err)
+	require.Nil(t, again, "nothing appended since the last anchor")
+
+	writeRecords(2)
+	anchor, err = auditSvc.Anchor(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), anchor.ChainSeq)
+
+	result, err = auditSvc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.True(t, result.Valid, result.Breaks)
+	require.Equal(t, 4, result.AnchorsChecked, "two anchors, each in the database and on disk")
+
+	// Rewrite a record and re-hash the chain after it, as someone with
+	// superuser access could; only the exported anchors can tell.
+	_, err = db.Exec(ctx, "ALTER TABLE audit_log DISABLE TRIGGER trg_audit_log_immutable")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(context.Background(), "ALTER TABLE audit_log ENABLE TRIGGER trg_audit_log_immutable")
+	})
+	_, err = db.Exec(ctx, "UPDATE audit_log SET action = 'rejected' WHERE chain_seq = 2")
+	require.NoError(t, err)
+
+	result, err = auditSvc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	require.Equal(t, domain.AuditBreakHashMismatch, result.Breaks[0].Reason)
+	require.Equal(t, int64(2), result.Breaks[0].ChainSeq)
+
+	_, err = db.Exec(ctx, `
+		DO $$
+		DECLARE
+		  r RECORD;
+		  v_prev TEXT;
+		  v_hash TEXT;
+		BEGIN
+		  SELECT record_hash INTO v_prev FROM audit_log WHERE chain_seq = 1;
+		  FOR r IN SELECT * FROM audit_log WHERE chain_seq >= 2 ORDER BY chain_seq LOOP
+		    v_hash := audit_record_hash(r.chain_seq, r.id, r.entity_type, r.entity_id, r.actor_id, r.action,
+		      r.prev_state, r.next_state, r.metadata, r.created_at, v_prev);
+		    UPDATE audit_log SET prev_hash = v_prev, record_hash = v_hash WHERE id = r.id;
+		    v_prev := v_hash;
+		  END LOOP;
+		  UPDATE audit_chain_head SET last_hash = v_prev;
+		END $$`)
+	require.NoError(t, err)
+
+	result, err = auditSvc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.False(t, result.Valid)
+	for _, b := range result.Breaks {
+		require.Equal(t, domain.AuditBreakAnchorMismatch, b.Reason)
+	}
+	require.Len(t, result.Breaks, 4)
+
+	// New records link to the rewritten head, not the anchored one, so the
+	// chain is no longer anchored.
+	writeRecords(1)
+	_, err = auditSvc.Anchor(ctx)
+	require.ErrorIs(t, err, ErrAuditChainBroken)
+}
+
+func TestAuditChainConcurrentWriters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	store := repository.NewStore(db)
+	auditSvc := NewAuditService(store)
+	write := func(qtx *repository.Queries) error {
+		return auditSvc.Write(ctx, qtx, "payout", uuid.New(), nil, "created", "", domain.PayoutStatusPending, nil)
+	}
+
+	// A transaction that has written audit records but not committed does
+	// not hold up other writers: the chain head is only locked at commit.
+	open, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer open.Rollback(ctx)
+	require.NoError(t, write(repository.New(open)))
+	other, err := db.Begin(ctx)
+	require.NoError(t, err)
+	defer other.Rollback(ctx)
+	_, err = other.Exec(ctx, "SET LOCAL lock_timeout = '2s'")
+	require.NoError(t, err)
+	require.NoError(t, write(repository.New(other)))
+	require.NoError(t, other.Commit(ctx))
+	require.NoError(t, open.Commit(ctx))
+
+	// Many writers committing at once still produce one gapless chain.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.RunInTx(ctx, func(qtx *repository.Queries) error {
+				for j := 0; j < 5; j++ {
+					if err := write(qtx); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	result, err := auditSvc.VerifyChain(ctx)
+	require.NoError(t, err)
+	require.True(t, result.Valid, result.Breaks)
+	require.Equal(t, int64(102), result.RecordsChecked)
+	require.Equal(t, int64(102), result.LastSeq)
+
+	// Chained records stay immutable.
+	_, err = db.Exec(ctx, "UPDATE audit_log SET chain_seq = chain_seq + 1000 WHERE chain_seq = 1")
+	require.ErrorContains(t, err, "append-only")
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// auditExportPageSize is the number of audit records read per page while
+// exporting CSV.
+const auditExportPageSize = 1000
+
+var (
+	ErrAuditCursorInvalid  = errors.New("invalid audit cursor")
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// auditExportColumns are the CSV columns of an audit log export.
+var auditExportColumns = []string{"id", "chain_seq", "created_at", "entity_type", "entity_id", "actor_id", "action", "prev_state", "next_state", "metadata", "record_hash"}
+
+// AuditLogFilter narrows an audit log query. Empty fields match everything;
+// To is exclusive.
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   *uuid.UUID
+	ActorID    *uuid.UUID
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// ListAuditLogs returns up to limit records matching filter, newest first,
+// starting after cursor. The returned cursor is empty on the last page.
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter AuditLogFilter, cursor string, limit int32) ([]models.AuditLogEntry, string, error) {
+	params, err := auditLogParams(filter, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	params.RowLimit = limit
+
+	rows, err := s.store.Queries().ListAuditLogs(ctx, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("list audit logs: %w", err)
+	}
+	entries := make([]models.AuditLogEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, toAuditLogEntryModel(row))
+	}
+
+	next := ""
+	if len(rows) > 0 && int32(len(rows)) == limit {
+		last := rows[len(rows)-1]
+		next = encodeAuditCursor(last.CreatedAt.Time, last.ID)
+	}
+	return entries, next, nil
+}
+
+// ExportAuditLogs writes every record matching filter to w as CSV, newest
+// first. Records are read page by page so exports of any size stream.
+func (s *AuditService) ExportAuditLogs(ctx context.Context, filter AuditLogFilter, w io.Writer) error {
+	params, err := auditLogParams(filter, "")
+	if err != nil {
+		return err
+	}
+	params.RowLimit = auditExportPageSize
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(auditExportColumns); err != nil {
+		return fmt.Errorf("write audit export header: %w", err)
+	}
+	q := s.store.Queries()
+	for {
+		rows, err := q.ListAuditLogs(ctx, params)
+		if err != nil {
+			return fmt.Errorf("list audit logs: %w", err)
+		}
+		for _, row := range rows {
+			actor := ""
+			if row.ActorID.Valid {
+				actor = repository.FromPgUUID(row.ActorID).String()
+			}
+			if err := cw.Write([]string{
+				strconv.FormatInt(row.ID, 10),
+				strconv.FormatInt(row.ChainSeq, 10),
+				row.CreatedAt.Time.UTC().Format(time.RFC3339Nano),
+				row.EntityType,
+				repository.FromPgUUID(row.EntityID).String(),
+				actor,
+				row.Action,
+				derefString(row.PrevState),
+				derefString(row.NextState),
+				string(row.Metadata),
+				row.RecordHash,
+			}); err != nil {
+				return fmt.Errorf("write audit export row: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("flush audit export: %w", err)
+		}
+		if len(rows) < auditExportPageSize {
+			return nil
+		}
+		last := rows[len(rows)-1]
+		params.CursorAt = pgtype.Timestamptz{Time: last.CreatedAt.Time, Valid: true}
+		params.CursorID = &last.ID
+	}
+}
+
+// TransactionAuditTrail returns the redacted audit trail of a transaction
+// and its payouts, oldest first. Unless anyAccount is set, users only see
+// transactions that touch one of their accounts; others are reported as not
+// found.
+func (s *AuditService) TransactionAuditTrail(ctx context.Context, transactionID, userID uuid.UUID, anyAccount bool) ([]models.AuditTrailEvent, error) {
+	q := s.store.Queries()
+	if !anyAccount {
+		visible, err := q.IsTransactionVisibleToUser(ctx, repository.IsTransactionVisibleToUserParams{
+			TransactionID: repository.ToPgUUID(transactionID),
+			UserID:        repository.ToPgUUID(userID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check transaction visibility: %w", err)
+		}
+		if visible == nil || !*visible {
+			return nil, ErrTransactionNotFound
+		}
+	}
+
+	rows, err := q.ListTransactionAuditTrail(ctx, repository.ToPgUUID(transactionID))
+	if err != nil {
+		return nil, fmt.Errorf("list transaction audit trail: %w", err)
+	}
+	if len(rows) == 0 && anyAccount {
+		return nil, ErrTransactionNotFound
+	}
+	events := make([]models.AuditTrailEvent, 0, len(rows))
+	for _, row := range rows {
+		actorType := domain.AuditActorSystem
+		if row.ActorID.Valid {
+			actorType = domain.AuditActorStaff
+			if repository.FromPgUUID(row.ActorID) == userID && !anyAccount {
+				actorType = domain.AuditActorCustomer
+			}
+		}
+		events = append(events, models.AuditTrailEvent{
+			EntityType: row.EntityType,
+			Action:     row.Action,
+			PrevState:  derefString(row.PrevState),
+			NextState:  derefString(row.NextState),
+			ActorType:  actorType,
+			CreatedAt:  row.CreatedAt.Time,
+		})
+	}
+	return events, nil
+}
+
+func auditLogParams(filter AuditLogFilter, cursor string) (repository.ListAuditLogsParams, error) {
+	params := repository.ListAuditLogsParams{
+		EntityType: textParam(filter.EntityType),
+		Action:     textParam(filter.Action),
+	}
+	if filter.EntityID != nil {
+		params.EntityID = repository.ToPgUUID(*filter.EntityID)
+	}
+	if filter.ActorID != nil {
+		params.ActorID = repository.ToPgUUID(*filter.ActorID)
+	}
+	if filter.From != nil {
+		params.CreatedFrom = pgtype.Timestamptz{Time: *filter.From, Valid: true}
+	}
+	if filter.To != nil {
+		params.CreatedTo = pgtype.Timestamptz{Time: *filter.To, Valid: true}
+	}
+	if cursor != "" {
+		at, id, err := decodeAuditCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		params.CursorAt = pgtype.Timestamptz{Time: at, Valid: true}
+		params.CursorID = &id
+	}
+	return params, nil
+}
+
+// encodeAuditCursor makes an opaque cursor from the position of the last
+// record returned.
+func encodeAuditCursor(createdAt time.Time, id int64) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + ":" + strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAuditCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrAuditCursorInvalid
+	}
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, ErrAuditCursorInvalid
+	}
+	at, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrAuditCursorInvalid
+	}
+	auditID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrAuditCursorInvalid
+	}
+	return time.UnixMicro(at).UTC(), auditID, nil
+}
+
+func toAuditLogEntryModel(row repository.ListAuditLogsRow) models.AuditLogEntry {
+	return models.AuditLogEntry{
+		ID:         row.ID,
+		ChainSeq:   row.ChainSeq,
+		EntityType: row.EntityType,
+		EntityID:   repository.FromPgUUID(row.EntityID),
+		ActorID:    optionalUUID(row.ActorID),
+		Action:     row.Action,
+		PrevState:  derefString(row.PrevState),
+		NextState:  derefString(row.NextState),
+		Metadata:   row.Metadata,
+		CreatedAt:  row.CreatedAt.Time,
+		RecordHash: row.RecordHash,
+	}
+}
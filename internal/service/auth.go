@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/mail"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/password"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Defaults used until the With... builders are called.
+const (
+	defaultMaxFailedLogins  = 5
+	defaultLoginLockout     = 15 * time.Minute
+	defaultPasswordResetTTL = 30 * time.Minute
+	defaultAccessTokenTTL   = 15 * time.Minute
+	defaultRefreshTokenTTL  = 30 * 24 * time.Hour
+)
+
+// opaqueTokenBytes is the entropy of password reset and refresh tokens.
+const opaqueTokenBytes = 32
+
+var (
+	// ErrInvalidCredentials indicates an unknown email or a wrong password.
+	// The two are not told apart.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrInvalidRegistration indicates a missing username or a malformed email.
+	ErrInvalidRegistration = errors.New("invalid registration")
+	// ErrWeakPassword indicates a password that does not meet the policy.
+	ErrWeakPassword = password.ErrWeak
+	// ErrInvalidResetToken indicates an unknown, used or expired reset token.
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+)
+
+// AccountLockedError is returned while a user is locked out after repeated
+// failed logins.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return "account locked until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+// Registration is a new user's sign-up details.
+type Registration struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// AuthService registers users with a password, checks credentials with a
+// lockout after repeated failures, resets passwords with single-use tokens
+// sent by email, and manages the sessions logins open and the API keys
+// services use instead.
+type AuthService struct {
+	store           QueryStore
+	audit           *AuditService
+	mailer          mail.Sender
+	redis           redis.Cmdable
+	maxFailedLogins int32
+	lockout         time.Duration
+	resetTTL        time.Duration
+	accessTTL       time.Duration
+	refreshTTL      time.Duration
+	apiKeyGrace     time.Duration
+	now             func() time.Time
+}
+
+// NewAuthService creates a new AuthService instance. Mail goes to the log
+// until WithMailer is called, and revoked access tokens are checked in
+// Postgres until WithRedis is called.
+func NewAuthService(store QueryStore) *AuthService {
+	return &AuthService{
+		store:           store,
+		audit:           NewAuditService(store),
+		mailer:          mail.LogSender{},
+		maxFailedLogins: defaultMaxFailedLogins,
+		lockout:         defaultLoginLockout,
+		resetTTL:        defaultPasswordResetTTL,
+		accessTTL:       defaultAccessTokenTTL,
+		refreshTTL:      defaultRefreshTokenTTL,
+		apiKeyGrace:     defaultAPIKeyRotationGrace,
+		now:             time.Now,
+	}
+}
+
+// WithLockout locks a user for duration after maxAttempts failed logins in a row.
+func (s *AuthService) WithLockout(maxAttempts int, duration time.Duration) *AuthService {
+	s.maxFailedLogins = int32(maxAttempts)
+	s.lockout = duration
+	return s
+}
+
+// WithResetTTL sets how long a password reset token stays valid.
+func (s *AuthService) WithResetTTL(ttl time.Duration) *AuthService {
+	s.resetTTL = ttl
+	return s
+}
+
+// WithMailer sets where password reset emails are sent.
+func (s *AuthService) WithMailer(sender mail.Sender) *AuthService {
+	s.mailer = sender
+	return s
+}
+
+// Register creates a user with the "user" role and a password that meets
+// the policy.
+func (s *AuthService) Register(ctx context.Context, reg Registration) (*models.User, error) {
+	reg.Username = strings.TrimSpace(reg.Username)
+	reg.Email = strings.ToLower(strings.TrimSpace(reg.Email))
+	if reg.Username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidRegistration)
+	}
+	if at := strings.Index(reg.Email, "@"); at < 1 || at == len(reg.Email)-1 {
+		return nil, fmt.Errorf("%w: email is invalid", ErrInvalidRegistration)
+	}
+	if err := validatePassword(reg.Password, reg.Username, reg.Email); err != nil {
+		return nil, err
+	}
+	hash, err := password.Hash(reg.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: uuid.New(), Username: reg.Username, Email: reg.Email, Role: "user"}
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		created, err := qtx.CreateUser(ctx, repository.CreateUserParams{
+			ID:       repository.ToPgUUID(user.ID),
+			Username: user.Username,
+			Email:    user.Email,
+			Role:     user.Role,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		user.KYCStatus = created.KycStatus
+		user.KYCTier = created.KycTier
+		user.CreatedAt = created.CreatedAt.Time
+		if err := qtx.SetUserPassword(ctx, repository.SetUserPasswordParams{ID: repository.ToPgUUID(user.ID), PasswordHash: &hash}); err != nil {
+			return fmt.Errorf("failed to set password: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "user", user.ID, &user.ID, "registered", "", user.Role, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Authenticate returns the user whose email and password match. Each wrong
+// password counts towards the lockout; a locked user is refused with
+// *AccountLockedError even when the password is right.
+func (s *AuthService) Authenticate(ctx context.Context, email, plain string) (*models.User, error) {
+	email = strings.TrimSpace(email)
+	queries := s.store.Queries()
+	row, err := queries.GetUserCredentialsByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Spend the same time as a real check so response times do not
+			// reveal which emails are registered.
+			_, _ = password.Verify(plain, dummyPasswordHash())
+			observability.IncrementLoginAttempt("invalid")
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	userID := repository.FromPgUUID(row.ID)
+	now := s.now()
+	if row.LockedUntil.Valid && row.LockedUntil.Time.After(now) {
+		observability.IncrementLoginAttempt("locked")
+		return nil, &AccountLockedError{Until: row.LockedUntil.Time}
+	}
+
+	ok := false
+	if row.PasswordHash != nil {
+		ok, err = password.Verify(plain, *row.PasswordHash)
+		if err != nil {
+			zap.L().Error("stored password hash is malformed", zap.String("user_id", userID.String()), zap.Error(err))
+			ok = false
+		}
+	} else {
+		_, _ = password.Verify(plain, dummyPasswordHash())
+	}
+	if !ok {
+		observability.IncrementLoginAttempt("invalid")
+		return nil, s.recordFailedLogin(ctx, userID, now)
+	}
+
+	if err := queries.RecordSuccessfulLogin(ctx, row.ID); err != nil {
+		return nil, fmt.Errorf("failed to record login: %w", err)
+	}
+	observability.IncrementLoginAttempt("success")
+	return &models.User{
+		ID:        userID,
+		Username:  row.Username,
+		Email:     row.Email,
+		Role:      row.Role,
+		KYCStatus: row.KycStatus,
+		KYCTier:   row.KycTier,
+		CreatedAt: row.CreatedAt.Time,
+	}, nil
+}
+
+// recordFailedLogin counts a failed login and locks the user when it
+// reaches the maximum. It returns the error the caller should report.
+func (s *AuthService) recordFailedLogin(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	lockedUntil := now.Add(s.lockout)
+	var locked bool
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.RecordFailedLogin(ctx, repository.RecordFailedLoginParams{
+			ID:          repository.ToPgUUID(userID),
+			MaxAttempts: s.maxFailedLogins,
+			LockedUntil: pgtype.Timestamptz{Time: lockedUntil, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record failed login: %w", err)
+		}
+		// The count restarts only when this failure locked the user.
+		locked = row.FailedLoginAttempts == 0 && row.LockedUntil.Valid && row.LockedUntil.Time.After(now)
+		if !locked {
+			return nil
+		}
+		metadata, err := json.Marshal(map[string]any{"locked_until": lockedUntil.UTC(), "max_attempts": s.maxFailedLogins})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "user", userID, nil, "login_locked", "", "", metadata)
+	})
+	if err != nil {
+		return err
+	}
+	if locked {
+		zap.L().Warn("user locked after failed logins", zap.String("user_id", userID.String()), zap.Time("locked_until", lockedUntil))
+	}
+	return ErrInvalidCredentials
+}
+
+// RequestPasswordReset emails a reset token to the user with email, if
+// there is one, and revokes any token sent before. It succeeds for unknown
+// emails too so callers cannot learn who is registered.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	row, err := s.store.Queries().GetUserCredentialsByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("generate reset token: %w", err)
+	}
+	expiresAt := s.now().Add(s.resetTTL)
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		if err := qtx.RevokePasswordResetTokens(ctx, row.ID); err != nil {
+			return fmt.Errorf("failed to revoke reset tokens: %w", err)
+		}
+		if err := qtx.CreatePasswordResetToken(ctx, repository.CreatePasswordResetTokenParams{
+			TokenHash: hashToken(token),
+			UserID:    row.ID,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to store reset token: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "user", repository.FromPgUUID(row.ID), nil, "password_reset_requested", "", "", nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello %s,\n\nWe received a request to reset your password. Use this token to choose a new one:\n\n%s\n\nSubmit it with your new password to POST /v1/auth/password-reset/confirm. It expires at %s and can be used once.\n\nIf you did not ask for this, you can ignore this email.\n",
+		row.Username, token, expiresAt.UTC().Format(time.RFC1123))
+	if err := s.mailer.Send(ctx, mail.Message{To: row.Email, Subject: "Reset your password", Body: body}); err != nil {
+		return fmt.Errorf("failed to send reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset,
+// lifts any lockout and ends every session of the user. The token is spent
+// only when the password is accepted.
+func (s *AuthService) ResetPassword(ctx context.Context, token, plain string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	var revoked []revokedAccess
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		userID, err := qtx.ConsumePasswordResetToken(ctx, repository.ConsumePasswordResetTokenParams{
+			TokenHash: hashToken(token),
+			Now:       pgtype.Timestamptz{Time: s.now(), Valid: true},
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidResetToken
+			}
+			return fmt.Errorf("failed to consume reset token: %w", err)
+		}
+		user, err := qtx.GetUser(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch user: %w", err)
+		}
+		if err := validatePassword(plain, user.Username, user.Email); err != nil {
+			return err
+		}
+		hash, err := password.Hash(plain)
+		if err != nil {
+			return err
+		}
+		if err := qtx.SetUserPassword(ctx, repository.SetUserPasswordParams{ID: userID, PasswordHash: &hash}); err != nil {
+			return fmt.Errorf("failed to set password: %w", err)
+		}
+		revoked, err = s.revokeAllSessions(ctx, qtx, repository.FromPgUUID(userID), sessionRevokedPasswordReset)
+		if err != nil {
+			return err
+		}
+		return s.audit.Write(ctx, qtx, "user", repository.FromPgUUID(userID), nil, "password_reset", "", "", nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.markSessionsRevoked(ctx, revoked)
+	return nil
+}
+
+// validatePassword applies the password policy, refusing passwords that
+// contain the username or the local part of the email.
+func validatePassword(plain, username, email string) error {
+	local, _, _ := strings.Cut(email, "@")
+	return password.Validate(plain, username, local)
+}
+
+// newOpaqueToken returns a random URL-safe token. Only its hashToken is
+// stored.
+func newOpaqueToken() (string, error) {
+	raw := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash is checked against when there is no real hash, so a
+// failed login costs the same whether or not the user exists.
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = password.Hash(uuid.NewString())
+	})
+	return dummyHash
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/mail"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type capturingMailer struct {
+	sent []mail.Message
+}
+
+func (m *capturingMailer) Send(_ context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestAuthValidation(t *testing.T) {
+	svc := NewAuthService(panicStore{})
+	ctx := context.Background()
+
+	_, err := svc.Register(ctx, Registration{Email: "a@example.com", Password: "Correct-Horse-42"})
+	require.ErrorIs(t, err, ErrInvalidRegistration)
+	_, err = svc.Register(ctx, Registration{Username: "ayo", Email: "not-an-email", Password: "Correct-Horse-42"})
+	require.ErrorIs(t, err, ErrInvalidRegistration)
+	_, err = svc.Register(ctx, Registration{Username: "ayo", Email: "ayo@example.com", Password: "password"})
+	require.ErrorIs(t, err, ErrWeakPassword)
+	_, err = svc.Register(ctx, Registration{Username: "sam", Email: "ayodele@example.com", Password: "Ayodele-Rocks-42"})
+	require.ErrorIs(t, err, ErrWeakPassword)
+
+	require.ErrorIs(t, svc.ResetPassword(ctx, " ", "Correct-Horse-42"), ErrInvalidResetToken)
+}
+
+func TestAuthLockoutAndReset(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	mailer := &capturingMailer{}
+	now := time.Now().UTC()
+	svc := NewAuthService(repository.NewStore(db)).WithLockout(3, 10*time.Minute).WithResetTTL(time.Hour).WithMailer(mailer)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, Registration{Username: "kim", Email: " Kim@Example.com ", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	require.Equal(t, "kim@example.com", user.Email)
+
+	got, err := svc.Authenticate(ctx, "KIM@example.com", "Correct-Horse-42")
+	require.NoError(t, err)
+	require.Equal(t, user.ID, got.ID)
+	_, err = svc.Authenticate(ctx, "nobody@example.com", "Correct-Horse-42")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Emails are unique regardless of case, so a login matches one user.
+	_, err = db.Exec(ctx, `INSERT INTO users (id, username, email, role) VALUES ($1, 'kim2', 'KIM@EXAMPLE.COM', 'user')`, uuid.New())
+	require.ErrorContains(t, err, "idx_users_email_lower")
+
+	// A success clears the count, so only three failures in a row lock.
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Wrong-Horse-42")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Correct-Horse-42")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = svc.Authenticate(ctx, "kim@example.com", "Wrong-Horse-42")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	var locked *AccountLockedError
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Correct-Horse-42")
+	require.ErrorAs(t, err, &locked)
+	require.WithinDuration(t, now.Add(10*time.Minute), locked.Until, time.Second)
+
+	// The lock expires on its own.
+	now = now.Add(11 * time.Minute)
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Correct-Horse-42")
+	require.NoError(t, err)
+
+	// Only the latest reset token works, once, and not after it expires.
+	require.NoError(t, svc.RequestPasswordReset(ctx, "nobody@example.com"))
+	require.Empty(t, mailer.sent)
+	tokenPattern := regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`)
+	require.NoError(t, svc.RequestPasswordReset(ctx, "kim@example.com"))
+	require.NoError(t, svc.RequestPasswordReset(ctx, "kim@example.com"))
+	require.Len(t, mailer.sent, 2)
+	require.Equal(t, "kim@example.com", mailer.sent[1].To)
+	first := tokenPattern.FindString(mailer.sent[0].Body)
+	second := tokenPattern.FindString(mailer.sent[1].Body)
+	require.NotEmpty(t, second)
+	require.ErrorIs(t, svc.ResetPassword(ctx, first, "Brand-New-Secret-7"), ErrInvalidResetToken)
+	require.ErrorIs(t, svc.ResetPassword(ctx, second, "weak"), ErrWeakPassword)
+	require.NoError(t, svc.ResetPassword(ctx, second, "Brand-New-Secret-7"))
+	require.ErrorIs(t, svc.ResetPassword(ctx, second, "Brand-New-Secret-8"), ErrInvalidResetToken)
+
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Correct-Horse-42")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = svc.Authenticate(ctx, "kim@example.com", "Brand-New-Secret-7")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RequestPasswordReset(ctx, "kim@example.com"))
+	expired := tokenPattern.FindString(mailer.sent[2].Body)
+	now = now.Add(2 * time.Hour)
+	require.ErrorIs(t, svc.ResetPassword(ctx, expired, "Brand-New-Secret-9"), ErrInvalidResetToken)
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBeneficiaryNotFound      = errors.New("beneficiary not found")
+	ErrBeneficiaryCoolingDown   = errors.New("beneficiary is still in its cool-down period")
+	ErrBeneficiaryOwnerMismatch = errors.New("beneficiary does not belong to the account owner")
+)
+
+// BeneficiaryService manages users' saved payout destinations.
+type BeneficiaryService struct {
+	store    QueryStore
+	audit    *AuditService
+	cooldown time.Duration
+	// screening flags saved payees that match a sanctions list. Payouts
+	// to them are held by the payout service's own screening.
+	screening *ScreeningService
+}
+
+func NewBeneficiaryService(store QueryStore) *BeneficiaryService {
+	return &BeneficiaryService{
+		store: store,
+		audit: NewAuditService(store),
+	}
+}
+
+// WithCooldown delays the first payout to a new beneficiary, or to one whose
+// account details changed, by d. Zero disables the cool-down.
+func (s *BeneficiaryService) WithCooldown(d time.Duration) *BeneficiaryService {
+	if d >= 0 {
+		s.cooldown = d
+	}
+	return s
+}
+
+// WithScreening screens beneficiary names against sanctions lists when they
+// are saved. A match is audited but does not stop the save.
+func (s *BeneficiaryService) WithScreening(screening *ScreeningService) *BeneficiaryService {
+	s.screening = screening
+	return s
+}
+
+// CreateBeneficiary validates and saves a destination for userID.
+func (s *BeneficiaryService) CreateBeneficiary(ctx context.Context, userID uuid.UUID, dest PayoutDestinationInput, actorID *uuid.UUID) (*models.Beneficiary, error) {
+	if err := dest.Validate(); err != nil {
+		return nil, err
+	}
+	dest = dest.Normalized()
+
+	var row repository.Beneficiary
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		var err error
+		row, err = qtx.CreateBeneficiary(ctx, repository.CreateBeneficiaryParams{
+			ID:            repository.ToPgUUID(uuid.New()),
+			UserID:        repository.ToPgUUID(userID),
+			Name:          dest.Name,
+			Scheme:        dest.Scheme,
+			Iban:          textParam(dest.IBAN),
+			Bic:           textParam(dest.BIC),
+			RoutingNumber: textParam(dest.RoutingNumber),
+			AccountNumber: textParam(dest.AccountNumber),
+			SortCode:      textParam(dest.SortCode),
+			AvailableAt:   pgtype.Timestamptz{Time: time.Now().Add(s.cooldown), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("create beneficiary: %w", err)
+		}
+		if err := s.writeAudit(ctx, qtx, row, actorID, "created"); err != nil {
+			return err
+		}
+		return s.screenBeneficiary(ctx, qtx, row, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	beneficiary := toBeneficiaryModel(row)
+	return &beneficiary, nil
+}
+
+// GetBeneficiary returns an active beneficiary by ID.
+func (s *BeneficiaryService) GetBeneficiary(ctx context.Context, id uuid.UUID) (*models.Beneficiary, error) {
+	row, err := s.store.Queries().GetBeneficiary(ctx, repository.ToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBeneficiaryNotFound
+		}
+		return nil, fmt.Errorf("get beneficiary: %w", err)
+	}
+	beneficiary := toBeneficiaryModel(row)
+	return &beneficiary, nil
+}
+
+// ListBeneficiaries returns a user's active beneficiaries, newest first.
+func (s *BeneficiaryService) ListBeneficiaries(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]models.Beneficiary, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := s.store.Queries().ListBeneficiariesByUser(ctx, repository.ListBeneficiariesByUserParams{
+		UserID: repository.ToPgUUID(userID),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list beneficiaries: %w", err)
+	}
+	out := make([]models.Beneficiary, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toBeneficiaryModel(row))
+	}
+	return out, nil
+}
+
+// UpdateBeneficiary replaces a beneficiary's details. Changing the account
+// details restarts the cool-down; renaming alone does not.
+func (s *BeneficiaryService) UpdateBeneficiary(ctx context.Context, id uuid.UUID, dest PayoutDestinationInput, actorID *uuid.UUID) (*models.Beneficiary, error) {
+	if err := dest.Validate(); err != nil {
+		return nil, err
+	}
+	dest = dest.Normalized()
+
+	var row repository.Beneficiary
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		current, err := qtx.GetBeneficiary(ctx, repository.ToPgUUID(id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrBeneficiaryNotFound
+			}
+			return fmt.Errorf("get beneficiary: %w", err)
+		}
+
+		availableAt := current.AvailableAt
+		if beneficiaryDestination(current) != dest.withName(current.Name) {
+			availableAt = pgtype.Timestamptz{Time: time.Now().Add(s.cooldown), Valid: true}
+		}
+		row, err = qtx.UpdateBeneficiary(ctx, repository.UpdateBeneficiaryParams{
+			ID:            current.ID,
+			Name:          dest.Name,
+			Scheme:        dest.Scheme,
+			Iban:          textParam(dest.IBAN),
+			Bic:           textParam(dest.BIC),
+			RoutingNumber: textParam(dest.RoutingNumber),
+			AccountNumber: textParam(dest.AccountNumber),
+			SortCode:      textParam(dest.SortCode),
+			AvailableAt:   availableAt,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrBeneficiaryNotFound
+			}
+			return fmt.Errorf("update beneficiary: %w", err)
+		}
+		if err := s.writeAudit(ctx, qtx, row, actorID, "updated"); err != nil {
+			return err
+		}
+		if row.Name == current.Name {
+			return nil
+		}
+		return s.screenBeneficiary(ctx, qtx, row, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	beneficiary := toBeneficiaryModel(row)
+	return &beneficiary, nil
+}
+
+// DeleteBeneficiary soft-deletes a beneficiary. Payouts that already
+// reference it keep the reference.
+func (s *BeneficiaryService) DeleteBeneficiary(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) error {
+	return s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		current, err := qtx.GetBeneficiary(ctx, repository.ToPgUUID(id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrBeneficiaryNotFound
+			}
+			return fmt.Errorf("get beneficiary: %w", err)
+		}
+		rows, err := qtx.DeleteBeneficiary(ctx, current.ID)
+		if err != nil {
+			return fmt.Errorf("delete beneficiary: %w", err)
+		}
+		if rows == 0 {
+			return ErrBeneficiaryNotFound
+		}
+		return s.writeAudit(ctx, qtx, current, actorID, "deleted")
+	})
+}
+
+func (s *BeneficiaryService) writeAudit(ctx context.Context, qtx *repository.Queries, row repository.Beneficiary, actorID *uuid.UUID, action string) error {
+	metadata, err := json.Marshal(map[string]any{
+		"user_id":      repository.FromPgUUID(row.UserID),
+		"destination":  beneficiaryDestination(row),
+		"available_at": row.AvailableAt.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal beneficiary audit metadata: %w", err)
+	}
+	return s.audit.Write(ctx, qtx, "beneficiary", repository.FromPgUUID(row.ID), actorID, action, "", "", metadata)
+}
+
+// screenBeneficiary audits a sanctions match on the beneficiary's name.
+func (s *BeneficiaryService) screenBeneficiary(ctx context.Context, qtx *repository.Queries, row repository.Beneficiary, actorID *uuid.UUID) error {
+	hit, err := s.screening.screen(ctx, qtx, row.Name)
+	if err != nil || hit == nil {
+		return err
+	}
+	recordScreeningHit("beneficiary", hit)
+	metadata, err := json.Marshal(hit)
+	if err != nil {
+		return fmt.Errorf("marshal beneficiary screening metadata: %w", err)
+	}
+	return s.audit.Write(ctx, qtx, "beneficiary", repository.FromPgUUID(row.ID), actorID, "screening_hit", "", "", metadata)
+}
+
+// resolveBeneficiary loads the destination for a payout to a saved
+// beneficiary, enforcing ownership and the cool-down.
+func resolveBeneficiary(ctx context.Context, qtx *repository.Queries, beneficiaryID, accountOwner uuid.UUID, now time.Time) (PayoutDestinationInput, error) {
+	row, err := qtx.GetBeneficiary(ctx, repository.ToPgUUID(beneficiaryID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PayoutDestinationInput{}, ErrBeneficiaryNotFound
+		}
+		return PayoutDestinationInput{}, fmt.Errorf("get beneficiary: %w", err)
+	}
+	if repository.FromPgUUID(row.UserID) != accountOwner {
+		return PayoutDestinationInput{}, ErrBeneficiaryOwnerMismatch
+	}
+	if now.Before(row.AvailableAt.Time) {
+		return PayoutDestinationInput{}, fmt.Errorf("%w until %s", ErrBeneficiaryCoolingDown, row.AvailableAt.Time.UTC().Format(time.RFC3339))
+	}
+	return beneficiaryDestination(row), nil
+}
+
+func beneficiaryDestination(row repository.Beneficiary) PayoutDestinationInput {
+	return PayoutDestinationInput{
+		Scheme:        row.Scheme,
+		Name:          row.Name,
+		IBAN:          derefString(row.Iban),
+		BIC:           derefString(row.Bic),
+		RoutingNumber: derefString(row.RoutingNumber),
+		AccountNumber: derefString(row.AccountNumber),
+		SortCode:      derefString(row.SortCode),
+	}
+}
+
+// withName returns d with its payee name replaced, for comparing account details.
+func (d PayoutDestinationInput) withName(name string) PayoutDestinationInput {
+	d.Name = name
+	return d
+}
+
+func toBeneficiaryModel(row repository.Beneficiary) models.Beneficiary {
+	return models.Beneficiary{
+		ID:            repository.FromPgUUID(row.ID),
+		UserID:        repository.FromPgUUID(row.UserID),
+		Name:          row.Name,
+		Scheme:        row.Scheme,
+		IBAN:          derefString(row.Iban),
+		BIC:           derefString(row.Bic),
+		RoutingNumber: derefString(row.RoutingNumber),
+		AccountNumber: derefString(row.AccountNumber),
+		SortCode:      derefString(row.SortCode),
+		AvailableAt:   row.AvailableAt.Time,
+		CreatedAt:     row.CreatedAt.Time,
+		UpdatedAt:     row.UpdatedAt.Time,
+	}
+}
+
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
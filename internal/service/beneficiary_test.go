@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPayoutToBeneficiary(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	payoutSvc := NewPayoutService(store, &stubGateway{ref: "MOCK-REF"})
+	beneficiarySvc := NewBeneficiaryService(store)
+	ctx := context.Background()
+
+	owner := &models.User{ID: uuid.New(), Username: "beneficiary-owner", Email: "beneficiary-owner@example.com"}
+	other := &models.User{ID: uuid.New(), Username: "beneficiary-other", Email: "beneficiary-other@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, owner))
+	require.NoError(t, repoSvc.CreateUser(ctx, other))
+	account := &models.Account{ID: uuid.New(), UserID: owner.ID, Currency: "USD", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	saved, err := beneficiarySvc.CreateBeneficiary(ctx, owner.ID, PayoutDestinationInput{
+		Name:          "Landlord",
+		RoutingNumber: "021000021",
+		AccountNumber: "123456789",
+	}, &owner.ID)
+	require.NoError(t, err)
+	require.Equal(t, "US_ACH", saved.Scheme)
+
+	foreign, err := beneficiarySvc.CreateBeneficiary(ctx, other.ID, PayoutDestinationInput{
+		Name: "Someone Else",
+		IBAN: "GB29 NWBK 6016 1331 9268 19",
+	}, &other.ID)
+	require.NoError(t, err)
+	require.Equal(t, "GB29NWBK60161331926819", foreign.IBAN)
+
+	_, err = payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:     account.ID,
+		AmountMicros:  500_000,
+		Currency:      "USD",
+		ReferenceID:   "beneficiary-foreign",
+		BeneficiaryID: &foreign.ID,
+	})
+	require.ErrorIs(t, err, ErrBeneficiaryOwnerMismatch)
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:     account.ID,
+		AmountMicros:  500_000,
+		Currency:      "USD",
+		ReferenceID:   "beneficiary-owned",
+		BeneficiaryID: &saved.ID,
+	})
+	require.NoError(t, err)
+
+	payout, err := payoutSvc.GetPayout(ctx, resp.PayoutID)
+	require.NoError(t, err)
+	require.NotNil(t, payout.BeneficiaryID)
+	require.Equal(t, saved.ID, *payout.BeneficiaryID)
+
+	txRow, err := repository.New(db).GetTransaction(ctx, repository.ToPgUUID(payout.TransactionID))
+	require.NoError(t, err)
+	dest := extractDestination(txRow.Metadata)
+	require.Equal(t, "021000021", dest.RoutingNumber)
+	require.Equal(t, "Landlord (021000021/123456789)", formatDestination(dest))
+}
+
+func TestBeneficiaryCooldown(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	payoutSvc := NewPayoutService(store, &stubGateway{ref: "MOCK-REF"})
+	beneficiarySvc := NewBeneficiaryService(store).WithCooldown(time.Hour)
+	ctx := context.Background()
+
+	owner := &models.User{ID: uuid.New(), Username: "cooldown-owner", Email: "cooldown-owner@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, owner))
+	account := &models.Account{ID: uuid.New(), UserID: owner.ID, Currency: "GBP", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	saved, err := beneficiarySvc.CreateBeneficiary(ctx, owner.ID, PayoutDestinationInput{
+		Name:          "Plumber",
+		SortCode:      "60-16-13",
+		AccountNumber: "31926819",
+	}, &owner.ID)
+	require.NoError(t, err)
+	require.True(t, saved.AvailableAt.After(time.Now().Add(59*time.Minute)))
+
+	_, err = payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:     account.ID,
+		AmountMicros:  100_000,
+		Currency:      "GBP",
+		ReferenceID:   "cooldown-blocked",
+		BeneficiaryID: &saved.ID,
+	})
+	require.ErrorIs(t, err, ErrBeneficiaryCoolingDown)
+
+	_, err = db.Exec(ctx, "UPDATE beneficiaries SET available_at = NOW() - INTERVAL '1 minute' WHERE id = $1", repository.ToPgUUID(saved.ID))
+	require.NoError(t, err)
+
+	renamed, err := beneficiarySvc.UpdateBeneficiary(ctx, saved.ID, PayoutDestinationInput{
+		Name:          "Plumber Ltd",
+		SortCode:      "601613",
+		AccountNumber: "31926819",
+	}, &owner.ID)
+	require.NoError(t, err)
+	require.True(t, renamed.AvailableAt.Before(time.Now()), "renaming must not restart the cool-down")
+
+	_, err = payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:     account.ID,
+		AmountMicros:  100_000,
+		Currency:      "GBP",
+		ReferenceID:   "cooldown-elapsed",
+		BeneficiaryID: &saved.ID,
+	})
+	require.NoError(t, err)
+
+	moved, err := beneficiarySvc.UpdateBeneficiary(ctx, saved.ID, PayoutDestinationInput{
+		Name:          "Plumber Ltd",
+		SortCode:      "601613",
+		AccountNumber: "12345678",
+	}, &owner.ID)
+	require.NoError(t, err)
+	require.True(t, moved.AvailableAt.After(time.Now()), "changing account details restarts the cool-down")
+
+	require.NoError(t, beneficiarySvc.DeleteBeneficiary(ctx, saved.ID, &owner.ID))
+	_, err = beneficiarySvc.GetBeneficiary(ctx, saved.ID)
+	require.ErrorIs(t, err, ErrBeneficiaryNotFound)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+)
+
+// availableFunds is what an account can spend: its balance less funds held
+// by open payouts, plus its approved overdraft.
+func availableFunds(balance, locked, overdraftLimit int64) int64 {
+	return balance - locked + overdraftLimit
+}
+
+// checkAvailableFunds is the single debit rule shared by transfers,
+// exchanges and payout holds. Callers must hold the account row lock.
+func checkAvailableFunds(balance, locked, overdraftLimit, amount int64) error {
+	if availableFunds(balance, locked, overdraftLimit) < amount {
+		return models.ErrInsufficientFunds
+	}
+	return nil
+}
+
+// postEntries writes the debit and credit entries of a two-legged
+// transaction and applies them to both balances. Callers must hold both
+// account row locks.
+func postEntries(ctx context.Context, qtx *repository.Queries, transactionID, debitAccountID, creditAccountID uuid.UUID, amount int64) error {
+	legs := []struct {
+		accountID uuid.UUID
+		direction string
+		delta     int64
+	}{
+		{debitAccountID, domain.DirectionDebit, -amount},
+		{creditAccountID, domain.DirectionCredit, amount},
+	}
+	for _, leg := range legs {
+		if _, err := qtx.CreateEntry(ctx, repository.CreateEntryParams{
+			ID:            repository.ToPgUUID(uuid.New()),
+			TransactionID: repository.ToPgUUID(transactionID),
+			AccountID:     repository.ToPgUUID(leg.accountID),
+			Amount:        amount,
+			Direction:     leg.direction,
+		}); err != nil {
+			return fmt.Errorf("failed to create %s entry: %w", leg.direction, err)
+		}
+		rows, err := qtx.UpdateAccountBalance(ctx, repository.UpdateAccountBalanceParams{
+			Balance: leg.delta,
+			ID:      repository.ToPgUUID(leg.accountID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update balance of account %s: %w", leg.accountID, err)
+		}
+		if err := requireExactlyOne(rows, "apply "+leg.direction+" to account"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// Sources of a KYC change, recorded in its audit metadata and event.
+const (
+	kycSourceAdmin    = "admin"
+	kycSourceProvider = "provider"
+)
+
+var (
+	// ErrKYCCurrencyNotAllowed indicates an account currency the user's tier does not allow.
+	ErrKYCCurrencyNotAllowed = errors.New("currency not allowed for KYC tier")
+	// ErrKYCPayoutsNotAllowed indicates a payout from a user whose tier does not allow payouts.
+	ErrKYCPayoutsNotAllowed = errors.New("payouts not allowed for KYC tier")
+	// ErrKYCBalanceLimit indicates a credit that would take an account past its tier's maximum balance.
+	ErrKYCBalanceLimit = errors.New("balance would exceed KYC tier maximum")
+	// ErrInvalidKYC indicates an unknown status or tier, or a tier the status cannot hold.
+	ErrInvalidKYC = errors.New("invalid KYC update")
+	// ErrUserNotFound indicates the user does not exist.
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// KYCTier is what users at one verification tier may do.
+type KYCTier struct {
+	Tier              int16    `json:"tier"`
+	Name              string   `json:"name"`
+	AllowedCurrencies []string `json:"allowed_currencies"`
+	PayoutsAllowed    bool     `json:"payouts_allowed"`
+	// MaxBalanceMicros caps each account's balance; nil means no cap.
+	MaxBalanceMicros *int64 `json:"max_balance_micros"`
+}
+
+// UserKYC is a user's verification status and the rules of their tier.
+type UserKYC struct {
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	ProviderRef string     `json:"provider_ref,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Tier        KYCTier    `json:"tier"`
+}
+
+// KYCUpdate sets a user's status and tier.
+type KYCUpdate struct {
+	Status      string `json:"status"`
+	Tier        int16  `json:"tier"`
+	ProviderRef string `json:"provider_ref"`
+	Note        string `json:"note"`
+}
+
+// KYCProviderEvent is the body of a verification provider's webhook.
+type KYCProviderEvent struct {
+	EventID     string    `json:"event_id"`
+	UserID      string    `json:"user_id"`
+	Status      string    `json:"status"`
+	Tier        int16     `json:"tier"`
+	ProviderRef string    `json:"provider_ref"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// KYCService keeps each user's verification status and tier and enforces
+// the tier's rules on account opening, payouts and credits. A nil
+// *KYCService enforces nothing.
+type KYCService struct {
+	store         QueryStore
+	audit         *AuditService
+	webhookSecret []byte
+	now           func() time.Time
+}
+
+// NewKYCService creates a new KYCService instance. Provider webhooks are
+// refused until WithWebhookSecret is called.
+func NewKYCService(store QueryStore) *KYCService {
+	return &KYCService{
+		store: store,
+		audit: NewAuditService(store),
+		now:   time.Now,
+	}
+}
+
+// WithWebhookSecret sets the key provider webhooks are signed with.
+func (s *KYCService) WithWebhookSecret(secret string) *KYCService {
+	s.webhookSecret = []byte(secret)
+	return s
+}
+
+// ListTiers returns every tier in ascending order.
+func (s *KYCService) ListTiers(ctx context.Context) ([]KYCTier, error) {
+	rows, err := s.store.Queries().ListKYCTiers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list kyc tiers: %w", err)
+	}
+	tiers := make([]KYCTier, 0, len(rows))
+	for _, row := range rows {
+		tiers = append(tiers, kycTierFromRow(row))
+	}
+	return tiers, nil
+}
+
+// GetUserKYC returns a user's status and tier rules.
+func (s *KYCService) GetUserKYC(ctx context.Context, userID uuid.UUID) (*UserKYC, error) {
+	row, err := s.store.Queries().GetUserKYC(ctx, repository.ToPgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get user kyc: %w", err)
+	}
+	kyc := &UserKYC{
+		UserID: userID,
+		Status: row.KycStatus,
+		Tier: KYCTier{
+			Tier:              row.KycTier,
+			Name:              row.TierName,
+			AllowedCurrencies: row.AllowedCurrencies,
+			PayoutsAllowed:    row.PayoutsAllowed,
+			MaxBalanceMicros:  row.MaxBalanceMicros,
+		},
+		ProviderRef: derefString(row.KycProviderRef),
+	}
+	if row.KycUpdatedAt.Valid {
+		updatedAt := row.KycUpdatedAt.Time
+		kyc.UpdatedAt = &updatedAt
+	}
+	return kyc, nil
+}
+
+// UpdateUserKYC sets a user's status and tier on an admin's decision.
+// Accounts the user already holds are kept after a downgrade; the new tier
+// applies to what they do next.
+func (s *KYCService) UpdateUserKYC(ctx context.Context, userID uuid.UUID, update KYCUpdate, actorID uuid.UUID) (*UserKYC, error) {
+	details := map[string]any{"source": kycSourceAdmin}
+	if note := strings.TrimSpace(update.Note); note != "" {
+		details["note"] = note
+	}
+	if _, err := s.apply(ctx, userID, update, &actorID, s.now().UTC(), "", details); err != nil {
+		return nil, err
+	}
+	return s.GetUserKYC(ctx, userID)
+}
+
+// HandleProviderEvent applies a signed webhook from the verification
+// provider. An event whose event_id was already handled, or that occurred
+// before the user's last KYC change, is acknowledged but ignored, so
+// replays, retries and out-of-order delivery cannot roll a decision back.
+// It reports whether the event changed anything.
+func (s *KYCService) HandleProviderEvent(ctx context.Context, payload []byte, signature string) (*UserKYC, bool, error) {
+	if len(s.webhookSecret) == 0 || !validSignature(s.webhookSecret, payload, signature) {
+		return nil, false, ErrInvalidSignature
+	}
+	var event KYCProviderEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, false, fmt.Errorf("%w: invalid payload: %v", ErrInvalidWebhookPayload, err)
+	}
+	userID, err := uuid.Parse(strings.TrimSpace(event.UserID))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: invalid user_id", ErrInvalidWebhookPayload)
+	}
+	if strings.TrimSpace(event.EventID) == "" {
+		return nil, false, fmt.Errorf("%w: event_id is required", ErrInvalidWebhookPayload)
+	}
+	if event.OccurredAt.IsZero() {
+		return nil, false, fmt.Errorf("%w: occurred_at is required", ErrInvalidWebhookPayload)
+	}
+
+	eventID := strings.TrimSpace(event.EventID)
+	details := map[string]any{
+		"source":   kycSourceProvider,
+		"event_id": eventID,
+	}
+	update := KYCUpdate{Status: event.Status, Tier: event.Tier, ProviderRef: event.ProviderRef}
+	applied, err := s.apply(ctx, userID, update, nil, event.OccurredAt.UTC(), eventID, details)
+	if err != nil {
+		return nil, false, err
+	}
+	if !applied {
+		zap.L().Info("duplicate or stale kyc provider event ignored", zap.String("event_id", eventID), zap.String("user_id", userID.String()))
+	}
+	kyc, err := s.GetUserKYC(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return kyc, applied, nil
+}
+
+// apply writes a KYC change effective at at. With a providerEventID, the
+// event is recorded in the same transaction, and an event seen before or a
+// change not newer than the user's last one is ignored and apply returns
+// false.
+func (s *KYCService) apply(ctx context.Context, userID uuid.UUID, update KYCUpdate, actorID *uuid.UUID, at time.Time, providerEventID string, details map[string]any) (bool, error) {
+	update.Status = strings.ToUpper(strings.TrimSpace(update.Status))
+	update.ProviderRef = strings.TrimSpace(update.ProviderRef)
+	switch update.Status {
+	case domain.KYCStatusNone, domain.KYCStatusPending, domain.KYCStatusVerified, domain.KYCStatusRejected:
+	default:
+		return false, fmt.Errorf("%w: unknown status %q", ErrInvalidKYC, update.Status)
+	}
+	if update.Tier != domain.KYCTierUnverified && update.Status != domain.KYCStatusVerified {
+		return false, fmt.Errorf("%w: tier %d requires status %s", ErrInvalidKYC, update.Tier, domain.KYCStatusVerified)
+	}
+	if userID.String() == domain.SystemUserID {
+		return false, fmt.Errorf("%w: the system user has no KYC", ErrInvalidKYC)
+	}
+
+	applied := false
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		current, err := qtx.LockUserKYC(ctx, repository.ToPgUUID(userID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+		if providerEventID != "" {
+			recorded, err := qtx.RecordKYCProviderEvent(ctx, repository.RecordKYCProviderEventParams{
+				EventID:    providerEventID,
+				UserID:     repository.ToPgUUID(userID),
+				OccurredAt: pgtype.Timestamptz{Time: at, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record kyc provider event: %w", err)
+			}
+			if recorded == 0 {
+				return nil
+			}
+			if current.KycUpdatedAt.Valid && !at.After(current.KycUpdatedAt.Time) {
+				return nil
+			}
+		}
+		if _, err := qtx.GetKYCTier(ctx, update.Tier); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%w: unknown tier %d", ErrInvalidKYC, update.Tier)
+			}
+			return fmt.Errorf("failed to fetch kyc tier: %w", err)
+		}
+
+		providerRef := current.KycProviderRef
+		if update.ProviderRef != "" {
+			providerRef = &update.ProviderRef
+		}
+		if err := qtx.UpdateUserKYC(ctx, repository.UpdateUserKYCParams{
+			KycStatus:      update.Status,
+			KycTier:        update.Tier,
+			KycProviderRef: providerRef,
+			KycUpdatedAt:   pgtype.Timestamptz{Time: at, Valid: true},
+			ID:             repository.ToPgUUID(userID),
+		}); err != nil {
+			return fmt.Errorf("failed to update user kyc: %w", err)
+		}
+		applied = true
+		if current.KycStatus == update.Status && current.KycTier == update.Tier {
+			return nil
+		}
+
+		details["user_id"] = userID
+		details["status"] = update.Status
+		details["tier"] = update.Tier
+		details["prev_status"] = current.KycStatus
+		details["prev_tier"] = current.KycTier
+		if providerRef != nil {
+			details["provider_ref"] = *providerRef
+		}
+		metadata, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		prev := formatKYC(current.KycStatus, current.KycTier)
+		next := formatKYC(update.Status, update.Tier)
+		if err := s.audit.Write(ctx, qtx, "user", userID, actorID, "kyc_updated", prev, next, metadata); err != nil {
+			return err
+		}
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateUser, userID, outbox.EventUserKYCUpdated, details)
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
+
+// CheckCurrency returns ErrKYCCurrencyNotAllowed when the user's tier may
+// not hold accounts in currency. Unknown users and unsupported currencies
+// are left to the caller.
+func (s *KYCService) CheckCurrency(ctx context.Context, userID uuid.UUID, currency string) error {
+	if s == nil || userID.String() == domain.SystemUserID || !isValidCurrency(currency) {
+		return nil
+	}
+	row, err := s.store.Queries().GetUserKYC(ctx, repository.ToPgUUID(userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch user kyc: %w", err)
+	}
+	if !slices.Contains(row.AllowedCurrencies, currency) {
+		return fmt.Errorf("%w: tier %s allows %s", ErrKYCCurrencyNotAllowed, row.TierName, strings.Join(row.AllowedCurrencies, ", "))
+	}
+	return nil
+}
+
+// checkPayout returns ErrKYCPayoutsNotAllowed when the owner of accountID
+// may not make payouts. It runs inside the payout's transaction.
+func (s *KYCService) checkPayout(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID) error {
+	if s == nil || isSystemAccount(accountID) {
+		return nil
+	}
+	row, err := qtx.GetAccountKYCRules(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		return fmt.Errorf("failed to fetch account kyc rules: %w", err)
+	}
+	if !row.PayoutsAllowed {
+		return fmt.Errorf("%w: tier %d", ErrKYCPayoutsNotAllowed, row.KycTier)
+	}
+	return nil
+}
+
+// checkCredit returns ErrKYCBalanceLimit when crediting amount would take
+// accountID past its owner's maximum balance. It must run after the account
+// is locked so the balance it reads cannot move.
+func (s *KYCService) checkCredit(ctx context.Context, qtx *repository.Queries, accountID uuid.UUID, amount int64) error {
+	if s == nil || isSystemAccount(accountID) {
+		return nil
+	}
+	row, err := qtx.GetAccountKYCRules(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		return fmt.Errorf("failed to fetch account kyc rules: %w", err)
+	}
+	if row.MaxBalanceMicros != nil && row.Balance+amount > *row.MaxBalanceMicros {
+		return fmt.Errorf("%w: tier %d allows %d micros, balance would be %d", ErrKYCBalanceLimit, row.KycTier, *row.MaxBalanceMicros, row.Balance+amount)
+	}
+	return nil
+}
+
+func kycTierFromRow(row repository.KycTier) KYCTier {
+	return KYCTier{
+		Tier:              row.Tier,
+		Name:              row.Name,
+		AllowedCurrencies: row.AllowedCurrencies,
+		PayoutsAllowed:    row.PayoutsAllowed,
+		MaxBalanceMicros:  row.MaxBalanceMicros,
+	}
+}
+
+func formatKYC(status string, tier int16) string {
+	return fmt.Sprintf("status=%s tier=%d", status, tier)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKYCUpdateValidation(t *testing.T) {
+	svc := NewKYCService(panicStore{})
+	ctx := context.Background()
+
+	_, err := svc.UpdateUserKYC(ctx, uuid.New(), KYCUpdate{Status: "APPROVED"}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidKYC)
+	_, err = svc.UpdateUserKYC(ctx, uuid.New(), KYCUpdate{Status: domain.KYCStatusPending, Tier: 1}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidKYC)
+	_, err = svc.UpdateUserKYC(ctx, uuid.MustParse(domain.SystemUserID), KYCUpdate{Status: domain.KYCStatusVerified, Tier: 2}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidKYC)
+
+	_, _, err = svc.HandleProviderEvent(ctx, []byte(`{}`), "sha256=00")
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	var unset *KYCService
+	require.NoError(t, unset.CheckCurrency(ctx, uuid.New(), "GBP"))
+}
+
+func TestKYCTierRules(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	kyc := NewKYCService(store)
+	accounts := NewAccountService(repo).WithKYC(kyc)
+	transfers := NewTransferService(store, NewMockExchangeRateService()).WithKYC(kyc)
+	payouts := NewPayoutService(store, gateway.NewMockGateway()).WithKYC(kyc)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	require.Equal(t, domain.KYCStatusNone, user.KYCStatus)
+
+	_, err := accounts.CreateAccount(ctx, user.ID, "GBP")
+	require.ErrorIs(t, err, ErrKYCCurrencyNotAllowed)
+	account, err := accounts.CreateAccount(ctx, user.ID, "USD")
+	require.NoError(t, err)
+
+	// The unverified tier caps balances at 1,000 USD.
+	funder := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "USD", Balance: 2_000_000_000}
+	require.NoError(t, repo.CreateAccount(ctx, funder))
+	_, err = transfers.Transfer(ctx, funder.ID, account.ID, 1_000_000_001, "kyc-over-cap")
+	require.ErrorIs(t, err, ErrKYCBalanceLimit)
+	_, err = transfers.Transfer(ctx, funder.ID, account.ID, 1_000_000_000, "kyc-at-cap")
+	require.NoError(t, err)
+
+	destination := PayoutDestinationInput{Name: "Ayo", IBAN: "GB29NWBK60161331926819"}
+	request := RequestPayoutRequest{AccountID: account.ID, AmountMicros: 100, Currency: "USD", Destination: destination, ReferenceID: "kyc-payout"}
+	_, err = payouts.RequestPayout(ctx, request)
+	require.ErrorIs(t, err, ErrKYCPayoutsNotAllowed)
+
+	updated, err := kyc.UpdateUserKYC(ctx, user.ID, KYCUpdate{Status: domain.KYCStatusVerified, Tier: 1, Note: "passport checked"}, admin.ID)
+	require.NoError(t, err)
+	require.Equal(t, "BASIC", updated.Tier.Name)
+	require.True(t, updated.Tier.PayoutsAllowed)
+
+	_, err = payouts.RequestPayout(ctx, request)
+	require.NoError(t, err)
+	_, err = accounts.CreateAccount(ctx, user.ID, "GBP")
+	require.NoError(t, err)
+
+	events, err := repository.New(db).GetAuditLogsByEntity(ctx, repository.GetAuditLogsByEntityParams{EntityType: "user", EntityID: repository.ToPgUUID(user.ID)})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "status=NONE tier=0", derefString(events[0].PrevState))
+	require.Equal(t, "status=VERIFIED tier=1", derefString(events[0].NextState))
+}
+
+func TestKYCProviderEventReplayIsIgnored(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	kyc := NewKYCService(repository.NewStore(db)).WithWebhookSecret("kyc-secret")
+	ctx := context.Background()
+
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	deliver := func(eventID, status string, tier int16, occurredAt time.Time) bool {
+		body, err := json.Marshal(map[string]any{"event_id": eventID, "user_id": user.ID, "status": status, "tier": tier, "occurred_at": occurredAt})
+		require.NoError(t, err)
+		_, applied, err := kyc.HandleProviderEvent(ctx, body, signPayload("kyc-secret", body))
+		require.NoError(t, err)
+		return applied
+	}
+
+	verifiedAt := time.Now().Add(-time.Hour).UTC()
+	require.True(t, deliver("evt-verify", domain.KYCStatusVerified, 1, verifiedAt))
+	require.True(t, deliver("evt-reject", domain.KYCStatusRejected, 0, verifiedAt.Add(time.Minute)))
+
+	// Replays are ignored by event_id, even when re-stamped as newer.
+	require.False(t, deliver("evt-verify", domain.KYCStatusVerified, 1, verifiedAt))
+	require.False(t, deliver("evt-verify", domain.KYCStatusVerified, 1, time.Now().UTC()))
+
+	current, err := kyc.GetUserKYC(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, domain.KYCStatusRejected, current.Status)
+
+	var recorded int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM kyc_provider_events WHERE user_id = $1", repository.ToPgUUID(user.ID)).Scan(&recorded))
+	require.Equal(t, 2, recorded)
+}
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// errLedgerCheckpointMoved is returned when the checkpoint was advanced by
+// someone else between reading and updating it.
+var errLedgerCheckpointMoved = errors.New("ledger checkpoint moved concurrently")
+
+// entryRange is a half-open [start, end) range of entry creation times.
+type entryRange struct {
+	start pgtype.Timestamptz
+	end   pgtype.Timestamptz
+}
+
+// checkLedgerIncremental performs the checks of checkLedgerNet and
+// checkLedgerInvariants without scanning every partition of entries. Closed
+// UTC days are folded into per-day and per-account totals once; each run
+// then recomputes the days that received back-dated entries, folds in the
+// days that closed since the last run, and scans only the entries created
+// after the checkpoint. Closed months are sealed with a checksum, and one
+// sealed month is re-verified per run.
+func (s *ReconciliationService) checkLedgerIncremental(ctx context.Context, now time.Time) ([]models.CurrencyTotal, []models.LedgerFinding, error) {
+	checkpoint, err := s.ensureCheckpoint(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	startedFrom := checkpoint.StartedFrom.Time
+	previous := checkpoint.CheckpointedUntil.Time
+
+	dirtyDays, err := s.refreshDirtyDays(ctx, previous)
+	if err != nil {
+		return nil, nil, err
+	}
+	until, err := s.advanceCheckpoint(ctx, previous, utcDay(now.Add(-s.checkpointLag)))
+	if err != nil {
+		return nil, nil, err
+	}
+	findings, err := s.checkMonthSeals(ctx, startedFrom, until, dirtyDays)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totals, err := s.incrementalLedgerTotals(ctx, until)
+	if err != nil {
+		return nil, nil, err
+	}
+	reportLedgerTotals(totals)
+
+	// Transactions are re-checked when they have entries the checkpoint has
+	// not seen before: everything since the previous checkpoint and every
+	// recomputed day.
+	ranges := []entryRange{{
+		start: dateTimestamp(previous),
+		end:   pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true},
+	}}
+	for _, day := range dirtyDays {
+		ranges = append(ranges, entryRange{start: dateTimestamp(day), end: dateTimestamp(day.AddDate(0, 0, 1))})
+	}
+	invariants, err := s.incrementalLedgerInvariants(ctx, until, ranges)
+	if err != nil {
+		return totals, nil, err
+	}
+	findings = append(findings, invariants...)
+
+	err = s.recordFindings(ctx, now, findings, func(qtx *repository.Queries, seenAt pgtype.Timestamptz) (int64, error) {
+		resolved, err := qtx.ResolveReconciliationFindings(ctx, repository.ResolveReconciliationFindingsParams{
+			FindingTypes: []string{domain.FindingTypeAccountBalanceDrift, domain.FindingTypeLockedFundsDrift},
+			SeenBefore:   seenAt,
+		})
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range ranges {
+			n, err := qtx.ResolveTransactionFindingsInRange(ctx, repository.ResolveTransactionFindingsInRangeParams{
+				SeenBefore: seenAt,
+				RangeStart: r.start,
+				RangeEnd:   r.end,
+			})
+			if err != nil {
+				return 0, err
+			}
+			resolved += n
+		}
+		return resolved, nil
+	})
+	if err != nil {
+		return totals, nil, err
+	}
+	return totals, findings, nil
+}
+
+// ensureCheckpoint returns the ledger checkpoint, creating it at the day of
+// the earliest entry on the first incremental run.
+func (s *ReconciliationService) ensureCheckpoint(ctx context.Context, now time.Time) (repository.LedgerCheckpoint, error) {
+	queries := s.store.Queries()
+	checkpoint, err := queries.GetLedgerCheckpoint(ctx)
+	if err == nil {
+		return checkpoint, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return repository.LedgerCheckpoint{}, fmt.Errorf("get ledger checkpoint: %w", err)
+	}
+
+	earliest, err := queries.GetEarliestEntryTime(ctx)
+	if err != nil {
+		return repository.LedgerCheckpoint{}, fmt.Errorf("get earliest entry time: %w", err)
+	}
+	start := utcDay(now)
+	if earliest.Valid && earliest.Time.Before(start) {
+		start = utcDay(earliest.Time)
+	}
+	if err := queries.InitLedgerCheckpoint(ctx, pgtype.Date{Time: start, Valid: true}); err != nil {
+		return repository.LedgerCheckpoint{}, fmt.Errorf("init ledger checkpoint: %w", err)
+	}
+	zap.L().Info("ledger checkpoint created", zap.Time("started_from", start))
+	checkpoint, err = queries.GetLedgerCheckpoint(ctx)
+	if err != nil {
+		return repository.LedgerCheckpoint{}, fmt.Errorf("get ledger checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// refreshDirtyDays recomputes the totals of checkpointed days that received
+// entries after they were folded in, and returns those days.
+func (s *ReconciliationService) refreshDirtyDays(ctx context.Context, checkpointedUntil time.Time) ([]time.Time, error) {
+	rows, err := s.store.Queries().ListLedgerDirtyDays(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list ledger dirty days: %w", err)
+	}
+
+	days := make([]time.Time, 0, len(rows))
+	for _, row := range rows {
+		day := row.Time
+		archived, err := s.monthArchived(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		if archived {
+			// The day's totals include entries now in cold storage and cannot
+			// be recomputed. The late entries surface as account drift.
+			if err := s.store.Queries().DeleteLedgerDirtyDay(ctx, row); err != nil {
+				return nil, fmt.Errorf("clear ledger dirty day: %w", err)
+			}
+			zap.L().Error("late entries in an archived month", zap.String("day", day.Format(time.DateOnly)))
+			continue
+		}
+		err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+			// Cleared first: an entry committed after this transaction marks
+			// the day dirty again.
+			if err := qtx.DeleteLedgerDirtyDay(ctx, row); err != nil {
+				return fmt.Errorf("clear ledger dirty day: %w", err)
+			}
+			if err := s.foldDay(ctx, qtx, day); err != nil {
+				return err
+			}
+			return qtx.RebuildLedgerAccountTotals(ctx, repository.RebuildLedgerAccountTotalsParams{
+				CheckpointEnd: dateTimestamp(checkpointedUntil),
+				DayStart:      dateTimestamp(day),
+				DayEnd:        dateTimestamp(day.AddDate(0, 0, 1)),
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("refresh ledger day %s: %w", day.Format(time.DateOnly), err)
+		}
+		zap.L().Warn("ledger day recomputed after late entries", zap.String("day", day.Format(time.DateOnly)))
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// monthArchived reports whether the month containing day was archived.
+func (s *ReconciliationService) monthArchived(ctx context.Context, day time.Time) (bool, error) {
+	seal, err := s.store.Queries().GetLedgerMonthSeal(ctx, pgtype.Date{Time: utcMonth(day), Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("get ledger month seal: %w", err)
+	}
+	return seal.Status == domain.LedgerSealStatusArchived, nil
+}
+
+// advanceCheckpoint folds every day from the checkpoint up to (excluding)
+// cutoff into the totals, one transaction per day, and returns the new
+// checkpoint.
+func (s *ReconciliationService) advanceCheckpoint(ctx context.Context, from, cutoff time.Time) (time.Time, error) {
+	day := from
+	for day.Before(cutoff) {
+		next := day.AddDate(0, 0, 1)
+		err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+			if err := s.foldDay(ctx, qtx, day); err != nil {
+				return err
+			}
+			if err := qtx.AddLedgerAccountTotals(ctx, repository.AddLedgerAccountTotalsParams{
+				DayStart: dateTimestamp(day),
+				DayEnd:   dateTimestamp(next),
+			}); err != nil {
+				return fmt.Errorf("add ledger account totals: %w", err)
+			}
+			moved, err := qtx.AdvanceLedgerCheckpoint(ctx, repository.AdvanceLedgerCheckpointParams{
+				NextDay: pgtype.Date{Time: next, Valid: true},
+				Day:     pgtype.Date{Time: day, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("advance ledger checkpoint: %w", err)
+			}
+			if moved == 0 {
+				return errLedgerCheckpointMoved
+			}
+			return nil
+		})
+		if err != nil {
+			return day, fmt.Errorf("checkpoint ledger day %s: %w", day.Format(time.DateOnly), err)
+		}
+		day = next
+	}
+	return day, nil
+}
+
+// foldDay replaces the per-currency totals stored for day.
+func (s *ReconciliationService) foldDay(ctx context.Context, qtx *repository.Queries, day time.Time) error {
+	if err := qtx.DeleteLedgerDayTotals(ctx, pgtype.Date{Time: day, Valid: true}); err != nil {
+		return fmt.Errorf("delete ledger day totals: %w", err)
+	}
+	if err := qtx.InsertLedgerDayTotals(ctx, repository.InsertLedgerDayTotalsParams{
+		Day:      pgtype.Date{Time: day, Valid: true},
+		DayStart: dateTimestamp(day),
+		DayEnd:   dateTimestamp(day.AddDate(0, 0, 1)),
+	}); err != nil {
+		return fmt.Errorf("insert ledger day totals: %w", err)
+	}
+	return nil
+}
+
+// checkMonthSeals seals every fully checkpointed month that has no seal
+// yet, then re-verifies the sealed months that had late entries and the
+// seal verified longest ago. A month whose entries no longer match its seal
+// is reported as SEALED_PERIOD_CHANGED.
+func (s *ReconciliationService) checkMonthSeals(ctx context.Context, startedFrom, checkpointedUntil time.Time, dirtyDays []time.Time) ([]models.LedgerFinding, error) {
+	queries := s.store.Queries()
+	rows, err := queries.ListLedgerMonthSeals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list ledger month seals: %w", err)
+	}
+	sealed := make(map[time.Time]bool, len(rows))
+	for _, row := range rows {
+		sealed[row.Time] = true
+	}
+
+	verify := make(map[time.Time]bool)
+	for _, day := range dirtyDays {
+		if month := utcMonth(day); sealed[month] {
+			verify[month] = true
+		}
+	}
+	oldest, err := queries.GetLeastRecentlyVerifiedMonthSeal(ctx)
+	switch {
+	case err == nil:
+		verify[oldest.Month.Time] = true
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("get least recently verified month seal: %w", err)
+	}
+
+	for month := utcMonth(startedFrom); !month.AddDate(0, 1, 0).After(checkpointedUntil); month = month.AddDate(0, 1, 0) {
+		if sealed[month] {
+			continue
+		}
+		if err := s.sealMonth(ctx, month); err != nil {
+			return nil, err
+		}
+	}
+
+	months := make([]time.Time, 0, len(verify))
+	for month := range verify {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+
+	var findings []models.LedgerFinding
+	for _, month := range months {
+		finding, err := s.verifyMonthSeal(ctx, month)
+		if err != nil {
+			return nil, err
+		}
+		if finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	return findings, nil
+}
+
+func (s *ReconciliationService) sealMonth(ctx context.Context, month time.Time) error {
+	sum, err := s.store.Queries().GetLedgerPeriodChecksum(ctx, repository.GetLedgerPeriodChecksumParams{
+		PeriodStart: dateTimestamp(month),
+		PeriodEnd:   dateTimestamp(month.AddDate(0, 1, 0)),
+	})
+	if err != nil {
+		return fmt.Errorf("checksum ledger month %s: %w", month.Format("2006-01"), err)
+	}
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		if err := qtx.CreateLedgerMonthSeal(ctx, repository.CreateLedgerMonthSealParams{
+			Month:      pgtype.Date{Time: month, Valid: true},
+			EntryCount: sum.EntryCount,
+			Checksum:   sum.Checksum,
+		}); err != nil {
+			return err
+		}
+		// A new seal is a new baseline for the month.
+		_, err := qtx.ResolveLedgerPeriodFindings(ctx, month.Format("2006-01"))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("seal ledger month %s: %w", month.Format("2006-01"), err)
+	}
+	zap.L().Info("ledger month sealed",
+		zap.String("month", month.Format("2006-01")),
+		zap.Int64("entry_count", sum.EntryCount),
+		zap.String("checksum", sum.Checksum),
+	)
+	return nil
+}
+
+// verifyMonthSeal recomputes the checksum of a sealed month and returns a
+// finding when it no longer matches.
+func (s *ReconciliationService) verifyMonthSeal(ctx context.Context, month time.Time) (*models.LedgerFinding, error) {
+	queries := s.store.Queries()
+	period := month.Format("2006-01")
+	seal, err := queries.GetLedgerMonthSeal(ctx, pgtype.Date{Time: month, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("get ledger month seal %s: %w", period, err)
+	}
+	if seal.Status == domain.LedgerSealStatusArchived {
+		// The month's entries are in cold storage; its manifest carries the
+		// checksum.
+		return nil, nil
+	}
+	sum, err := queries.GetLedgerPeriodChecksum(ctx, repository.GetLedgerPeriodChecksumParams{
+		PeriodStart: dateTimestamp(month),
+		PeriodEnd:   dateTimestamp(month.AddDate(0, 1, 0)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("checksum ledger month %s: %w", period, err)
+	}
+
+	status := domain.LedgerSealStatusSealed
+	if sum.EntryCount != seal.EntryCount || sum.Checksum != seal.Checksum {
+		status = domain.LedgerSealStatusBroken
+	}
+	if err := queries.MarkLedgerMonthSealVerified(ctx, repository.MarkLedgerMonthSealVerifiedParams{
+		Month:  seal.Month,
+		Status: status,
+	}); err != nil {
+		return nil, fmt.Errorf("mark ledger month seal %s verified: %w", period, err)
+	}
+	if status == domain.LedgerSealStatusSealed {
+		if _, err := queries.ResolveLedgerPeriodFindings(ctx, period); err != nil {
+			return nil, fmt.Errorf("resolve ledger period findings: %w", err)
+		}
+		return nil, nil
+	}
+	return &models.LedgerFinding{
+		Type:           domain.FindingTypeSealedPeriodChanged,
+		SubjectID:      uuid.Nil,
+		Currency:       "ALL",
+		Period:         period,
+		ExpectedMicros: seal.EntryCount,
+		ActualMicros:   sum.EntryCount,
+	}, nil
+}
+
+// incrementalLedgerTotals adds the checkpointed per-day totals to the totals
+// of the entries created since the checkpoint.
+func (s *ReconciliationService) incrementalLedgerTotals(ctx context.Context, checkpointedUntil time.Time) ([]models.CurrencyTotal, error) {
+	queries := s.store.Queries()
+	checkpointed, err := queries.GetCheckpointedCurrencyTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("run checkpointed totals query: %w", err)
+	}
+	live, err := queries.GetLedgerCurrencyTotalsSince(ctx, dateTimestamp(checkpointedUntil))
+	if err != nil {
+		return nil, fmt.Errorf("run ledger totals query: %w", err)
+	}
+
+	byCurrency := make(map[string]*models.CurrencyTotal)
+	add := func(currency string, count, debit, credit int64) {
+		total, ok := byCurrency[currency]
+		if !ok {
+			total = &models.CurrencyTotal{Currency: currency}
+			byCurrency[currency] = total
+		}
+		total.EntryCount += count
+		total.DebitMicros += debit
+		total.CreditMicros += credit
+		total.NetMicros = total.CreditMicros - total.DebitMicros
+	}
+	for _, row := range checkpointed {
+		add(row.Currency, row.EntryCount, row.DebitMicros, row.CreditMicros)
+	}
+	for _, row := range live {
+		add(row.Currency, row.EntryCount, row.DebitMicros, row.CreditMicros)
+	}
+
+	totals := make([]models.CurrencyTotal, 0, len(byCurrency))
+	for _, total := range byCurrency {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Currency < totals[j].Currency })
+	return totals, nil
+}
+
+// incrementalLedgerInvariants checks account balances against the
+// checkpointed account totals plus entries since the checkpoint, locked
+// funds against open payouts, and the transactions with entries in ranges.
+func (s *ReconciliationService) incrementalLedgerInvariants(ctx context.Context, checkpointedUntil time.Time, ranges []entryRange) ([]models.LedgerFinding, error) {
+	queries := s.store.Queries()
+	var findings []models.LedgerFinding
+	add := func(findingType string, subjectID pgtype.UUID, currency string, expected, actual int64) {
+		findings = append(findings, models.LedgerFinding{
+			Type:           findingType,
+			SubjectID:      repository.FromPgUUID(subjectID),
+			Currency:       currency,
+			ExpectedMicros: expected,
+			ActualMicros:   actual,
+		})
+	}
+
+	balances, err := queries.ListAccountBalanceDriftsSince(ctx, dateTimestamp(checkpointedUntil))
+	if err != nil {
+		return nil, fmt.Errorf("list account balance drifts: %w", err)
+	}
+	for _, row := range balances {
+		add(domain.FindingTypeAccountBalanceDrift, row.ID, row.Currency, row.EntriesNet, row.Balance)
+	}
+
+	locked, err := queries.ListLockedFundsDrifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list locked funds drifts: %w", err)
+	}
+	for _, row := range locked {
+		add(domain.FindingTypeLockedFundsDrift, row.ID, row.Currency, row.OpenPayoutMicros, row.LockedMicros)
+	}
+
+	type txKey struct {
+		id       pgtype.UUID
+		currency string
+	}
+	seen := make(map[txKey]bool)
+	for _, r := range ranges {
+		unbalanced, err := queries.ListUnbalancedTransactionsInRange(ctx, repository.ListUnbalancedTransactionsInRangeParams{
+			RangeStart: r.start,
+			RangeEnd:   r.end,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list unbalanced transactions: %w", err)
+		}
+		for _, row := range unbalanced {
+			key := txKey{id: row.TransactionID, currency: row.Currency}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			add(domain.FindingTypeUnbalancedTx, row.TransactionID, row.Currency, 0, row.NetAmount)
+		}
+	}
+	return findings, nil
+}
+
+// utcDay truncates t to midnight UTC.
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// utcMonth truncates t to the first day of its month, UTC.
+func utcMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// dateTimestamp returns midnight UTC of day as a timestamptz parameter.
+func dateTimestamp(day time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: utcDay(day), Valid: true}
+}
@@ -0,0 +1,686 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Limit windows reported in LimitExceededError.
+const (
+	LimitWindowTransaction = "transaction"
+	LimitWindowDay         = "day"
+	LimitWindowMonth       = "month"
+)
+
+const (
+	limitRulesCacheKeyPrefix = "limits:rules"
+	// limitRulesGenerationKey is bumped on every limit change, moving
+	// lookups to fresh cache keys.
+	limitRulesGenerationKey = "limits:generation"
+	limitRulesCacheTTL      = 30 * time.Second
+)
+
+var (
+	// ErrLimitExceeded indicates a movement refused by a transaction limit.
+	// Breaches are returned as *LimitExceededError, which matches it.
+	ErrLimitExceeded = errors.New("transaction limit exceeded")
+	// ErrInvalidLimit indicates an unknown type or currency or a non-positive cap.
+	ErrInvalidLimit = errors.New("invalid transaction limit")
+	// ErrLimitNotFound indicates no default or override exists to remove.
+	ErrLimitNotFound = errors.New("transaction limit not found")
+)
+
+// LimitExceededError describes which cap a movement would breach.
+type LimitExceededError struct {
+	TxnType      string
+	Currency     string
+	Window       string
+	LimitMicros  int64
+	UsedMicros   int64
+	AmountMicros int64
+	// ResetsAt is when the window's usage starts over; zero for the
+	// per-transaction maximum.
+	ResetsAt time.Time
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Window == LimitWindowTransaction {
+		return fmt.Sprintf("%s of %d micros %s exceeds the per-transaction limit of %d", e.TxnType, e.AmountMicros, e.Currency, e.LimitMicros)
+	}
+	period := "daily"
+	if e.Window == LimitWindowMonth {
+		period = "monthly"
+	}
+	return fmt.Sprintf("%s of %d micros %s exceeds the %s limit of %d (%d already used)", e.TxnType, e.AmountMicros, e.Currency, period, e.LimitMicros, e.UsedMicros)
+}
+
+// Is reports whether target is ErrLimitExceeded.
+func (e *LimitExceededError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// Limits are the caps for one transaction type and currency. A nil cap is
+// not enforced.
+type Limits struct {
+	PerTransactionMaxMicros *int64 `json:"per_transaction_max_micros"`
+	DailyMaxMicros          *int64 `json:"daily_max_micros"`
+	MonthlyMaxMicros        *int64 `json:"monthly_max_micros"`
+}
+
+func (l Limits) validate() error {
+	for _, v := range []*int64{l.PerTransactionMaxMicros, l.DailyMaxMicros, l.MonthlyMaxMicros} {
+		if v != nil && *v <= 0 {
+			return fmt.Errorf("%w: caps must be greater than zero", ErrInvalidLimit)
+		}
+	}
+	return nil
+}
+
+func (l Limits) empty() bool {
+	return l.PerTransactionMaxMicros == nil && l.DailyMaxMicros == nil && l.MonthlyMaxMicros == nil
+}
+
+// LimitRule is a default limit or, when UserID is set, a user's override.
+// An override with AccountID set applies to that account only.
+type LimitRule struct {
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	AccountID *uuid.UUID `json:"account_id,omitempty"`
+	TxnType   string     `json:"txn_type"`
+	Currency  string     `json:"currency"`
+	Note      string     `json:"note,omitempty"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Limits
+}
+
+// LimitCheck is one movement to count against a user's limits. AccountID
+// is the account the money leaves, or uuid.Nil when only user-wide limits
+// apply.
+type LimitCheck struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	TxnType   string
+	Currency  string
+	Amount    int64
+}
+
+// LimitService enforces per-transaction, daily and monthly caps per user,
+// account, transaction type and currency. Usage is counted in Postgres
+// (limit_usage) only; Redis, when configured, caches the limits that apply.
+type LimitService struct {
+	store QueryStore
+	redis redis.Cmdable
+	audit *AuditService
+	now   func() time.Time
+}
+
+// NewLimitService creates a new LimitService instance that reads limits from
+// Postgres on every check until WithRedis is called.
+func NewLimitService(store QueryStore) *LimitService {
+	return &LimitService{
+		store: store,
+		audit: NewAuditService(store),
+		now:   time.Now,
+	}
+}
+
+// WithRedis caches the limits that apply to a check for up to 30 seconds.
+// Limit changes move lookups to new keys, and any Redis failure falls back
+// to Postgres. Usage is never counted in Redis, so an outage cannot loosen
+// a cap.
+func (s *LimitService) WithRedis(client redis.Cmdable) *LimitService {
+	s.redis = client
+	return s
+}
+
+// LimitReservation is usage counted for a movement that has not committed
+// yet. Release it when the movement fails so the amount does not count.
+type LimitReservation struct {
+	svc *LimitService
+	// counted is the check as counted: AccountID is uuid.Nil unless an
+	// account override applied.
+	counted LimitCheck
+	windows []reservedWindow
+}
+
+type reservedWindow struct {
+	period string
+	start  time.Time
+	end    time.Time
+}
+
+// ReserveForAccount counts amount against the limits of the user who owns
+// accountID, in the account's currency. Unknown and system-owned accounts
+// are not limited; the movement itself reports a missing account. A nil
+// service reserves nothing.
+func (s *LimitService) ReserveForAccount(ctx context.Context, accountID uuid.UUID, txnType string, amount int64) (*LimitReservation, error) {
+	if s == nil {
+		return nil, nil
+	}
+	account, err := s.store.Queries().GetAccount(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch account for limits: %w", err)
+	}
+	userID := repository.FromPgUUID(account.UserID)
+	if userID.String() == domain.SystemUserID {
+		return nil, nil
+	}
+	return s.Reserve(ctx, LimitCheck{UserID: userID, AccountID: accountID, TxnType: txnType, Currency: account.Currency, Amount: amount})
+}
+
+// Reserve checks the per-transaction maximum, then adds the amount to the
+// daily and monthly counters that have a cap. Usage under an account
+// override is counted for that account alone; otherwise it is counted
+// across the user's accounts. A breach undoes what was added and returns
+// *LimitExceededError. The reservation is nil when no cap applies.
+func (s *LimitService) Reserve(ctx context.Context, check LimitCheck) (*LimitReservation, error) {
+	limits, accountScoped, err := s.effectiveLimits(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	if limits.empty() {
+		return nil, nil
+	}
+	if max := limits.PerTransactionMaxMicros; max != nil && check.Amount > *max {
+		return nil, s.breach(check, LimitWindowTransaction, *max, 0, time.Time{})
+	}
+
+	counted := check
+	if !accountScoped {
+		counted.AccountID = uuid.Nil
+	}
+	now := s.now().UTC()
+	reservation := &LimitReservation{svc: s, counted: counted}
+	for _, w := range []struct {
+		period string
+		max    *int64
+	}{
+		{LimitWindowDay, limits.DailyMaxMicros},
+		{LimitWindowMonth, limits.MonthlyMaxMicros},
+	} {
+		if w.max == nil {
+			continue
+		}
+		start, end := limitWindow(w.period, now)
+		window := reservedWindow{period: w.period, start: start, end: end}
+		total, err := s.addUsage(ctx, counted, window, check.Amount)
+		if err != nil {
+			reservation.Release(ctx)
+			return nil, fmt.Errorf("failed to count limit usage: %w", err)
+		}
+		reservation.windows = append(reservation.windows, window)
+		if total > *w.max {
+			reservation.Release(ctx)
+			return nil, s.breach(check, w.period, *w.max, total-check.Amount, end)
+		}
+	}
+	return reservation, nil
+}
+
+// Release subtracts the reserved amount from every counter it was added to.
+// It is safe on a nil reservation and ignores cancellation of ctx.
+func (r *LimitReservation) Release(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, w := range r.windows {
+		if _, err := r.svc.addUsage(ctx, r.counted, w, -r.counted.Amount); err != nil {
+			zap.L().Warn("release transaction limit usage failed", zap.Error(err),
+				zap.String("user_id", r.counted.UserID.String()), zap.String("window", w.period))
+		}
+	}
+	r.windows = nil
+}
+
+// recordedReservation is a reservation kept on the movement it was made for,
+// so the usage can be given back once the movement is rejected or fails.
+type recordedReservation struct {
+	UserID    uuid.UUID        `json:"user_id"`
+	AccountID uuid.UUID        `json:"account_id"`
+	TxnType   string           `json:"txn_type"`
+	Currency  string           `json:"currency"`
+	Amount    int64            `json:"amount_micros"`
+	Windows   []recordedWindow `json:"windows"`
+}
+
+type recordedWindow struct {
+	Period string    `json:"period"`
+	Start  time.Time `json:"start"`
+}
+
+// Record returns the reservation as JSON for storing with the movement, or
+// nil when nothing was counted.
+func (r *LimitReservation) Record() ([]byte, error) {
+	if r == nil || len(r.windows) == 0 {
+		return nil, nil
+	}
+	record := recordedReservation{
+		UserID:    r.counted.UserID,
+		AccountID: r.counted.AccountID,
+		TxnType:   r.counted.TxnType,
+		Currency:  r.counted.Currency,
+		Amount:    r.counted.Amount,
+	}
+	for _, w := range r.windows {
+		record.Windows = append(record.Windows, recordedWindow{Period: w.period, Start: w.start})
+	}
+	return json.Marshal(record)
+}
+
+// releaseRecordedLimit subtracts a reservation saved by Record from the
+// windows it was counted in, using q so the release commits with the status
+// change that causes it. An empty record releases nothing.
+func releaseRecordedLimit(ctx context.Context, q *repository.Queries, raw []byte) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	var record recordedReservation
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return fmt.Errorf("decode limit reservation: %w", err)
+	}
+	check := LimitCheck{
+		UserID:    record.UserID,
+		AccountID: record.AccountID,
+		TxnType:   record.TxnType,
+		Currency:  record.Currency,
+	}
+	for _, w := range record.Windows {
+		if _, err := addLimitUsage(ctx, q, check, w.Period, w.Start, -record.Amount); err != nil {
+			return fmt.Errorf("release limit usage: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *LimitService) breach(check LimitCheck, window string, limit, used int64, resetsAt time.Time) error {
+	observability.IncrementLimitBreach(check.TxnType, window)
+	return &LimitExceededError{
+		TxnType:      check.TxnType,
+		Currency:     check.Currency,
+		Window:       window,
+		LimitMicros:  limit,
+		UsedMicros:   used,
+		AmountMicros: check.Amount,
+		ResetsAt:     resetsAt,
+	}
+}
+
+// cachedLimits is what effectiveLimits caches in Redis.
+type cachedLimits struct {
+	Limits
+	AccountScoped bool `json:"account_scoped"`
+}
+
+// effectiveLimits returns the limits that apply to check, from the cache when
+// Redis has them, and whether an account override applied.
+func (s *LimitService) effectiveLimits(ctx context.Context, check LimitCheck) (Limits, bool, error) {
+	key := s.limitRulesCacheKey(ctx, check)
+	if key != "" {
+		raw, err := s.redis.Get(ctx, key).Result()
+		var cached cachedLimits
+		if err == nil && json.Unmarshal([]byte(raw), &cached) == nil {
+			return cached.Limits, cached.AccountScoped, nil
+		}
+		if err != nil && !errors.Is(err, redis.Nil) {
+			limitCacheUnavailable(err)
+			key = ""
+		}
+	}
+	limits, accountScoped, err := s.loadLimits(ctx, check)
+	if err != nil {
+		return Limits{}, false, err
+	}
+	if key != "" {
+		raw, err := json.Marshal(cachedLimits{Limits: limits, AccountScoped: accountScoped})
+		if err == nil {
+			err = s.redis.Set(ctx, key, string(raw), limitRulesCacheTTL).Err()
+		}
+		if err != nil {
+			limitCacheUnavailable(err)
+		}
+	}
+	return limits, accountScoped, nil
+}
+
+// limitRulesCacheKey names the cache entry for check under the current
+// generation, or returns "" when there is no usable cache.
+func (s *LimitService) limitRulesCacheKey(ctx context.Context, check LimitCheck) string {
+	if s.redis == nil {
+		return ""
+	}
+	generation, err := s.redis.Get(ctx, limitRulesGenerationKey).Result()
+	if errors.Is(err, redis.Nil) {
+		generation = "0"
+	} else if err != nil {
+		limitCacheUnavailable(err)
+		return ""
+	}
+	owner := check.UserID.String()
+	if check.AccountID != uuid.Nil {
+		owner += ":" + check.AccountID.String()
+	}
+	return fmt.Sprintf("%s:%s:%s:%s:%s", limitRulesCacheKeyPrefix, generation, owner, check.TxnType, check.Currency)
+}
+
+// invalidateLimitRules moves lookups to a new cache generation after a
+// committed limit change. If Redis is unreachable, cached entries still
+// expire within limitRulesCacheTTL.
+func (s *LimitService) invalidateLimitRules(ctx context.Context) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.Incr(context.WithoutCancel(ctx), limitRulesGenerationKey).Err(); err != nil {
+		limitCacheUnavailable(err)
+	}
+}
+
+func limitCacheUnavailable(err error) {
+	observability.IncrementLimitCacheFallback()
+	zap.L().Warn("redis limit cache unavailable, reading postgres", zap.Error(err))
+}
+
+// loadLimits returns the override for the check's account, else the user's
+// override for the type and currency, else the default. It reports whether
+// an account override applied.
+func (s *LimitService) loadLimits(ctx context.Context, check LimitCheck) (Limits, bool, error) {
+	queries := s.store.Queries()
+	override, err := queries.GetApplicableLimitOverride(ctx, repository.GetApplicableLimitOverrideParams{
+		UserID:    repository.ToPgUUID(check.UserID),
+		AccountID: limitAccountParam(check.AccountID),
+		TxnType:   check.TxnType,
+		Currency:  check.Currency,
+	})
+	if err == nil {
+		return Limits{override.PerTransactionMaxMicros, override.DailyMaxMicros, override.MonthlyMaxMicros}, override.AccountID.Valid, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Limits{}, false, fmt.Errorf("failed to load limit override: %w", err)
+	}
+	def, err := queries.GetTransactionLimit(ctx, repository.GetTransactionLimitParams{TxnType: check.TxnType, Currency: check.Currency})
+	if err == nil {
+		return Limits{def.PerTransactionMaxMicros, def.DailyMaxMicros, def.MonthlyMaxMicros}, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return Limits{}, false, fmt.Errorf("failed to load transaction limit: %w", err)
+	}
+	return Limits{}, false, nil
+}
+
+// addUsage adds delta to a window's counter in one atomic upsert and returns
+// the new total.
+func (s *LimitService) addUsage(ctx context.Context, check LimitCheck, w reservedWindow, delta int64) (int64, error) {
+	return addLimitUsage(ctx, s.store.Queries(), check, w.period, w.start, delta)
+}
+
+func addLimitUsage(ctx context.Context, q *repository.Queries, check LimitCheck, period string, start time.Time, delta int64) (int64, error) {
+	return q.AddLimitUsage(ctx, repository.AddLimitUsageParams{
+		UserID:      repository.ToPgUUID(check.UserID),
+		AccountID:   limitAccountParam(check.AccountID),
+		TxnType:     check.TxnType,
+		Currency:    check.Currency,
+		Period:      period,
+		PeriodStart: pgtype.Date{Time: start, Valid: true},
+		Delta:       delta,
+	})
+}
+
+// limitAccountParam maps uuid.Nil, meaning any account, to NULL.
+func limitAccountParam(accountID uuid.UUID) pgtype.UUID {
+	if accountID == uuid.Nil {
+		return pgtype.UUID{}
+	}
+	return repository.ToPgUUID(accountID)
+}
+
+// limitWindow returns the UTC day or calendar month containing now.
+func limitWindow(period string, now time.Time) (time.Time, time.Time) {
+	if period == LimitWindowMonth {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// ListDefaults returns every default limit.
+func (s *LimitService) ListDefaults(ctx context.Context) ([]LimitRule, error) {
+	rows, err := s.store.Queries().ListTransactionLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction limits: %w", err)
+	}
+	rules := make([]LimitRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, defaultLimitRule(row))
+	}
+	return rules, nil
+}
+
+// SetDefault replaces the default limits for a transaction type and currency.
+func (s *LimitService) SetDefault(ctx context.Context, txnType, currency string, limits Limits, actorID uuid.UUID) (*LimitRule, error) {
+	txnType, currency, err := normalizeLimitKey(txnType, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+
+	var rule LimitRule
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.UpsertTransactionLimit(ctx, repository.UpsertTransactionLimitParams{
+			TxnType:                 txnType,
+			Currency:                currency,
+			PerTransactionMaxMicros: limits.PerTransactionMaxMicros,
+			DailyMaxMicros:          limits.DailyMaxMicros,
+			MonthlyMaxMicros:        limits.MonthlyMaxMicros,
+			UpdatedBy:               repository.ToPgUUID(actorID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save transaction limit: %w", err)
+		}
+		rule = defaultLimitRule(repository.TransactionLimit(row))
+		return s.auditLimitChange(ctx, qtx, "transaction_limit", uuid.Nil, actorID, "limit_set", rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateLimitRules(ctx)
+	return &rule, nil
+}
+
+// DeleteDefault removes the default limits for a transaction type and
+// currency, leaving it unlimited for users without an override.
+func (s *LimitService) DeleteDefault(ctx context.Context, txnType, currency string, actorID uuid.UUID) error {
+	txnType, currency, err := normalizeLimitKey(txnType, currency)
+	if err != nil {
+		return err
+	}
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		rows, err := qtx.DeleteTransactionLimit(ctx, repository.DeleteTransactionLimitParams{TxnType: txnType, Currency: currency})
+		if err != nil {
+			return fmt.Errorf("failed to delete transaction limit: %w", err)
+		}
+		if rows == 0 {
+			return ErrLimitNotFound
+		}
+		return s.auditLimitChange(ctx, qtx, "transaction_limit", uuid.Nil, actorID, "limit_removed", LimitRule{TxnType: txnType, Currency: currency})
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateLimitRules(ctx)
+	return nil
+}
+
+// ListUserOverrides returns a user's limit overrides.
+func (s *LimitService) ListUserOverrides(ctx context.Context, userID uuid.UUID) ([]LimitRule, error) {
+	rows, err := s.store.Queries().ListUserLimitOverrides(ctx, repository.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit overrides: %w", err)
+	}
+	rules := make([]LimitRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, overrideLimitRule(row))
+	}
+	return rules, nil
+}
+
+// SetUserOverride replaces the default for one user, transaction type and
+// currency. With accountID set it applies to that account only, which must
+// be the user's and in the currency; uuid.Nil covers every account. Caps
+// left nil are not enforced.
+func (s *LimitService) SetUserOverride(ctx context.Context, userID, accountID uuid.UUID, txnType, currency string, limits Limits, actorID uuid.UUID, note string) (*LimitRule, error) {
+	txnType, currency, err := normalizeLimitKey(txnType, currency)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.validate(); err != nil {
+		return nil, err
+	}
+
+	var rule LimitRule
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		if err := checkLimitAccount(ctx, qtx, userID, accountID, currency); err != nil {
+			return err
+		}
+		row, err := qtx.UpsertUserLimitOverride(ctx, repository.UpsertUserLimitOverrideParams{
+			UserID:                  repository.ToPgUUID(userID),
+			AccountID:               limitAccountParam(accountID),
+			TxnType:                 txnType,
+			Currency:                currency,
+			PerTransactionMaxMicros: limits.PerTransactionMaxMicros,
+			DailyMaxMicros:          limits.DailyMaxMicros,
+			MonthlyMaxMicros:        limits.MonthlyMaxMicros,
+			Note:                    textParam(strings.TrimSpace(note)),
+			UpdatedBy:               repository.ToPgUUID(actorID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save limit override: %w", err)
+		}
+		rule = overrideLimitRule(repository.UserLimitOverride(row))
+		return s.auditLimitChange(ctx, qtx, "user", userID, actorID, "limit_override_set", rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.invalidateLimitRules(ctx)
+	return &rule, nil
+}
+
+// DeleteUserOverride removes the user-wide override, or with accountID set
+// the account's, so the next broader limit applies.
+func (s *LimitService) DeleteUserOverride(ctx context.Context, userID, accountID uuid.UUID, txnType, currency string, actorID uuid.UUID) error {
+	txnType, currency, err := normalizeLimitKey(txnType, currency)
+	if err != nil {
+		return err
+	}
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		rows, err := qtx.DeleteUserLimitOverride(ctx, repository.DeleteUserLimitOverrideParams{
+			UserID:    repository.ToPgUUID(userID),
+			AccountID: limitAccountParam(accountID),
+			TxnType:   txnType,
+			Currency:  currency,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete limit override: %w", err)
+		}
+		if rows == 0 {
+			return ErrLimitNotFound
+		}
+		rule := LimitRule{UserID: &userID, TxnType: txnType, Currency: currency}
+		if accountID != uuid.Nil {
+			rule.AccountID = &accountID
+		}
+		return s.auditLimitChange(ctx, qtx, "user", userID, actorID, "limit_override_removed", rule)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateLimitRules(ctx)
+	return nil
+}
+
+// checkLimitAccount rejects an account override for an account that is not
+// the user's or not in the override's currency.
+func checkLimitAccount(ctx context.Context, qtx *repository.Queries, userID, accountID uuid.UUID, currency string) error {
+	if accountID == uuid.Nil {
+		return nil
+	}
+	account, err := qtx.GetAccount(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: unknown account %s", ErrInvalidLimit, accountID)
+		}
+		return fmt.Errorf("failed to fetch account for limits: %w", err)
+	}
+	if repository.FromPgUUID(account.UserID) != userID {
+		return fmt.Errorf("%w: account %s does not belong to the user", ErrInvalidLimit, accountID)
+	}
+	if account.Currency != currency {
+		return fmt.Errorf("%w: account %s holds %s, not %s", ErrInvalidLimit, accountID, account.Currency, currency)
+	}
+	return nil
+}
+
+func (s *LimitService) auditLimitChange(ctx context.Context, qtx *repository.Queries, entityType string, entityID, actorID uuid.UUID, action string, rule LimitRule) error {
+	metadata, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return s.audit.Write(ctx, qtx, entityType, entityID, &actorID, action, "", rule.TxnType+":"+rule.Currency, metadata)
+}
+
+func normalizeLimitKey(txnType, currency string) (string, string, error) {
+	txnType = strings.ToLower(strings.TrimSpace(txnType))
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	switch txnType {
+	case domain.TxTypeTransfer, domain.TxTypeExchange, domain.TxTypePayout:
+	default:
+		return "", "", fmt.Errorf("%w: unknown transaction type %q", ErrInvalidLimit, txnType)
+	}
+	if _, err := getSystemAccountID(currency); err != nil {
+		return "", "", fmt.Errorf("%w: unsupported currency %q", ErrInvalidLimit, currency)
+	}
+	return txnType, currency, nil
+}
+
+func defaultLimitRule(row repository.TransactionLimit) LimitRule {
+	return LimitRule{
+		TxnType:   row.TxnType,
+		Currency:  row.Currency,
+		UpdatedBy: optionalUUID(row.UpdatedBy),
+		UpdatedAt: row.UpdatedAt.Time,
+		Limits:    Limits{row.PerTransactionMaxMicros, row.DailyMaxMicros, row.MonthlyMaxMicros},
+	}
+}
+
+func overrideLimitRule(row repository.UserLimitOverride) LimitRule {
+	userID := repository.FromPgUUID(row.UserID)
+	return LimitRule{
+		UserID:    &userID,
+		AccountID: optionalUUID(row.AccountID),
+		TxnType:   row.TxnType,
+		Currency:  row.Currency,
+		Note:      derefString(row.Note),
+		UpdatedBy: optionalUUID(row.UpdatedBy),
+		UpdatedAt: row.UpdatedAt.Time,
+		Limits:    Limits{row.PerTransactionMaxMicros, row.DailyMaxMicros, row.MonthlyMaxMicros},
+	}
+}
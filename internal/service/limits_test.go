@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitWindow(t *testing.T) {
+	now := time.Date(2026, 2, 28, 23, 59, 0, 0, time.UTC)
+
+	start, end := limitWindow(LimitWindowDay, now)
+	require.Equal(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = limitWindow(LimitWindowMonth, now)
+	require.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestLimitRulesCacheKey(t *testing.T) {
+	cache := newFlakyRedis(time.Now)
+	svc := NewLimitService(panicStore{}).WithRedis(cache)
+	ctx := context.Background()
+	userID, accountID := uuid.New(), uuid.New()
+	check := LimitCheck{UserID: userID, TxnType: "transfer", Currency: "USD"}
+
+	require.Equal(t, "limits:rules:0:"+userID.String()+":transfer:USD", svc.limitRulesCacheKey(ctx, check))
+	check.AccountID = accountID
+	require.Equal(t, "limits:rules:0:"+userID.String()+":"+accountID.String()+":transfer:USD", svc.limitRulesCacheKey(ctx, check))
+
+	// A limit change moves lookups to a new generation.
+	svc.invalidateLimitRules(ctx)
+	require.Equal(t, "limits:rules:1:"+userID.String()+":"+accountID.String()+":transfer:USD", svc.limitRulesCacheKey(ctx, check))
+
+	cache.setDown(true)
+	require.Empty(t, svc.limitRulesCacheKey(ctx, check))
+}
+
+func TestLimitValidation(t *testing.T) {
+	svc := NewLimitService(panicStore{})
+	ctx := context.Background()
+	zero := int64(0)
+
+	_, err := svc.SetDefault(ctx, "deposit", "USD", Limits{}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidLimit)
+	_, err = svc.SetDefault(ctx, "transfer", "JPY", Limits{}, uuid.New())
+	require.ErrorIs(t, err, ErrInvalidLimit)
+	_, err = svc.SetUserOverride(ctx, uuid.New(), uuid.Nil, "payout", "usd", Limits{DailyMaxMicros: &zero}, uuid.New(), "")
+	require.ErrorIs(t, err, ErrInvalidLimit)
+
+	var limitErr error = &LimitExceededError{TxnType: "transfer", Currency: "USD", Window: LimitWindowMonth, LimitMicros: 10, UsedMicros: 8, AmountMicros: 5}
+	require.True(t, errors.Is(limitErr, ErrLimitExceeded))
+	require.Contains(t, limitErr.Error(), "monthly limit of 10")
+}
+
+func TestTransferLimits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	limits := NewLimitService(store)
+	transfers := NewTransferService(store, NewMockExchangeRateService()).WithLimits(limits)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	from := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1000}
+	require.NoError(t, repo.CreateAccount(ctx, from))
+	to := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, to))
+
+	perTx, daily := int64(300), int64(500)
+	_, err := limits.SetDefault(ctx, domain.TxTypeTransfer, "USD", Limits{PerTransactionMaxMicros: &perTx, DailyMaxMicros: &daily}, admin.ID)
+	require.NoError(t, err)
+
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 301, "limit-per-tx")
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitWindowTransaction, limitErr.Window)
+
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 300, "limit-1")
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 300, "limit-2")
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitWindowDay, limitErr.Window)
+	require.Equal(t, int64(300), limitErr.UsedMicros)
+
+	// A replayed reference returns the original transfer and counts nothing.
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 300, "limit-1")
+	require.NoError(t, err)
+
+	// A transfer that fails after the check gives its usage back.
+	euro := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "EUR", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, euro))
+	_, err = transfers.Transfer(ctx, from.ID, euro.ID, 200, "limit-mismatch")
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 200, "limit-3")
+	require.NoError(t, err)
+
+	// An override replaces the default for this user only.
+	monthly := int64(10_000)
+	_, err = limits.SetUserOverride(ctx, user.ID, uuid.Nil, domain.TxTypeTransfer, "USD", Limits{MonthlyMaxMicros: &monthly}, admin.ID, "payroll customer")
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 400, "limit-override")
+	require.NoError(t, err)
+
+	require.NoError(t, limits.DeleteUserOverride(ctx, user.ID, uuid.Nil, domain.TxTypeTransfer, "USD", admin.ID))
+	require.ErrorIs(t, limits.DeleteUserOverride(ctx, user.ID, uuid.Nil, domain.TxTypeTransfer, "USD", admin.ID), ErrLimitNotFound)
+}
+
+func TestAccountLimitOverrides(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	limits := NewLimitService(store)
+	transfers := NewTransferService(store, NewMockExchangeRateService()).WithLimits(limits)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	capped := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 2000}
+	require.NoError(t, repo.CreateAccount(ctx, capped))
+	other := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 2000}
+	require.NoError(t, repo.CreateAccount(ctx, other))
+	to := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, to))
+	euro := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "EUR", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, euro))
+
+	// An account override must name one of the user's accounts in its currency.
+	daily := int64(200)
+	_, err := limits.SetUserOverride(ctx, user.ID, to.ID, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &daily}, admin.ID, "")
+	require.ErrorIs(t, err, ErrInvalidLimit)
+	_, err = limits.SetUserOverride(ctx, user.ID, euro.ID, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &daily}, admin.ID, "")
+	require.ErrorIs(t, err, ErrInvalidLimit)
+
+	userDaily := int64(500)
+	_, err = limits.SetUserOverride(ctx, user.ID, uuid.Nil, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &userDaily}, admin.ID, "")
+	require.NoError(t, err)
+	rule, err := limits.SetUserOverride(ctx, user.ID, capped.ID, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &daily}, admin.ID, "shared card account")
+	require.NoError(t, err)
+	require.Equal(t, capped.ID, *rule.AccountID)
+
+	rules, err := limits.ListUserOverrides(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	require.Nil(t, rules[0].AccountID)
+	require.Equal(t, capped.ID, *rules[1].AccountID)
+
+	// The account override caps that account on its own counter.
+	_, err = transfers.Transfer(ctx, capped.ID, to.ID, 200, "acct-limit-1")
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, capped.ID, to.ID, 1, "acct-limit-2")
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitWindowDay, limitErr.Window)
+	require.Equal(t, daily, limitErr.LimitMicros)
+	require.Equal(t, int64(200), limitErr.UsedMicros)
+
+	// The user's other accounts stay under the user-wide override, which the
+	// capped account's usage did not count towards.
+	_, err = transfers.Transfer(ctx, other.ID, to.ID, 500, "acct-limit-3")
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, other.ID, to.ID, 1, "acct-limit-4")
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, userDaily, limitErr.LimitMicros)
+
+	// Removing the account override puts the account back on the user-wide cap.
+	require.NoError(t, limits.DeleteUserOverride(ctx, user.ID, capped.ID, domain.TxTypeTransfer, "USD", admin.ID))
+	require.ErrorIs(t, limits.DeleteUserOverride(ctx, user.ID, capped.ID, domain.TxTypeTransfer, "USD", admin.ID), ErrLimitNotFound)
+	_, err = transfers.Transfer(ctx, capped.ID, to.ID, 1, "acct-limit-5")
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, userDaily, limitErr.LimitMicros)
+}
+
+func TestLimitsHoldAcrossRedisOutage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	cache := newFlakyRedis(time.Now)
+	limits := NewLimitService(store).WithRedis(cache)
+	transfers := NewTransferService(store, NewMockExchangeRateService()).WithLimits(limits)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	from := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 2000}
+	require.NoError(t, repo.CreateAccount(ctx, from))
+	to := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, to))
+
+	daily := int64(500)
+	_, err := limits.SetDefault(ctx, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &daily}, admin.ID)
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 300, "outage-1")
+	require.NoError(t, err)
+
+	// Usage counted before the outage still counts during it.
+	cache.setDown(true)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 300, "outage-2")
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, int64(300), limitErr.UsedMicros)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 200, "outage-3")
+	require.NoError(t, err)
+
+	// And usage counted during the outage still counts after it.
+	cache.setDown(false)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 1, "outage-4")
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, int64(500), limitErr.UsedMicros)
+
+	// A raised cap applies at once despite the cached limits.
+	daily = 1000
+	_, err = limits.SetDefault(ctx, domain.TxTypeTransfer, "USD", Limits{DailyMaxMicros: &daily}, admin.ID)
+	require.NoError(t, err)
+	_, err = transfers.Transfer(ctx, from.ID, to.ID, 1, "outage-5")
+	require.NoError(t, err)
+}
+
+func TestPayoutLimits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	limits := NewLimitService(store)
+	payouts := NewPayoutService(store, gateway.NewMockGateway()).WithLimits(limits)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "GBP", Balance: 1000}
+	require.NoError(t, repo.CreateAccount(ctx, account))
+
+	monthly := int64(250)
+	_, err := limits.SetDefault(ctx, domain.TxTypePayout, "GBP", Limits{MonthlyMaxMicros: &monthly}, admin.ID)
+	require.NoError(t, err)
+
+	destination := PayoutDestinationInput{Name: "Ayo", SortCode: "601613", AccountNumber: "31926819"}
+	_, err = payouts.RequestPayout(ctx, RequestPayoutRequest{AccountID: account.ID, AmountMicros: 200, Currency: "GBP", Destination: destination, ReferenceID: "limit-payout-1"})
+	require.NoError(t, err)
+	_, err = payouts.RequestPayout(ctx, RequestPayoutRequest{AccountID: account.ID, AmountMicros: 100, Currency: "GBP", Destination: destination, ReferenceID: "limit-payout-2"})
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	locked, err := repository.New(db).GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(200), locked.LockedMicros)
+}
+
+func TestPayoutLimitsReleasedWhenRejectedOrFailed(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	limits := NewLimitService(store)
+	approvals := NewPayoutService(store, &stubGateway{ref: "MOCK-REF"}).WithLimits(limits).WithApprovalThresholds(map[string]int64{"GBP": 0})
+	failing := NewPayoutService(store, &stubGateway{err: errors.New("gateway down")}).WithLimits(limits)
+	ctx := context.Background()
+
+	checker := &models.User{ID: uuid.New(), Username: "checker", Email: "checker@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, checker))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "GBP", Balance: 1000}
+	require.NoError(t, repo.CreateAccount(ctx, account))
+
+	daily := int64(300)
+	_, err := limits.SetDefault(ctx, domain.TxTypePayout, "GBP", Limits{DailyMaxMicros: &daily}, checker.ID)
+	require.NoError(t, err)
+
+	destination := PayoutDestinationInput{Name: "Ayo", SortCode: "601613", AccountNumber: "31926819"}
+	request := func(svc *PayoutService, amount int64, ref string) (*PayoutResponse, error) {
+		return svc.RequestPayout(ctx, RequestPayoutRequest{AccountID: account.ID, AmountMicros: amount, Currency: "GBP", Destination: destination, ReferenceID: ref, RequestedBy: &user.ID})
+	}
+
+	// A rejected payout gives its usage back.
+	resp, err := request(approvals, 200, "limit-release-rejected")
+	require.NoError(t, err)
+	_, err = approvals.RejectPayout(ctx, PayoutReviewRequest{PayoutID: resp.PayoutID, ActorID: checker.ID, Reason: "unknown beneficiary"})
+	require.NoError(t, err)
+
+	// So does one the gateway fails.
+	resp, err = request(failing, 200, "limit-release-failed")
+	require.NoError(t, err)
+	require.NoError(t, failing.ProcessPayouts(ctx, 5))
+	payoutRow, err := repository.New(db).GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusFailed, payoutRow.Status)
+	require.Nil(t, payoutRow.LimitReservation)
+
+	// Releasing again is a no-op.
+	require.NoError(t, store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		return releasePayoutLimit(ctx, qtx, payoutRow.ID)
+	}))
+
+	// The whole cap is available again, and only once.
+	_, err = request(failing, 300, "limit-release-full")
+	require.NoError(t, err)
+	_, err = request(failing, 1, "limit-release-over")
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, int64(300), limitErr.UsedMicros)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrOpeningBalanceExists indicates the account was already funded.
+	ErrOpeningBalanceExists = errors.New("account already has an opening balance")
+	// ErrSystemAccountOpeningBalance indicates an opening balance aimed at a
+	// system liquidity or equity account.
+	ErrSystemAccountOpeningBalance = errors.New("system accounts cannot receive an opening balance")
+)
+
+// PostOpeningBalance funds an account by crediting it from the equity
+// account of its currency. Each customer account gets at most one opening
+// balance; its reference is "opening-balance:<account id>".
+func (s *TransferService) PostOpeningBalance(ctx context.Context, accountID uuid.UUID, amount int64, actorID uuid.UUID, note string) (*models.Transaction, error) {
+	if amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if isSystemAccount(accountID) {
+		return nil, ErrSystemAccountOpeningBalance
+	}
+	referenceID := "opening-balance:" + accountID.String()
+
+	queries := s.store.Queries()
+	if _, err := queries.CheckTransactionIdempotency(ctx, referenceID); err == nil {
+		return nil, ErrOpeningBalanceExists
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check idempotency: %w", err)
+	}
+
+	currency, err := queries.GetAccountCurrency(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	equityID, err := getEquityAccountID(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{
+		"kind":              "opening_balance",
+		"account_id":        accountID,
+		"equity_account_id": equityID,
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		details["note"] = note
+	}
+	metadata, err := json.Marshal(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	transactionID := uuid.New()
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		accountIDs := []uuid.UUID{accountID, equityID}
+		sortUUIDs(accountIDs)
+		statuses := make(map[uuid.UUID]string, len(accountIDs))
+		for _, id := range accountIDs {
+			status, err := qtx.LockAccount(ctx, repository.ToPgUUID(id))
+			if err != nil {
+				return fmt.Errorf("failed to lock account %s: %w", id, err)
+			}
+			statuses[id] = status
+		}
+		if err := checkAccountCredit(accountID, statuses[accountID]); err != nil {
+			return err
+		}
+		if err := s.kyc.checkCredit(ctx, qtx, accountID, amount); err != nil {
+			return err
+		}
+
+		if _, err := qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
+			ID:          repository.ToPgUUID(transactionID),
+			Amount:      amount,
+			Currency:    currency,
+			Type:        domain.TxTypeOpeningBalance,
+			Status:      domain.TxStatusPending,
+			ReferenceID: referenceID,
+			Metadata:    metadata,
+		}); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		if err := s.audit.Write(ctx, qtx, "transaction", transactionID, &actorID, "created", "", domain.TxStatusPending, metadata); err != nil {
+			return err
+		}
+		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusProcessing, &actorID, "processing_started", nil); err != nil {
+			return fmt.Errorf("failed to transition transaction to processing: %w", err)
+		}
+
+		if err := postEntries(ctx, qtx, transactionID, equityID, accountID, amount); err != nil {
+			return err
+		}
+
+		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, &actorID, "completed", nil); err != nil {
+			return fmt.Errorf("failed to complete transaction: %w", err)
+		}
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventOpeningBalancePosted, map[string]any{
+			"transaction_id":    transactionID,
+			"reference_id":      referenceID,
+			"account_id":        accountID,
+			"equity_account_id": equityID,
+			"amount_micros":     amount,
+			"currency":          currency,
+		})
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrOpeningBalanceExists
+		}
+		return nil, err
+	}
+
+	return &models.Transaction{
+		ID:          transactionID,
+		Amount:      amount,
+		Currency:    currency,
+		Type:        domain.TxTypeOpeningBalance,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: referenceID,
+		Metadata:    details,
+	}, nil
+}
+
+func getEquityAccountID(currency string) (uuid.UUID, error) {
+	switch strings.ToUpper(strings.TrimSpace(currency)) {
+	case "USD":
+		return uuid.Parse(domain.SystemEquityAccountUSD)
+	case "EUR":
+		return uuid.Parse(domain.SystemEquityAccountEUR)
+	case "GBP":
+		return uuid.Parse(domain.SystemEquityAccountGBP)
+	default:
+		return uuid.Nil, fmt.Errorf("unsupported currency for equity account: %s", currency)
+	}
+}
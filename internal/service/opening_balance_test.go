@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostOpeningBalanceRejectsSystemAccounts(t *testing.T) {
+	svc := NewTransferService(panicStore{}, NewMockExchangeRateService())
+	ctx := context.Background()
+
+	for _, id := range []string{domain.SystemAccountUSD, domain.SystemEquityAccountEUR} {
+		_, err := svc.PostOpeningBalance(ctx, uuid.MustParse(id), 100, uuid.New(), "")
+		require.ErrorIs(t, err, ErrSystemAccountOpeningBalance)
+	}
+}
+
+func TestPostOpeningBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	svc := NewTransferService(store, NewMockExchangeRateService())
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	account, err := NewAccountService(repo).CreateAccount(ctx, user.ID, "EUR")
+	require.NoError(t, err)
+	require.Zero(t, account.Balance)
+
+	_, err = svc.PostOpeningBalance(ctx, account.ID, 0, admin.ID, "")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	tx, err := svc.PostOpeningBalance(ctx, account.ID, 2500, admin.ID, "migrated from legacy core")
+	require.NoError(t, err)
+	require.Equal(t, domain.TxTypeOpeningBalance, tx.Type)
+	require.Equal(t, "opening-balance:"+account.ID.String(), tx.ReferenceID)
+
+	_, err = svc.PostOpeningBalance(ctx, account.ID, 100, admin.ID, "")
+	require.ErrorIs(t, err, ErrOpeningBalanceExists)
+
+	funded, err := repo.GetAccount(ctx, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2500), funded.Balance)
+	equity, err := repo.GetAccount(ctx, uuid.MustParse(domain.SystemEquityAccountEUR))
+	require.NoError(t, err)
+	require.Equal(t, int64(-2500), equity.Balance)
+
+	entries, err := repo.GetEntries(ctx, account.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, domain.DirectionCredit, entries[0].Direction)
+
+	auditRows, err := repository.New(db).GetAuditLogsByEntity(ctx, repository.GetAuditLogsByEntityParams{
+		EntityType: "transaction",
+		EntityID:   repository.ToPgUUID(tx.ID),
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRows, 3)
+	require.Equal(t, repository.ToPgUUID(admin.ID), auditRows[0].ActorID)
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	outboxClaimLease  = 30 * time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+	outboxErrorMaxLen = 1024
+)
+
+// writeOutboxEvent records a domain event in the caller's transaction, so the
+// event exists if and only if the state change it describes commits.
+func writeOutboxEvent(ctx context.Context, qtx *repository.Queries, aggregateType string, aggregateID uuid.UUID, eventType string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", eventType, err)
+	}
+	if err := qtx.InsertOutboxEvent(ctx, repository.InsertOutboxEventParams{
+		ID:            repository.ToPgUUID(uuid.New()),
+		AggregateType: aggregateType,
+		AggregateID:   repository.ToPgUUID(aggregateID),
+		EventType:     eventType,
+		Payload:       body,
+	}); err != nil {
+		return fmt.Errorf("insert %s outbox event: %w", eventType, err)
+	}
+	return nil
+}
+
+// writePayoutEvent records a payout lifecycle event keyed by payout ID.
+func writePayoutEvent(ctx context.Context, qtx *repository.Queries, eventType string, payout repository.Payout, extra map[string]any) error {
+	payload := map[string]any{
+		"payout_id":      repository.FromPgUUID(payout.ID),
+		"transaction_id": repository.FromPgUUID(payout.TransactionID),
+		"account_id":     repository.FromPgUUID(payout.AccountID),
+		"amount_micros":  payout.AmountMicros,
+		"currency":       payout.Currency,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	return writeOutboxEvent(ctx, qtx, outbox.AggregatePayout, repository.FromPgUUID(payout.ID), eventType, payload)
+}
+
+// OutboxRelayService publishes committed outbox events to the configured sinks.
+// Each (event, sink) pair is recorded once accepted, so retries after a partial
+// failure only go to the sinks that missed the event.
+type OutboxRelayService struct {
+	store QueryStore
+	sinks []outbox.Sink
+}
+
+func NewOutboxRelayService(store QueryStore, sinks ...outbox.Sink) *OutboxRelayService {
+	return &OutboxRelayService{
+		store: store,
+		sinks: sinks,
+	}
+}
+
+// RelayBatch claims up to batchSize due events and publishes them.
+// It returns the number of events fully published to every sink.
+func (s *OutboxRelayService) RelayBatch(ctx context.Context, batchSize int32) (int, error) {
+	queries := s.store.Queries()
+	events, err := queries.ClaimOutboxEvents(ctx, repository.ClaimOutboxEventsParams{
+		LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(outboxClaimLease), Valid: true},
+		BatchSize:  batchSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox events: %w", err)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.Time.Before(events[j].CreatedAt.Time)
+	})
+
+	published := 0
+	for _, row := range events {
+		if err := ctx.Err(); err != nil {
+			// Unpublished claims become due again when their lease expires.
+			return published, err
+		}
+		ok, err := s.deliver(ctx, queries, row)
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// PendingCount returns the number of events not yet published to every sink.
+func (s *OutboxRelayService) PendingCount(ctx context.Context) (int64, error) {
+	count, err := s.store.Queries().CountPendingOutboxEvents(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("count pending outbox events: %w", err)
+	}
+	return count, nil
+}
+
+// PurgePublished deletes events published before cutoff, with their delivery rows.
+func (s *OutboxRelayService) PurgePublished(ctx context.Context, cutoff time.Time) (int64, error) {
+	rows, err := s.store.Queries().DeletePublishedOutboxEvents(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+	if err != nil {
+		return 0, fmt.Errorf("purge published outbox events: %w", err)
+	}
+	return rows, nil
+}
+
+func (s *OutboxRelayService) deliver(ctx context.Context, queries *repository.Queries, row repository.OutboxEvent) (bool, error) {
+	delivered, err := queries.GetOutboxDeliveredSinks(ctx, row.ID)
+	if err != nil {
+		return false, fmt.Errorf("load outbox deliveries: %w", err)
+	}
+	done := make(map[string]bool, len(delivered))
+	for _, sink := range delivered {
+		done[sink] = true
+	}
+
+	evt := outbox.Event{
+		ID:            repository.FromPgUUID(row.ID),
+		Type:          row.EventType,
+		AggregateType: row.AggregateType,
+		AggregateID:   repository.FromPgUUID(row.AggregateID),
+		Payload:       row.Payload,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+
+	var failures []string
+	for _, sink := range s.sinks {
+		name := sink.Name()
+		if done[name] {
+			continue
+		}
+		if err := sink.Publish(ctx, evt); err != nil {
+			observability.IncrementOutboxPublish(name, "failed")
+			zap.L().Warn("outbox publish failed", zap.Error(err), zap.String("sink", name), zap.String("event_id", evt.ID.String()), zap.String("event_type", evt.Type))
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		observability.IncrementOutboxPublish(name, "published")
+		if err := queries.InsertOutboxDelivery(ctx, repository.InsertOutboxDeliveryParams{
+			EventID: row.ID,
+			Sink:    name,
+		}); err != nil {
+			return false, fmt.Errorf("record outbox delivery: %w", err)
+		}
+	}
+
+	if len(failures) > 0 {
+		lastError := strings.Join(failures, "; ")
+		if len(lastError) > outboxErrorMaxLen {
+			lastError = lastError[:outboxErrorMaxLen]
+		}
+		rows, err := queries.MarkOutboxEventRetry(ctx, repository.MarkOutboxEventRetryParams{
+			ID:            row.ID,
+			LastError:     &lastError,
+			NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(outboxBackoff(row.Attempts + 1)), Valid: true},
+		})
+		if err != nil {
+			return false, fmt.Errorf("schedule outbox retry: %w", err)
+		}
+		if err := requireExactlyOne(rows, "schedule outbox retry"); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	rows, err := queries.MarkOutboxEventPublished(ctx, row.ID)
+	if err != nil {
+		return false, fmt.Errorf("mark outbox event published: %w", err)
+	}
+	if err := requireExactlyOne(rows, "mark outbox event published"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// outboxBackoff doubles the retry delay per attempt, capped at outboxMaxBackoff.
+func outboxBackoff(attempt int32) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 16 {
+		return outboxMaxBackoff
+	}
+	delay := time.Second << (attempt - 1)
+	if delay > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return delay
+}
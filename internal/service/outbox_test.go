@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	name string
+	mu   sync.Mutex
+	err  error
+	got  []outbox.Event
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Publish(ctx context.Context, evt outbox.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.got = append(s.got, evt)
+	return nil
+}
+
+func TestRequestPayoutWritesOutboxEvent(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	payoutSvc := NewPayoutService(store, &stubGateway{ref: "MOCK-REF"})
+
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "outbox-user", Email: "outbox@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 100_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "req-outbox",
+	})
+	require.NoError(t, err)
+
+	events, err := repository.New(db).GetOutboxEventsByAggregate(ctx, repository.GetOutboxEventsByAggregateParams{
+		AggregateType: outbox.AggregatePayout,
+		AggregateID:   repository.ToPgUUID(resp.PayoutID),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, outbox.EventPayoutRequested, events[0].EventType)
+	require.False(t, events[0].PublishedAt.Valid)
+}
+
+func TestOutboxRelayPublishesOncePerSink(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := repository.NewStore(db)
+	ctx := context.Background()
+	aggregateID := uuid.New()
+	require.NoError(t, store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, aggregateID, outbox.EventTransferCompleted, map[string]any{"amount_micros": 1})
+	}))
+
+	healthy := &recordingSink{name: "healthy"}
+	flaky := &recordingSink{name: "flaky", err: errors.New("sink down")}
+	relay := NewOutboxRelayService(store, healthy, flaky)
+
+	published, err := relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, published)
+	require.Len(t, healthy.got, 1)
+
+	// Make the retry due immediately and recover the flaky sink.
+	_, err = db.Exec(ctx, "UPDATE outbox_events SET next_attempt_at = NOW() WHERE aggregate_id = $1", aggregateID)
+	require.NoError(t, err)
+	flaky.err = nil
+
+	published, err = relay.RelayBatch(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Len(t, healthy.got, 1, "healthy sink must not see the event twice")
+	require.Len(t, flaky.got, 1)
+	require.Equal(t, healthy.got[0].ID, flaky.got[0].ID)
+
+	events, err := repository.New(db).GetOutboxEventsByAggregate(ctx, repository.GetOutboxEventsByAggregateParams{
+		AggregateType: outbox.AggregateTransaction,
+		AggregateID:   repository.ToPgUUID(aggregateID),
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.True(t, events[0].PublishedAt.Valid)
+	require.Equal(t, int32(1), events[0].Attempts)
+
+	pending, err := relay.PendingCount(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), pending)
+}
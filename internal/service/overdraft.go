@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// overdraftPageSize is the number of overdrawn accounts read per page
+// while accruing interest.
+const overdraftPageSize = 500
+
+// overdraftCatchUpDays bounds how many days back a run charges days it
+// missed. Older days are left to finance to charge by hand.
+const overdraftCatchUpDays = 31
+
+// maxOverdraftInterestBps caps the annual overdraft rate at 100%.
+const maxOverdraftInterestBps = 10000
+
+var (
+	// ErrInvalidOverdraftTerms indicates a negative limit or a rate out of range.
+	ErrInvalidOverdraftTerms = errors.New("invalid overdraft terms")
+	// ErrOverdraftInUse indicates a new limit below what the account already uses.
+	ErrOverdraftInUse = errors.New("overdraft limit is below the amount in use")
+)
+
+// OverdraftService manages per-account overdraft limits and charges daily
+// interest on negative balances.
+type OverdraftService struct {
+	store QueryStore
+	audit *AuditService
+	now   func() time.Time
+}
+
+// NewOverdraftService creates a new OverdraftService instance.
+func NewOverdraftService(store QueryStore) *OverdraftService {
+	return &OverdraftService{
+		store: store,
+		audit: NewAuditService(store),
+		now:   time.Now,
+	}
+}
+
+// OverdraftTerms is an account's approved overdraft.
+type OverdraftTerms struct {
+	LimitMicros int64
+	InterestBps int32
+}
+
+// SetOverdraft replaces an account's overdraft terms. A limit cannot be cut
+// below what the account already draws, counting funds held by payouts.
+func (s *OverdraftService) SetOverdraft(ctx context.Context, accountID uuid.UUID, terms OverdraftTerms, actorID uuid.UUID, note string) (*models.Account, error) {
+	if terms.LimitMicros < 0 {
+		return nil, fmt.Errorf("%w: limit must not be negative", ErrInvalidOverdraftTerms)
+	}
+	if terms.InterestBps < 0 || terms.InterestBps > maxOverdraftInterestBps {
+		return nil, fmt.Errorf("%w: interest_bps must be between 0 and %d", ErrInvalidOverdraftTerms, maxOverdraftInterestBps)
+	}
+	if isSystemAccount(accountID) {
+		return nil, fmt.Errorf("%w: system accounts have no overdraft", ErrInvalidOverdraftTerms)
+	}
+
+	var account *models.Account
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(accountID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrAccountNotFound
+			}
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+		if row.Status == domain.AccountStatusClosed {
+			return fmt.Errorf("%w: account %s", ErrAccountClosed, accountID)
+		}
+		if availableFunds(row.Balance, row.LockedMicros, terms.LimitMicros) < 0 {
+			return fmt.Errorf("%w: account draws %d micros", ErrOverdraftInUse, row.LockedMicros-row.Balance)
+		}
+
+		updated, err := qtx.UpdateAccountOverdraft(ctx, repository.UpdateAccountOverdraftParams{
+			OverdraftLimitMicros: terms.LimitMicros,
+			OverdraftInterestBps: terms.InterestBps,
+			ID:                   repository.ToPgUUID(accountID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update overdraft: %w", err)
+		}
+
+		details := map[string]any{
+			"prev_limit_micros": row.OverdraftLimitMicros,
+			"prev_interest_bps": row.OverdraftInterestBps,
+			"limit_micros":      terms.LimitMicros,
+			"interest_bps":      terms.InterestBps,
+			"currency":          row.Currency,
+			"account_id":        accountID,
+		}
+		if note = strings.TrimSpace(note); note != "" {
+			details["note"] = note
+		}
+		metadata, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		prev := formatOverdraftTerms(row.OverdraftLimitMicros, row.OverdraftInterestBps)
+		next := formatOverdraftTerms(terms.LimitMicros, terms.InterestBps)
+		if err := s.audit.Write(ctx, qtx, "account", accountID, &actorID, "overdraft_updated", prev, next, metadata); err != nil {
+			return err
+		}
+		if err := writeOutboxEvent(ctx, qtx, outbox.AggregateAccount, accountID, outbox.EventOverdraftUpdated, details); err != nil {
+			return err
+		}
+
+		account = &models.Account{
+			ID:                   repository.FromPgUUID(updated.ID),
+			UserID:               repository.FromPgUUID(updated.UserID),
+			Currency:             updated.Currency,
+			Balance:              updated.Balance,
+			Status:               updated.Status,
+			CreatedAt:            updated.CreatedAt.Time,
+			OverdraftLimit:       updated.OverdraftLimitMicros,
+			OverdraftInterestBps: updated.OverdraftInterestBps,
+		}
+		if updated.StatusReason != nil {
+			account.StatusReason = *updated.StatusReason
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// AccrueInterest charges interest to every account with a rate for each UTC
+// day, up to and including the one containing through, that ended
+// overdrawn and has not been charged. Each account is caught up from the day
+// after its last charge, looking back at most overdraftCatchUpDays, so days
+// missed while the worker was down are still charged. Each charge is its
+// own transaction with reference "overdraft-interest:<account id>:<date>",
+// so a rerun charges nothing twice. It returns the number of charges posted.
+func (s *OverdraftService) AccrueInterest(ctx context.Context, through time.Time) (int, error) {
+	last := utcDay(through)
+	first := last.AddDate(0, 0, 1-overdraftCatchUpDays)
+	charged := 0
+	after := uuid.Nil
+	for {
+		ids, err := s.store.Queries().ListOverdrawnAccounts(ctx, repository.ListOverdrawnAccountsParams{
+			Since:    dateTimestamp(first.AddDate(0, 0, 1)),
+			AfterID:  repository.ToPgUUID(after),
+			RowLimit: overdraftPageSize,
+		})
+		if err != nil {
+			return charged, fmt.Errorf("list overdrawn accounts: %w", err)
+		}
+		for _, id := range ids {
+			accountID := repository.FromPgUUID(id)
+			n, err := s.accrueAccountInterest(ctx, accountID, first, last)
+			charged += n
+			if err != nil {
+				return charged, fmt.Errorf("charge overdraft interest to %s: %w", accountID, err)
+			}
+		}
+		if len(ids) < overdraftPageSize {
+			return charged, nil
+		}
+		after = repository.FromPgUUID(ids[len(ids)-1])
+	}
+}
+
+// AccrueInterestForYesterday accrues interest through the last full UTC day.
+func (s *OverdraftService) AccrueInterestForYesterday(ctx context.Context) (int, error) {
+	return s.AccrueInterest(ctx, s.now().UTC().AddDate(0, 0, -1))
+}
+
+// accrueAccountInterest charges the account for the days from the one after
+// its last charge, or first if that is later, through last.
+func (s *OverdraftService) accrueAccountInterest(ctx context.Context, accountID uuid.UUID, first, last time.Time) (int, error) {
+	prefix := overdraftInterestReference(accountID, "")
+	reference, err := s.store.Queries().GetLastOverdraftInterestReference(ctx, repository.GetLastOverdraftInterestReferenceParams{
+		AccountID:       repository.ToPgUUID(accountID),
+		Since:           dateTimestamp(first),
+		ReferencePrefix: prefix,
+	})
+	if err == nil {
+		if day, err := time.Parse(time.DateOnly, strings.TrimPrefix(reference, prefix)); err == nil && !day.Before(first) {
+			first = day.AddDate(0, 0, 1)
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to find last interest charge: %w", err)
+	}
+
+	charged := 0
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		ok, err := s.chargeInterest(ctx, accountID, day)
+		if err != nil {
+			return charged, fmt.Errorf("%s: %w", day.Format(time.DateOnly), err)
+		}
+		if ok {
+			charged++
+		}
+	}
+	return charged, nil
+}
+
+func overdraftInterestReference(accountID uuid.UUID, date string) string {
+	return "overdraft-interest:" + accountID.String() + ":" + date
+}
+
+// balanceAtDayEnd is the account's balance when day ended: the current
+// balance less the entries posted since.
+func balanceAtDayEnd(ctx context.Context, q *repository.Queries, accountID uuid.UUID, balance int64, day time.Time) (int64, error) {
+	net, err := q.GetAccountNetEntriesSince(ctx, repository.GetAccountNetEntriesSinceParams{
+		AccountID: repository.ToPgUUID(accountID),
+		Since:     dateTimestamp(utcDay(day).AddDate(0, 0, 1)),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum entries since day end: %w", err)
+	}
+	return balance - net, nil
+}
+
+// chargeInterest posts one day of interest on the account's balance at the
+// end of day to the equity account of its currency. A charge that would
+// take the balance past the approved limit is capped at the limit.
+func (s *OverdraftService) chargeInterest(ctx context.Context, accountID uuid.UUID, day time.Time) (bool, error) {
+	date := day.UTC().Format(time.DateOnly)
+	referenceID := overdraftInterestReference(accountID, date)
+	queries := s.store.Queries()
+	if _, err := queries.CheckTransactionIdempotency(ctx, referenceID); err == nil {
+		return false, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("failed to check idempotency: %w", err)
+	}
+	account, err := queries.GetAccount(ctx, repository.ToPgUUID(accountID))
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch account: %w", err)
+	}
+	// Most days a caught-up account checks ended in credit; skip them
+	// without locking the equity account. The charge rechecks under lock.
+	if account.OverdraftInterestBps <= 0 {
+		return false, nil
+	}
+	if dayEndBalance, err := balanceAtDayEnd(ctx, queries, accountID, account.Balance, day); err != nil {
+		return false, err
+	} else if dayEndBalance >= 0 {
+		return false, nil
+	}
+	currency := account.Currency
+	equityID, err := getEquityAccountID(currency)
+	if err != nil {
+		return false, err
+	}
+
+	var amount int64
+	transactionID := uuid.New()
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		accountIDs := []uuid.UUID{accountID, equityID}
+		sortUUIDs(accountIDs)
+		for _, id := range accountIDs {
+			if _, err := qtx.LockAccount(ctx, repository.ToPgUUID(id)); err != nil {
+				return fmt.Errorf("failed to lock account %s: %w", id, err)
+			}
+		}
+		row, err := qtx.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(accountID))
+		if err != nil {
+			return fmt.Errorf("failed to fetch account: %w", err)
+		}
+		if row.OverdraftInterestBps <= 0 {
+			return nil
+		}
+		dayEndBalance, err := balanceAtDayEnd(ctx, qtx, accountID, row.Balance, day)
+		if err != nil {
+			return err
+		}
+		if dayEndBalance >= 0 {
+			return nil
+		}
+
+		amount = dailyOverdraftInterest(dayEndBalance, row.OverdraftInterestBps)
+		capped := false
+		if headroom := row.Balance + row.OverdraftLimitMicros; amount > headroom {
+			amount, capped = headroom, true
+		}
+		if amount <= 0 {
+			amount = 0
+			return nil
+		}
+
+		metadata, err := json.Marshal(map[string]any{
+			"kind":           "overdraft_interest",
+			"account_id":     accountID,
+			"date":           date,
+			"balance_micros": dayEndBalance,
+			"interest_bps":   row.OverdraftInterestBps,
+			"capped":         capped,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		if _, err := qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
+			ID:          repository.ToPgUUID(transactionID),
+			Amount:      amount,
+			Currency:    currency,
+			Type:        domain.TxTypeFee,
+			Status:      domain.TxStatusPending,
+			ReferenceID: referenceID,
+			Metadata:    metadata,
+		}); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+		if err := s.audit.Write(ctx, qtx, "transaction", transactionID, nil, "created", "", domain.TxStatusPending, metadata); err != nil {
+			return err
+		}
+		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusProcessing, nil, "processing_started", nil); err != nil {
+			return fmt.Errorf("failed to transition transaction to processing: %w", err)
+		}
+		if err := postEntries(ctx, qtx, transactionID, accountID, equityID, amount); err != nil {
+			return err
+		}
+		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, nil, "completed", nil); err != nil {
+			return fmt.Errorf("failed to complete transaction: %w", err)
+		}
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventOverdraftInterest, map[string]any{
+			"transaction_id": transactionID,
+			"reference_id":   referenceID,
+			"account_id":     accountID,
+			"amount_micros":  amount,
+			"currency":       currency,
+			"date":           date,
+		})
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if amount == 0 {
+		return false, nil
+	}
+	observability.AddOverdraftInterest(currency, amount)
+	zap.L().Debug("overdraft interest charged", zap.String("account_id", accountID.String()), zap.Int64("amount_micros", amount), zap.String("date", date))
+	return true, nil
+}
+
+// dailyOverdraftInterest is one day of simple interest at an annual rate of
+// bps on a negative balance, rounded down to the micro.
+func dailyOverdraftInterest(balance int64, bps int32) int64 {
+	if balance >= 0 || bps <= 0 {
+		return 0
+	}
+	return decimal.NewFromInt(-balance).
+		Mul(decimal.NewFromInt32(bps)).
+		Div(decimal.NewFromInt(maxOverdraftInterestBps * 365)).
+		Floor().
+		IntPart()
+}
+
+func formatOverdraftTerms(limit int64, bps int32) string {
+	return fmt.Sprintf("limit=%d interest_bps=%d", limit, bps)
+}
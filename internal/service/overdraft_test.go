@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyOverdraftInterest(t *testing.T) {
+	require.Zero(t, dailyOverdraftInterest(100, 1000))
+	require.Zero(t, dailyOverdraftInterest(-100, 0))
+	// 10% a year on 3650 units is 1 unit a day.
+	require.Equal(t, int64(1_000_000), dailyOverdraftInterest(-3_650_000_000, 1000))
+	// Fractions of a micro are rounded down.
+	require.Equal(t, int64(0), dailyOverdraftInterest(-3649, 1000))
+	require.Equal(t, int64(1), dailyOverdraftInterest(-3650, 1000))
+}
+
+func TestCheckAvailableFunds(t *testing.T) {
+	require.NoError(t, checkAvailableFunds(100, 0, 0, 100))
+	require.ErrorIs(t, checkAvailableFunds(100, 10, 0, 100), models.ErrInsufficientFunds)
+	require.NoError(t, checkAvailableFunds(100, 10, 50, 140))
+	require.ErrorIs(t, checkAvailableFunds(-40, 0, 50, 11), models.ErrInsufficientFunds)
+}
+
+func TestSetOverdraftValidation(t *testing.T) {
+	svc := NewOverdraftService(panicStore{})
+	ctx := context.Background()
+
+	_, err := svc.SetOverdraft(ctx, uuid.New(), OverdraftTerms{LimitMicros: -1}, uuid.New(), "")
+	require.ErrorIs(t, err, ErrInvalidOverdraftTerms)
+	_, err = svc.SetOverdraft(ctx, uuid.New(), OverdraftTerms{InterestBps: 10001}, uuid.New(), "")
+	require.ErrorIs(t, err, ErrInvalidOverdraftTerms)
+	_, err = svc.SetOverdraft(ctx, uuid.MustParse(domain.SystemAccountUSD), OverdraftTerms{LimitMicros: 1}, uuid.New(), "")
+	require.ErrorIs(t, err, ErrInvalidOverdraftTerms)
+}
+
+func TestOverdraftAllowsApprovedNegativeBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	overdrafts := NewOverdraftService(store)
+	transfers := NewTransferService(store, NewMockExchangeRateService())
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	drawn := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 100}
+	require.NoError(t, repo.CreateAccount(ctx, drawn))
+	other := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repo.CreateAccount(ctx, other))
+
+	_, err := transfers.Transfer(ctx, drawn.ID, other.ID, 150, "overdraft-none")
+	require.ErrorIs(t, err, models.ErrInsufficientFunds)
+
+	account, err := overdrafts.SetOverdraft(ctx, drawn.ID, OverdraftTerms{LimitMicros: 3_650_000, InterestBps: 1000}, admin.ID, "approved line")
+	require.NoError(t, err)
+	require.Equal(t, int64(3_650_000), account.OverdraftLimit)
+
+	_, err = transfers.Transfer(ctx, drawn.ID, other.ID, 3_650_101, "overdraft-over")
+	require.ErrorIs(t, err, models.ErrInsufficientFunds)
+	_, err = transfers.Transfer(ctx, drawn.ID, other.ID, 3_650_100, "overdraft-full")
+	require.NoError(t, err)
+
+	_, err = overdrafts.SetOverdraft(ctx, drawn.ID, OverdraftTerms{LimitMicros: 1_000_000}, admin.ID, "")
+	require.ErrorIs(t, err, ErrOverdraftInUse)
+
+	// Pay back half so there is headroom for the interest charge.
+	_, err = transfers.Transfer(ctx, other.ID, drawn.ID, 1_825_000, "overdraft-repay")
+	require.NoError(t, err)
+
+	// The movements above were posted today, so today ends overdrawn.
+	day := time.Now().UTC()
+	charged, err := overdrafts.AccrueInterest(ctx, day)
+	require.NoError(t, err)
+	require.Equal(t, 1, charged)
+	charged, err = overdrafts.AccrueInterest(ctx, day)
+	require.NoError(t, err)
+	require.Zero(t, charged)
+
+	drawnDB, err := repo.GetAccount(ctx, drawn.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1_825_000-500), drawnDB.Balance)
+
+	var entries int
+	require.NoError(t, db.QueryRow(ctx, `SELECT COUNT(*) FROM entries e JOIN transactions t ON t.id = e.transaction_id WHERE t.reference_id = $1`,
+		"overdraft-interest:"+drawn.ID.String()+":"+day.Format(time.DateOnly)).Scan(&entries))
+	require.Equal(t, 2, entries)
+}
+
+func TestOverdraftInterestUsesDayEndBalance(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	overdrafts := NewOverdraftService(store)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	repaid := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD"}
+	late := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD"}
+	other := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 5_000_000}
+	for _, account := range []*models.Account{repaid, late, other} {
+		require.NoError(t, repo.CreateAccount(ctx, account))
+	}
+	for _, account := range []*models.Account{repaid, late} {
+		_, err := overdrafts.SetOverdraft(ctx, account.ID, OverdraftTerms{LimitMicros: 3_650_000, InterestBps: 1000}, admin.ID, "")
+		require.NoError(t, err)
+	}
+
+	day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	afterMidnight := day.AddDate(0, 0, 1).Add(10 * time.Minute)
+	// Overdrawn for the whole day and repaid just after it ended.
+	postEntriesAt(t, db, repaid.ID, other.ID, 1_825_000, day.Add(10*time.Hour))
+	postEntriesAt(t, db, other.ID, repaid.ID, 1_825_000, afterMidnight)
+	// In credit all day, overdrawn just after it ended.
+	postEntriesAt(t, db, late.ID, other.ID, 1_825_000, afterMidnight)
+
+	charged, err := overdrafts.AccrueInterest(ctx, day)
+	require.NoError(t, err)
+	require.Equal(t, 1, charged)
+
+	repaidDB, err := repo.GetAccount(ctx, repaid.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-500), repaidDB.Balance)
+	lateDB, err := repo.GetAccount(ctx, late.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1_825_000), lateDB.Balance)
+}
+
+func TestOverdraftInterestCatchesUpMissedDays(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	overdrafts := NewOverdraftService(store)
+	ctx := context.Background()
+
+	admin := &models.User{ID: uuid.New(), Username: "ops", Email: "ops@example.com", Role: "admin"}
+	require.NoError(t, repo.CreateUser(ctx, admin))
+	user := &models.User{ID: uuid.New(), Username: "ayo", Email: "ayo@example.com"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+	drawn := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD"}
+	other := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD"}
+	require.NoError(t, repo.CreateAccount(ctx, drawn))
+	require.NoError(t, repo.CreateAccount(ctx, other))
+	_, err := overdrafts.SetOverdraft(ctx, drawn.ID, OverdraftTerms{LimitMicros: 3_650_000, InterestBps: 1000}, admin.ID, "")
+	require.NoError(t, err)
+
+	day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	postEntriesAt(t, db, drawn.ID, other.ID, 1_825_000, day.AddDate(0, 0, -2).Add(10*time.Hour))
+
+	charged, err := overdrafts.AccrueInterest(ctx, day.AddDate(0, 0, -2))
+	require.NoError(t, err)
+	require.Equal(t, 1, charged)
+
+	// The worker was down for the next day; the run after it charges both.
+	charged, err = overdrafts.AccrueInterest(ctx, day)
+	require.NoError(t, err)
+	require.Equal(t, 2, charged)
+	charged, err = overdrafts.AccrueInterest(ctx, day)
+	require.NoError(t, err)
+	require.Zero(t, charged)
+
+	var dates []string
+	rows, err := db.Query(ctx, `SELECT reference_id FROM transactions WHERE reference_id LIKE $1 ORDER BY reference_id`,
+		"overdraft-interest:"+drawn.ID.String()+":%")
+	require.NoError(t, err)
+	for rows.Next() {
+		var reference string
+		require.NoError(t, rows.Scan(&reference))
+		dates = append(dates, reference[len(reference)-len(time.DateOnly):])
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"2026-01-13", "2026-01-14", "2026-01-15"}, dates)
+
+	drawnDB, err := repo.GetAccount(ctx, drawn.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1_825_000-3*500), drawnDB.Balance)
+}
+
+// postEntriesAt records a movement as if it had been posted at at, which
+// the service paths cannot do because entries are stamped on insert.
+func postEntriesAt(t *testing.T, db *pgxpool.Pool, from, to uuid.UUID, amount int64, at time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	transactionID := uuid.New()
+	_, err := repository.New(db).CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(transactionID),
+		Amount:      amount,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "backdated:" + transactionID.String(),
+	})
+	require.NoError(t, err)
+	for _, leg := range []struct {
+		accountID uuid.UUID
+		direction string
+		delta     int64
+	}{
+		{from, domain.DirectionDebit, -amount},
+		{to, domain.DirectionCredit, amount},
+	} {
+		_, err := db.Exec(ctx, `INSERT INTO entries (id, transaction_id, account_id, amount, direction, created_at) VALUES ($1, $2, $3, $4, $5, $6)`,
+			uuid.New(), transactionID, leg.accountID, amount, leg.direction, at)
+		require.NoError(t, err)
+		_, err = db.Exec(ctx, `UPDATE accounts SET balance = balance + $1 WHERE id = $2`, leg.delta, leg.accountID)
+		require.NoError(t, err)
+	}
+}
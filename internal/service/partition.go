@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// archiveExportPageSize is the number of entries read per page while
+	// exporting a partition.
+	archiveExportPageSize = 5000
+	// archiveClaimStaleAfter is how long an EXPORTING claim may go
+	// unfinished before another instance takes it over.
+	archiveClaimStaleAfter = 6 * time.Hour
+)
+
+var partitionNamePattern = regexp.MustCompile(`^entries_y(\d{4})m(\d{2})$`)
+
+// archiveColumns are the CSV columns of an archived partition.
+var archiveColumns = []string{"id", "transaction_id", "account_id", "currency", "amount", "direction", "created_at"}
+
+// PartitionService maintains the monthly partitions of entries: it keeps a
+// rolling horizon of future partitions, moves rows out of entries_default
+// into their month's partition, and archives partitions older than the
+// retention period to CSV files with a JSON manifest.
+type PartitionService struct {
+	store           QueryStore
+	horizonMonths   int
+	retentionMonths int
+	archiveDir      string
+}
+
+// NewPartitionService creates a partition service keeping 24 months of
+// future partitions. Archiving is off until WithArchive is called.
+func NewPartitionService(store QueryStore) *PartitionService {
+	return &PartitionService{
+		store:         store,
+		horizonMonths: 24,
+	}
+}
+
+// WithHorizon sets how many months ahead of the current one must have a
+// partition.
+func (s *PartitionService) WithHorizon(months int) *PartitionService {
+	if months > 0 {
+		s.horizonMonths = months
+	}
+	return s
+}
+
+// WithArchive enables archiving of partitions whose month ended more than
+// retentionMonths months ago into dir. A zero retention keeps every
+// partition.
+func (s *PartitionService) WithArchive(dir string, retentionMonths int) *PartitionService {
+	s.archiveDir = dir
+	s.retentionMonths = retentionMonths
+	return s
+}
+
+// entriesArchiveManifest describes an archived partition file.
+type entriesArchiveManifest struct {
+	Partition      string                 `json:"partition"`
+	PeriodStart    time.Time              `json:"period_start"`
+	PeriodEnd      time.Time              `json:"period_end"`
+	Format         string                 `json:"format"`
+	File           string                 `json:"file"`
+	Columns        []string               `json:"columns"`
+	RowCount       int64                  `json:"row_count"`
+	SHA256         string                 `json:"sha256"`
+	LedgerChecksum string                 `json:"ledger_checksum"`
+	CurrencyTotals []models.CurrencyTotal `json:"currency_totals"`
+	ArchivedAt     time.Time              `json:"archived_at"`
+}
+
+// Run performs one maintenance pass: it creates missing partitions up to
+// the horizon, moves stray rows out of entries_default, then archives the
+// partitions past retention.
+func (s *PartitionService) Run(ctx context.Context) error {
+	now := time.Now()
+	archived, err := s.store.Queries().ListArchivedPartitionNames(ctx)
+	if err != nil {
+		return fmt.Errorf("list archived partitions: %w", err)
+	}
+	archivedNames := make(map[string]bool, len(archived))
+	for _, name := range archived {
+		archivedNames[name] = true
+	}
+
+	if err := s.ensureHorizon(ctx, now); err != nil {
+		return err
+	}
+	if err := s.relocateDefaultRows(ctx, archivedNames); err != nil {
+		return err
+	}
+	return s.archiveExpired(ctx, now)
+}
+
+// ensureHorizon creates the partitions from the current month through the
+// horizon.
+func (s *PartitionService) ensureHorizon(ctx context.Context, now time.Time) error {
+	partitions, err := s.store.Queries().ListEntriesPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("list entries partitions: %w", err)
+	}
+	existing := make(map[string]bool, len(partitions))
+	for _, name := range partitions {
+		existing[name] = true
+	}
+
+	current := utcMonth(now)
+	for i := 0; i <= s.horizonMonths; i++ {
+		month := current.AddDate(0, i, 0)
+		if existing[entriesPartitionName(month)] {
+			continue
+		}
+		if err := s.createPartition(ctx, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relocateDefaultRows creates the partition of every month with rows in
+// entries_default, which moves those rows into it. Rows for a month that
+// was already archived are left in place and reported.
+func (s *PartitionService) relocateDefaultRows(ctx context.Context, archived map[string]bool) error {
+	months, err := s.store.Queries().ListEntriesDefaultMonths(ctx)
+	if err != nil {
+		return fmt.Errorf("list entries_default months: %w", err)
+	}
+	for _, row := range months {
+		month := row.Time
+		name := entriesPartitionName(month)
+		if archived[name] {
+			observability.IncrementPartitionOperation("stranded")
+			zap.L().Error("entries_default holds rows for an archived month",
+				zap.String("partition", name),
+			)
+			continue
+		}
+		if err := s.createPartition(ctx, month); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PartitionService) createPartition(ctx context.Context, month time.Time) error {
+	name := entriesPartitionName(month)
+	moved, err := s.store.Queries().CreateEntriesPartition(ctx, pgtype.Date{Time: month, Valid: true})
+	if err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	observability.IncrementPartitionOperation("created")
+	if moved > 0 {
+		observability.AddDefaultPartitionRowsMoved(moved)
+		zap.L().Warn("entries partition created from entries_default rows", zap.String("partition", name), zap.Int64("rows_moved", moved))
+		return nil
+	}
+	zap.L().Info("entries partition created", zap.String("partition", name))
+	return nil
+}
+
+// archiveExpired archives every partition whose month ended at least
+// retentionMonths months before the current month.
+func (s *PartitionService) archiveExpired(ctx context.Context, now time.Time) error {
+	if s.retentionMonths <= 0 || s.archiveDir == "" {
+		return nil
+	}
+	partitions, err := s.store.Queries().ListEntriesPartitions(ctx)
+	if err != nil {
+		return fmt.Errorf("list entries partitions: %w", err)
+	}
+	cutoff := utcMonth(now).AddDate(0, -s.retentionMonths, 0)
+	for _, name := range partitions {
+		month, ok := parseEntriesPartitionName(name)
+		if !ok || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if err := s.archivePartition(ctx, name, month, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivePartition exports a partition to CSV, writes its manifest, then
+// records the archived totals and drops the partition in one transaction.
+// Only months sealed by reconciliation whose entries still match the seal
+// are archived.
+func (s *PartitionService) archivePartition(ctx context.Context, name string, month, now time.Time) error {
+	queries := s.store.Queries()
+	monthDate := pgtype.Date{Time: month, Valid: true}
+	seal, err := queries.GetLedgerMonthSeal(ctx, monthDate)
+	if errors.Is(err, pgx.ErrNoRows) {
+		zap.L().Warn("partition not archived: month not sealed by reconciliation yet", zap.String("partition", name))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get ledger month seal for %s: %w", name, err)
+	}
+	if seal.Status != domain.LedgerSealStatusSealed {
+		zap.L().Warn("partition not archived: month seal is not intact", zap.String("partition", name), zap.String("seal_status", seal.Status))
+		return nil
+	}
+
+	periodStart := dateTimestamp(month)
+	periodEnd := dateTimestamp(month.AddDate(0, 1, 0))
+	claim, err := queries.ClaimEntriesArchive(ctx, repository.ClaimEntriesArchiveParams{
+		ID:            repository.ToPgUUID(uuid.New()),
+		PartitionName: name,
+		PeriodStart:   periodStart,
+		PeriodEnd:     periodEnd,
+		StaleBefore:   pgtype.Timestamptz{Time: now.Add(-archiveClaimStaleAfter), Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		zap.L().Info("partition archive already claimed", zap.String("partition", name))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("claim archive of %s: %w", name, err)
+	}
+
+	sum, err := queries.GetLedgerPeriodChecksum(ctx, repository.GetLedgerPeriodChecksumParams{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return fmt.Errorf("checksum %s: %w", name, err)
+	}
+	if sum.EntryCount != seal.EntryCount || sum.Checksum != seal.Checksum {
+		return fmt.Errorf("archive %s: entries no longer match the month seal", name)
+	}
+
+	if err := os.MkdirAll(s.archiveDir, 0o750); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+	filePath := filepath.Join(s.archiveDir, name+".csv")
+	export, err := s.exportPartition(ctx, filePath, periodStart, periodEnd)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", name, err)
+	}
+	if export.rows != seal.EntryCount {
+		return fmt.Errorf("archive %s: exported %d rows, seal has %d", name, export.rows, seal.EntryCount)
+	}
+
+	manifestPath := filepath.Join(s.archiveDir, name+".manifest.json")
+	manifest := entriesArchiveManifest{
+		Partition:      name,
+		PeriodStart:    periodStart.Time,
+		PeriodEnd:      periodEnd.Time,
+		Format:         "csv",
+		File:           filepath.Base(filePath),
+		Columns:        archiveColumns,
+		RowCount:       export.rows,
+		SHA256:         export.sha256,
+		LedgerChecksum: seal.Checksum,
+		CurrencyTotals: export.currencyTotals(),
+		ArchivedAt:     now.UTC(),
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest for %s: %w", name, err)
+	}
+	if err := writeFileAtomic(manifestPath, func(w io.Writer) error {
+		_, err := w.Write(manifestJSON)
+		return err
+	}); err != nil {
+		return fmt.Errorf("write manifest for %s: %w", name, err)
+	}
+
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		for _, total := range manifest.CurrencyTotals {
+			if err := qtx.CreateEntriesArchiveCurrencyTotal(ctx, repository.CreateEntriesArchiveCurrencyTotalParams{
+				ArchiveID:    claim.ID,
+				Currency:     total.Currency,
+				EntryCount:   total.EntryCount,
+				DebitMicros:  total.DebitMicros,
+				CreditMicros: total.CreditMicros,
+			}); err != nil {
+				return fmt.Errorf("record archive currency total: %w", err)
+			}
+		}
+		for accountID, net := range export.accountNets {
+			if err := qtx.AddEntriesArchivedAccountTotal(ctx, repository.AddEntriesArchivedAccountTotalParams{
+				AccountID: accountID,
+				NetMicros: net,
+			}); err != nil {
+				return fmt.Errorf("record archived account total: %w", err)
+			}
+		}
+		if _, err := qtx.CompleteEntriesArchive(ctx, repository.CompleteEntriesArchiveParams{
+			ID:           claim.ID,
+			RowCount:     export.rows,
+			FilePath:     textParam(filePath),
+			ManifestPath: textParam(manifestPath),
+			Sha256:       textParam(export.sha256),
+		}); err != nil {
+			return fmt.Errorf("complete archive: %w", err)
+		}
+		if _, err := qtx.MarkLedgerMonthSealArchived(ctx, monthDate); err != nil {
+			return fmt.Errorf("mark month seal archived: %w", err)
+		}
+		if err := qtx.DropEntriesPartition(ctx, repository.DropEntriesPartitionParams{
+			Month:        monthDate,
+			ArchivedRows: export.rows,
+		}); err != nil {
+			return fmt.Errorf("drop partition: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("archive %s: %w", name, err)
+	}
+	observability.IncrementPartitionOperation("archived")
+	zap.L().Info("entries partition archived",
+		zap.String("partition", name),
+		zap.Int64("rows", export.rows),
+		zap.String("file", filePath),
+		zap.String("sha256", export.sha256),
+	)
+	return nil
+}
+
+// partitionExport is the result of writing a partition to CSV.
+type partitionExport struct {
+	rows        int64
+	sha256      string
+	totals      map[string]*models.CurrencyTotal
+	accountNets map[pgtype.UUID]int64
+}
+
+func (e partitionExport) currencyTotals() []models.CurrencyTotal {
+	out := make([]models.CurrencyTotal, 0, len(e.totals))
+	for _, total := range e.totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+// exportPartition writes the entries of [periodStart, periodEnd) to path as
+// CSV, page by page, and totals them per currency and per account.
+func (s *PartitionService) exportPartition(ctx context.Context, path string, periodStart, periodEnd pgtype.Timestamptz) (partitionExport, error) {
+	export := partitionExport{
+		totals:      make(map[string]*models.CurrencyTotal),
+		accountNets: make(map[pgtype.UUID]int64),
+	}
+	hash := sha256.New()
+	err := writeFileAtomic(path, func(w io.Writer) error {
+		out := csv.NewWriter(io.MultiWriter(w, hash))
+		if err := out.Write(archiveColumns); err != nil {
+			return err
+		}
+		afterCreatedAt := periodStart
+		afterID := repository.ToPgUUID(uuid.Nil)
+		for {
+			rows, err := s.store.Queries().ListEntriesForArchive(ctx, repository.ListEntriesForArchiveParams{
+				PeriodStart:    periodStart,
+				PeriodEnd:      periodEnd,
+				AfterCreatedAt: afterCreatedAt,
+				AfterID:        afterID,
+				RowLimit:       archiveExportPageSize,
+			})
+			if err != nil {
+				return fmt.Errorf("list entries: %w", err)
+			}
+			for _, row := range rows {
+				if err := out.Write([]string{
+					repository.FromPgUUID(row.ID).String(),
+					repository.FromPgUUID(row.TransactionID).String(),
+					repository.FromPgUUID(row.AccountID).String(),
+					row.Currency,
+					strconv.FormatInt(row.Amount, 10),
+					row.Direction,
+					row.CreatedAt.Time.UTC().Format(time.RFC3339Nano),
+				}); err != nil {
+					return err
+				}
+				export.add(row)
+			}
+			if len(rows) < archiveExportPageSize {
+				break
+			}
+			last := rows[len(rows)-1]
+			afterCreatedAt, afterID = last.CreatedAt, last.ID
+		}
+		out.Flush()
+		return out.Error()
+	})
+	if err != nil {
+		return partitionExport{}, err
+	}
+	export.sha256 = hex.EncodeToString(hash.Sum(nil))
+	return export, nil
+}
+
+func (e *partitionExport) add(row repository.ListEntriesForArchiveRow) {
+	e.rows++
+	total, ok := e.totals[row.Currency]
+	if !ok {
+		total = &models.CurrencyTotal{Currency: row.Currency}
+		e.totals[row.Currency] = total
+	}
+	total.EntryCount++
+	if row.Direction == domain.DirectionCredit {
+		total.CreditMicros += row.Amount
+		e.accountNets[row.AccountID] += row.Amount
+	} else {
+		total.DebitMicros += row.Amount
+		e.accountNets[row.AccountID] -= row.Amount
+	}
+	total.NetMicros = total.CreditMicros - total.DebitMicros
+}
+
+// writeFileAtomic writes path through a temporary file that is synced and
+// renamed into place only once write succeeds.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", tmp, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("sync %s: %w", tmp, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("close %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", tmp, err)
+	}
+	return nil
+}
+
+// entriesPartitionName returns the name of the partition holding month.
+func entriesPartitionName(month time.Time) string {
+	return fmt.Sprintf("entries_y%04dm%02d", month.Year(), int(month.Month()))
+}
+
+// parseEntriesPartitionName returns the month of a monthly partition name.
+func parseEntriesPartitionName(name string) (time.Time, bool) {
+	m := partitionNamePattern.FindStringSubmatch(name)
+	if m == nil {
+		return time.Time{}, false
+	}
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntriesPartitionName(t *testing.T) {
+	month := time.Date(2031, time.March, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, "entries_y2031m03", entriesPartitionName(month))
+	parsed, ok := parseEntriesPartitionName("entries_y2031m03")
+	require.True(t, ok)
+	require.Equal(t, month, parsed)
+	_, ok = parseEntriesPartitionName("entries_default")
+	require.False(t, ok)
+	_, ok = parseEntriesPartitionName("entries_y2031m13")
+	require.False(t, ok)
+}
+
+func TestPartitionMaintenanceMovesDefaultRowsAndArchives(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repoSvc := repository.NewRepository(db)
+	queries := repository.New(db)
+	store := repository.NewStore(db)
+
+	user := &models.User{ID: uuid.New(), Username: "partitions", Email: "partitions@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	accountA := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 100_000}
+	accountB := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: -100_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, accountA))
+	require.NoError(t, repoSvc.CreateAccount(ctx, accountB))
+
+	// Archiving drops partitions other tests write to; put them back.
+	now := time.Now().UTC()
+	oldMonth := utcMonth(now).AddDate(0, -20, 0)
+	t.Cleanup(func() {
+		for month := oldMonth; month.Before(utcMonth(now)); month = month.AddDate(0, 1, 0) {
+			_, _ = queries.CreateEntriesPartition(context.Background(), pgtype.Date{Time: month, Valid: true})
+		}
+	})
+
+	// An old transaction whose month may have no partition yet.
+	transactionID := uuid.New()
+	_, err := queries.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(transactionID),
+		Amount:      100_000,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "partition-old",
+	})
+	require.NoError(t, err)
+	oldAt := oldMonth.AddDate(0, 0, 10)
+	for _, entry := range []struct {
+		accountID uuid.UUID
+		direction string
+	}{{accountA.ID, domain.DirectionCredit}, {accountB.ID, domain.DirectionDebit}} {
+		_, err := db.Exec(ctx, "INSERT INTO entries (id, transaction_id, account_id, amount, direction, created_at) VALUES ($1,$2,$3,$4,$5,$6)",
+			repository.ToPgUUID(uuid.New()), repository.ToPgUUID(transactionID), repository.ToPgUUID(entry.accountID), int64(100_000), entry.direction, oldAt)
+		require.NoError(t, err)
+	}
+
+	// A far-future entry lands in entries_default until its partition exists.
+	farMonth := utcMonth(now).AddDate(15, 0, 0)
+	farName := entriesPartitionName(farMonth)
+	_, err = db.Exec(ctx, "DROP TABLE IF EXISTS "+farName)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(context.Background(), "DROP TABLE IF EXISTS "+farName)
+	})
+	futureTx := uuid.New()
+	_, err = queries.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(futureTx),
+		Amount:      1,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "partition-future",
+	})
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "INSERT INTO entries (id, transaction_id, account_id, amount, direction, created_at) VALUES ($1,$2,$3,1,'credit',$4), ($5,$2,$6,1,'debit',$4)",
+		repository.ToPgUUID(uuid.New()), repository.ToPgUUID(futureTx), repository.ToPgUUID(accountA.ID), farMonth.AddDate(0, 0, 3),
+		repository.ToPgUUID(uuid.New()), repository.ToPgUUID(accountB.ID))
+	require.NoError(t, err)
+
+	require.NoError(t, NewPartitionService(store).WithHorizon(3).Run(ctx))
+	var defaultRows int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM entries_default").Scan(&defaultRows))
+	require.Zero(t, defaultRows)
+	partitions, err := queries.ListEntriesPartitions(ctx)
+	require.NoError(t, err)
+	require.Contains(t, partitions, farName)
+	require.Contains(t, partitions, entriesPartitionName(oldMonth))
+	require.Contains(t, partitions, entriesPartitionName(utcMonth(now).AddDate(0, 3, 0)))
+
+	// Archiving waits for reconciliation to seal the month.
+	dir := t.TempDir()
+	archiver := NewPartitionService(store).WithArchive(dir, 12)
+	require.NoError(t, archiver.Run(ctx))
+	_, err = os.Stat(filepath.Join(dir, entriesPartitionName(oldMonth)+".csv"))
+	require.True(t, os.IsNotExist(err))
+
+	reconcileSvc := NewReconciliationService(store)
+	require.NoError(t, reconcileSvc.Run(ctx))
+	require.NoError(t, archiver.Run(ctx))
+
+	oldName := entriesPartitionName(oldMonth)
+	partitions, err = queries.ListEntriesPartitions(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, partitions, oldName)
+
+	manifestJSON, err := os.ReadFile(filepath.Join(dir, oldName+".manifest.json"))
+	require.NoError(t, err)
+	var manifest entriesArchiveManifest
+	require.NoError(t, json.Unmarshal(manifestJSON, &manifest))
+	require.Equal(t, oldName, manifest.Partition)
+	require.Equal(t, int64(2), manifest.RowCount)
+	require.Len(t, manifest.CurrencyTotals, 1)
+	require.Equal(t, int64(100_000), manifest.CurrencyTotals[0].CreditMicros)
+
+	f, err := os.Open(filepath.Join(dir, manifest.File))
+	require.NoError(t, err)
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		lines++
+	}
+	require.Equal(t, 3, lines, "header and two entries")
+
+	var sealStatus string
+	require.NoError(t, db.QueryRow(ctx, "SELECT status FROM ledger_month_seals WHERE month = $1", pgtype.Date{Time: oldMonth, Valid: true}).Scan(&sealStatus))
+	require.Equal(t, domain.LedgerSealStatusArchived, sealStatus)
+
+	// Both reconciliation scopes still account for the archived entries.
+	require.NoError(t, reconcileSvc.Run(ctx))
+	runs, err := reconcileSvc.ListRuns(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Equal(t, domain.RunStatusPassed, runs[0].Status)
+	triggered, err := reconcileSvc.TriggerRun(ctx, user.ID, domain.RunScopeFull)
+	require.NoError(t, err)
+	var full *models.ReconciliationRun
+	require.Eventually(t, func() bool {
+		full, err = reconcileSvc.GetRun(ctx, triggered.ID)
+		require.NoError(t, err)
+		return full.Status != domain.RunStatusRunning
+	}, 10*time.Second, 20*time.Millisecond)
+	require.Equal(t, domain.RunStatusPassed, full.Status)
+	require.Equal(t, runs[0].CurrencyTotals, full.CurrencyTotals)
+}
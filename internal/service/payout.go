@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
 	"github.com/ayo6706/payment-multicurrency/internal/gateway"
 	"github.com/ayo6706/payment-multicurrency/internal/models"
 	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -24,38 +26,188 @@ type PayoutService struct {
 	store   QueryStore
 	gateway gateway.Gateway
 	audit   *AuditService
+	// currencyGateways routes payouts in a currency to a dedicated gateway,
+	// such as a bank file channel, instead of the default one.
+	currencyGateways map[string]gateway.Gateway
+	slots            chan struct{}
+	sendTimeout      time.Duration
+	// approvalThresholds maps currency to the largest amount, in micros,
+	// that may be dispatched without a second admin's approval.
+	approvalThresholds map[string]int64
+	limits             *LimitService
+	kyc                *KYCService
+	screening          *ScreeningService
 }
 
 var (
 	ErrPayoutNotFound              = errors.New("payout not found")
 	ErrPayoutNotInManualReview     = errors.New("payout is not in manual review")
 	ErrInvalidManualReviewDecision = errors.New("invalid manual review decision")
+	ErrPayoutNotAwaitingApproval   = errors.New("payout is not awaiting approval")
+	ErrPayoutSelfApproval          = errors.New("payout cannot be reviewed by its requester")
+	ErrPayoutRequesterRequired     = errors.New("payout above approval threshold requires a requester")
+	ErrPayoutDestinationConflict   = errors.New("destination and beneficiary_id are mutually exclusive")
 )
 
-const stalePayoutRecoveryWindow = 2 * time.Minute
+const (
+	stalePayoutRecoveryWindow = 2 * time.Minute
+	defaultPayoutConcurrency  = 4
+	defaultPayoutSendTimeout  = 30 * time.Second
+)
 
 func NewPayoutService(store QueryStore, gw gateway.Gateway) *PayoutService {
 	return &PayoutService{
-		store:   store,
-		gateway: gw,
-		audit:   NewAuditService(store),
+		store:       store,
+		gateway:     gw,
+		audit:       NewAuditService(store),
+		slots:       make(chan struct{}, defaultPayoutConcurrency),
+		sendTimeout: defaultPayoutSendTimeout,
+	}
+}
+
+// WithConcurrency bounds how many gateway calls this instance runs at once.
+// Call before processing starts.
+func (s *PayoutService) WithConcurrency(n int) *PayoutService {
+	if n > 0 {
+		s.slots = make(chan struct{}, n)
+	}
+	return s
+}
+
+// WithSendTimeout bounds a single gateway call. It must stay below the stale
+// recovery window so a timed-out call is never mistaken for a live one.
+func (s *PayoutService) WithSendTimeout(timeout time.Duration) *PayoutService {
+	if timeout > 0 && timeout < stalePayoutRecoveryWindow {
+		s.sendTimeout = timeout
+	}
+	return s
+}
+
+// WithCurrencyGateway routes payouts in currency through gw instead of the
+// default gateway. Call before processing starts.
+func (s *PayoutService) WithCurrencyGateway(currency string, gw gateway.Gateway) *PayoutService {
+	if gw == nil {
+		return s
+	}
+	if s.currencyGateways == nil {
+		s.currencyGateways = make(map[string]gateway.Gateway)
+	}
+	s.currencyGateways[strings.ToUpper(strings.TrimSpace(currency))] = gw
+	return s
+}
+
+// gatewayFor returns the gateway that handles payouts in currency.
+func (s *PayoutService) gatewayFor(currency string) gateway.Gateway {
+	if gw, ok := s.currencyGateways[currency]; ok {
+		return gw
+	}
+	return s.gateway
+}
+
+// WithApprovalThresholds sets per-currency amounts above which a payout waits
+// in AWAITING_APPROVAL for a second admin. Currencies without an entry never
+// require approval.
+func (s *PayoutService) WithApprovalThresholds(thresholds map[string]int64) *PayoutService {
+	s.approvalThresholds = make(map[string]int64, len(thresholds))
+	for currency, limit := range thresholds {
+		s.approvalThresholds[strings.ToUpper(strings.TrimSpace(currency))] = limit
 	}
+	return s
+}
+
+// WithLimits checks payout requests against transaction limits before
+// funds are locked.
+func (s *PayoutService) WithLimits(limits *LimitService) *PayoutService {
+	s.limits = limits
+	return s
+}
+
+// WithKYC refuses payouts from users whose KYC tier does not allow them.
+func (s *PayoutService) WithKYC(kyc *KYCService) *PayoutService {
+	s.kyc = kyc
+	return s
+}
+
+// requiresApproval reports whether amount exceeds the currency's threshold.
+func (s *PayoutService) requiresApproval(currency string, amount int64) bool {
+	limit, ok := s.approvalThresholds[currency]
+	return ok && amount > limit
 }
 
 // PayoutDestinationInput represents the external destination payload expected from clients.
+// Scheme may be omitted; it is inferred from whichever account identifier is present.
 type PayoutDestinationInput struct {
-	IBAN string `json:"iban"`
-	Name string `json:"name"`
+	Scheme        string `json:"scheme,omitempty"`
+	Name          string `json:"name"`
+	IBAN          string `json:"iban,omitempty"`
+	BIC           string `json:"bic,omitempty"`
+	RoutingNumber string `json:"routing_number,omitempty"`
+	AccountNumber string `json:"account_number,omitempty"`
+	SortCode      string `json:"sort_code,omitempty"`
 }
 
-// Validate ensures the destination contains the required fields.
-func (d PayoutDestinationInput) Validate() error {
-	if d.IBAN == "" {
-		return errors.New("destination.iban is required")
+// Normalized returns a copy with identifiers stripped of separators and the
+// scheme filled in, in the form that is validated and stored.
+func (d PayoutDestinationInput) Normalized() PayoutDestinationInput {
+	d.Name = strings.TrimSpace(d.Name)
+	d.IBAN = domain.NormalizeAccountIdentifier(d.IBAN)
+	d.BIC = domain.NormalizeAccountIdentifier(d.BIC)
+	d.RoutingNumber = domain.NormalizeAccountIdentifier(d.RoutingNumber)
+	d.AccountNumber = domain.NormalizeAccountIdentifier(d.AccountNumber)
+	d.SortCode = domain.NormalizeAccountIdentifier(d.SortCode)
+	d.Scheme = strings.ToUpper(strings.TrimSpace(d.Scheme))
+	if d.Scheme == "" {
+		switch {
+		case d.IBAN != "":
+			d.Scheme = domain.SchemeIBAN
+		case d.RoutingNumber != "":
+			d.Scheme = domain.SchemeUSACH
+		case d.SortCode != "":
+			d.Scheme = domain.SchemeUKSortCode
+		}
 	}
+	return d
+}
+
+// Validate ensures the destination names a payee and carries well-formed
+// account details for its scheme.
+func (d PayoutDestinationInput) Validate() error {
+	d = d.Normalized()
 	if d.Name == "" {
 		return errors.New("destination.name is required")
 	}
+	switch d.Scheme {
+	case domain.SchemeIBAN:
+		if d.IBAN == "" {
+			return errors.New("destination.iban is required")
+		}
+		if err := domain.ValidateIBAN(d.IBAN); err != nil {
+			return fmt.Errorf("destination.%w", err)
+		}
+		if d.BIC != "" {
+			if err := domain.ValidateBIC(d.BIC); err != nil {
+				return fmt.Errorf("destination.%w", err)
+			}
+		}
+	case domain.SchemeUSACH:
+		if err := domain.ValidateABARoutingNumber(d.RoutingNumber); err != nil {
+			return fmt.Errorf("destination.%w", err)
+		}
+		if err := domain.ValidateUSAccountNumber(d.AccountNumber); err != nil {
+			return fmt.Errorf("destination.%w", err)
+		}
+	case domain.SchemeUKSortCode:
+		if err := domain.ValidateSortCode(d.SortCode); err != nil {
+			return fmt.Errorf("destination.%w", err)
+		}
+		if err := domain.ValidateUKAccountNumber(d.AccountNumber); err != nil {
+			return fmt.Errorf("destination.%w", err)
+		}
+	case "":
+		return errors.New("destination.iban is required")
+	default:
+		return fmt.Errorf("destination.scheme %q is not supported", d.Scheme)
+	}
 	return nil
 }
 
@@ -66,6 +218,11 @@ type RequestPayoutRequest struct {
 	Currency     string
 	Destination  PayoutDestinationInput
 	ReferenceID  string
+	// BeneficiaryID selects a saved destination instead of Destination.
+	BeneficiaryID *uuid.UUID
+	// RequestedBy is the admin creating the payout. It is required for
+	// payouts above the approval threshold so the approver can be checked.
+	RequestedBy *uuid.UUID
 }
 
 // PayoutResponse represents the response from a payout request.
@@ -104,9 +261,22 @@ func (s *PayoutService) RequestPayout(ctx context.Context, req RequestPayoutRequ
 	if req.ReferenceID == "" {
 		return nil, errors.New("reference_id is required")
 	}
-	if err := req.Destination.Validate(); err != nil {
+	if req.BeneficiaryID != nil {
+		if req.Destination != (PayoutDestinationInput{}) {
+			return nil, ErrPayoutDestinationConflict
+		}
+	} else if err := req.Destination.Validate(); err != nil {
 		return nil, err
 	}
+	needsApproval := s.requiresApproval(req.Currency, req.AmountMicros)
+	if needsApproval && req.RequestedBy == nil {
+		return nil, ErrPayoutRequesterRequired
+	}
+	status := domain.PayoutStatusPending
+	if needsApproval {
+		status = domain.PayoutStatusAwaitingApproval
+	}
+	releaseStatus := status
 
 	queries := s.store.Queries()
 
@@ -129,6 +299,17 @@ func (s *PayoutService) RequestPayout(ctx context.Context, req RequestPayoutRequ
 		return nil, fmt.Errorf("failed to check idempotency: %w", err)
 	}
 
+	reservation, err := s.limits.ReserveForAccount(ctx, req.AccountID, domain.TxTypePayout, req.AmountMicros)
+	if err != nil {
+		return nil, err
+	}
+	// Kept on the payout so a rejection or failure gives the usage back.
+	limitReservation, err := reservation.Record()
+	if err != nil {
+		reservation.Release(ctx)
+		return nil, fmt.Errorf("failed to record limit reservation: %w", err)
+	}
+
 	transactionID := uuid.New()
 	payoutID := uuid.New()
 	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
@@ -140,10 +321,15 @@ func (s *PayoutService) RequestPayout(ctx context.Context, req RequestPayoutRequ
 			}
 			return fmt.Errorf("failed to lock account: %w", err)
 		}
+		if err := checkAccountDebit(req.AccountID, accountRow.Status); err != nil {
+			return err
+		}
+		if err := s.kyc.checkPayout(ctx, qtx, req.AccountID); err != nil {
+			return err
+		}
 
-		availableBalance := accountRow.Balance - accountRow.LockedMicros
-		if availableBalance < req.AmountMicros {
-			return models.ErrInsufficientFunds
+		if err := checkAvailableFunds(accountRow.Balance, accountRow.LockedMicros, accountRow.OverdraftLimitMicros, req.AmountMicros); err != nil {
+			return err
 		}
 
 		// Verify currency matches
@@ -163,10 +349,38 @@ func (s *PayoutService) RequestPayout(ctx context.Context, req RequestPayoutRequ
 			return err
 		}
 
+		destination := req.Destination.Normalized()
+		var beneficiaryID pgtype.UUID
+		if req.BeneficiaryID != nil {
+			account, err := qtx.GetAccount(ctx, repository.ToPgUUID(req.AccountID))
+			if err != nil {
+				return fmt.Errorf("failed to load account owner: %w", err)
+			}
+			destination, err = resolveBeneficiary(ctx, qtx, *req.BeneficiaryID, repository.FromPgUUID(account.UserID), time.Now())
+			if err != nil {
+				return err
+			}
+			beneficiaryID = repository.ToPgUUID(*req.BeneficiaryID)
+		}
+
+		// A payee that matches a sanctions list holds the payout for review
+		// whatever status it would otherwise take.
+		hit, err := s.screening.screen(ctx, qtx, destination.Name)
+		if err != nil {
+			return err
+		}
+		if hit != nil {
+			status = domain.PayoutStatusScreeningHold
+		}
+
 		// Create transaction record
-		metadata, err := json.Marshal(map[string]any{
-			"destination": req.Destination,
-		})
+		txMetadata := map[string]any{
+			"destination": destination,
+		}
+		if req.BeneficiaryID != nil {
+			txMetadata["beneficiary_id"] = *req.BeneficiaryID
+		}
+		metadata, err := json.Marshal(txMetadata)
 		if err != nil {
 			return fmt.Errorf("failed to encode metadata: %w", err)
 		}
@@ -184,39 +398,84 @@ func (s *PayoutService) RequestPayout(ctx context.Context, req RequestPayoutRequ
 			return fmt.Errorf("failed to create transaction: %w", err)
 		}
 
-		if err := s.audit.Write(ctx, qtx, "transaction", transactionID, nil, "created", "", domain.TxStatusPending, metadata); err != nil {
+		if err := s.audit.Write(ctx, qtx, "transaction", transactionID, req.RequestedBy, "created", "", domain.TxStatusPending, metadata); err != nil {
 			return err
 		}
 
 		// Create payout record
-		_, err = qtx.InsertPayout(ctx, repository.InsertPayoutParams{
-			ID:            repository.ToPgUUID(payoutID),
-			TransactionID: repository.ToPgUUID(transactionID),
-			AccountID:     repository.ToPgUUID(req.AccountID),
-			AmountMicros:  req.AmountMicros,
-			Currency:      req.Currency,
-			Status:        domain.PayoutStatusPending,
+		var requestedBy pgtype.UUID
+		if req.RequestedBy != nil {
+			requestedBy = repository.ToPgUUID(*req.RequestedBy)
+		}
+		payoutRow, err := qtx.InsertPayout(ctx, repository.InsertPayoutParams{
+			ID:               repository.ToPgUUID(payoutID),
+			TransactionID:    repository.ToPgUUID(transactionID),
+			AccountID:        repository.ToPgUUID(req.AccountID),
+			AmountMicros:     req.AmountMicros,
+			Currency:         req.Currency,
+			Status:           status,
+			RequestedBy:      requestedBy,
+			BeneficiaryID:    beneficiaryID,
+			LimitReservation: limitReservation,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create payout: %w", err)
 		}
 
-		return nil
+		if hit != nil {
+			recordScreeningHit("payout", hit)
+			return s.holdForScreening(ctx, qtx, payoutRow, hit, releaseStatus, req.RequestedBy)
+		}
+
+		if needsApproval {
+			// Funds stay locked while the payout waits; the worker only claims
+			// PENDING rows, so nothing is dispatched until a second admin approves.
+			if err := s.audit.Write(ctx, qtx, "payout", payoutID, req.RequestedBy, "approval_requested", "", status, metadata); err != nil {
+				return err
+			}
+			return writePayoutEvent(ctx, qtx, outbox.EventPayoutAwaitingApproval, payoutRow, map[string]any{"requested_by": *req.RequestedBy})
+		}
+
+		// Dispatch happens once this transaction commits: NOTIFY wakes a
+		// listening worker immediately, the outbox event is the durable path.
+		if err := writePayoutEvent(ctx, qtx, outbox.EventPayoutRequested, payoutRow, nil); err != nil {
+			return err
+		}
+		return notifyPayoutReady(ctx, qtx, payoutID)
 	})
 	if err != nil {
+		reservation.Release(ctx)
 		return nil, err
 	}
 
+	if status == domain.PayoutStatusScreeningHold {
+		return &PayoutResponse{
+			PayoutID: payoutID,
+			Status:   status,
+			Message:  "Payout held for sanctions screening review",
+		}, nil
+	}
+	if needsApproval {
+		return &PayoutResponse{
+			PayoutID: payoutID,
+			Status:   status,
+			Message:  "Payout awaiting approval",
+		}, nil
+	}
 	return &PayoutResponse{
 		PayoutID: payoutID,
-		Status:   domain.PayoutStatusPending,
+		Status:   status,
 		Message:  "Payout queued for processing",
 	}, nil
 }
 
 // ProcessPayouts processes a batch of pending payouts.
-// It fetches pending payouts using SKIP LOCKED, calls the gateway,
-// and updates the payout status and ledger accordingly.
+// It fetches pending payouts using SKIP LOCKED, sends up to the configured
+// concurrency to the gateway at once, and updates the payout status and
+// ledger accordingly. It returns once every started send has settled.
+//
+// Canceling ctx stops new sends: claims that have not started are requeued,
+// while in-flight calls run to completion bounded by the send timeout.
 func (s *PayoutService) ProcessPayouts(ctx context.Context, batchSize int32) error {
 	if err := s.recoverStaleProcessingPayouts(ctx, batchSize); err != nil {
 		return err
@@ -230,51 +489,160 @@ func (s *PayoutService) ProcessPayouts(ctx context.Context, batchSize int32) err
 		return nil
 	}
 
-	queries := s.store.Queries()
+	var wg sync.WaitGroup
+	defer wg.Wait()
 	for i, payout := range claimed {
-		if err := ctx.Err(); err != nil {
-			if requeueErr := s.requeueClaimedPayouts(context.Background(), claimed[i:]); requeueErr != nil {
+		if err := s.acquireSlot(ctx); err != nil {
+			if requeueErr := s.requeueClaimedPayouts(context.WithoutCancel(ctx), claimed[i:]); requeueErr != nil {
 				zap.L().Error("failed to requeue claimed payouts on context cancellation", zap.Error(requeueErr))
 			}
 			return err
 		}
+		wg.Add(1)
+		go func(payout repository.Payout) {
+			defer wg.Done()
+			defer s.releaseSlot()
+			s.sendClaimedPayout(context.WithoutCancel(ctx), payout)
+		}(payout)
+	}
 
-		payoutID := repository.FromPgUUID(payout.ID)
-		accountID := repository.FromPgUUID(payout.AccountID)
+	return nil
+}
 
-		txRow, err := queries.GetTransaction(ctx, payout.TransactionID)
-		if err != nil {
-			s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, "failed to fetch transaction metadata")
-			continue
+// ProcessPayout dispatches a single payout as soon as its dispatch event arrives.
+// It is a no-op when the payout is no longer PENDING or another worker holds it.
+// A slot is taken before claiming so waiting dispatches never hold claims.
+func (s *PayoutService) ProcessPayout(ctx context.Context, payoutID uuid.UUID) error {
+	if err := s.acquireSlot(ctx); err != nil {
+		return err
+	}
+	defer s.releaseSlot()
+	return s.processPayout(ctx, payoutID)
+}
+
+// TryProcessPayout takes a free slot without waiting and returns a function
+// that runs ProcessPayout's work in it and then releases it. It returns nil
+// when every slot is busy, leaving the payout PENDING for the next sweep.
+func (s *PayoutService) TryProcessPayout(payoutID uuid.UUID) func(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return nil
+	}
+	return func(ctx context.Context) error {
+		defer s.releaseSlot()
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		return s.processPayout(ctx, payoutID)
+	}
+}
 
-		destination := extractDestination(txRow.Metadata)
-		gatewayDestination := formatDestination(destination)
-		gatewayRef, err := s.gateway.SendPayout(ctx, gatewayDestination, payout.AmountMicros, payout.Currency)
+func (s *PayoutService) processPayout(ctx context.Context, payoutID uuid.UUID) error {
+	var (
+		payout  repository.Payout
+		claimed bool
+	)
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.GetPendingPayoutForUpdate(ctx, repository.ToPgUUID(payoutID))
 		if err != nil {
-			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-				if requeueErr := s.requeueClaimedPayouts(context.Background(), []repository.Payout{payout}); requeueErr != nil {
-					zap.L().Error("failed to requeue payout after gateway cancellation", zap.Error(requeueErr), zap.String("payout_id", payoutID.String()))
-				}
-				return err
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
 			}
-			s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, err.Error())
-			continue
+			return fmt.Errorf("failed to load pending payout: %w", err)
 		}
+		payout, err = s.claimPayout(ctx, qtx, row)
+		if err != nil {
+			return err
+		}
+		claimed = true
+		return nil
+	})
+	if err != nil || !claimed {
+		return err
+	}
+	s.sendClaimedPayout(context.WithoutCancel(ctx), payout)
+	return nil
+}
+
+func (s *PayoutService) acquireSlot(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *PayoutService) releaseSlot() {
+	<-s.slots
+}
+
+// sendClaimedPayout submits a PROCESSING payout to the gateway and settles the result.
+// Gateway failures are recorded on the payout. A call that times out may have
+// reached the gateway, so the payout stays PROCESSING for stale recovery to confirm.
+func (s *PayoutService) sendClaimedPayout(ctx context.Context, payout repository.Payout) {
+	payoutID := repository.FromPgUUID(payout.ID)
+	accountID := repository.FromPgUUID(payout.AccountID)
 
-		if err := s.handlePayoutSuccess(ctx, payoutID, accountID, payout.AmountMicros, payout.Currency, payout.TransactionID, gatewayRef); err != nil {
+	txRow, err := s.store.Queries().GetTransaction(ctx, payout.TransactionID)
+	if err != nil {
+		s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, "failed to fetch transaction metadata")
+		return
+	}
+
+	destination := extractDestination(txRow.Metadata)
+	gw := s.gatewayFor(payout.Currency)
+	callCtx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+	defer cancel()
+	gatewayRef, err := gw.SendPayout(callCtx, gateway.PayoutRequest{
+		PayoutID:       payoutID.String(),
+		IdempotencyKey: payoutIdempotencyKey(payoutID, payout.Attempts),
+		Destination:    formatDestination(destination),
+		AmountMicros:   payout.AmountMicros,
+		Currency:       payout.Currency,
+		CreditorName:   destination.Name,
+		CreditorIBAN:   destination.IBAN,
+		CreditorBIC:    destination.BIC,
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			zap.L().Warn("payout gateway call timed out; left for stale recovery", zap.Error(err), zap.String("payout_id", payoutID.String()), zap.Int32("attempt", payout.Attempts))
+			return
+		}
+		s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, err.Error())
+		return
+	}
+
+	if gateway.SettlesAsynchronously(gw) {
+		if err := s.markPayoutSubmitted(ctx, payout, gatewayRef); err != nil {
 			zap.L().Error(
-				"payout succeeded at gateway but local finalization failed; moved to manual review",
+				"payout submitted to gateway but could not be marked submitted; left for stale recovery",
 				zap.Error(err),
 				zap.String("payout_id", payoutID.String()),
 				zap.String("gateway_ref", gatewayRef),
 			)
 		}
+		return
 	}
 
-	return nil
+	if err := s.handlePayoutSuccess(ctx, payoutID, accountID, payout.AmountMicros, payout.Currency, payout.TransactionID, gatewayRef); err != nil {
+		zap.L().Error(
+			"payout succeeded at gateway but local finalization failed; moved to manual review",
+			zap.Error(err),
+			zap.String("payout_id", payoutID.String()),
+			zap.String("gateway_ref", gatewayRef),
+		)
+	}
 }
 
+// recoverStaleProcessingPayouts resolves payouts left in PROCESSING by a worker
+// that crashed or lost its connection mid-call. The earlier gateway attempt may
+// have gone through, so each payout is confirmed with the gateway before it is
+// resent; anything that cannot be confirmed is routed to MANUAL_REVIEW.
 func (s *PayoutService) recoverStaleProcessingPayouts(ctx context.Context, batchSize int32) error {
 	cutoff := time.Now().Add(-stalePayoutRecoveryWindow)
 	var stale []repository.Payout
@@ -287,35 +655,174 @@ func (s *PayoutService) recoverStaleProcessingPayouts(ctx context.Context, batch
 		if err != nil {
 			return fmt.Errorf("load stale processing payouts: %w", err)
 		}
+		// Bump updated_at so other workers skip these rows while the gateway is consulted.
 		for _, payout := range stale {
-			rows, err := qtx.UpdatePayoutStatus(ctx, repository.UpdatePayoutStatusParams{
-				Status:     domain.PayoutStatusPending,
-				GatewayRef: nil,
-				ID:         payout.ID,
-			})
+			rows, err := qtx.TouchPayout(ctx, payout.ID)
 			if err != nil {
-				return fmt.Errorf("requeue stale payout %s: %w", repository.FromPgUUID(payout.ID), err)
+				return fmt.Errorf("touch stale payout %s: %w", repository.FromPgUUID(payout.ID), err)
 			}
-			if err := requireExactlyOne(rows, "requeue stale payout"); err != nil {
+			if err := requireExactlyOne(rows, "touch stale payout"); err != nil {
 				return err
 			}
-			if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payout.TransactionID), domain.TxStatusPending, nil, "requeue_stale", nil); err != nil {
-				return fmt.Errorf("transition stale payout transaction %s: %w", repository.FromPgUUID(payout.TransactionID), err)
-			}
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+	if len(stale) == 0 {
+		return nil
+	}
 
-	if len(stale) > 0 {
-		zap.L().Warn("recovered stale processing payouts", zap.Int("count", len(stale)))
+	zap.L().Warn("recovering stale processing payouts", zap.Int("count", len(stale)))
+	for _, payout := range stale {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.recoverStalePayout(ctx, payout)
 	}
 	return nil
 }
 
+// recoverStalePayout asks the gateway what happened to the payout's last attempt
+// and settles the payout accordingly.
+func (s *PayoutService) recoverStalePayout(ctx context.Context, payout repository.Payout) {
+	payoutID := repository.FromPgUUID(payout.ID)
+	accountID := repository.FromPgUUID(payout.AccountID)
+
+	if payout.Attempts == 0 {
+		observability.IncrementPayoutStaleRecovery("manual_review")
+		s.markPayoutManualReview(ctx, payoutID, "", "stale payout has no recorded gateway attempt")
+		return
+	}
+
+	key := payoutIdempotencyKey(payoutID, payout.Attempts)
+	status, err := s.gatewayFor(payout.Currency).GetPayoutStatus(ctx, key)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down: leave the payout in PROCESSING for the next sweep.
+			return
+		}
+		observability.IncrementPayoutStaleRecovery("manual_review")
+		s.markPayoutManualReview(ctx, payoutID, "", fmt.Sprintf("gateway status lookup for %s failed: %v", key, err))
+		return
+	}
+
+	switch status.State {
+	case gateway.PayoutStateSent:
+		observability.IncrementPayoutStaleRecovery("completed")
+		if err := s.handlePayoutSuccess(ctx, payoutID, accountID, payout.AmountMicros, payout.Currency, payout.TransactionID, status.Ref); err != nil {
+			zap.L().Error(
+				"stale payout confirmed sent but local finalization failed; moved to manual review",
+				zap.Error(err),
+				zap.String("payout_id", payoutID.String()),
+				zap.String("gateway_ref", status.Ref),
+			)
+		}
+	case gateway.PayoutStateSubmitted:
+		observability.IncrementPayoutStaleRecovery("submitted")
+		if err := s.markPayoutSubmitted(ctx, payout, status.Ref); err != nil {
+			zap.L().Error("failed to mark stale payout submitted", zap.Error(err), zap.String("payout_id", payoutID.String()))
+		}
+	case gateway.PayoutStateNotFound:
+		observability.IncrementPayoutStaleRecovery("requeued")
+		if err := s.requeuePayouts(ctx, []repository.Payout{payout}, "requeue_stale"); err != nil {
+			zap.L().Error("failed to requeue stale payout", zap.Error(err), zap.String("payout_id", payoutID.String()))
+		}
+	case gateway.PayoutStateFailed:
+		observability.IncrementPayoutStaleRecovery("failed")
+		s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, "gateway reported failure for "+key)
+	default:
+		observability.IncrementPayoutStaleRecovery("manual_review")
+		s.markPayoutManualReview(ctx, payoutID, status.Ref, fmt.Sprintf("gateway could not confirm %s (state %s)", key, status.State))
+	}
+}
+
+// markPayoutSubmitted records that an asynchronous gateway accepted the payout.
+// Funds stay locked and the transaction stays PROCESSING until a status report
+// settles it through SettleSubmittedPayout.
+func (s *PayoutService) markPayoutSubmitted(ctx context.Context, payout repository.Payout, gatewayRef string) error {
+	return s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		rows, err := qtx.MarkPayoutSubmitted(ctx, repository.MarkPayoutSubmittedParams{
+			GatewayRef: textParam(gatewayRef),
+			ID:         payout.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("mark payout submitted: %w", err)
+		}
+		if err := requireExactlyOne(rows, "mark payout submitted"); err != nil {
+			return err
+		}
+		metadata, err := json.Marshal(map[string]any{"gateway_ref": gatewayRef})
+		if err != nil {
+			return fmt.Errorf("marshal payout submitted metadata: %w", err)
+		}
+		if err := s.audit.Write(ctx, qtx, "payout", repository.FromPgUUID(payout.ID), nil, "submitted", domain.PayoutStatusProcessing, domain.PayoutStatusSubmitted, metadata); err != nil {
+			return err
+		}
+		return writePayoutEvent(ctx, qtx, outbox.EventPayoutSubmitted, payout, map[string]any{"gateway_ref": gatewayRef})
+	})
+}
+
+// SettleSubmittedPayout applies a gateway status report to the SUBMITTED payout
+// it references. Reports for unknown or already settled payouts are ignored so
+// a redelivered report is harmless; interim states leave the payout waiting.
+func (s *PayoutService) SettleSubmittedPayout(ctx context.Context, settlement gateway.Settlement) error {
+	switch settlement.State {
+	case gateway.PayoutStateSent, gateway.PayoutStateFailed:
+	default:
+		return nil
+	}
+
+	payout, err := s.store.Queries().GetPayoutByGatewayRef(ctx, textParam(settlement.Ref))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			zap.L().Warn("status report references unknown payout", zap.String("gateway_ref", settlement.Ref))
+			return nil
+		}
+		return fmt.Errorf("get payout by gateway ref: %w", err)
+	}
+	if payout.Status != domain.PayoutStatusSubmitted {
+		return nil
+	}
+
+	// Reopening claims the settlement: only one report handler wins the row.
+	rows, err := s.store.Queries().ReopenSubmittedPayout(ctx, payout.ID)
+	if err != nil {
+		return fmt.Errorf("reopen submitted payout: %w", err)
+	}
+	if rows == 0 {
+		return nil
+	}
+
+	payoutID := repository.FromPgUUID(payout.ID)
+	accountID := repository.FromPgUUID(payout.AccountID)
+	if settlement.State == gateway.PayoutStateFailed {
+		reason := settlement.Reason
+		if reason == "" {
+			reason = "gateway rejected " + settlement.Ref
+		}
+		s.handlePayoutFailure(ctx, payoutID, accountID, payout.AmountMicros, reason)
+		return nil
+	}
+	if err := s.handlePayoutSuccess(ctx, payoutID, accountID, payout.AmountMicros, payout.Currency, payout.TransactionID, settlement.Ref); err != nil {
+		zap.L().Error(
+			"payout settled at gateway but local finalization failed; moved to manual review",
+			zap.Error(err),
+			zap.String("payout_id", payoutID.String()),
+			zap.String("gateway_ref", settlement.Ref),
+		)
+	}
+	return nil
+}
+
+// requeueClaimedPayouts returns claims that never reached the gateway to PENDING.
 func (s *PayoutService) requeueClaimedPayouts(ctx context.Context, payouts []repository.Payout) error {
+	return s.requeuePayouts(ctx, payouts, "requeue_claimed")
+}
+
+// requeuePayouts moves payouts back to PENDING so the next claim sends a new attempt.
+func (s *PayoutService) requeuePayouts(ctx context.Context, payouts []repository.Payout, action string) error {
 	if len(payouts) == 0 {
 		return nil
 	}
@@ -329,11 +836,17 @@ func (s *PayoutService) requeueClaimedPayouts(ctx context.Context, payouts []rep
 			if err != nil {
 				return fmt.Errorf("requeue payout %s: %w", repository.FromPgUUID(payout.ID), err)
 			}
-			if err := requireExactlyOne(rows, "requeue claimed payout"); err != nil {
+			if err := requireExactlyOne(rows, "requeue payout"); err != nil {
+				return err
+			}
+			if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payout.TransactionID), domain.TxStatusPending, nil, action, nil); err != nil {
+				return fmt.Errorf("transition requeued transaction %s: %w", repository.FromPgUUID(payout.TransactionID), err)
+			}
+			if err := writePayoutEvent(ctx, qtx, outbox.EventPayoutRequeued, payout, map[string]any{"reason": action}); err != nil {
 				return err
 			}
-			if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payout.TransactionID), domain.TxStatusPending, nil, "requeue_claimed", nil); err != nil {
-				return fmt.Errorf("transition claimed transaction %s: %w", repository.FromPgUUID(payout.TransactionID), err)
+			if err := notifyPayoutReady(ctx, qtx, repository.FromPgUUID(payout.ID)); err != nil {
+				return err
 			}
 		}
 		return nil
@@ -350,22 +863,10 @@ func (s *PayoutService) claimPendingPayouts(ctx context.Context, batchSize int32
 		}
 
 		for i, payout := range payouts {
-			payouts[i].Status = domain.PayoutStatusProcessing
-			rows, err := queries.UpdatePayoutStatus(ctx, repository.UpdatePayoutStatusParams{
-				Status:     domain.PayoutStatusProcessing,
-				GatewayRef: nil,
-				ID:         payout.ID,
-			})
+			payouts[i], err = s.claimPayout(ctx, queries, payout)
 			if err != nil {
-				return fmt.Errorf("failed to mark payout processing: %w", err)
-			}
-			if err := requireExactlyOne(rows, "mark payout processing"); err != nil {
 				return err
 			}
-
-			if err := transitionTransactionState(ctx, queries, s.audit, repository.FromPgUUID(payout.TransactionID), domain.TxStatusProcessing, nil, "processing_started", nil); err != nil {
-				return fmt.Errorf("failed to transition transaction status: %w", err)
-			}
 		}
 		return nil
 	})
@@ -375,6 +876,22 @@ func (s *PayoutService) claimPendingPayouts(ctx context.Context, batchSize int32
 	return payouts, nil
 }
 
+// claimPayout moves a locked PENDING payout to PROCESSING and starts a new gateway attempt.
+func (s *PayoutService) claimPayout(ctx context.Context, qtx *repository.Queries, payout repository.Payout) (repository.Payout, error) {
+	attempts, err := qtx.ClaimPayoutAttempt(ctx, payout.ID)
+	if err != nil {
+		return payout, fmt.Errorf("failed to mark payout processing: %w", err)
+	}
+	payout.Status = domain.PayoutStatusProcessing
+	payout.Attempts = attempts
+	payout.GatewayRef = nil
+
+	if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payout.TransactionID), domain.TxStatusProcessing, nil, "processing_started", nil); err != nil {
+		return payout, fmt.Errorf("failed to transition transaction status: %w", err)
+	}
+	return payout, nil
+}
+
 // handlePayoutSuccess finalizes accounting for a successful gateway payout.
 // If local finalization fails after gateway success, the payout is moved to MANUAL_REVIEW
 // and funds remain locked to avoid accidental double spend/retry.
@@ -445,7 +962,11 @@ func (s *PayoutService) handlePayoutSuccess(ctx context.Context, payoutID, accou
 		if err := requireExactlyOne(rows, "mark payout completed"); err != nil {
 			return err
 		}
-		return nil
+		payoutRow, err := qtx.GetPayout(ctx, repository.ToPgUUID(payoutID))
+		if err != nil {
+			return fmt.Errorf("failed to load completed payout: %w", err)
+		}
+		return writePayoutEvent(ctx, qtx, outbox.EventPayoutCompleted, payoutRow, map[string]any{"gateway_ref": gatewayRef})
 	})
 
 	if err != nil {
@@ -496,8 +1017,11 @@ func (s *PayoutService) handlePayoutFailure(ctx context.Context, payoutID, accou
 		if err := requireExactlyOne(rows, "mark payout failed"); err != nil {
 			return err
 		}
+		if err := releasePayoutLimit(ctx, qtx, payoutRow.ID); err != nil {
+			return err
+		}
 
-		return nil
+		return writePayoutEvent(ctx, qtx, outbox.EventPayoutFailed, payoutRow, map[string]any{"reason": reason})
 	})
 	if err != nil {
 		zap.L().Error("handle payout failure failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
@@ -508,6 +1032,20 @@ func (s *PayoutService) handlePayoutFailure(ctx context.Context, payoutID, accou
 	zap.L().Warn("payout marked failed", zap.String("payout_id", payoutID.String()), zap.String("reason", reason))
 }
 
+// releasePayoutLimit gives back the limit usage counted when the payout was
+// requested. It runs in the transaction that rejects or fails the payout and
+// releases at most once per payout.
+func releasePayoutLimit(ctx context.Context, qtx *repository.Queries, payoutID pgtype.UUID) error {
+	raw, err := qtx.TakePayoutLimitReservation(ctx, payoutID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("take payout limit reservation: %w", err)
+	}
+	return releaseRecordedLimit(ctx, qtx, raw)
+}
+
 // updatePayoutFailed is a helper to update payout status to failed when critical errors occur.
 func (s *PayoutService) updatePayoutFailed(ctx context.Context, payoutID uuid.UUID, reason string) {
 	queries := s.store.Queries()
@@ -535,7 +1073,10 @@ func (s *PayoutService) updatePayoutFailed(ctx context.Context, payoutID uuid.UU
 				if metaErr != nil {
 					return fmt.Errorf("marshal payout failure fallback metadata: %w", metaErr)
 				}
-				return s.audit.Write(ctx, qtx, "transaction", repository.FromPgUUID(payoutRow.TransactionID), nil, "payout_failed_fallback", "", domain.TxStatusFailed, metadata)
+				if err := s.audit.Write(ctx, qtx, "transaction", repository.FromPgUUID(payoutRow.TransactionID), nil, "payout_failed_fallback", "", domain.TxStatusFailed, metadata); err != nil {
+					return err
+				}
+				return writePayoutEvent(ctx, qtx, outbox.EventPayoutFailed, payoutRow, map[string]any{"reason": reason})
 			}); err != nil {
 				zap.L().Error("fallback audit write failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
 			}
@@ -553,6 +1094,10 @@ func (s *PayoutService) updatePayoutFailed(ctx context.Context, payoutID uuid.UU
 		zap.L().Error("fallback payout fail update failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
 	} else if err := requireExactlyOne(rows, "fallback mark payout failed"); err != nil {
 		zap.L().Error("fallback payout fail update affected unexpected rows", zap.Error(err), zap.String("payout_id", payoutID.String()))
+	} else if err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		return releasePayoutLimit(ctx, qtx, repository.ToPgUUID(payoutID))
+	}); err != nil {
+		zap.L().Error("fallback limit usage release failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
 	}
 
 	zap.L().Warn("payout failure fallback executed", zap.String("payout_id", payoutID.String()), zap.String("reason", reason))
@@ -560,10 +1105,9 @@ func (s *PayoutService) updatePayoutFailed(ctx context.Context, payoutID uuid.UU
 
 func (s *PayoutService) markPayoutManualReview(ctx context.Context, payoutID uuid.UUID, gatewayRef, reason string) {
 	queries := s.store.Queries()
-	ref := gatewayRef
 	if rows, err := queries.UpdatePayoutStatus(ctx, repository.UpdatePayoutStatusParams{
 		Status:     domain.PayoutStatusManualReview,
-		GatewayRef: &ref,
+		GatewayRef: textParam(gatewayRef),
 		ID:         repository.ToPgUUID(payoutID),
 	}); err != nil {
 		zap.L().Error("failed to mark payout manual review", zap.Error(err), zap.String("payout_id", payoutID.String()))
@@ -611,17 +1155,8 @@ func (s *PayoutService) GetPayout(ctx context.Context, payoutID uuid.UUID) (*mod
 		return nil, fmt.Errorf("failed to get payout: %w", err)
 	}
 
-	return &models.Payout{
-		ID:            repository.FromPgUUID(row.ID),
-		TransactionID: repository.FromPgUUID(row.TransactionID),
-		AccountID:     repository.FromPgUUID(row.AccountID),
-		AmountMicros:  row.AmountMicros,
-		Currency:      row.Currency,
-		Status:        row.Status,
-		GatewayRef:    row.GatewayRef,
-		CreatedAt:     row.CreatedAt.Time,
-		UpdatedAt:     row.UpdatedAt.Time,
-	}, nil
+	payout := toPayoutModel(row)
+	return &payout, nil
 }
 
 func (s *PayoutService) ManualReviewQueueSize(ctx context.Context) (int64, error) {
@@ -653,17 +1188,7 @@ func (s *PayoutService) ListManualReviewPayouts(ctx context.Context, limit, offs
 	}
 	out := make([]models.Payout, 0, len(rows))
 	for _, row := range rows {
-		out = append(out, models.Payout{
-			ID:            repository.FromPgUUID(row.ID),
-			TransactionID: repository.FromPgUUID(row.TransactionID),
-			AccountID:     repository.FromPgUUID(row.AccountID),
-			AmountMicros:  row.AmountMicros,
-			Currency:      row.Currency,
-			Status:        row.Status,
-			GatewayRef:    row.GatewayRef,
-			CreatedAt:     row.CreatedAt.Time,
-			UpdatedAt:     row.UpdatedAt.Time,
-		})
+		out = append(out, toPayoutModel(row))
 	}
 	return out, nil
 }
@@ -789,7 +1314,11 @@ func (s *PayoutService) applyManualReviewConfirmation(
 	if err := requireExactlyOne(rows, "manual-review set payout completed"); err != nil {
 		return err
 	}
-	return nil
+	extra := map[string]any{"resolution": string(DecisionConfirmSent)}
+	if ref != nil {
+		extra["gateway_ref"] = *ref
+	}
+	return writePayoutEvent(ctx, qtx, outbox.EventPayoutCompleted, payoutRow, extra)
 }
 
 func (s *PayoutService) applyManualReviewRefund(
@@ -825,7 +1354,42 @@ func (s *PayoutService) applyManualReviewRefund(
 	if err := requireExactlyOne(rows, "manual-review set payout failed"); err != nil {
 		return err
 	}
-	return nil
+	if err := releasePayoutLimit(ctx, qtx, payoutRow.ID); err != nil {
+		return err
+	}
+	return writePayoutEvent(ctx, qtx, outbox.EventPayoutFailed, payoutRow, map[string]any{"resolution": string(DecisionRefundFailed)})
+}
+
+func toPayoutModel(row repository.Payout) models.Payout {
+	payout := models.Payout{
+		ID:            repository.FromPgUUID(row.ID),
+		TransactionID: repository.FromPgUUID(row.TransactionID),
+		AccountID:     repository.FromPgUUID(row.AccountID),
+		AmountMicros:  row.AmountMicros,
+		Currency:      row.Currency,
+		Status:        row.Status,
+		GatewayRef:    row.GatewayRef,
+		Attempts:      row.Attempts,
+		CreatedAt:     row.CreatedAt.Time,
+		UpdatedAt:     row.UpdatedAt.Time,
+	}
+	if row.BeneficiaryID.Valid {
+		id := repository.FromPgUUID(row.BeneficiaryID)
+		payout.BeneficiaryID = &id
+	}
+	if row.RequestedBy.Valid {
+		id := repository.FromPgUUID(row.RequestedBy)
+		payout.RequestedBy = &id
+	}
+	if row.ReviewedBy.Valid {
+		id := repository.FromPgUUID(row.ReviewedBy)
+		payout.ReviewedBy = &id
+	}
+	if row.ReviewedAt.Valid {
+		at := row.ReviewedAt.Time
+		payout.ReviewedAt = &at
+	}
+	return payout
 }
 
 type payoutMetadata struct {
@@ -843,16 +1407,42 @@ func extractDestination(metadata []byte) PayoutDestinationInput {
 }
 
 func formatDestination(dest PayoutDestinationInput) string {
-	if dest.IBAN == "" && dest.Name == "" {
+	account := dest.IBAN
+	switch {
+	case account != "":
+	case dest.RoutingNumber != "":
+		account = dest.RoutingNumber + "/" + dest.AccountNumber
+	case dest.SortCode != "":
+		account = dest.SortCode + "/" + dest.AccountNumber
+	}
+	if account == "" && dest.Name == "" {
 		return "EXTERNAL_ACCOUNT"
 	}
 	if dest.Name == "" {
-		return dest.IBAN
+		return account
 	}
-	if dest.IBAN == "" {
+	if account == "" {
 		return dest.Name
 	}
-	return fmt.Sprintf("%s (%s)", dest.Name, dest.IBAN)
+	return fmt.Sprintf("%s (%s)", dest.Name, account)
+}
+
+// notifyPayoutReady queues a NOTIFY that Postgres delivers only if the
+// surrounding transaction commits.
+func notifyPayoutReady(ctx context.Context, qtx *repository.Queries, payoutID uuid.UUID) error {
+	if err := qtx.NotifyChannel(ctx, repository.NotifyChannelParams{
+		Channel: domain.PayoutNotifyChannel,
+		Payload: payoutID.String(),
+	}); err != nil {
+		return fmt.Errorf("notify payout ready: %w", err)
+	}
+	return nil
+}
+
+// payoutIdempotencyKey derives the gateway dedupe key for one payout attempt.
+// It is stable across process restarts so an attempt can be looked up later.
+func payoutIdempotencyKey(payoutID uuid.UUID, attempt int32) string {
+	return fmt.Sprintf("payout-%s-%d", payoutID, attempt)
 }
 
 func marshalReasonMetadata(reason string) ([]byte, error) {
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PayoutReviewRequest is a second admin's decision on a payout awaiting approval.
+type PayoutReviewRequest struct {
+	PayoutID uuid.UUID
+	ActorID  uuid.UUID
+	Reason   string
+}
+
+// ListPayoutsAwaitingApproval returns payouts held for a four-eyes decision, oldest first.
+func (s *PayoutService) ListPayoutsAwaitingApproval(ctx context.Context, limit, offset int32) ([]models.Payout, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := s.store.Queries().GetPayoutsAwaitingApproval(ctx, repository.GetPayoutsAwaitingApprovalParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list payouts awaiting approval: %w", err)
+	}
+	out := make([]models.Payout, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toPayoutModel(row))
+	}
+	return out, nil
+}
+
+// ApprovalQueueSize returns the number of payouts awaiting approval.
+func (s *PayoutService) ApprovalQueueSize(ctx context.Context) (int64, error) {
+	count, err := s.store.Queries().CountPayoutsByStatus(ctx, domain.PayoutStatusAwaitingApproval)
+	if err != nil {
+		return 0, fmt.Errorf("count payouts awaiting approval: %w", err)
+	}
+	return count, nil
+}
+
+// ApprovePayout releases a held payout to the worker. The approver must be a
+// different admin from the one who requested it.
+func (s *PayoutService) ApprovePayout(ctx context.Context, req PayoutReviewRequest) (*models.Payout, error) {
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		payoutRow, metadata, err := s.lockPayoutForReview(ctx, qtx, req)
+		if err != nil {
+			return err
+		}
+		if err := s.markPayoutReviewed(ctx, qtx, payoutRow, req.ActorID, domain.PayoutStatusPending, "approved", metadata); err != nil {
+			return err
+		}
+		if err := writePayoutEvent(ctx, qtx, outbox.EventPayoutRequested, payoutRow, map[string]any{"approved_by": req.ActorID}); err != nil {
+			return err
+		}
+		return notifyPayoutReady(ctx, qtx, req.PayoutID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.IncrementPayoutApprovalDecision("approved")
+	return s.GetPayout(ctx, req.PayoutID)
+}
+
+// RejectPayout declines a held payout, releasing its locked funds and failing
+// the underlying transaction. The same four-eyes rule as approval applies.
+func (s *PayoutService) RejectPayout(ctx context.Context, req PayoutReviewRequest) (*models.Payout, error) {
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		payoutRow, metadata, err := s.lockPayoutForReview(ctx, qtx, req)
+		if err != nil {
+			return err
+		}
+
+		rows, err := qtx.ReleaseAccountFundsSafe(ctx, repository.ReleaseAccountFundsSafeParams{
+			LockedMicros: payoutRow.AmountMicros,
+			ID:           payoutRow.AccountID,
+		})
+		if err != nil {
+			return fmt.Errorf("reject payout: release locked funds: %w", err)
+		}
+		if err := requireExactlyOne(rows, "reject payout release locked funds"); err != nil {
+			return err
+		}
+
+		actorID := req.ActorID
+		if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payoutRow.TransactionID), domain.TxStatusFailed, &actorID, "payout_rejected", metadata); err != nil {
+			return fmt.Errorf("reject payout: transition transaction: %w", err)
+		}
+		if err := s.markPayoutReviewed(ctx, qtx, payoutRow, req.ActorID, domain.PayoutStatusRejected, "rejected", metadata); err != nil {
+			return err
+		}
+		if err := releasePayoutLimit(ctx, qtx, payoutRow.ID); err != nil {
+			return err
+		}
+		return writePayoutEvent(ctx, qtx, outbox.EventPayoutRejected, payoutRow, map[string]any{
+			"rejected_by": req.ActorID,
+			"reason":      req.Reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.IncrementPayoutApprovalDecision("rejected")
+	return s.GetPayout(ctx, req.PayoutID)
+}
+
+// lockPayoutForReview locks the payout row and enforces the four-eyes rule.
+// It returns the audit metadata shared by both decisions.
+func (s *PayoutService) lockPayoutForReview(ctx context.Context, qtx *repository.Queries, req PayoutReviewRequest) (repository.Payout, []byte, error) {
+	payoutRow, err := qtx.GetPayoutForUpdate(ctx, repository.ToPgUUID(req.PayoutID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.Payout{}, nil, ErrPayoutNotFound
+		}
+		return repository.Payout{}, nil, fmt.Errorf("get payout for update: %w", err)
+	}
+	if payoutRow.Status != domain.PayoutStatusAwaitingApproval {
+		return repository.Payout{}, nil, ErrPayoutNotAwaitingApproval
+	}
+	// A held payout without a recorded requester cannot prove four-eyes
+	// control, so nobody may approve it.
+	if !payoutRow.RequestedBy.Valid || repository.FromPgUUID(payoutRow.RequestedBy) == req.ActorID {
+		return repository.Payout{}, nil, ErrPayoutSelfApproval
+	}
+
+	metadata, err := json.Marshal(map[string]any{
+		"requested_by": repository.FromPgUUID(payoutRow.RequestedBy),
+		"reviewed_by":  req.ActorID,
+		"reason":       req.Reason,
+	})
+	if err != nil {
+		return repository.Payout{}, nil, fmt.Errorf("marshal review metadata: %w", err)
+	}
+	return payoutRow, metadata, nil
+}
+
+func (s *PayoutService) markPayoutReviewed(ctx context.Context, qtx *repository.Queries, payoutRow repository.Payout, actorID uuid.UUID, nextStatus, action string, metadata []byte) error {
+	rows, err := qtx.ReviewPayout(ctx, repository.ReviewPayoutParams{
+		Status:     nextStatus,
+		ReviewedBy: repository.ToPgUUID(actorID),
+		ID:         payoutRow.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("review payout: %w", err)
+	}
+	if err := requireExactlyOne(rows, "review payout"); err != nil {
+		return err
+	}
+	return s.audit.Write(ctx, qtx, "payout", repository.FromPgUUID(payoutRow.ID), &actorID, action, payoutRow.Status, nextStatus, metadata)
+}
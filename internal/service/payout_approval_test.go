@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestPayoutAboveThresholdAwaitsApproval(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	gateway := &stubGateway{ref: "MOCK-REF"}
+	payoutSvc := NewPayoutService(store, gateway).WithApprovalThresholds(map[string]int64{"USD": 1_000_000})
+	ctx := context.Background()
+
+	maker := &models.User{ID: uuid.New(), Username: "maker", Email: "maker@example.com", Role: "admin"}
+	require.NoError(t, repoSvc.CreateUser(ctx, maker))
+	account := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	small, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 1_000_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "approval-at-threshold",
+		RequestedBy:  &maker.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusPending, small.Status)
+
+	large, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 2_000_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "approval-above-threshold",
+		RequestedBy:  &maker.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusAwaitingApproval, large.Status)
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 10))
+	require.Len(t, gateway.sentKeys, 1, "worker must not claim payouts awaiting approval")
+
+	queries := repository.New(db)
+	held, err := queries.GetPayout(ctx, repository.ToPgUUID(large.PayoutID))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusAwaitingApproval, held.Status)
+	require.Equal(t, maker.ID, repository.FromPgUUID(held.RequestedBy))
+
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(2_000_000), accRow.LockedMicros)
+
+	_, err = payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 2_000_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "approval-without-requester",
+	})
+	require.ErrorIs(t, err, ErrPayoutRequesterRequired)
+}
+
+func TestApprovePayoutRequiresSecondAdmin(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	gateway := &stubGateway{ref: "MOCK-REF"}
+	payoutSvc := NewPayoutService(store, gateway).WithApprovalThresholds(map[string]int64{"USD": 0})
+	ctx := context.Background()
+
+	maker := &models.User{ID: uuid.New(), Username: "maker", Email: "maker@example.com", Role: "admin"}
+	checker := &models.User{ID: uuid.New(), Username: "checker", Email: "checker@example.com", Role: "admin"}
+	require.NoError(t, repoSvc.CreateUser(ctx, maker))
+	require.NoError(t, repoSvc.CreateUser(ctx, checker))
+	account := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 1_500_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "approval-four-eyes",
+		RequestedBy:  &maker.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = payoutSvc.ApprovePayout(ctx, PayoutReviewRequest{PayoutID: resp.PayoutID, ActorID: maker.ID})
+	require.ErrorIs(t, err, ErrPayoutSelfApproval)
+
+	approved, err := payoutSvc.ApprovePayout(ctx, PayoutReviewRequest{PayoutID: resp.PayoutID, ActorID: checker.ID, Reason: "verified beneficiary"})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusPending, approved.Status)
+	require.NotNil(t, approved.ReviewedBy)
+	require.Equal(t, checker.ID, *approved.ReviewedBy)
+
+	_, err = payoutSvc.ApprovePayout(ctx, PayoutReviewRequest{PayoutID: resp.PayoutID, ActorID: checker.ID})
+	require.ErrorIs(t, err, ErrPayoutNotAwaitingApproval)
+
+	queries := repository.New(db)
+	auditRows, err := queries.GetAuditLogsByEntity(ctx, repository.GetAuditLogsByEntityParams{
+		EntityType: "payout",
+		EntityID:   repository.ToPgUUID(resp.PayoutID),
+	})
+	require.NoError(t, err)
+	require.Len(t, auditRows, 2)
+	require.Equal(t, "approval_requested", auditRows[0].Action)
+	require.Equal(t, maker.ID, repository.FromPgUUID(auditRows[0].ActorID))
+	require.Equal(t, "approved", auditRows[1].Action)
+	require.Equal(t, checker.ID, repository.FromPgUUID(auditRows[1].ActorID))
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 10))
+	payoutRow, err := queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusCompleted, payoutRow.Status)
+}
+
+func TestRejectPayoutReleasesFunds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	payoutSvc := NewPayoutService(store, &stubGateway{ref: "MOCK-REF"}).WithApprovalThresholds(map[string]int64{"USD": 0})
+	ctx := context.Background()
+
+	maker := &models.User{ID: uuid.New(), Username: "maker", Email: "maker@example.com", Role: "admin"}
+	checker := &models.User{ID: uuid.New(), Username: "checker", Email: "checker@example.com", Role: "admin"}
+	require.NoError(t, repoSvc.CreateUser(ctx, maker))
+	require.NoError(t, repoSvc.CreateUser(ctx, checker))
+	account := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 1_500_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "approval-reject",
+		RequestedBy:  &maker.ID,
+	})
+	require.NoError(t, err)
+
+	rejected, err := payoutSvc.RejectPayout(ctx, PayoutReviewRequest{PayoutID: resp.PayoutID, ActorID: checker.ID, Reason: "unknown beneficiary"})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusRejected, rejected.Status)
+
+	queries := repository.New(db)
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(5_000_000), accRow.Balance)
+	require.Equal(t, int64(0), accRow.LockedMicros)
+
+	txRow, err := queries.GetTransaction(ctx, repository.ToPgUUID(rejected.TransactionID))
+	require.NoError(t, err)
+	require.Equal(t, domain.TxStatusFailed, txRow.Status)
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrPayoutNotInScreeningHold indicates a screening decision on a payout that is not held.
+	ErrPayoutNotInScreeningHold = errors.New("payout is not in screening hold")
+	// ErrInvalidScreeningDecision indicates a screening decision other than clear or confirm_match.
+	ErrInvalidScreeningDecision = errors.New("invalid screening decision")
+)
+
+// Screening decision values recorded on payout_screenings.
+const (
+	screeningDecisionCleared   = "CLEARED"
+	screeningDecisionConfirmed = "CONFIRMED"
+)
+
+// ScreeningDecision is an admin's verdict on a sanctions match.
+type ScreeningDecision string
+
+const (
+	// ScreeningDecisionClear releases a held payout: the match was a false positive.
+	ScreeningDecisionClear ScreeningDecision = "clear"
+	// ScreeningDecisionConfirmMatch rejects a held payout and releases its funds.
+	ScreeningDecisionConfirmMatch ScreeningDecision = "confirm_match"
+)
+
+// PayoutScreening is the sanctions match that held a payout and its review.
+type PayoutScreening struct {
+	ScreeningHit
+	// ReleaseStatus is where the payout goes if the match is cleared.
+	ReleaseStatus string     `json:"release_status"`
+	Decision      *string    `json:"decision,omitempty"`
+	DecidedBy     *uuid.UUID `json:"decided_by,omitempty"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+	Reason        *string    `json:"reason,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ScreenedPayout is a held payout with the match that held it.
+type ScreenedPayout struct {
+	models.Payout
+	Screening PayoutScreening `json:"screening"`
+}
+
+// ResolveScreeningRequest is an admin's decision on a payout in SCREENING_HOLD.
+type ResolveScreeningRequest struct {
+	PayoutID uuid.UUID
+	Decision ScreeningDecision
+	Reason   string
+	ActorID  uuid.UUID
+}
+
+// WithScreening screens payout payee names against sanctions lists and holds
+// payouts that match.
+func (s *PayoutService) WithScreening(screening *ScreeningService) *PayoutService {
+	s.screening = screening
+	return s
+}
+
+// holdForScreening records hit against a payout just inserted in
+// SCREENING_HOLD. releaseStatus is the status it would otherwise have taken.
+func (s *PayoutService) holdForScreening(ctx context.Context, qtx *repository.Queries, payoutRow repository.Payout, hit *ScreeningHit, releaseStatus string, actorID *uuid.UUID) error {
+	if err := qtx.InsertPayoutScreening(ctx, repository.InsertPayoutScreeningParams{
+		PayoutID:      payoutRow.ID,
+		ScreenedName:  hit.ScreenedName,
+		ListSource:    hit.Source,
+		ListEntryID:   hit.ExternalID,
+		MatchedName:   hit.MatchedName,
+		Score:         hit.Score,
+		ReleaseStatus: releaseStatus,
+	}); err != nil {
+		return fmt.Errorf("failed to record payout screening: %w", err)
+	}
+	metadata, err := json.Marshal(hit)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := s.audit.Write(ctx, qtx, "payout", repository.FromPgUUID(payoutRow.ID), actorID, "screening_hold", "", domain.PayoutStatusScreeningHold, metadata); err != nil {
+		return err
+	}
+	return writePayoutEvent(ctx, qtx, outbox.EventPayoutScreeningHold, payoutRow, map[string]any{
+		"list_source":   hit.Source,
+		"list_entry_id": hit.ExternalID,
+		"score":         hit.Score,
+	})
+}
+
+// ListScreeningHoldPayouts returns payouts held by sanctions screening with
+// the match that held each one.
+func (s *PayoutService) ListScreeningHoldPayouts(ctx context.Context, limit, offset int32) ([]ScreenedPayout, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	queries := s.store.Queries()
+	rows, err := queries.GetPayoutsByStatus(ctx, repository.GetPayoutsByStatusParams{
+		Status: domain.PayoutStatusScreeningHold,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list screening hold payouts: %w", err)
+	}
+	out := make([]ScreenedPayout, 0, len(rows))
+	if len(rows) == 0 {
+		return out, nil
+	}
+
+	ids := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	screenings, err := queries.ListPayoutScreenings(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("list payout screenings: %w", err)
+	}
+	byPayout := make(map[uuid.UUID]repository.PayoutScreening, len(screenings))
+	for _, screening := range screenings {
+		byPayout[repository.FromPgUUID(screening.PayoutID)] = screening
+	}
+	for _, row := range rows {
+		item := ScreenedPayout{Payout: toPayoutModel(row)}
+		if screening, ok := byPayout[item.ID]; ok {
+			item.Screening = payoutScreeningFromRow(screening)
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// ScreeningHoldQueueSize returns the number of payouts held by screening.
+func (s *PayoutService) ScreeningHoldQueueSize(ctx context.Context) (int64, error) {
+	count, err := s.store.Queries().CountPayoutsByStatus(ctx, domain.PayoutStatusScreeningHold)
+	if err != nil {
+		return 0, fmt.Errorf("count screening hold payouts: %w", err)
+	}
+	return count, nil
+}
+
+// ResolveScreeningHold decides a payout held by sanctions screening.
+// Clearing the match sends the payout on to the status it was held from;
+// confirming it rejects the payout and releases its locked funds. The
+// reviewer may not be the admin who requested the payout.
+func (s *PayoutService) ResolveScreeningHold(ctx context.Context, req ResolveScreeningRequest) (*models.Payout, error) {
+	decision := ScreeningDecision(strings.ToLower(strings.TrimSpace(string(req.Decision))))
+	switch decision {
+	case ScreeningDecisionClear, ScreeningDecisionConfirmMatch:
+	default:
+		return nil, ErrInvalidScreeningDecision
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidScreeningDecision)
+	}
+
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		payoutRow, err := qtx.GetPayoutForUpdate(ctx, repository.ToPgUUID(req.PayoutID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrPayoutNotFound
+			}
+			return fmt.Errorf("get payout for update: %w", err)
+		}
+		if payoutRow.Status != domain.PayoutStatusScreeningHold {
+			return ErrPayoutNotInScreeningHold
+		}
+		if payoutRow.RequestedBy.Valid && repository.FromPgUUID(payoutRow.RequestedBy) == req.ActorID {
+			return ErrPayoutSelfApproval
+		}
+		screening, err := qtx.GetPayoutScreening(ctx, payoutRow.ID)
+		if err != nil {
+			return fmt.Errorf("get payout screening: %w", err)
+		}
+
+		recorded := screeningDecisionCleared
+		if decision == ScreeningDecisionConfirmMatch {
+			recorded = screeningDecisionConfirmed
+		}
+		rows, err := qtx.DecidePayoutScreening(ctx, repository.DecidePayoutScreeningParams{
+			Decision:  &recorded,
+			DecidedBy: repository.ToPgUUID(req.ActorID),
+			Reason:    &reason,
+			PayoutID:  payoutRow.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("decide payout screening: %w", err)
+		}
+		if err := requireExactlyOne(rows, "decide payout screening"); err != nil {
+			return err
+		}
+
+		metadata, err := json.Marshal(map[string]any{
+			"decision":      recorded,
+			"reason":        reason,
+			"list_source":   screening.ListSource,
+			"list_entry_id": screening.ListEntryID,
+			"score":         screening.Score,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal screening metadata: %w", err)
+		}
+		if decision == ScreeningDecisionClear {
+			return s.clearScreeningHold(ctx, qtx, payoutRow, screening.ReleaseStatus, req.ActorID, metadata)
+		}
+		return s.confirmScreeningMatch(ctx, qtx, payoutRow, req.ActorID, reason, metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+	observability.IncrementScreeningDecision(string(decision))
+	return s.GetPayout(ctx, req.PayoutID)
+}
+
+func (s *PayoutService) clearScreeningHold(ctx context.Context, qtx *repository.Queries, payoutRow repository.Payout, releaseStatus string, actorID uuid.UUID, metadata []byte) error {
+	if err := s.releaseScreenedPayout(ctx, qtx, payoutRow, releaseStatus, actorID, "screening_cleared", metadata); err != nil {
+		return err
+	}
+	if releaseStatus == domain.PayoutStatusAwaitingApproval {
+		return writePayoutEvent(ctx, qtx, outbox.EventPayoutAwaitingApproval, payoutRow, map[string]any{
+			"requested_by": repository.FromPgUUID(payoutRow.RequestedBy),
+		})
+	}
+	if err := writePayoutEvent(ctx, qtx, outbox.EventPayoutRequested, payoutRow, map[string]any{"screening_cleared_by": actorID}); err != nil {
+		return err
+	}
+	return notifyPayoutReady(ctx, qtx, repository.FromPgUUID(payoutRow.ID))
+}
+
+func (s *PayoutService) confirmScreeningMatch(ctx context.Context, qtx *repository.Queries, payoutRow repository.Payout, actorID uuid.UUID, reason string, metadata []byte) error {
+	rows, err := qtx.ReleaseAccountFundsSafe(ctx, repository.ReleaseAccountFundsSafeParams{
+		LockedMicros: payoutRow.AmountMicros,
+		ID:           payoutRow.AccountID,
+	})
+	if err != nil {
+		return fmt.Errorf("confirm screening match: release locked funds: %w", err)
+	}
+	if err := requireExactlyOne(rows, "confirm screening match release locked funds"); err != nil {
+		return err
+	}
+	if err := transitionTransactionState(ctx, qtx, s.audit, repository.FromPgUUID(payoutRow.TransactionID), domain.TxStatusFailed, &actorID, "payout_screening_confirmed", metadata); err != nil {
+		return fmt.Errorf("confirm screening match: transition transaction: %w", err)
+	}
+	if err := s.releaseScreenedPayout(ctx, qtx, payoutRow, domain.PayoutStatusRejected, actorID, "screening_confirmed", metadata); err != nil {
+		return err
+	}
+	if err := releasePayoutLimit(ctx, qtx, payoutRow.ID); err != nil {
+		return err
+	}
+	return writePayoutEvent(ctx, qtx, outbox.EventPayoutRejected, payoutRow, map[string]any{
+		"rejected_by": actorID,
+		"reason":      reason,
+		"screening":   true,
+	})
+}
+
+func (s *PayoutService) releaseScreenedPayout(ctx context.Context, qtx *repository.Queries, payoutRow repository.Payout, nextStatus string, actorID uuid.UUID, action string, metadata []byte) error {
+	rows, err := qtx.ReleaseScreenedPayout(ctx, repository.ReleaseScreenedPayoutParams{
+		Status: nextStatus,
+		ID:     payoutRow.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("release screened payout: %w", err)
+	}
+	if err := requireExactlyOne(rows, "release screened payout"); err != nil {
+		return err
+	}
+	return s.audit.Write(ctx, qtx, "payout", repository.FromPgUUID(payoutRow.ID), &actorID, action, payoutRow.Status, nextStatus, metadata)
+}
+
+func payoutScreeningFromRow(row repository.PayoutScreening) PayoutScreening {
+	screening := PayoutScreening{
+		ScreeningHit: ScreeningHit{
+			ScreenedName: row.ScreenedName,
+			Source:       row.ListSource,
+			ExternalID:   row.ListEntryID,
+			MatchedName:  row.MatchedName,
+			Score:        row.Score,
+		},
+		ReleaseStatus: row.ReleaseStatus,
+		Decision:      row.Decision,
+		Reason:        row.Reason,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+	if row.DecidedBy.Valid {
+		decidedBy := repository.FromPgUUID(row.DecidedBy)
+		screening.DecidedBy = &decidedBy
+	}
+	if row.DecidedAt.Valid {
+		decidedAt := row.DecidedAt.Time
+		screening.DecidedAt = &decidedAt
+	}
+	return screening
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	gw "github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// asyncStubGateway settles through status reports, like the SEPA file gateway.
+type asyncStubGateway struct {
+	stubGateway
+}
+
+func (g *asyncStubGateway) SettlesAsynchronously() bool { return true }
+
+func TestSubmittedPayoutSettlesFromStatusReport(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	fallback := &stubGateway{ref: "MOCK-REF"}
+	fileGateway := &asyncStubGateway{stubGateway{ref: "E2E-"}}
+	payoutSvc := NewPayoutService(store, fallback).WithCurrencyGateway("eur", fileGateway)
+	ctx := context.Background()
+	queries := repository.New(db)
+
+	user := &models.User{ID: uuid.New(), Username: "sepa-user", Email: "sepa@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "EUR", Balance: 3_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	request := func(ref string) uuid.UUID {
+		resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+			AccountID:    account.ID,
+			AmountMicros: 1_000_000,
+			Currency:     "EUR",
+			Destination:  PayoutDestinationInput{IBAN: "DE89370400440532013000", Name: "Jana"},
+			ReferenceID:  ref,
+		})
+		require.NoError(t, err)
+		return resp.PayoutID
+	}
+	accepted := request("sepa-accepted")
+	fileGateway.ref = "E2E-ACCEPTED"
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 1))
+	rejected := request("sepa-rejected")
+	fileGateway.ref = "E2E-REJECTED"
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 1))
+	require.Empty(t, fallback.sentKeys, "EUR payouts must use the currency gateway")
+
+	for _, id := range []uuid.UUID{accepted, rejected} {
+		row, err := queries.GetPayout(ctx, repository.ToPgUUID(id))
+		require.NoError(t, err)
+		require.Equal(t, domain.PayoutStatusSubmitted, row.Status)
+		txRow, err := queries.GetTransaction(ctx, row.TransactionID)
+		require.NoError(t, err)
+		require.Equal(t, domain.TxStatusProcessing, txRow.Status)
+	}
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(2_000_000), accRow.LockedMicros)
+
+	// Interim statuses leave the payout waiting.
+	require.NoError(t, payoutSvc.SettleSubmittedPayout(ctx, gw.Settlement{Ref: "E2E-ACCEPTED", State: gw.PayoutStateSubmitted}))
+	require.NoError(t, payoutSvc.SettleSubmittedPayout(ctx, gw.Settlement{Ref: "E2E-ACCEPTED", State: gw.PayoutStateSent}))
+	require.NoError(t, payoutSvc.SettleSubmittedPayout(ctx, gw.Settlement{Ref: "E2E-REJECTED", State: gw.PayoutStateFailed, Reason: "pain.002 RJCT AC04"}))
+	// Redelivered and unknown reports are ignored.
+	require.NoError(t, payoutSvc.SettleSubmittedPayout(ctx, gw.Settlement{Ref: "E2E-ACCEPTED", State: gw.PayoutStateFailed}))
+	require.NoError(t, payoutSvc.SettleSubmittedPayout(ctx, gw.Settlement{Ref: "E2E-UNKNOWN", State: gw.PayoutStateSent}))
+
+	row, err := queries.GetPayout(ctx, repository.ToPgUUID(accepted))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusCompleted, row.Status)
+	require.Equal(t, "E2E-ACCEPTED", *row.GatewayRef)
+	row, err = queries.GetPayout(ctx, repository.ToPgUUID(rejected))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusFailed, row.Status)
+
+	accRow, err = queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(2_000_000), accRow.Balance)
+	require.Equal(t, int64(0), accRow.LockedMicros)
+}
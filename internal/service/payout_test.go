@@ -3,25 +3,46 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	gw "github.com/ayo6706/payment-multicurrency/internal/gateway"
 	"github.com/ayo6706/payment-multicurrency/internal/models"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/require"
 )
 
 type stubGateway struct {
-	ref string
-	err error
+	ref       string
+	err       error
+	status    gw.PayoutStatus
+	statusErr error
+	mu        sync.Mutex
+	sentKeys  []string
 }
 
-func (s *stubGateway) SendPayout(ctx context.Context, destination string, amount int64, currency string) (string, error) {
+func (s *stubGateway) SendPayout(ctx context.Context, req gw.PayoutRequest) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sentKeys = append(s.sentKeys, req.IdempotencyKey)
 	return s.ref, s.err
 }
 
+func (s *stubGateway) GetPayoutStatus(ctx context.Context, idempotencyKey string) (gw.PayoutStatus, error) {
+	if s.statusErr != nil {
+		return gw.PayoutStatus{State: gw.PayoutStateUnknown}, s.statusErr
+	}
+	if s.status.State == "" {
+		return gw.PayoutStatus{State: gw.PayoutStateNotFound}, nil
+	}
+	return s.status, nil
+}
+
 func TestPayoutDestinationValidate(t *testing.T) {
 	cases := []struct {
 		name string
@@ -38,6 +59,14 @@ func TestPayoutDestinationValidate(t *testing.T) {
 		},
 		{name: "missing_iban", in: PayoutDestinationInput{Name: "John"}, ok: false},
 		{name: "missing_name", in: PayoutDestinationInput{IBAN: "GB29NWBK60161331926819"}, ok: false},
+		{name: "iban_bad_checksum", in: PayoutDestinationInput{IBAN: "GB28NWBK60161331926819", Name: "John"}, ok: false},
+		{name: "iban_spaced_with_bic", in: PayoutDestinationInput{IBAN: "de89 3704 0044 0532 0130 00", BIC: "COBADEFFXXX", Name: "Jane"}, ok: true},
+		{name: "iban_bad_bic", in: PayoutDestinationInput{IBAN: "DE89370400440532013000", BIC: "COBA1", Name: "Jane"}, ok: false},
+		{name: "us_ach", in: PayoutDestinationInput{RoutingNumber: "021000021", AccountNumber: "123456789", Name: "Sam"}, ok: true},
+		{name: "us_ach_bad_routing", in: PayoutDestinationInput{RoutingNumber: "021000022", AccountNumber: "123456789", Name: "Sam"}, ok: false},
+		{name: "uk_sort_code", in: PayoutDestinationInput{SortCode: "60-16-13", AccountNumber: "31926819", Name: "Alex"}, ok: true},
+		{name: "uk_sort_code_short_account", in: PayoutDestinationInput{SortCode: "60-16-13", AccountNumber: "3192681", Name: "Alex"}, ok: false},
+		{name: "unsupported_scheme", in: PayoutDestinationInput{Scheme: "SWIFT", IBAN: "GB29NWBK60161331926819", Name: "John"}, ok: false},
 	}
 
 	for _, tc := range cases {
@@ -198,20 +227,50 @@ func TestPayoutProcessRecoversStaleProcessing(t *testing.T) {
 	payoutRow, err := queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
 	require.NoError(t, err)
 
-	// Simulate a worker that claimed the payout and then crashed.
-	_, err = queries.UpdatePayoutStatus(ctx, repository.UpdatePayoutStatusParams{
-		Status:     domain.PayoutStatusProcessing,
-		GatewayRef: nil,
-		ID:         payoutRow.ID,
-	})
+	simulateCrashedPayoutAttempt(t, db, payoutRow)
+
+	// The gateway never saw attempt 1, so the payout is resent as attempt 2.
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 5))
+
+	payoutRow, err = queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
 	require.NoError(t, err)
-	_, err = queries.UpdateTransactionStatus(ctx, repository.UpdateTransactionStatusParams{
-		Status: domain.TxStatusProcessing,
-		ID:     payoutRow.TransactionID,
+	require.Equal(t, domain.PayoutStatusCompleted, payoutRow.Status)
+	require.NotNil(t, payoutRow.GatewayRef)
+	require.Equal(t, int32(2), payoutRow.Attempts)
+	require.Equal(t, []string{payoutIdempotencyKey(resp.PayoutID, 2)}, gateway.sentKeys)
+}
+
+func TestPayoutStaleRecoveryConfirmsEarlierAttempt(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	stub := &stubGateway{
+		ref:    "MOCK-REF-RESEND",
+		status: gw.PayoutStatus{State: gw.PayoutStateSent, Ref: "MOCK-REF-FIRST"},
+	}
+	payoutSvc := NewPayoutService(store, stub)
+
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "confirm-stale-user", Email: "confirm-stale@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 250_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "req-confirm-stale",
 	})
 	require.NoError(t, err)
-	_, err = db.Exec(ctx, "UPDATE payouts SET updated_at = $1 WHERE id = $2", time.Now().Add(-3*time.Minute), payoutRow.ID)
+
+	queries := repository.New(db)
+	payoutRow, err := queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
 	require.NoError(t, err)
+	simulateCrashedPayoutAttempt(t, db, payoutRow)
 
 	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 5))
 
@@ -219,6 +278,125 @@ func TestPayoutProcessRecoversStaleProcessing(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, domain.PayoutStatusCompleted, payoutRow.Status)
 	require.NotNil(t, payoutRow.GatewayRef)
+	require.Equal(t, "MOCK-REF-FIRST", *payoutRow.GatewayRef)
+	require.Empty(t, stub.sentKeys, "confirmed payout must not be resent")
+
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(750_000), accRow.Balance)
+	require.Equal(t, int64(0), accRow.LockedMicros)
+}
+
+func TestPayoutStaleRecoveryUnconfirmedMovesToManualReview(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	stub := &stubGateway{ref: "MOCK-REF-RESEND", statusErr: errors.New("gateway status endpoint down")}
+	payoutSvc := NewPayoutService(store, stub)
+
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "unconfirmed-stale-user", Email: "unconfirmed-stale@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 400_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "req-unconfirmed-stale",
+	})
+	require.NoError(t, err)
+
+	queries := repository.New(db)
+	payoutRow, err := queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	simulateCrashedPayoutAttempt(t, db, payoutRow)
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 5))
+
+	payoutRow, err = queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusManualReview, payoutRow.Status)
+	require.Nil(t, payoutRow.GatewayRef)
+	require.Empty(t, stub.sentKeys, "unconfirmed payout must not be resent")
+
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(1_000_000), accRow.Balance)
+	require.Equal(t, int64(400_000), accRow.LockedMicros)
+}
+
+func TestPayoutStaleRecoveryFailedAttemptReleasesFunds(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	mock := &gw.MockGateway{FailureRate: 1, Delay: func() time.Duration { return 0 }}
+	payoutSvc := NewPayoutService(store, mock)
+
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "failed-stale-user", Email: "failed-stale@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 350_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "req-failed-stale",
+	})
+	require.NoError(t, err)
+
+	queries := repository.New(db)
+	payoutRow, err := queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	simulateCrashedPayoutAttempt(t, db, payoutRow)
+
+	// The crashed attempt reached the gateway, which rejected it.
+	key := payoutIdempotencyKey(resp.PayoutID, 1)
+	_, err = mock.SendPayout(ctx, gw.PayoutRequest{PayoutID: resp.PayoutID.String(), IdempotencyKey: key})
+	require.Error(t, err)
+	status, err := mock.GetPayoutStatus(ctx, key)
+	require.NoError(t, err)
+	require.Equal(t, gw.PayoutStateFailed, status.State)
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 5))
+
+	payoutRow, err = queries.GetPayout(ctx, repository.ToPgUUID(resp.PayoutID))
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusFailed, payoutRow.Status)
+	require.Equal(t, int32(1), payoutRow.Attempts, "failed payout must not be resent")
+
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(1_000_000), accRow.Balance)
+	require.Equal(t, int64(0), accRow.LockedMicros)
+}
+
+// simulateCrashedPayoutAttempt claims a payout attempt the way the worker does
+// and backdates it past the stale recovery window, as if the worker died mid-call.
+func simulateCrashedPayoutAttempt(t *testing.T, db *pgxpool.Pool, payoutRow repository.Payout) {
+	t.Helper()
+	ctx := context.Background()
+	queries := repository.New(db)
+
+	attempts, err := queries.ClaimPayoutAttempt(ctx, payoutRow.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempts)
+	_, err = queries.UpdateTransactionStatus(ctx, repository.UpdateTransactionStatusParams{
+		Status: domain.TxStatusProcessing,
+		ID:     payoutRow.TransactionID,
+	})
+	require.NoError(t, err)
+	_, err = db.Exec(ctx, "UPDATE payouts SET updated_at = $1 WHERE id = $2", time.Now().Add(-3*time.Minute), payoutRow.ID)
+	require.NoError(t, err)
 }
 
 func TestUpdatePayoutFailedReleasesLockedFunds(t *testing.T) {
@@ -390,3 +568,110 @@ func TestResolveManualReviewPayoutRefundFailed(t *testing.T) {
 	require.Equal(t, int64(1_000_000), accountRow.Balance)
 	require.Equal(t, int64(0), accountRow.LockedMicros)
 }
+
+func TestRequestPayoutNotifiesOnCommit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+	_, err = conn.Exec(ctx, "LISTEN "+domain.PayoutNotifyChannel)
+	require.NoError(t, err)
+
+	repoSvc := repository.NewRepository(db)
+	payoutSvc := NewPayoutService(repository.NewStore(db), &stubGateway{ref: "MOCK-REF"})
+
+	user := &models.User{ID: uuid.New(), Username: "notify-user", Email: "notify@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+		AccountID:    account.ID,
+		AmountMicros: 100_000,
+		Currency:     "USD",
+		Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+		ReferenceID:  "req-notify",
+	})
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	n, err := conn.Conn().WaitForNotification(waitCtx)
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutNotifyChannel, n.Channel)
+	require.Equal(t, resp.PayoutID.String(), n.Payload)
+}
+
+// blockingGateway holds every call until released and records peak concurrency.
+type blockingGateway struct {
+	release chan struct{}
+	active  atomic.Int32
+	peak    atomic.Int32
+}
+
+func (g *blockingGateway) SendPayout(ctx context.Context, req gw.PayoutRequest) (string, error) {
+	n := g.active.Add(1)
+	defer g.active.Add(-1)
+	for {
+		peak := g.peak.Load()
+		if n <= peak || g.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	select {
+	case <-g.release:
+		return "MOCK-" + req.IdempotencyKey, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (g *blockingGateway) GetPayoutStatus(ctx context.Context, idempotencyKey string) (gw.PayoutStatus, error) {
+	return gw.PayoutStatus{State: gw.PayoutStateNotFound}, nil
+}
+
+func TestProcessPayoutsBoundsConcurrency(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	stub := &blockingGateway{release: make(chan struct{})}
+	payoutSvc := NewPayoutService(repository.NewStore(db), stub).WithConcurrency(2)
+
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "pool-user", Email: "pool@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 1_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	var payoutIDs []uuid.UUID
+	for i := 0; i < 4; i++ {
+		resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+			AccountID:    account.ID,
+			AmountMicros: 100_000,
+			Currency:     "USD",
+			Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: "John"},
+			ReferenceID:  "req-pool-" + uuid.NewString(),
+		})
+		require.NoError(t, err)
+		payoutIDs = append(payoutIDs, resp.PayoutID)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- payoutSvc.ProcessPayouts(ctx, 10) }()
+
+	require.Eventually(t, func() bool { return stub.active.Load() == 2 }, 2*time.Second, 5*time.Millisecond)
+	close(stub.release)
+	require.NoError(t, <-done)
+	require.Equal(t, int32(2), stub.peak.Load())
+
+	queries := repository.New(db)
+	for _, id := range payoutIDs {
+		row, err := queries.GetPayout(ctx, repository.ToPgUUID(id))
+		require.NoError(t, err)
+		require.Equal(t, domain.PayoutStatusCompleted, row.Status)
+	}
+}
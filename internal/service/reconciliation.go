@@ -2,46 +2,431 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
 	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
-// ReconciliationService verifies ledger integrity invariants.
+// ReconciliationService verifies ledger integrity invariants and matches
+// settlement files against payouts.
 type ReconciliationService struct {
-	store QueryStore
+	store         QueryStore
+	audit         *AuditService
+	missingAfter  time.Duration
+	checkpointLag time.Duration
 }
 
 // NewReconciliationService creates a reconciliation service.
 func NewReconciliationService(store QueryStore) *ReconciliationService {
-	return &ReconciliationService{store: store}
+	return &ReconciliationService{
+		store:         store,
+		audit:         NewAuditService(store),
+		missingAfter:  72 * time.Hour,
+		checkpointLag: time.Hour,
+	}
+}
+
+// WithMissingAfter sets how long a completed payout may go without a
+// settlement line before it is reported as COMPLETED_BUT_MISSING.
+func (s *ReconciliationService) WithMissingAfter(d time.Duration) *ReconciliationService {
+	if d > 0 {
+		s.missingAfter = d
+	}
+	return s
+}
+
+// WithCheckpointLag sets how long after midnight UTC a day stays open before
+// incremental runs fold it into the ledger checkpoint. It must exceed the
+// longest write transaction.
+func (s *ReconciliationService) WithCheckpointLag(d time.Duration) *ReconciliationService {
+	if d > 0 {
+		s.checkpointLag = d
+	}
+	return s
 }
 
-// Run checks that the net sum of all ledger entries is zero.
+var (
+	ErrReconciliationRunInProgress = errors.New("a reconciliation run is already in progress")
+	ErrReconciliationRunNotFound   = errors.New("reconciliation run not found")
+	ErrReconciliationScopeInvalid  = errors.New("reconciliation scope must be FULL or INCREMENTAL")
+)
+
+const (
+	// reconciliationRunStaleAfter is how long a RUNNING run may go unfinished
+	// before it is treated as abandoned by a crashed instance.
+	reconciliationRunStaleAfter = 6 * time.Hour
+	// maxRunFindings bounds the findings stored on a run; finding_count
+	// keeps the full number.
+	maxRunFindings = 1000
+)
+
+// Run performs a scheduled incremental reconciliation run. It is skipped
+// when another run is already in progress.
 func (s *ReconciliationService) Run(ctx context.Context) error {
+	run, err := s.startRun(ctx, domain.RunTriggerScheduled, domain.RunScopeIncremental, nil)
+	if errors.Is(err, ErrReconciliationRunInProgress) {
+		zap.L().Info("reconciliation run skipped: another run is in progress")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.executeRun(ctx, run)
+	return err
+}
+
+// TriggerRun starts a manual run in the background and returns it in the
+// RUNNING state. Poll GetRun for the outcome. An empty scope means
+// INCREMENTAL; FULL rescans every partition of the ledger.
+func (s *ReconciliationService) TriggerRun(ctx context.Context, actorID uuid.UUID, scope string) (*models.ReconciliationRun, error) {
+	switch scope {
+	case "":
+		scope = domain.RunScopeIncremental
+	case domain.RunScopeFull, domain.RunScopeIncremental:
+	default:
+		return nil, ErrReconciliationScopeInvalid
+	}
+	run, err := s.startRun(ctx, domain.RunTriggerManual, scope, &actorID)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if _, err := s.executeRun(context.WithoutCancel(ctx), run); err != nil {
+			zap.L().Error("manual reconciliation run failed", zap.String("run_id", repository.FromPgUUID(run.ID).String()), zap.Error(err))
+		}
+	}()
+	out, err := toReconciliationRunModel(run)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRun returns a reconciliation run by ID.
+func (s *ReconciliationService) GetRun(ctx context.Context, id uuid.UUID) (*models.ReconciliationRun, error) {
+	row, err := s.store.Queries().GetReconciliationRun(ctx, repository.ToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReconciliationRunNotFound
+		}
+		return nil, fmt.Errorf("get reconciliation run: %w", err)
+	}
+	out, err := toReconciliationRunModel(row)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRuns returns reconciliation runs, newest first.
+func (s *ReconciliationService) ListRuns(ctx context.Context, limit, offset int32) ([]models.ReconciliationRun, error) {
+	rows, err := s.store.Queries().ListReconciliationRuns(ctx, repository.ListReconciliationRunsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliation runs: %w", err)
+	}
+	out := make([]models.ReconciliationRun, 0, len(rows))
+	for _, row := range rows {
+		run, err := toReconciliationRunModel(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, nil
+}
+
+func (s *ReconciliationService) startRun(ctx context.Context, trigger, scope string, actorID *uuid.UUID) (repository.ReconciliationRun, error) {
+	now := time.Now()
 	queries := s.store.Queries()
-	net, err := queries.GetLedgerNet(ctx)
+	abandoned, err := queries.AbandonStaleReconciliationRuns(ctx, pgtype.Timestamptz{Time: now.Add(-reconciliationRunStaleAfter), Valid: true})
+	if err != nil {
+		return repository.ReconciliationRun{}, fmt.Errorf("abandon stale reconciliation runs: %w", err)
+	}
+	if abandoned > 0 {
+		zap.L().Warn("abandoned unfinished reconciliation runs", zap.Int64("count", abandoned))
+	}
+
+	var triggeredBy pgtype.UUID
+	if actorID != nil {
+		triggeredBy = repository.ToPgUUID(*actorID)
+	}
+	run, err := queries.CreateReconciliationRun(ctx, repository.CreateReconciliationRunParams{
+		ID:          repository.ToPgUUID(uuid.New()),
+		Trigger:     trigger,
+		Scope:       scope,
+		TriggeredBy: triggeredBy,
+		StartedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ReconciliationRun{}, ErrReconciliationRunInProgress
+		}
+		return repository.ReconciliationRun{}, fmt.Errorf("create reconciliation run: %w", err)
+	}
+	return run, nil
+}
+
+// executeRun performs every check for run and records the outcome: PASSED
+// when the ledger nets to zero in every currency and no invariant is
+// violated, FAILED otherwise, and ERROR if a check could not complete.
+func (s *ReconciliationService) executeRun(ctx context.Context, run repository.ReconciliationRun) (*models.ReconciliationRun, error) {
+	totals, findings, checkErr := s.runChecks(ctx, run.Scope, run.StartedAt.Time)
+
+	status := domain.RunStatusPassed
+	switch {
+	case checkErr != nil:
+		status = domain.RunStatusError
+	case len(findings) > 0:
+		status = domain.RunStatusFailed
+	default:
+		for _, total := range totals {
+			if total.NetMicros != 0 {
+				status = domain.RunStatusFailed
+			}
+		}
+	}
+
+	if totals == nil {
+		totals = []models.CurrencyTotal{}
+	}
+	stored := findings
+	if stored == nil {
+		stored = []models.LedgerFinding{}
+	}
+	if len(stored) > maxRunFindings {
+		stored = stored[:maxRunFindings]
+	}
+	totalsJSON, err := json.Marshal(totals)
 	if err != nil {
-		return fmt.Errorf("run ledger net query: %w", err)
+		return nil, fmt.Errorf("marshal reconciliation totals: %w", err)
+	}
+	findingsJSON, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal reconciliation findings: %w", err)
+	}
+	var errText *string
+	if checkErr != nil {
+		errText = textParam(checkErr.Error())
+	}
+
+	// Record the outcome even if the caller's context was canceled mid-run.
+	row, err := s.store.Queries().FinishReconciliationRun(context.WithoutCancel(ctx), repository.FinishReconciliationRunParams{
+		ID:             run.ID,
+		Status:         status,
+		CurrencyTotals: totalsJSON,
+		Findings:       findingsJSON,
+		FindingCount:   int32(len(findings)),
+		Error:          errText,
+	})
+	if err != nil {
+		return nil, errors.Join(checkErr, fmt.Errorf("finish reconciliation run: %w", err))
+	}
+	zap.L().Info("reconciliation run finished",
+		zap.String("run_id", repository.FromPgUUID(row.ID).String()),
+		zap.String("trigger", row.Trigger),
+		zap.String("status", row.Status),
+		zap.Int32("findings", row.FindingCount),
+	)
+	out, err := toReconciliationRunModel(row)
+	if err != nil {
+		return nil, err
+	}
+	return &out, checkErr
+}
+
+// runChecks checks that ledger entries net to zero in every currency, that
+// every account and transaction is internally consistent, then checks
+// imported settlement files against the current payout states. A FULL run
+// scans every entry; an INCREMENTAL run works from the ledger checkpoint.
+func (s *ReconciliationService) runChecks(ctx context.Context, scope string, now time.Time) ([]models.CurrencyTotal, []models.LedgerFinding, error) {
+	if scope == domain.RunScopeIncremental {
+		totals, findings, err := s.checkLedgerIncremental(ctx, now)
+		if err != nil {
+			return totals, nil, err
+		}
+		return totals, findings, s.checkSettlements(ctx, now)
+	}
+
+	totals, err := s.checkLedgerNet(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	findings, err := s.checkLedgerInvariants(ctx, now)
+	if err != nil {
+		return totals, nil, err
+	}
+	return totals, findings, s.checkSettlements(ctx, now)
+}
+
+// checkLedgerNet sums entries per currency. Every currency must net to zero.
+func (s *ReconciliationService) checkLedgerNet(ctx context.Context) ([]models.CurrencyTotal, error) {
+	rows, err := s.store.Queries().GetLedgerCurrencyTotals(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("run ledger totals query: %w", err)
 	}
 
+	totals := make([]models.CurrencyTotal, 0, len(rows))
+	for _, row := range rows {
+		totals = append(totals, models.CurrencyTotal{
+			Currency:     row.Currency,
+			EntryCount:   row.EntryCount,
+			DebitMicros:  row.DebitMicros,
+			CreditMicros: row.CreditMicros,
+			NetMicros:    row.CreditMicros - row.DebitMicros,
+		})
+	}
+	reportLedgerTotals(totals)
+	return totals, nil
+}
+
+// reportLedgerTotals logs and counts any currency that does not net to zero.
+func reportLedgerTotals(totals []models.CurrencyTotal) {
+	var net int64
+	for _, total := range totals {
+		net += total.NetMicros
+	}
 	if net != 0 {
 		observability.IncrementLedgerImbalance("ALL")
 		zap.L().Error("CRITICAL: ledger imbalance detected", zap.Int64("net_amount", net))
+	}
+	for _, total := range totals {
+		if total.NetMicros != 0 {
+			observability.IncrementLedgerImbalance(total.Currency)
+			zap.L().Error("ledger imbalance by currency", zap.String("currency", total.Currency), zap.Int64("net_amount", total.NetMicros))
+		}
+	}
+	if net == 0 {
+		zap.L().Info("Ledger Balanced")
+	}
+}
+
+// checkLedgerInvariants compares each account's balance with the net of its
+// entries and its locked_micros with the payouts still holding funds, and
+// checks that every transaction's entries net to zero per currency. Each
+// violation is persisted as an open finding; findings this run no longer
+// sees are resolved.
+func (s *ReconciliationService) checkLedgerInvariants(ctx context.Context, now time.Time) ([]models.LedgerFinding, error) {
+	queries := s.store.Queries()
+	var findings []models.LedgerFinding
+	add := func(findingType string, subjectID pgtype.UUID, currency string, expected, actual int64) {
+		findings = append(findings, models.LedgerFinding{
+			Type:           findingType,
+			SubjectID:      repository.FromPgUUID(subjectID),
+			Currency:       currency,
+			ExpectedMicros: expected,
+			ActualMicros:   actual,
+		})
+	}
+
+	balances, err := queries.ListAccountBalanceDrifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list account balance drifts: %w", err)
+	}
+	for _, row := range balances {
+		add(domain.FindingTypeAccountBalanceDrift, row.ID, row.Currency, row.EntriesNet, row.Balance)
+	}
+
+	locked, err := queries.ListLockedFundsDrifts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list locked funds drifts: %w", err)
+	}
+	for _, row := range locked {
+		add(domain.FindingTypeLockedFundsDrift, row.ID, row.Currency, row.OpenPayoutMicros, row.LockedMicros)
+	}
+
+	unbalanced, err := queries.ListUnbalancedTransactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list unbalanced transactions: %w", err)
+	}
+	for _, row := range unbalanced {
+		add(domain.FindingTypeUnbalancedTx, row.TransactionID, row.Currency, 0, row.NetAmount)
+	}
+
+	err = s.recordFindings(ctx, now, findings, func(qtx *repository.Queries, seenAt pgtype.Timestamptz) (int64, error) {
+		return qtx.ResolveReconciliationFindings(ctx, repository.ResolveReconciliationFindingsParams{
+			FindingTypes: []string{domain.FindingTypeAccountBalanceDrift, domain.FindingTypeLockedFundsDrift, domain.FindingTypeUnbalancedTx},
+			SeenBefore:   seenAt,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
 
-		imbalances, byCurrencyErr := queries.GetLedgerCurrencyImbalances(ctx)
-		if byCurrencyErr == nil {
-			for _, row := range imbalances {
-				observability.IncrementLedgerImbalance(row.Currency)
-				zap.L().Error("ledger imbalance by currency", zap.String("currency", row.Currency), zap.Int64("net_amount", row.NetAmount))
+// recordFindings upserts each finding as open, then calls resolve to close
+// the open findings the run checked but no longer sees.
+func (s *ReconciliationService) recordFindings(ctx context.Context, now time.Time, findings []models.LedgerFinding, resolve func(*repository.Queries, pgtype.Timestamptz) (int64, error)) error {
+	seenAt := pgtype.Timestamptz{Time: now, Valid: true}
+	return s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		for _, f := range findings {
+			row, err := qtx.UpsertReconciliationFinding(ctx, repository.UpsertReconciliationFindingParams{
+				ID:             repository.ToPgUUID(uuid.New()),
+				FindingType:    f.Type,
+				SubjectID:      repository.ToPgUUID(f.SubjectID),
+				Currency:       f.Currency,
+				Period:         f.Period,
+				ExpectedMicros: f.ExpectedMicros,
+				ActualMicros:   f.ActualMicros,
+				SeenAt:         seenAt,
+			})
+			if err != nil {
+				return fmt.Errorf("upsert reconciliation finding: %w", err)
 			}
-		} else {
-			zap.L().Error("failed to load currency imbalances", zap.Error(byCurrencyErr))
+			observability.IncrementLedgerBreak(f.Type)
+			zap.L().Error("CRITICAL: ledger invariant violated",
+				zap.String("type", f.Type),
+				zap.String("subject_id", f.SubjectID.String()),
+				zap.String("currency", f.Currency),
+				zap.String("period", f.Period),
+				zap.Int64("expected_micros", f.ExpectedMicros),
+				zap.Int64("actual_micros", f.ActualMicros),
+				zap.Time("first_seen_at", row.FirstSeenAt.Time),
+			)
+		}
+		resolved, err := resolve(qtx, seenAt)
+		if err != nil {
+			return fmt.Errorf("resolve reconciliation findings: %w", err)
+		}
+		if resolved > 0 {
+			zap.L().Info("ledger findings resolved", zap.Int64("count", resolved))
 		}
 		return nil
-	}
+	})
+}
 
-	zap.L().Info("Ledger Balanced")
-	return nil
+func toReconciliationRunModel(row repository.ReconciliationRun) (models.ReconciliationRun, error) {
+	run := models.ReconciliationRun{
+		ID:             repository.FromPgUUID(row.ID),
+		Trigger:        row.Trigger,
+		Scope:          row.Scope,
+		Status:         row.Status,
+		TriggeredBy:    optionalUUID(row.TriggeredBy),
+		StartedAt:      row.StartedAt.Time,
+		FinishedAt:     optionalTime(row.FinishedAt),
+		CurrencyTotals: []models.CurrencyTotal{},
+		Findings:       []models.LedgerFinding{},
+		FindingCount:   row.FindingCount,
+		Error:          derefString(row.Error),
+	}
+	if err := json.Unmarshal(row.CurrencyTotals, &run.CurrencyTotals); err != nil {
+		return models.ReconciliationRun{}, fmt.Errorf("decode reconciliation totals: %w", err)
+	}
+	if err := json.Unmarshal(row.Findings, &run.Findings); err != nil {
+		return models.ReconciliationRun{}, fmt.Errorf("decode reconciliation findings: %w", err)
+	}
+	return run, nil
 }
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
 	"github.com/ayo6706/payment-multicurrency/internal/models"
@@ -73,3 +75,236 @@ func TestReconciliationRun(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEqual(t, int64(0), net)
 }
+
+func TestReconciliationRecordsLedgerFindings(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repoSvc := repository.NewRepository(db)
+	queries := repository.New(db)
+	store := repository.NewStore(db)
+	reconcileSvc := NewReconciliationService(store)
+	webhookSvc := NewWebhookService(store, "secret", false)
+
+	user := &models.User{ID: uuid.New(), Username: "rec-findings", Email: "rec-findings@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	account := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 0}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	body, err := json.Marshal(DepositWebhookPayload{AccountID: account.ID.String(), AmountMicros: 500_000, Currency: "USD", Reference: "rec-findings-dep"})
+	require.NoError(t, err)
+	_, err = webhookSvc.HandleDepositWebhook(ctx, body, signPayload("secret", body))
+	require.NoError(t, err)
+
+	openFindings := func() map[string]repository.ReconciliationFinding {
+		rows, err := queries.ListReconciliationFindings(ctx, repository.ListReconciliationFindingsParams{Status: "OPEN", Limit: 50})
+		require.NoError(t, err)
+		out := map[string]repository.ReconciliationFinding{}
+		for _, row := range rows {
+			out[row.FindingType] = row
+		}
+		return out
+	}
+
+	require.NoError(t, reconcileSvc.Run(ctx))
+	require.Empty(t, openFindings())
+
+	_, err = db.Exec(ctx, "UPDATE accounts SET balance = balance + 1, locked_micros = 7 WHERE id = $1", repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	transactionID := uuid.New()
+	_, err = queries.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(transactionID),
+		Amount:      10_000,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "rec-half-entry",
+	})
+	require.NoError(t, err)
+	_, err = queries.CreateEntry(ctx, repository.CreateEntryParams{
+		ID:            repository.ToPgUUID(uuid.New()),
+		TransactionID: repository.ToPgUUID(transactionID),
+		AccountID:     repository.ToPgUUID(account.ID),
+		Amount:        10_000,
+		Direction:     domain.DirectionCredit,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, reconcileSvc.Run(ctx))
+	require.NoError(t, reconcileSvc.Run(ctx))
+	findings := openFindings()
+	require.Len(t, findings, 3)
+
+	balance := findings[domain.FindingTypeAccountBalanceDrift]
+	require.Equal(t, account.ID, repository.FromPgUUID(balance.SubjectID))
+	require.Equal(t, int64(510_000), balance.ExpectedMicros)
+	require.Equal(t, int64(500_001), balance.ActualMicros)
+	locked := findings[domain.FindingTypeLockedFundsDrift]
+	require.Equal(t, int64(0), locked.ExpectedMicros)
+	require.Equal(t, int64(7), locked.ActualMicros)
+	unbalanced := findings[domain.FindingTypeUnbalancedTx]
+	require.Equal(t, transactionID, repository.FromPgUUID(unbalanced.SubjectID))
+	require.Equal(t, int64(10_000), unbalanced.ActualMicros)
+
+	// Fixing the locked funds resolves that finding on the next run.
+	_, err = db.Exec(ctx, "UPDATE accounts SET locked_micros = 0 WHERE id = $1", repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.NoError(t, reconcileSvc.Run(ctx))
+	findings = openFindings()
+	require.Len(t, findings, 2)
+	require.NotContains(t, findings, domain.FindingTypeLockedFundsDrift)
+}
+
+func TestReconciliationRunsAreRecorded(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repoSvc := repository.NewRepository(db)
+	reconcileSvc := NewReconciliationService(repository.NewStore(db))
+
+	admin := &models.User{ID: uuid.New(), Username: "rec-runs", Email: "rec-runs@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, admin))
+
+	require.NoError(t, reconcileSvc.Run(ctx))
+	runs, err := reconcileSvc.ListRuns(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, domain.RunTriggerScheduled, runs[0].Trigger)
+	require.Equal(t, domain.RunStatusPassed, runs[0].Status)
+	require.NotNil(t, runs[0].FinishedAt)
+	require.Empty(t, runs[0].Findings)
+
+	// A run left RUNNING blocks new runs until it goes stale.
+	_, err = db.Exec(ctx, "INSERT INTO reconciliation_runs (id, trigger, scope, started_at) VALUES ($1, 'SCHEDULED', 'FULL', NOW())", repository.ToPgUUID(uuid.New()))
+	require.NoError(t, err)
+	_, err = reconcileSvc.TriggerRun(ctx, admin.ID, "")
+	require.ErrorIs(t, err, ErrReconciliationRunInProgress)
+	_, err = db.Exec(ctx, "UPDATE reconciliation_runs SET started_at = NOW() - INTERVAL '7 hours' WHERE status = 'RUNNING'")
+	require.NoError(t, err)
+
+	// An account whose balance has no entries behind it fails the run.
+	account := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "EUR", Balance: 250_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+	_, err = reconcileSvc.TriggerRun(ctx, admin.ID, "PARTIAL")
+	require.ErrorIs(t, err, ErrReconciliationScopeInvalid)
+	triggered, err := reconcileSvc.TriggerRun(ctx, admin.ID, domain.RunScopeFull)
+	require.NoError(t, err)
+	require.Equal(t, domain.RunStatusRunning, triggered.Status)
+	require.Equal(t, domain.RunScopeFull, triggered.Scope)
+	require.Equal(t, admin.ID, *triggered.TriggeredBy)
+
+	var run *models.ReconciliationRun
+	require.Eventually(t, func() bool {
+		run, err = reconcileSvc.GetRun(ctx, triggered.ID)
+		require.NoError(t, err)
+		return run.Status != domain.RunStatusRunning
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Equal(t, domain.RunStatusFailed, run.Status)
+	require.Equal(t, int32(1), run.FindingCount)
+	require.Equal(t, domain.FindingTypeAccountBalanceDrift, run.Findings[0].Type)
+	require.Equal(t, account.ID, run.Findings[0].SubjectID)
+
+	runs, err = reconcileSvc.ListRuns(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	require.Equal(t, domain.RunStatusError, runs[2].Status, "the stale run is abandoned")
+}
+
+func TestIncrementalReconciliationCheckpointsClosedDays(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repoSvc := repository.NewRepository(db)
+	queries := repository.New(db)
+	reconcileSvc := NewReconciliationService(repository.NewStore(db))
+
+	user := &models.User{ID: uuid.New(), Username: "rec-incr", Email: "rec-incr@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, user))
+	accountA := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: 100_000}
+	accountB := &models.Account{ID: uuid.New(), UserID: user.ID, Currency: "USD", Balance: -100_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, accountA))
+	require.NoError(t, repoSvc.CreateAccount(ctx, accountB))
+
+	transactionID := uuid.New()
+	_, err := queries.CreateTransaction(ctx, repository.CreateTransactionParams{
+		ID:          repository.ToPgUUID(transactionID),
+		Amount:      100_000,
+		Currency:    "USD",
+		Type:        domain.TxTypeTransfer,
+		Status:      domain.TxStatusCompleted,
+		ReferenceID: "rec-incr-old",
+	})
+	require.NoError(t, err)
+	insertOldEntry := func(accountID uuid.UUID, amount int64, direction string) {
+		_, err := db.Exec(ctx, "INSERT INTO entries (id, transaction_id, account_id, amount, direction, created_at) VALUES ($1,$2,$3,$4,$5,NOW() - INTERVAL '40 days')",
+			repository.ToPgUUID(uuid.New()), repository.ToPgUUID(transactionID), repository.ToPgUUID(accountID), amount, direction)
+		require.NoError(t, err)
+	}
+	insertOldEntry(accountA.ID, 100_000, domain.DirectionCredit)
+	insertOldEntry(accountB.ID, 100_000, domain.DirectionDebit)
+	usdTotal := func(run models.ReconciliationRun) models.CurrencyTotal {
+		for _, total := range run.CurrencyTotals {
+			if total.Currency == "USD" {
+				return total
+			}
+		}
+		t.Fatalf("run %s has no USD total", run.ID)
+		return models.CurrencyTotal{}
+	}
+
+	// The first run folds every closed day into the checkpoint and seals the
+	// closed months.
+	require.NoError(t, reconcileSvc.Run(ctx))
+	checkpoint, err := queries.GetLedgerCheckpoint(ctx)
+	require.NoError(t, err)
+	require.True(t, checkpoint.CheckpointedUntil.Time.After(time.Now().AddDate(0, 0, -2)))
+	runs, err := reconcileSvc.ListRuns(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, domain.RunScopeIncremental, runs[0].Scope)
+	require.Equal(t, domain.RunStatusPassed, runs[0].Status)
+	require.Equal(t, int64(2), usdTotal(runs[0]).EntryCount)
+	var sealStatus string
+	sealQuery := "SELECT status FROM ledger_month_seals WHERE month = date_trunc('month', (NOW() - INTERVAL '40 days') AT TIME ZONE 'UTC')::date"
+	require.NoError(t, db.QueryRow(ctx, sealQuery).Scan(&sealStatus))
+	require.Equal(t, domain.LedgerSealStatusSealed, sealStatus)
+
+	// A late entry in the sealed month marks its day dirty; the next run
+	// recomputes the day and reports the changed seal.
+	insertOldEntry(accountA.ID, 1, domain.DirectionCredit)
+	var dirty int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM ledger_dirty_days").Scan(&dirty))
+	require.Equal(t, 1, dirty)
+
+	require.NoError(t, reconcileSvc.Run(ctx))
+	runs, err = reconcileSvc.ListRuns(ctx, 10, 0)
+	require.NoError(t, err)
+	incremental := runs[0]
+	require.Equal(t, domain.RunStatusFailed, incremental.Status)
+	require.Equal(t, int64(3), usdTotal(incremental).EntryCount)
+	require.Equal(t, int64(1), usdTotal(incremental).NetMicros)
+	found := map[string]models.LedgerFinding{}
+	for _, f := range incremental.Findings {
+		found[f.Type] = f
+	}
+	require.Contains(t, found, domain.FindingTypeSealedPeriodChanged)
+	require.Equal(t, int64(100_001), found[domain.FindingTypeAccountBalanceDrift].ExpectedMicros)
+	require.Equal(t, transactionID, found[domain.FindingTypeUnbalancedTx].SubjectID)
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM ledger_dirty_days").Scan(&dirty))
+	require.Zero(t, dirty)
+	require.NoError(t, db.QueryRow(ctx, sealQuery).Scan(&sealStatus))
+	require.Equal(t, domain.LedgerSealStatusBroken, sealStatus)
+
+	// A full rescan agrees with the checkpointed totals.
+	triggered, err := reconcileSvc.TriggerRun(ctx, user.ID, domain.RunScopeFull)
+	require.NoError(t, err)
+	var full *models.ReconciliationRun
+	require.Eventually(t, func() bool {
+		full, err = reconcileSvc.GetRun(ctx, triggered.ID)
+		require.NoError(t, err)
+		return full.Status != domain.RunStatusRunning
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Equal(t, usdTotal(incremental), usdTotal(*full))
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/sanctions"
+	"go.uber.org/zap"
+)
+
+// sanctionsLoadBatchSize bounds the rows sent in one insert while loading a list.
+const sanctionsLoadBatchSize = 1000
+
+// ErrInvalidSanctionsList indicates an unknown source or a list with no usable entries.
+var ErrInvalidSanctionsList = errors.New("invalid sanctions list")
+
+// ScreeningHit is a screened name that matched a sanctions list entry.
+type ScreeningHit struct {
+	ScreenedName string  `json:"screened_name"`
+	Source       string  `json:"source"`
+	ExternalID   string  `json:"external_id"`
+	MatchedName  string  `json:"matched_name"`
+	Score        float64 `json:"score"`
+}
+
+// SanctionsListLoad records one load of a sanctions list.
+type SanctionsListLoad struct {
+	ID         int64     `json:"id"`
+	Source     string    `json:"source"`
+	EntryCount int32     `json:"entry_count"`
+	Checksum   string    `json:"checksum"`
+	LoadedBy   string    `json:"loaded_by"`
+	LoadedAt   time.Time `json:"loaded_at"`
+}
+
+// ScreeningService matches payee names against the locally loaded sanctions
+// lists. The matcher is built from the database and rebuilt whenever a newer
+// list load is seen, so every instance screens against the same lists. A nil
+// *ScreeningService screens nothing.
+type ScreeningService struct {
+	store      QueryStore
+	thresholds sanctions.Thresholds
+
+	mu      sync.Mutex
+	version int64
+	matcher *sanctions.Matcher
+}
+
+// NewScreeningService creates a new ScreeningService instance using the
+// default match threshold.
+func NewScreeningService(store QueryStore) *ScreeningService {
+	return &ScreeningService{store: store, version: -1}
+}
+
+// WithThresholds sets the minimum match scores per list source.
+func (s *ScreeningService) WithThresholds(thresholds sanctions.Thresholds) *ScreeningService {
+	s.thresholds = thresholds
+	return s
+}
+
+// LoadList replaces the stored entries of source with entries. checksum
+// identifies the file they were parsed from.
+func (s *ScreeningService) LoadList(ctx context.Context, source string, entries []sanctions.Entry, checksum, loadedBy string) (*SanctionsListLoad, error) {
+	source = strings.ToUpper(strings.TrimSpace(source))
+	loadedBy = strings.TrimSpace(loadedBy)
+	if !sanctions.ValidSource(source) {
+		return nil, fmt.Errorf("%w: unsupported source %q", ErrInvalidSanctionsList, source)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no entries", ErrInvalidSanctionsList)
+	}
+	if loadedBy == "" {
+		return nil, fmt.Errorf("%w: loaded_by is required", ErrInvalidSanctionsList)
+	}
+
+	var load repository.SanctionsListLoad
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		if _, err := qtx.DeleteSanctionsEntriesBySource(ctx, source); err != nil {
+			return fmt.Errorf("delete sanctions entries: %w", err)
+		}
+		var err error
+		load, err = qtx.CreateSanctionsListLoad(ctx, repository.CreateSanctionsListLoadParams{
+			Source:     source,
+			EntryCount: int32(len(entries)),
+			Checksum:   checksum,
+			LoadedBy:   loadedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("create sanctions list load: %w", err)
+		}
+		for start := 0; start < len(entries); start += sanctionsLoadBatchSize {
+			batch := entries[start:min(start+sanctionsLoadBatchSize, len(entries))]
+			params := repository.InsertSanctionsEntriesParams{
+				LoadID:      load.ID,
+				Source:      source,
+				ExternalIds: make([]string, 0, len(batch)),
+				Names:       make([]string, 0, len(batch)),
+				EntryTypes:  make([]string, 0, len(batch)),
+				Programs:    make([]string, 0, len(batch)),
+			}
+			for _, entry := range batch {
+				if entry.Source != source {
+					return fmt.Errorf("%w: entry %s is from %s", ErrInvalidSanctionsList, entry.ExternalID, entry.Source)
+				}
+				params.ExternalIds = append(params.ExternalIds, entry.ExternalID)
+				params.Names = append(params.Names, entry.Name)
+				params.EntryTypes = append(params.EntryTypes, entry.EntryType)
+				params.Programs = append(params.Programs, entry.Programs)
+			}
+			if _, err := qtx.InsertSanctionsEntries(ctx, params); err != nil {
+				return fmt.Errorf("insert sanctions entries: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	zap.L().Info("sanctions list loaded", zap.String("source", source), zap.Int("entries", len(entries)), zap.String("loaded_by", loadedBy))
+	out := sanctionsListLoadFromRow(load)
+	return &out, nil
+}
+
+// Screen returns the best sanctions match for name, or nil when nothing
+// reaches its threshold.
+func (s *ScreeningService) Screen(ctx context.Context, name string) (*ScreeningHit, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return s.screen(ctx, s.store.Queries(), name)
+}
+
+// screen is Screen using q, so it can run inside a caller's transaction.
+func (s *ScreeningService) screen(ctx context.Context, q *repository.Queries, name string) (*ScreeningHit, error) {
+	if s == nil || strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	matcher, err := s.currentMatcher(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	match, ok := matcher.Best(name, s.thresholds)
+	if !ok {
+		return nil, nil
+	}
+	return &ScreeningHit{
+		ScreenedName: strings.TrimSpace(name),
+		Source:       match.Entry.Source,
+		ExternalID:   match.Entry.ExternalID,
+		MatchedName:  match.Entry.Name,
+		Score:        match.Score,
+	}, nil
+}
+
+// currentMatcher returns the matcher for the latest list load, rebuilding
+// it when a newer load has been committed.
+func (s *ScreeningService) currentMatcher(ctx context.Context, q *repository.Queries) (*sanctions.Matcher, error) {
+	version, err := q.GetSanctionsListVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get sanctions list version: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.matcher != nil && s.version == version {
+		return s.matcher, nil
+	}
+	rows, err := q.ListSanctionsEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sanctions entries: %w", err)
+	}
+	entries := make([]sanctions.Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, sanctions.Entry{
+			Source:     row.Source,
+			ExternalID: row.ExternalID,
+			Name:       row.Name,
+			EntryType:  row.EntryType,
+			Programs:   row.Programs,
+		})
+	}
+	s.matcher = sanctions.NewMatcher(entries)
+	s.version = version
+	return s.matcher, nil
+}
+
+// recordScreeningHit logs and counts a hit on subject, such as "payout".
+func recordScreeningHit(subject string, hit *ScreeningHit) {
+	zap.L().Warn("sanctions screening hit",
+		zap.String("subject", subject),
+		zap.String("source", hit.Source),
+		zap.String("external_id", hit.ExternalID),
+		zap.Float64("score", hit.Score),
+	)
+	observability.IncrementScreeningHit(subject, hit.Source)
+}
+
+func sanctionsListLoadFromRow(row repository.SanctionsListLoad) SanctionsListLoad {
+	return SanctionsListLoad{
+		ID:         row.ID,
+		Source:     row.Source,
+		EntryCount: row.EntryCount,
+		Checksum:   row.Checksum,
+		LoadedBy:   row.LoadedBy,
+		LoadedAt:   row.LoadedAt.Time,
+	}
+}
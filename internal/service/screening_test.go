@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/sanctions"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadListValidation(t *testing.T) {
+	svc := NewScreeningService(panicStore{})
+	ctx := context.Background()
+	entries := []sanctions.Entry{{Source: sanctions.SourceOFACSDN, ExternalID: "1", Name: "VOLKOV, Ivan"}}
+
+	_, err := svc.LoadList(ctx, "UN_CONSOLIDATED", entries, "sum", "ops")
+	require.ErrorIs(t, err, ErrInvalidSanctionsList)
+	_, err = svc.LoadList(ctx, sanctions.SourceOFACSDN, nil, "sum", "ops")
+	require.ErrorIs(t, err, ErrInvalidSanctionsList)
+	_, err = svc.LoadList(ctx, sanctions.SourceOFACSDN, entries, "sum", " ")
+	require.ErrorIs(t, err, ErrInvalidSanctionsList)
+
+	var nilSvc *ScreeningService
+	hit, err := nilSvc.Screen(ctx, "Ivan Volkov")
+	require.NoError(t, err)
+	require.Nil(t, hit)
+}
+
+func TestPayoutScreeningHold(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	screening := NewScreeningService(store)
+	gateway := &stubGateway{ref: "MOCK-REF"}
+	payoutSvc := NewPayoutService(store, gateway).WithScreening(screening)
+	ctx := context.Background()
+
+	maker := &models.User{ID: uuid.New(), Username: "maker", Email: "maker@example.com", Role: "admin"}
+	checker := &models.User{ID: uuid.New(), Username: "checker", Email: "checker@example.com", Role: "admin"}
+	require.NoError(t, repoSvc.CreateUser(ctx, maker))
+	require.NoError(t, repoSvc.CreateUser(ctx, checker))
+	account := &models.Account{ID: uuid.New(), UserID: maker.ID, Currency: "USD", Balance: 5_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	_, err := screening.LoadList(ctx, sanctions.SourceOFACSDN, []sanctions.Entry{
+		{Source: sanctions.SourceOFACSDN, ExternalID: "36", Name: "VOLKOV, Ivan Petrovich", EntryType: "individual", Programs: "RUSSIA-EO14024"},
+	}, "checksum", "ops")
+	require.NoError(t, err)
+
+	request := func(name, ref string) *PayoutResponse {
+		resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+			AccountID:    account.ID,
+			AmountMicros: 1_000_000,
+			Currency:     "USD",
+			Destination:  PayoutDestinationInput{IBAN: "GB29NWBK60161331926819", Name: name},
+			ReferenceID:  ref,
+			RequestedBy:  &maker.ID,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	clean := request("John Smith", "screening-clean")
+	require.Equal(t, domain.PayoutStatusPending, clean.Status)
+	held := request("Ivan Petrovich Volkov", "screening-held")
+	require.Equal(t, domain.PayoutStatusScreeningHold, held.Status)
+	confirmed := request("Iván Volkov Petrovich", "screening-confirmed")
+	require.Equal(t, domain.PayoutStatusScreeningHold, confirmed.Status)
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 10))
+	require.Len(t, gateway.sentKeys, 1, "worker must not claim held payouts")
+
+	queue, err := payoutSvc.ListScreeningHoldPayouts(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, queue, 2)
+	require.Equal(t, "36", queue[0].Screening.ExternalID)
+	require.Equal(t, domain.PayoutStatusPending, queue[0].Screening.ReleaseStatus)
+
+	_, err = payoutSvc.ResolveScreeningHold(ctx, ResolveScreeningRequest{PayoutID: held.PayoutID, Decision: ScreeningDecisionClear, Reason: "different person", ActorID: maker.ID})
+	require.ErrorIs(t, err, ErrPayoutSelfApproval)
+	_, err = payoutSvc.ResolveScreeningHold(ctx, ResolveScreeningRequest{PayoutID: held.PayoutID, Decision: "approve", Reason: "different person", ActorID: checker.ID})
+	require.ErrorIs(t, err, ErrInvalidScreeningDecision)
+
+	cleared, err := payoutSvc.ResolveScreeningHold(ctx, ResolveScreeningRequest{PayoutID: held.PayoutID, Decision: ScreeningDecisionClear, Reason: "date of birth differs", ActorID: checker.ID})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusPending, cleared.Status)
+	_, err = payoutSvc.ResolveScreeningHold(ctx, ResolveScreeningRequest{PayoutID: held.PayoutID, Decision: ScreeningDecisionClear, Reason: "again", ActorID: checker.ID})
+	require.ErrorIs(t, err, ErrPayoutNotInScreeningHold)
+
+	rejected, err := payoutSvc.ResolveScreeningHold(ctx, ResolveScreeningRequest{PayoutID: confirmed.PayoutID, Decision: ScreeningDecisionConfirmMatch, Reason: "listed party", ActorID: checker.ID})
+	require.NoError(t, err)
+	require.Equal(t, domain.PayoutStatusRejected, rejected.Status)
+
+	queries := repository.New(db)
+	accRow, err := queries.GetAccountBalanceAndLocked(ctx, repository.ToPgUUID(account.ID))
+	require.NoError(t, err)
+	require.Equal(t, int64(3_000_000), accRow.Balance)
+	require.Equal(t, int64(1_000_000), accRow.LockedMicros, "only the cleared payout stays locked")
+
+	txRow, err := queries.GetTransaction(ctx, repository.ToPgUUID(rejected.TransactionID))
+	require.NoError(t, err)
+	require.Equal(t, domain.TxStatusFailed, txRow.Status)
+
+	require.NoError(t, payoutSvc.ProcessPayouts(ctx, 10))
+	require.Len(t, gateway.sentKeys, 2, "a cleared payout is dispatched")
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Reasons a session ends, recorded in user_sessions.revoked_reason.
+const (
+	sessionRevokedLogout        = "logout"
+	sessionRevokedByUser        = "revoked_by_user"
+	sessionRevokedRefreshReuse  = "refresh_token_reuse"
+	sessionRevokedPasswordReset = "password_reset"
+	sessionRevokedRoleChanged   = "role_changed"
+)
+
+// revokedTokenTTLMargin keeps a revoked session's marker in Redis a little
+// past its access token's expiry to cover clock skew between instances.
+const revokedTokenTTLMargin = time.Minute
+
+// sessionCacheTTL bounds how long Redis may answer for a session without
+// Postgres. A revocation whose Redis write is lost is honoured after at most
+// this long.
+const sessionCacheTTL = 30 * time.Second
+
+// sessionRevokedMarker is cached in place of the current jti once a session
+// ends.
+const sessionRevokedMarker = "revoked"
+
+// cacheRotatedJTIScript caches the jti a refresh issued unless the session's
+// entry is already the revoked marker, which a revocation committed after
+// the refresh may have written first.
+const cacheRotatedJTIScript = `
+if redis.call("GET", KEYS[1]) == ARGV[2] then
+  return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`
+
+// sessionCacheWriteAttempts is how often a revocation retries its Redis
+// write before leaving the stale entry to expire.
+const sessionCacheWriteAttempts = 3
+
+var (
+	// ErrInvalidRefreshToken indicates an unknown, used or expired refresh
+	// token, or one whose session has ended.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrSessionNotFound indicates a session that does not exist, belongs to
+	// another user or has already ended.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrInvalidRole indicates a role that does not exist, or a change to or
+	// from the system role.
+	ErrInvalidRole = errors.New("invalid role")
+)
+
+// SessionClient describes where a login came from.
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionTokens is what a login or refresh issues. The caller signs the
+// access token with SessionID, AccessJTI, Role and the role's Permissions,
+// valid until AccessExpiresAt, and hands RefreshToken to the client.
+type SessionTokens struct {
+	SessionID        uuid.UUID
+	UserID           uuid.UUID
+	Role             string
+	Permissions      []string
+	AccessJTI        uuid.UUID
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Session is an active login as shown to its user.
+type Session struct {
+	ID              uuid.UUID `json:"id"`
+	UserAgent       string    `json:"user_agent"`
+	IPAddress       string    `json:"ip_address"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastRefreshedAt time.Time `json:"last_refreshed_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// RoleChange is the result of ChangeRole.
+type RoleChange struct {
+	UserID          uuid.UUID `json:"user_id"`
+	PreviousRole    string    `json:"previous_role"`
+	Role            string    `json:"role"`
+	SessionsRevoked int       `json:"sessions_revoked"`
+}
+
+// revokedAccess is the access token of a session that just ended.
+type revokedAccess struct {
+	sessionID uuid.UUID
+	expiresAt time.Time
+}
+
+// WithSessionTTLs sets how long access tokens and sessions (and so their
+// refresh tokens) last.
+func (s *AuthService) WithSessionTTLs(access, refresh time.Duration) *AuthService {
+	s.accessTTL = access
+	s.refreshTTL = refresh
+	return s
+}
+
+// WithRedis caches session state in Redis so SessionActive usually answers
+// without a database read. Postgres answers when Redis has no entry or fails.
+func (s *AuthService) WithRedis(client redis.Cmdable) *AuthService {
+	s.redis = client
+	return s
+}
+
+// StartSession opens a session for user, who has just authenticated.
+func (s *AuthService) StartSession(ctx context.Context, user *models.User, client SessionClient) (*SessionTokens, error) {
+	now := s.now()
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+	tokens := &SessionTokens{
+		SessionID:        uuid.New(),
+		UserID:           user.ID,
+		Role:             user.Role,
+		AccessJTI:        uuid.New(),
+		AccessExpiresAt:  now.Add(s.accessTTL),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(s.refreshTTL),
+	}
+	if tokens.AccessExpiresAt.After(tokens.RefreshExpiresAt) {
+		tokens.AccessExpiresAt = tokens.RefreshExpiresAt
+	}
+
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		if err := qtx.CreateUserSession(ctx, repository.CreateUserSessionParams{
+			ID:              repository.ToPgUUID(tokens.SessionID),
+			UserID:          repository.ToPgUUID(user.ID),
+			AccessJti:       repository.ToPgUUID(tokens.AccessJTI),
+			AccessExpiresAt: pgtype.Timestamptz{Time: tokens.AccessExpiresAt, Valid: true},
+			UserAgent:       truncate(client.UserAgent, 512),
+			IpAddress:       client.IPAddress,
+			Now:             pgtype.Timestamptz{Time: now, Valid: true},
+			ExpiresAt:       pgtype.Timestamptz{Time: tokens.RefreshExpiresAt, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		if err := qtx.CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+			TokenHash: hashToken(refreshToken),
+			SessionID: repository.ToPgUUID(tokens.SessionID),
+			CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to store refresh token: %w", err)
+		}
+		permissions, err := qtx.ListRolePermissions(ctx, user.Role)
+		if err != nil {
+			return fmt.Errorf("failed to list role permissions: %w", err)
+		}
+		tokens.Permissions = permissions
+		metadata, err := json.Marshal(map[string]string{"session_id": tokens.SessionID.String(), "ip_address": client.IPAddress})
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "user", user.ID, &user.ID, "session_started", "", "", metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new
+// refresh token. The old refresh token and access token stop working. A
+// refresh token used twice means it was copied, so its session is ended.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (*SessionTokens, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	now := s.now()
+	next, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate refresh token: %w", err)
+	}
+
+	var tokens *SessionTokens
+	var revoked []revokedAccess
+	var reusedSession, rotatedSession uuid.UUID
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		row, err := qtx.GetRefreshTokenForUpdate(ctx, hashToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to fetch refresh token: %w", err)
+		}
+		sessionID := repository.FromPgUUID(row.SessionID)
+		userID := repository.FromPgUUID(row.UserID)
+		if row.RevokedAt.Valid || !row.ExpiresAt.Time.After(now) {
+			return ErrInvalidRefreshToken
+		}
+		if row.UsedAt.Valid {
+			reusedSession = sessionID
+			revoked, err = s.revokeSession(ctx, qtx, userID, sessionID, sessionRevokedRefreshReuse)
+			return err
+		}
+
+		if err := qtx.MarkRefreshTokenUsed(ctx, repository.MarkRefreshTokenUsedParams{
+			TokenHash: hashToken(refreshToken),
+			Now:       pgtype.Timestamptz{Time: now, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to mark refresh token used: %w", err)
+		}
+		tokens = &SessionTokens{
+			SessionID:        sessionID,
+			UserID:           userID,
+			Role:             row.Role,
+			AccessJTI:        uuid.New(),
+			AccessExpiresAt:  now.Add(s.accessTTL),
+			RefreshToken:     next,
+			RefreshExpiresAt: row.ExpiresAt.Time,
+		}
+		if tokens.AccessExpiresAt.After(tokens.RefreshExpiresAt) {
+			tokens.AccessExpiresAt = tokens.RefreshExpiresAt
+		}
+		if err := qtx.RotateSessionAccessToken(ctx, repository.RotateSessionAccessTokenParams{
+			ID:              row.SessionID,
+			AccessJti:       repository.ToPgUUID(tokens.AccessJTI),
+			AccessExpiresAt: pgtype.Timestamptz{Time: tokens.AccessExpiresAt, Valid: true},
+			Now:             pgtype.Timestamptz{Time: now, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to rotate access token: %w", err)
+		}
+		if err := qtx.CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+			TokenHash: hashToken(next),
+			SessionID: row.SessionID,
+			CreatedAt: pgtype.Timestamptz{Time: now, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to store refresh token: %w", err)
+		}
+		if tokens.Permissions, err = qtx.ListRolePermissions(ctx, row.Role); err != nil {
+			return fmt.Errorf("failed to list role permissions: %w", err)
+		}
+		rotatedSession = sessionID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.markSessionsRevoked(ctx, revoked)
+	if rotatedSession != uuid.Nil {
+		s.cacheRotatedSession(ctx, tokens)
+	}
+	if reusedSession != uuid.Nil {
+		zap.L().Warn("refresh token reused; session revoked", zap.String("session_id", reusedSession.String()))
+		return nil, ErrInvalidRefreshToken
+	}
+	return tokens, nil
+}
+
+// SessionActive reports whether jti is the current access token of an
+// active session. Redis answers when it holds the session's current jti or
+// its revoked marker; otherwise, or when Redis fails, the session row does,
+// and an active answer is cached for at most sessionCacheTTL.
+func (s *AuthService) SessionActive(ctx context.Context, sessionID, jti uuid.UUID) (bool, error) {
+	key := sessionCacheKey(sessionID)
+	if s.redis != nil {
+		cached, err := s.redis.Get(ctx, key).Result()
+		switch {
+		case err == nil && cached == jti.String():
+			return true, nil
+		case err == nil && cached == sessionRevokedMarker:
+			return false, nil
+		case err != nil && !errors.Is(err, redis.Nil):
+			zap.L().Warn("redis session check failed, using postgres", zap.Error(err))
+		}
+	}
+	row, err := s.store.Queries().GetSessionState(ctx, repository.ToPgUUID(sessionID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch session: %w", err)
+	}
+	now := s.now()
+	if row.RevokedAt.Valid || !row.ExpiresAt.Time.After(now) || repository.FromPgUUID(row.AccessJti) != jti {
+		return false, nil
+	}
+	if ttl := min(sessionCacheTTL, row.AccessExpiresAt.Time.Sub(now)); s.redis != nil && ttl > 0 {
+		// SetNX so a revoked marker written meanwhile is never overwritten.
+		if err := s.redis.SetNX(ctx, key, jti.String(), ttl).Err(); err != nil {
+			zap.L().Warn("failed to cache session state in redis", zap.Error(err))
+		}
+	}
+	return true, nil
+}
+
+// ListSessions returns the user's active sessions, newest first.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	rows, err := s.store.Queries().ListActiveUserSessions(ctx, repository.ListActiveUserSessionsParams{
+		UserID: repository.ToPgUUID(userID),
+		Now:    pgtype.Timestamptz{Time: s.now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessions := make([]Session, 0, len(rows))
+	for _, row := range rows {
+		sessions = append(sessions, Session{
+			ID:              repository.FromPgUUID(row.ID),
+			UserAgent:       row.UserAgent,
+			IPAddress:       row.IpAddress,
+			CreatedAt:       row.CreatedAt.Time,
+			LastRefreshedAt: row.LastRefreshedAt.Time,
+			ExpiresAt:       row.ExpiresAt.Time,
+		})
+	}
+	return sessions, nil
+}
+
+// Logout ends the session the caller's access token belongs to.
+func (s *AuthService) Logout(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.endSession(ctx, userID, sessionID, sessionRevokedLogout)
+}
+
+// RevokeSession ends one of the user's sessions.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.endSession(ctx, userID, sessionID, sessionRevokedByUser)
+}
+
+// RevokeAllSessions ends every session of the user, including the caller's,
+// and returns how many there were.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	var revoked []revokedAccess
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		var err error
+		revoked, err = s.revokeAllSessions(ctx, qtx, userID, sessionRevokedByUser)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.markSessionsRevoked(ctx, revoked)
+	return len(revoked), nil
+}
+
+// ChangeRole sets the user's role and ends all their sessions, so tokens
+// carrying the old role's permissions stop working at once.
+func (s *AuthService) ChangeRole(ctx context.Context, userID uuid.UUID, role string, actorID uuid.UUID) (*RoleChange, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" || role == "system" {
+		return nil, fmt.Errorf("%w: role cannot be empty or system", ErrInvalidRole)
+	}
+
+	change := &RoleChange{UserID: userID, Role: role}
+	var revoked []revokedAccess
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		exists, err := qtx.RoleExists(ctx, role)
+		if err != nil {
+			return fmt.Errorf("failed to check role: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: unknown role %q", ErrInvalidRole, role)
+		}
+		previous, err := qtx.UpdateUserRole(ctx, repository.UpdateUserRoleParams{ID: repository.ToPgUUID(userID), Role: role})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if previous == "system" {
+			return fmt.Errorf("%w: system users cannot change role", ErrInvalidRole)
+		}
+		change.PreviousRole = previous
+		if previous == role {
+			return nil
+		}
+		revoked, err = s.revokeAllSessions(ctx, qtx, userID, sessionRevokedRoleChanged)
+		if err != nil {
+			return err
+		}
+		return s.audit.Write(ctx, qtx, "user", userID, &actorID, "role_changed", previous, role, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.markSessionsRevoked(ctx, revoked)
+	change.SessionsRevoked = len(revoked)
+	return change, nil
+}
+
+// ListRoles returns every role with the permissions it grants.
+func (s *AuthService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	rows, err := s.store.Queries().ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	roles := make([]models.Role, 0, len(rows))
+	for _, row := range rows {
+		roles = append(roles, models.Role{Name: row.Name, Description: row.Description, Permissions: row.Permissions})
+	}
+	return roles, nil
+}
+
+func (s *AuthService) endSession(ctx context.Context, userID, sessionID uuid.UUID, reason string) error {
+	var revoked []revokedAccess
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		var err error
+		revoked, err = s.revokeSession(ctx, qtx, userID, sessionID, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.markSessionsRevoked(ctx, revoked)
+	return nil
+}
+
+func (s *AuthService) revokeSession(ctx context.Context, qtx *repository.Queries, userID, sessionID uuid.UUID, reason string) ([]revokedAccess, error) {
+	row, err := qtx.RevokeUserSession(ctx, repository.RevokeUserSessionParams{
+		ID:     repository.ToPgUUID(sessionID),
+		UserID: repository.ToPgUUID(userID),
+		Reason: reason,
+		Now:    pgtype.Timestamptz{Time: s.now(), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := s.auditSessionRevoked(ctx, qtx, userID, []uuid.UUID{sessionID}, reason); err != nil {
+		return nil, err
+	}
+	observability.IncrementSessionsRevoked(reason, 1)
+	return []revokedAccess{{sessionID: sessionID, expiresAt: row.AccessExpiresAt.Time}}, nil
+}
+
+func (s *AuthService) revokeAllSessions(ctx context.Context, qtx *repository.Queries, userID uuid.UUID, reason string) ([]revokedAccess, error) {
+	rows, err := qtx.RevokeAllUserSessions(ctx, repository.RevokeAllUserSessionsParams{
+		UserID: repository.ToPgUUID(userID),
+		Reason: reason,
+		Now:    pgtype.Timestamptz{Time: s.now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	revoked := make([]revokedAccess, 0, len(rows))
+	sessionIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		sessionIDs = append(sessionIDs, repository.FromPgUUID(row.ID))
+		revoked = append(revoked, revokedAccess{sessionID: repository.FromPgUUID(row.ID), expiresAt: row.AccessExpiresAt.Time})
+	}
+	if err := s.auditSessionRevoked(ctx, qtx, userID, sessionIDs, reason); err != nil {
+		return nil, err
+	}
+	observability.IncrementSessionsRevoked(reason, len(rows))
+	return revoked, nil
+}
+
+func (s *AuthService) auditSessionRevoked(ctx context.Context, qtx *repository.Queries, userID uuid.UUID, sessionIDs []uuid.UUID, reason string) error {
+	metadata, err := json.Marshal(map[string]any{"session_ids": sessionIDs, "reason": reason})
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	return s.audit.Write(ctx, qtx, "user", userID, nil, "sessions_revoked", "", "", metadata)
+}
+
+// markSessionsRevoked replaces the cached state of ended sessions with the
+// revoked marker until their access tokens expire. It runs after the
+// revocation commits and retries within the request. If Redis still fails,
+// SessionActive falls back to Postgres once the stale entry expires, at most
+// sessionCacheTTL later.
+func (s *AuthService) markSessionsRevoked(ctx context.Context, revoked []revokedAccess) {
+	if s.redis == nil {
+		return
+	}
+	now := s.now()
+	for _, session := range revoked {
+		ttl := max(session.expiresAt.Sub(now), 0) + revokedTokenTTLMargin
+		err := s.writeSessionCache(ctx, func() error {
+			return s.redis.Set(ctx, sessionCacheKey(session.sessionID), sessionRevokedMarker, ttl).Err()
+		})
+		if err != nil {
+			zap.L().Error("failed to record revoked session in redis", zap.String("session_id", session.sessionID.String()), zap.Error(err))
+		}
+	}
+}
+
+// cacheRotatedSession replaces a refreshed session's cached jti with the
+// new one. Writing it, rather than dropping the key, keeps a check that read
+// the session before the refresh committed from caching the old jti after
+// it: that check's SetNX finds the key taken. If Redis fails, the old jti
+// stays cached for at most sessionCacheTTL.
+func (s *AuthService) cacheRotatedSession(ctx context.Context, tokens *SessionTokens) {
+	if s.redis == nil {
+		return
+	}
+	ttl := min(sessionCacheTTL, tokens.AccessExpiresAt.Sub(s.now()))
+	if ttl <= 0 {
+		return
+	}
+	err := s.writeSessionCache(ctx, func() error {
+		return s.redis.Eval(ctx, cacheRotatedJTIScript, []string{sessionCacheKey(tokens.SessionID)},
+			tokens.AccessJTI.String(), sessionRevokedMarker, ttl.Milliseconds()).Err()
+	})
+	if err != nil {
+		zap.L().Error("failed to cache rotated session in redis", zap.String("session_id", tokens.SessionID.String()), zap.Error(err))
+	}
+}
+
+func (s *AuthService) writeSessionCache(ctx context.Context, write func() error) error {
+	var err error
+	for attempt := 1; attempt <= sessionCacheWriteAttempts; attempt++ {
+		if err = write(); err == nil {
+			return nil
+		}
+		if attempt < sessionCacheWriteAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 50 * time.Millisecond):
+			}
+		}
+	}
+	return err
+}
+
+func sessionCacheKey(sessionID uuid.UUID) string {
+	return "auth:session:" + sessionID.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
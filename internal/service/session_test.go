@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/permission"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionValidation(t *testing.T) {
+	svc := NewAuthService(panicStore{})
+	ctx := context.Background()
+
+	_, err := svc.RefreshSession(ctx, "  ")
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = svc.ChangeRole(ctx, uuid.New(), "system", uuid.New())
+	require.ErrorIs(t, err, ErrInvalidRole)
+	_, err = svc.ChangeRole(ctx, uuid.New(), "", uuid.New())
+	require.ErrorIs(t, err, ErrInvalidRole)
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	mailer := &capturingMailer{}
+	now := time.Now().UTC()
+	svc := NewAuthService(repository.NewStore(db)).WithSessionTTLs(10*time.Minute, 24*time.Hour).WithMailer(mailer)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, Registration{Username: "lee", Email: "lee@example.com", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	admin, err := svc.Register(ctx, Registration{Username: "boss", Email: "boss@example.com", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	active := func(tokens *SessionTokens) bool {
+		ok, err := svc.SessionActive(ctx, tokens.SessionID, tokens.AccessJTI)
+		require.NoError(t, err)
+		return ok
+	}
+
+	first, err := svc.StartSession(ctx, user, SessionClient{UserAgent: "laptop", IPAddress: "192.0.2.1"})
+	require.NoError(t, err)
+	require.Equal(t, now.Add(10*time.Minute), first.AccessExpiresAt)
+	require.True(t, active(first))
+
+	// Rotation replaces the access token and the refresh token.
+	now = now.Add(5 * time.Minute)
+	second, err := svc.RefreshSession(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, first.SessionID, second.SessionID)
+	require.WithinDuration(t, first.RefreshExpiresAt, second.RefreshExpiresAt, time.Millisecond)
+	require.False(t, active(first))
+	require.True(t, active(second))
+
+	// Reusing the first refresh token ends the session.
+	_, err = svc.RefreshSession(ctx, first.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	require.False(t, active(second))
+	_, err = svc.RefreshSession(ctx, second.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Sessions cannot be refreshed after they expire.
+	expiring, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+	now = now.Add(25 * time.Hour)
+	_, err = svc.RefreshSession(ctx, expiring.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	require.False(t, active(expiring))
+
+	laptop, err := svc.StartSession(ctx, user, SessionClient{UserAgent: "laptop"})
+	require.NoError(t, err)
+	phone, err := svc.StartSession(ctx, user, SessionClient{UserAgent: "phone"})
+	require.NoError(t, err)
+	sessions, err := svc.ListSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	require.ErrorIs(t, svc.RevokeSession(ctx, admin.ID, phone.SessionID), ErrSessionNotFound)
+	require.NoError(t, svc.Logout(ctx, user.ID, phone.SessionID))
+	require.ErrorIs(t, svc.Logout(ctx, user.ID, phone.SessionID), ErrSessionNotFound)
+	require.False(t, active(phone))
+	require.True(t, active(laptop))
+
+	// A password reset signs the user out everywhere.
+	require.NoError(t, svc.RequestPasswordReset(ctx, user.Email))
+	token := regexp.MustCompile(`(?m)^[A-Za-z0-9_-]{43}$`).FindString(mailer.sent[len(mailer.sent)-1].Body)
+	require.NoError(t, svc.ResetPassword(ctx, token, "Brand-New-Secret-7"))
+	require.False(t, active(laptop))
+
+	// So does a role change, and only a real change.
+	adminSession, err := svc.StartSession(ctx, admin, SessionClient{})
+	require.NoError(t, err)
+	require.Contains(t, adminSession.Permissions, permission.AccountsRead)
+	require.NotContains(t, adminSession.Permissions, permission.AccountsReadAny)
+	change, err := svc.ChangeRole(ctx, admin.ID, "user", user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, change.SessionsRevoked)
+	require.True(t, active(adminSession))
+	change, err = svc.ChangeRole(ctx, admin.ID, "admin", user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "user", change.PreviousRole)
+	require.Equal(t, 1, change.SessionsRevoked)
+	require.False(t, active(adminSession))
+
+	// Sessions carry the permissions of the role they started with.
+	_, err = svc.ChangeRole(ctx, admin.ID, "auditor", user.ID)
+	require.ErrorIs(t, err, ErrInvalidRole)
+	_, err = svc.ChangeRole(ctx, admin.ID, "support", user.ID)
+	require.NoError(t, err)
+	admin.Role = "support"
+	supportSession, err := svc.StartSession(ctx, admin, SessionClient{})
+	require.NoError(t, err)
+	require.Equal(t, "support", supportSession.Role)
+	require.Contains(t, supportSession.Permissions, permission.AccountsReadAny)
+	require.NotContains(t, supportSession.Permissions, permission.AccountsLifecycle)
+	refreshed, err := svc.RefreshSession(ctx, supportSession.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, supportSession.Permissions, refreshed.Permissions)
+
+	roles, err := svc.ListRoles(ctx)
+	require.NoError(t, err)
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	require.Subset(t, names, []string{"admin", "finance", "ops", "support", "user"})
+
+	_, err = svc.ChangeRole(ctx, uuid.New(), "admin", user.ID)
+	require.ErrorIs(t, err, ErrUserNotFound)
+	_, err = svc.ChangeRole(ctx, uuid.MustParse("11111111-1111-1111-1111-111111111111"), "admin", user.ID)
+	require.ErrorIs(t, err, ErrInvalidRole)
+
+	count, err := svc.RevokeAllSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+}
+
+func TestSessionRevocationSurvivesRedisOutage(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	rdb := newFlakyRedis(func() time.Time { return now })
+	svc := NewAuthService(repository.NewStore(db)).WithRedis(rdb)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, Registration{Username: "kit", Email: "kit@example.com", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	active := func(tokens *SessionTokens) bool {
+		ok, err := svc.SessionActive(ctx, tokens.SessionID, tokens.AccessJTI)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// Logout while Redis is down still ends the session: Postgres answers
+	// while Redis fails, and the stale cached jti expires soon after.
+	laptop, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+	require.True(t, active(laptop))
+	require.Equal(t, laptop.AccessJTI.String(), rdb.get(sessionCacheKey(laptop.SessionID)))
+	rdb.setDown(true)
+	require.NoError(t, svc.Logout(ctx, user.ID, laptop.SessionID))
+	require.False(t, active(laptop))
+	rdb.setDown(false)
+	now = now.Add(sessionCacheTTL)
+	require.False(t, active(laptop))
+
+	// So does a refresh token replayed while Redis is down.
+	phone, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+	rotated, err := svc.RefreshSession(ctx, phone.RefreshToken)
+	require.NoError(t, err)
+	require.True(t, active(rotated))
+	require.False(t, active(phone))
+	rdb.setDown(true)
+	_, err = svc.RefreshSession(ctx, phone.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+	require.False(t, active(rotated))
+	rdb.setDown(false)
+	now = now.Add(sessionCacheTTL)
+	require.False(t, active(rotated))
+	require.Equal(t, "", rdb.get(sessionCacheKey(rotated.SessionID)))
+
+	// With Redis up, a revocation replaces the cached jti at once.
+	tablet, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+	require.True(t, active(tablet))
+	require.NoError(t, svc.Logout(ctx, user.ID, tablet.SessionID))
+	require.Equal(t, sessionRevokedMarker, rdb.get(sessionCacheKey(tablet.SessionID)))
+	require.False(t, active(tablet))
+}
+
+func TestSessionRefreshRacingCheck(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	now := time.Now().UTC()
+	rdb := newFlakyRedis(func() time.Time { return now })
+	svc := NewAuthService(repository.NewStore(db)).WithRedis(rdb)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	user, err := svc.Register(ctx, Registration{Username: "kai", Email: "kai@example.com", Password: "Correct-Horse-42"})
+	require.NoError(t, err)
+	active := func(tokens *SessionTokens) bool {
+		ok, err := svc.SessionActive(ctx, tokens.SessionID, tokens.AccessJTI)
+		require.NoError(t, err)
+		return ok
+	}
+
+	old, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+
+	// A check reads the session, then a refresh commits before the check
+	// caches what it read.
+	var rotated *SessionTokens
+	rdb.beforeSetNX = func() {
+		rotated, err = svc.RefreshSession(ctx, old.RefreshToken)
+		require.NoError(t, err)
+	}
+	require.True(t, active(old))
+	require.NotNil(t, rotated)
+
+	require.Equal(t, rotated.AccessJTI.String(), rdb.get(sessionCacheKey(old.SessionID)))
+	require.False(t, active(old))
+	require.True(t, active(rotated))
+
+	// A revocation written before the refresh's cache write is kept.
+	phone, err := svc.StartSession(ctx, user, SessionClient{})
+	require.NoError(t, err)
+	rdb.put(sessionCacheKey(phone.SessionID), sessionRevokedMarker, time.Minute)
+	svc.cacheRotatedSession(ctx, &SessionTokens{SessionID: phone.SessionID, AccessJTI: uuid.New(), AccessExpiresAt: now.Add(time.Minute)})
+	require.Equal(t, sessionRevokedMarker, rdb.get(sessionCacheKey(phone.SessionID)))
+}
+
+// flakyRedis is an in-memory stand-in for the Redis commands sessions and
+// the limits cache use. While down every command fails. beforeSetNX, if set,
+// runs once at the start of the next SetNX.
+type flakyRedis struct {
+	redis.Cmdable
+	mu          sync.Mutex
+	now         func() time.Time
+	values      map[string]string
+	expires     map[string]time.Time
+	down        bool
+	beforeSetNX func()
+}
+
+func newFlakyRedis(now func() time.Time) *flakyRedis {
+	return &flakyRedis{now: now, values: map[string]string{}, expires: map[string]time.Time{}}
+}
+
+func (f *flakyRedis) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *flakyRedis) get(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if exp, ok := f.expires[key]; ok && !f.now().Before(exp) {
+		delete(f.values, key)
+	}
+	return f.values[key]
+}
+
+func (f *flakyRedis) Get(_ context.Context, key string) *redis.StringCmd {
+	if f.isDown() {
+		return redis.NewStringResult("", errors.New("redis down"))
+	}
+	v := f.get(key)
+	if v == "" {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (f *flakyRedis) Set(_ context.Context, key string, value any, ttl time.Duration) *redis.StatusCmd {
+	if f.isDown() {
+		return redis.NewStatusResult("", errors.New("redis down"))
+	}
+	f.put(key, fmt.Sprint(value), ttl)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *flakyRedis) SetNX(_ context.Context, key string, value any, ttl time.Duration) *redis.BoolCmd {
+	f.mu.Lock()
+	hook := f.beforeSetNX
+	f.beforeSetNX = nil
+	f.mu.Unlock()
+	if hook != nil {
+		hook()
+	}
+	if f.isDown() {
+		return redis.NewBoolResult(false, errors.New("redis down"))
+	}
+	if f.get(key) != "" {
+		return redis.NewBoolResult(false, nil)
+	}
+	f.put(key, fmt.Sprint(value), ttl)
+	return redis.NewBoolResult(true, nil)
+}
+
+func (f *flakyRedis) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	if f.isDown() {
+		return redis.NewIntResult(0, errors.New("redis down"))
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+// Eval runs the one script sessions use: cache ARGV[1] for ARGV[3]
+// milliseconds unless the key holds ARGV[2].
+func (f *flakyRedis) Eval(_ context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	if f.isDown() {
+		return redis.NewCmdResult(nil, errors.New("redis down"))
+	}
+	if script != cacheRotatedJTIScript {
+		return redis.NewCmdResult(nil, errors.New("unexpected script"))
+	}
+	if f.get(keys[0]) == fmt.Sprint(args[1]) {
+		return redis.NewCmdResult(int64(0), nil)
+	}
+	f.put(keys[0], fmt.Sprint(args[0]), time.Duration(args[2].(int64))*time.Millisecond)
+	return redis.NewCmdResult(int64(1), nil)
+}
+
+func (f *flakyRedis) Incr(_ context.Context, key string) *redis.IntCmd {
+	if f.isDown() {
+		return redis.NewIntResult(0, errors.New("redis down"))
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	fmt.Sscan(f.values[key], &n)
+	n++
+	f.values[key] = fmt.Sprint(n)
+	delete(f.expires, key)
+	return redis.NewIntResult(n, nil)
+}
+
+func (f *flakyRedis) isDown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.down
+}
+
+func (f *flakyRedis) put(key, value string, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.values[key] = value
+	f.expires[key] = f.now().Add(ttl)
+}
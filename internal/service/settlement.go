@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/ayo6706/payment-multicurrency/internal/settlement"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSettlementFileInvalid       = errors.New("invalid settlement file")
+	ErrSettlementFileDuplicate     = errors.New("settlement file already imported")
+	ErrReconciliationBreakNotFound = errors.New("reconciliation break not found")
+	ErrReconciliationBreakCleared  = errors.New("reconciliation break already cleared")
+)
+
+// Settlement line match outcomes.
+const (
+	settlementLineMatched = "MATCHED"
+	settlementLineBreak   = "BREAK"
+	settlementLineIgnored = "IGNORED"
+)
+
+// settlementSweepBatch bounds how many rows one sweep transaction handles.
+const settlementSweepBatch = 500
+
+// ImportSettlementFile parses a settlement file, matches every debit line to
+// the payout sent under its reference and records a break for each line that
+// does not reconcile. Credit lines are stored but not matched. Importing the
+// same file twice returns ErrSettlementFileDuplicate.
+func (s *ReconciliationService) ImportSettlementFile(ctx context.Context, fileName, format string, data []byte, actorID *uuid.UUID) (*models.SettlementFile, error) {
+	stmt, err := settlement.Parse(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSettlementFileInvalid, err)
+	}
+	digest := sha256.Sum256(data)
+	checksum := hex.EncodeToString(digest[:])
+
+	var (
+		file   repository.SettlementFile
+		raised []string
+	)
+	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		raised = raised[:0]
+		if _, err := qtx.GetSettlementFileBySHA256(ctx, checksum); err == nil {
+			return ErrSettlementFileDuplicate
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("check settlement file: %w", err)
+		}
+
+		var importedBy pgtype.UUID
+		if actorID != nil {
+			importedBy = repository.ToPgUUID(*actorID)
+		}
+		created, err := qtx.CreateSettlementFile(ctx, repository.CreateSettlementFileParams{
+			ID:          repository.ToPgUUID(uuid.New()),
+			FileName:    fileName,
+			Format:      stmt.Format,
+			Sha256:      checksum,
+			PeriodStart: timestampParam(stmt.PeriodStart),
+			PeriodEnd:   timestampParam(stmt.PeriodEnd),
+			ImportedBy:  importedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("create settlement file: %w", err)
+		}
+
+		var matched, breaks int32
+		for _, line := range stmt.Lines {
+			breakType, err := s.importSettlementLine(ctx, qtx, created, line)
+			if err != nil {
+				return err
+			}
+			switch {
+			case breakType != "":
+				breaks++
+				raised = append(raised, breakType)
+			case line.Direction == settlement.DirectionDebit:
+				matched++
+			}
+		}
+
+		file, err = qtx.UpdateSettlementFileCounts(ctx, repository.UpdateSettlementFileCountsParams{
+			ID:           created.ID,
+			LineCount:    int32(len(stmt.Lines)),
+			MatchedCount: matched,
+			BreakCount:   breaks,
+		})
+		if err != nil {
+			return fmt.Errorf("update settlement file counts: %w", err)
+		}
+
+		metadata, err := json.Marshal(map[string]any{
+			"file_name": file.FileName,
+			"format":    file.Format,
+			"sha256":    file.Sha256,
+			"lines":     file.LineCount,
+			"matched":   file.MatchedCount,
+			"breaks":    file.BreakCount,
+		})
+		if err != nil {
+			return fmt.Errorf("marshal settlement file audit metadata: %w", err)
+		}
+		return s.audit.Write(ctx, qtx, "settlement_file", repository.FromPgUUID(file.ID), actorID, "imported", "", "", metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, breakType := range raised {
+		observability.IncrementSettlementBreak(breakType)
+	}
+	zap.L().Info("settlement file imported",
+		zap.String("file_id", repository.FromPgUUID(file.ID).String()),
+		zap.String("format", file.Format),
+		zap.Int32("lines", file.LineCount),
+		zap.Int32("matched", file.MatchedCount),
+		zap.Int32("breaks", file.BreakCount),
+	)
+	out := toSettlementFileModel(file)
+	return &out, nil
+}
+
+// importSettlementLine stores one statement line and returns the type of the
+// break it raised, if any.
+func (s *ReconciliationService) importSettlementLine(ctx context.Context, qtx *repository.Queries, file repository.SettlementFile, line settlement.Line) (string, error) {
+	params := repository.CreateSettlementLineParams{
+		ID:           repository.ToPgUUID(uuid.New()),
+		FileID:       file.ID,
+		LineNo:       int32(line.LineNo),
+		GatewayRef:   line.Ref,
+		AmountMicros: line.AmountMicros,
+		Currency:     line.Currency,
+		Direction:    line.Direction,
+		BookedAt:     timestampParam(line.BookedAt),
+		Description:  textParam(line.Description),
+		MatchStatus:  settlementLineIgnored,
+	}
+	if line.Direction != settlement.DirectionDebit {
+		if _, err := qtx.CreateSettlementLine(ctx, params); err != nil {
+			return "", fmt.Errorf("create settlement line %d: %w", line.LineNo, err)
+		}
+		return "", nil
+	}
+
+	ref := line.Ref
+	payout, err := qtx.GetPayoutByGatewayRef(ctx, &ref)
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("get payout by gateway ref: %w", err)
+	}
+
+	var (
+		breakType string
+		details   string
+	)
+	switch {
+	case !found:
+		breakType = domain.BreakTypeUnknownDebit
+		details = "no payout was sent under this reference"
+	default:
+		params.PayoutID = payout.ID
+		prior, err := qtx.CountSettlementDebitsForPayout(ctx, payout.ID)
+		if err != nil {
+			return "", fmt.Errorf("count settlement debits: %w", err)
+		}
+		switch {
+		case prior > 0:
+			breakType = domain.BreakTypeUnknownDebit
+			details = "payout was already debited by an earlier settlement line"
+		case payout.Currency != line.Currency || payout.AmountMicros != line.AmountMicros:
+			breakType = domain.BreakTypeAmountMismatch
+			details = fmt.Sprintf("payout is %d %s micros, bank debited %d %s micros", payout.AmountMicros, payout.Currency, line.AmountMicros, line.Currency)
+		case payout.Status == domain.PayoutStatusFailed || payout.Status == domain.PayoutStatusRejected:
+			breakType = domain.BreakTypePaidButFailed
+			details = fmt.Sprintf("bank debited a payout that is %s", payout.Status)
+		}
+	}
+
+	params.MatchStatus = settlementLineMatched
+	if breakType != "" {
+		params.MatchStatus = settlementLineBreak
+	}
+	stored, err := qtx.CreateSettlementLine(ctx, params)
+	if err != nil {
+		return "", fmt.Errorf("create settlement line %d: %w", line.LineNo, err)
+	}
+
+	if breakType == "" {
+		// A late file settles a payout that an earlier run reported missing.
+		if _, err := qtx.ClearMissingSettlementBreak(ctx, repository.ClearMissingSettlementBreakParams{
+			PayoutID:       payout.ID,
+			ResolutionNote: textParam(fmt.Sprintf("settled by %s line %d", file.FileName, line.LineNo)),
+		}); err != nil {
+			return "", fmt.Errorf("clear missing settlement break: %w", err)
+		}
+		return "", nil
+	}
+
+	brk := repository.CreateReconciliationBreakParams{
+		ID:           repository.ToPgUUID(uuid.New()),
+		BreakType:    breakType,
+		PayoutID:     stored.PayoutID,
+		LineID:       stored.ID,
+		FileID:       file.ID,
+		GatewayRef:   textParam(line.Ref),
+		Currency:     textParam(line.Currency),
+		ActualMicros: &stored.AmountMicros,
+		Details:      details,
+	}
+	if found {
+		brk.ExpectedMicros = &payout.AmountMicros
+	}
+	if _, err := qtx.CreateReconciliationBreak(ctx, brk); err != nil {
+		return "", fmt.Errorf("create reconciliation break: %w", err)
+	}
+	return breakType, nil
+}
+
+// checkSettlements raises breaks that only show up over time: a settled line
+// whose payout has since failed, and a completed payout that no settlement
+// file has debited within the missing-after window. Payouts completed before
+// the first imported statement period are never reported missing.
+func (s *ReconciliationService) checkSettlements(ctx context.Context, now time.Time) error {
+	queries := s.store.Queries()
+
+	for {
+		lines, err := queries.ListSettledLinesForFailedPayouts(ctx, settlementSweepBatch)
+		if err != nil {
+			return fmt.Errorf("list settled lines for failed payouts: %w", err)
+		}
+		err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+			for _, line := range lines {
+				amount, expected := line.AmountMicros, line.PayoutAmountMicros
+				if err := s.raiseBreak(ctx, qtx, repository.CreateReconciliationBreakParams{
+					ID:             repository.ToPgUUID(uuid.New()),
+					BreakType:      domain.BreakTypePaidButFailed,
+					PayoutID:       line.PayoutID,
+					LineID:         line.ID,
+					FileID:         line.FileID,
+					GatewayRef:     textParam(line.GatewayRef),
+					Currency:       textParam(line.Currency),
+					ExpectedMicros: &expected,
+					ActualMicros:   &amount,
+					Details:        fmt.Sprintf("bank debited a payout that is %s", line.PayoutStatus),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(lines) < settlementSweepBatch {
+			break
+		}
+	}
+
+	coverage, err := queries.GetSettlementCoverageStart(ctx)
+	if err != nil {
+		return fmt.Errorf("get settlement coverage start: %w", err)
+	}
+	if !coverage.Valid {
+		return nil
+	}
+	for {
+		payouts, err := queries.ListCompletedPayoutsMissingSettlement(ctx, repository.ListCompletedPayoutsMissingSettlementParams{
+			CompletedAfter:  coverage,
+			CompletedBefore: pgtype.Timestamptz{Time: now.Add(-s.missingAfter), Valid: true},
+			RowLimit:        settlementSweepBatch,
+		})
+		if err != nil {
+			return fmt.Errorf("list payouts missing settlement: %w", err)
+		}
+		err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+			for _, payout := range payouts {
+				expected := payout.AmountMicros
+				if err := s.raiseBreak(ctx, qtx, repository.CreateReconciliationBreakParams{
+					ID:             repository.ToPgUUID(uuid.New()),
+					BreakType:      domain.BreakTypeCompletedButMissing,
+					PayoutID:       payout.ID,
+					GatewayRef:     payout.GatewayRef,
+					Currency:       textParam(payout.Currency),
+					ExpectedMicros: &expected,
+					Details:        fmt.Sprintf("payout completed at %s but no settlement file debited it", payout.UpdatedAt.Time.UTC().Format(time.RFC3339)),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(payouts) < settlementSweepBatch {
+			return nil
+		}
+	}
+}
+
+func (s *ReconciliationService) raiseBreak(ctx context.Context, qtx *repository.Queries, params repository.CreateReconciliationBreakParams) error {
+	rows, err := qtx.CreateReconciliationBreak(ctx, params)
+	if err != nil {
+		return fmt.Errorf("create reconciliation break: %w", err)
+	}
+	if rows == 0 {
+		return nil
+	}
+	observability.IncrementSettlementBreak(params.BreakType)
+	zap.L().Warn("settlement reconciliation break",
+		zap.String("type", params.BreakType),
+		zap.String("payout_id", repository.FromPgUUID(params.PayoutID).String()),
+		zap.String("details", params.Details),
+	)
+	return nil
+}
+
+// ListSettlementFiles returns imported settlement files, newest first.
+func (s *ReconciliationService) ListSettlementFiles(ctx context.Context, limit, offset int32) ([]models.SettlementFile, error) {
+	rows, err := s.store.Queries().ListSettlementFiles(ctx, repository.ListSettlementFilesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list settlement files: %w", err)
+	}
+	out := make([]models.SettlementFile, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toSettlementFileModel(row))
+	}
+	return out, nil
+}
+
+// ListReconciliationBreaks returns breaks, newest first, optionally filtered
+// by status and type.
+func (s *ReconciliationService) ListReconciliationBreaks(ctx context.Context, status, breakType string, limit, offset int32) ([]models.ReconciliationBreak, error) {
+	rows, err := s.store.Queries().ListReconciliationBreaks(ctx, repository.ListReconciliationBreaksParams{
+		Status:    textParam(strings.ToUpper(status)),
+		BreakType: textParam(strings.ToUpper(breakType)),
+		RowLimit:  limit,
+		RowOffset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliation breaks: %w", err)
+	}
+	out := make([]models.ReconciliationBreak, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toReconciliationBreakModel(row))
+	}
+	return out, nil
+}
+
+// ClearReconciliationBreak marks an open break as reviewed. The note records
+// how it was resolved.
+func (s *ReconciliationService) ClearReconciliationBreak(ctx context.Context, id uuid.UUID, actorID uuid.UUID, note string) (*models.ReconciliationBreak, error) {
+	var row repository.ReconciliationBreak
+	err := s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
+		current, err := qtx.GetReconciliationBreak(ctx, repository.ToPgUUID(id))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrReconciliationBreakNotFound
+			}
+			return fmt.Errorf("get reconciliation break: %w", err)
+		}
+		row, err = qtx.ClearReconciliationBreak(ctx, repository.ClearReconciliationBreakParams{
+			ID:             current.ID,
+			ClearedBy:      repository.ToPgUUID(actorID),
+			ResolutionNote: textParam(note),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrReconciliationBreakCleared
+			}
+			return fmt.Errorf("clear reconciliation break: %w", err)
+		}
+		metadata, err := marshalReasonMetadata(note)
+		if err != nil {
+			return err
+		}
+		return s.audit.Write(ctx, qtx, "reconciliation_break", id, &actorID, "cleared", current.Status, row.Status, metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := toReconciliationBreakModel(row)
+	return &out, nil
+}
+
+func timestampParam(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+func optionalUUID(id pgtype.UUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	out := repository.FromPgUUID(id)
+	return &out
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	out := t.Time
+	return &out
+}
+
+func toSettlementFileModel(row repository.SettlementFile) models.SettlementFile {
+	return models.SettlementFile{
+		ID:           repository.FromPgUUID(row.ID),
+		FileName:     row.FileName,
+		Format:       row.Format,
+		SHA256:       row.Sha256,
+		PeriodStart:  optionalTime(row.PeriodStart),
+		PeriodEnd:    optionalTime(row.PeriodEnd),
+		LineCount:    row.LineCount,
+		MatchedCount: row.MatchedCount,
+		BreakCount:   row.BreakCount,
+		ImportedBy:   optionalUUID(row.ImportedBy),
+		ImportedAt:   row.ImportedAt.Time,
+	}
+}
+
+func toReconciliationBreakModel(row repository.ReconciliationBreak) models.ReconciliationBreak {
+	return models.ReconciliationBreak{
+		ID:             repository.FromPgUUID(row.ID),
+		Type:           row.BreakType,
+		Status:         row.Status,
+		PayoutID:       optionalUUID(row.PayoutID),
+		LineID:         optionalUUID(row.LineID),
+		FileID:         optionalUUID(row.FileID),
+		GatewayRef:     derefString(row.GatewayRef),
+		Currency:       derefString(row.Currency),
+		ExpectedMicros: row.ExpectedMicros,
+		ActualMicros:   row.ActualMicros,
+		Details:        row.Details,
+		CreatedAt:      row.CreatedAt.Time,
+		ClearedBy:      optionalUUID(row.ClearedBy),
+		ClearedAt:      optionalTime(row.ClearedAt),
+		ResolutionNote: derefString(row.ResolutionNote),
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettlementFileReconciliation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repoSvc := repository.NewRepository(db)
+	store := repository.NewStore(db)
+	gateway := &stubGateway{}
+	payoutSvc := NewPayoutService(store, gateway)
+	reconcileSvc := NewReconciliationService(store).WithMissingAfter(72 * time.Hour)
+
+	admin := &models.User{ID: uuid.New(), Username: "settle-admin", Email: "settle-admin@example.com"}
+	require.NoError(t, repoSvc.CreateUser(ctx, admin))
+	account := &models.Account{ID: uuid.New(), UserID: admin.ID, Currency: "USD", Balance: 10_000_000}
+	require.NoError(t, repoSvc.CreateAccount(ctx, account))
+
+	payoutIDs := map[string]uuid.UUID{}
+	for _, ref := range []string{"GW-OK", "GW-SHORT", "GW-FAILED", "GW-MISSING"} {
+		resp, err := payoutSvc.RequestPayout(ctx, RequestPayoutRequest{
+			AccountID:    account.ID,
+			AmountMicros: 1_000_000,
+			Currency:     "USD",
+			Destination:  PayoutDestinationInput{RoutingNumber: "021000021", AccountNumber: "12345678", Name: "Vendor"},
+			ReferenceID:  "settle-" + ref,
+		})
+		require.NoError(t, err)
+		gateway.ref = ref
+		require.NoError(t, payoutSvc.ProcessPayouts(ctx, 1))
+		payoutIDs[ref] = resp.PayoutID
+	}
+	_, err := db.Exec(ctx, "UPDATE payouts SET updated_at = NOW() - INTERVAL '4 days' WHERE id = $1", repository.ToPgUUID(payoutIDs["GW-MISSING"]))
+	require.NoError(t, err)
+
+	period := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+	csv := "gateway_ref,amount,currency,direction,booked_at\n" +
+		"GW-OK,1.00,USD,debit," + period + "\n" +
+		"GW-SHORT,0.90,USD,debit," + period + "\n" +
+		"GW-FAILED,1.00,USD,debit," + period + "\n" +
+		"GW-OK,1.00,USD,debit," + period + "\n" +
+		"NOT-OURS,5.00,USD,debit," + period + "\n" +
+		"REFUND-1,2.00,USD,credit," + period + "\n"
+	file, err := reconcileSvc.ImportSettlementFile(ctx, "bank.csv", "", []byte(csv), &admin.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(6), file.LineCount)
+	require.Equal(t, int32(2), file.MatchedCount)
+	require.Equal(t, int32(3), file.BreakCount)
+
+	_, err = reconcileSvc.ImportSettlementFile(ctx, "bank-copy.csv", "CSV", []byte(csv), &admin.ID)
+	require.ErrorIs(t, err, ErrSettlementFileDuplicate)
+	_, err = reconcileSvc.ImportSettlementFile(ctx, "bad.csv", "CSV", []byte("ref,amount\n"), &admin.ID)
+	require.ErrorIs(t, err, ErrSettlementFileInvalid)
+
+	// The bank debited GW-FAILED, but the payout failed afterwards.
+	_, err = db.Exec(ctx, "UPDATE payouts SET status = 'FAILED' WHERE id = $1", repository.ToPgUUID(payoutIDs["GW-FAILED"]))
+	require.NoError(t, err)
+	require.NoError(t, reconcileSvc.Run(ctx))
+	require.NoError(t, reconcileSvc.Run(ctx))
+
+	open, err := reconcileSvc.ListReconciliationBreaks(ctx, domain.BreakStatusOpen, "", 50, 0)
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, brk := range open {
+		counts[brk.Type]++
+	}
+	require.Equal(t, map[string]int{
+		domain.BreakTypeUnknownDebit:        2,
+		domain.BreakTypeAmountMismatch:      1,
+		domain.BreakTypePaidButFailed:       1,
+		domain.BreakTypeCompletedButMissing: 1,
+	}, counts)
+
+	// A later file that debits the missing payout clears its break.
+	late := "gateway_ref,amount,currency\nGW-MISSING,1.00,USD\n"
+	_, err = reconcileSvc.ImportSettlementFile(ctx, "bank-late.csv", "", []byte(late), &admin.ID)
+	require.NoError(t, err)
+	missing, err := reconcileSvc.ListReconciliationBreaks(ctx, "", domain.BreakTypeCompletedButMissing, 50, 0)
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+	require.Equal(t, domain.BreakStatusCleared, missing[0].Status)
+
+	unknown, err := reconcileSvc.ListReconciliationBreaks(ctx, domain.BreakStatusOpen, domain.BreakTypeUnknownDebit, 50, 0)
+	require.NoError(t, err)
+	cleared, err := reconcileSvc.ClearReconciliationBreak(ctx, unknown[0].ID, admin.ID, "bank fee")
+	require.NoError(t, err)
+	require.Equal(t, domain.BreakStatusCleared, cleared.Status)
+	require.Equal(t, "bank fee", cleared.ResolutionNote)
+	_, err = reconcileSvc.ClearReconciliationBreak(ctx, unknown[0].ID, admin.ID, "again")
+	require.ErrorIs(t, err, ErrReconciliationBreakCleared)
+	_, err = reconcileSvc.ClearReconciliationBreak(ctx, uuid.New(), admin.ID, "missing")
+	require.ErrorIs(t, err, ErrReconciliationBreakNotFound)
+}
@@ -27,7 +27,7 @@ func setupTestDB(t *testing.T) *pgxpool.Pool {
 	ensurePayoutsTable(t, db)
 	ensureAuditLogTable(t, db)
 
-	for _, table := range []string{"audit_log", "entries", "transactions", "payouts", "accounts", "users"} {
+	for _, table := range []string{"kyc_provider_events", "api_keys", "refresh_tokens", "user_sessions", "password_reset_tokens", "aml_alerts", "aml_cases", "aml_observations", "payout_screenings", "sanctions_entries", "sanctions_list_loads", "limit_usage", "user_limit_overrides", "transaction_limits", "audit_anchors", "audit_chain_head", "entries_archive_currency_totals", "entries_archives", "ledger_month_seals", "ledger_dirty_days", "ledger_day_totals", "ledger_checkpoint", "reconciliation_runs", "reconciliation_findings", "reconciliation_breaks", "settlement_lines", "settlement_files", "outbox_events", "audit_log", "entries", "transactions", "payouts", "beneficiaries", "accounts", "users"} {
 		stmt := fmt.Sprintf("TRUNCATE TABLE %s CASCADE", table)
 		if _, err := db.Exec(context.Background(), stmt); err != nil {
 			if strings.Contains(err.Error(), "does not exist") {
@@ -47,12 +47,18 @@ func seedSystemAccounts(t *testing.T, db *pgxpool.Pool) {
 	columns := "id, user_id, currency, balance, created_at"
 	values := "('22222222-2222-2222-2222-222222222222', '11111111-1111-1111-1111-111111111111', 'USD', 0, NOW())," +
 		"('33333333-3333-3333-3333-333333333333', '11111111-1111-1111-1111-111111111111', 'EUR', 0, NOW())," +
-		"('44444444-4444-4444-4444-444444444444', '11111111-1111-1111-1111-111111111111', 'GBP', 0, NOW())"
+		"('44444444-4444-4444-4444-444444444444', '11111111-1111-1111-1111-111111111111', 'GBP', 0, NOW())," +
+		"('55555555-5555-5555-5555-555555555555', '11111111-1111-1111-1111-111111111111', 'USD', 0, NOW())," +
+		"('66666666-6666-6666-6666-666666666666', '11111111-1111-1111-1111-111111111111', 'EUR', 0, NOW())," +
+		"('77777777-7777-7777-7777-777777777777', '11111111-1111-1111-1111-111111111111', 'GBP', 0, NOW())"
 	if hasLockedMicrosColumn(db) {
 		columns = "id, user_id, currency, balance, locked_micros, created_at"
 		values = "('22222222-2222-2222-2222-222222222222', '11111111-1111-1111-1111-111111111111', 'USD', 0, 0, NOW())," +
 			"('33333333-3333-3333-3333-333333333333', '11111111-1111-1111-1111-111111111111', 'EUR', 0, 0, NOW())," +
-			"('44444444-4444-4444-4444-444444444444', '11111111-1111-1111-1111-111111111111', 'GBP', 0, 0, NOW())"
+			"('44444444-4444-4444-4444-444444444444', '11111111-1111-1111-1111-111111111111', 'GBP', 0, 0, NOW())," +
+			"('55555555-5555-5555-5555-555555555555', '11111111-1111-1111-1111-111111111111', 'USD', 0, 0, NOW())," +
+			"('66666666-6666-6666-6666-666666666666', '11111111-1111-1111-1111-111111111111', 'EUR', 0, 0, NOW())," +
+			"('77777777-7777-7777-7777-777777777777', '11111111-1111-1111-1111-111111111111', 'GBP', 0, 0, NOW())"
 	}
 
 	sql := fmt.Sprintf(`
@@ -92,7 +98,12 @@ func ensurePayoutsTable(t *testing.T, db *pgxpool.Pool) {
 			status TEXT NOT NULL DEFAULT 'PENDING',
 			gateway_ref TEXT,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			requested_by UUID,
+			reviewed_by UUID,
+			reviewed_at TIMESTAMPTZ,
+			beneficiary_id UUID
 		);
 	`
 	if _, err := db.Exec(context.Background(), sql); err != nil {
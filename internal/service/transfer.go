@@ -9,6 +9,7 @@ import (
 
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
 	"github.com/ayo6706/payment-multicurrency/internal/models"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -35,6 +36,8 @@ type TransferService struct {
 	store   QueryStore
 	fxRates ExchangeRateService
 	audit   *AuditService
+	limits  *LimitService
+	kyc     *KYCService
 }
 
 // NewTransferService creates a new TransferService instance.
@@ -46,6 +49,20 @@ func NewTransferService(store QueryStore, fxRates ExchangeRateService) *Transfer
 	}
 }
 
+// WithLimits checks transfers and exchanges against transaction limits
+// before any account is locked.
+func (s *TransferService) WithLimits(limits *LimitService) *TransferService {
+	s.limits = limits
+	return s
+}
+
+// WithKYC holds credits to the maximum balance of the receiving account
+// owner's KYC tier.
+func (s *TransferService) WithKYC(kyc *KYCService) *TransferService {
+	s.kyc = kyc
+	return s
+}
+
 // Transfer processes a same-currency transfer between two accounts.
 // It handles idempotency, pessimistic locking to prevent deadlocks,
 // balance validation, transaction creation, and ledger entry creation.
@@ -70,6 +87,11 @@ func (s *TransferService) Transfer(ctx context.Context, fromAccountID, toAccount
 		return nil, fmt.Errorf("failed to check idempotency: %w", err)
 	}
 
+	reservation, err := s.limits.ReserveForAccount(ctx, fromAccountID, domain.TxTypeTransfer, amount)
+	if err != nil {
+		return nil, err
+	}
+
 	transactionID := uuid.New()
 	txCurrency := ""
 	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
@@ -79,20 +101,26 @@ func (s *TransferService) Transfer(ctx context.Context, fromAccountID, toAccount
 			account1ID, account2ID = toAccountID, fromAccountID
 		}
 
-		_, err = qtx.LockAccount(ctx, repository.ToPgUUID(account1ID))
-		if err != nil {
-			return fmt.Errorf("failed to lock account %s: %w", account1ID, err)
+		statuses := make(map[uuid.UUID]string, 2)
+		for _, id := range []uuid.UUID{account1ID, account2ID} {
+			status, err := qtx.LockAccount(ctx, repository.ToPgUUID(id))
+			if err != nil {
+				return fmt.Errorf("failed to lock account %s: %w", id, err)
+			}
+			statuses[id] = status
 		}
-		_, err = qtx.LockAccount(ctx, repository.ToPgUUID(account2ID))
-		if err != nil {
-			return fmt.Errorf("failed to lock account %s: %w", account2ID, err)
+		if err := checkAccountDebit(fromAccountID, statuses[fromAccountID]); err != nil {
+			return err
+		}
+		if err := checkAccountCredit(toAccountID, statuses[toAccountID]); err != nil {
+			return err
 		}
 
 		fromAccRow, err := qtx.GetAccountBalanceAndCurrency(ctx, repository.ToPgUUID(fromAccountID))
 		if err != nil {
 			return fmt.Errorf("failed to fetch sender account: %w", err)
 		}
-		fromBalance, fromCurrency := fromAccRow.Balance, fromAccRow.Currency
+		fromCurrency := fromAccRow.Currency
 		txCurrency = fromCurrency
 
 		toCurrency, err := qtx.GetAccountCurrency(ctx, repository.ToPgUUID(toAccountID))
@@ -103,9 +131,12 @@ func (s *TransferService) Transfer(ctx context.Context, fromAccountID, toAccount
 		if fromCurrency != toCurrency {
 			return fmt.Errorf("%w: sender is %s, receiver is %s", ErrCurrencyMismatch, fromCurrency, toCurrency)
 		}
+		if err := s.kyc.checkCredit(ctx, qtx, toAccountID, amount); err != nil {
+			return err
+		}
 
-		if fromBalance < amount {
-			return models.ErrInsufficientFunds
+		if err := checkAvailableFunds(fromAccRow.Balance, fromAccRow.LockedMicros, fromAccRow.OverdraftLimitMicros, amount); err != nil {
+			return err
 		}
 
 		_, err = qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
@@ -172,9 +203,17 @@ func (s *TransferService) Transfer(ctx context.Context, fromAccountID, toAccount
 		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, nil, "completed", nil); err != nil {
 			return fmt.Errorf("failed to complete transaction: %w", err)
 		}
-		return nil
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventTransferCompleted, map[string]any{
+			"transaction_id":  transactionID,
+			"reference_id":    referenceID,
+			"from_account_id": fromAccountID,
+			"to_account_id":   toAccountID,
+			"amount_micros":   amount,
+			"currency":        txCurrency,
+		})
 	})
 	if err != nil {
+		reservation.Release(ctx)
 		if isUniqueViolation(err) {
 			existing, lookupErr := queries.CheckTransactionIdempotency(ctx, referenceID)
 			if lookupErr == nil {
@@ -248,27 +287,41 @@ func (s *TransferService) TransferExchange(ctx context.Context, cmd TransferExch
 		return nil, fmt.Errorf("failed to identify liquidity target account: %w", err)
 	}
 
+	// 3. Count the source amount against the sender's limits
+	reservation, err := s.limits.ReserveForAccount(ctx, cmd.FromAccountID, domain.TxTypeExchange, cmd.Amount)
+	if err != nil {
+		return nil, err
+	}
+
 	transactionID := uuid.New()
 	fxRateVar := rate
 
 	err = s.store.RunInTx(ctx, func(qtx *repository.Queries) error {
 		accountIDs := dedupeUUIDs(cmd.FromAccountID, liqSourceID, liqTargetID, cmd.ToAccountID)
 		sortUUIDs(accountIDs)
+		statuses := make(map[uuid.UUID]string, len(accountIDs))
 		for _, id := range accountIDs {
-			_, err := qtx.LockAccount(ctx, repository.ToPgUUID(id))
+			status, err := qtx.LockAccount(ctx, repository.ToPgUUID(id))
 			if err != nil {
 				if errors.Is(err, pgx.ErrNoRows) {
 					return fmt.Errorf("account %s not found", id)
 				}
 				return fmt.Errorf("failed to lock account %s: %w", id, err)
 			}
+			statuses[id] = status
+		}
+		if err := checkAccountDebit(cmd.FromAccountID, statuses[cmd.FromAccountID]); err != nil {
+			return err
+		}
+		if err := checkAccountCredit(cmd.ToAccountID, statuses[cmd.ToAccountID]); err != nil {
+			return err
 		}
 
 		fromAccRow, err := qtx.GetAccountBalanceAndCurrency(ctx, repository.ToPgUUID(cmd.FromAccountID))
 		if err != nil {
 			return fmt.Errorf("failed to fetch sender account: %w", err)
 		}
-		fromBalance, fromAccountCurrency := fromAccRow.Balance, fromAccRow.Currency
+		fromAccountCurrency := fromAccRow.Currency
 
 		toAccountCurrency, err := qtx.GetAccountCurrency(ctx, repository.ToPgUUID(cmd.ToAccountID))
 		if err != nil {
@@ -283,8 +336,8 @@ func (s *TransferService) TransferExchange(ctx context.Context, cmd TransferExch
 			return fmt.Errorf("receiver account currency (%s) does not match requested to_currency (%s)", toAccountCurrency, cmd.ToCurrency)
 		}
 
-		if fromBalance < cmd.Amount {
-			return models.ErrInsufficientFunds
+		if err := checkAvailableFunds(fromAccRow.Balance, fromAccRow.LockedMicros, fromAccRow.OverdraftLimitMicros, cmd.Amount); err != nil {
+			return err
 		}
 
 		_, err = qtx.CreateTransaction(ctx, repository.CreateTransactionParams{
@@ -310,6 +363,9 @@ func (s *TransferService) TransferExchange(ctx context.Context, cmd TransferExch
 
 		amountSource := sourceMoney.Amount
 		amountTarget := targetMoney.Amount
+		if err := s.kyc.checkCredit(ctx, qtx, cmd.ToAccountID, amountTarget); err != nil {
+			return err
+		}
 
 		var numericFxRate pgtype.Numeric
 		err = numericFxRate.Scan(rate.String())
@@ -425,9 +481,20 @@ func (s *TransferService) TransferExchange(ctx context.Context, cmd TransferExch
 		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, nil, "completed", nil); err != nil {
 			return fmt.Errorf("failed to complete exchange transaction: %w", err)
 		}
-		return nil
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventExchangeCompleted, map[string]any{
+			"transaction_id":       transactionID,
+			"reference_id":         cmd.ReferenceID,
+			"from_account_id":      cmd.FromAccountID,
+			"to_account_id":        cmd.ToAccountID,
+			"amount_micros":        amountSource,
+			"currency":             cmd.FromCurrency,
+			"target_amount_micros": amountTarget,
+			"target_currency":      cmd.ToCurrency,
+			"fx_rate":              rate.String(),
+		})
 	})
 	if err != nil {
+		reservation.Release(ctx)
 		if isUniqueViolation(err) {
 			existing, lookupErr := queries.CheckTransactionIdempotency(ctx, cmd.ReferenceID)
 			if lookupErr == nil {
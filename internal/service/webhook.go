@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
 	"github.com/ayo6706/payment-multicurrency/internal/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -29,6 +30,7 @@ type WebhookService struct {
 	hmacKey []byte
 	skipSig bool
 	audit   *AuditService
+	kyc     *KYCService
 }
 
 // NewWebhookService creates a new WebhookService instance.
@@ -41,6 +43,12 @@ func NewWebhookService(store QueryStore, hmacKey string, skipSignature bool) *We
 	}
 }
 
+// WithKYC holds deposits to the maximum balance of the account owner's KYC tier.
+func (s *WebhookService) WithKYC(kyc *KYCService) *WebhookService {
+	s.kyc = kyc
+	return s
+}
+
 // DepositWebhookPayload represents the incoming deposit webhook payload.
 type DepositWebhookPayload struct {
 	AccountID    string `json:"account_id"`
@@ -131,10 +139,16 @@ func (s *WebhookService) HandleDepositWebhook(ctx context.Context, payload []byt
 		if err != nil {
 			return fmt.Errorf("failed to lock account: %w", err)
 		}
+		if err := checkAccountCredit(accountID, accountRow.Status); err != nil {
+			return err
+		}
 
 		if accountRow.Currency != deposit.Currency {
 			return fmt.Errorf("%w: currency mismatch: account is %s, deposit is %s", ErrInvalidWebhookPayload, accountRow.Currency, deposit.Currency)
 		}
+		if err := s.kyc.checkCredit(ctx, qtx, accountID, deposit.AmountMicros); err != nil {
+			return err
+		}
 
 		if retryExisting {
 			if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusProcessing, nil, "retry_processing_started", metadataJson); err != nil {
@@ -207,7 +221,13 @@ func (s *WebhookService) HandleDepositWebhook(ctx context.Context, payload []byt
 		if err := transitionTransactionState(ctx, qtx, s.audit, transactionID, domain.TxStatusCompleted, nil, "completed", nil); err != nil {
 			return fmt.Errorf("failed to complete webhook transaction: %w", err)
 		}
-		return nil
+		return writeOutboxEvent(ctx, qtx, outbox.AggregateTransaction, transactionID, outbox.EventDepositCompleted, map[string]any{
+			"transaction_id": transactionID,
+			"reference_id":   deposit.Reference,
+			"account_id":     accountID,
+			"amount_micros":  deposit.AmountMicros,
+			"currency":       deposit.Currency,
+		})
 	})
 	if err != nil {
 		if !retryExisting && isUniqueViolation(err) {
@@ -251,9 +271,13 @@ func (s *WebhookService) verifyHMAC(payload []byte, signature string) bool {
 	if len(s.hmacKey) == 0 {
 		return false
 	}
+	return validSignature(s.hmacKey, payload, signature)
+}
 
-	// Calculate expected HMAC
-	h := hmac.New(sha256.New, s.hmacKey)
+// validSignature reports whether signature is "sha256=" followed by the hex
+// HMAC-SHA256 of payload under key.
+func validSignature(key, payload []byte, signature string) bool {
+	h := hmac.New(sha256.New, key)
 	h.Write(payload)
 	expectedSig := "sha256=" + hex.EncodeToString(h.Sum(nil))
 
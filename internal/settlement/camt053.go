@@ -0,0 +1,164 @@
+package settlement
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// camtDocument is the subset of a camt.053 bank-to-customer statement needed
+// for reconciliation. Tags carry no namespace so camt.053.001.02 through
+// later versions all parse.
+type camtDocument struct {
+	XMLName    xml.Name `xml:"Document"`
+	Statements []struct {
+		FrToDt struct {
+			FrDtTm string `xml:"FrDtTm"`
+			ToDtTm string `xml:"ToDtTm"`
+		} `xml:"FrToDt"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	NtryRef   string     `xml:"NtryRef"`
+	Amt       camtAmount `xml:"Amt"`
+	CdtDbtInd string     `xml:"CdtDbtInd"`
+	// Sts is a plain code up to camt.053.001.08 and wraps it in Cd after.
+	Sts struct {
+		Code string `xml:",chardata"`
+		Cd   string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookgDt struct {
+		Dt   string `xml:"Dt"`
+		DtTm string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	AddtlInf    string `xml:"AddtlNtryInf"`
+	TxDtls      []struct {
+		Refs struct {
+			EndToEndId  string `xml:"EndToEndId"`
+			AcctSvcrRef string `xml:"AcctSvcrRef"`
+		} `xml:"Refs"`
+		Amt    *camtAmount `xml:"Amt"`
+		TxAmt  *camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+		InstdA *camtAmount `xml:"AmtDtls>InstdAmt>Amt"`
+		Ustrd  []string    `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+type camtAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// parseCAMT053 flattens booked entries into lines. A batch-booked entry with
+// transaction details yields one line per transaction, so each transfer of a
+// pain.001 file can be matched by its end-to-end ID. Pending entries are skipped.
+func parseCAMT053(data []byte) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse camt.053: %w", err)
+	}
+	if !strings.HasPrefix(doc.XMLName.Space, "urn:iso:std:iso:20022:tech:xsd:camt.053") {
+		return nil, errors.New("parse camt.053: document is not a camt.053 statement")
+	}
+
+	stmt := &Statement{}
+	lineNo := 0
+	for _, s := range doc.Statements {
+		if from, err := parseBookingDate(s.FrToDt.FrDtTm); err == nil && from != nil && (stmt.PeriodStart == nil || from.Before(*stmt.PeriodStart)) {
+			stmt.PeriodStart = from
+		}
+		if to, err := parseBookingDate(s.FrToDt.ToDtTm); err == nil && to != nil && (stmt.PeriodEnd == nil || to.After(*stmt.PeriodEnd)) {
+			stmt.PeriodEnd = to
+		}
+
+		for _, entry := range s.Entries {
+			status := strings.ToUpper(strings.TrimSpace(entry.Sts.Code + entry.Sts.Cd))
+			if status != "" && status != "BOOK" {
+				continue
+			}
+			var direction string
+			switch strings.ToUpper(entry.CdtDbtInd) {
+			case "DBIT":
+				direction = DirectionDebit
+			case "CRDT":
+				direction = DirectionCredit
+			default:
+				return nil, fmt.Errorf("parse camt.053: entry %q has CdtDbtInd %q", entry.NtryRef, entry.CdtDbtInd)
+			}
+			bookedRaw := entry.BookgDt.Dt
+			if bookedRaw == "" {
+				bookedRaw = entry.BookgDt.DtTm
+			}
+			bookedAt, err := parseBookingDate(bookedRaw)
+			if err != nil {
+				return nil, fmt.Errorf("parse camt.053: entry %q: %w", entry.NtryRef, err)
+			}
+
+			type part struct {
+				ref         string
+				amount      camtAmount
+				description string
+			}
+			var parts []part
+			for _, tx := range entry.TxDtls {
+				amount := entry.Amt
+				switch {
+				case tx.Amt != nil:
+					amount = *tx.Amt
+				case tx.TxAmt != nil:
+					amount = *tx.TxAmt
+				case tx.InstdA != nil:
+					amount = *tx.InstdA
+				case len(entry.TxDtls) > 1:
+					return nil, fmt.Errorf("parse camt.053: entry %q batches %d transactions without per-transaction amounts", entry.NtryRef, len(entry.TxDtls))
+				}
+				ref := firstNonEmpty(tx.Refs.EndToEndId, tx.Refs.AcctSvcrRef)
+				if strings.EqualFold(ref, "NOTPROVIDED") {
+					ref = tx.Refs.AcctSvcrRef
+				}
+				parts = append(parts, part{ref: ref, amount: amount, description: strings.Join(tx.Ustrd, " ")})
+			}
+			if len(parts) == 0 {
+				parts = []part{{ref: firstNonEmpty(entry.AcctSvcrRef, entry.NtryRef), amount: entry.Amt, description: entry.AddtlInf}}
+			}
+
+			for _, p := range parts {
+				lineNo++
+				if p.ref == "" {
+					return nil, fmt.Errorf("parse camt.053: entry %q has no reference", entry.NtryRef)
+				}
+				amount, err := parseAmount(p.amount.Value)
+				if err != nil {
+					return nil, fmt.Errorf("parse camt.053: entry %q: %w", entry.NtryRef, err)
+				}
+				currency, err := normalizeCurrency(p.amount.Ccy)
+				if err != nil {
+					return nil, fmt.Errorf("parse camt.053: entry %q: %w", entry.NtryRef, err)
+				}
+				stmt.Lines = append(stmt.Lines, Line{
+					LineNo:       lineNo,
+					Ref:          p.ref,
+					AmountMicros: amount,
+					Currency:     currency,
+					Direction:    direction,
+					BookedAt:     bookedAt,
+					Description:  strings.TrimSpace(p.description),
+				})
+			}
+		}
+	}
+	return stmt, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
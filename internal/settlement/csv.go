@@ -0,0 +1,83 @@
+package settlement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSV files need a header row. gateway_ref, amount and currency are required;
+// direction (debit/credit, default debit), booked_at and description are
+// optional. Column order and header case do not matter.
+var csvRequiredColumns = []string{"gateway_ref", "amount", "currency"}
+
+func parseCSV(data []byte) (*Statement, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("csv settlement file is empty")
+		}
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	stmt := &Statement{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv row %d: %w", row, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		line := Line{LineNo: row, Ref: field(record, "gateway_ref"), Description: field(record, "description")}
+		if line.Ref == "" {
+			return nil, fmt.Errorf("csv row %d: gateway_ref is required", row)
+		}
+		if line.AmountMicros, err = parseAmount(field(record, "amount")); err != nil {
+			return nil, fmt.Errorf("csv row %d: %w", row, err)
+		}
+		if line.Currency, err = normalizeCurrency(field(record, "currency")); err != nil {
+			return nil, fmt.Errorf("csv row %d: %w", row, err)
+		}
+		switch direction := strings.ToLower(field(record, "direction")); direction {
+		case "", DirectionDebit, "dbit", "dr":
+			line.Direction = DirectionDebit
+		case DirectionCredit, "crdt", "cr":
+			line.Direction = DirectionCredit
+		default:
+			return nil, fmt.Errorf("csv row %d: direction %q must be debit or credit", row, direction)
+		}
+		if line.BookedAt, err = parseBookingDate(field(record, "booked_at")); err != nil {
+			return nil, fmt.Errorf("csv row %d: %w", row, err)
+		}
+		stmt.Lines = append(stmt.Lines, line)
+	}
+	return stmt, nil
+}
@@ -0,0 +1,146 @@
+// Package settlement parses bank and gateway settlement files into statement
+// lines that reconciliation matches against payouts.
+package settlement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Supported file formats.
+const (
+	FormatCSV     = "CSV"
+	FormatCAMT053 = "CAMT053"
+)
+
+// Line directions, from the account holder's point of view.
+const (
+	DirectionDebit  = "debit"
+	DirectionCredit = "credit"
+)
+
+// Statement is a parsed settlement file.
+type Statement struct {
+	Format string
+	// PeriodStart and PeriodEnd bound the bookings the file covers. They
+	// come from the statement header when it has one, otherwise from the
+	// earliest and latest booking dates.
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	Lines       []Line
+}
+
+// Line is one booked movement. Ref is the reference the payout was sent
+// under (the gateway reference or end-to-end ID).
+type Line struct {
+	LineNo       int
+	Ref          string
+	AmountMicros int64
+	Currency     string
+	Direction    string
+	BookedAt     *time.Time
+	Description  string
+}
+
+// Parse reads a settlement file in the given format. An empty format is
+// detected from the content: XML is camt.053, anything else CSV.
+func Parse(format string, data []byte) (*Statement, error) {
+	format = strings.ToUpper(strings.TrimSpace(format))
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	var (
+		stmt *Statement
+		err  error
+	)
+	switch format {
+	case FormatCSV:
+		stmt, err = parseCSV(data)
+	case FormatCAMT053:
+		stmt, err = parseCAMT053(data)
+	default:
+		return nil, fmt.Errorf("unsupported settlement file format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(stmt.Lines) == 0 {
+		return nil, errors.New("settlement file has no booked lines")
+	}
+	stmt.Format = format
+	stmt.fillPeriod()
+	return stmt, nil
+}
+
+// DetectFormat guesses a file's format from its first non-blank byte.
+func DetectFormat(data []byte) string {
+	trimmed := bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed = bytes.TrimLeft(trimmed, " \t\r\n")
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return FormatCAMT053
+	}
+	return FormatCSV
+}
+
+func (s *Statement) fillPeriod() {
+	for _, line := range s.Lines {
+		if line.BookedAt == nil {
+			continue
+		}
+		if s.PeriodStart == nil || line.BookedAt.Before(*s.PeriodStart) {
+			start := *line.BookedAt
+			s.PeriodStart = &start
+		}
+		if s.PeriodEnd == nil || line.BookedAt.After(*s.PeriodEnd) {
+			end := *line.BookedAt
+			s.PeriodEnd = &end
+		}
+	}
+}
+
+// parseAmount converts a decimal amount to micros, rejecting negative values
+// and precision beyond a micro.
+func parseAmount(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	d, err := decimal.NewFromString(raw)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is not a decimal number", raw)
+	}
+	if d.IsNegative() {
+		return 0, fmt.Errorf("amount %q must not be negative", raw)
+	}
+	micros := domain.FromDecimal(d)
+	if !decimal.NewFromInt(micros).Equal(d.Shift(6)) {
+		return 0, fmt.Errorf("amount %q has more than 6 decimals", raw)
+	}
+	return micros, nil
+}
+
+// parseBookingDate accepts an ISO date or date-time.
+func parseBookingDate(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("booking date %q is not an ISO date", raw)
+}
+
+func normalizeCurrency(raw string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(raw))
+	if len(currency) != 3 {
+		return "", fmt.Errorf("currency %q is not an ISO 4217 code", raw)
+	}
+	return currency, nil
+}
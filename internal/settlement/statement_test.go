@@ -0,0 +1,119 @@
+package settlement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	data := []byte("\xef\xbb\xbfCurrency,Gateway_Ref,Amount,Direction,Booked_At,Description\n" +
+		"usd,MOCK-1,12.5,debit,2026-03-01,Payout one\n" +
+		"\n" +
+		"EUR,e2e-2,0.000001,,2026-03-02T10:00:00Z,\n" +
+		"EUR,RET-1,5,credit,,Return\n")
+
+	stmt, err := Parse("", data)
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, stmt.Format)
+	require.Len(t, stmt.Lines, 3)
+
+	require.Equal(t, Line{LineNo: 2, Ref: "MOCK-1", AmountMicros: 12_500_000, Currency: "USD", Direction: DirectionDebit, BookedAt: stmt.Lines[0].BookedAt, Description: "Payout one"}, stmt.Lines[0])
+	require.Equal(t, int64(1), stmt.Lines[1].AmountMicros)
+	require.Equal(t, DirectionDebit, stmt.Lines[1].Direction)
+	require.Equal(t, DirectionCredit, stmt.Lines[2].Direction)
+	require.Nil(t, stmt.Lines[2].BookedAt)
+
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *stmt.PeriodStart)
+	require.Equal(t, time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), *stmt.PeriodEnd)
+}
+
+func TestParseCSVRejectsBadRows(t *testing.T) {
+	cases := map[string]string{
+		"missing column": "gateway_ref,amount\nA,1\n",
+		"bad amount":     "gateway_ref,amount,currency\nA,1.2.3,USD\n",
+		"negative":       "gateway_ref,amount,currency\nA,-1,USD\n",
+		"sub micro":      "gateway_ref,amount,currency\nA,0.0000001,USD\n",
+		"bad currency":   "gateway_ref,amount,currency\nA,1,DOLLARS\n",
+		"missing ref":    "gateway_ref,amount,currency\n,1,USD\n",
+		"bad direction":  "gateway_ref,amount,currency,direction\nA,1,USD,sideways\n",
+		"no lines":       "gateway_ref,amount,currency\n",
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(FormatCSV, []byte(data))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestParseCAMT053(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>STMT-1</MsgId></GrpHdr>
+    <Stmt>
+      <Id>STMT-1</Id>
+      <FrToDt><FrDtTm>2026-03-01T00:00:00</FrDtTm><ToDtTm>2026-03-01T23:59:59</ToDtTm></FrToDt>
+      <Ntry>
+        <NtryRef>1</NtryRef>
+        <Amt Ccy="EUR">30.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2026-03-01</Dt></BookgDt>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>e2e-a</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">10.00</Amt></TxAmt></AmtDtls>
+            <RmtInf><Ustrd>Payout a</Ustrd></RmtInf>
+          </TxDtls>
+          <TxDtls>
+            <Refs><EndToEndId>e2e-b</EndToEndId></Refs>
+            <AmtDtls><TxAmt><Amt Ccy="EUR">20.00</Amt></TxAmt></AmtDtls>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+      <Ntry>
+        <NtryRef>2</NtryRef>
+        <Amt Ccy="EUR">99.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2026-03-01</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <NtryRef>3</NtryRef>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><DtTm>2026-03-01T12:00:00Z</DtTm></BookgDt>
+        <AcctSvcrRef>BANKFEE-7</AcctSvcrRef>
+        <AddtlNtryInf>Account fee</AddtlNtryInf>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`)
+
+	stmt, err := Parse("", data)
+	require.NoError(t, err)
+	require.Equal(t, FormatCAMT053, stmt.Format)
+	require.Len(t, stmt.Lines, 3)
+	require.Equal(t, "e2e-a", stmt.Lines[0].Ref)
+	require.Equal(t, int64(10_000_000), stmt.Lines[0].AmountMicros)
+	require.Equal(t, "Payout a", stmt.Lines[0].Description)
+	require.Equal(t, "e2e-b", stmt.Lines[1].Ref)
+	require.Equal(t, int64(20_000_000), stmt.Lines[1].AmountMicros)
+	require.Equal(t, "BANKFEE-7", stmt.Lines[2].Ref)
+	require.Equal(t, "Account fee", stmt.Lines[2].Description)
+	for _, line := range stmt.Lines {
+		require.Equal(t, "EUR", line.Currency)
+		require.Equal(t, DirectionDebit, line.Direction)
+	}
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *stmt.PeriodStart)
+	require.Equal(t, time.Date(2026, 3, 1, 23, 59, 59, 0, time.UTC), *stmt.PeriodEnd)
+}
+
+func TestParseCAMT053RejectsOtherDocuments(t *testing.T) {
+	_, err := Parse(FormatCAMT053, []byte(`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"/>`))
+	require.ErrorContains(t, err, "not a camt.053")
+}
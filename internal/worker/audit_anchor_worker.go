@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"go.uber.org/zap"
+)
+
+// AuditAnchorWorker periodically verifies the audit records appended since
+// the last anchor and anchors the chain head. An anchor is unique per
+// chain_seq, so concurrent instances are safe.
+type AuditAnchorWorker struct {
+	svc      *service.AuditService
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewAuditAnchorWorker constructs a worker with a default hourly interval.
+func NewAuditAnchorWorker(svc *service.AuditService) *AuditAnchorWorker {
+	return &AuditAnchorWorker{
+		svc:      svc,
+		interval: time.Hour,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// WithInterval updates the run interval.
+func (w *AuditAnchorWorker) WithInterval(interval time.Duration) *AuditAnchorWorker {
+	if interval > 0 {
+		w.interval = interval
+	}
+	return w
+}
+
+// Start blocks and anchors the audit chain at the configured interval.
+func (w *AuditAnchorWorker) Start(ctx context.Context) {
+	zap.L().Info("audit anchor worker starting", zap.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run once immediately at startup.
+	w.runOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("audit anchor worker context canceled")
+			return
+		case <-w.stopCh:
+			zap.L().Info("audit anchor worker stop signal received")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// Stop stops the running worker loop.
+func (w *AuditAnchorWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Run starts the worker in a goroutine and returns a stop function.
+func (w *AuditAnchorWorker) Run(ctx context.Context) func() {
+	go w.Start(ctx)
+	return w.Stop
+}
+
+func (w *AuditAnchorWorker) runOnce(ctx context.Context) {
+	if _, err := w.svc.Anchor(ctx); err != nil {
+		observability.IncrementWorkerRun("audit_anchor", "failed")
+		zap.L().Error("audit chain anchoring failed", zap.Error(err))
+		return
+	}
+	observability.IncrementWorkerRun("audit_anchor", "success")
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/gateway"
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"go.uber.org/zap"
+)
+
+// GatewayReportWorker polls an asynchronous gateway for status reports and
+// settles the SUBMITTED payouts they cover. Reports are only acknowledged once
+// applied, and settling an already settled payout is a no-op, so concurrent
+// instances are safe.
+type GatewayReportWorker struct {
+	reports      gateway.ReportConsumer
+	payouts      *service.PayoutService
+	pollInterval time.Duration
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+}
+
+// NewGatewayReportWorker constructs a report worker polling every 30 seconds.
+func NewGatewayReportWorker(reports gateway.ReportConsumer, payouts *service.PayoutService) *GatewayReportWorker {
+	return &GatewayReportWorker{
+		reports:      reports,
+		payouts:      payouts,
+		pollInterval: 30 * time.Second,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// WithPollInterval sets how often new status reports are looked for.
+func (w *GatewayReportWorker) WithPollInterval(interval time.Duration) *GatewayReportWorker {
+	if interval > 0 {
+		w.pollInterval = interval
+	}
+	return w
+}
+
+// Start blocks and consumes status reports until stopped.
+func (w *GatewayReportWorker) Start(ctx context.Context) {
+	zap.L().Info("gateway report worker starting", zap.Duration("poll_interval", w.pollInterval))
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("gateway report worker context canceled")
+			return
+		case <-w.stopCh:
+			zap.L().Info("gateway report worker stop signal received")
+			return
+		case <-ticker.C:
+			w.consume(ctx)
+		}
+	}
+}
+
+// Stop stops the running worker loop.
+func (w *GatewayReportWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Run starts the worker in a goroutine and returns a stop function.
+func (w *GatewayReportWorker) Run(ctx context.Context) func() {
+	go w.Start(ctx)
+	return w.Stop
+}
+
+func (w *GatewayReportWorker) consume(ctx context.Context) {
+	handled, err := w.reports.ConsumeReports(ctx, w.payouts.SettleSubmittedPayout)
+	if err != nil {
+		observability.IncrementWorkerRun("gateway_reports", "failed")
+		zap.L().Error("gateway report consumption failed", zap.Error(err), zap.Int("settled", handled))
+		return
+	}
+	observability.IncrementWorkerRun("gateway_reports", "success")
+	if handled > 0 {
+		zap.L().Info("gateway reports applied", zap.Int("settled", handled))
+	}
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"go.uber.org/zap"
+)
+
+// OutboxWorker relays committed outbox events to their sinks.
+// Safe for concurrent instances: events are claimed with FOR UPDATE SKIP LOCKED.
+type OutboxWorker struct {
+	svc          *service.OutboxRelayService
+	pollInterval time.Duration
+	batchSize    int32
+	retention    time.Duration
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+}
+
+// NewOutboxWorker constructs a relay worker polling every second.
+func NewOutboxWorker(svc *service.OutboxRelayService) *OutboxWorker {
+	return &OutboxWorker{
+		svc:          svc,
+		pollInterval: time.Second,
+		batchSize:    100,
+		retention:    7 * 24 * time.Hour,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// WithPollInterval sets how often due events are relayed.
+func (w *OutboxWorker) WithPollInterval(interval time.Duration) *OutboxWorker {
+	if interval > 0 {
+		w.pollInterval = interval
+	}
+	return w
+}
+
+// WithBatchSize sets the maximum number of events relayed per pass.
+func (w *OutboxWorker) WithBatchSize(size int32) *OutboxWorker {
+	if size > 0 {
+		w.batchSize = size
+	}
+	return w
+}
+
+// WithRetention sets how long published events are kept before purging.
+func (w *OutboxWorker) WithRetention(retention time.Duration) *OutboxWorker {
+	if retention > 0 {
+		w.retention = retention
+	}
+	return w
+}
+
+// Start blocks and relays events until stopped.
+func (w *OutboxWorker) Start(ctx context.Context) {
+	zap.L().Info("outbox worker starting", zap.Duration("poll_interval", w.pollInterval), zap.Int32("batch_size", w.batchSize))
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("outbox worker context canceled")
+			return
+		case <-w.stopCh:
+			zap.L().Info("outbox worker stop signal received")
+			return
+		case <-ticker.C:
+			w.relay(ctx)
+		case <-purgeTicker.C:
+			w.purge(ctx)
+		}
+	}
+}
+
+// Stop stops the running worker loop.
+func (w *OutboxWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Run starts the worker in a goroutine and returns a stop function.
+func (w *OutboxWorker) Run(ctx context.Context) func() {
+	go w.Start(ctx)
+	return w.Stop
+}
+
+// relay drains due events, continuing while full batches keep coming back.
+func (w *OutboxWorker) relay(ctx context.Context) {
+	for {
+		published, err := w.svc.RelayBatch(ctx, w.batchSize)
+		if err != nil {
+			observability.IncrementWorkerRun("outbox", "failed")
+			zap.L().Error("outbox relay failed", zap.Error(err))
+			break
+		}
+		observability.IncrementWorkerRun("outbox", "success")
+		if published < int(w.batchSize) {
+			break
+		}
+		select {
+		case <-w.stopCh:
+			return
+		default:
+		}
+	}
+
+	pending, err := w.svc.PendingCount(ctx)
+	if err != nil {
+		zap.L().Warn("outbox worker failed to refresh pending count", zap.Error(err))
+		return
+	}
+	observability.SetOutboxPending(pending)
+}
+
+func (w *OutboxWorker) purge(ctx context.Context) {
+	purged, err := w.svc.PurgePublished(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		zap.L().Warn("outbox purge failed", zap.Error(err))
+		return
+	}
+	if purged > 0 {
+		zap.L().Info("purged published outbox events", zap.Int64("count", purged))
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"go.uber.org/zap"
+)
+
+// OverdraftInterestWorker charges overdraft interest through the previous
+// UTC day, including days missed while it was down. Charges are keyed by
+// account and day, so it can run more often than daily and on several
+// instances.
+type OverdraftInterestWorker struct {
+	svc      *service.OverdraftService
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewOverdraftInterestWorker constructs a worker with a default hourly interval.
+func NewOverdraftInterestWorker(svc *service.OverdraftService) *OverdraftInterestWorker {
+	return &OverdraftInterestWorker{
+		svc:      svc,
+		interval: time.Hour,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// WithInterval updates the run interval.
+func (w *OverdraftInterestWorker) WithInterval(interval time.Duration) *OverdraftInterestWorker {
+	if interval > 0 {
+		w.interval = interval
+	}
+	return w
+}
+
+// Start blocks and accrues overdraft interest at the configured interval.
+func (w *OverdraftInterestWorker) Start(ctx context.Context) {
+	zap.L().Info("overdraft interest worker starting", zap.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run once immediately at startup.
+	w.runOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("overdraft interest worker context canceled")
+			return
+		case <-w.stopCh:
+			zap.L().Info("overdraft interest worker stop signal received")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// Stop stops the running worker loop.
+func (w *OverdraftInterestWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Run starts the worker in a goroutine and returns a stop function.
+func (w *OverdraftInterestWorker) Run(ctx context.Context) func() {
+	go w.Start(ctx)
+	return w.Stop
+}
+
+func (w *OverdraftInterestWorker) runOnce(ctx context.Context) {
+	charged, err := w.svc.AccrueInterestForYesterday(ctx)
+	if err != nil {
+		observability.IncrementWorkerRun("overdraft_interest", "failed")
+		zap.L().Error("overdraft interest accrual failed", zap.Error(err), zap.Int("charged", charged))
+		return
+	}
+	observability.IncrementWorkerRun("overdraft_interest", "success")
+	if charged > 0 {
+		zap.L().Info("overdraft interest charged", zap.Int("accounts", charged))
+	}
+}
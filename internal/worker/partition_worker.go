@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"go.uber.org/zap"
+)
+
+// PartitionWorker runs periodic entries partition maintenance. The DDL is
+// serialized in the database, so concurrent instances are safe.
+type PartitionWorker struct {
+	svc      *service.PartitionService
+	interval time.Duration
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPartitionWorker constructs a worker with a default daily interval.
+func NewPartitionWorker(svc *service.PartitionService) *PartitionWorker {
+	return &PartitionWorker{
+		svc:      svc,
+		interval: 24 * time.Hour,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// WithInterval updates the run interval.
+func (w *PartitionWorker) WithInterval(interval time.Duration) *PartitionWorker {
+	if interval > 0 {
+		w.interval = interval
+	}
+	return w
+}
+
+// Start blocks and runs partition maintenance at the configured interval.
+func (w *PartitionWorker) Start(ctx context.Context) {
+	zap.L().Info("partition worker starting", zap.Duration("interval", w.interval))
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// Run once immediately at startup.
+	w.runOnce(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("partition worker context canceled")
+			return
+		case <-w.stopCh:
+			zap.L().Info("partition worker stop signal received")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// Stop stops the running worker loop.
+func (w *PartitionWorker) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+}
+
+// Run starts the worker in a goroutine and returns a stop function.
+func (w *PartitionWorker) Run(ctx context.Context) func() {
+	go w.Start(ctx)
+	return w.Stop
+}
+
+func (w *PartitionWorker) runOnce(ctx context.Context) {
+	if err := w.svc.Run(ctx); err != nil {
+		observability.IncrementWorkerRun("partitions", "failed")
+		zap.L().Error("partition maintenance failed", zap.Error(err))
+		return
+	}
+	observability.IncrementWorkerRun("partitions", "success")
+}
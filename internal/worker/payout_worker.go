@@ -4,22 +4,44 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ayo6706/payment-multicurrency/internal/observability"
+	"github.com/ayo6706/payment-multicurrency/internal/outbox"
 	"github.com/ayo6706/payment-multicurrency/internal/service"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // PayoutWorker processes pending payouts in the background.
-// It polls for pending payouts at regular intervals and processes them.
+// It dispatches payouts as their outbox events arrive and sweeps at regular
+// intervals for anything missed and for stale PROCESSING payouts.
 // Safe for concurrent instances thanks to FOR UPDATE SKIP LOCKED.
+//
+// Gateway calls run concurrently, bounded by the payout service's concurrency.
+// The select loop never blocks on them: sweeps and dispatches run in tracked
+// goroutines, and Stop waits for in-flight calls to settle. A dispatch only
+// starts when a slot is free, so a burst never parks goroutines; the rest is
+// left to a sweep.
 type PayoutWorker struct {
 	payoutService *service.PayoutService
 	pollInterval  time.Duration
 	batchSize     int32
+	events        <-chan outbox.Event
+	wakeups       <-chan string
+	sweeping      atomic.Bool
+	sweepAgain    atomic.Bool
+	started       atomic.Bool
+	inflight      sync.WaitGroup
 	stopCh        chan struct{}
 	stopOnce      sync.Once
+	done          chan struct{}
+
+	// dispatching holds the payouts with a dispatch running, so the outbox
+	// event and the NOTIFY for one payout dispatch it once.
+	dispatchMu  sync.Mutex
+	dispatching map[uuid.UUID]struct{}
 }
 
 // NewPayoutWorker creates a new PayoutWorker instance.
@@ -29,6 +51,8 @@ func NewPayoutWorker(payoutSvc *service.PayoutService) *PayoutWorker {
 		pollInterval:  10 * time.Second, // Default: poll every 10 seconds
 		batchSize:     10,               // Process up to 10 payouts at a time
 		stopCh:        make(chan struct{}),
+		done:          make(chan struct{}),
+		dispatching:   make(map[uuid.UUID]struct{}),
 	}
 }
 
@@ -44,11 +68,38 @@ func (w *PayoutWorker) WithBatchSize(size int32) *PayoutWorker {
 	return w
 }
 
+// WithDispatchEvents sets the outbox event stream the worker dispatches from.
+// Without it the worker only sweeps on the poll interval.
+func (w *PayoutWorker) WithDispatchEvents(events <-chan outbox.Event) *PayoutWorker {
+	w.events = events
+	return w
+}
+
+// WithWakeups sets a stream of NOTIFY payloads that wake the worker early.
+// A payout ID payload dispatches that payout; an empty payload (sent after the
+// listener reconnects) triggers a full sweep for anything missed meanwhile.
+func (w *PayoutWorker) WithWakeups(wakeups <-chan string) *PayoutWorker {
+	w.wakeups = wakeups
+	return w
+}
+
 // Start begins the background worker.
-// It runs in a loop until Stop is called or the context is canceled.
+// It runs in a loop until Stop is called or the context is canceled, then
+// waits for in-flight payouts before returning.
 func (w *PayoutWorker) Start(ctx context.Context) {
+	w.started.Store(true)
+	defer close(w.done)
 	zap.L().Info("payout worker starting", zap.Duration("poll_interval", w.pollInterval), zap.Int32("batch_size", w.batchSize))
 
+	// runCtx stops new claims on shutdown; in-flight gateway calls are
+	// detached from it inside the service and finish within their timeout.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		w.inflight.Wait()
+		zap.L().Info("payout worker drained")
+	}()
+
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
@@ -60,23 +111,116 @@ func (w *PayoutWorker) Start(ctx context.Context) {
 		case <-w.stopCh:
 			zap.L().Info("payout worker stop signal received")
 			return
+		case evt, ok := <-w.events:
+			if !ok {
+				w.events = nil
+				continue
+			}
+			if evt.AggregateType == outbox.AggregatePayout {
+				w.goProcessPayout(runCtx, evt.AggregateID)
+			}
+		case payload, ok := <-w.wakeups:
+			if !ok {
+				w.wakeups = nil
+				continue
+			}
+			w.wake(runCtx, payload)
 		case <-ticker.C:
-			w.processBatch(ctx)
+			w.goSweep(runCtx)
 		}
 	}
 }
 
-// Stop signals the worker to stop.
+// Stop signals the worker to stop and waits for in-flight payouts to settle.
 func (w *PayoutWorker) Stop() {
 	w.stopOnce.Do(func() {
 		close(w.stopCh)
 	})
+	if w.started.Load() {
+		<-w.done
+	}
+}
+
+// wake handles a NOTIFY payload from the payout channel.
+func (w *PayoutWorker) wake(ctx context.Context, payload string) {
+	payoutID, err := uuid.Parse(payload)
+	if err != nil {
+		w.goSweep(ctx)
+		return
+	}
+	w.goProcessPayout(ctx, payoutID)
+}
+
+// goSweep starts a batch sweep unless one is already running, in which case
+// that sweep runs once more when it finishes, so nothing asked for meanwhile
+// waits for the next tick.
+func (w *PayoutWorker) goSweep(ctx context.Context) {
+	w.sweepAgain.Store(true)
+	if !w.sweeping.CompareAndSwap(false, true) {
+		return
+	}
+	w.inflight.Add(1)
+	go func() {
+		defer w.inflight.Done()
+		for {
+			w.sweepAgain.Store(false)
+			w.processBatch(ctx)
+			w.sweeping.Store(false)
+			if ctx.Err() != nil || !w.sweepAgain.Load() || !w.sweeping.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	}()
+}
+
+// goProcessPayout dispatches one payout without blocking the worker loop.
+// A payout already being dispatched is skipped. When every slot is busy the
+// payout is left to a sweep instead of waiting in a goroutine.
+func (w *PayoutWorker) goProcessPayout(ctx context.Context, payoutID uuid.UUID) {
+	if ctx.Err() != nil {
+		return
+	}
+	w.dispatchMu.Lock()
+	if _, ok := w.dispatching[payoutID]; ok {
+		w.dispatchMu.Unlock()
+		return
+	}
+	process := w.payoutService.TryProcessPayout(payoutID)
+	if process == nil {
+		w.dispatchMu.Unlock()
+		w.goSweep(ctx)
+		return
+	}
+	w.dispatching[payoutID] = struct{}{}
+	w.dispatchMu.Unlock()
+
+	w.inflight.Add(1)
+	go func() {
+		defer w.inflight.Done()
+		defer func() {
+			w.dispatchMu.Lock()
+			delete(w.dispatching, payoutID)
+			w.dispatchMu.Unlock()
+		}()
+		if err := process(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			observability.IncrementWorkerRun("payout", "failed")
+			zap.L().Error("payout dispatch failed", zap.Error(err), zap.String("payout_id", payoutID.String()))
+			return
+		}
+		observability.IncrementWorkerRun("payout", "success")
+	}()
 }
 
 // processBatch processes a single batch of pending payouts.
 func (w *PayoutWorker) processBatch(ctx context.Context) {
 	err := w.payoutService.ProcessPayouts(ctx, w.batchSize)
 	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
 		observability.IncrementWorkerRun("payout", "failed")
 		zap.L().Error("payout worker batch failed", zap.Error(err))
 	} else {
@@ -100,6 +244,7 @@ func (w *PayoutWorker) ProcessOnce(ctx context.Context) error {
 // Run starts the worker and returns a function that can be called to stop it.
 // This is useful for starting the worker in a goroutine.
 func (w *PayoutWorker) Run(ctx context.Context) func() {
+	w.started.Store(true)
 	go w.Start(ctx)
 	return w.Stop
 }